@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/jwt"
+)
+
+const actorContextKey = contextKey("actor")
+
+// accessClaims are what an access token asserts about its user: who they
+// are, their organization and permissions, as of claims version Version.
+type accessClaims struct {
+	jwt.Claims
+	OrgID       int32            `json:"org"`
+	Permissions data.Permissions `json:"perms"`
+	Version     int32            `json:"ver"`
+}
+
+// claimsVersions holds the claims version of users whose permissions or
+// organization changed lately. Access tokens carrying an older version are
+// rejected without a query per request.
+type claimsVersions struct {
+	mu        sync.RWMutex
+	versions  map[int32]int32
+	since     time.Time
+	refreshed time.Time
+}
+
+// refreshClaimsVersions picks up claims changed since the last refresh, or
+// since the oldest access token still valid on the first.
+func (app *application) refreshClaimsVersions() error {
+	c := &app.claims
+	start := time.Now()
+
+	c.mu.RLock()
+	since := c.since
+	c.mu.RUnlock()
+
+	if since.IsZero() {
+		since = start.Add(-app.config.access.ttl)
+	}
+
+	versions, err := app.models.Permission.GetClaimsVersions(since)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.versions == nil {
+		c.versions = make(map[int32]int32)
+	}
+	for userID, version := range versions {
+		c.versions[userID] = max(c.versions[userID], version)
+	}
+
+	// Look back a minute further than needed, so changes committed by
+	// transactions that started before the last refresh, and small clock
+	// differences with the database, are not missed.
+	c.since = start.Add(-time.Minute)
+	c.refreshed = start
+
+	return nil
+}
+
+func (app *application) runClaimsRevocation() {
+	if app.config.access.secret == "" {
+		return
+	}
+
+	for {
+		time.Sleep(app.config.access.revocationInterval)
+
+		err := app.refreshClaimsVersions()
+		if err != nil {
+			app.logger.Error("access token revocation refresh failed", "error", err.Error())
+		}
+	}
+}
+
+// currentClaimsVersion returns the latest claims version known for a user,
+// or ok false when the versions have not been refreshed lately enough to be
+// trusted.
+func (app *application) currentClaimsVersion(userID int32) (version int32, ok bool) {
+	c := &app.claims
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if time.Since(c.refreshed) > 3*app.config.access.revocationInterval+time.Minute {
+		return 0, false
+	}
+
+	return c.versions[userID], true
+}
+
+// authenticateAccessToken resolves an access token to its user and actor.
+// Tokens whose claims version has since been superseded are rejected with
+// jwt.ErrInvalid, which is recorded in the user's security history.
+func (app *application) authenticateAccessToken(r *http.Request, token string) (*http.Request, error) {
+	var claims accessClaims
+
+	err := jwt.Parse(token, []byte(app.config.access.secret), time.Now(), &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, jwt.ErrInvalid
+	}
+
+	current, ok := app.currentClaimsVersion(int32(userID))
+	if !ok {
+		// Revocations may have been missed, so check the token against the
+		// database instead.
+		_, current, err = app.models.Permission.GetActorClaims(int32(userID))
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				return nil, jwt.ErrInvalid
+			}
+			return nil, err
+		}
+	}
+
+	if claims.Version < current {
+		app.recordSecurityEvent(r, int32(userID), data.SecurityEventAccessTokenRejected)
+		return nil, jwt.ErrInvalid
+	}
+
+	actor := data.Actor{
+		UserID:      int32(userID),
+		OrgID:       claims.OrgID,
+		Permissions: claims.Permissions,
+	}
+
+	r = app.contextSetUser(r, &data.User{InternalID: actor.UserID, Activated: true})
+	ctx := context.WithValue(r.Context(), actorContextKey, actor)
+
+	return r.WithContext(ctx), nil
+}
+
+// createAccessTokenHandler exchanges the authentication token the request
+// is made with, or the one in the refresh cookie, for a short-lived access
+// token embedding the user's organization and permissions, which authorizes
+// requests without reading them from the database. Access tokens stop
+// working once the user's permissions or organization change, and cannot be
+// exchanged themselves.
+func (app *application) createAccessTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if app.config.access.secret == "" {
+		app.featureNotConfiguredResponse(w, r)
+		return
+	}
+
+	if _, ok := r.Context().Value(actorContextKey).(data.Actor); ok {
+		app.errorResponse(w, r, http.StatusForbidden, "access tokens cannot be exchanged for new ones")
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	actor, version, err := app.models.Permission.GetActorClaims(user.InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	expiry := now.Add(app.config.access.ttl).Truncate(time.Second)
+
+	claims := accessClaims{
+		Claims: jwt.Claims{
+			Subject:   strconv.Itoa(int(user.InternalID)),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiry.Unix(),
+		},
+		OrgID:       actor.OrgID,
+		Permissions: actor.Permissions,
+		Version:     version,
+	}
+
+	token, err := jwt.Sign(claims, []byte(app.config.access.secret))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, user.InternalID, data.SecurityEventAccessTokenCreated)
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"access_token": envelope{"token": token, "expiry": expiry}}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// authenticateRefreshCookie authenticates requests made without an
+// Authorization header with the authentication token in the refresh cookie,
+// when there is one.
+func (app *application) authenticateRefreshCookie(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(refreshCookieName)
+		if err != nil || r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := app.authenticationTokenUser(cookie.Value)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.invalidAuthenticationTokenResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, app.contextSetUser(r, user))
+	}
+}
+
+// refreshCookieAuthentication is authenticateRefreshCookie as middleware,
+// for routes that keep requireAuthenticatedUser outermost on the handler.
+func (app *application) refreshCookieAuthentication(next http.Handler) http.Handler {
+	return app.authenticateRefreshCookie(next.ServeHTTP)
+}
+
+// setRefreshCookieHandler stores the authentication token the request is
+// made with in an HttpOnly cookie, so browser frontends can create access
+// tokens without keeping the authentication token where scripts read it.
+func (app *application) setRefreshCookieHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.Context().Value(actorContextKey).(data.Actor); ok {
+		app.errorResponse(w, r, http.StatusForbidden, "access tokens cannot be stored in the refresh cookie")
+		return
+	}
+
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	http.SetCookie(w, app.newCookie(r, refreshCookieName, token, 0))
+
+	app.recordSecurityEvent(r, app.contextGetUser(r).InternalID, data.SecurityEventRefreshCookieSet)
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"message": "refresh cookie set"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteRefreshCookieHandler clears the refresh cookie. The authentication
+// token it held stays valid until it expires.
+func (app *application) deleteRefreshCookieHandler(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, app.newCookie(r, refreshCookieName, "", -1))
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"message": "refresh cookie cleared"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// logoutAllHandler signs the user out of every device: their authentication
+// tokens are deleted, access tokens already issued to them stop working
+// within the revocation interval, and the refresh cookie of the calling
+// browser is cleared.
+func (app *application) logoutAllHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	revoked, err := app.models.Token.RevokeAllForUser(user.InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, app.newCookie(r, refreshCookieName, "", -1))
+
+	app.recordSecurityEvent(r, user.InternalID, data.SecurityEventLogoutAll)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "signed out of all devices", "revoked_tokens": revoked}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) listAccountingMappingHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	customers, err := app.models.Accounting.GetCustomers(actor)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	serviceItems, err := app.models.Accounting.GetServiceItems(actor)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"customers": customers, "service_items": serviceItems}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// setCustomerMappingHandler maps a project to a customer. Mappings are
+// changed by organization admins.
+func (app *application) setCustomerMappingHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Customer string `json:"customer"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateAccountingName(v, "customer", input.Customer); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	mapping := &data.CustomerMapping{
+		ProjectID: externalID,
+		Customer:  input.Customer,
+	}
+
+	err = app.models.Accounting.SetCustomer(actor, mapping)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"customer": mapping}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteCustomerMappingHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Accounting.DeleteCustomer(actor, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "customer mapping successfully deleted", deletedResource{Resource: "customer_mapping", ID: externalID})
+}
+
+// setServiceItemMappingHandler maps an activity to a service item. Mappings
+// are changed by organization admins.
+func (app *application) setServiceItemMappingHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	activityID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ServiceItem string `json:"service_item"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateAccountingName(v, "service_item", input.ServiceItem); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	mapping := &data.ServiceItemMapping{
+		ActivityID:  activityID,
+		ServiceItem: input.ServiceItem,
+	}
+
+	err = app.models.Accounting.SetServiceItem(actor, mapping)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"service_item": mapping}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteServiceItemMappingHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	activityID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Accounting.DeleteServiceItem(actor, activityID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "service item mapping successfully deleted", deletedResource{Resource: "service_item_mapping", ID: activityID})
+}
+
+// exportAccountingHandler exports the approved time worked between from and
+// to, inclusive, for import into an accounting system: format iif gives a
+// QuickBooks Desktop IIF file of time activities, and xero-csv a Xero sales
+// invoice CSV with a draft invoice per customer. Only projects mapped to a
+// customer are exported, billed as their activities' service items.
+func (app *application) exportAccountingHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	format := app.readString(qs, "format", "iif")
+	v.Check(validator.PermittedValue(format, "iif", "xero-csv"), "format", "must be one of iif or xero-csv")
+
+	var from, to *time.Time
+	if s := qs.Get("from"); s != "" {
+		from = app.parseDate(v, "from", s)
+	} else {
+		v.AddError("from", "must be provided")
+	}
+	if s := qs.Get("to"); s != "" {
+		to = app.parseDate(v, "to", s)
+	} else {
+		v.AddError("to", "must be provided")
+	}
+
+	if from != nil && to != nil {
+		v.Check(!to.Before(*from), "to", "must not be before from")
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	lines, err := app.models.Accounting.GetLines(actor, *from, *to)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var body []byte
+	var contentType, extension string
+
+	switch format {
+	case "iif":
+		body = renderIIF(lines)
+		contentType, extension = "text/plain; charset=utf-8", "iif"
+	default:
+		body, err = renderXeroCSV(lines, *from, *to)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	}
+
+	filename := fmt.Sprintf("time-%s-%s.%s", from.Format(time.DateOnly), to.Format(time.DateOnly), extension)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// renderIIF writes lines as QuickBooks TIMEACT records, marked billable.
+// IIF fields are tab separated, so tabs and line breaks in values become
+// spaces.
+func renderIIF(lines []*data.AccountingLine) []byte {
+	var b bytes.Buffer
+
+	clean := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+	b.WriteString("!TIMEACT\tDATE\tJOB\tEMP\tITEM\tPITEM\tDURATION\tPROJ\tNOTE\tXFERTOPR\tBILLINGSTATUS\r\n")
+
+	for _, line := range lines {
+		fields := []string{
+			"TIMEACT",
+			line.WorkDate.Format("01/02/2006"),
+			clean.Replace(line.Customer),
+			clean.Replace(line.Employee),
+			clean.Replace(line.ServiceItem),
+			"",
+			fmt.Sprintf("%d:%02d", line.Minutes/60, line.Minutes%60),
+			"",
+			clean.Replace(line.Note),
+			"N",
+			"1",
+		}
+		b.WriteString(strings.Join(fields, "\t") + "\r\n")
+	}
+
+	return b.Bytes()
+}
+
+// renderXeroCSV writes lines as a draft sales invoice per customer, with a
+// line per project and service item. Unit prices, accounts and tax rates
+// are left for Xero to take from the service items.
+func renderXeroCSV(lines []*data.AccountingLine, from, to time.Time) ([]byte, error) {
+	type invoiceLine struct {
+		customer    string
+		serviceItem string
+		description string
+		minutes     int32
+	}
+
+	var invoiceLines []*invoiceLine
+	index := make(map[string]*invoiceLine)
+
+	for _, line := range lines {
+		key := fmt.Sprintf("%s\x00%d\x00%s", line.Customer, line.ProjectID, line.ServiceItem)
+
+		il, ok := index[key]
+		if !ok {
+			il = &invoiceLine{
+				customer:    line.Customer,
+				serviceItem: line.ServiceItem,
+				description: strings.TrimSpace(fmt.Sprintf("Project %d %s", line.ProjectID, line.ProjectName)),
+			}
+			index[key] = il
+			invoiceLines = append(invoiceLines, il)
+		}
+		il.minutes += line.Minutes
+	}
+
+	var b bytes.Buffer
+	cw := csv.NewWriter(&b)
+
+	cw.Write([]string{"*ContactName", "*InvoiceNumber", "Reference", "*InvoiceDate", "*DueDate", "InventoryItemCode", "*Description", "*Quantity"})
+
+	reference := fmt.Sprintf("Time %s to %s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	invoiceDate := to.Format(time.DateOnly)
+
+	// Lines arrive ordered by customer, so each customer's invoice is
+	// numbered once its first line is reached.
+	invoices := make(map[string]string)
+
+	for _, il := range invoiceLines {
+		number, ok := invoices[il.customer]
+		if !ok {
+			number = fmt.Sprintf("TS-%s-%d", to.Format("20060102"), len(invoices)+1)
+			invoices[il.customer] = number
+		}
+
+		cw.Write([]string{
+			il.customer,
+			number,
+			reference,
+			invoiceDate,
+			invoiceDate,
+			il.serviceItem,
+			il.description,
+			fmt.Sprintf("%.2f", float64(il.minutes)/60),
+		})
+	}
+
+	cw.Flush()
+
+	return b.Bytes(), cw.Error()
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// validateActivityParent checks that the parent exists in the actor's
+// organization and that attaching the activity to it keeps the hierarchy
+// acyclic.
+func (app *application) validateActivityParent(actor data.Actor, v *validator.Validator, activity *data.Activity) error {
+	if activity.ParentID == nil {
+		return nil
+	}
+
+	_, err := app.models.Activity.Get(actor, *activity.ParentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("parent_id", "activity does not exist")
+			return nil
+		default:
+			return err
+		}
+	}
+
+	if activity.InternalID == 0 {
+		return nil
+	}
+
+	cycle, err := app.models.Activity.IsDescendant(activity.InternalID, *activity.ParentID)
+	if err != nil {
+		return err
+	}
+	v.Check(!cycle, "parent_id", "must not be the activity itself or one of its sub-activities")
+
+	return nil
+}
+
+func (app *application) createActivityHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name     string `json:"name"`
+		ParentID *int32 `json:"parent_id"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	activity := &data.Activity{
+		Name:     input.Name,
+		ParentID: input.ParentID,
+		OrgID:    actor.OrgID,
+	}
+
+	v := validator.New()
+	if data.ValidateActivity(v, activity); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.validateActivityParent(actor, v, activity)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Activity.Insert(activity)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.cache.Invalidate("activity:")
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/activity/%d", activity.InternalID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"activity": activity}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) showActivityHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	activity, err := app.models.Activity.Get(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"activity": activity}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listActivityHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("activity:%d:list", actor.OrgID)
+	if js, found := app.cache.Get(cacheKey); found {
+		app.writeCachedJSON(w, r, js)
+		return
+	}
+
+	activities, err := app.models.Activity.GetAll(actor)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	js, err := app.marshalJSON(envelope{"activities": activities})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.cache.Set(cacheKey, js)
+	app.writeCachedJSON(w, r, js)
+}
+
+func (app *application) activityTreeHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("activity:%d:tree", actor.OrgID)
+	if js, found := app.cache.Get(cacheKey); found {
+		app.writeCachedJSON(w, r, js)
+		return
+	}
+
+	activities, err := app.models.Activity.GetAll(actor)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	js, err := app.marshalJSON(envelope{"activities": data.BuildActivityTree(activities)})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.cache.Set(cacheKey, js)
+	app.writeCachedJSON(w, r, js)
+}
+
+func (app *application) updateActivityHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	activity, err := app.models.Activity.Get(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, activity.OrgID) {
+		return
+	}
+
+	// A null parent_id moves the activity to the top level, so it is kept
+	// raw to tell it apart from an absent field.
+	var input struct {
+		Name     *string         `json:"name"`
+		ParentID json.RawMessage `json:"parent_id"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name != nil {
+		activity.Name = *input.Name
+	}
+
+	if input.ParentID != nil {
+		activity.ParentID = nil
+		err = json.Unmarshal(input.ParentID, &activity.ParentID)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("body contains incorrect JSON type for field %q", "parent_id"))
+			return
+		}
+	}
+
+	v := validator.New()
+	if data.ValidateActivity(v, activity); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.validateActivityParent(actor, v, activity)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Activity.Update(activity)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cache.Invalidate("activity:")
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"activity": activity}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteActivityHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	err = app.models.Activity.Delete(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrActivityHasChildren):
+			v := validator.New()
+			v.AddError("id", "activity still has sub-activities")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cache.Invalidate("activity:")
+
+	app.deletedResponse(w, r, "activity successfully deleted", deletedResource{Resource: "activity", ID: id})
+}
+
+func (app *application) listProjectActivitiesHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	activities, err := app.models.Activity.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"activities": activities}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateProjectActivitiesHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	project, err := app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ActivityIDs []int32 `json:"activity_ids"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.ActivityIDs != nil, "activity_ids", "must be provided")
+	v.Check(validator.Unique(input.ActivityIDs), "activity_ids", "must not contain duplicate values")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Activity.SetForProject(project.InternalID, input.ActivityIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("activity_ids", "must only contain existing activities")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	activities, err := app.models.Activity.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"activities": activities}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
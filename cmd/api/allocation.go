@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// allocation returns the allocation if its project is one the request's user
+// sees.
+func (app *application) allocation(r *http.Request, id int32) (*data.Allocation, error) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return app.models.Allocation.Get(actor, id)
+}
+
+func (app *application) createAllocationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		UserID    int32   `json:"user_id"`
+		ProjectID int32   `json:"project_id"`
+		Week      string  `json:"week"`
+		Hours     float64 `json:"hours"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	allocation := &data.Allocation{
+		UserID:    input.UserID,
+		ProjectID: input.ProjectID,
+		Hours:     input.Hours,
+	}
+
+	if week := app.parseDate(v, "week", input.Week); week != nil {
+		allocation.Week = *week
+	}
+
+	if data.ValidateAllocation(v, allocation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	available, err := app.models.Planning.AvailableHours(allocation.UserID, allocation.Week, app.config.planning.weeklyHours)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Allocation.Insert(actor, allocation, available)
+	if err != nil {
+		app.allocationErrorResponse(w, r, v, err, available)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/allocation/%d", allocation.InternalID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"allocation": allocation}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listAllocationHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	var filter data.AllocationFilter
+
+	userID := app.readInt(qs, "user_id", 0, v)
+	v.Check(userID >= 0, "user_id", "must not be negative")
+	filter.UserID = int32(userID)
+
+	projectID := app.readInt(qs, "project_id", 0, v)
+	v.Check(projectID >= 0, "project_id", "must not be negative")
+	filter.ProjectID = int32(projectID)
+
+	if from := qs.Get("from"); from != "" {
+		filter.From = app.parseDate(v, "from", from)
+	}
+	if to := qs.Get("to"); to != "" {
+		filter.To = app.parseDate(v, "to", to)
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	allocations, err := app.models.Allocation.GetAll(actor, filter)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"allocations": allocations}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) showAllocationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	allocation, err := app.allocation(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"allocation": allocation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateAllocationHandler moves an allocation to another week or changes its
+// hours. The user and project stay as they are.
+func (app *application) updateAllocationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	allocation, err := app.allocation(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Week  *string  `json:"week"`
+		Hours *float64 `json:"hours"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if input.Week != nil {
+		if week := app.parseDate(v, "week", *input.Week); week != nil {
+			allocation.Week = *week
+		}
+	}
+	if input.Hours != nil {
+		allocation.Hours = *input.Hours
+	}
+
+	if data.ValidateAllocation(v, allocation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	available, err := app.models.Planning.AvailableHours(allocation.UserID, allocation.Week, app.config.planning.weeklyHours)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Allocation.Update(allocation, available)
+	if err != nil {
+		app.allocationErrorResponse(w, r, v, err, available)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"allocation": allocation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteAllocationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Allocation.Delete(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "allocation successfully deleted", deletedResource{Resource: "allocation", ID: id})
+}
+
+// allocationErrorResponse answers a failed insert or update of an
+// allocation.
+func (app *application) allocationErrorResponse(w http.ResponseWriter, r *http.Request, v *validator.Validator, err error, available float64) {
+	switch {
+	case errors.Is(err, data.ErrOverAllocated):
+		v.AddError("hours", fmt.Sprintf("would allocate the user beyond the %g hours they have available that week", available))
+		app.failedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, data.ErrDuplicateAllocation):
+		v.AddError("week", "the user already has an allocation on this project that week")
+		app.failedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, data.ErrRecordNotFound):
+		v.AddError("project_id", "user and project must exist")
+		app.failedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, data.ErrEditConflict):
+		app.editConflictResponse(w, r)
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
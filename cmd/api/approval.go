@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) showApprovalChainHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "approval:write") {
+		return
+	}
+
+	steps, err := app.models.ApprovalStep.GetChain(0)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"steps": steps}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) showProjectApprovalChainHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	steps, err := app.models.ApprovalStep.GetChain(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"steps": steps}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateApprovalChainHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "approval:write") {
+		return
+	}
+
+	app.replaceApprovalChain(w, r, nil)
+}
+
+func (app *application) updateProjectApprovalChainHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	project, err := app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
+	app.replaceApprovalChain(w, r, &project.InternalID)
+}
+
+func (app *application) replaceApprovalChain(w http.ResponseWriter, r *http.Request, projectInternalID *int32) {
+	var input struct {
+		Steps []data.ApprovalStep `json:"steps"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Steps != nil, "steps", "must be provided")
+	if data.ValidateApprovalSteps(v, input.Steps); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.ApprovalStep.ReplaceChain(projectInternalID, input.Steps)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("steps", "approvers must be existing users")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"steps": input.Steps}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readTimesheetEntry loads the entry named in the URL, answering the
+// request itself when it cannot.
+func (app *application) readTimesheetEntry(w http.ResponseWriter, r *http.Request) (*data.TimesheetEntry, bool) {
+	id, err := app.readInt64IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	entry, err := app.models.Timesheet.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// canDecide reports whether the request's user may approve or reject the
+// entry at its current step, answering the request itself when not. The
+// step's approver, anyone they delegated to and the leads of the entry
+// user's teams may decide.
+func (app *application) canDecide(w http.ResponseWriter, r *http.Request, entry *data.TimesheetEntry) bool {
+	if entry.Status != "submitted" || entry.ApproverID == nil {
+		app.timesheetStatusConflictResponse(w, r, entry.Status)
+		return false
+	}
+
+	user := app.contextGetUser(r)
+
+	ok, err := app.models.Delegation.CanActFor(user.InternalID, *entry.ApproverID, time.Now())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	if !ok && user.InternalID != entry.UserID {
+		ok, err = app.models.Team.IsLead(user.InternalID, entry.UserID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return false
+		}
+	}
+
+	if !ok {
+		app.notPermittedResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
+// submitTimesheetHandler sends the caller's own entry to the first step of
+// its project's approval chain.
+func (app *application) submitTimesheetHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := app.readTimesheetEntry(w, r)
+	if !ok {
+		return
+	}
+
+	if entry.UserID != app.contextGetUser(r).InternalID {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	steps, err := app.models.ApprovalStep.GetChain(entry.ExternalProjectID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	step := data.NextApprovalStep(steps, 0)
+
+	rules, err := app.models.WorkRules.GetForUsers([]int32{entry.UserID})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	workRules := rules[entry.UserID]
+	if workRules == nil {
+		workRules = data.DefaultWorkRules(0)
+	}
+
+	billed := workRules.Round(entry.Minutes)
+	entry.BilledMinutes = &billed
+
+	err = app.models.Timesheet.Submit(entry, step)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.timesheetStatusConflictResponse(w, r, entry.Status)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if step != nil {
+		app.notifyApprover(step.ApproverID, timesheetMessage(entry, "awaits your approval"), timesheetLink(entry))
+	}
+
+	app.writeTimesheetEntry(w, r, entry)
+}
+
+// approveTimesheetHandler passes the entry on to the next approval step, or
+// approves it when the chain is complete.
+func (app *application) approveTimesheetHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := app.readTimesheetEntry(w, r)
+	if !ok || !app.canDecide(w, r, entry) {
+		return
+	}
+
+	steps, err := app.models.ApprovalStep.GetChain(entry.ExternalProjectID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	next := data.NextApprovalStep(steps, entry.ApprovalPosition)
+
+	err = app.models.Timesheet.Advance(entry, next)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.timesheetStatusConflictResponse(w, r, entry.Status)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if next != nil {
+		app.notifyApprover(next.ApproverID, timesheetMessage(entry, "awaits your approval"), timesheetLink(entry))
+	} else {
+		app.notify(entry.UserID, data.NotificationApproval, timesheetMessage(entry, "was approved"), timesheetLink(entry))
+	}
+
+	app.writeTimesheetEntry(w, r, entry)
+}
+
+func (app *application) rejectTimesheetHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := app.readTimesheetEntry(w, r)
+	if !ok || !app.canDecide(w, r, entry) {
+		return
+	}
+
+	err := app.models.Timesheet.Reject(entry)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.timesheetStatusConflictResponse(w, r, entry.Status)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.notify(entry.UserID, data.NotificationApproval, timesheetMessage(entry, "was rejected"), timesheetLink(entry))
+
+	app.writeTimesheetEntry(w, r, entry)
+}
+
+func (app *application) writeTimesheetEntry(w http.ResponseWriter, r *http.Request, entry *data.TimesheetEntry) {
+	err := app.writeJSON(w, http.StatusOK, envelope{"timesheet_entry": entry}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func timesheetMessage(entry *data.TimesheetEntry, event string) string {
+	return fmt.Sprintf("Timesheet entry for %s on project %d %s", entry.WorkDate.Format(time.DateOnly), entry.ExternalProjectID, event)
+}
+
+func timesheetLink(entry *data.TimesheetEntry) string {
+	return fmt.Sprintf("/timesheet/%d", entry.InternalID)
+}
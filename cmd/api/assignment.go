@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) readAssignmentParams(r *http.Request) (int32, int32, error) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 32)
+	if err != nil || userID < 1 {
+		return 0, 0, errors.New("invalid user_id parameter")
+	}
+
+	return externalID, int32(userID), nil
+}
+
+func (app *application) listProjectMembersHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	members, err := app.models.Assignment.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"members": members}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// assignProjectMemberHandler puts a user on a project and notifies them the
+// first time. With manager=true they also manage it, and with role they are
+// granted the role's permissions.
+func (app *application) assignProjectMemberHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, userID, err := app.readAssignmentParams(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	qs := r.URL.Query()
+	manager := app.readString(qs, "manager", "false")
+	role := app.readString(qs, "role", "")
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(manager, "true", "false"), "manager", "must be true or false"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var rolePtr *string
+	if role != "" {
+		_, err = app.models.Role.Get(role)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("role", "must be an existing role")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+		rolePtr = &role
+	}
+
+	project, err := app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
+	created, err := app.models.Assignment.Insert(externalID, userID, manager == "true", rolePtr)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if created {
+		name := ""
+		if project.Name != nil {
+			name = *project.Name
+		}
+		message := fmt.Sprintf("You were assigned to project %d %s", externalID, name)
+		app.notify(userID, data.NotificationAssignment, message, fmt.Sprintf("/project/%d", externalID))
+	}
+
+	members, err := app.models.Assignment.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"members": members}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) removeProjectMemberHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, userID, err := app.readAssignmentParams(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Assignment.Delete(externalID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "member successfully removed", deletedResource{Resource: "project_member", ID: userID})
+}
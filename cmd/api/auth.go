@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/jwt"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const userContextKey = contextKey("user")
+
+func (app *application) contextSetUser(r *http.Request, user *data.User) *http.Request {
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	return r.WithContext(ctx)
+}
+
+// contextGetUser returns the user authenticate stored for the request, or
+// the anonymous user on routes it did not run for.
+func (app *application) contextGetUser(r *http.Request) *data.User {
+	user, ok := r.Context().Value(userContextKey).(*data.User)
+	if !ok {
+		return data.AnonymousUser
+	}
+
+	return user
+}
+
+// actor returns who data queries made for the request run on behalf of,
+// as asserted by the access token when the request was made with one. The
+// anonymous user is given an empty actor, which belongs to no organization
+// and sees no scoped rows, so routes reading them require authentication
+// rather than answer anonymous requests with empty results.
+func (app *application) actor(ctx context.Context) (data.Actor, error) {
+	if actor, ok := ctx.Value(actorContextKey).(data.Actor); ok {
+		return actor, nil
+	}
+
+	user, ok := ctx.Value(userContextKey).(*data.User)
+	if !ok || user.IsAnonymous() {
+		return data.Actor{}, nil
+	}
+
+	return app.models.Permission.GetActor(user.InternalID)
+}
+
+// authenticate resolves the bearer token in the Authorization header to the
+// user it was issued to, either an authentication token or, when enabled, an
+// access token. Requests without the header proceed as the anonymous user;
+// an invalid or expired token is rejected.
+func (app *application) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		authorizationHeader := r.Header.Get("Authorization")
+		if authorizationHeader == "" {
+			next.ServeHTTP(w, app.contextSetUser(r, data.AnonymousUser))
+			return
+		}
+
+		token, found := strings.CutPrefix(authorizationHeader, "Bearer ")
+		if !found {
+			app.invalidAuthenticationTokenResponse(w, r)
+			return
+		}
+
+		if app.config.access.secret != "" && jwt.Looks(token) {
+			authenticated, err := app.authenticateAccessToken(r, token)
+			switch {
+			case err == nil:
+				next.ServeHTTP(w, authenticated)
+			case errors.Is(err, jwt.ErrInvalid), errors.Is(err, jwt.ErrExpired):
+				app.invalidAuthenticationTokenResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		user, err := app.authenticationTokenUser(token)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.invalidAuthenticationTokenResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, app.contextSetUser(r, user))
+	})
+}
+
+// authenticationTokenUser returns the user an authentication token was
+// issued to, or ErrRecordNotFound when the token is malformed, unknown or
+// expired.
+func (app *application) authenticationTokenUser(token string) (*data.User, error) {
+	v := validator.New()
+	if data.ValidateTokenPlaintext(v, token); !v.Valid() {
+		return nil, data.ErrRecordNotFound
+	}
+
+	userID, err := app.models.Token.GetUserID(data.ScopeAuthentication, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.models.User.Get(userID)
+}
+
+// requireAuthenticatedUser rejects requests made without a valid
+// authentication token.
+func (app *application) requireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if app.contextGetUser(r).IsAnonymous() {
+			app.authenticationRequiredResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// requirePermission reports whether the request's user holds the permission
+// code, answering the request itself when not.
+func (app *application) requirePermission(w http.ResponseWriter, r *http.Request, code string) bool {
+	user := app.contextGetUser(r)
+	if user.IsAnonymous() {
+		app.authenticationRequiredResponse(w, r)
+		return false
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	if !actor.Permissions.Include(code) {
+		app.notPermittedResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
+// requireOrgAdmin reports whether the request's user may administer the
+// organization: with organization:admin-all any of them, with
+// organization:admin only their own. Otherwise it sends the error response.
+func (app *application) requireOrgAdmin(w http.ResponseWriter, r *http.Request, orgID int32) bool {
+	user := app.contextGetUser(r)
+	if user.IsAnonymous() {
+		app.authenticationRequiredResponse(w, r)
+		return false
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	if !isOrgAdmin(actor, orgID) {
+		app.notPermittedResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
+// isOrgAdmin reports whether actor administers the organization orgID.
+func isOrgAdmin(actor data.Actor, orgID int32) bool {
+	if actor.Permissions.Include("organization:admin-all") {
+		return true
+	}
+
+	return actor.Permissions.Include("organization:admin") && actor.OrgID == orgID
+}
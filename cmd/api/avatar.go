@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const (
+	maxAvatarBytes = 5 << 20
+	// maxAvatarPixels bounds the decoded size of an upload, so a small file
+	// cannot claim dimensions that exhaust memory.
+	maxAvatarPixels = 40_000_000
+	avatarSize      = 256
+	avatarURLTTL    = time.Hour
+)
+
+var avatarTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+// updateMyAvatarHandler replaces the caller's avatar with the uploaded image,
+// sent as the raw request body or a multipart "file" field. The image is
+// cropped to a square, scaled down to avatarSize and stored as a PNG.
+func (app *application) updateMyAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarBytes)
+
+	var body io.Reader = r.Body
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			app.readAvatarError(w, r, err)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		app.readAvatarError(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(http.DetectContentType(raw), avatarTypes...), "avatar", "must be a PNG, JPEG or GIF image"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var img image.Image
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err == nil && config.Width*config.Height <= maxAvatarPixels {
+		img, _, err = image.Decode(bytes.NewReader(raw))
+	}
+	if img == nil || err != nil {
+		v.AddError("avatar", "must be a readable image of at most 40 megapixels")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, resizeAvatar(img, avatarSize))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	user, err := app.models.User.Get(app.contextGetUser(r).InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	key := data.AvatarKey(user.InternalID)
+
+	_, err = app.s3actor.client.PutObject(r.Context(), &s3.PutObjectInput{
+		Bucket:      aws.String(app.config.s3.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(buf.Bytes()),
+		ContentType: aws.String("image/png"),
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	user.AvatarKey = &key
+
+	err = app.models.User.UpdateAvatarKey(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.setAvatarURL(r.Context(), user)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readAvatarError answers a failed read of the uploaded avatar.
+func (app *application) readAvatarError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		v := validator.New()
+		v.AddError("avatar", fmt.Sprintf("must not be more than %d bytes", maxAvatarBytes))
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	app.badRequestResponse(w, r, err)
+}
+
+// resizeAvatar crops img to a centred square and scales it down to size
+// pixels a side, averaging the source pixels each output pixel covers.
+// Images smaller than size are only cropped.
+func resizeAvatar(img image.Image, size int) image.Image {
+	b := img.Bounds()
+
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	out := min(side, size)
+	dst := image.NewRGBA(image.Rect(0, 0, out, out))
+
+	for y := 0; y < out; y++ {
+		sy0, sy1 := y0+y*side/out, y0+(y+1)*side/out
+		for x := 0; x < out; x++ {
+			sx0, sx1 := x0+x*side/out, x0+(x+1)*side/out
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+
+	return dst
+}
+
+// setAvatarURL fills in a signed download URL for the user's avatar. Failures
+// are logged and leave the URL empty rather than failing the response.
+func (app *application) setAvatarURL(ctx context.Context, user *data.User) {
+	if user.AvatarKey == nil || !app.storageEnabled() {
+		return
+	}
+
+	signedURL, err := app.signAvatarURL(ctx, *user.AvatarKey)
+	if err != nil {
+		app.logger.Error("signing avatar URL failed", "user_id", user.InternalID, "error", err.Error())
+		return
+	}
+
+	user.AvatarURL = &signedURL
+}
+
+// signAvatarURL signs a download URL for key with CloudFront when a CDN is
+// configured, valid for its URL lifetime, and with S3 for avatarURLTTL
+// otherwise. Avatars outlive the one-minute file downloads, as clients keep
+// them on screen.
+func (app *application) signAvatarURL(ctx context.Context, key string) (string, error) {
+	if app.s3actor.cdnSigner != nil {
+		return app.s3actor.cdnSigner.Sign(app.fileBaseURL()+(&url.URL{Path: key}).EscapedPath(), time.Now().Add(app.config.cdn.urlTTL))
+	}
+
+	request, err := app.s3actor.presignClient.PresignGetObject(
+		ctx,
+		&s3.GetObjectInput{
+			Bucket: aws.String(app.config.s3.bucket),
+			Key:    aws.String(key),
+		}, func(opts *s3.PresignOptions) {
+			opts.Expires = avatarURLTTL
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
+// setAvatarURLs is setAvatarURL for each of users.
+func (app *application) setAvatarURLs(ctx context.Context, users []*data.User) {
+	for _, user := range users {
+		app.setAvatarURL(ctx, user)
+	}
+}
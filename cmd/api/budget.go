@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) showProjectBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	budget, err := app.models.Budget.Get(externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"budget": budget}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateProjectBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Minutes int32    `json:"minutes"`
+		Cost    *float64 `json:"cost"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	budget := &data.ProjectBudget{
+		ProjectID: externalID,
+		Minutes:   input.Minutes,
+		Cost:      input.Cost,
+	}
+
+	v := validator.New()
+	if data.ValidateProjectBudget(v, budget); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Budget.Set(budget)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"budget": budget}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteProjectBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Budget.Delete(externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "budget successfully deleted", deletedResource{Resource: "budget", ID: externalID})
+}
+
+// showProjectEVMHandler reports the earned value of a project over time,
+// one point per week, or per month with interval=month.
+func (app *application) showProjectEVMHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	interval := app.readString(r.URL.Query(), "interval", "week")
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(interval, "week", "month"), "interval", "must be week or month"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	step := func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	if interval == "month" {
+		step = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	}
+
+	_, err = app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	budget, err := app.models.Budget.Get(externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.errorResponse(w, r, http.StatusConflict, "the project has no budget to measure against")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	milestones, err := app.models.Milestone.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	actuals, err := app.models.Timesheet.GetApprovedDaily(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	evm := data.ComputeEVM(budget, milestones, actuals, time.Now(), step)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"evm": evm}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const calendarTokenTTL = 365 * 24 * time.Hour
+
+func (app *application) createMilestoneHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Name  string `json:"name"`
+		DueOn string `json:"due_on"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	_, err = app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	milestone := &data.Milestone{
+		ProjectID: externalID,
+		Name:      input.Name,
+	}
+
+	v := validator.New()
+
+	if dueOn := app.parseDate(v, "due_on", input.DueOn); dueOn != nil {
+		milestone.DueOn = *dueOn
+	}
+
+	if data.ValidateMilestone(v, milestone); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Milestone.Insert(milestone)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"milestone": milestone}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listMilestoneHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	milestones, err := app.models.Milestone.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"milestones": milestones}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateMilestoneHandler records when a milestone was reached. A null
+// completed_on marks it as not reached.
+func (app *application) updateMilestoneHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		CompletedOn *string `json:"completed_on"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	var completedOn *time.Time
+	if input.CompletedOn != nil {
+		completedOn = app.parseDate(v, "completed_on", *input.CompletedOn)
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	milestone, err := app.models.Milestone.SetCompleted(actor, id, completedOn)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"milestone": milestone}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteMilestoneHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Milestone.Delete(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "milestone successfully deleted", deletedResource{Resource: "milestone", ID: id})
+}
+
+// createCalendarTokenHandler emails a calendar subscription address to an
+// activated account. Issuing a new one revokes the previous address.
+func (app *application) createCalendarTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateEmail(v, input.Email); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.User.GetByEmail(input.Email)
+	switch {
+	case err == nil && user.Activated:
+		err = app.models.Token.DeleteAllForUser(data.ScopeCalendar, user.InternalID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		token, err := app.models.Token.New(user.InternalID, calendarTokenTTL, data.ScopeCalendar)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		app.background(func() {
+			data := map[string]any{
+				"calendarToken": token.Plaintext,
+			}
+
+			err := app.userMailer(user.InternalID).Send(user.Email, "token_calendar.tmpl", data)
+			if err != nil {
+				app.requestLogger(r).Error(err.Error())
+			}
+		})
+	case err == nil, errors.Is(err, data.ErrRecordNotFound):
+	default:
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"message": "if the address belongs to an activated account, an email will be sent to it containing the calendar address"}
+
+	err = app.writeJSON(w, http.StatusAccepted, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) calendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	token := app.readString(r.URL.Query(), "token", "")
+
+	v := validator.New()
+	if data.ValidateTokenPlaintext(v, token); !v.Valid() {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	userID, err := app.models.Token.GetUserID(data.ScopeCalendar, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	events, err := app.models.Milestone.GetCalendarForUser(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(renderICalendar(events)))
+}
+
+// renderICalendar writes events as an RFC 5545 calendar of all-day entries.
+func renderICalendar(events []*data.CalendarEvent) string {
+	var b strings.Builder
+
+	line := func(s string) {
+		// Content lines are folded at 75 octets.
+		for len(s) > 75 {
+			cut := 75
+			for cut > 0 && s[cut]&0xC0 == 0x80 {
+				cut--
+			}
+			b.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+
+	escape := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Wanpm//Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:Wanpm")
+
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:%s@wanpm", event.UID))
+		line("DTSTAMP:" + event.UpdatedAt.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + event.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + event.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escape.Replace(event.Summary))
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	return b.String()
+}
@@ -11,10 +11,14 @@ import (
 
 func (app *application) createClientHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Name    *string `json:"name"`
-		Address *string `json:"address"`
-		LogoURL *string `json:"logo_url"`
-		Note    *string `json:"note"`
+		Name         *string           `json:"name"`
+		Address      *string           `json:"address"`
+		LogoURL      *string           `json:"logo_url"`
+		Note         *string           `json:"note"`
+		CustomFields data.CustomValues `json:"custom_fields"`
+		Longitude    *float64          `json:"longitude"`
+		Latitude     *float64          `json:"latitude"`
+		ParentID     *int32            `json:"parent_id"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -23,25 +27,56 @@ func (app *application) createClientHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	client := &data.Client{
-		Name:    input.Name,
-		Address: input.Address,
-		LogoURL: input.LogoURL,
-		Note:    input.Note,
+		Name:         input.Name,
+		Address:      input.Address,
+		LogoURL:      input.LogoURL,
+		Note:         input.Note,
+		CustomFields: input.CustomFields,
+		Longitude:    input.Longitude,
+		Latitude:     input.Latitude,
+		ParentID:     input.ParentID,
+		OrgID:        actor.OrgID,
 	}
 
 	v := validator.New()
-	if data.ValidateClient(v, client); !v.Valid() {
+	data.ValidateClient(v, client)
+
+	err = app.validateCustomValues(r, v, "client", client.CustomFields)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.checkClientParent(r, v, client)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
+	if client.Longitude == nil {
+		app.geocodeClient(client)
+	}
+
 	err = app.models.Client.Insert(client)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	app.cache.Invalidate("client:")
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/client/%d", client.InternalID))
 
@@ -51,6 +86,17 @@ func (app *application) createClientHandler(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// client returns the client with the ID if it belongs to the request's
+// actor's organization, and ErrRecordNotFound otherwise.
+func (app *application) client(r *http.Request, id int32) (*data.Client, error) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return app.models.Client.Get(actor, id)
+}
+
 func (app *application) showClientHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readInt32IDParam(r)
 	if err != nil {
@@ -58,7 +104,7 @@ func (app *application) showClientHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	client, err := app.models.Client.Get(id)
+	client, err := app.client(r, id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -75,9 +121,100 @@ func (app *application) showClientHandler(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// checkClientParent adds a validation error when the client's parent does
+// not exist in the caller's organization.
+func (app *application) checkClientParent(r *http.Request, v *validator.Validator, client *data.Client) error {
+	if client.ParentID == nil || *client.ParentID == client.InternalID {
+		return nil
+	}
+
+	_, err := app.client(r, *client.ParentID)
+	if errors.Is(err, data.ErrRecordNotFound) {
+		v.AddError("parent_id", "must be an existing client")
+		return nil
+	}
+
+	return err
+}
+
+// showClientSubtreeHandler returns a client with its divisions, theirs and
+// so on, for building the tree from each client's parent_id.
+func (app *application) showClientSubtreeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.client(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	clients, err := app.models.Client.GetSubtree(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"clients": clients}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) showClientSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.client(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	divisions := app.readString(r.URL.Query(), "include_divisions", "false")
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(divisions, "true", "false"), "include_divisions", "must be true or false"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	summary, err := app.models.Client.GetSummary(id, divisions == "true")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"summary": summary}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
 func (app *application) listClientHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Name string
+		data.ClientFilter
 		data.Filters
 	}
 
@@ -87,6 +224,27 @@ func (app *application) listClientHandler(w http.ResponseWriter, r *http.Request
 
 	input.Name = app.readString(qs, "name", "")
 
+	customFields, err := app.readCustomFieldFilter(r, qs, "client", v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	input.CustomFields = customFields
+
+	if bbox := app.readCSV(qs, "bbox", nil); bbox != nil {
+		input.Bbox, err = data.ConvertToBbox(bbox)
+		v.Check(err == nil && len(bbox) == 4, "bbox", "must be 4 comma separated coordinates")
+	}
+
+	if near := app.readCSV(qs, "near", nil); near != nil {
+		point, err := data.ConvertToPoint(near)
+		if v.Check(err == nil, "near", "must be a longitude,latitude pair"); err == nil {
+			input.Near = &point
+		}
+		input.Radius = float64(app.readInt(qs, "radius", 10000, v))
+		v.Check(input.Radius > 0, "radius", "must be greater than zero")
+	}
+
 	input.Filters.Page = app.readInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readInt(qs, "page_size", 0, v)
 
@@ -98,16 +256,33 @@ func (app *application) listClientHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	clients, metadata, err := app.models.Client.GetAll(input.Name, input.Filters)
+	actor, err := app.actor(r.Context())
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "clients": clients}, nil)
+	// Lists differ between organizations, so each has its own entries.
+	cacheKey := fmt.Sprintf("client:%d:%s", actor.OrgID, qs.Encode())
+	if js, found := app.cache.Get(cacheKey); found {
+		app.writeCachedJSON(w, r, js)
+		return
+	}
+
+	clients, metadata, err := app.models.Client.GetAll(actor, input.ClientFilter, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	js, err := app.marshalJSON(envelope{"metadata": metadata, "clients": clients})
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
+		return
 	}
+
+	app.cache.Set(cacheKey, js)
+	app.writeCachedJSON(w, r, js)
 }
 
 func (app *application) updateClientHandler(w http.ResponseWriter, r *http.Request) {
@@ -117,7 +292,7 @@ func (app *application) updateClientHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	client, err := app.models.Client.Get(id)
+	client, err := app.client(r, id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -129,10 +304,14 @@ func (app *application) updateClientHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	var input struct {
-		Name    *string `json:"name"`
-		Address *string `json:"address"`
-		Note    *string `json:"note"`
-		LogoURL *string `json:"logo_url"`
+		Name         *string           `json:"name"`
+		Address      *string           `json:"address"`
+		Note         *string           `json:"note"`
+		LogoURL      *string           `json:"logo_url"`
+		CustomFields data.CustomValues `json:"custom_fields"`
+		Longitude    *float64          `json:"longitude"`
+		Latitude     *float64          `json:"latitude"`
+		ParentID     *int32            `json:"parent_id"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -149,6 +328,10 @@ func (app *application) updateClientHandler(w http.ResponseWriter, r *http.Reque
 		client.Address = input.Address
 	}
 
+	if input.Longitude != nil || input.Latitude != nil {
+		client.Longitude, client.Latitude = input.Longitude, input.Latitude
+	}
+
 	if input.Note != nil {
 		client.Note = input.Note
 	}
@@ -157,19 +340,58 @@ func (app *application) updateClientHandler(w http.ResponseWriter, r *http.Reque
 		client.LogoURL = input.LogoURL
 	}
 
+	if input.CustomFields != nil {
+		client.CustomFields = client.CustomFields.Merge(input.CustomFields)
+	}
+
+	// A parent_id of 0 makes the client top-level again.
+	if input.ParentID != nil {
+		client.ParentID = input.ParentID
+		if *input.ParentID == 0 {
+			client.ParentID = nil
+		}
+	}
+
 	v := validator.New()
+	data.ValidateClient(v, client)
 
-	if data.ValidateClient(v, client); !v.Valid() {
+	err = app.validateCustomValues(r, v, "client", client.CustomFields)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.checkClientParent(r, v, client)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
+	if input.Address != nil && input.Longitude == nil && input.Latitude == nil {
+		app.geocodeClient(client)
+	}
+
 	err = app.models.Client.Update(client)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrClientCycle):
+			v.AddError("parent_id", "must not be one of the client's own divisions")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
 
+	app.cache.Invalidate("client:")
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"client": client}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -183,7 +405,24 @@ func (app *application) deleteClientHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err = app.models.Client.Delete(id)
+	qs := r.URL.Query()
+	v := validator.New()
+
+	mode := app.readString(qs, "mode", data.ClientDeleteRestrict)
+	reassignTo := app.readInt(qs, "reassign_to", 0, v)
+
+	v.Check(validator.PermittedValue(mode, data.ClientDeleteRestrict, data.ClientDeleteDetach, data.ClientDeleteReassign), "mode", "must be restrict, detach or reassign")
+	if mode == data.ClientDeleteReassign {
+		v.Check(reassignTo > 0, "reassign_to", "must be provided in reassign mode")
+		v.Check(reassignTo != int(id), "reassign_to", "must not be the client being deleted")
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	_, err = app.client(r, id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -194,8 +433,42 @@ func (app *application) deleteClientHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "client successfully deleted"}, nil)
+	if mode == data.ClientDeleteReassign {
+		_, err = app.client(r, int32(reassignTo))
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("reassign_to", "must be an existing client")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+
+	projectIDs, err := app.models.Client.Delete(id, mode, int32(reassignTo))
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrClientInUse):
+			app.clientInUseResponse(w, r, projectIDs)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
 	}
+
+	app.cache.Invalidate("client:")
+
+	deleted := deletedResource{Resource: "client", ID: id}
+	switch mode {
+	case data.ClientDeleteDetach:
+		deleted.Cascade = map[string]int{"projects_detached": len(projectIDs)}
+	case data.ClientDeleteReassign:
+		deleted.Cascade = map[string]int{"projects_reassigned": len(projectIDs)}
+	}
+
+	app.deletedResponse(w, r, "client successfully deleted", deleted)
 }
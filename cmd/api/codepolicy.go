@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/codefmt"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// codePolicy returns the code policy of the caller's organization, or of the
+// default organization new projects go to for anonymous callers.
+func (app *application) codePolicy(r *http.Request) (*data.CodePolicy, error) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	orgID := actor.OrgID
+	if orgID == 0 {
+		orgID = data.DefaultOrganizationID
+	}
+
+	return app.models.CodePolicy.Get(orgID)
+}
+
+// validateCodes checks new project and proposal codes, either of which may
+// be nil, against the caller's code policy. Existing codes are left alone,
+// so callers only pass the codes a request changes.
+func (app *application) validateCodes(v *validator.Validator, r *http.Request, projectID *int32, proposalID *string) error {
+	policy, err := app.codePolicy(r)
+	if err != nil {
+		return err
+	}
+
+	if projectID != nil {
+		checkCode(v, "project_id", policy.ProjectFormat, strconv.Itoa(int(*projectID)))
+	}
+
+	if proposalID != nil {
+		checkCode(v, "proposal_id", policy.ProposalFormat, *proposalID)
+	}
+
+	return nil
+}
+
+func checkCode(v *validator.Validator, key, format, code string) {
+	if format == "" {
+		return
+	}
+
+	// Formats are validated when saved.
+	f, err := codefmt.Parse(format)
+	if err != nil {
+		return
+	}
+
+	if !f.Match(code) {
+		example, _ := f.Next(time.Now(), nil)
+		v.AddError(key, fmt.Sprintf("must follow the format %s, such as %s", format, example))
+	}
+}
+
+// showNextCodesHandler suggests the next free project and proposal codes
+// under the caller's code policy, or under the formats given in the query
+// string to preview them before saving. A code is null when its format is
+// empty or has no sequence numbers left this year.
+func (app *application) showNextCodesHandler(w http.ResponseWriter, r *http.Request) {
+	policy, err := app.codePolicy(r)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	qs := r.URL.Query()
+	policy.ProjectFormat = app.readString(qs, "project_format", policy.ProjectFormat)
+	policy.ProposalFormat = app.readString(qs, "proposal_format", policy.ProposalFormat)
+
+	v := validator.New()
+	if data.ValidateCodePolicy(v, policy); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	next := struct {
+		ProjectID  *int32  `json:"project_id"`
+		ProposalID *string `json:"proposal_id"`
+	}{}
+
+	if policy.ProjectFormat != "" {
+		codes, err := app.models.CodePolicy.GetProjectCodes()
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		code, ok := nextCode(policy.ProjectFormat, codes)
+		if ok {
+			id, _ := strconv.ParseInt(code, 10, 32)
+			projectID := int32(id)
+			next.ProjectID = &projectID
+		}
+	}
+
+	if policy.ProposalFormat != "" {
+		codes, err := app.models.CodePolicy.GetProposalCodes()
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		code, ok := nextCode(policy.ProposalFormat, codes)
+		if ok {
+			next.ProposalID = &code
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"next": next, "policy": policy}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// projectIDReservation is how long a reserved project_id is kept from
+// being handed out again.
+const projectIDReservation = 24 * time.Hour
+
+// reserveProjectIDHandler hands out the next project_id under the caller's
+// code policy, following the highest in use when the policy has no project
+// format, and reserves it so concurrent callers never get the same one.
+func (app *application) reserveProjectIDHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	policy, err := app.codePolicy(r)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var format *codefmt.Format
+	if policy.ProjectFormat != "" {
+		format, err = codefmt.Parse(policy.ProjectFormat)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	var userID *int32
+	if actor.UserID != 0 {
+		userID = &actor.UserID
+	}
+
+	expiresAt := time.Now().Add(projectIDReservation).Truncate(time.Second)
+
+	id, err := app.models.Project.ReserveExternalID(format, userID, expiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, codefmt.ErrExhausted):
+			app.errorResponse(w, r, http.StatusConflict, fmt.Sprintf("no project_id left this year under the format %s", policy.ProjectFormat))
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"project_id": id, "expires_at": expiresAt}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func nextCode(format string, codes []string) (string, bool) {
+	f, err := codefmt.Parse(format)
+	if err != nil {
+		return "", false
+	}
+
+	code, err := f.Next(time.Now(), codes)
+	return code, err == nil
+}
+
+func (app *application) showCodePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	policy, err := app.models.CodePolicy.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"code_policy": policy}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateCodePolicyHandler changes the formats given in the body. Codes
+// already in use are kept whatever the new formats.
+func (app *application) updateCodePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	policy, err := app.models.CodePolicy.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		ProjectFormat  *string `json:"project_format"`
+		ProposalFormat *string `json:"proposal_format"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.ProjectFormat != nil {
+		policy.ProjectFormat = *input.ProjectFormat
+	}
+	if input.ProposalFormat != nil {
+		policy.ProposalFormat = *input.ProposalFormat
+	}
+
+	v := validator.New()
+	if data.ValidateCodePolicy(v, policy); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.CodePolicy.Update(policy)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"code_policy": policy}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
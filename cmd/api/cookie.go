@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// refreshCookieName is the cookie browsers keep the authentication token
+// in, for exchanging it for access tokens without exposing it to scripts.
+const refreshCookieName = "refresh_token"
+
+var cookieSameSite = map[string]http.SameSite{
+	"strict": http.SameSiteStrictMode,
+	"lax":    http.SameSiteLaxMode,
+	"none":   http.SameSiteNoneMode,
+}
+
+// validateCookieConfig rejects cookie attributes browsers would refuse.
+// Outside development cookies must be Secure, which SameSite=None and
+// Partitioned require anyway.
+func validateCookieConfig(cfg config) error {
+	if _, ok := cookieSameSite[cfg.cookie.sameSite]; !ok {
+		return errors.New("cookie-samesite must be one of strict, lax or none")
+	}
+
+	if !cfg.cookie.secure && cfg.env != "development" {
+		return errors.New("cookie-secure may only be disabled in development")
+	}
+
+	return nil
+}
+
+// newCookie returns a cookie with the configured attributes, deleting it
+// when maxAge is negative. In development, requests over plain HTTP, such as
+// from http://localhost, get a cookie browsers accept there: not Secure, and
+// so neither SameSite=None nor Partitioned, which require it.
+func (app *application) newCookie(r *http.Request, name, value string, maxAge int) *http.Cookie {
+	cfg := app.config.cookie
+
+	cookie := &http.Cookie{
+		Name:        name,
+		Value:       value,
+		Path:        "/v1/token",
+		Domain:      cfg.domain,
+		MaxAge:      maxAge,
+		HttpOnly:    true,
+		Secure:      cfg.secure,
+		SameSite:    cookieSameSite[cfg.sameSite],
+		Partitioned: cfg.partitioned,
+	}
+
+	insecure := r.TLS == nil && r.Header.Get("X-Forwarded-Proto") != "https"
+
+	if !cookie.Secure || app.config.env == "development" && insecure {
+		cookie.Secure = false
+		cookie.Partitioned = false
+		if cookie.SameSite == http.SameSiteNoneMode {
+			cookie.SameSite = http.SameSiteLaxMode
+		}
+	}
+
+	return cookie
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) createCustomFieldHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Entity   string `json:"entity"`
+		Name     string `json:"name"`
+		DataType string `json:"data_type"`
+		Required bool   `json:"required"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	field := &data.CustomField{
+		Entity:   input.Entity,
+		Name:     input.Name,
+		DataType: input.DataType,
+		Required: input.Required,
+		OrgID:    actor.OrgID,
+	}
+
+	v := validator.New()
+	if data.ValidateCustomField(v, field); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.CustomField.Insert(field)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateCustomField):
+			v.AddError("name", fmt.Sprintf("a %s custom field with this name already exists", field.Entity))
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/custom-field/%d", field.InternalID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"custom_field": field}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listCustomFieldHandler(w http.ResponseWriter, r *http.Request) {
+	entity := app.readString(r.URL.Query(), "entity", "")
+
+	v := validator.New()
+	if entity != "" {
+		v.Check(validator.PermittedValue(entity, data.CustomFieldEntities...), "entity", "must be one of project or client")
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	fields, err := app.models.CustomField.GetAll(actor, entity)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"custom_fields": fields}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// customField returns the field definition with the ID if it belongs to the
+// request's actor's organization, and ErrRecordNotFound otherwise.
+func (app *application) customField(r *http.Request, id int32) (*data.CustomField, error) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return app.models.CustomField.Get(actor, id)
+}
+
+func (app *application) showCustomFieldHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	field, err := app.customField(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"custom_field": field}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateCustomFieldHandler renames a field or changes whether it is required.
+// The entity and data type are fixed once values may have been stored.
+func (app *application) updateCustomFieldHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	field, err := app.customField(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, field.OrgID) {
+		return
+	}
+
+	var input struct {
+		Name     *string `json:"name"`
+		Required *bool   `json:"required"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	oldName := field.Name
+
+	if input.Name != nil {
+		field.Name = *input.Name
+	}
+
+	if input.Required != nil {
+		field.Required = *input.Required
+	}
+
+	v := validator.New()
+	if data.ValidateCustomField(v, field); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.CustomField.Update(field, oldName)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateCustomField):
+			v.AddError("name", fmt.Sprintf("a %s custom field with this name already exists", field.Entity))
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cache.Invalidate("client:")
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"custom_field": field}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteCustomFieldHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	field, err := app.customField(r, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, field.OrgID) {
+		return
+	}
+
+	err = app.models.CustomField.Delete(field)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cache.Invalidate("client:")
+
+	app.deletedResponse(w, r, "custom field successfully deleted", deletedResource{Resource: "custom_field", ID: id})
+}
+
+// validateCustomValues checks values against the custom field definitions of
+// entity in the request's actor's organization, adding any problems to v.
+func (app *application) validateCustomValues(r *http.Request, v *validator.Validator, entity string, values data.CustomValues) error {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return err
+	}
+
+	fields, err := app.models.CustomField.GetAll(actor, entity)
+	if err != nil {
+		return err
+	}
+
+	data.ValidateCustomValues(v, fields, values)
+	return nil
+}
+
+// readCustomFieldFilter reads cf.{name}=value query parameters into values of
+// the types the entity's field definitions in the request's actor's
+// organization declare.
+func (app *application) readCustomFieldFilter(r *http.Request, qs url.Values, entity string, v *validator.Validator) (data.CustomValues, error) {
+	filter := data.CustomValues{}
+
+	params := map[string]string{}
+	for key := range qs {
+		if name, ok := strings.CutPrefix(key, "cf."); ok {
+			params[name] = qs.Get(key)
+		}
+	}
+
+	if len(params) == 0 {
+		return filter, nil
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := app.models.CustomField.GetAll(actor, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := make(map[string]*data.CustomField, len(fields))
+	for _, field := range fields {
+		defined[field.Name] = field
+	}
+
+	for name, s := range params {
+		key := "cf." + name
+
+		field, ok := defined[name]
+		if !ok {
+			v.AddError(key, "is not a defined custom field")
+			continue
+		}
+
+		switch field.DataType {
+		case "number":
+			n, err := strconv.ParseFloat(s, 64)
+			if v.Check(err == nil, key, "must be a number"); err == nil {
+				filter[name] = n
+			}
+		case "boolean":
+			b, err := strconv.ParseBool(s)
+			if v.Check(err == nil, key, "must be true or false"); err == nil {
+				filter[name] = b
+			}
+		case "date":
+			_, err := time.Parse(time.DateOnly, s)
+			if v.Check(err == nil, key, "must be a date in YYYY-MM-DD format"); err == nil {
+				filter[name] = s
+			}
+		default:
+			filter[name] = s
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) createDelegationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		DelegatorID int32  `json:"delegator_id"`
+		DelegateID  int32  `json:"delegate_id"`
+		StartsOn    string `json:"starts_on"`
+		EndsOn      string `json:"ends_on"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Users delegate their own approvals; delegating on behalf of someone
+	// else takes an organization admin.
+	if input.DelegatorID == 0 {
+		input.DelegatorID = actor.UserID
+	}
+	if input.DelegatorID != actor.UserID && !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	v := validator.New()
+
+	delegation := &data.Delegation{
+		DelegatorID: input.DelegatorID,
+		DelegateID:  input.DelegateID,
+	}
+
+	if startsOn := app.parseDate(v, "starts_on", input.StartsOn); startsOn != nil {
+		delegation.StartsOn = *startsOn
+	}
+	if endsOn := app.parseDate(v, "ends_on", input.EndsOn); endsOn != nil {
+		delegation.EndsOn = *endsOn
+	}
+
+	if data.ValidateDelegation(v, delegation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Delegation.Insert(actor, delegation)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("delegate_id", "delegator and delegate must be existing users")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/delegation/%d", delegation.InternalID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"delegation": delegation}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listDelegationHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	userID := int32(app.readInt(r.URL.Query(), "user_id", int(actor.UserID), v))
+	if v.Check(userID > 0, "user_id", "must be a positive integer"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if userID != actor.UserID && !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	delegations, err := app.models.Delegation.GetAll(actor, userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"delegations": delegations}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteDelegationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	delegation, err := app.models.Delegation.Get(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Either side may end a delegation.
+	if delegation.DelegatorID != actor.UserID && delegation.DelegateID != actor.UserID && !isOrgAdmin(actor, actor.OrgID) {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	err = app.models.Delegation.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "delegation successfully deleted", deletedResource{Resource: "delegation", ID: id})
+}
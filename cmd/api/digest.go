@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// runDigest sends the digests that have come due each time the digest job
+// runs.
+func (app *application) runDigest() {
+	if !app.emailEnabled() {
+		return
+	}
+
+	app.runScheduled("digest", func() {
+		sent, err := app.sendDigests(time.Now())
+		if err != nil {
+			app.logger.Error("digest run failed", "error", err.Error())
+			return
+		}
+
+		if sent > 0 {
+			app.logger.Info("digests sent", "count", sent)
+		}
+	})
+}
+
+// sendDigests emails every recipient whose digest is due at now and returns
+// how many were sent. A digest with nothing to report is skipped but still
+// counts as sent, so the next one covers the following period only.
+func (app *application) sendDigests(now time.Time) (int, error) {
+	recipients, err := app.models.Digest.GetDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+
+	for _, recipient := range recipients {
+		since := now.AddDate(0, 0, -1)
+		if recipient.Frequency == data.DigestWeekly {
+			since = now.AddDate(0, 0, -7)
+		}
+		if recipient.LastSentAt != nil {
+			since = *recipient.LastSentAt
+		}
+
+		digest, err := app.models.Digest.Build(recipient.UserID, since, app.config.digest.budgetWarning)
+		if err != nil {
+			app.logger.Error("digest failed", "user_id", recipient.UserID, "error", err.Error())
+			continue
+		}
+
+		if !digest.Empty() {
+			err = app.userMailer(recipient.UserID).Send(recipient.Email, "digest.tmpl", map[string]any{
+				"firstName": recipient.FirstName,
+				"frequency": recipient.Frequency,
+				"digest":    digest,
+			})
+			if err != nil {
+				app.logger.Error("digest failed", "user_id", recipient.UserID, "error", err.Error())
+				continue
+			}
+			sent++
+		}
+
+		err = app.models.Digest.MarkSent(recipient.UserID, now)
+		if err != nil {
+			app.logger.Error("digest failed", "user_id", recipient.UserID, "error", err.Error())
+		}
+	}
+
+	return sent, nil
+}
+
+func (app *application) showDigestPreferenceHandler(w http.ResponseWriter, r *http.Request) {
+	preference, err := app.models.Digest.GetPreference(app.contextGetUser(r).InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"digest": preference}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateDigestPreferenceHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Frequency string `json:"frequency"`
+		Weekday   *int   `json:"weekday"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	preference := &data.DigestPreference{
+		Frequency: input.Frequency,
+		Weekday:   int(time.Monday),
+	}
+	if input.Weekday != nil {
+		preference.Weekday = *input.Weekday
+	}
+
+	v := validator.New()
+	if data.ValidateDigestPreference(v, preference); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Digest.SetPreference(app.contextGetUser(r).InternalID, preference)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"digest": preference}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
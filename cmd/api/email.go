@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/mailer"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+var emailPreviewData = map[string]any{
+	"activationToken":  "Y3QMGX3PJ3WLRL2YRTQGQ6KRHU",
+	"calendarToken":    "Y3QMGX3PJ3WLRL2YRTQGQ6KRHU",
+	"emailChangeToken": "Y3QMGX3PJ3WLRL2YRTQGQ6KRHU",
+	"newEmail":         "jane.doe@example.com",
+	"organizationName": "Default",
+	"downloadURL":      "https://example.com/exports/1/20260101T000000Z.zip",
+	"firstName":        "Jane",
+	"frequency":        data.DigestWeekly,
+	"category":         data.NotificationApproval,
+	"message":          "Timesheet entry of 2 Jan 2026 on Otester Bridge Inspection awaits your approval",
+	"digest": &data.Digest{
+		PendingApprovals: 4,
+		PendingMinutes:   960,
+		Projects: []data.DigestProject{
+			{ProjectID: 2301, Name: "Otester Bridge Inspection", Minutes: 1830},
+			{ProjectID: 2288, Name: "Harbour Front Survey", Minutes: 45},
+		},
+		BudgetWarnings: []data.DigestBudgetWarning{
+			{ProjectID: 2301, Name: "Otester Bridge Inspection", BudgetMinutes: 12000, LoggedMinutes: 10560},
+		},
+	},
+	"projects": []data.HealthAlertProject{
+		{ProjectID: 2301, Name: "Otester Bridge Inspection", Health: &data.ProjectHealth{
+			Status:  data.HealthRed,
+			Reasons: []string{"104% of the budget is used", "2 milestones are overdue"},
+		}},
+	},
+}
+
+func (app *application) emailPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "template")
+
+	templates, err := mailer.Templates()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	templateFile := name + ".tmpl"
+	if !validator.PermittedValue("templates/"+templateFile, templates...) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	qs := r.URL.Query()
+	lang := app.readString(qs, "lang", mailer.DefaultLanguage)
+	format := app.readString(qs, "format", "html")
+
+	v := validator.New()
+	orgID := app.readInt(qs, "organization_id", 0, v)
+	v.Check(validator.PermittedValue(format, "html", "text", "json"), "format", "must be one of html, text or json")
+	v.Check(path.Base(lang) == lang && !strings.Contains(lang, "."), "lang", "invalid language code")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Previews take an administrator of the organization whose branding is
+	// shown, or of their own when none is given.
+	previewOrgID := actor.OrgID
+	if orgID > 0 {
+		previewOrgID = int32(orgID)
+	}
+	if !app.requireOrgAdmin(w, r, previewOrgID) {
+		return
+	}
+
+	// Preview an organization's branding when one is given.
+	var b *mailer.Branding
+	if orgID > 0 {
+		settings, err := app.models.OrgSettings.Get(int32(orgID))
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		b = branding(settings)
+	}
+
+	email, err := mailer.RenderBranded(templateFile, lang, b, emailPreviewData)
+	if err != nil {
+		switch {
+		case errors.Is(err, mailer.ErrTemplateNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logo, err := mailer.Logo()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Browsers cannot resolve cid: references, so inline the logo for preview.
+	htmlBody := strings.ReplaceAll(email.HTMLBody, "cid:logo.png", "data:image/png;base64,"+base64.StdEncoding.EncodeToString(logo))
+
+	switch format {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(email.PlainBody))
+	case "json":
+		err = app.writeJSON(w, http.StatusOK, envelope{"email": envelope{
+			"subject":    email.Subject,
+			"plain_body": email.PlainBody,
+			"html_body":  email.HTMLBody,
+		}}, nil)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(htmlBody))
+	}
+}
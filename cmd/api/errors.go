@@ -3,6 +3,10 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (app *application) logError(r *http.Request, err error) {
@@ -11,7 +15,7 @@ func (app *application) logError(r *http.Request, err error) {
 		uri    = r.URL.RequestURI()
 	)
 
-	app.logger.Error(err.Error(), "method", method, "uri", uri)
+	app.requestLogger(r).Error(err.Error(), "method", method, "uri", uri)
 }
 
 func (app *application) errorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
@@ -24,11 +28,52 @@ func (app *application) errorResponse(w http.ResponseWriter, r *http.Request, st
 	}
 }
 
+// logCrash writes a crash record for a recovered panic: the panic value,
+// the goroutine stack and enough of the request to reproduce it.
+func (app *application) logCrash(r *http.Request, err error, stack []byte) {
+	attrs := []any{
+		"method", r.Method,
+		"uri", r.URL.RequestURI(),
+		"remote_addr", r.RemoteAddr,
+		"user_agent", r.UserAgent(),
+		"stack", string(stack),
+	}
+
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		attrs = append(attrs, "route", rctx.RoutePattern())
+	}
+
+	if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+		attrs = append(attrs, "trace_id", sc.TraceID().String())
+	}
+
+	if user := app.contextGetUser(r); !user.IsAnonymous() {
+		attrs = append(attrs, "user_id", user.InternalID)
+	}
+
+	app.requestLogger(r).Error("panic: "+err.Error(), attrs...)
+}
+
 func (app *application) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	app.logError(r, err)
+	app.reportError(r, err)
+	app.internalErrorResponse(w, r)
+}
+
+// internalErrorResponse sends the generic 500 body. It carries the request
+// ID so users can quote it in support tickets.
+func (app *application) internalErrorResponse(w http.ResponseWriter, r *http.Request) {
+	env := envelope{"error": "the server encountered a problem and could not process your request"}
+
+	if id, ok := r.Context().Value(requestIDContextKey).(string); ok {
+		env["request_id"] = id
+	}
 
-	message := "the server encountered a problem and could not process your request"
-	app.errorResponse(w, r, http.StatusInternalServerError, message)
+	err := app.writeJSON(w, http.StatusInternalServerError, env, nil)
+	if err != nil {
+		app.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
 }
 
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request) {
@@ -37,6 +82,8 @@ func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request)
 }
 
 func (app *application) methodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", strings.Join(app.allowedMethods(r), ", "))
+
 	message := fmt.Sprintf("the %s method is not supported for this resource", r.Method)
 	app.errorResponse(w, r, http.StatusMethodNotAllowed, message)
 }
@@ -62,3 +109,82 @@ func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http
 	message := "rate limit exceeded"
 	app.errorResponse(w, r, http.StatusTooManyRequests, message)
 }
+
+func (app *application) projectArchivedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the project is archived and cannot be modified"
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
+
+func (app *application) featureNotConfiguredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "this feature is not configured on the server"
+	app.errorResponse(w, r, http.StatusServiceUnavailable, message)
+}
+
+// subsystemDisabledResponse answers requests needing a subsystem, such as
+// email, that the server was started without.
+func (app *application) subsystemDisabledResponse(w http.ResponseWriter, r *http.Request, subsystem string) {
+	message := fmt.Sprintf("%s is not configured on this server", subsystem)
+	app.errorResponse(w, r, http.StatusNotImplemented, message)
+}
+
+func (app *application) storageQuotaExceededResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the project storage quota has been exceeded"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (app *application) invalidAuthenticationTokenResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "invalid or missing authentication token"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (app *application) invalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	message := "invalid authentication credentials"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (app *application) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you must be authenticated to access this resource"
+	app.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+// projectHasDependentsResponse refuses to delete a project that records
+// still refer to, listing how many of each there are.
+func (app *application) projectHasDependentsResponse(w http.ResponseWriter, r *http.Request, dependents map[string]int) {
+	env := envelope{
+		"error":      "the project has timesheet entries, delete it with force=true to proceed",
+		"dependents": dependents,
+	}
+
+	err := app.writeJSON(w, http.StatusConflict, env, nil)
+	if err != nil {
+		app.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// clientInUseResponse refuses to delete a client in restrict mode, listing
+// the projects linked to it.
+func (app *application) clientInUseResponse(w http.ResponseWriter, r *http.Request, projectIDs []int32) {
+	env := envelope{
+		"error":    "the client is linked to projects, delete it with mode=detach or mode=reassign to proceed",
+		"projects": projectIDs,
+	}
+
+	err := app.writeJSON(w, http.StatusConflict, env, nil)
+	if err != nil {
+		app.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (app *application) timesheetStatusConflictResponse(w http.ResponseWriter, r *http.Request, status string) {
+	message := fmt.Sprintf("the timesheet entry cannot be changed this way while it is %s", status)
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
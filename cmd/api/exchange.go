@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) showExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	from := strings.ToUpper(app.readString(qs, "from", ""))
+	to := strings.ToUpper(app.readString(qs, "to", app.config.fx.base))
+
+	v := validator.New()
+	data.ValidateCurrency(v, "from", from)
+	data.ValidateCurrency(v, "to", to)
+
+	date := time.Now()
+	if s := app.readString(qs, "date", ""); s != "" {
+		if d := app.parseDate(v, "date", s); d != nil {
+			date = *d
+		}
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rate, err := app.models.ExchangeRate.Get(from, to, date)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"exchange_rate": rate}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
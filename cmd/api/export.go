@@ -0,0 +1,155 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// exportLinkTTL is the longest lifetime S3 allows for a presigned URL.
+const exportLinkTTL = 7 * 24 * time.Hour
+
+// createFullExportHandler exports the caller's organization, which they must
+// administer, and emails them a link to the archive. The link gives anyone
+// holding it the organization's data, so it is only sent to the caller's own
+// verified address.
+func (app *application) createFullExportHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Format string `json:"format"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Format == "" {
+		input.Format = "json"
+	}
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(input.Format, "json", "csv"), "format", "must be one of json or csv"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	user := app.contextGetUser(r)
+	if !user.Activated {
+		app.errorResponse(w, r, http.StatusForbidden, "your email address must be verified to receive an export")
+		return
+	}
+
+	org, err := app.models.Organization.Get(actor.OrgID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.background(func() {
+		err := app.exportOrganization(org, user, input.Format)
+		if err != nil {
+			app.logger.Error("export failed", "organization_id", org.InternalID, "error", err.Error())
+		}
+	})
+
+	env := envelope{"message": "the export is being prepared, a download link will be emailed to you when it is ready"}
+
+	err = app.writeJSON(w, http.StatusAccepted, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// exportOrganization writes every export entity of org into a zip archive,
+// uploads it to S3 and emails recipient a presigned link to it.
+func (app *application) exportOrganization(org *data.Organization, recipient *data.User, format string) error {
+	archive, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	zw := zip.NewWriter(archive)
+
+	for _, entity := range data.ExportEntities {
+		f, err := zw.Create(entity.Name + "." + format)
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case "csv":
+			err = app.models.Export.WriteCSV(f, entity.Query, org.InternalID)
+		default:
+			err = app.models.Export.WriteJSON(f, entity.Query, org.InternalID)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", entity.Name, err)
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = archive.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("exports/%d/%s.zip", org.InternalID, time.Now().UTC().Format("20060102T150405Z"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	_, err = app.s3actor.uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(app.config.s3.bucket),
+		Key:         aws.String(key),
+		Body:        archive,
+		ContentType: aws.String("application/zip"),
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := app.s3actor.presignClient.PresignGetObject(
+		ctx,
+		&s3.GetObjectInput{
+			Bucket: aws.String(app.config.s3.bucket),
+			Key:    aws.String(key),
+		}, func(opts *s3.PresignOptions) {
+			opts.Expires = exportLinkTTL
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"organizationName": org.Name,
+		"downloadURL":      request.URL,
+	}
+
+	return app.userMailer(recipient.InternalID).Send(recipient.Email, "export_ready.tmpl", data)
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/s3action"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+type fileKeysInput struct {
+	Keys []string `json:"keys"`
+}
+
+func validateFileKeys(v *validator.Validator, input *fileKeysInput) {
+	v.Check(len(input.Keys) >= 1, "keys", "must contain at least 1 key")
+	v.Check(len(input.Keys) <= 1000, "keys", "must not contain more than 1000 keys")
+	v.Check(validator.Unique(input.Keys), "keys", "must not contain duplicate values")
+
+	for _, key := range input.Keys {
+		v.Check(key != "", "keys", "must not contain empty keys")
+	}
+}
+
+// visibleProjectIDs returns the IDs of the projects that keys are stored
+// under and the request's actor may see.
+func (app *application) visibleProjectIDs(r *http.Request, keys []string) (map[int32]bool, error) {
+	var ids []int32
+	for _, key := range keys {
+		if id, ok := projectIDFromKey(key); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	visible := make(map[int32]bool)
+	if len(ids) == 0 {
+		return visible, nil
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := app.models.Project.GetByIDs(actor, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, project := range projects {
+		visible[*project.ExternalID] = true
+	}
+
+	return visible, nil
+}
+
+// checkFileKeys checks that every key is stored under a project the
+// request's actor may see.
+func (app *application) checkFileKeys(r *http.Request, v *validator.Validator, keys []string) error {
+	visible, err := app.visibleProjectIDs(r, keys)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		id, ok := projectIDFromKey(key)
+		v.Check(ok && visible[id], "keys", "must only contain files of projects you can access")
+	}
+
+	return nil
+}
+
+func (app *application) deleteFilesHandler(w http.ResponseWriter, r *http.Request) {
+	var input fileKeysInput
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if validateFileKeys(v, &input); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.checkFileKeys(r, v, input.Keys)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	objects := make([]types.ObjectIdentifier, 0, len(input.Keys))
+	for _, key := range input.Keys {
+		objects = append(objects, types.ObjectIdentifier{Key: &key})
+	}
+
+	err = s3action.DeleteObjects(r.Context(), app.s3actor.client, app.config.s3.bucket, objects)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.File.DeleteByKeys(input.Keys)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"trashed": input.Keys}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listTrashHandler(w http.ResponseWriter, r *http.Request) {
+	prefix := app.readString(r.URL.Query(), "prefix", "")
+
+	deleteMarkers, err := s3action.ListTrash(app.s3actor.client, app.config.s3.bucket, prefix)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	keys := make([]string, 0, len(deleteMarkers))
+	for _, deleteMarker := range deleteMarkers {
+		keys = append(keys, *deleteMarker.Key)
+	}
+
+	visible, err := app.visibleProjectIDs(r, keys)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	type trashedFile struct {
+		Key        string     `json:"key"`
+		VersionID  string     `json:"version_id"`
+		DeletedAt  time.Time  `json:"deleted_at"`
+		PurgeAfter *time.Time `json:"purge_after"`
+	}
+
+	files := []trashedFile{}
+	for _, deleteMarker := range deleteMarkers {
+		// Only files of projects the caller can see are listed.
+		if id, ok := projectIDFromKey(*deleteMarker.Key); !ok || !visible[id] {
+			continue
+		}
+
+		file := trashedFile{
+			Key:       *deleteMarker.Key,
+			VersionID: *deleteMarker.VersionId,
+			DeletedAt: *deleteMarker.LastModified,
+		}
+		if app.config.s3.trashRetention > 0 {
+			purgeAfter := deleteMarker.LastModified.Add(app.config.s3.trashRetention)
+			file.PurgeAfter = &purgeAfter
+		}
+		files = append(files, file)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"files": files}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) restoreFilesHandler(w http.ResponseWriter, r *http.Request) {
+	var input fileKeysInput
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if validateFileKeys(v, &input); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.checkFileKeys(r, v, input.Keys)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	restored, err := s3action.RestoreObjects(r.Context(), app.s3actor.client, app.config.s3.bucket, input.Keys)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if restored == nil {
+		restored = []string{}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"restored": restored}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) completeUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Key        string  `json:"key"`
+		SHA256     string  `json:"sha256"`
+		Category   *string `json:"category"`
+		Supersedes *int64  `json:"supersedes"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Key != "", "key", "must be provided")
+	externalID, ok := projectIDFromKey(input.Key)
+	v.Check(ok, "key", "must be stored under a project_id/ prefix")
+	data.ValidateChecksumSHA256(v, input.SHA256)
+	if input.Category != nil {
+		data.ValidateCategory(v, *input.Category)
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	project, err := app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("key", fmt.Sprintf("project %d does not exist", externalID))
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
+	if input.Supersedes != nil {
+		previous, err := app.models.File.Get(*input.Supersedes)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("supersedes", "file does not exist")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		v.Check(previous.ProjectID == externalID, "supersedes", "must belong to the same project")
+		v.Check(previous.Key != input.Key, "supersedes", "must be a different file")
+		if !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		// A new version stays in its document's category unless moved.
+		if input.Category == nil {
+			input.Category = previous.Category
+		}
+	}
+
+	ctx := r.Context()
+	bucket := app.config.s3.bucket
+	checksum := strings.ToLower(input.SHA256)
+
+	info, err := s3action.GetObjectInfo(ctx, app.s3actor.client, bucket, input.Key)
+	if err != nil {
+		var apiErr smithy.APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.ErrorCode() == "NotFound":
+			v.AddError("key", "no uploaded object exists for this key")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var scannedAt *time.Time
+
+	// S3 only keeps a SHA-256 checksum when the upload sent one. Otherwise,
+	// or when the content has to be scanned anyway, hash the object here.
+	if info.ChecksumSHA256 != "" && app.scanner == nil {
+		sum, _ := hex.DecodeString(checksum)
+		if info.ChecksumSHA256 != base64.StdEncoding.EncodeToString(sum) {
+			v.AddError("sha256", "does not match the uploaded object")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	} else {
+		obj, err := s3action.GetObject(ctx, app.s3actor.client, bucket, input.Key)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		defer obj.Body.Close()
+
+		hash := sha256.New()
+		body := io.TeeReader(obj.Body, hash)
+
+		if app.scanner != nil {
+			clean, signature, err := app.scanner.Scan(ctx, body)
+			if err != nil {
+				app.serverErrorResponse(w, r, fmt.Errorf("virus scan failed: %w", err))
+				return
+			}
+
+			if !clean {
+				app.requestLogger(r).Warn("infected upload quarantined", "key", input.Key, "signature", signature)
+
+				err = s3action.DeleteObjects(ctx, app.s3actor.client, bucket, []types.ObjectIdentifier{{Key: &input.Key}})
+				if err != nil {
+					app.serverErrorResponse(w, r, err)
+					return
+				}
+
+				v.AddError("key", "the uploaded file failed the virus scan and was removed")
+				app.failedValidationResponse(w, r, v.Errors)
+				return
+			}
+
+			now := time.Now()
+			scannedAt = &now
+		}
+
+		_, err = io.Copy(io.Discard, body)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if hex.EncodeToString(hash.Sum(nil)) != checksum {
+			v.AddError("sha256", "does not match the uploaded object")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	mimeType := info.ContentType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	file := &data.File{
+		Key:               input.Key,
+		ProjectID:         externalID,
+		ProjectInternalID: project.InternalID,
+		SizeBytes:         info.Size,
+		MimeType:          mimeType,
+		ChecksumSHA256:    &checksum,
+		Category:          input.Category,
+		SupersedesID:      input.Supersedes,
+		ScannedAt:         scannedAt,
+	}
+
+	err = app.models.File.Upsert(file)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.updateProjectStorage(externalID)
+	if err != nil {
+		app.logError(r, err)
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"file": file}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listProjectFilesHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	files, err := app.models.File.GetAllForProject(externalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"base_url": app.fileBaseURL(), "files": files}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listProjectDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	category := app.readString(r.URL.Query(), "category", "")
+
+	v := validator.New()
+	if category != "" {
+		if data.ValidateCategory(v, category); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	files, err := app.models.File.GetLatestDocuments(externalID, category)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	documents := make(map[string][]*data.File)
+	for _, file := range files {
+		documents[*file.Category] = append(documents[*file.Category], file)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"base_url": app.fileBaseURL(), "documents": documents}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listFileVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt64IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	versions, err := app.models.File.GetVersions(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if len(versions) == 0 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Every version belongs to the same project.
+	_, err = app.project(r, versions[0].ProjectID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"versions": versions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) downloadFileHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt64IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	file, err := app.models.File.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	_, err = app.project(r, file.ProjectID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	request, err := app.presignGetObject(r.Context(), file.Key)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Couldn't get a presigned request to get %s: %v", file.Key, err))
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"file": file, "presigned": request}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
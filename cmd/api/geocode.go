@@ -1,12 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/geocode"
+	"github.com/hwanbin/wanpm-api/internal/s3action"
+	"github.com/hwanbin/wanpm-api/internal/validator"
 )
 
 func (app *application) forwardGeocodeHandler(w http.ResponseWriter, r *http.Request) {
@@ -23,16 +35,15 @@ func (app *application) forwardGeocodeHandler(w http.ResponseWriter, r *http.Req
 	geocodeURL := "https://api.mapbox.com/search/geocode/v6/forward"
 	requestURL := fmt.Sprintf("%s?q=%s&proximity=%s&access_token=%s", geocodeURL, t.String(), proximity, accessToken)
 
-	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, requestURL, nil)
 	if err != nil {
-		// add to error bag
-		fmt.Printf("client: could not create request: %s\n", err)
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		fmt.Printf("client: error making http request: %s\n", err)
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
@@ -40,7 +51,7 @@ func (app *application) forwardGeocodeHandler(w http.ResponseWriter, r *http.Req
 
 	err = app.readMapboxJSON(res, &input)
 	if err != nil {
-		fmt.Printf("client: could not read response body: %s\n", err)
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
@@ -49,3 +60,160 @@ func (app *application) forwardGeocodeHandler(w http.ResponseWriter, r *http.Req
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// routeHandler returns the driving distance and time between two
+// longitude,latitude pairs, for example an office and a project site.
+func (app *application) routeHandler(w http.ResponseWriter, r *http.Request) {
+	if app.geocoder == nil {
+		app.featureNotConfiguredResponse(w, r)
+		return
+	}
+
+	qs := r.URL.Query()
+	v := validator.New()
+
+	from, err := data.ConvertToPoint(app.readCSV(qs, "from", nil))
+	v.Check(err == nil, "from", "must be a longitude,latitude pair")
+
+	to, err := data.ConvertToPoint(app.readCSV(qs, "to", nil))
+	v.Check(err == nil, "to", "must be a longitude,latitude pair")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	route, err := app.geocoder.Driving(
+		r.Context(),
+		geocode.Point{from.Longitude, from.Latitude},
+		geocode.Point{to.Longitude, to.Latitude},
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, geocode.ErrNoRoute):
+			v.AddError("to", "cannot be reached by road from from")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := envelope{
+		"route": envelope{
+			"distance_meters":  route.DistanceMeters,
+			"duration_seconds": route.DurationSeconds,
+			"distance_km":      math.Round(route.DistanceMeters/10) / 100,
+		},
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showProjectMapHandler serves a static map image centred on the project
+// location. Rendered images are cached in S3 under a key derived from the
+// coordinates, so moving the project renders a fresh image.
+func (app *application) showProjectMapHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	project, err := app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if project.Feature == nil || len(project.Feature.Geometry.Coordinates) != 2 {
+		app.errorResponse(w, r, http.StatusNotFound, "the project has no location")
+		return
+	}
+
+	center := geocode.Point{project.Feature.Geometry.Coordinates[0], project.Feature.Geometry.Coordinates[1]}
+	key := fmt.Sprintf("%s%.5f,%.5f.png", projectMapPrefix(externalID), center[0], center[1])
+
+	ctx := r.Context()
+	bucket := app.config.s3.bucket
+
+	var image []byte
+
+	obj, err := s3action.GetObject(ctx, app.s3actor.client, bucket, key)
+	switch {
+	case err == nil:
+		defer obj.Body.Close()
+
+		image, err = io.ReadAll(obj.Body)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	default:
+		var noKey *types.NoSuchKey
+		if !errors.As(err, &noKey) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if app.geocoder == nil {
+			app.featureNotConfiguredResponse(w, r)
+			return
+		}
+
+		image, err = app.geocoder.StaticMap(ctx, center)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		_, err = app.s3actor.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(image),
+			ContentType: aws.String("image/png"),
+		})
+		if err != nil {
+			app.requestLogger(r).Error("caching project map failed", "key", key, "error", err.Error())
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	w.Write(image)
+}
+
+// geocodeClient sets a client's coordinates from its address when client
+// geocoding is enabled. A provider failure is logged and leaves the
+// coordinates empty rather than blocking the save.
+func (app *application) geocodeClient(client *data.Client) {
+	if app.geocoder == nil || !app.config.geocode.clients {
+		return
+	}
+
+	client.Longitude, client.Latitude = nil, nil
+
+	if client.Address == nil || *client.Address == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	longitude, latitude, err := app.geocoder.Forward(ctx, *client.Address)
+	if err != nil {
+		app.logger.Error("client geocoding failed", "client_id", client.InternalID, "error", err.Error())
+		return
+	}
+
+	client.Longitude, client.Latitude = &longitude, &latitude
+}
@@ -0,0 +1,554 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const (
+	// graphqlPageSize is the page size of list fields that do not ask for
+	// one. Unlike the REST endpoints, GraphQL lists are always paginated, as
+	// nested lists multiply.
+	graphqlPageSize = 20
+	// graphqlMaxDepth is how deeply a query may nest fields, counting the
+	// top-level field as one. Users and timesheets refer to each other, so
+	// without it a query could recurse as far as it liked.
+	graphqlMaxDepth = 7
+)
+
+func (app *application) graphqlSchema() (graphql.Schema, error) {
+	pointType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Geometry",
+		Fields: graphql.Fields{
+			"type":        &graphql.Field{Type: graphql.String},
+			"coordinates": &graphql.Field{Type: graphql.NewList(graphql.Float)},
+		},
+	})
+
+	propertiesType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Properties",
+		Fields: graphql.Fields{
+			"name":         &graphql.Field{Type: graphql.String},
+			"full_address": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	featureType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Feature",
+		Fields: graphql.Fields{
+			"type":       &graphql.Field{Type: graphql.String},
+			"geometry":   &graphql.Field{Type: pointType},
+			"properties": &graphql.Field{Type: propertiesType},
+		},
+	})
+
+	clientType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Client",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.Int},
+			"name":       &graphql.Field{Type: graphql.String},
+			"address":    &graphql.Field{Type: graphql.String},
+			"logo_url":   &graphql.Field{Type: graphql.String},
+			"note":       &graphql.Field{Type: graphql.String},
+			"longitude":  &graphql.Field{Type: graphql.Float},
+			"latitude":   &graphql.Field{Type: graphql.Float},
+			"version":    &graphql.Field{Type: graphql.Int},
+			"created_at": &graphql.Field{Type: graphql.DateTime},
+			"updated_at": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	proposalType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Proposal",
+		Fields: graphql.Fields{
+			"proposal_id": &graphql.Field{Type: graphql.String},
+			"due_on":      &graphql.Field{Type: graphql.DateTime},
+			"version":     &graphql.Field{Type: graphql.Int},
+			"created_at":  &graphql.Field{Type: graphql.DateTime},
+			"updated_at":  &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	projectType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Project",
+		Fields: graphql.Fields{
+			"project_id":  &graphql.Field{Type: graphql.Int},
+			"proposal_id": &graphql.Field{Type: graphql.String},
+			"name":        &graphql.Field{Type: graphql.String},
+			"status":      &graphql.Field{Type: graphql.String},
+			"feature":     &graphql.Field{Type: featureType},
+			"images":      &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"clients":     &graphql.Field{Type: graphql.NewList(clientType)},
+			"proposal": &graphql.Field{
+				Type: proposalType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					project := p.Source.(*data.ProjectResponse)
+					if project.ProposalID == nil {
+						return nil, nil
+					}
+
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					proposal, err := app.models.Proposal.Get(actor, *project.ProposalID)
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return proposal, err
+				},
+			},
+			"tags":        &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"archived_at": &graphql.Field{Type: graphql.DateTime},
+			"version":     &graphql.Field{Type: graphql.Int},
+			"created_at":  &graphql.Field{Type: graphql.DateTime},
+			"updated_at":  &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.Int},
+			"email":      &graphql.Field{Type: graphql.String},
+			"first_name": &graphql.Field{Type: graphql.String},
+			"last_name":  &graphql.Field{Type: graphql.String},
+			"activated":  &graphql.Field{Type: graphql.Boolean},
+			"avatar_url": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					user := p.Source.(*data.User)
+					app.setAvatarURL(p.Context, user)
+					return user.AvatarURL, nil
+				},
+			},
+			"created_at": &graphql.Field{Type: graphql.DateTime},
+			"updated_at": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	timesheetType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Timesheet",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.ID},
+			"entry_uuid":  &graphql.Field{Type: graphql.String},
+			"user_id":     &graphql.Field{Type: graphql.Int},
+			"project_id":  &graphql.Field{Type: graphql.Int},
+			"activity_id": &graphql.Field{Type: graphql.Int},
+			"user": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					user, err := app.models.User.GetForActor(actor, p.Source.(*data.TimesheetEntry).UserID)
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return user, err
+				},
+			},
+			"project": &graphql.Field{
+				Type: projectType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					project, err := app.models.Project.Get(actor, p.Source.(*data.TimesheetEntry).ExternalProjectID)
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return project, err
+				},
+			},
+			"work_date":      &graphql.Field{Type: graphql.DateTime},
+			"minutes":        &graphql.Field{Type: graphql.Int},
+			"billed_minutes": &graphql.Field{Type: graphql.Int},
+			"note":           &graphql.Field{Type: graphql.String},
+			"tags":           &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"status":         &graphql.Field{Type: graphql.String},
+			"submitted_at":   &graphql.Field{Type: graphql.DateTime},
+			"approver_id":    &graphql.Field{Type: graphql.Int},
+			"version":        &graphql.Field{Type: graphql.Int},
+			"created_at":     &graphql.Field{Type: graphql.DateTime},
+			"updated_at":     &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"page":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"page_size": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: graphqlPageSize},
+	}
+
+	projectListArgs := graphql.FieldConfigArgument{
+		"name":        &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+		"status":      &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+		"client_name": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+		"sort":        &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "project_id"},
+
+		"include_archived": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+	}
+	for name, arg := range pageArgs {
+		projectListArgs[name] = arg
+	}
+
+	clientListArgs := graphql.FieldConfigArgument{
+		"name": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+		"sort": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "internal_id"},
+	}
+	for name, arg := range pageArgs {
+		clientListArgs[name] = arg
+	}
+
+	userTimesheetArgs := graphql.FieldConfigArgument{
+		"project_id": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		"from":       &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+		"to":         &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+		"status":     &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String)},
+		"sort":       &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "-work_date"},
+	}
+	for name, arg := range pageArgs {
+		userTimesheetArgs[name] = arg
+	}
+
+	timesheetListArgs := graphql.FieldConfigArgument{
+		"user_id": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+	}
+	for name, arg := range userTimesheetArgs {
+		timesheetListArgs[name] = arg
+	}
+
+	// A user's timesheets are the timesheets query pinned to that user.
+	userType.AddFieldConfig("timesheets", &graphql.Field{
+		Type: graphql.NewList(timesheetType),
+		Args: userTimesheetArgs,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return app.graphqlTimesheets(p, p.Source.(*data.User).InternalID)
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"project": &graphql.Field{
+				Type: projectType,
+				Args: graphql.FieldConfigArgument{
+					"project_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					project, err := app.models.Project.Get(actor, int32(p.Args["project_id"].(int)))
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return project, err
+				},
+			},
+			"projects": &graphql.Field{
+				Type: graphql.NewList(projectType),
+				Args: projectListArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					var input data.ProjectQsInput
+
+					input.Name = p.Args["name"].(string)
+					input.Status = p.Args["status"].(string)
+					input.ClientName = p.Args["client_name"].(string)
+					input.IncludeArchived = p.Args["include_archived"].(bool)
+					input.Filters = data.Filters{
+						Page:         p.Args["page"].(int),
+						PageSize:     p.Args["page_size"].(int),
+						Sort:         p.Args["sort"].(string),
+						SortSafelist: []string{"project_id", "name", "status", "created_at", "updated_at", "-project_id", "-name", "-status", "-created_at", "-updated_at"},
+					}
+
+					v := validator.New()
+					if validateGraphQLFilters(v, input.Filters); !v.Valid() {
+						return nil, graphqlValidationError(v)
+					}
+
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					projects, _, err := app.models.Project.GetAll(actor, input, data.BoundingBox{})
+					return projects, err
+				},
+			},
+			"client": &graphql.Field{
+				Type: clientType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					client, err := app.models.Client.Get(actor, int32(p.Args["id"].(int)))
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return client, err
+				},
+			},
+			"clients": &graphql.Field{
+				Type: graphql.NewList(clientType),
+				Args: clientListArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					filters := data.Filters{
+						Page:         p.Args["page"].(int),
+						PageSize:     p.Args["page_size"].(int),
+						Sort:         p.Args["sort"].(string),
+						SortSafelist: []string{"internal_id", "name", "-internal_id", "-name"},
+					}
+
+					v := validator.New()
+					if validateGraphQLFilters(v, filters); !v.Valid() {
+						return nil, graphqlValidationError(v)
+					}
+
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					clients, _, err := app.models.Client.GetAll(actor, data.ClientFilter{Name: p.Args["name"].(string)}, filters)
+					return clients, err
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					user, err := app.models.User.GetForActor(actor, int32(p.Args["id"].(int)))
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return user, err
+				},
+			},
+			"timesheets": &graphql.Field{
+				Type: graphql.NewList(timesheetType),
+				Args: timesheetListArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return app.graphqlTimesheets(p, int32(p.Args["user_id"].(int)))
+				},
+			},
+			"proposal": &graphql.Field{
+				Type: proposalType,
+				Args: graphql.FieldConfigArgument{
+					"proposal_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					actor, err := app.actor(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					proposal, err := app.models.Proposal.Get(actor, p.Args["proposal_id"].(string))
+					if errors.Is(err, data.ErrRecordNotFound) {
+						return nil, nil
+					}
+					return proposal, err
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// graphqlTimesheets resolves a page of timesheet entries for userID, or for
+// every user the actor may see when it is zero.
+func (app *application) graphqlTimesheets(p graphql.ResolveParams, userID int32) (any, error) {
+	v := validator.New()
+
+	filter := data.TimesheetFilter{
+		UserID:    userID,
+		ProjectID: int32(p.Args["project_id"].(int)),
+	}
+
+	if from := p.Args["from"].(string); from != "" {
+		filter.From = app.parseDate(v, "from", from)
+	}
+	if to := p.Args["to"].(string); to != "" {
+		filter.To = app.parseDate(v, "to", to)
+	}
+
+	if statuses, ok := p.Args["status"].([]any); ok {
+		for _, status := range statuses {
+			if status, ok := status.(string); ok {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+
+	filters := data.Filters{
+		Page:         p.Args["page"].(int),
+		PageSize:     p.Args["page_size"].(int),
+		Sort:         p.Args["sort"].(string),
+		SortSafelist: timesheetSortSafelist,
+	}
+
+	data.ValidateTimesheetFilter(v, filter)
+	if validateGraphQLFilters(v, filters); !v.Valid() {
+		return nil, graphqlValidationError(v)
+	}
+
+	actor, err := app.actor(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _, err := app.models.Timesheet.GetAll(actor, filter, filters)
+	return entries, err
+}
+
+// validateGraphQLFilters checks filters as the REST endpoints do, except
+// that a page_size of 0 does not lift the limit.
+func validateGraphQLFilters(v *validator.Validator, f data.Filters) {
+	data.ValidateFilters(v, f)
+	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
+}
+
+// graphqlDepth returns how deeply the operations of a query nest fields,
+// following fragments. A query that does not parse has depth 0 and is left
+// for graphql.Do to report.
+func graphqlDepth(query string) int {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0
+	}
+
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	// visiting holds the fragments being expanded, so that cyclic ones,
+	// which validation rejects anyway, do not recurse forever.
+	visiting := make(map[string]bool)
+
+	var depth func(set *ast.SelectionSet) int
+	depth = func(set *ast.SelectionSet) int {
+		if set == nil {
+			return 0
+		}
+
+		deepest := 0
+		for _, selection := range set.Selections {
+			d := 0
+			switch selection := selection.(type) {
+			case *ast.Field:
+				d = 1 + depth(selection.SelectionSet)
+			case *ast.InlineFragment:
+				d = depth(selection.SelectionSet)
+			case *ast.FragmentSpread:
+				fragment := fragments[selection.Name.Value]
+				if fragment == nil || visiting[selection.Name.Value] {
+					continue
+				}
+				visiting[selection.Name.Value] = true
+				d = depth(fragment.SelectionSet)
+				visiting[selection.Name.Value] = false
+			}
+			deepest = max(deepest, d)
+		}
+
+		return deepest
+	}
+
+	deepest := 0
+	for _, def := range doc.Definitions {
+		if operation, ok := def.(*ast.OperationDefinition); ok {
+			deepest = max(deepest, depth(operation.SelectionSet))
+		}
+	}
+
+	return deepest
+}
+
+// graphqlValidationError reports every failed check, in key order so the
+// message is the same from one request to the next.
+func graphqlValidationError(v *validator.Validator) error {
+	if v.Valid() {
+		return nil
+	}
+
+	messages := make([]string, 0, len(v.Errors))
+	for _, key := range slices.Sorted(maps.Keys(v.Errors)) {
+		messages = append(messages, key+" "+v.Errors[key])
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func (app *application) graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.Query != "", "query", "must be provided")
+	v.Check(graphqlDepth(input.Query) <= graphqlMaxDepth, "query", fmt.Sprintf("must not nest fields more than %d deep", graphqlMaxDepth))
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         app.graphql,
+		RequestString:  input.Query,
+		OperationName:  input.OperationName,
+		VariableValues: input.Variables,
+		Context:        r.Context(),
+	})
+
+	env := envelope{"data": result.Data}
+	if result.HasErrors() {
+		env["errors"] = result.Errors
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
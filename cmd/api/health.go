@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+)
+
+// attachProjectHealth fills in the health of each active project in a list.
+func (app *application) attachProjectHealth(projects []*data.ProjectResponse) error {
+	ids := make([]int32, 0, len(projects))
+	for _, project := range projects {
+		if project.ExternalID != nil {
+			ids = append(ids, *project.ExternalID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	health, err := app.models.Health.GetForProjects(ids, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		if project.ExternalID != nil {
+			project.Health = health[*project.ExternalID]
+		}
+	}
+
+	return nil
+}
+
+// runHealthAlerts emails each manager the projects they manage that are red,
+// each time the health_alerts job runs.
+func (app *application) runHealthAlerts() {
+	if !app.emailEnabled() {
+		return
+	}
+
+	app.runScheduled("health_alerts", func() {
+		alerts, err := app.models.Health.GetAlerts(time.Now())
+		if err != nil {
+			app.logger.Error("health alerts failed", "error", err.Error())
+			return
+		}
+
+		sent := 0
+		for _, alert := range alerts {
+			err = app.userMailer(alert.UserID).Send(alert.Email, "health_alert.tmpl", map[string]any{
+				"firstName": alert.FirstName,
+				"projects":  alert.Projects,
+			})
+			if err != nil {
+				app.logger.Error("health alert failed", "user_id", alert.UserID, "error", err.Error())
+				continue
+			}
+			sent++
+		}
+
+		if sent > 0 {
+			app.logger.Info("health alerts sent", "count", sent)
+		}
+	})
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"net/http"
+	"runtime/debug"
+	"time"
 )
 
 func (app *application) healthcheckHandler(w http.ResponseWriter, r *http.Request) {
@@ -18,3 +20,95 @@ func (app *application) healthcheckHandler(w http.ResponseWriter, r *http.Reques
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// statusHandler describes the running build and which optional features
+// are switched on. It is public, so it reports only whether a feature is
+// enabled, never how it is configured.
+func (app *application) statusHandler(w http.ResponseWriter, r *http.Request) {
+	commit, built := buildInfo()
+
+	uptime := time.Since(app.started).Truncate(time.Second)
+
+	env := envelope{
+		"status": "available",
+		"build": map[string]string{
+			"version":    version,
+			"git_commit": commit,
+			"build_time": built,
+			"go_version": goVersion(),
+		},
+		"environment":    app.config.env,
+		"started_at":     app.started.UTC().Format(time.RFC3339),
+		"uptime":         uptime.String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"features":       app.features(),
+	}
+
+	err := app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// features snapshots which optional features the server was started with.
+func (app *application) features() map[string]bool {
+	cfg := app.config
+
+	return map[string]bool{
+		"rate_limiter":     cfg.limiter.enabled,
+		"read_replica":     cfg.db.replicaDSN != "",
+		"response_cache":   cfg.cache.ttl > 0,
+		"cdn":              cfg.cdn.domain != "",
+		"upload_scanning":  app.scanner != nil,
+		"storage":          app.storageEnabled(),
+		"email":            app.emailEnabled(),
+		"exchange_rates":   app.rates != nil,
+		"geocoding":        app.geocoder != nil,
+		"client_geocoding": app.geocoder != nil && cfg.geocode.clients,
+		"proposal_links":   cfg.proposal.require || cfg.proposal.autoCreate,
+		"access_tokens":    cfg.access.secret != "",
+		"pwned_passwords":  cfg.password.breachCheck,
+		"retention":        cfg.retention.interval > 0 && !cfg.retention.dryRun,
+		"digests":          cfg.digest.interval > 0,
+		"report_snapshots": cfg.report.snapshotInterval > 0,
+		"health_alerts":    cfg.health.alertInterval > 0,
+		"tracing":          cfg.otel.endpoint != "",
+		"error_reporting":  cfg.sentry.dsn != "",
+	}
+}
+
+// buildInfo returns the commit and time the binary was built from, taken
+// from the link-time variables or else from the toolchain's version control
+// stamp. Either is empty when unknown.
+func buildInfo() (commit, built string) {
+	commit, built = gitCommit, buildTime
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return commit, built
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if commit == "" {
+				commit = setting.Value
+			}
+		case "vcs.time":
+			if built == "" {
+				built = setting.Value
+			}
+		}
+	}
+
+	return commit, built
+}
+
+func goVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	return info.GoVersion
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +11,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/hwanbin/wanpm-api/internal/validator"
@@ -25,6 +28,17 @@ func (app *application) readInt32IDParam(r *http.Request) (int32, error) {
 	return int32(id), nil
 }
 
+func (app *application) readInt64IDParam(r *http.Request) (int64, error) {
+	idParam := chi.URLParam(r, "id")
+
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("invalid id parameter")
+	}
+
+	return id, nil
+}
+
 func (app *application) readStringIDParam(r *http.Request) (string, error) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
@@ -37,13 +51,11 @@ func (app *application) readStringIDParam(r *http.Request) (string, error) {
 type envelope map[string]any
 
 func (app *application) writeJSON(w http.ResponseWriter, status int, data envelope, headers http.Header) error {
-	js, err := json.MarshalIndent(data, "", "\t")
+	js, err := app.marshalJSON(data)
 	if err != nil {
 		return err
 	}
 
-	js = append(js, '\n')
-
 	for key, value := range headers {
 		w.Header()[key] = value
 	}
@@ -55,6 +67,85 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data envelo
 	return nil
 }
 
+// deletedResource describes what a DELETE removed. Cascade counts the
+// dependent records that went with it, where the handler knows them.
+type deletedResource struct {
+	Resource string         `json:"resource"`
+	ID       any            `json:"id" doc:"The ID of the deleted resource, in the form its route takes."`
+	Cascade  map[string]int `json:"cascade,omitempty" doc:"Dependent records removed along with the resource, when the endpoint reports them." example:"{\"files\": 12}"`
+}
+
+// deletedResponse answers a successful DELETE. Clients asking for response
+// version 2 or later get 204 No Content. Others get 200 with the message
+// earlier clients rely on and the deleted resource.
+func (app *application) deletedResponse(w http.ResponseWriter, r *http.Request, message string, deleted deletedResource) {
+	w.Header().Add("Vary", "Accept")
+
+	if app.responseVersion(r) >= 2 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"message": message, "deleted": deleted}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) marshalJSON(data envelope) ([]byte, error) {
+	js, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(js, '\n'), nil
+}
+
+func (app *application) writeCachedJSON(w http.ResponseWriter, r *http.Request, js []byte) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d, must-revalidate", int(app.config.cache.ttl.Seconds())))
+	app.writeTaggedJSON(w, r, js)
+}
+
+// writeTaggedJSON sends js with an ETag derived from its content, or a 304
+// when it matches the If-None-Match header. Unlike a modification time the
+// tag also changes when items are removed from a list.
+func (app *application) writeTaggedJSON(w http.ResponseWriter, r *http.Request, js []byte) {
+	sum := sha256.Sum256(js)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+
+	if match := r.Header.Get("If-None-Match"); match != "" && match == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(js)
+}
+
+func (app *application) notModifiedSince(w http.ResponseWriter, r *http.Request, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	if lastModified.After(since) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
 func (app *application) readMapboxJSON(res *http.Response, dst any) error {
 	dec := json.NewDecoder(res.Body)
 	err := dec.Decode(dst)
@@ -136,6 +227,18 @@ func (app *application) readCSV(qs url.Values, key string, defaultValue []string
 	return strings.Split(csv, ",")
 }
 
+// parseDate parses a YYYY-MM-DD date, recording a validation error under
+// key when it is malformed.
+func (app *application) parseDate(v *validator.Validator, key, s string) *time.Time {
+	date, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		v.AddError(key, "must be a date in YYYY-MM-DD format")
+		return nil
+	}
+
+	return &date
+}
+
 func (app *application) readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
 	s := qs.Get(key)
 
@@ -151,3 +254,19 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 
 	return i
 }
+
+func (app *application) background(fn func()) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+
+		defer func() {
+			if err := recover(); err != nil {
+				app.logger.Error(fmt.Sprintf("%v", err))
+			}
+		}()
+
+		fn()
+	}()
+}
@@ -0,0 +1,803 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/xuri/excelize/v2"
+)
+
+const maxImportBytes = 10 << 20
+
+var projectImportColumns = []string{"project_id", "proposal_id", "name", "status", "client_names"}
+
+type importRowError struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+type importReport struct {
+	Rows            int              `json:"rows"`
+	Valid           int              `json:"valid"`
+	Errors          []importRowError `json:"errors"`
+	ClientsToCreate []string         `json:"clients_to_create"`
+	Committed       bool             `json:"committed"`
+}
+
+// importProjectsHandler validates a CSV or XLSX sheet of projects and
+// reports what would be imported. With confirm=true and no row errors, every
+// project is inserted in a single transaction.
+func (app *application) importProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	confirm := app.readString(qs, "confirm", "") == "true"
+	createClients := app.readString(qs, "create_clients", "") == "true"
+
+	records, err := app.readImportRecords(w, r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(records) < 2 {
+		app.badRequestResponse(w, r, errors.New("file must contain a header row and at least one project"))
+		return
+	}
+
+	v := validator.New()
+	cell := importColumns(v, records[0], projectImportColumns)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	report := importReport{Errors: []importRowError{}, ClientsToCreate: []string{}}
+	projects := []*data.ProjectRequest{}
+	rowErrors := make(map[int]*validator.Validator)
+
+	// Rows are numbered as they appear in the sheet, header included.
+	inputs := make(map[int]*data.ProjectInput)
+	seenIDs := make(map[int32]int)
+	seenProposals := make(map[string]int)
+	clientNames := []string{}
+
+	for i, record := range records[1:] {
+		row := i + 2
+
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		report.Rows++
+
+		rv := validator.New()
+		input := parseProjectImportRow(rv, record, cell)
+
+		if data.ValidateProjectInputRequired(rv, input); rv.Valid() {
+			data.ValidateProjectInputSemantic(rv, input)
+		}
+
+		if input.ExternalID != nil {
+			if first, ok := seenIDs[*input.ExternalID]; ok {
+				rv.AddError("project_id", fmt.Sprintf("duplicates row %d", first))
+			} else {
+				seenIDs[*input.ExternalID] = row
+			}
+		}
+		if input.ProposalID != nil && *input.ProposalID != "" {
+			if first, ok := seenProposals[*input.ProposalID]; ok {
+				rv.AddError("proposal_id", fmt.Sprintf("duplicates row %d", first))
+			} else {
+				seenProposals[*input.ProposalID] = row
+			}
+		}
+
+		clientNames = append(clientNames, input.ClientNames...)
+
+		inputs[row] = input
+		rowErrors[row] = rv
+	}
+
+	externalIDs := make([]int32, 0, len(seenIDs))
+	for id := range seenIDs {
+		externalIDs = append(externalIDs, id)
+	}
+	proposalIDs := make([]string, 0, len(seenProposals))
+	for id := range seenProposals {
+		proposalIDs = append(proposalIDs, id)
+	}
+
+	takenIDs, takenProposals, err := app.models.Project.GetExistingKeys(externalIDs, proposalIDs)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	clients, err := app.models.Client.GetAllByNames(actor, clientNames)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	missing := make(map[string]bool)
+
+	for row := 2; row <= len(records); row++ {
+		input, ok := inputs[row]
+		if !ok {
+			continue
+		}
+		rv := rowErrors[row]
+
+		if input.ExternalID != nil && takenIDs[*input.ExternalID] {
+			rv.AddError("project_id", "a project with this project_id already exists")
+		}
+		if input.ProposalID != nil && takenProposals[*input.ProposalID] {
+			rv.AddError("proposal_id", "a proposal with this proposal_id already exists")
+		}
+		if input.ProposalID != nil && *input.ProposalID != "" && app.config.proposal.require && !app.config.proposal.autoCreate {
+			_, err := app.models.Proposal.Get(actor, *input.ProposalID)
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				rv.AddError("proposal_id", "must be an existing proposal")
+			case err != nil:
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+
+		projectClients := []data.ProjectClient{}
+		for _, name := range input.ClientNames {
+			client, ok := clients[name]
+			switch {
+			case ok:
+				projectClients = append(projectClients, data.ProjectClient{
+					ClientID:   &client.InternalID,
+					ClientName: client.Name,
+				})
+			case createClients:
+				if !missing[name] {
+					missing[name] = true
+					report.ClientsToCreate = append(report.ClientsToCreate, name)
+				}
+				projectClients = append(projectClients, data.ProjectClient{ClientName: &name})
+			default:
+				rv.AddError("client_names", fmt.Sprintf("%s cannot be found", name))
+			}
+		}
+
+		if !rv.Valid() {
+			report.Errors = append(report.Errors, importRowError{Row: row, Errors: rv.Errors})
+			continue
+		}
+
+		feature, err := json.Marshal(input.Feature)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		projectFeature := string(feature)
+
+		projects = append(projects, &data.ProjectRequest{
+			ExternalID: input.ExternalID,
+			ProposalID: input.ProposalID,
+			Name:       input.Name,
+			Status:     input.Status,
+			Feature:    &projectFeature,
+			Clients:    projectClients,
+		})
+	}
+
+	report.Valid = len(projects)
+
+	status := http.StatusOK
+	if confirm && len(report.Errors) == 0 {
+		err = app.models.WithTx(r.Context(), func(tx data.Models) error {
+			for _, project := range projects {
+				_, err := app.linkProposal(tx, actor, project.ProposalID)
+				if err != nil {
+					return err
+				}
+			}
+
+			return tx.Project.Import(actor.OrgID, projects, report.ClientsToCreate)
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrDuplicateProjectID), errors.Is(err, data.ErrDuplicateProposalID):
+				app.editConflictResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		// The import may have created clients.
+		app.cache.Invalidate("client:")
+
+		report.Committed = true
+		status = http.StatusCreated
+	}
+
+	err = app.writeJSON(w, status, envelope{"import": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// importColumns maps a header row to a cell lookup, recording an error for
+// each required column that is missing.
+func importColumns(v *validator.Validator, header []string, required []string) func([]string, string) string {
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range required {
+		_, ok := columns[name]
+		v.Check(ok, name, "column must be present")
+	}
+
+	return func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseProjectImportRow(v *validator.Validator, record []string, cell func([]string, string) string) *data.ProjectInput {
+	input := &data.ProjectInput{}
+
+	if s := cell(record, "project_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			v.AddError("project_id", "must be an integer value")
+		} else {
+			externalID := int32(id)
+			input.ExternalID = &externalID
+		}
+	}
+
+	for name, field := range map[string]**string{
+		"proposal_id": &input.ProposalID,
+		"name":        &input.Name,
+		"status":      &input.Status,
+	} {
+		if s := cell(record, name); s != "" {
+			*field = &s
+		}
+	}
+
+	if s := cell(record, "client_names"); s != "" {
+		input.ClientNames = []string{}
+		for _, name := range strings.Split(s, ";") {
+			if name = strings.TrimSpace(name); name != "" {
+				input.ClientNames = append(input.ClientNames, name)
+			}
+		}
+	}
+
+	address := cell(record, "full_address")
+	lng := cell(record, "longitude")
+	lat := cell(record, "latitude")
+	if address != "" || lng != "" || lat != "" {
+		feature := &data.Feature{Type: "Feature"}
+		feature.Geometry.Type = "Point"
+		feature.Properties.FullAddress = address
+		if input.Name != nil {
+			feature.Properties.Name = *input.Name
+		}
+
+		for _, c := range []struct{ key, value string }{{"longitude", lng}, {"latitude", lat}} {
+			f, err := strconv.ParseFloat(c.value, 64)
+			if err != nil {
+				v.AddError(c.key, "must be a number")
+				continue
+			}
+			feature.Geometry.Coordinates = append(feature.Geometry.Coordinates, f)
+		}
+
+		input.Feature = feature
+	}
+
+	return input
+}
+
+// readImportRecords reads the uploaded sheet from a multipart "file" field or
+// the raw request body. XLSX workbooks are detected by content and their
+// first sheet is used; anything else is parsed as CSV.
+func (app *application) readImportRecords(w http.ResponseWriter, r *http.Request) ([][]string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	var body io.Reader = r.Body
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body = file
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if http.DetectContentType(content) == "application/zip" {
+		book, err := excelize.OpenReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read workbook: %v", err)
+		}
+		defer book.Close()
+
+		sheets := book.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+
+		return book.GetRows(sheets[0])
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse csv: %v", err)
+	}
+
+	return records, nil
+}
+
+var userImportColumns = []string{"first_name", "last_name", "email", "role"}
+
+type userImportReport struct {
+	Rows     int              `json:"rows"`
+	Created  []*data.User     `json:"created"`
+	Existing []string         `json:"existing"`
+	Errors   []importRowError `json:"errors"`
+}
+
+// importUsersHandler creates invited accounts from a sheet of users and
+// emails each new account an activation code. Rows failing validation are
+// reported and skipped, and addresses that already have an account are
+// left as they are, so the same file can be submitted again safely. Users
+// join the importer's organization and may only be given roles granting
+// permissions the importer holds.
+func (app *application) importUsersHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "user:write") {
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	records, err := app.readImportRecords(w, r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(records) < 2 {
+		app.badRequestResponse(w, r, errors.New("file must contain a header row and at least one user"))
+		return
+	}
+
+	v := validator.New()
+	cell := importColumns(v, records[0], userImportColumns)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	known, err := app.models.Role.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	roleNames := make([]string, len(known))
+	grantable := make(map[string]bool, len(known))
+	for i, role := range known {
+		roleNames[i] = role.Name
+		grantable[role.Name] = actor.Permissions.IncludeAll(role.Permissions)
+	}
+
+	report := userImportReport{Existing: []string{}, Errors: []importRowError{}}
+	users := []*data.User{}
+	roles := make(map[string]string)
+	seen := make(map[string]int)
+
+	for i, record := range records[1:] {
+		row := i + 2
+
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		report.Rows++
+
+		user := &data.User{
+			FirstName: cell(record, "first_name"),
+			LastName:  cell(record, "last_name"),
+			Email:     cell(record, "email"),
+		}
+		role := strings.ToLower(cell(record, "role"))
+
+		rv := validator.New()
+		data.ValidateEmail(rv, user.Email)
+		rv.Check(user.FirstName != "", "first_name", "must be provided")
+		rv.Check(user.LastName != "", "last_name", "must be provided")
+		switch {
+		case !validator.PermittedValue(role, roleNames...):
+			rv.AddError("role", "must be an existing role")
+		case !grantable[role]:
+			rv.AddError("role", "must not grant permissions you do not hold")
+		}
+
+		// Emails are case-insensitive in the database.
+		email := strings.ToLower(user.Email)
+		if first, ok := seen[email]; ok {
+			rv.AddError("email", fmt.Sprintf("duplicates row %d", first))
+		}
+
+		if !rv.Valid() {
+			report.Errors = append(report.Errors, importRowError{Row: row, Errors: rv.Errors})
+			continue
+		}
+
+		seen[email] = row
+		roles[user.Email] = role
+		users = append(users, user)
+	}
+
+	report.Created, err = app.models.User.Import(actor.OrgID, users, roles)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	created := make(map[*data.User]bool, len(report.Created))
+	for _, user := range report.Created {
+		created[user] = true
+	}
+	for _, user := range users {
+		if !created[user] {
+			report.Existing = append(report.Existing, user.Email)
+		}
+	}
+
+	invited := report.Created
+	if app.emailEnabled() {
+		app.background(func() {
+			for _, user := range invited {
+				token, err := app.models.Token.New(user.InternalID, activationTokenTTL, data.ScopeActivation)
+				if err != nil {
+					app.requestLogger(r).Error(err.Error())
+					continue
+				}
+
+				data := map[string]any{
+					"activationToken": token.Plaintext,
+				}
+
+				err = app.userMailer(user.InternalID).Send(user.Email, "user_welcome.tmpl", data)
+				if err != nil {
+					app.requestLogger(r).Error(err.Error())
+				}
+			}
+		})
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"import": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+var timesheetImportColumns = []string{"email", "project", "date", "hours"}
+
+type timesheetImportReport struct {
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Rejected []importRowError `json:"rejected"`
+}
+
+// importTimesheetsHandler loads historical time entries exported from a
+// previous tracker. The project column holds the old tracker's reference,
+// translated through an optional project_map form field (a JSON object of
+// old reference to project_id or proposal_id) and otherwise matched as is.
+// Rows that do not resolve are rejected and the rest are copied in one
+// batch. Unless force=true, rows that would take a user over 24 hours on a
+// day are rejected as well. Users and projects are matched within the
+// importer's organization, which the importer must administer.
+func (app *application) importTimesheetsHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	qs := r.URL.Query()
+	force := app.readString(qs, "force", "") == "true"
+	source := app.readString(qs, "source", "import")
+
+	records, err := app.readImportRecords(w, r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(records) < 2 {
+		app.badRequestResponse(w, r, errors.New("file must contain a header row and at least one entry"))
+		return
+	}
+
+	v := validator.New()
+	cell := importColumns(v, records[0], timesheetImportColumns)
+	v.Check(len(source) <= 100, "source", "must not be more than 100 bytes long")
+
+	projectMap := make(map[string]string)
+	if raw := r.PostFormValue("project_map"); raw != "" {
+		decoded := make(map[string]any)
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&decoded); err != nil {
+			v.AddError("project_map", "must be a JSON object")
+		}
+		for ref, target := range decoded {
+			projectMap[ref] = fmt.Sprint(target)
+		}
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	activities, err := app.models.Activity.GetAll(actor)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	activityIDs := make(map[string]int32)
+	for _, activity := range activities {
+		name := strings.ToLower(activity.Name)
+		if _, ok := activityIDs[name]; ok {
+			activityIDs[name] = 0
+			continue
+		}
+		activityIDs[name] = activity.InternalID
+	}
+
+	type parsedEntry struct {
+		row   int
+		email string
+		ref   string
+		entry *data.TimesheetEntry
+	}
+
+	report := timesheetImportReport{Rejected: []importRowError{}}
+	parsed := []*parsedEntry{}
+	emails := []string{}
+	refs := []string{}
+	var from, to time.Time
+
+	for i, record := range records[1:] {
+		row := i + 2
+
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		report.Rows++
+
+		rv := validator.New()
+		p := &parsedEntry{
+			row:   row,
+			email: strings.ToLower(cell(record, "email")),
+			ref:   cell(record, "project"),
+			entry: &data.TimesheetEntry{Note: cell(record, "note")},
+		}
+
+		if mapped, ok := projectMap[p.ref]; ok {
+			p.ref = mapped
+		}
+
+		data.ValidateEmail(rv, p.email)
+		rv.Check(p.ref != "", "project", "must be provided")
+
+		if workDate := app.parseDate(rv, "date", cell(record, "date")); workDate != nil {
+			p.entry.WorkDate = *workDate
+		}
+
+		hours, err := strconv.ParseFloat(cell(record, "hours"), 64)
+		if err != nil {
+			rv.AddError("hours", "must be a number")
+		}
+		p.entry.Minutes = int32(math.Round(hours * 60))
+		rv.Check(p.entry.Minutes > 0, "hours", "must be greater than zero")
+		rv.Check(p.entry.Minutes <= data.MaxDailyMinutes, "hours", "must not be more than 24")
+
+		if name := cell(record, "activity"); name != "" {
+			id, ok := activityIDs[strings.ToLower(name)]
+			switch {
+			case !ok:
+				rv.AddError("activity", fmt.Sprintf("%s cannot be found", name))
+			case id == 0:
+				rv.AddError("activity", fmt.Sprintf("%s matches more than one activity", name))
+			default:
+				p.entry.ActivityID = &id
+			}
+		}
+
+		if !rv.Valid() {
+			report.Rejected = append(report.Rejected, importRowError{Row: row, Errors: rv.Errors})
+			continue
+		}
+
+		if from.IsZero() || p.entry.WorkDate.Before(from) {
+			from = p.entry.WorkDate
+		}
+		if p.entry.WorkDate.After(to) {
+			to = p.entry.WorkDate
+		}
+
+		emails = append(emails, p.email)
+		refs = append(refs, p.ref)
+		parsed = append(parsed, p)
+	}
+
+	users, err := app.models.User.GetAllByEmails(actor, emails)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	projectIDs, err := app.models.Project.ResolveRefs(actor, refs)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	userIDs := make([]int32, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.InternalID)
+	}
+
+	totals := make(map[int32]map[string]int32)
+	if !force && len(users) > 0 {
+		totals, err = app.models.Timesheet.GetDailyMinutes(userIDs, from, to)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	rules, err := app.models.WorkRules.GetForUsers(userIDs)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Whether an activity is enabled for a project, by project and activity.
+	enabled := make(map[[2]int32]bool)
+
+	entries := []*data.TimesheetEntry{}
+
+	for _, p := range parsed {
+		rv := validator.New()
+
+		user, ok := users[p.email]
+		if ok {
+			p.entry.UserID = user.InternalID
+		} else {
+			rv.AddError("email", "no user has this email address")
+		}
+
+		p.entry.ProjectID, ok = projectIDs[p.ref]
+		if !ok {
+			rv.AddError("project", fmt.Sprintf("%s does not match any project", p.ref))
+		}
+
+		if ok && p.entry.ActivityID != nil {
+			key := [2]int32{p.entry.ProjectID, *p.entry.ActivityID}
+
+			isEnabled, seen := enabled[key]
+			if !seen {
+				isEnabled, err = app.models.Activity.IsEnabledForProject(key[0], key[1])
+				if err != nil {
+					app.serverErrorResponse(w, r, err)
+					return
+				}
+				enabled[key] = isEnabled
+			}
+
+			rv.Check(isEnabled, "activity", fmt.Sprintf("is not enabled for project %s", p.ref))
+		}
+
+		workRules := rules[p.entry.UserID]
+		if workRules == nil {
+			workRules = data.DefaultWorkRules(0)
+		}
+
+		if rv.Valid() && !force {
+			day := p.entry.WorkDate.Format(time.DateOnly)
+			if totals[user.InternalID] == nil {
+				totals[user.InternalID] = make(map[string]int32)
+			}
+
+			total := totals[user.InternalID][day] + p.entry.Minutes
+			if total > workRules.MaxDayMinutes {
+				rv.AddError("hours", fmt.Sprintf("would bring the total for %s to more than %s", day, dayLimit(workRules)))
+			} else {
+				totals[user.InternalID][day] = total
+			}
+		}
+
+		if !rv.Valid() {
+			report.Rejected = append(report.Rejected, importRowError{Row: p.row, Errors: rv.Errors})
+			continue
+		}
+
+		entries = append(entries, p.entry)
+	}
+
+	sort.Slice(report.Rejected, func(i, j int) bool {
+		return report.Rejected[i].Row < report.Rejected[j].Row
+	})
+
+	if len(entries) > 0 {
+		err = app.models.Timesheet.CopyIn(entries, source)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	report.Imported = len(entries)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"import": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"mime"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/s3action"
+)
+
+func (app *application) runStorageReconciliation() {
+	if !app.storageEnabled() {
+		return
+	}
+
+	app.runScheduled("storage_reconciliation", func() {
+		externalIDs, err := app.models.Project.GetAllExternalIDs()
+		if err != nil {
+			app.logger.Error("storage reconciliation failed", "error", err.Error())
+			return
+		}
+
+		for _, externalID := range externalIDs {
+			err = app.updateProjectStorage(externalID)
+			if err != nil {
+				app.logger.Error("storage reconciliation failed", "project_id", externalID, "error", err.Error())
+			}
+		}
+
+		app.logger.Info("storage reconciled", "projects", len(externalIDs))
+	})
+}
+
+// runExchangeRateFetch stores the provider's latest rates on start and then
+// each time the exchange_rates job runs.
+func (app *application) runExchangeRateFetch() {
+	if app.rates == nil {
+		return
+	}
+
+	app.fetchExchangeRates()
+	app.runScheduled("exchange_rates", app.fetchExchangeRates)
+}
+
+func (app *application) fetchExchangeRates() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	rates, date, err := app.rates.Latest(ctx, app.config.fx.base)
+	cancel()
+	if err == nil {
+		err = app.models.ExchangeRate.Insert(app.config.fx.base, date, rates)
+	}
+	if err != nil {
+		app.logger.Error("exchange rate fetch failed", "error", err.Error())
+		return
+	}
+
+	app.logger.Info("exchange rates fetched", "base", app.config.fx.base, "date", date.Format(time.DateOnly), "rates", len(rates))
+}
+
+// updateProjectStorage recomputes a project's storage usage from S3 and
+// brings its file catalog in line with the objects actually stored.
+func (app *application) updateProjectStorage(externalID int32) error {
+	objects, err := s3action.ListObjectSummaries(app.s3actor.client, app.config.s3.bucket, strconv.Itoa(int(externalID))+"/")
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	files := make([]*data.File, 0, len(objects))
+	for _, obj := range objects {
+		size += aws.ToInt64(obj.Size)
+
+		mimeType := mime.TypeByExtension(path.Ext(*obj.Key))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+
+		files = append(files, &data.File{
+			Key:       *obj.Key,
+			SizeBytes: aws.ToInt64(obj.Size),
+			MimeType:  mimeType,
+		})
+	}
+
+	err = app.models.File.Reconcile(externalID, files)
+	if err != nil {
+		return err
+	}
+
+	return app.models.Project.SetStorageBytes(externalID, size)
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/ldap"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const (
+	authenticationTokenTTL = 24 * time.Hour
+	ldapTimeout            = 10 * time.Second
+)
+
+// ldapUsernameRX keeps usernames to characters that need no escaping in a
+// DN or user principal name.
+var ldapUsernameRX = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+
+func (app *application) showLDAPConfigHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	config, err := app.models.LDAP.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"ldap": config}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// putLDAPConfigHandler lets an organization's users sign in with their
+// directory credentials, or changes how. Attribute names left out default
+// to those of Active Directory.
+func (app *application) putLDAPConfigHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		URL                string               `json:"url"`
+		BindTemplate       string               `json:"bind_template"`
+		BaseDN             string               `json:"base_dn"`
+		LoginAttribute     string               `json:"login_attribute"`
+		EmailAttribute     string               `json:"email_attribute"`
+		FirstNameAttribute string               `json:"first_name_attribute"`
+		LastNameAttribute  string               `json:"last_name_attribute"`
+		GroupAttribute     string               `json:"group_attribute"`
+		GroupRoles         []data.LDAPGroupRole `json:"group_roles"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	config := &data.LDAPConfig{
+		OrgID:              id,
+		URL:                input.URL,
+		BindTemplate:       input.BindTemplate,
+		BaseDN:             input.BaseDN,
+		LoginAttribute:     cmp.Or(input.LoginAttribute, "sAMAccountName"),
+		EmailAttribute:     cmp.Or(input.EmailAttribute, "mail"),
+		FirstNameAttribute: cmp.Or(input.FirstNameAttribute, "givenName"),
+		LastNameAttribute:  cmp.Or(input.LastNameAttribute, "sn"),
+		GroupAttribute:     cmp.Or(input.GroupAttribute, "memberOf"),
+		GroupRoles:         input.GroupRoles,
+	}
+
+	v := validator.New()
+	if data.ValidateLDAPConfig(v, config); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	created, err := app.models.LDAP.Put(config)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrUnknownRole):
+			v.AddError("group_roles", "must map to existing roles")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	err = app.writeJSON(w, status, envelope{"ldap": config}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteLDAPConfigHandler stops an organization's users signing in with the
+// directory. Their authentication tokens stay valid until they expire.
+func (app *application) deleteLDAPConfigHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	err = app.models.LDAP.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "ldap configuration successfully deleted", deletedResource{Resource: "ldap", ID: id})
+}
+
+// linkLDAPUserHandler lets the organization's directory sign in to an
+// existing account of the organization, which it otherwise refuses to, as
+// the account may not belong to whoever holds that email in the directory.
+func (app *application) linkLDAPUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setLDAPLink(w, r, true)
+}
+
+// unlinkLDAPUserHandler stops the organization's directory signing in to
+// an account.
+func (app *application) unlinkLDAPUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setLDAPLink(w, r, false)
+}
+
+func (app *application) setLDAPLink(w http.ResponseWriter, r *http.Request, linked bool) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 32)
+	if err != nil || userID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.LDAP.Link(id, int32(userID), linked)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"directory_linked": linked}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createLDAPTokenHandler signs a user in with their credentials in their
+// organization's directory and returns an authentication token. Their
+// account is created from the directory on their first sign-in, and their
+// directory groups decide the roles they hold every time.
+func (app *application) createLDAPTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		OrganizationID int32  `json:"organization_id"`
+		Username       string `json:"username"`
+		Password       string `json:"password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.OrganizationID > 0, "organization_id", "must be provided")
+	v.Check(input.Username != "", "username", "must be provided")
+	v.Check(len(input.Username) <= 256, "username", "must not be more than 256 bytes long")
+	v.Check(input.Password != "", "password", "must be provided")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	config, err := app.models.LDAP.Get(input.OrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.invalidCredentialsResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !validator.Matches(input.Username, ldapUsernameRX) {
+		app.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	entry, err := app.ldapAuthenticate(r.Context(), config, input.Username, input.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, ldap.ErrInvalidCredentials):
+			app.invalidCredentialsResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := &data.User{
+		Email:     entry.Get(config.EmailAttribute),
+		FirstName: entry.Get(config.FirstNameAttribute),
+		LastName:  entry.Get(config.LastNameAttribute),
+	}
+
+	if data.ValidateEmail(v, user.Email); !v.Valid() {
+		app.requestLogger(r).Warn("directory entry has no valid email", "dn", entry.DN, "attribute", config.EmailAttribute)
+		app.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	created, err := app.models.LDAP.Provision(config.OrgID, user, config.Roles(entry.Values(config.GroupAttribute)))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrOtherOrganization):
+			app.requestLogger(r).Warn("directory user's email belongs to another organization", "dn", entry.DN, "organization_id", config.OrgID)
+			app.invalidCredentialsResponse(w, r)
+		case errors.Is(err, data.ErrNotLinked):
+			app.requestLogger(r).Warn("directory user's email belongs to an account not linked to the directory", "dn", entry.DN, "user_id", user.InternalID)
+			app.invalidCredentialsResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if created {
+		app.requestLogger(r).Info("user provisioned from directory", "user_id", user.InternalID, "organization_id", config.OrgID)
+	}
+
+	token, err := app.models.Token.New(user.InternalID, authenticationTokenTTL, data.ScopeAuthentication)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, user.InternalID, data.SecurityEventLogin)
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ldapAuthenticate binds to the directory as the user and returns their
+// entry, failing with ldap.ErrInvalidCredentials when the bind fails or the
+// username does not name exactly one entry.
+func (app *application) ldapAuthenticate(ctx context.Context, config *data.LDAPConfig, username, password string) (ldap.Entry, error) {
+	conn, err := ldap.Dial(ctx, config.URL, time.Now().Add(ldapTimeout))
+	if err != nil {
+		return ldap.Entry{}, err
+	}
+	defer conn.Close()
+
+	err = conn.Bind(strings.ReplaceAll(config.BindTemplate, "{username}", username), password)
+	if err != nil {
+		return ldap.Entry{}, err
+	}
+
+	attributes := []string{config.EmailAttribute, config.FirstNameAttribute, config.LastNameAttribute, config.GroupAttribute}
+
+	entries, err := conn.SearchEqual(config.BaseDN, config.LoginAttribute, username, attributes)
+	if err != nil {
+		return ldap.Entry{}, err
+	}
+
+	if len(entries) != 1 {
+		app.logger.Warn("directory user not found unambiguously", "organization_id", config.OrgID, "entries", len(entries))
+		return ldap.Entry{}, ldap.ErrInvalidCredentials
+	}
+
+	return entries[0], nil
+}
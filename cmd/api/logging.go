@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// newLogger builds the application logger from the -log-* flags.
+func newLogger(w io.Writer, format, level string, debugSample int) (*slog.Logger, error) {
+	var lvl slog.Level
+	err := lvl.UnmarshalText([]byte(level))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	if debugSample > 1 {
+		handler = &samplingHandler{Handler: handler, every: uint64(debugSample), seen: new(atomic.Uint64)}
+	}
+
+	return slog.New(handler), nil
+}
+
+// samplingHandler keeps one in every h.every debug records and passes
+// records of higher levels through untouched.
+type samplingHandler struct {
+	slog.Handler
+	every uint64
+	seen  *atomic.Uint64
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level <= slog.LevelDebug && h.seen.Add(1)%h.every != 0 {
+		return nil
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), every: h.every, seen: h.seen}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), every: h.every, seen: h.seen}
+}
+
+const requestIDContextKey = contextKey("requestID")
+
+// requestID tags the request with the caller's X-Request-Id, or a new ID
+// in the configured scheme, and echoes it in the response so log lines can
+// be matched up with client reports.
+func (app *application) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if id == "" || len(id) > 64 {
+			id = app.config.ids.New()
+		}
+
+		w.Header().Set("X-Request-Id", id)
+
+		ctx := context.WithValue(r.Context(), requestIDContextKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestLogger returns the application logger annotated with the request
+// ID. Handlers log through it, including from background tasks they start.
+func (app *application) requestLogger(r *http.Request) *slog.Logger {
+	id, ok := r.Context().Value(requestIDContextKey).(string)
+	if !ok {
+		return app.logger
+	}
+
+	return app.logger.With("request_id", id)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// logRequest writes a debug record for every completed request. These are
+// the high-volume records -log-debug-sample thins out.
+func (app *application) logRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.logger.Enabled(r.Context(), slog.LevelDebug) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		app.requestLogger(r).Debug("request completed",
+			"method", r.Method,
+			"uri", r.URL.RequestURI(),
+			"status", rec.status,
+			"duration", time.Since(start),
+		)
+	})
+}
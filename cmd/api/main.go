@@ -1,31 +1,75 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/XSAM/otelsql"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/cloudfront/sign"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-chi/chi/v5"
+	"github.com/graphql-go/graphql"
+	"github.com/hwanbin/wanpm-api/internal/cache"
 	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/exchange"
+	"github.com/hwanbin/wanpm-api/internal/geocode"
+	"github.com/hwanbin/wanpm-api/internal/id"
+	"github.com/hwanbin/wanpm-api/internal/mailer"
+	"github.com/hwanbin/wanpm-api/internal/s3action"
+	"github.com/hwanbin/wanpm-api/internal/s3local"
+	"github.com/hwanbin/wanpm-api/internal/scanner"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-const version = "1.0.0"
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.gitCommit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+//
+// Without them the commit and time fall back to what the Go toolchain
+// stamped from version control, if anything.
+var (
+	version   = "dev"
+	gitCommit = ""
+	buildTime = ""
+)
 
 type config struct {
 	port int
 	env  string
-	db   struct {
+	dev  bool
+	log  struct {
+		format      string
+		level       string
+		debugSample int
+	}
+	db struct {
 		dsn          string
+		replicaDSN   string
 		maxOpenConns int
 		maxIdleConns int
+		instances    int
 		maxIdleTime  time.Duration
+		timeout      time.Duration
+		timeouts     map[string]time.Duration
+		slowQuery    time.Duration
 	}
 	limiter struct {
 		rps     float64
@@ -33,23 +77,125 @@ type config struct {
 		enabled bool
 	}
 	s3 struct {
-		profile string
-		bucket  string
+		profile           string
+		bucket            string
+		endpoint          string
+		region            string
+		pathStyle         bool
+		trashRetention    time.Duration
+		projectQuota      int64
+		reconcileInterval time.Duration
+		credentials       aws.CredentialsProvider
+	}
+	devStorage struct {
+		dir  string
+		port int
+	}
+	clamav struct {
+		addr string
+	}
+	cdn struct {
+		domain         string
+		keyPairID      string
+		privateKeyFile string
+		urlTTL         time.Duration
+	}
+	cache struct {
+		ttl time.Duration
+	}
+	smtp struct {
+		host     string
+		port     int
+		username string
+		password string
+		sender   string
+	}
+	retention struct {
+		interval          time.Duration
+		dryRun            bool
+		unactivatedUsers  time.Duration
+		deletedTimesheets time.Duration
+		deletedProjects   time.Duration
+	}
+	digest struct {
+		interval      time.Duration
+		budgetWarning float64
+	}
+	report struct {
+		snapshotInterval time.Duration
+	}
+	health struct {
+		alertInterval time.Duration
+	}
+	planning struct {
+		weeklyHours float64
+	}
+	proposal struct {
+		require    bool
+		autoCreate bool
+	}
+	access struct {
+		secret             string
+		ttl                time.Duration
+		revocationInterval time.Duration
+	}
+	cookie struct {
+		domain      string
+		sameSite    string
+		secure      bool
+		partitioned bool
+	}
+	password struct {
+		minLength   int
+		require     string
+		denylist    string
+		breachCheck bool
+	}
+	fx struct {
+		provider string
+		base     string
+		interval time.Duration
+	}
+	geocode struct {
+		provider string
+		token    string
+		clients  bool
+	}
+	otel struct {
+		endpoint    string
+		sampleRatio float64
 	}
+	sentry struct {
+		dsn string
+	}
+	ids id.Scheme
 }
 
 type s3Actor struct {
 	client        *s3.Client
 	presignClient *s3.PresignClient
 	uploader      *manager.Uploader
+	cdnSigner     *sign.URLSigner
 }
 
 type application struct {
-	config  config
-	logger  *slog.Logger
-	models  data.Models
-	s3actor s3Actor
-	wg      sync.WaitGroup
+	config   config
+	logger   *slog.Logger
+	models   data.Models
+	s3actor  s3Actor
+	cache    *cache.Cache
+	graphql  graphql.Schema
+	mailer   mailer.Mailer
+	scanner  *scanner.ClamAV
+	rates    exchange.Provider
+	geocoder geocode.Provider
+	wg       sync.WaitGroup
+	started  time.Time
+	claims   claimsVersions
+	push     notificationHub
+	password data.PasswordPolicy
+
+	shutdownTracing func(context.Context) error
 }
 
 func main() {
@@ -57,49 +203,312 @@ func main() {
 
 	flag.IntVar(&cfg.port, "port", 9000, "API server port")
 	flag.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
+	flag.BoolVar(&cfg.dev, "dev", false, "Run for local development: emails are logged instead of sent when no SMTP host is set, and files are stored on local disk when no S3 bucket or endpoint is set. A Postgres database is still needed (development only)")
+
+	flag.StringVar(&cfg.log.format, "log-format", "text", "Log output format (text|json)")
+	flag.StringVar(&cfg.log.level, "log-level", "info", "Minimum log level (debug|info|warn|error)")
+	flag.IntVar(&cfg.log.debugSample, "log-debug-sample", 1, "Keep one in this many debug log records (1 keeps all)")
 
 	flag.StringVar(&cfg.db.dsn, "db-dsn", os.Getenv("WANTONI_DB_DSN"), "PostgreSQL DSN")
+	flag.StringVar(&cfg.db.replicaDSN, "db-replica-dsn", os.Getenv("WANTONI_DB_REPLICA_DSN"), "PostgreSQL read replica DSN for list, report and export queries (empty reads from the primary)")
 
 	flag.IntVar(&cfg.db.maxOpenConns, "db-max-open-conns", 25, "PostgreSQL max open connections")
 	flag.IntVar(&cfg.db.maxIdleConns, "db-max-idle-conns", 25, "PostgreSQL max idle connections")
+	flag.IntVar(&cfg.db.instances, "db-instances", 0, "Number of API instances sharing the database; when set, max open connections is derived from the server's max_connections instead of -db-max-open-conns")
 	flag.DurationVar(&cfg.db.maxIdleTime, "db-max-idle-time", 15*time.Minute, "PostgreSQL max connection idle time")
+	flag.DurationVar(&cfg.db.timeout, "db-query-timeout", 3*time.Second, "Default timeout of a single model query")
+	flag.Func("db-query-timeouts", "Per store query timeouts overriding the default (e.g. timesheet=10s,project=5s)", func(val string) error {
+		cfg.db.timeouts = make(map[string]time.Duration)
+
+		for _, pair := range strings.Split(val, ",") {
+			store, d, found := strings.Cut(pair, "=")
+			if !found {
+				return fmt.Errorf("%q is not store=duration", pair)
+			}
+
+			timeout, err := time.ParseDuration(d)
+			if err != nil {
+				return err
+			}
+
+			cfg.db.timeouts[strings.TrimSpace(store)] = timeout
+		}
+
+		return nil
+	})
+	flag.DurationVar(&cfg.db.slowQuery, "db-slow-query", 500*time.Millisecond, "Log queries running longer than this (0 disables)")
 
 	flag.Float64Var(&cfg.limiter.rps, "limter-rps", 20, "Rate limiter maximum requests per second")
 	flag.IntVar(&cfg.limiter.burst, "limiter-burst", 40, "Rate limiter maximum burst")
 	flag.BoolVar(&cfg.limiter.enabled, "limiter-enabled", true, "Enable rate limiter")
 
-	flag.StringVar(&cfg.s3.profile, "s3-profile", "s3_profile", "S3 profile")
-	flag.StringVar(&cfg.s3.bucket, "s3-bucket", os.Getenv("S3_BUCKET_NAME"), "S3 bucket name")
+	flag.StringVar(&cfg.s3.profile, "s3-profile", "s3_profile", "S3 profile (empty uses the default credential chain)")
+	flag.StringVar(&cfg.s3.bucket, "s3-bucket", os.Getenv("S3_BUCKET_NAME"), "S3 bucket name (empty disables file storage)")
+	flag.StringVar(&cfg.s3.endpoint, "s3-endpoint", os.Getenv("S3_ENDPOINT_URL"), "S3 endpoint URL, e.g. http://localhost:9000 for MinIO (empty uses AWS)")
+	flag.StringVar(&cfg.s3.region, "s3-region", os.Getenv("S3_REGION"), "S3 region (empty uses the profile's region)")
+	flag.BoolVar(&cfg.s3.pathStyle, "s3-path-style", false, "Address the bucket in the URL path rather than the host name, as MinIO and LocalStack need")
+	flag.DurationVar(&cfg.s3.trashRetention, "s3-trash-retention", 30*24*time.Hour, "How long soft deleted files stay restorable before the retention job purges them (0 disables purging)")
+	flag.Int64Var(&cfg.s3.projectQuota, "s3-project-quota", 5<<30, "Maximum bytes stored per project (0 disables the quota)")
+	flag.StringVar(&cfg.devStorage.dir, "dev-storage-dir", "tmp/storage", "Directory files are stored in by dev mode when no S3 bucket is set")
+	flag.IntVar(&cfg.devStorage.port, "dev-storage-port", 9001, "Port on localhost that serves the dev mode file store to presigned URLs")
+	flag.DurationVar(&cfg.s3.reconcileInterval, "s3-reconcile-interval", 6*time.Hour, "How often project storage usage is recomputed from S3 (0 disables)")
+
+	flag.StringVar(&cfg.cdn.domain, "cdn-domain", os.Getenv("CLOUDFRONT_DOMAIN"), "CloudFront distribution domain (empty serves S3 presigned URLs)")
+	flag.StringVar(&cfg.cdn.keyPairID, "cdn-key-pair-id", os.Getenv("CLOUDFRONT_KEY_PAIR_ID"), "CloudFront public key ID")
+	flag.StringVar(&cfg.cdn.privateKeyFile, "cdn-private-key", os.Getenv("CLOUDFRONT_PRIVATE_KEY_FILE"), "Path to the CloudFront signing private key (PEM)")
+	flag.DurationVar(&cfg.cdn.urlTTL, "cdn-url-ttl", time.Hour, "CloudFront signed URL lifetime")
+
+	flag.DurationVar(&cfg.cache.ttl, "cache-ttl", 5*time.Minute, "Reference data response cache TTL (0 disables caching)")
+
+	flag.StringVar(&cfg.clamav.addr, "clamav-addr", os.Getenv("CLAMAV_ADDR"), "clamd TCP address used to scan completed uploads (empty disables scanning)")
+
+	flag.StringVar(&cfg.smtp.host, "smtp-host", os.Getenv("SMTP_HOST"), "SMTP host (empty disables email)")
+	flag.IntVar(&cfg.smtp.port, "smtp-port", 587, "SMTP port")
+	flag.StringVar(&cfg.smtp.username, "smtp-username", os.Getenv("SMTP_USERNAME"), "SMTP username")
+	flag.StringVar(&cfg.smtp.password, "smtp-password", os.Getenv("SMTP_PASSWORD"), "SMTP password")
+	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "Wanpm <no-reply@wanton.app>", "SMTP sender")
+
+	flag.DurationVar(&cfg.retention.interval, "retention-interval", 24*time.Hour, "How often retention rules are applied (0 disables)")
+	flag.BoolVar(&cfg.retention.dryRun, "retention-dry-run", false, "Only report what retention rules would purge")
+	flag.DurationVar(&cfg.retention.unactivatedUsers, "retention-unactivated-users", 30*24*time.Hour, "Delete accounts never activated after this long (0 disables)")
+	flag.DurationVar(&cfg.retention.deletedTimesheets, "retention-deleted-timesheets", 365*24*time.Hour, "Purge soft deleted timesheet entries after this long (0 disables)")
+	flag.DurationVar(&cfg.retention.deletedProjects, "retention-deleted-projects", 30*24*time.Hour, "Permanently delete projects and their files this long after deletion, until then they can be undeleted (0 keeps them)")
+
+	flag.DurationVar(&cfg.digest.interval, "digest-interval", time.Hour, "How often due digest emails are looked for and sent (0 disables)")
+	flag.Float64Var(&cfg.digest.budgetWarning, "digest-budget-warning", 0.8, "Fraction of a project's budget logged before digests warn about it")
+
+	flag.DurationVar(&cfg.report.snapshotInterval, "report-snapshot-interval", 24*time.Hour, "How often the weekly timesheet summary used by reports is rebuilt (0 disables)")
+	flag.DurationVar(&cfg.health.alertInterval, "health-alert-interval", 7*24*time.Hour, "How often managers are emailed about their projects in red health (0 disables)")
+	flag.Float64Var(&cfg.planning.weeklyHours, "planning-weekly-hours", 40, "Hours in a full working week, for capacity planning")
+
+	flag.BoolVar(&cfg.proposal.require, "proposal-require", false, "Reject projects whose proposal_id is not an existing proposal")
+	flag.BoolVar(&cfg.proposal.autoCreate, "proposal-autocreate", false, "Create the proposal a project's proposal_id names when it does not exist yet")
+
+	flag.StringVar(&cfg.access.secret, "access-token-secret", os.Getenv("ACCESS_TOKEN_SECRET"), "Secret signing JWT access tokens (empty disables them)")
+	flag.DurationVar(&cfg.access.ttl, "access-token-ttl", 15*time.Minute, "How long an access token is valid")
+	flag.DurationVar(&cfg.access.revocationInterval, "access-token-revocation-interval", 10*time.Second, "How often permission changes are looked for to revoke access tokens")
+
+	flag.StringVar(&cfg.cookie.domain, "cookie-domain", os.Getenv("COOKIE_DOMAIN"), "Domain refresh token cookies are set for (empty limits them to the API host)")
+	flag.StringVar(&cfg.cookie.sameSite, "cookie-samesite", "strict", "SameSite attribute of refresh token cookies (strict|lax|none)")
+	flag.BoolVar(&cfg.cookie.secure, "cookie-secure", true, "Only send refresh token cookies over HTTPS (may only be disabled in development)")
+	flag.BoolVar(&cfg.cookie.partitioned, "cookie-partitioned", false, "Partition refresh token cookies by top-level site, for frontends embedding the API cross-site")
+
+	flag.IntVar(&cfg.password.minLength, "password-min-length", 12, "Minimum number of characters in a password")
+	flag.StringVar(&cfg.password.require, "password-require", "", "Comma separated character classes passwords must contain (lower,upper,digit,symbol)")
+	flag.StringVar(&cfg.password.denylist, "password-denylist", os.Getenv("PASSWORD_DENYLIST_FILE"), "Path to a file of passwords to reject, one per line, on top of the built-in list of common ones")
+	flag.BoolVar(&cfg.password.breachCheck, "password-breach-check", false, "Reject passwords found in the Have I Been Pwned breach corpus, sending it only a prefix of their SHA-1 hash")
+
+	flag.StringVar(&cfg.fx.provider, "fx-provider", os.Getenv("FX_PROVIDER"), "Exchange rate provider (frankfurter, empty disables fetching)")
+	flag.StringVar(&cfg.fx.base, "fx-base", "USD", "Currency exchange rates are fetched against")
+	flag.DurationVar(&cfg.fx.interval, "fx-interval", 24*time.Hour, "How often exchange rates are fetched")
+
+	flag.StringVar(&cfg.geocode.provider, "geocode-provider", "mapbox", "Geocode provider (mapbox)")
+	flag.StringVar(&cfg.geocode.token, "geocode-token", os.Getenv("MAPBOX_GEOCODE_TOKEN"), "Geocode provider access token (empty disables server side geocoding)")
+	flag.BoolVar(&cfg.geocode.clients, "geocode-clients", false, "Geocode client addresses when clients are created or updated")
+
+	flag.StringVar(&cfg.otel.endpoint, "otel-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"), "OTLP/HTTP traces endpoint URL (empty disables tracing)")
+	flag.Float64Var(&cfg.otel.sampleRatio, "otel-sample-ratio", 1, "Fraction of new traces to sample")
+
+	flag.StringVar(&cfg.sentry.dsn, "sentry-dsn", os.Getenv("SENTRY_DSN"), "Sentry DSN (empty disables error reporting)")
+
+	cfg.ids = id.ULID
+	flag.Func("id-scheme", "Scheme of the IDs the server generates (ulid|uuid7|uuid4, default ulid)", func(val string) error {
+		var err error
+		cfg.ids, err = id.Lookup(val)
+		return err
+	})
+
+	printOpenAPI := flag.Bool("openapi", false, "Print the OpenAPI document and exit, without connecting to anything")
 
 	flag.Parse()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	logger, err := newLogger(os.Stdout, cfg.log.format, cfg.log.level, cfg.log.debugSample)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *printOpenAPI {
+		// The router is built only to be walked, so the application needs
+		// none of its dependencies. Logs go to stderr to keep the document
+		// alone on stdout.
+		app := &application{config: cfg, logger: slog.New(slog.NewTextHandler(os.Stderr, nil))}
+
+		spec, err := openAPISpec(app.routes().(chi.Routes), cfg.ids)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 
-	db, err := openDB(cfg)
+		os.Stdout.Write(spec)
+		return
+	}
+
+	if cfg.dev && cfg.env != "development" {
+		logger.Error("dev may only be used in development")
+		os.Exit(1)
+	}
+
+	err = validateCookieConfig(cfg)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	defer db.Close()
+	shutdownTracing, err := initTracing(cfg)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
 
-	logger.Info("database connection pool established")
+	err = initSentry(cfg)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
 
-	s3actor, err := initS3(cfg)
+	db, err := openDB(cfg, cfg.db.dsn)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	logger.Info("s3 actor initialized")
+	defer db.Close()
+
+	logger.Info("database connection pool established", "max_open_conns", db.Stats().MaxOpenConnections)
+
+	pools := map[string]*sql.DB{"primary": db}
+
+	var replica *sql.DB
+	if cfg.db.replicaDSN != "" {
+		replica, err = openDB(cfg, cfg.db.replicaDSN)
+		if err != nil {
+			logger.Warn("read replica unavailable, reading from primary", "error", err.Error())
+		} else {
+			defer replica.Close()
+
+			logger.Info("read replica connection pool established", "max_open_conns", replica.Stats().MaxOpenConnections)
+
+			pools["replica"] = replica
+		}
+	}
+
+	if cfg.dev && cfg.s3.bucket == "" && cfg.s3.endpoint == "" {
+		err = initDevStorage(&cfg, logger)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		logger.Info("s3 bucket not configured, files are stored locally", "dir", cfg.devStorage.dir, "endpoint", cfg.s3.endpoint)
+	}
+
+	var s3actor s3Actor
+	if cfg.s3.bucket != "" {
+		s3actor, err = initS3(cfg)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+
+		logger.Info("s3 actor initialized")
+	} else {
+		logger.Warn("s3 bucket not configured, file storage disabled")
+	}
+
+	mail := mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender)
+	switch {
+	case cfg.smtp.host != "":
+	case cfg.dev:
+		mail = mailer.NewLog(logger, cfg.smtp.sender)
+		logger.Info("smtp host not configured, emails will be logged")
+	default:
+		logger.Warn("smtp host not configured, email disabled")
+	}
 
 	app := &application{
-		config:  cfg,
-		logger:  logger,
-		models:  data.NewModels(db),
+		config: cfg,
+		logger: logger,
+		models: data.NewModels(db, data.QueryConfig{
+			Timeout:       cfg.db.timeout,
+			Timeouts:      cfg.db.timeouts,
+			SlowThreshold: cfg.db.slowQuery,
+			Logger:        logger,
+			Replica:       replica,
+		}),
 		s3actor: s3actor,
+		cache:   cache.New(cfg.cache.ttl),
+		mailer:  mail,
+		started: time.Now(),
+
+		shutdownTracing: shutdownTracing,
 	}
 
+	if cfg.clamav.addr != "" {
+		clamav := scanner.NewClamAV(cfg.clamav.addr)
+		app.scanner = &clamav
+	}
+
+	if cfg.fx.provider != "" {
+		app.rates, err = exchange.New(cfg.fx.provider)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if cfg.geocode.token != "" {
+		app.geocoder, err = geocode.New(cfg.geocode.provider, cfg.geocode.token)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	app.password, err = newPasswordPolicy(cfg)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	app.graphql, err = app.graphqlSchema()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	go func() {
+		for {
+			time.Sleep(time.Minute)
+			app.cache.Purge()
+		}
+	}()
+
+	if cfg.access.secret != "" {
+		err = app.refreshClaimsVersions()
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	err = app.models.Schedule.Seed(app.defaultSchedules())
+	if err != nil {
+		logger.Warn("job schedules not seeded", "error", err.Error())
+	}
+
+	publishPoolMetrics(pools)
+
+	go app.runPoolMonitor(pools)
+	go app.runRetention()
+	go app.runStorageReconciliation()
+	go app.runExchangeRateFetch()
+	go app.runDigest()
+	go app.runReportSnapshot()
+	go app.runHealthAlerts()
+	go app.runClaimsRevocation()
+	go app.runNotificationListener()
+
 	err = app.serve()
 	if err != nil {
 		logger.Error(err.Error())
@@ -107,8 +516,11 @@ func main() {
 	}
 }
 
-func openDB(cfg config) (*sql.DB, error) {
-	db, err := sql.Open("postgres", cfg.db.dsn)
+func openDB(cfg config, dsn string) (*sql.DB, error) {
+	db, err := otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -126,25 +538,129 @@ func openDB(cfg config) (*sql.DB, error) {
 		return nil, err
 	}
 
+	if cfg.db.instances > 0 {
+		maxConns, err := autoMaxConns(ctx, db, cfg.db.instances)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		db.SetMaxOpenConns(maxConns)
+		db.SetMaxIdleConns(min(cfg.db.maxIdleConns, maxConns))
+	}
+
 	return db, nil
 }
 
+// initS3 builds the S3 clients and checks the bucket is there to use, so a
+// misconfigured server fails on start rather than on its first upload.
 func initS3(cfg config) (s3Actor, error) {
-	s3Cfg, err := awsConfig.LoadDefaultConfig(
-		context.Background(),
-		awsConfig.WithSharedConfigProfile(cfg.s3.profile),
-	)
+	var opts []func(*awsConfig.LoadOptions) error
+	if cfg.s3.profile != "" {
+		opts = append(opts, awsConfig.WithSharedConfigProfile(cfg.s3.profile))
+	}
+	if cfg.s3.region != "" {
+		opts = append(opts, awsConfig.WithRegion(cfg.s3.region))
+	}
+	if cfg.s3.credentials != nil {
+		opts = append(opts, awsConfig.WithCredentialsProvider(cfg.s3.credentials))
+	}
+
+	s3Cfg, err := awsConfig.LoadDefaultConfig(context.Background(), opts...)
 	if err != nil {
 		return s3Actor{}, err
 	}
 
-	client := s3.NewFromConfig(s3Cfg)
+	otelaws.AppendMiddlewares(&s3Cfg.APIOptions)
+
+	if cfg.s3.endpoint != "" {
+		u, err := url.Parse(cfg.s3.endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return s3Actor{}, errors.New("s3-endpoint must be an http:// or https:// URL")
+		}
+	}
+
+	client := s3.NewFromConfig(s3Cfg, func(o *s3.Options) {
+		if cfg.s3.endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.s3.endpoint)
+		}
+		o.UsePathStyle = cfg.s3.pathStyle
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = s3action.CheckBucket(ctx, client, cfg.s3.bucket)
+	if err != nil {
+		return s3Actor{}, fmt.Errorf("s3 at %s: %w", cmp.Or(cfg.s3.endpoint, "AWS"), err)
+	}
+
 	uploader := manager.NewUploader(client)
 	presignClient := s3.NewPresignClient(client)
 
+	cdnSigner, err := initCDNSigner(cfg)
+	if err != nil {
+		return s3Actor{}, err
+	}
+
 	return s3Actor{
 		client:        client,
 		uploader:      uploader,
 		presignClient: presignClient,
+		cdnSigner:     cdnSigner,
 	}, nil
 }
+
+// initDevStorage serves a local bucket from cfg.devStorage.dir on localhost
+// and points the S3 settings at it, so dev mode can store files without AWS
+// or MinIO. The store does not check request signatures.
+func initDevStorage(cfg *config, logger *slog.Logger) error {
+	store, err := s3local.New(cfg.devStorage.dir, "dev")
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", cfg.devStorage.port))
+	if err != nil {
+		return fmt.Errorf("dev storage: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:     store,
+		IdleTimeout: time.Minute,
+		ErrorLog:    slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+
+	go func() {
+		err := srv.Serve(ln)
+		if !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("dev storage stopped", "error", err.Error())
+		}
+	}()
+
+	cfg.s3.bucket = "dev"
+	cfg.s3.endpoint = "http://" + ln.Addr().String()
+	cfg.s3.pathStyle = true
+	cfg.s3.profile = ""
+	cfg.s3.region = cmp.Or(cfg.s3.region, "us-east-1")
+	cfg.s3.credentials = credentials.NewStaticCredentialsProvider("dev", "dev", "")
+
+	return nil
+}
+
+func initCDNSigner(cfg config) (*sign.URLSigner, error) {
+	if cfg.cdn.domain == "" {
+		return nil, nil
+	}
+
+	if cfg.cdn.keyPairID == "" || cfg.cdn.privateKeyFile == "" {
+		return nil, errors.New("cdn-key-pair-id and cdn-private-key are required when cdn-domain is set")
+	}
+
+	privateKey, err := sign.LoadPEMPrivKeyFile(cfg.cdn.privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return sign.NewURLSigner(cfg.cdn.keyPairID, privateKey), nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"time"
+)
+
+// publishPoolMetrics exposes the stats of each connection pool under
+// "database" at /debug/vars.
+func publishPoolMetrics(pools map[string]*sql.DB) {
+	expvar.Publish("database", expvar.Func(func() any {
+		stats := make(map[string]sql.DBStats, len(pools))
+		for name, db := range pools {
+			stats[name] = db.Stats()
+		}
+		return stats
+	}))
+}
+
+// runPoolMonitor warns once a minute about pools where requests had to
+// wait for a free connection, a sign that max open connections is too low
+// for the load.
+func (app *application) runPoolMonitor(pools map[string]*sql.DB) {
+	last := make(map[string]sql.DBStats, len(pools))
+	for name, db := range pools {
+		last[name] = db.Stats()
+	}
+
+	for {
+		time.Sleep(time.Minute)
+
+		for name, db := range pools {
+			stats := db.Stats()
+			waits := stats.WaitCount - last[name].WaitCount
+
+			if waits > 0 {
+				app.logger.Warn("database pool exhausted",
+					"pool", name,
+					"waits", waits,
+					"wait_duration", stats.WaitDuration-last[name].WaitDuration,
+					"max_open", stats.MaxOpenConnections,
+					"in_use", stats.InUse,
+				)
+			}
+
+			last[name] = stats
+		}
+	}
+}
+
+// autoMaxConns divides the connections Postgres accepts from ordinary
+// roles evenly across the API instances sharing the server.
+func autoMaxConns(ctx context.Context, db *sql.DB, instances int) (int, error) {
+	query := `
+		SELECT current_setting('max_connections')::int - current_setting('superuser_reserved_connections')::int`
+
+	var available int
+
+	err := db.QueryRowContext(ctx, query).Scan(&available)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(1, available/instances), nil
+}
@@ -1,21 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"golang.org/x/time/rate"
 )
 
 func (app *application) recoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				err := fmt.Errorf("%v", rec)
+
+				app.logCrash(r, err, debug.Stack())
+				app.reportError(r, err)
+
 				w.Header().Set("Connection", "close")
-				app.serverErrorResponse(w, r, fmt.Errorf("%s", err))
+				app.internalErrorResponse(w, r)
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -67,21 +82,67 @@ func (app *application) rateLimit(next http.Handler) http.Handler {
 				}
 			}
 
-			clients[ip].lastSeen = time.Now()
+			now := time.Now()
+			clients[ip].lastSeen = now
+
+			allowed := clients[ip].limiter.AllowN(now, 1)
+			quota := app.rateLimitQuota(clients[ip].limiter.TokensAt(now))
 
-			if !clients[ip].limiter.Allow() {
-				mu.Unlock()
+			mu.Unlock()
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(quota.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(quota.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(quota.Reset))
+
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(quota.RetryAfter))
 				app.rateLimitExceededResponse(w, r)
 				return
 			}
 
-			mu.Unlock()
+			ctx := context.WithValue(r.Context(), rateLimitContextKey, quota)
+			r = r.WithContext(ctx)
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
 
+const rateLimitContextKey = contextKey("rateLimit")
+
+// rateLimitQuota describes a client's token bucket. Limit is the burst size,
+// Reset the seconds until the bucket is full again and RetryAfter the
+// seconds until the next request is allowed.
+type rateLimitQuota struct {
+	Limit      int `json:"limit"`
+	Remaining  int `json:"remaining"`
+	Reset      int `json:"reset"`
+	RetryAfter int `json:"retry_after"`
+}
+
+func (app *application) rateLimitQuota(tokens float64) rateLimitQuota {
+	rps := app.config.limiter.rps
+	burst := float64(app.config.limiter.burst)
+
+	quota := rateLimitQuota{
+		Limit:     app.config.limiter.burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     int(math.Ceil((burst - tokens) / rps)),
+	}
+
+	if tokens < 1 {
+		quota.RetryAfter = int(math.Ceil((1 - tokens) / rps))
+	}
+
+	return quota
+}
+
+// trustedOrigin reports whether origin is one of the frontends, which may
+// make credentialed cross-origin requests.
+func trustedOrigin(origin string) bool {
+	return origin == "https://wanton.app" || origin == "https://www.wanton.app" || origin == "http://localhost:5173" || origin == "http://localhost:9000"
+}
+
 func (app *application) enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Origin")
@@ -89,12 +150,13 @@ func (app *application) enableCORS(next http.Handler) http.Handler {
 
 		origin := r.Header.Get("Origin")
 		if origin != "" {
-			if origin == "https://wanton.app" || origin == "https://www.wanton.app" || origin == "http://localhost:5173" || origin == "http://localhost:9000" {
+			if trustedOrigin(origin) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Set("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After")
 
 				if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
-					w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, PUT, PATCH, DELETE")
+					w.Header().Set("Access-Control-Allow-Methods", strings.Join(app.allowedMethods(r), ", "))
 					w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
 
 					w.WriteHeader(http.StatusOK)
@@ -106,3 +168,50 @@ func (app *application) enableCORS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// allowedMethods lists the methods the router serves for the request path,
+// with HEAD wherever GET is served and OPTIONS wherever anything is.
+func (app *application) allowedMethods(r *http.Request) []string {
+	routes := chi.RouteContext(r.Context()).Routes
+
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+
+	var allowed []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		if routes.Match(chi.NewRouteContext(), method, path) {
+			allowed = append(allowed, method)
+			if method == http.MethodGet {
+				allowed = append(allowed, http.MethodHead)
+			}
+		}
+	}
+
+	if len(allowed) > 0 {
+		allowed = append(allowed, http.MethodOptions)
+	}
+
+	return allowed
+}
+
+// handleOptions answers OPTIONS requests that are not CORS preflights with
+// the methods the resource supports in the Allow header.
+func (app *application) handleOptions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed := app.allowedMethods(r)
+		if len(allowed) == 0 {
+			app.notFoundResponse(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
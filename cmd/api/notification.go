@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// notify records a notification for a user. Failures are logged rather than
+// returned so they never undo the action that caused the notification.
+func (app *application) notify(userID int32, category, message, link string) {
+	n := &data.Notification{
+		UserID:   userID,
+		Category: category,
+		Message:  message,
+	}
+	if link != "" {
+		n.Link = &link
+	}
+
+	v := validator.New()
+	if data.ValidateNotification(v, n); !v.Valid() {
+		app.logger.Error("invalid notification", "user_id", userID, "category", category, "errors", v.Errors)
+		return
+	}
+
+	err := app.models.Notification.Insert(n)
+	if err != nil {
+		app.logger.Error("notification failed", "user_id", userID, "category", category, "error", err.Error())
+		return
+	}
+
+	app.emailNotification(n)
+}
+
+// emailNotification emails a stored notification to its user, unless they
+// muted its category in their preferences.
+func (app *application) emailNotification(n *data.Notification) {
+	if !app.emailEnabled() {
+		return
+	}
+
+	app.background(func() {
+		enabled, err := app.models.Preference.EmailEnabled(n.UserID, n.Category)
+		if err != nil {
+			app.logger.Error("notification email failed", "user_id", n.UserID, "category", n.Category, "error", err.Error())
+			return
+		}
+		if !enabled {
+			return
+		}
+
+		user, err := app.models.User.Get(n.UserID)
+		if err != nil {
+			app.logger.Error("notification email failed", "user_id", n.UserID, "category", n.Category, "error", err.Error())
+			return
+		}
+
+		err = app.userMailer(user.InternalID).Send(user.Email, "notification.tmpl", map[string]any{
+			"firstName": user.FirstName,
+			"category":  n.Category,
+			"message":   n.Message,
+		})
+		if err != nil {
+			app.logger.Error("notification email failed", "user_id", n.UserID, "category", n.Category, "error", err.Error())
+		}
+	})
+}
+
+func (app *application) showNotificationPreferenceHandler(w http.ResponseWriter, r *http.Request) {
+	preferences, err := app.models.Preference.GetAllForUser(app.contextGetUser(r).InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"preferences": preferences}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateNotificationPreferenceHandler sets whether the caller is emailed
+// notifications of the listed categories. Categories left out keep their
+// current setting.
+func (app *application) updateNotificationPreferenceHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Preferences []*data.NotificationPreference `json:"preferences"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(len(input.Preferences) > 0, "preferences", "must be provided")
+	for _, preference := range input.Preferences {
+		data.ValidateNotificationPreference(v, preference)
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.WithTx(r.Context(), func(tx data.Models) error {
+		for _, preference := range input.Preferences {
+			err := tx.Preference.Upsert(user.InternalID, preference)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	preferences, err := app.models.Preference.GetAllForUser(user.InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"preferences": preferences}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// notifyApprover notifies an approver along with whoever is covering for
+// them today through a delegation.
+func (app *application) notifyApprover(approverID int32, message, link string) {
+	app.notify(approverID, data.NotificationApproval, message, link)
+
+	delegates, err := app.models.Delegation.GetActiveDelegates(approverID, time.Now())
+	if err != nil {
+		app.logger.Error("notification failed", "user_id", approverID, "error", err.Error())
+		return
+	}
+
+	for _, delegate := range delegates {
+		app.notify(delegate, data.NotificationApproval, message, link)
+	}
+}
+
+func (app *application) listNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Unread bool
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	unread := app.readString(qs, "unread", "false")
+	v.Check(validator.PermittedValue(unread, "true", "false"), "unread", "must be true or false")
+	input.Unread = unread == "true"
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = app.readString(qs, "sort", "-created_at")
+	input.Filters.SortSafelist = []string{"created_at", "-created_at"}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID := app.contextGetUser(r).InternalID
+
+	notifications, metadata, err := app.models.Notification.GetAllForUser(userID, input.Unread, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	unreadCount, err := app.models.Notification.CountUnread(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"metadata": metadata, "unread_count": unreadCount, "notifications": notifications}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) markNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt64IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Notification.MarkRead(app.contextGetUser(r).InternalID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "notification marked as read"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) markAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	updated, err := app.models.Notification.MarkAllRead(app.contextGetUser(r).InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"updated": updated}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
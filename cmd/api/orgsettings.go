@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/mailer"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func branding(s *data.OrgSettings) *mailer.Branding {
+	return &mailer.Branding{
+		SenderName:   s.SenderName,
+		SenderEmail:  s.SenderEmail,
+		ReplyTo:      s.ReplyTo,
+		LogoURL:      s.LogoURL,
+		PrimaryColor: s.PrimaryColor,
+		AccentColor:  s.AccentColor,
+	}
+}
+
+// orgMailer returns the mailer for emails sent on behalf of an
+// organization, branded with its settings. Emails still go out, unbranded,
+// when the settings cannot be read.
+func (app *application) orgMailer(orgID int32) mailer.Mailer {
+	s, err := app.models.OrgSettings.Get(orgID)
+	if err != nil {
+		app.logger.Error("organization settings unavailable", "organization_id", orgID, "error", err.Error())
+		return app.mailer
+	}
+
+	return app.mailer.WithBranding(branding(s))
+}
+
+// userMailer returns the mailer for emails sent to a user, in their language
+// and branded with the settings of their organization.
+func (app *application) userMailer(userID int32) mailer.Mailer {
+	m := app.mailer
+
+	lang, err := app.models.User.GetLanguage(userID)
+	if err != nil {
+		app.logger.Error("user language unavailable", "user_id", userID, "error", err.Error())
+	} else {
+		m = m.WithLanguage(lang)
+	}
+
+	s, err := app.models.OrgSettings.GetForUser(userID)
+	if err != nil {
+		app.logger.Error("organization settings unavailable", "user_id", userID, "error", err.Error())
+		return m
+	}
+
+	return m.WithBranding(branding(s))
+}
+
+func (app *application) showOrgSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	settings, err := app.models.OrgSettings.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"settings": settings}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateOrgSettingsHandler changes the fields given in the body. An empty
+// string puts a field back to the default.
+func (app *application) updateOrgSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	settings, err := app.models.OrgSettings.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		SenderName   *string `json:"sender_name"`
+		SenderEmail  *string `json:"sender_email"`
+		ReplyTo      *string `json:"reply_to"`
+		LogoURL      *string `json:"logo_url"`
+		PrimaryColor *string `json:"primary_color"`
+		AccentColor  *string `json:"accent_color"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.SenderName != nil {
+		settings.SenderName = *input.SenderName
+	}
+	if input.SenderEmail != nil {
+		settings.SenderEmail = *input.SenderEmail
+	}
+	if input.ReplyTo != nil {
+		settings.ReplyTo = *input.ReplyTo
+	}
+	if input.LogoURL != nil {
+		settings.LogoURL = *input.LogoURL
+	}
+	if input.PrimaryColor != nil {
+		settings.PrimaryColor = *input.PrimaryColor
+	}
+	if input.AccentColor != nil {
+		settings.AccentColor = *input.AccentColor
+	}
+
+	v := validator.New()
+	if data.ValidateOrgSettings(v, settings); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.OrgSettings.Update(settings)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"settings": settings}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
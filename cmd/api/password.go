@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/pwned"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// newPasswordPolicy builds the password policy from the -password-* flags,
+// reading the denylist file when one is given.
+func newPasswordPolicy(cfg config) (data.PasswordPolicy, error) {
+	policy := data.PasswordPolicy{MinLength: cfg.password.minLength}
+
+	if policy.MinLength < 8 || policy.MinLength > 72 {
+		return data.PasswordPolicy{}, errors.New("password-min-length must be between 8 and 72")
+	}
+
+	if cfg.password.require != "" {
+		for _, class := range strings.Split(cfg.password.require, ",") {
+			class = strings.TrimSpace(class)
+			if _, ok := data.PasswordClasses[class]; !ok {
+				return data.PasswordPolicy{}, fmt.Errorf("password-require: unknown character class %q", class)
+			}
+
+			policy.Require = append(policy.Require, class)
+		}
+	}
+
+	if cfg.password.denylist != "" {
+		list, err := os.ReadFile(cfg.password.denylist)
+		if err != nil {
+			return data.PasswordPolicy{}, err
+		}
+
+		policy.Denylist = data.ParseDenylist(string(list))
+	}
+
+	if cfg.password.breachCheck {
+		checker := pwned.New()
+		policy.Breached = func(password string) (int, error) {
+			return checker.Count(context.Background(), password)
+		}
+	}
+
+	return policy, nil
+}
+
+// checkPasswordHandler tells whether a password meets the password policy,
+// naming the first rule it breaks, so frontends can check one as it is
+// typed. When the breach check cannot be reached the password is accepted.
+func (app *application) checkPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Password string `json:"password"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	err = data.ValidatePasswordPlaintext(v, input.Password, app.password)
+	if err != nil {
+		app.requestLogger(r).Warn("password breach check failed", "error", err.Error())
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "password meets the policy"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
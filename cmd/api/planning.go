@@ -0,0 +1,342 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// capacityHandler reports the available and committed hours of each user in
+// the caller's organization per week between from and to, optionally for one
+// team. Both dates are widened to whole weeks.
+func (app *application) capacityHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	var from, to *time.Time
+	if s := qs.Get("from"); s != "" {
+		from = app.parseDate(v, "from", s)
+	} else {
+		v.AddError("from", "must be provided")
+	}
+	if s := qs.Get("to"); s != "" {
+		to = app.parseDate(v, "to", s)
+	} else {
+		v.AddError("to", "must be provided")
+	}
+
+	teamID := app.readInt(qs, "team_id", 0, v)
+	v.Check(teamID >= 0, "team_id", "must not be negative")
+
+	if from != nil && to != nil {
+		v.Check(!to.Before(*from), "to", "must not be before from")
+		v.Check(to.Sub(*from) <= 366*24*time.Hour, "to", "must be within a year of from")
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	capacity, err := app.models.Planning.GetCapacity(actor, int32(teamID), *from, *to, app.config.planning.weeklyHours)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"capacity": capacity}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// canManageLeave reports whether the actor may record or remove the user's
+// leave: users manage their own, and organization admins and the leads of
+// the user's teams manage anyone's. Otherwise it sends the error response.
+func (app *application) canManageLeave(w http.ResponseWriter, r *http.Request, actor data.Actor, userID int32) bool {
+	if userID == actor.UserID || isOrgAdmin(actor, actor.OrgID) {
+		return true
+	}
+
+	isLead, err := app.models.Team.IsLead(actor.UserID, userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	if !isLead {
+		app.notPermittedResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
+// createLeaveHandler records leave for the caller, or for the user_id given
+// when the caller manages their leave.
+func (app *application) createLeaveHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		UserID   int32  `json:"user_id"`
+		StartsOn string `json:"starts_on"`
+		EndsOn   string `json:"ends_on"`
+		Note     string `json:"note"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if input.UserID == 0 {
+		input.UserID = actor.UserID
+	}
+	if !app.canManageLeave(w, r, actor, input.UserID) {
+		return
+	}
+
+	v := validator.New()
+
+	leave := &data.Leave{
+		UserID: input.UserID,
+		Note:   input.Note,
+	}
+
+	if startsOn := app.parseDate(v, "starts_on", input.StartsOn); startsOn != nil {
+		leave.StartsOn = *startsOn
+	}
+	if endsOn := app.parseDate(v, "ends_on", input.EndsOn); endsOn != nil {
+		leave.EndsOn = *endsOn
+	}
+
+	if data.ValidateLeave(v, leave); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Leave.Insert(actor, leave)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("user_id", "must be an existing user")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/leave/%d", leave.InternalID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"leave": leave}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) listLeaveHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	userID := app.readInt(qs, "user_id", 0, v)
+	v.Check(userID >= 0, "user_id", "must not be negative")
+
+	var from, to *time.Time
+	if s := qs.Get("from"); s != "" {
+		from = app.parseDate(v, "from", s)
+	}
+	if s := qs.Get("to"); s != "" {
+		to = app.parseDate(v, "to", s)
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	leave, err := app.models.Leave.GetAll(actor, int32(userID), from, to)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"leave": leave}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteLeaveHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	leave, err := app.models.Leave.Get(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.canManageLeave(w, r, actor, leave.UserID) {
+		return
+	}
+
+	err = app.models.Leave.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "leave successfully deleted", deletedResource{Resource: "leave", ID: id})
+}
+
+// createHolidayHandler adds a holiday to the caller's organization.
+func (app *application) createHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Day  string `json:"day"`
+		Name string `json:"name"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	holiday := &data.Holiday{Name: input.Name}
+	if day := app.parseDate(v, "day", input.Day); day != nil {
+		holiday.Day = *day
+	}
+
+	if data.ValidateHoliday(v, holiday); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Holiday.Insert(actor.OrgID, holiday)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateHoliday):
+			v.AddError("day", "is already a holiday")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/holiday/%d", holiday.InternalID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"holiday": holiday}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listHolidayHandler lists the caller's organization's holidays in a year,
+// the current one by default.
+func (app *application) listHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	year := app.readInt(r.URL.Query(), "year", time.Now().Year(), v)
+	if v.Check(year >= 1900 && year <= 2100, "year", "must be between 1900 and 2100"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	holidays, err := app.models.Holiday.GetAll(actor.OrgID, from, from.AddDate(1, 0, -1))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"holidays": holidays}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteHolidayHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Holiday.Delete(actor.OrgID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "holiday successfully deleted", deletedResource{Resource: "holiday", ID: id})
+}
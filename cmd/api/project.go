@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/hwanbin/wanpm-api/internal/data"
@@ -27,7 +29,21 @@ func (app *application) createProjectHandler(w http.ResponseWriter, r *http.Requ
 		app.missingRequiredFieldsResponse(w, r, v.Errors)
 		return
 	}
-	if data.ValidateProjectInputSemantic(v, &input); !v.Valid() {
+	data.ValidateProjectInputSemantic(v, &input)
+
+	err = app.validateCustomValues(r, v, "project", input.CustomFields)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.validateCodes(v, r, input.ExternalID, input.ProposalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
@@ -39,40 +55,55 @@ func (app *application) createProjectHandler(w http.ResponseWriter, r *http.Requ
 	}
 	inputFeature := string(feature)
 
-	project := &data.ProjectRequest{
-		ExternalID: input.ExternalID,
-		ProposalID: input.ProposalID,
-		Name:       input.Name,
-		Status:     input.Status,
-		Feature:    &inputFeature,
-		Images:     input.Images,
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
 
-	project.Clients = []data.ProjectClient{}
-	for _, clientName := range input.ClientNames {
-		client, err := app.models.Client.GetClientByName(clientName)
+	project := &data.ProjectRequest{
+		ExternalID:   input.ExternalID,
+		ProposalID:   input.ProposalID,
+		Name:         input.Name,
+		Status:       input.Status,
+		Feature:      &inputFeature,
+		Images:       input.Images,
+		CustomFields: input.CustomFields,
+		OrgID:        actor.OrgID,
+	}
+
+	// The client and proposal lookups, the insert and the read back share a
+	// transaction so a failure part way leaves nothing behind.
+	var missingClient, missingProposal string
+	var projectResponse *data.ProjectResponse
+
+	err = app.models.WithTx(r.Context(), func(tx data.Models) error {
+		project.Clients, missingClient, err = lookupProjectClients(tx, actor, input.ClientNames)
 		if err != nil {
-			switch {
-			case errors.Is(err, data.ErrRecordNotFound):
-				v.AddError("client_names", fmt.Sprintf("%s cannot be found", clientName))
-				app.failedValidationResponse(w, r, v.Errors)
-			default:
-				app.serverErrorResponse(w, r, err)
-			}
-			return
+			return err
 		}
-		project.Clients = append(project.Clients, data.ProjectClient{
-			ClientID:      &client.InternalID,
-			ClientName:    client.Name,
-			ClientLogo:    client.LogoURL,
-			ClientAddress: client.Address,
-			ClientNote:    client.Note,
-		})
-	}
 
-	err = app.models.Project.Insert(project)
+		missingProposal, err = app.linkProposal(tx, actor, project.ProposalID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Project.Insert(project)
+		if err != nil {
+			return err
+		}
+
+		projectResponse, err = tx.Project.Get(data.SystemActor, *project.ExternalID)
+		return err
+	})
 	if err != nil {
 		switch {
+		case missingClient != "":
+			v.AddError("client_names", fmt.Sprintf("%s cannot be found", missingClient))
+			app.failedValidationResponse(w, r, v.Errors)
+		case missingProposal != "":
+			v.AddError("proposal_id", "must be an existing proposal")
+			app.failedValidationResponse(w, r, v.Errors)
 		case errors.Is(err, data.ErrDuplicateProjectID):
 			v.AddError("project_id", "a project with this project_id already exists")
 			app.failedValidationResponse(w, r, v.Errors)
@@ -85,12 +116,6 @@ func (app *application) createProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	projectResponse, err := app.models.Project.Get(*project.ExternalID)
-	if err != nil {
-		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Errorf("unable to get the project: %v", err))
-		return
-	}
-
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/project/%d", *projectResponse.ExternalID))
 
@@ -100,6 +125,42 @@ func (app *application) createProjectHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// lookupProjectClients resolves client names to project clients of the
+// actor's organization. When a name is unknown it is returned alongside
+// ErrRecordNotFound.
+func lookupProjectClients(models data.Models, actor data.Actor, names []string) ([]data.ProjectClient, string, error) {
+	clients := []data.ProjectClient{}
+	for _, name := range names {
+		client, err := models.Client.GetClientByName(actor, name)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				return nil, name, err
+			}
+			return nil, "", err
+		}
+		clients = append(clients, data.ProjectClient{
+			ClientID:      &client.InternalID,
+			ClientName:    client.Name,
+			ClientLogo:    client.LogoURL,
+			ClientAddress: client.Address,
+			ClientNote:    client.Note,
+		})
+	}
+
+	return clients, "", nil
+}
+
+// project returns the project with the external ID if the request's actor
+// may see it, and ErrRecordNotFound otherwise.
+func (app *application) project(r *http.Request, externalID int32) (*data.ProjectResponse, error) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return app.models.Project.Get(actor, externalID)
+}
+
 func (app *application) showProjectHandler(w http.ResponseWriter, r *http.Request) {
 	externalID, err := app.readInt32IDParam(r)
 	if err != nil {
@@ -107,7 +168,7 @@ func (app *application) showProjectHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	project, err := app.models.Project.Get(externalID)
+	project, err := app.project(r, externalID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -118,6 +179,10 @@ func (app *application) showProjectHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if app.notModifiedSince(w, r, project.UpdatedAt) {
+		return
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"project": project}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -152,6 +217,10 @@ func (app *application) toProject(project *data.ProjectResponse, input *data.Pro
 			project.Images = input.Images
 		}
 	}
+
+	if input.CustomFields != nil {
+		project.CustomFields = project.CustomFields.Merge(input.CustomFields)
+	}
 }
 
 func (app *application) updateProjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -161,7 +230,7 @@ func (app *application) updateProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	project, err := app.models.Project.Get(externalID)
+	project, err := app.project(r, externalID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -172,6 +241,11 @@ func (app *application) updateProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
 	var input data.ProjectInput
 	err = app.readJSON(w, r, &input)
 	if err != nil {
@@ -185,8 +259,34 @@ func (app *application) updateProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Only changed codes must follow the code policy.
+	newProjectID, newProposalID := input.ExternalID, input.ProposalID
+	if newProjectID != nil && project.ExternalID != nil && *newProjectID == *project.ExternalID {
+		newProjectID = nil
+	}
+	if newProposalID != nil && project.ProposalID != nil && *newProposalID == *project.ProposalID {
+		newProposalID = nil
+	}
+
 	app.toProject(project, &input)
 
+	err = app.validateCustomValues(r, v, "project", project.CustomFields)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.validateCodes(v, r, newProjectID, newProposalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
 	feature, err := json.Marshal(project.Feature)
 	if err != nil {
 		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Errorf("failed to marshal feature json: %v", err))
@@ -195,47 +295,59 @@ func (app *application) updateProjectHandler(w http.ResponseWriter, r *http.Requ
 	inputFeature := string(feature)
 
 	projectRequest := &data.ProjectRequest{
-		InternalID: project.InternalID,
-		ExternalID: project.ExternalID,
-		ProposalID: project.ProposalID,
-		Name:       project.Name,
-		Status:     project.Status,
-		Feature:    &inputFeature,
-		Images:     project.Images,
-		Version:    project.Version,
-		CreatedAt:  project.CreatedAt,
-		UpdatedAt:  project.UpdatedAt,
-	}
-
-	if input.ClientNames != nil {
-		projectRequest.Clients = []data.ProjectClient{}
-		for _, clientName := range input.ClientNames {
-			client, err := app.models.Client.GetClientByName(clientName)
+		InternalID:   project.InternalID,
+		ExternalID:   project.ExternalID,
+		ProposalID:   project.ProposalID,
+		Name:         project.Name,
+		Status:       project.Status,
+		Feature:      &inputFeature,
+		Images:       project.Images,
+		CustomFields: project.CustomFields,
+		Version:      project.Version,
+		CreatedAt:    project.CreatedAt,
+		UpdatedAt:    project.UpdatedAt,
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var missingClient, missingProposal string
+	var projectResponse *data.ProjectResponse
+
+	err = app.models.WithTx(r.Context(), func(tx data.Models) error {
+		projectRequest.Clients = project.Clients
+		if input.ClientNames != nil {
+			projectRequest.Clients, missingClient, err = lookupProjectClients(tx, actor, input.ClientNames)
 			if err != nil {
-				switch {
-				case errors.Is(err, data.ErrRecordNotFound):
-					v.AddError("client_names", fmt.Sprintf("%s cannot be found", clientName))
-					app.failedValidationResponse(w, r, v.Errors)
-				default:
-					app.serverErrorResponse(w, r, err)
-				}
-				return
+				return err
 			}
-			projectRequest.Clients = append(projectRequest.Clients, data.ProjectClient{
-				ClientID:      &client.InternalID,
-				ClientName:    client.Name,
-				ClientLogo:    client.LogoURL,
-				ClientAddress: client.Address,
-				ClientNote:    client.Note,
-			})
 		}
-	} else {
-		projectRequest.Clients = project.Clients
-	}
 
-	err = app.models.Project.Update(projectRequest)
+		// A proposal_id saved before linking was enforced is kept as it is.
+		missingProposal, err = app.linkProposal(tx, actor, newProposalID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Project.Update(projectRequest)
+		if err != nil {
+			return err
+		}
+
+		projectResponse, err = tx.Project.Get(data.SystemActor, *projectRequest.ExternalID)
+		return err
+	})
 	if err != nil {
 		switch {
+		case missingClient != "":
+			v.AddError("client_names", fmt.Sprintf("%s cannot be found", missingClient))
+			app.failedValidationResponse(w, r, v.Errors)
+		case missingProposal != "":
+			v.AddError("proposal_id", "must be an existing proposal")
+			app.failedValidationResponse(w, r, v.Errors)
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
 		default:
@@ -244,12 +356,64 @@ func (app *application) updateProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	projectResponse, err := app.models.Project.Get(*projectRequest.ExternalID)
+	err = app.writeJSON(w, http.StatusOK, envelope{"project": projectResponse}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// archiveProjectHandler makes a project read-only, hides it from default
+// listings and tags its files for transition to cold storage.
+func (app *application) archiveProjectHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	project, err := app.project(r, externalID)
 	if err != nil {
-		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Errorf("unable to get the project: %v", err))
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
-	err = app.writeJSON(w, http.StatusOK, envelope{"project": projectResponse}, nil)
+
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
+	err = app.models.Project.Archive(project)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.storageEnabled() {
+		app.background(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			defer cancel()
+
+			keys, err := s3action.TagForArchive(ctx, app.s3actor.client, app.config.s3.bucket, projectPrefix(externalID))
+			if err != nil {
+				app.requestLogger(r).Error("archive tagging failed", "project_id", externalID, "tagged", len(keys), "error", err.Error())
+				return
+			}
+
+			app.requestLogger(r).Info("project archived", "project_id", externalID, "tagged", len(keys))
+		})
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"project": project}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -262,7 +426,7 @@ func (app *application) deleteProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	project, err := app.models.Project.Get(externalID)
+	project, err := app.project(r, externalID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -273,29 +437,56 @@ func (app *application) deleteProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var objects []types.ObjectIdentifier
-	fileNames, err := s3action.ListObjects(
-		app.s3actor.client,
-		app.config.s3.bucket,
-		strconv.Itoa(int(externalID)),
-	)
-	for _, fileName := range fileNames {
-		objects = append(
-			objects,
-			types.ObjectIdentifier{
-				Key: &fileName,
-			},
-		)
+	force := app.readString(r.URL.Query(), "force", "false")
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(force, "true", "false"), "force", "must be true or false"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
 	}
+
+	dependents, err := app.models.Project.CountDependents(project.InternalID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if dependents["timesheet_entries"] > 0 {
+		if force != "true" {
+			app.projectHasDependentsResponse(w, r, dependents)
+			return
+		}
+
+		if !app.requirePermission(w, r, "project:force-delete") {
+			return
+		}
+	}
+
+	var objects []types.ObjectIdentifier
+	if app.storageEnabled() {
+		fileNames, err := s3action.ListObjects(
+			app.s3actor.client,
+			app.config.s3.bucket,
+			projectPrefix(externalID),
+		)
+		for _, fileName := range fileNames {
+			objects = append(
+				objects,
+				types.ObjectIdentifier{
+					Key: &fileName,
+				},
+			)
+		}
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
 	err = app.models.Project.Delete(
 		project.InternalID,
 		app.config.s3.bucket,
-		strconv.Itoa(int(externalID)),
+		projectPrefix(externalID),
 		app.s3actor.client,
 		objects,
 	)
@@ -309,11 +500,60 @@ func (app *application) deleteProjectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "project successfully deleted"}, nil)
+	message := "project successfully deleted"
+	if app.config.retention.deletedProjects > 0 {
+		message = fmt.Sprintf("project deleted, it can be restored until %s", time.Now().Add(app.config.retention.deletedProjects).UTC().Format(time.DateOnly))
+	}
+
+	app.deletedResponse(w, r, message, deletedResource{
+		Resource: "project",
+		ID:       externalID,
+		Cascade:  map[string]int{"files": len(objects)},
+	})
+}
+
+// undeleteProjectHandler restores a deleted project and its files while the
+// deletion grace period has not run out.
+func (app *application) undeleteProjectHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	deletedAt, err := app.models.Project.Undelete(externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if app.storageEnabled() {
+		// Allow for clock skew between the database and S3, the delete
+		// markers are placed just before deleted_at is set.
+		since := deletedAt.Add(-time.Minute)
+
+		_, err = s3action.RestoreDeletedSince(r.Context(), app.s3actor.client, app.config.s3.bucket, projectPrefix(externalID), since)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	project, err := app.project(r, externalID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"project": project}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
 }
 
 func (app *application) listProjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -322,6 +562,11 @@ func (app *application) listProjectHandler(w http.ResponseWriter, r *http.Reques
 	qs := r.URL.Query()
 	v := validator.New()
 
+	if qs.Has("ids") {
+		app.batchProjectHandler(w, r, app.readCSV(qs, "ids", []string{}), v)
+		return
+	}
+
 	input.Name = app.readString(qs, "name", "")
 	input.Status = app.readString(qs, "status", "")
 	input.ProposalId = app.readString(qs, "proposal_id", "")
@@ -330,6 +575,21 @@ func (app *application) listProjectHandler(w http.ResponseWriter, r *http.Reques
 	input.ClientName = app.readString(qs, "client_name", "")
 	input.Clients = app.readCSV(qs, "clients", []string{})
 	input.Bbox = app.readCSV(qs, "bbox", nil)
+	input.Tags = data.NormalizeTags(app.readCSV(qs, "tags", nil))
+
+	includeArchived := app.readString(qs, "include_archived", "false")
+	v.Check(validator.PermittedValue(includeArchived, "true", "false"), "include_archived", "must be true or false")
+	input.IncludeArchived = includeArchived == "true"
+
+	summary := app.readString(qs, "summary", "false")
+	v.Check(validator.PermittedValue(summary, "true", "false"), "summary", "must be true or false")
+
+	customFields, err := app.readCustomFieldFilter(r, qs, "project", v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	input.CustomFields = customFields
 
 	if data.ValidateQueryString(v, &input); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -355,14 +615,26 @@ func (app *application) listProjectHandler(w http.ResponseWriter, r *http.Reques
 	input.Filters.Page = app.readInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readInt(qs, "page_size", 0, v)
 	input.Filters.Sort = app.readString(qs, "sort", "project_id")
-	input.Filters.SortSafelist = []string{"project_id", "name", "status", "-project_id", "-name", "-status"}
+	input.Filters.SortSafelist = []string{"project_id", "name", "status", "created_at", "updated_at", "-project_id", "-name", "-status", "-created_at", "-updated_at"}
 
 	if data.ValidateFilters(v, input.Filters); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
+	if summary == "true" {
+		app.listProjectSummaryHandler(w, r, input, floatBbox, v)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	projects, metadata, err := app.models.Project.GetAll(
+		actor,
 		input,
 		floatBbox,
 	)
@@ -371,8 +643,78 @@ func (app *application) listProjectHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	err = app.attachProjectHealth(projects)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "projects": projects}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listProjectSummaryHandler serves ?summary=true: only the ID, proposal ID,
+// name and status of each project, for pickers. Filters on clients,
+// addresses, location and tags are rejected since the summary query does
+// not join them.
+func (app *application) listProjectSummaryHandler(w http.ResponseWriter, r *http.Request, input data.ProjectQsInput, bbox data.BoundingBox, v *validator.Validator) {
+	v.Check(input.ClientName == "" && len(input.Clients) == 0, "client_name", "is not supported with summary")
+	v.Check(input.FullAddress == "", "full_address", "is not supported with summary")
+	v.Check(!bbox.Valid, "bbox", "is not supported with summary")
+	v.Check(len(input.Tags) == 0, "tags", "is not supported with summary")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	projects, metadata, err := app.models.Project.GetAllSummaries(actor, input)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "projects": projects}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+func (app *application) batchProjectHandler(w http.ResponseWriter, r *http.Request, idStrings []string, v *validator.Validator) {
+	ids, err := data.ConvertToIDs(idStrings)
+	if err != nil {
+		v.AddError("ids", err.Error())
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if data.ValidateProjectIDs(v, ids); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	projects, err := app.models.Project.GetByIDs(actor, ids)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"projects": projects}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
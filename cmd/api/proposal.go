@@ -11,7 +11,8 @@ import (
 
 func (app *application) createProposalHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		ExternalID string `json:"proposal_id"`
+		ExternalID string  `json:"proposal_id"`
+		DueOn      *string `json:"due_on"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -20,12 +21,32 @@ func (app *application) createProposalHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	proposal := &data.Proposal{
 		ExternalID: input.ExternalID,
+		OrgID:      actor.OrgID,
 	}
 
 	v := validator.New()
-	if data.ValidateProposal(v, proposal); !v.Valid() {
+
+	if input.DueOn != nil {
+		proposal.DueOn = app.parseDate(v, "due_on", *input.DueOn)
+	}
+
+	data.ValidateProposal(v, proposal)
+
+	err = app.validateCodes(v, r, nil, &proposal.ExternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
@@ -45,6 +66,71 @@ func (app *application) createProposalHandler(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// linkProposal makes sure the proposal a project names exists, creating it
+// when -proposal-autocreate is set. Otherwise, with -proposal-require, a
+// missing proposal is reported by returning its ID alongside
+// ErrRecordNotFound. Proposals are looked up and created in the actor's
+// organization. A nil or empty proposalID links nothing.
+func (app *application) linkProposal(models data.Models, actor data.Actor, proposalID *string) (string, error) {
+	cfg := app.config.proposal
+	if proposalID == nil || *proposalID == "" || !cfg.require && !cfg.autoCreate {
+		return "", nil
+	}
+
+	_, err := models.Proposal.Get(actor, *proposalID)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		return "", err
+	}
+
+	if !cfg.autoCreate {
+		return *proposalID, err
+	}
+
+	return "", models.Proposal.Insert(&data.Proposal{ExternalID: *proposalID, OrgID: actor.OrgID})
+}
+
+// showProposalProjectHandler returns the project created from a proposal.
+func (app *application) showProposalProjectHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readStringIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	project, err := app.models.Project.GetByProposalID(actor, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"project": project}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// proposal returns the proposal with the ID if it belongs to the request's
+// actor's organization, and ErrRecordNotFound otherwise.
+func (app *application) proposal(r *http.Request, externalID string) (*data.Proposal, error) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return app.models.Proposal.Get(actor, externalID)
+}
+
 func (app *application) showProposalHandler(w http.ResponseWriter, r *http.Request) {
 	externalID, err := app.readStringIDParam(r)
 	if err != nil {
@@ -52,7 +138,7 @@ func (app *application) showProposalHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	proposal, err := app.models.Proposal.Get(externalID)
+	proposal, err := app.proposal(r, externalID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -76,7 +162,7 @@ func (app *application) updateProposalHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	proposal, err := app.models.Proposal.Get(externalID)
+	proposal, err := app.proposal(r, externalID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -88,7 +174,8 @@ func (app *application) updateProposalHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	var input struct {
-		ExternalID string `json:"proposal_id"`
+		ExternalID string  `json:"proposal_id"`
+		DueOn      *string `json:"due_on"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -97,9 +184,27 @@ func (app *application) updateProposalHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	v := validator.New()
+
+	// Only a changed code must follow the code policy.
+	if input.ExternalID != proposal.ExternalID {
+		err = app.validateCodes(v, r, nil, &input.ExternalID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
 	proposal.ExternalID = input.ExternalID
 
-	v := validator.New()
+	// An empty due_on clears the date.
+	if input.DueOn != nil {
+		proposal.DueOn = nil
+		if *input.DueOn != "" {
+			proposal.DueOn = app.parseDate(v, "due_on", *input.DueOn)
+		}
+	}
+
 	if data.ValidateProposal(v, proposal); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -129,7 +234,13 @@ func (app *application) deleteProposalHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err = app.models.Proposal.Delete(externalID)
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Proposal.Delete(actor, externalID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -140,8 +251,5 @@ func (app *application) deleteProposalHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "proposal successfully deleted"}, nil)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-	}
+	app.deletedResponse(w, r, "proposal successfully deleted", deletedResource{Resource: "proposal", ID: externalID})
 }
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/websocket"
+	"github.com/lib/pq"
+)
+
+const (
+	pushPingInterval = 30 * time.Second
+	// pushPongWait is how long a connection may stay silent, so a client
+	// missing one pong is not dropped.
+	pushPongWait = 2*pushPingInterval + 10*time.Second
+	// pushBuffer is how many notifications may wait for a slow connection
+	// before further ones are dropped. Clients catch up with GET
+	// /v1/notifications.
+	pushBuffer = 16
+)
+
+// notificationHub hands the notifications announced by the database to the
+// connections each user has open on this instance.
+type notificationHub struct {
+	mu          sync.Mutex
+	subscribers map[int32]map[chan *data.Notification]struct{}
+	closed      bool
+}
+
+// subscribe registers a connection for userID's notifications. The channel
+// is closed when the server shuts down; call the returned function once the
+// connection ends.
+func (h *notificationHub) subscribe(userID int32) (<-chan *data.Notification, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan *data.Notification, pushBuffer)
+
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if h.subscribers == nil {
+		h.subscribers = make(map[int32]map[chan *data.Notification]struct{})
+	}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan *data.Notification]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subscribers[userID], ch)
+		if len(h.subscribers[userID]) == 0 {
+			delete(h.subscribers, userID)
+		}
+	}
+}
+
+// publish passes n to its user's connections without waiting on any of them.
+func (h *notificationHub) publish(n *data.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[n.UserID] {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+}
+
+// close ends every subscription. The server does not track hijacked
+// connections, so it is registered to run on shutdown.
+func (h *notificationHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for _, chans := range h.subscribers {
+		for ch := range chans {
+			close(ch)
+		}
+	}
+	h.subscribers = nil
+}
+
+// runNotificationListener listens for the notifications stored by any API
+// instance and publishes them to this instance's connections. The listener
+// reconnects on its own when the database connection drops; notifications
+// stored meanwhile are not pushed.
+func (app *application) runNotificationListener() {
+	listener := pq.NewListener(app.config.db.dsn, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			app.logger.Error("notification listener failed", "error", err.Error())
+		}
+	})
+
+	err := listener.Listen(data.NotificationChannel)
+	if err != nil {
+		app.logger.Error("notification listener failed", "error", err.Error())
+		return
+	}
+
+	for {
+		select {
+		case event := <-listener.Notify:
+			// A nil event reports a reconnect.
+			if event == nil {
+				continue
+			}
+
+			n, err := data.ParseNotificationEvent(event.Extra)
+			if err != nil {
+				app.logger.Error("invalid notification event", "payload", event.Extra, "error", err.Error())
+				continue
+			}
+
+			app.push.publish(n)
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+// pushHandler upgrades to a WebSocket connection that pushes the user's new
+// notifications as they are stored. It sends {"unread_count": n} on connect,
+// then {"notification": {...}, "unread_count": n} for each notification.
+func (app *application) pushHandler(w http.ResponseWriter, r *http.Request) {
+	// Browsers send the refresh cookie on WebSocket handshakes from any
+	// site, so only the frontends may open a connection with it.
+	if origin := r.Header.Get("Origin"); origin != "" && !trustedOrigin(origin) {
+		app.errorResponse(w, r, http.StatusForbidden, "websocket connections are not allowed from this origin")
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	unreadCount, err := app.models.Notification.CountUnread(user.InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		switch {
+		case errors.Is(err, websocket.ErrBadHandshake):
+			w.Header().Set("Sec-WebSocket-Version", "13")
+			app.errorResponse(w, r, http.StatusUpgradeRequired, "this endpoint requires a WebSocket connection")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	defer conn.Close(websocket.CloseNormal)
+
+	conn.ReadTimeout = pushPongWait
+
+	notifications, unsubscribe := app.push.subscribe(user.InternalID)
+	defer unsubscribe()
+
+	// Clients have nothing to say; reading answers their pings and notices
+	// when they leave.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			_, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pushPingInterval)
+	defer ticker.Stop()
+
+	err = writePush(conn, envelope{"unread_count": unreadCount})
+
+	for err == nil {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				conn.Close(websocket.CloseGoingAway)
+				return
+			}
+
+			unreadCount, err = app.models.Notification.CountUnread(user.InternalID)
+			if err != nil {
+				app.logger.Error("counting unread notifications failed", "user_id", user.InternalID, "error", err.Error())
+				err = writePush(conn, envelope{"notification": n})
+				continue
+			}
+
+			err = writePush(conn, envelope{"notification": n, "unread_count": unreadCount})
+		case <-ticker.C:
+			err = conn.Ping()
+		case <-gone:
+			return
+		}
+	}
+}
+
+func writePush(conn *websocket.Conn, message envelope) error {
+	js, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return conn.WriteText(js)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+)
+
+// showQuotaHandler reports the caller's current rate limit consumption,
+// including this request.
+func (app *application) showQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	env := envelope{"quota": envelope{"enabled": false}}
+
+	quota, ok := r.Context().Value(rateLimitContextKey).(rateLimitQuota)
+	if ok {
+		env = envelope{"quota": envelope{
+			"enabled":             true,
+			"requests_per_second": app.config.limiter.rps,
+			"limit":               quota.Limit,
+			"remaining":           quota.Remaining,
+			"reset":               quota.Reset,
+		}}
+	}
+
+	err := app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// timesheetReportHandler sums the timesheet entries matching the usual
+// timesheet filters, grouped by the dimensions in group_by and limited to
+// the metrics asked for.
+func (app *application) timesheetReportHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	filter := app.readTimesheetFilter(qs, v)
+	groupBy := app.readCSV(qs, "group_by", nil)
+	metrics := app.readCSV(qs, "metrics", []string{"minutes"})
+
+	if data.ValidateReportParams(v, groupBy, metrics); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	report, err := app.models.Timesheet.Report(actor, filter, groupBy, metrics)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// runReportSnapshot rebuilds the weekly timesheet summary each time the
+// report_snapshot job runs.
+func (app *application) runReportSnapshot() {
+	app.runScheduled("report_snapshot", func() {
+		snapshot, err := app.models.Timesheet.RefreshWeekSummary()
+		if err != nil {
+			app.logger.Error("report snapshot refresh failed", "error", err.Error())
+			return
+		}
+
+		app.logger.Info("report snapshot refreshed", "through", snapshot.Through.Format(time.DateOnly), "rows", snapshot.Rows)
+	})
+}
+
+// showReportSnapshotHandler reports how far the weekly timesheet summary,
+// shared by every organization, is built.
+func (app *application) showReportSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "organization:admin-all") {
+		return
+	}
+
+	snapshot, err := app.models.Timesheet.GetWeekSummarySnapshot()
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"snapshot": snapshot}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// refreshReportSnapshotHandler rebuilds the weekly timesheet summary on
+// demand, for when past weeks were corrected since the last run.
+func (app *application) refreshReportSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "organization:admin-all") {
+		return
+	}
+
+	snapshot, err := app.models.Timesheet.RefreshWeekSummary()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"snapshot": snapshot}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
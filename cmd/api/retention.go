@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/s3action"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+type retentionRule struct {
+	name string
+	age  time.Duration
+	// purge removes what is older than cutoff, or only counts it when
+	// dryRun is set, and returns the count.
+	purge func(cutoff time.Time, dryRun bool) (int64, error)
+	// storage is set for rules that delete files, which are disabled when
+	// the server runs without S3.
+	storage bool
+}
+
+type retentionResult struct {
+	Rule   string    `json:"rule"`
+	Cutoff time.Time `json:"cutoff"`
+	Count  int64     `json:"count"`
+	Error  string    `json:"error,omitempty"`
+}
+
+type retentionReport struct {
+	DryRun  bool              `json:"dry_run"`
+	RanAt   time.Time         `json:"ran_at"`
+	Results []retentionResult `json:"results"`
+}
+
+// retentionRules returns the configured rules. A rule whose age is zero is
+// disabled, as are those deleting files when storage is not configured.
+func (app *application) retentionRules() []retentionRule {
+	rules := []retentionRule{
+		{
+			name:  "unactivated_users",
+			age:   app.config.retention.unactivatedUsers,
+			purge: app.models.User.PurgeUnactivated,
+		},
+		{
+			name:  "deleted_timesheets",
+			age:   app.config.retention.deletedTimesheets,
+			purge: app.models.Timesheet.PurgeDeleted,
+		},
+		{
+			name:    "deleted_projects",
+			age:     app.config.retention.deletedProjects,
+			purge:   app.purgeDeletedProjects,
+			storage: true,
+		},
+		{
+			name:    "s3_trash",
+			age:     app.config.s3.trashRetention,
+			purge:   app.purgeTrash,
+			storage: true,
+		},
+	}
+
+	enabled := rules[:0]
+	for _, rule := range rules {
+		if rule.age > 0 && (!rule.storage || app.storageEnabled()) {
+			enabled = append(enabled, rule)
+		}
+	}
+
+	return enabled
+}
+
+func (app *application) purgeTrash(cutoff time.Time, dryRun bool) (int64, error) {
+	if dryRun {
+		keys, err := s3action.ListExpiredTrash(app.s3actor.client, app.config.s3.bucket, "", cutoff)
+		return int64(len(keys)), err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	keys, err := s3action.PurgeTrash(ctx, app.s3actor.client, app.config.s3.bucket, "", cutoff)
+	return int64(len(keys)), err
+}
+
+// purgeDeletedProjects permanently removes projects deleted before cutoff,
+// together with every version of their files.
+func (app *application) purgeDeletedProjects(cutoff time.Time, dryRun bool) (int64, error) {
+	externalIDs, err := app.models.Project.GetAllDeletedBefore(cutoff)
+	if err != nil || dryRun {
+		return int64(len(externalIDs)), err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	var purged int64
+	for _, externalID := range externalIDs {
+		err = s3action.PermanentlyDeleteObjects(ctx, app.s3actor.client, app.config.s3.bucket, projectPrefix(externalID))
+		if err != nil {
+			return purged, err
+		}
+
+		err = s3action.PermanentlyDeleteObjects(ctx, app.s3actor.client, app.config.s3.bucket, projectMapPrefix(externalID))
+		if err != nil {
+			return purged, err
+		}
+
+		err = app.models.Project.Purge(externalID)
+		if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// applyRetention runs every enabled rule and records the report in the
+// audit log. A failing rule does not stop the others.
+func (app *application) applyRetention(dryRun bool) *retentionReport {
+	report := &retentionReport{
+		DryRun:  dryRun,
+		RanAt:   time.Now(),
+		Results: []retentionResult{},
+	}
+
+	for _, rule := range app.retentionRules() {
+		result := retentionResult{
+			Rule:   rule.name,
+			Cutoff: report.RanAt.Add(-rule.age),
+		}
+
+		count, err := rule.purge(result.Cutoff, dryRun)
+		result.Count = count
+		if err != nil {
+			result.Error = err.Error()
+			app.logger.Error("retention rule failed", "rule", rule.name, "error", err.Error())
+		} else {
+			app.logger.Info("retention rule applied", "rule", rule.name, "count", count, "dry_run", dryRun)
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	if !dryRun {
+		detail, err := json.Marshal(report)
+		if err == nil {
+			err = app.models.Audit.Insert(&data.AuditEntry{
+				Action:   "retention.purged",
+				Entity:   "retention",
+				EntityID: report.RanAt.UTC().Format(time.RFC3339),
+				Detail:   detail,
+			})
+		}
+		if err != nil {
+			app.logger.Error("retention report not recorded", "error", err.Error())
+		}
+	}
+
+	return report
+}
+
+func (app *application) runRetention() {
+	app.runScheduled("retention", func() {
+		app.applyRetention(app.config.retention.dryRun)
+	})
+}
+
+// runRetentionHandler applies the retention rules on demand. It is a dry run
+// unless dry_run=false is given. Retention spans every organization, so it
+// takes organization:admin-all.
+func (app *application) runRetentionHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "organization:admin-all") {
+		return
+	}
+
+	qs := r.URL.Query()
+	dryRun := app.readString(qs, "dry_run", "true")
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(dryRun, "true", "false"), "dry_run", "must be true or false"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	report := app.applyRetention(dryRun == "true")
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"retention": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
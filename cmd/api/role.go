@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) listRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if js, found := app.cache.Get("role:list"); found {
+		app.writeCachedJSON(w, r, js)
+		return
+	}
+
+	roles, err := app.models.Role.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	js, err := app.marshalJSON(envelope{"roles": roles})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.cache.Set("role:list", js)
+	app.writeCachedJSON(w, r, js)
+}
+
+// putRoleHandler creates a role or replaces its permissions. Users already
+// holding it are only brought in line by recomputeRolesHandler. As roles
+// grant permissions, changing them takes role:write.
+func (app *application) putRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "role:write") {
+		return
+	}
+
+	var input struct {
+		Permissions []string `json:"permissions"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := &data.Role{
+		Name:        chi.URLParam(r, "name"),
+		Permissions: input.Permissions,
+	}
+
+	v := validator.New()
+	if data.ValidateRole(v, role); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	created, err := app.models.Role.Put(role)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrUnknownPermission):
+			v.AddError("permissions", "must be existing permission codes")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cache.Invalidate("role:")
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	err = app.writeJSON(w, status, envelope{"role": role}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "role:write") {
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+
+	err := app.models.Role.Delete(name)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.cache.Invalidate("role:")
+
+	app.deletedResponse(w, r, "role successfully deleted", deletedResource{Resource: "role", ID: name})
+}
+
+// recomputeRolesHandler syncs every user's role permissions with the roles
+// they hold, after roles were changed or taken off projects.
+func (app *application) recomputeRolesHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "role:write") {
+		return
+	}
+
+	sync, err := app.models.Role.Recompute()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"sync": sync}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
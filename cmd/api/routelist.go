@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routePermissions lists the permissions handlers check for themselves,
+// which the router cannot see. Keep it next to the requirePermission calls
+// it mirrors.
+var routePermissions = map[string][]string{
+	// Only when force deleting a project that has timesheet entries.
+	"DELETE /v1/project/{id}":                                 {"project:force-delete"},
+	"DELETE /v1/user/{id}/personal-data":                      {"user:erase"},
+	"PUT /v1/admin/role/{name}":                               {"role:write"},
+	"DELETE /v1/admin/role/{name}":                            {"role:write"},
+	"POST /v1/admin/role/recompute":                           {"role:write"},
+	"GET /v1/admin/organization":                              {"organization:admin-all"},
+	"POST /v1/admin/organization":                             {"organization:admin-all"},
+	"DELETE /v1/admin/organization/{id}":                      {"organization:admin-all"},
+	"GET /v1/admin/organization/{id}":                         {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/admin/organization/{id}":                       {"organization:admin", "organization:admin-all"},
+	"GET /v1/admin/organization/{id}/settings":                {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/admin/organization/{id}/settings":              {"organization:admin", "organization:admin-all"},
+	"GET /v1/admin/organization/{id}/work-rules":              {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/admin/organization/{id}/work-rules":            {"organization:admin", "organization:admin-all"},
+	"GET /v1/admin/organization/{id}/code-policy":             {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/admin/organization/{id}/code-policy":           {"organization:admin", "organization:admin-all"},
+	"GET /v1/admin/organization/{id}/ldap":                    {"organization:admin", "organization:admin-all"},
+	"PUT /v1/admin/organization/{id}/ldap":                    {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/admin/organization/{id}/ldap":                 {"organization:admin", "organization:admin-all"},
+	"PUT /v1/admin/organization/{id}/ldap/links/{user_id}":    {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/admin/organization/{id}/ldap/links/{user_id}": {"organization:admin", "organization:admin-all"},
+	"GET /v1/admin/email-preview/{template}":                  {"organization:admin", "organization:admin-all"},
+	"POST /v1/export/full":                                    {"organization:admin", "organization:admin-all"},
+	"GET /v1/approval-steps":                                  {"approval:write"},
+	"PUT /v1/approval-steps":                                  {"approval:write"},
+	"POST /v1/import/users":                                   {"user:write"},
+	"POST /v1/import/timesheets":                              {"organization:admin", "organization:admin-all"},
+	"PUT /v1/user/{id}/hourly-cost":                           {"user:write-cost"},
+	"GET /v1/admin/routes":                                    {"organization:admin-all"},
+	"GET /v1/admin/schedules":                                 {"organization:admin-all"},
+	"PATCH /v1/admin/schedules":                               {"organization:admin-all"},
+	"GET /v1/export/accounting":                               {"organization:admin", "organization:admin-all"},
+	"PUT /v1/accounting/mapping/project/{id}":                 {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/accounting/mapping/project/{id}":              {"organization:admin", "organization:admin-all"},
+	"PUT /v1/accounting/mapping/activity/{id}":                {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/accounting/mapping/activity/{id}":             {"organization:admin", "organization:admin-all"},
+	"POST /v1/admin/retention/run":                            {"organization:admin-all"},
+	"POST /v1/activity":                                       {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/activity/{id}":                                 {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/activity/{id}":                                {"organization:admin", "organization:admin-all"},
+	"POST /v1/custom-field":                                   {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/custom-field/{id}":                             {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/custom-field/{id}":                            {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/tag/{id}":                                     {"organization:admin", "organization:admin-all"},
+	"GET /v1/report/snapshot":                                 {"organization:admin-all"},
+	"POST /v1/report/snapshot":                                {"organization:admin-all"},
+	"POST /v1/team":                                           {"organization:admin", "organization:admin-all"},
+	"PATCH /v1/team/{id}":                                     {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/team/{id}":                                    {"organization:admin", "organization:admin-all"},
+	"PUT /v1/team/{id}/members/{user_id}":                     {"organization:admin", "organization:admin-all"},
+	"DELETE /v1/team/{id}/members/{user_id}":                  {"organization:admin", "organization:admin-all"},
+}
+
+type routeDeprecation struct {
+	Since     time.Time  `json:"since"`
+	Sunset    *time.Time `json:"sunset"`
+	Successor string     `json:"successor,omitempty"`
+}
+
+type routeInfo struct {
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	Authentication bool              `json:"authentication"`
+	Permissions    []string          `json:"permissions"`
+	Deprecation    *routeDeprecation `json:"deprecation"`
+}
+
+var successorRX = regexp.MustCompile(`<([^>]*)>;\s*rel="successor-version"`)
+
+// listRoutesHandler lists every route registered on the router serving the
+// request, with whether it needs a signed-in user, the permissions its
+// handler checks and, for deprecated routes, when they were deprecated and
+// what replaces them.
+func (app *application) listRoutesHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "organization:admin-all") {
+		return
+	}
+
+	routes, err := walkRoutes(chi.RouteContext(r.Context()).Routes)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"routes": routes}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// walkRoutes describes the routes registered on router, ordered by path
+// and method.
+func walkRoutes(router chi.Routes) ([]*routeInfo, error) {
+	routes := []*routeInfo{}
+
+	walk := func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.ReplaceAll(route, "/*/", "/")
+
+		info := &routeInfo{
+			Method:         method,
+			Path:           route,
+			Authentication: strings.Contains(funcName(handler), ".requireAuthenticatedUser."),
+			Permissions:    routePermissions[method+" "+route],
+		}
+
+		if info.Permissions == nil {
+			info.Permissions = []string{}
+		}
+
+		for _, mw := range middlewares {
+			if strings.Contains(funcName(mw), ".deprecated.") {
+				info.Deprecation = probeDeprecation(mw)
+			}
+		}
+
+		routes = append(routes, info)
+
+		return nil
+	}
+
+	err := chi.Walk(router, walk)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes, nil
+}
+
+// probeDeprecation runs a deprecated middleware in front of an empty
+// handler and reads back the headers it sets.
+func probeDeprecation(mw func(http.Handler) http.Handler) *routeDeprecation {
+	rec := httptest.NewRecorder()
+	mw(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var d routeDeprecation
+
+	since, err := strconv.ParseInt(strings.TrimPrefix(rec.Header().Get("Deprecation"), "@"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	d.Since = time.Unix(since, 0).UTC()
+
+	if sunset, err := http.ParseTime(rec.Header().Get("Sunset")); err == nil {
+		d.Sunset = &sunset
+	}
+
+	for _, link := range rec.Header().Values("Link") {
+		if m := successorRX.FindStringSubmatch(link); m != nil {
+			d.Successor = m[1]
+		}
+	}
+
+	return &d
+}
+
+// funcName returns the name of the function behind fn, which for closures
+// includes the function that created them.
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", fn)
+	}
+
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+
+	return f.Name()
+}
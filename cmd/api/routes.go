@@ -1,18 +1,27 @@
 package main
 
 import (
+	"expvar"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/hwanbin/wanpm-api/internal/docs"
 )
 
 func (app *application) routes() http.Handler {
 	router := chi.NewRouter()
 
+	router.Use(app.traceRequest)
+	router.Use(app.requestID)
+	router.Use(app.logRequest)
 	router.Use(app.rateLimit)
 	router.Use(app.enableCORS)
 	router.Use(app.recoverPanic)
+	router.Use(app.handleOptions)
+	router.Use(middleware.GetHead)
+	router.Use(app.authenticate)
 
 	router.NotFound(app.notFoundResponse)
 	router.MethodNotAllowed(app.methodNotAllowedResponse)
@@ -24,38 +33,229 @@ func (app *application) routes() http.Handler {
 	fs := http.StripPrefix("/docs/swagger-ui", http.FileServer(http.FS(staticFS)))
 	router.Get("/docs/swagger-ui/*", fs.ServeHTTP)
 
-	router.Get("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/x-yaml")
-		w.WriteHeader(http.StatusOK)
-		w.Write(docs.OpenAPISpec)
-	})
+	router.Get("/openapi.json", app.openAPIHandler("application/json"))
+	// JSON is valid YAML, so clients of the old hand-written spec keep
+	// working.
+	router.Get("/openapi.yaml", app.openAPIHandler("application/x-yaml"))
 
-	router.Get("/v1/healthcheck", app.healthcheckHandler)
+	router.Get("/debug/vars", expvar.Handler().ServeHTTP)
 
-	router.Get("/v1/geocode/forward", app.forwardGeocodeHandler)
+	router.Route("/v1", app.routesV1)
 
-	router.Get("/v1/project", app.listProjectHandler)
-	router.Post("/v1/project", app.createProjectHandler)
-	router.Get("/v1/project/{id}", app.showProjectHandler)
-	router.Patch("/v1/project/{id}", app.updateProjectHandler)
-	router.Delete("/v1/project/{id}", app.deleteProjectHandler)
+	return router
+}
 
-	router.Get("/v1/client", app.listClientHandler)
-	router.Post("/v1/client", app.createClientHandler)
-	router.Get("/v1/client/{id}", app.showClientHandler)
-	router.Patch("/v1/client/{id}", app.updateClientHandler)
-	router.Delete("/v1/client/{id}", app.deleteClientHandler)
+// routesV1 registers the v1 API. A later version is mounted alongside it
+// with its own routes function, reusing handlers whose behaviour did not
+// change and mapping responses through versioned DTOs where it did.
+func (app *application) routesV1(r chi.Router) {
+	r.Use(app.apiVersion(1))
 
-	router.Post("/v1/proposal", app.createProposalHandler)
-	router.Get("/v1/proposal/{id}", app.showProposalHandler)
-	router.Patch("/v1/proposal/{id}", app.updateProposalHandler)
-	router.Delete("/v1/proposal/{id}", app.deleteProposalHandler)
+	catalogRelease := time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
 
-	router.Get("/v1/presigned-put", app.createPresignedPutUrlHandler)
-	router.Get("/v1/presigned-get", app.createPresignedGetUrlHandler)
-	router.Get("/v1/presigned-delete", app.createPresignedDeleteUrlHandler)
+	r.Get("/healthcheck", app.healthcheckHandler)
+	r.Get("/status", app.statusHandler)
+	r.Get("/me/quota", app.showQuotaHandler)
+	r.Get("/me/timesheets", app.requireAuthenticatedUser(app.listMyTimesheetHandler))
+	r.Get("/me/timesheet/suggestions", app.requireAuthenticatedUser(app.listTimesheetSuggestionsHandler))
 
-	router.Get("/v1/list-files", app.listFilesWithPrefixHandler)
+	r.Get("/sync", app.requireAuthenticatedUser(app.syncHandler))
+	r.Post("/sync/timesheets", app.requireAuthenticatedUser(app.syncTimesheetsHandler))
+	r.Post("/me/logout-all", app.requireAuthenticatedUser(app.logoutAllHandler))
+	r.Put("/me/email", app.requireAuthenticatedUser(app.requireEmail(app.requestEmailChangeHandler)))
+	r.Put("/me/email/confirm", app.confirmEmailChangeHandler)
+	r.Put("/me/language", app.requireAuthenticatedUser(app.updateMyLanguageHandler))
+	r.Put("/me/avatar", app.requireAuthenticatedUser(app.requireStorage(app.updateMyAvatarHandler)))
+	r.Get("/me/security-events", app.requireAuthenticatedUser(app.listMySecurityEventHandler))
+	r.Post("/password/check", app.checkPasswordHandler)
+	r.Get("/me/digest", app.requireAuthenticatedUser(app.showDigestPreferenceHandler))
+	r.Put("/me/digest", app.requireAuthenticatedUser(app.updateDigestPreferenceHandler))
+	r.Get("/me/preferences", app.requireAuthenticatedUser(app.showNotificationPreferenceHandler))
+	r.Put("/me/preferences", app.requireAuthenticatedUser(app.updateNotificationPreferenceHandler))
 
-	return router
+	r.Get("/notifications", app.requireAuthenticatedUser(app.listNotificationHandler))
+	r.Patch("/notifications", app.requireAuthenticatedUser(app.markAllNotificationsReadHandler))
+	r.Patch("/notifications/{id}", app.requireAuthenticatedUser(app.markNotificationReadHandler))
+	r.With(app.refreshCookieAuthentication).Get("/ws", app.requireAuthenticatedUser(app.pushHandler))
+
+	r.Get("/geocode/forward", app.forwardGeocodeHandler)
+	r.Get("/geocode/route", app.routeHandler)
+
+	r.Post("/graphql", app.requireAuthenticatedUser(app.graphqlHandler))
+
+	r.Post("/token/activation", app.requireEmail(app.createActivationTokenHandler))
+	r.Post("/token/calendar", app.requireEmail(app.createCalendarTokenHandler))
+	r.Post("/token/ldap", app.createLDAPTokenHandler)
+	r.Post("/token/access", app.authenticateRefreshCookie(app.requireAuthenticatedUser(app.createAccessTokenHandler)))
+	r.Post("/token/refresh-cookie", app.requireAuthenticatedUser(app.setRefreshCookieHandler))
+	r.Delete("/token/refresh-cookie", app.deleteRefreshCookieHandler)
+	r.Get("/calendar.ics", app.calendarFeedHandler)
+
+	r.Get("/codes/next", app.showNextCodesHandler)
+	r.Get("/typeahead/{entity}", app.requireAuthenticatedUser(app.typeaheadHandler))
+
+	r.Get("/project", app.requireAuthenticatedUser(app.listProjectHandler))
+	r.Post("/project", app.requireAuthenticatedUser(app.createProjectHandler))
+	r.Post("/project/next-id", app.requireAuthenticatedUser(app.reserveProjectIDHandler))
+	r.Get("/project/{id}", app.requireAuthenticatedUser(app.showProjectHandler))
+	r.Patch("/project/{id}", app.requireAuthenticatedUser(app.updateProjectHandler))
+	r.Delete("/project/{id}", app.requireAuthenticatedUser(app.deleteProjectHandler))
+	r.Post("/project/{id}/archive", app.requireAuthenticatedUser(app.archiveProjectHandler))
+	r.Post("/project/{id}/undelete", app.requireAuthenticatedUser(app.undeleteProjectHandler))
+	r.Put("/project/{id}/tags", app.requireAuthenticatedUser(app.updateProjectTagsHandler))
+	r.Get("/project/{id}/budget", app.requireAuthenticatedUser(app.showProjectBudgetHandler))
+	r.Put("/project/{id}/budget", app.requireAuthenticatedUser(app.updateProjectBudgetHandler))
+	r.Delete("/project/{id}/budget", app.requireAuthenticatedUser(app.deleteProjectBudgetHandler))
+	r.Get("/project/{id}/evm", app.requireAuthenticatedUser(app.showProjectEVMHandler))
+	r.Get("/project/{id}/map.png", app.requireAuthenticatedUser(app.requireStorage(app.showProjectMapHandler)))
+	r.Get("/project/{id}/files", app.requireAuthenticatedUser(app.listProjectFilesHandler))
+	r.Get("/project/{id}/documents", app.requireAuthenticatedUser(app.listProjectDocumentsHandler))
+	r.Get("/project/{id}/activities", app.requireAuthenticatedUser(app.listProjectActivitiesHandler))
+	r.Put("/project/{id}/activities", app.requireAuthenticatedUser(app.updateProjectActivitiesHandler))
+	r.Get("/project/{id}/milestones", app.requireAuthenticatedUser(app.listMilestoneHandler))
+	r.Post("/project/{id}/milestones", app.requireAuthenticatedUser(app.createMilestoneHandler))
+	r.Patch("/milestone/{id}", app.requireAuthenticatedUser(app.updateMilestoneHandler))
+	r.Delete("/milestone/{id}", app.requireAuthenticatedUser(app.deleteMilestoneHandler))
+	r.Get("/project/{id}/members", app.requireAuthenticatedUser(app.listProjectMembersHandler))
+	r.Put("/project/{id}/members/{user_id}", app.requireAuthenticatedUser(app.assignProjectMemberHandler))
+	r.Delete("/project/{id}/members/{user_id}", app.requireAuthenticatedUser(app.removeProjectMemberHandler))
+	r.Get("/project/{id}/approval-steps", app.requireAuthenticatedUser(app.showProjectApprovalChainHandler))
+	r.Put("/project/{id}/approval-steps", app.requireAuthenticatedUser(app.updateProjectApprovalChainHandler))
+
+	r.Get("/client", app.requireAuthenticatedUser(app.listClientHandler))
+	r.Post("/client", app.requireAuthenticatedUser(app.createClientHandler))
+	r.Get("/client/{id}", app.requireAuthenticatedUser(app.showClientHandler))
+	r.Get("/client/{id}/summary", app.requireAuthenticatedUser(app.showClientSummaryHandler))
+	r.Get("/client/{id}/subtree", app.requireAuthenticatedUser(app.showClientSubtreeHandler))
+	r.Patch("/client/{id}", app.requireAuthenticatedUser(app.updateClientHandler))
+	r.Delete("/client/{id}", app.requireAuthenticatedUser(app.deleteClientHandler))
+
+	r.Get("/custom-field", app.requireAuthenticatedUser(app.listCustomFieldHandler))
+	r.Post("/custom-field", app.requireAuthenticatedUser(app.createCustomFieldHandler))
+	r.Get("/custom-field/{id}", app.requireAuthenticatedUser(app.showCustomFieldHandler))
+	r.Patch("/custom-field/{id}", app.requireAuthenticatedUser(app.updateCustomFieldHandler))
+	r.Delete("/custom-field/{id}", app.requireAuthenticatedUser(app.deleteCustomFieldHandler))
+
+	r.Get("/activity", app.requireAuthenticatedUser(app.listActivityHandler))
+	r.Post("/activity", app.requireAuthenticatedUser(app.createActivityHandler))
+	r.Get("/activity/tree", app.requireAuthenticatedUser(app.activityTreeHandler))
+	r.Get("/activity/{id}", app.requireAuthenticatedUser(app.showActivityHandler))
+	r.Patch("/activity/{id}", app.requireAuthenticatedUser(app.updateActivityHandler))
+	r.Delete("/activity/{id}", app.requireAuthenticatedUser(app.deleteActivityHandler))
+
+	r.Get("/team", app.requireAuthenticatedUser(app.listTeamHandler))
+	r.Post("/team", app.requireAuthenticatedUser(app.createTeamHandler))
+	r.Get("/team/{id}", app.requireAuthenticatedUser(app.showTeamHandler))
+	r.Patch("/team/{id}", app.requireAuthenticatedUser(app.updateTeamHandler))
+	r.Delete("/team/{id}", app.requireAuthenticatedUser(app.deleteTeamHandler))
+	r.Get("/team/{id}/members", app.requireAuthenticatedUser(app.listTeamMembersHandler))
+	r.Put("/team/{id}/members/{user_id}", app.requireAuthenticatedUser(app.setTeamMemberHandler))
+	r.Delete("/team/{id}/members/{user_id}", app.requireAuthenticatedUser(app.removeTeamMemberHandler))
+
+	r.Get("/user", app.requireAuthenticatedUser(app.listUserHandler))
+	r.Delete("/user/{id}/personal-data", app.requireAuthenticatedUser(app.erasePersonalDataHandler))
+	r.Put("/user/{id}/hourly-cost", app.requireAuthenticatedUser(app.updateHourlyCostHandler))
+	r.Get("/user/{id}/security-events", app.listUserSecurityEventHandler)
+
+	r.Get("/delegation", app.requireAuthenticatedUser(app.listDelegationHandler))
+	r.Post("/delegation", app.requireAuthenticatedUser(app.createDelegationHandler))
+	r.Delete("/delegation/{id}", app.requireAuthenticatedUser(app.deleteDelegationHandler))
+
+	r.Get("/exchange-rate", app.showExchangeRateHandler)
+
+	r.Get("/planning/capacity", app.requireAuthenticatedUser(app.capacityHandler))
+
+	r.Get("/allocation", app.requireAuthenticatedUser(app.listAllocationHandler))
+	r.Post("/allocation", app.requireAuthenticatedUser(app.createAllocationHandler))
+	r.Get("/allocation/{id}", app.requireAuthenticatedUser(app.showAllocationHandler))
+	r.Patch("/allocation/{id}", app.requireAuthenticatedUser(app.updateAllocationHandler))
+	r.Delete("/allocation/{id}", app.requireAuthenticatedUser(app.deleteAllocationHandler))
+
+	r.Get("/leave", app.requireAuthenticatedUser(app.listLeaveHandler))
+	r.Post("/leave", app.requireAuthenticatedUser(app.createLeaveHandler))
+	r.Delete("/leave/{id}", app.requireAuthenticatedUser(app.deleteLeaveHandler))
+
+	r.Get("/holiday", app.requireAuthenticatedUser(app.listHolidayHandler))
+	r.Post("/holiday", app.requireAuthenticatedUser(app.createHolidayHandler))
+	r.Delete("/holiday/{id}", app.requireAuthenticatedUser(app.deleteHolidayHandler))
+
+	r.Get("/timesheet", app.listTimesheetHandler)
+	r.Get("/timesheet/facets", app.showTimesheetFacetsHandler)
+	r.Put("/timesheet/{id}/tags", app.requireAuthenticatedUser(app.updateTimesheetTagsHandler))
+
+	r.Get("/report/timesheet", app.requireAuthenticatedUser(app.timesheetReportHandler))
+	r.Get("/report/snapshot", app.requireAuthenticatedUser(app.showReportSnapshotHandler))
+	r.Post("/report/snapshot", app.requireAuthenticatedUser(app.refreshReportSnapshotHandler))
+	r.Post("/timesheet/{id}/submit", app.requireAuthenticatedUser(app.submitTimesheetHandler))
+	r.Post("/timesheet/{id}/approve", app.requireAuthenticatedUser(app.approveTimesheetHandler))
+	r.Post("/timesheet/{id}/reject", app.requireAuthenticatedUser(app.rejectTimesheetHandler))
+
+	r.Get("/tag", app.requireAuthenticatedUser(app.listTagHandler))
+	r.Post("/tag", app.requireAuthenticatedUser(app.createTagHandler))
+	r.Delete("/tag/{id}", app.requireAuthenticatedUser(app.deleteTagHandler))
+
+	r.Post("/import/projects", app.requireAuthenticatedUser(app.importProjectsHandler))
+	r.Post("/import/users", app.requireAuthenticatedUser(app.importUsersHandler))
+	r.Post("/import/timesheets", app.requireAuthenticatedUser(app.importTimesheetsHandler))
+
+	r.Post("/export/full", app.requireAuthenticatedUser(app.requireStorage(app.requireEmail(app.createFullExportHandler))))
+	r.Get("/export/accounting", app.requireAuthenticatedUser(app.exportAccountingHandler))
+
+	r.Get("/accounting/mapping", app.requireAuthenticatedUser(app.listAccountingMappingHandler))
+	r.Put("/accounting/mapping/project/{id}", app.requireAuthenticatedUser(app.setCustomerMappingHandler))
+	r.Delete("/accounting/mapping/project/{id}", app.requireAuthenticatedUser(app.deleteCustomerMappingHandler))
+	r.Put("/accounting/mapping/activity/{id}", app.requireAuthenticatedUser(app.setServiceItemMappingHandler))
+	r.Delete("/accounting/mapping/activity/{id}", app.requireAuthenticatedUser(app.deleteServiceItemMappingHandler))
+
+	r.Get("/approval-steps", app.requireAuthenticatedUser(app.showApprovalChainHandler))
+	r.Put("/approval-steps", app.requireAuthenticatedUser(app.updateApprovalChainHandler))
+
+	r.Post("/proposal", app.createProposalHandler)
+	r.Get("/proposal/{id}", app.showProposalHandler)
+	r.Get("/proposal/{id}/project", app.showProposalProjectHandler)
+	r.Patch("/proposal/{id}", app.updateProposalHandler)
+	r.Delete("/proposal/{id}", app.deleteProposalHandler)
+
+	r.Get("/presigned-put", app.requireStorage(app.createPresignedPutUrlHandler))
+	r.Get("/presigned-get", app.requireStorage(app.createPresignedGetUrlHandler))
+	r.With(app.deprecated(catalogRelease, time.Time{}, "/v1/files")).Get("/presigned-delete", app.requireStorage(app.createPresignedDeleteUrlHandler))
+
+	r.With(app.deprecated(catalogRelease, time.Time{}, "/v1/project/{id}/files")).Get("/list-files", app.requireStorage(app.listFilesWithPrefixHandler))
+
+	r.Delete("/files", app.requireAuthenticatedUser(app.requireStorage(app.deleteFilesHandler)))
+	r.Get("/files/trash", app.requireAuthenticatedUser(app.requireStorage(app.listTrashHandler)))
+	r.Post("/files/restore", app.requireAuthenticatedUser(app.requireStorage(app.restoreFilesHandler)))
+	r.Post("/files/complete", app.requireAuthenticatedUser(app.requireStorage(app.completeUploadHandler)))
+	r.Get("/files/{id}/versions", app.requireAuthenticatedUser(app.requireStorage(app.listFileVersionsHandler)))
+	r.Get("/files/{id}/download", app.requireAuthenticatedUser(app.requireStorage(app.downloadFileHandler)))
+
+	r.Get("/admin/routes", app.requireAuthenticatedUser(app.listRoutesHandler))
+	r.Get("/admin/email-preview/{template}", app.requireAuthenticatedUser(app.emailPreviewHandler))
+
+	r.Post("/admin/retention/run", app.requireAuthenticatedUser(app.runRetentionHandler))
+
+	r.Get("/admin/schedules", app.requireAuthenticatedUser(app.listScheduleHandler))
+	r.Patch("/admin/schedules", app.requireAuthenticatedUser(app.updateScheduleHandler))
+
+	r.Get("/admin/organization", app.requireAuthenticatedUser(app.listOrganizationHandler))
+	r.Post("/admin/organization", app.requireAuthenticatedUser(app.createOrganizationHandler))
+	r.Get("/admin/organization/{id}", app.requireAuthenticatedUser(app.showOrganizationHandler))
+	r.Patch("/admin/organization/{id}", app.requireAuthenticatedUser(app.updateOrganizationHandler))
+	r.Delete("/admin/organization/{id}", app.requireAuthenticatedUser(app.deleteOrganizationHandler))
+	r.Get("/admin/organization/{id}/settings", app.requireAuthenticatedUser(app.showOrgSettingsHandler))
+	r.Patch("/admin/organization/{id}/settings", app.requireAuthenticatedUser(app.updateOrgSettingsHandler))
+	r.Get("/admin/organization/{id}/work-rules", app.requireAuthenticatedUser(app.showWorkRulesHandler))
+	r.Patch("/admin/organization/{id}/work-rules", app.requireAuthenticatedUser(app.updateWorkRulesHandler))
+	r.Get("/admin/organization/{id}/code-policy", app.requireAuthenticatedUser(app.showCodePolicyHandler))
+	r.Patch("/admin/organization/{id}/code-policy", app.requireAuthenticatedUser(app.updateCodePolicyHandler))
+	r.Get("/admin/organization/{id}/ldap", app.requireAuthenticatedUser(app.showLDAPConfigHandler))
+	r.Put("/admin/organization/{id}/ldap", app.requireAuthenticatedUser(app.putLDAPConfigHandler))
+	r.Delete("/admin/organization/{id}/ldap", app.requireAuthenticatedUser(app.deleteLDAPConfigHandler))
+	r.Put("/admin/organization/{id}/ldap/links/{user_id}", app.requireAuthenticatedUser(app.linkLDAPUserHandler))
+	r.Delete("/admin/organization/{id}/ldap/links/{user_id}", app.requireAuthenticatedUser(app.unlinkLDAPUserHandler))
+
+	r.Get("/admin/role", app.requireAuthenticatedUser(app.listRoleHandler))
+	r.Post("/admin/role/recompute", app.requireAuthenticatedUser(app.recomputeRolesHandler))
+	r.Put("/admin/role/{name}", app.requireAuthenticatedUser(app.putRoleHandler))
+	r.Delete("/admin/role/{name}", app.requireAuthenticatedUser(app.deleteRoleHandler))
 }
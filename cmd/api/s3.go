@@ -1,14 +1,24 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/hwanbin/wanpm-api/internal/data"
 	"github.com/hwanbin/wanpm-api/internal/s3action"
+	"github.com/hwanbin/wanpm-api/internal/validator"
 )
 
 func (app *application) createPresignedPutUrlHandler(w http.ResponseWriter, r *http.Request) {
@@ -16,22 +26,65 @@ func (app *application) createPresignedPutUrlHandler(w http.ResponseWriter, r *h
 	fileName := app.readString(qs, "filename", "")
 	if fileName == "" {
 		app.badRequestResponse(w, r, fmt.Errorf("empty filename"))
+		return
+	}
+
+	v := validator.New()
+	size := app.readInt(qs, "size", 0, v)
+	if v.Check(size >= 0, "size", "must not be negative"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	exceeded, err := app.projectQuotaExceeded(fileName, int64(size))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if exceeded {
+		app.storageQuotaExceededResponse(w, r)
+		return
+	}
+
+	archived, err := app.projectArchived(fileName)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if archived {
+		app.projectArchivedResponse(w, r)
+		return
 	}
 
 	lifetimeSecs := 60
 	presigner := app.s3actor.presignClient
 
+	putInput := &s3.PutObjectInput{
+		Bucket: aws.String(app.config.s3.bucket),
+		Key:    aws.String(fileName),
+	}
+
+	// When the client knows the digest up front, sign it into the request so
+	// S3 rejects corrupted uploads and keeps the checksum for completion.
+	if checksum := app.readString(qs, "sha256", ""); checksum != "" {
+		if data.ValidateChecksumSHA256(v, checksum); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		sum, _ := hex.DecodeString(checksum)
+		putInput.ChecksumSHA256 = aws.String(base64.StdEncoding.EncodeToString(sum))
+	}
+
 	request, err := presigner.PresignPutObject(
 		context.Background(),
-		&s3.PutObjectInput{
-			Bucket: aws.String(app.config.s3.bucket),
-			Key:    aws.String(fileName),
-		}, func(opts *s3.PresignOptions) {
+		putInput,
+		func(opts *s3.PresignOptions) {
 			opts.Expires = time.Duration(lifetimeSecs) * time.Second
 		},
 	)
 	if err != nil {
 		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Couldn't get a presigned request to put %s: %v", fileName, err))
+		return
 	}
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"presigned": request}, nil)
@@ -45,28 +98,49 @@ func (app *application) createPresignedGetUrlHandler(w http.ResponseWriter, r *h
 	fileName := app.readString(qs, "filename", "")
 	if fileName == "" {
 		app.badRequestResponse(w, r, fmt.Errorf("empty filename"))
+		return
+	}
+
+	request, err := app.presignGetObject(r.Context(), fileName)
+	if err != nil {
+		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Couldn't get a presigned request to get %s: %v", fileName, err))
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"presigned": request}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// presignGetObject returns a short-lived download request for key, signed
+// by CloudFront when a CDN is configured and by S3 otherwise.
+func (app *application) presignGetObject(ctx context.Context, key string) (*v4.PresignedHTTPRequest, error) {
+	if app.s3actor.cdnSigner != nil {
+		signedURL, err := app.s3actor.cdnSigner.Sign(app.fileBaseURL()+(&url.URL{Path: key}).EscapedPath(), time.Now().Add(app.config.cdn.urlTTL))
+		if err != nil {
+			return nil, err
+		}
+
+		return &v4.PresignedHTTPRequest{
+			URL:          signedURL,
+			Method:       http.MethodGet,
+			SignedHeader: http.Header{},
+		}, nil
 	}
 
 	lifetimeSecs := 60
 	presigner := app.s3actor.presignClient
 
-	request, err := presigner.PresignGetObject(
-		context.Background(),
+	return presigner.PresignGetObject(
+		ctx,
 		&s3.GetObjectInput{
 			Bucket: aws.String(app.config.s3.bucket),
-			Key:    aws.String(fileName),
+			Key:    aws.String(key),
 		}, func(opts *s3.PresignOptions) {
 			opts.Expires = time.Duration(lifetimeSecs) * time.Second
 		},
 	)
-	if err != nil {
-		app.errorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Couldn't get a presigned request to get %s: %v", fileName, err))
-	}
-
-	err = app.writeJSON(w, http.StatusOK, envelope{"presigned": request}, nil)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-	}
 }
 
 func (app *application) createPresignedDeleteUrlHandler(w http.ResponseWriter, r *http.Request) {
@@ -103,20 +177,135 @@ func (app *application) listFilesWithPrefixHandler(w http.ResponseWriter, r *htt
 	prefix := app.readString(qs, "prefix", "")
 	bucket := app.config.s3.bucket
 
-	var fileNames []string
+	if externalID, ok := projectIDFromKey(prefix); ok && strings.HasSuffix(prefix, "/") {
+		files, err := app.models.File.GetAllForProject(externalID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		// Projects whose files predate the catalog are served from S3 until
+		// the reconciliation job has backfilled them.
+		if len(files) > 0 {
+			fileNames := make([]string, 0, len(files))
+			for _, file := range files {
+				fileNames = append(fileNames, file.Key)
+			}
+
+			app.writeFileNames(w, r, fileNames)
+			return
+		}
+	}
 
 	fileNames, err := s3action.ListObjects(app.s3actor.client, bucket, prefix)
 	if err != nil {
 		app.serverErrorResponse(w, r, fmt.Errorf("unable to list objects with prefix %q: %v", prefix, err))
+		return
 	}
 
-	app.writeJSON(
-		w,
-		http.StatusOK,
-		envelope{
-			"base_url":   fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", bucket, "us-east-1"),
-			"file_names": fileNames,
-		},
-		nil,
-	)
+	app.writeFileNames(w, r, fileNames)
+}
+
+// writeFileNames sends a file listing tagged with an ETag, which changes
+// when files are added or deleted alike.
+func (app *application) writeFileNames(w http.ResponseWriter, r *http.Request, fileNames []string) {
+	js, err := app.marshalJSON(envelope{"base_url": app.fileBaseURL(), "file_names": fileNames})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeTaggedJSON(w, r, js)
+}
+
+func (app *application) fileBaseURL() string {
+	if app.config.cdn.domain != "" {
+		return fmt.Sprintf("https://%s/", app.config.cdn.domain)
+	}
+
+	bucket := app.config.s3.bucket
+
+	if endpoint := app.config.s3.endpoint; endpoint != "" {
+		if app.config.s3.pathStyle {
+			return strings.TrimRight(endpoint, "/") + "/" + bucket + "/"
+		}
+
+		if u, err := url.Parse(endpoint); err == nil {
+			return fmt.Sprintf("%s://%s.%s/", u.Scheme, bucket, u.Host)
+		}
+	}
+
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", bucket, cmp.Or(app.config.s3.region, "us-east-1"))
+}
+
+// projectPrefix returns the key prefix of a project's files.
+func projectPrefix(externalID int32) string {
+	return fmt.Sprintf("%d/", externalID)
+}
+
+// projectIDFromKey returns the project that owns an object key. Project files
+// are stored under a "{project_id}/" prefix.
+func projectIDFromKey(key string) (int32, bool) {
+	prefix, _, found := strings.Cut(key, "/")
+	if !found {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(prefix, 10, 32)
+	if err != nil || id < 1 {
+		return 0, false
+	}
+
+	return int32(id), true
+}
+
+func (app *application) projectQuotaExceeded(key string, size int64) (bool, error) {
+	if app.config.s3.projectQuota <= 0 {
+		return false, nil
+	}
+
+	externalID, ok := projectIDFromKey(key)
+	if !ok {
+		return false, nil
+	}
+
+	storageBytes, err := app.models.Project.GetStorageBytes(externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return storageBytes+size > app.config.s3.projectQuota, nil
+}
+
+// projectArchived reports whether key belongs to an archived project, which
+// no longer accepts uploads.
+func (app *application) projectArchived(key string) (bool, error) {
+	externalID, ok := projectIDFromKey(key)
+	if !ok {
+		return false, nil
+	}
+
+	project, err := app.models.Project.Get(data.SystemActor, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return project.ArchivedAt != nil, nil
+}
+
+// projectMapPrefix is where rendered map images of the project are cached.
+// The images live outside the project prefix so they are not listed as
+// project files or counted against its quota.
+func projectMapPrefix(externalID int32) string {
+	return fmt.Sprintf("maps/projects/%d/", externalID)
 }
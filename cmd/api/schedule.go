@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/cron"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// schedulePoll is how often a waiting job rereads its schedule, and so how
+// long an edited schedule may take to apply.
+const schedulePoll = time.Minute
+
+// defaultSchedules are the background jobs and the schedules stored for
+// them on first start: every interval given on the command line, or daily
+// but disabled when that is zero. Once stored, a schedule is changed
+// through the API and the flags no longer apply.
+func (app *application) defaultSchedules() []*data.JobSchedule {
+	intervals := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"digest", app.config.digest.interval},
+		{"exchange_rates", app.config.fx.interval},
+		{"health_alerts", app.config.health.alertInterval},
+		{"report_snapshot", app.config.report.snapshotInterval},
+		{"retention", app.config.retention.interval},
+		{"storage_reconciliation", app.config.s3.reconcileInterval},
+	}
+
+	schedules := make([]*data.JobSchedule, len(intervals))
+	for i, job := range intervals {
+		schedules[i] = &data.JobSchedule{Name: job.name, Expression: "@daily"}
+		if job.interval >= time.Minute {
+			schedules[i].Expression = "@every " + job.interval.String()
+			schedules[i].Enabled = true
+		}
+	}
+
+	return schedules
+}
+
+// runScheduled runs a job each time its stored schedule comes due.
+func (app *application) runScheduled(name string, run func()) {
+	for {
+		time.Sleep(app.runIfDue(name, run))
+	}
+}
+
+// runIfDue runs a job if its schedule has come due since it last ran, or
+// since the server started when it never has, and returns how long to wait
+// before checking again. Of several servers finding the job due, only the
+// one that claims the run goes ahead.
+func (app *application) runIfDue(name string, run func()) time.Duration {
+	s, err := app.models.Schedule.Get(name)
+	if err != nil {
+		app.logger.Error("job schedule unavailable", "job", name, "error", err.Error())
+		return schedulePoll
+	}
+
+	if !s.Enabled {
+		return schedulePoll
+	}
+
+	schedule, err := cron.Parse(s.Expression)
+	if err != nil {
+		app.logger.Error("job schedule invalid", "job", name, "expression", s.Expression, "error", err.Error())
+		return schedulePoll
+	}
+
+	last := app.started
+	if s.LastRunAt != nil {
+		last = *s.LastRunAt
+	}
+
+	now := time.Now().UTC()
+
+	next := schedule.Next(last.UTC())
+	if next.IsZero() {
+		return schedulePoll
+	}
+	if now.Before(next) {
+		return min(next.Sub(now), schedulePoll)
+	}
+
+	claimed, err := app.models.Schedule.Claim(name, s.LastRunAt, now.Truncate(time.Second))
+	if err != nil {
+		app.logger.Error("job schedule unavailable", "job", name, "error", err.Error())
+		return schedulePoll
+	}
+
+	if claimed {
+		run()
+	}
+
+	return 0
+}
+
+// jobScheduleResponse is a stored schedule with when it next runs, null
+// when disabled.
+type jobScheduleResponse struct {
+	*data.JobSchedule
+	NextRunAt *time.Time `json:"next_run_at"`
+}
+
+func (app *application) scheduleResponses(schedules []*data.JobSchedule) []*jobScheduleResponse {
+	responses := make([]*jobScheduleResponse, len(schedules))
+
+	for i, s := range schedules {
+		responses[i] = &jobScheduleResponse{JobSchedule: s}
+
+		schedule, err := cron.Parse(s.Expression)
+		if !s.Enabled || err != nil {
+			continue
+		}
+
+		last := app.started
+		if s.LastRunAt != nil {
+			last = *s.LastRunAt
+		}
+
+		next := schedule.Next(last.UTC())
+		if next.IsZero() {
+			continue
+		}
+
+		// An overdue job runs at the next poll.
+		if now := time.Now().UTC().Truncate(time.Second); next.Before(now) {
+			next = now
+		}
+		responses[i].NextRunAt = &next
+	}
+
+	return responses
+}
+
+// listScheduleHandler lists the background jobs' schedules. Jobs run for
+// every organization, so only organization:admin-all sees them.
+func (app *application) listScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "organization:admin-all") {
+		return
+	}
+
+	schedules, err := app.models.Schedule.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"schedules": app.scheduleResponses(schedules)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateScheduleHandler changes the schedules of the jobs named in the
+// body, all or none of them. Running servers pick the changes up within a
+// minute. Like listing them, it takes organization:admin-all.
+func (app *application) updateScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "organization:admin-all") {
+		return
+	}
+
+	var input map[string]*struct {
+		Expression *string `json:"expression"`
+		Enabled    *bool   `json:"enabled"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if v.Check(len(input) > 0, "schedules", "must name at least one job"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	schedules, err := app.models.Schedule.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	changed := []*data.JobSchedule{}
+
+	for name, in := range input {
+		i := slices.IndexFunc(schedules, func(s *data.JobSchedule) bool { return s.Name == name })
+		if i < 0 {
+			v.AddError(name, "is not a scheduled job")
+			continue
+		}
+		if in == nil {
+			continue
+		}
+
+		s := schedules[i]
+		if in.Expression != nil {
+			s.Expression = *in.Expression
+		}
+		if in.Enabled != nil {
+			s.Enabled = *in.Enabled
+		}
+
+		data.ValidateJobSchedule(v, name, s)
+		changed = append(changed, s)
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.WithTx(r.Context(), func(tx data.Models) error {
+		for _, s := range changed {
+			err := tx.Schedule.Update(s)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"schedules": app.scheduleResponses(schedules)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
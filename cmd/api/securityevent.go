@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// recordSecurityEvent adds an event to a user's security history with the
+// IP address and user agent of the request. Failing to record it is logged
+// rather than failing the request.
+func (app *application) recordSecurityEvent(r *http.Request, userID int32, event string) {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+
+	err = app.models.Security.Insert(&data.SecurityEvent{
+		UserID:    userID,
+		Event:     event,
+		IP:        ip,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		app.requestLogger(r).Error("recording security event failed", "event", event, "error", err.Error())
+	}
+}
+
+func (app *application) listMySecurityEventHandler(w http.ResponseWriter, r *http.Request) {
+	app.listSecurityEvents(w, r, app.contextGetUser(r).InternalID)
+}
+
+// listUserSecurityEventHandler lets admins holding security-event:read-all
+// audit another user's access.
+func (app *application) listUserSecurityEventHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "security-event:read-all") {
+		return
+	}
+
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.listSecurityEvents(w, r, id)
+}
+
+func (app *application) listSecurityEvents(w http.ResponseWriter, r *http.Request, userID int32) {
+	var input struct {
+		Event string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Event = app.readString(qs, "event", "")
+	if input.Event != "" {
+		v.Check(validator.PermittedValue(input.Event, data.SecurityEvents...), "event", "invalid event")
+	}
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = app.readString(qs, "sort", "-created_at")
+	input.Filters.SortSafelist = []string{"created_at", "-created_at"}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	events, metadata, err := app.models.Security.GetAllForUser(userID, input.Event, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "security_events": events}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -22,6 +22,8 @@ func (app *application) serve() error {
 		ErrorLog:     slog.NewLogLogger(app.logger.Handler(), slog.LevelError),
 	}
 
+	srv.RegisterOnShutdown(app.push.close)
+
 	shutdownError := make(chan error)
 
 	go func() {
@@ -42,6 +44,7 @@ func (app *application) serve() error {
 		app.logger.Info("completing background tasks", "addr", srv.Addr)
 
 		app.wg.Wait()
+		app.flushTelemetry(ctx)
 		shutdownError <- nil
 	}()
 
@@ -0,0 +1,43 @@
+package main
+
+import "net/http"
+
+// storageEnabled reports whether the server was started with an S3 bucket.
+// Without one it runs with file uploads, downloads and exports switched
+// off, which is enough for local development.
+func (app *application) storageEnabled() bool {
+	return app.s3actor.client != nil
+}
+
+// emailEnabled reports whether the server was started with an SMTP host,
+// or in dev mode, which logs emails instead. Otherwise no email is sent, and
+// endpoints whose only purpose is to send one are switched off.
+func (app *application) emailEnabled() bool {
+	return app.config.smtp.host != "" || app.config.dev
+}
+
+// requireStorage rejects requests to endpoints that need S3 when the server
+// runs without it.
+func (app *application) requireStorage(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !app.storageEnabled() {
+			app.subsystemDisabledResponse(w, r, "file storage")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// requireEmail rejects requests to endpoints that need SMTP when the server
+// runs without it.
+func (app *application) requireEmail(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !app.emailEnabled() {
+			app.subsystemDisabledResponse(w, r, "email")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// syncHandler returns what changed for the caller since the RFC 3339
+// timestamp in since, or everything current when since is absent. Clients
+// pass the until of one response as the since of the next.
+func (app *application) syncHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	var since *time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			v.AddError("since", "must be an RFC 3339 timestamp")
+		} else {
+			v.Check(!t.After(time.Now()), "since", "must not be in the future")
+			since = &t
+		}
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	changes, err := app.models.Sync.Changes(actor, since)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"sync": changes}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// timesheetSyncResult reports what became of one entry in a sync batch.
+// Status is created, updated, conflict or rejected. Conflicts carry the
+// server's copy of the entry in place of the client's.
+type timesheetSyncResult struct {
+	EntryUUID string               `json:"entry_uuid"`
+	Status    string               `json:"status"`
+	Reason    string               `json:"reason,omitempty"`
+	Entry     *data.TimesheetEntry `json:"entry,omitempty"`
+	Errors    map[string]string    `json:"errors,omitempty"`
+}
+
+type timesheetSyncReport struct {
+	Strategy  string                 `json:"strategy"`
+	Created   int                    `json:"created"`
+	Updated   int                    `json:"updated"`
+	Conflicts int                    `json:"conflicts"`
+	Rejected  int                    `json:"rejected"`
+	Results   []*timesheetSyncResult `json:"results"`
+}
+
+// maxTimesheetSyncBatch is the most entries one sync request may carry.
+const maxTimesheetSyncBatch = 500
+
+// syncTimesheetsHandler upserts a batch of the caller's timesheet entries
+// recorded offline, matching them to stored entries by entry_uuid. Each
+// entry is saved on its own, so one conflict or invalid entry does not hold
+// back the rest; the response reports the outcome of each in order.
+func (app *application) syncTimesheetsHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Strategy string `json:"strategy"`
+		Entries  []struct {
+			EntryUUID   string    `json:"entry_uuid"`
+			ProjectID   int32     `json:"project_id"`
+			ActivityID  *int32    `json:"activity_id"`
+			WorkDate    string    `json:"work_date"`
+			Minutes     int32     `json:"minutes"`
+			Note        string    `json:"note"`
+			BaseVersion int32     `json:"base_version"`
+			ModifiedAt  time.Time `json:"modified_at"`
+		} `json:"entries"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Strategy == "" {
+		input.Strategy = data.SyncServerVersion
+	}
+
+	v := validator.New()
+
+	v.Check(validator.PermittedValue(input.Strategy, data.SyncLastWriteWins, data.SyncServerVersion), "strategy", "must be last_write_wins or server_version")
+	v.Check(len(input.Entries) > 0, "entries", "must contain at least one entry")
+	v.Check(len(input.Entries) <= maxTimesheetSyncBatch, "entries", fmt.Sprintf("must not contain more than %d entries", maxTimesheetSyncBatch))
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID := app.contextGetUser(r).InternalID
+	now := time.Now()
+
+	rules, err := app.models.WorkRules.GetForUsers([]int32{userID})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	workRules := rules[userID]
+	if workRules == nil {
+		workRules = data.DefaultWorkRules(0)
+	}
+
+	report := timesheetSyncReport{
+		Strategy: input.Strategy,
+		Results:  make([]*timesheetSyncResult, 0, len(input.Entries)),
+	}
+	seen := make(map[string]bool)
+
+	for _, in := range input.Entries {
+		ev := validator.New()
+		result := &timesheetSyncResult{EntryUUID: in.EntryUUID}
+		report.Results = append(report.Results, result)
+
+		entryUUID := strings.ToLower(in.EntryUUID)
+
+		s := &data.TimesheetSync{
+			Entry: &data.TimesheetEntry{
+				EntryUUID:         &entryUUID,
+				ExternalProjectID: in.ProjectID,
+				ActivityID:        in.ActivityID,
+				Minutes:           in.Minutes,
+				Note:              in.Note,
+			},
+			BaseVersion: in.BaseVersion,
+			ModifiedAt:  in.ModifiedAt,
+			Rules:       workRules,
+		}
+
+		if workDate := app.parseDate(ev, "work_date", in.WorkDate); workDate != nil {
+			s.Entry.WorkDate = *workDate
+		}
+
+		// A device clock running ahead must not let its edits outlast
+		// later ones made elsewhere.
+		if s.ModifiedAt.After(now) {
+			s.ModifiedAt = now
+		}
+
+		data.ValidateTimesheetSync(ev, s, input.Strategy)
+		ev.Check(!seen[entryUUID], "entry_uuid", "appears more than once in the batch")
+		seen[entryUUID] = true
+
+		if !ev.Valid() {
+			result.Status = "rejected"
+			result.Errors = ev.Errors
+			report.Rejected++
+			continue
+		}
+
+		server, created, err := app.models.Timesheet.Sync(userID, s, input.Strategy)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrSyncConflict):
+				result.Status = "conflict"
+				result.Reason = "the entry changed on the server since it was last synced"
+				result.Entry = server
+				report.Conflicts++
+			case errors.Is(err, data.ErrTimesheetLocked):
+				result.Status = "conflict"
+				result.Reason = "the entry has been submitted, approved or deleted and can no longer be edited"
+				result.Entry = server
+				report.Conflicts++
+			case errors.Is(err, data.ErrDuplicateEntryUUID):
+				ev.AddError("entry_uuid", "is already in use")
+			case errors.Is(err, data.ErrDailyMinutesExceeded):
+				ev.AddError("minutes", fmt.Sprintf("would bring the total for %s to more than %s", s.Entry.WorkDate.Format(time.DateOnly), dayLimit(workRules)))
+			case errors.Is(err, data.ErrActivityNotEnabled):
+				ev.AddError("activity_id", "must be enabled for the project")
+			case errors.Is(err, data.ErrRecordNotFound):
+				ev.AddError("project_id", "must be an active project you are assigned to, with an existing activity")
+			default:
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			if !ev.Valid() {
+				result.Status = "rejected"
+				result.Errors = ev.Errors
+				report.Rejected++
+			}
+			continue
+		}
+
+		result.Entry = s.Entry
+		if created {
+			result.Status = "created"
+			report.Created++
+		} else {
+			result.Status = "updated"
+			report.Updated++
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"sync": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
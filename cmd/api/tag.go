@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+func (app *application) listTagHandler(w http.ResponseWriter, r *http.Request) {
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	tags, err := app.models.Tag.GetAll(actor)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) createTagHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	tag := &data.Tag{Name: strings.ToLower(strings.TrimSpace(input.Name)), OrgID: actor.OrgID}
+
+	v := validator.New()
+	if data.ValidateTagName(v, "name", tag.Name); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Tag.Insert(tag)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateTag):
+			v.AddError("name", "a tag with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"tag": tag}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Deleting a tag removes it from every project and entry carrying it.
+	if !app.requireOrgAdmin(w, r, actor.OrgID) {
+		return
+	}
+
+	err = app.models.Tag.Delete(actor, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletedResponse(w, r, "tag successfully deleted", deletedResource{Resource: "tag", ID: id})
+}
+
+// readTagsInput reads a {"tags": [...]} body and returns the normalized
+// names, or false after writing an error response.
+func (app *application) readTagsInput(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	var input struct {
+		Tags []string `json:"tags"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	tags := data.NormalizeTags(input.Tags)
+
+	v := validator.New()
+	v.Check(input.Tags != nil, "tags", "must be provided")
+	v.Check(len(tags) <= 50, "tags", "must not contain more than 50 tags")
+	for _, tag := range tags {
+		data.ValidateTagName(v, "tags", tag)
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	return tags, true
+}
+
+// updateProjectTagsHandler replaces the tags of a project. Tags that do not
+// exist yet are created.
+func (app *application) updateProjectTagsHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	project, err := app.project(r, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if project.ArchivedAt != nil {
+		app.projectArchivedResponse(w, r)
+		return
+	}
+
+	tags, ok := app.readTagsInput(w, r)
+	if !ok {
+		return
+	}
+
+	err = app.models.Tag.SetProjectTags(project.InternalID, tags)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateTimesheetTagsHandler replaces the tags of the caller's own timesheet
+// entry. Tags that do not exist yet are created.
+func (app *application) updateTimesheetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := app.readTimesheetEntry(w, r)
+	if !ok {
+		return
+	}
+
+	if entry.UserID != app.contextGetUser(r).InternalID {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	tags, ok := app.readTagsInput(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.models.Tag.SetTimesheetTags(entry.InternalID, tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "wanpm-api"
+
+// initTracing installs a global tracer provider exporting spans over OTLP
+// HTTP. With no endpoint configured the global no-op provider stays in
+// place, so instrumented clients cost next to nothing. The returned
+// function flushes pending spans.
+func initTracing(cfg config) (func(context.Context) error, error) {
+	if cfg.otel.endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(
+		context.Background(),
+		otlptracehttp.WithEndpointURL(cfg.otel.endpoint),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+		semconv.DeploymentEnvironment(cfg.env),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.otel.sampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// initSentry enables error reporting when a DSN is configured.
+func initSentry(cfg config) error {
+	if cfg.sentry.dsn == "" {
+		return nil
+	}
+
+	return sentry.Init(sentry.ClientOptions{
+		Dsn:         cfg.sentry.dsn,
+		Environment: cfg.env,
+		Release:     serviceName + "@" + version,
+	})
+}
+
+// traceRequest starts a server span for every request. The span is renamed
+// after routing so spans group by route pattern rather than by raw path.
+func (app *application) traceRequest(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx != nil && rctx.RoutePattern() != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(semconv.HTTPRoute(rctx.RoutePattern()))
+		}
+	})
+
+	return otelhttp.NewHandler(named, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+	)
+}
+
+// reportError sends err to Sentry along with the request, its ID and the
+// current trace, when Sentry is enabled.
+func (app *application) reportError(r *http.Request, err error) {
+	if sentry.CurrentHub().Client() == nil {
+		return
+	}
+
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) {
+		scope.SetRequest(r)
+
+		if id, ok := r.Context().Value(requestIDContextKey).(string); ok {
+			scope.SetTag("request_id", id)
+		}
+
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			scope.SetTag("trace_id", sc.TraceID().String())
+		}
+	})
+	hub.CaptureException(err)
+}
+
+// flushTelemetry delivers buffered spans and error reports before exit.
+func (app *application) flushTelemetry(ctx context.Context) {
+	if app.shutdownTracing != nil {
+		err := app.shutdownTracing(ctx)
+		if err != nil {
+			app.logger.Error("flushing traces failed", "error", err.Error())
+		}
+	}
+
+	sentry.Flush(5 * time.Second)
+}
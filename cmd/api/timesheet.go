@@ -0,0 +1,172 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// timesheetSortSafelist holds the sort keys timesheet listings accept.
+var timesheetSortSafelist = []string{
+	"work_date", "project_id", "user_id", "minutes", "submitted_at", "created_at", "updated_at",
+	"-work_date", "-project_id", "-user_id", "-minutes", "-submitted_at", "-created_at", "-updated_at",
+}
+
+// readTimesheetFilter reads the user_id, project_id, from, to, tags, status,
+// approver_id, submitted_from, submitted_to and team_id query parameters
+// shared by the timesheet endpoints.
+func (app *application) readTimesheetFilter(qs url.Values, v *validator.Validator) data.TimesheetFilter {
+	var filter data.TimesheetFilter
+
+	filter.UserID = int32(app.readInt(qs, "user_id", 0, v))
+	filter.ProjectID = int32(app.readInt(qs, "project_id", 0, v))
+
+	if from := qs.Get("from"); from != "" {
+		filter.From = app.parseDate(v, "from", from)
+	}
+	if to := qs.Get("to"); to != "" {
+		filter.To = app.parseDate(v, "to", to)
+	}
+
+	filter.Tags = data.NormalizeTags(app.readCSV(qs, "tags", nil))
+
+	filter.Statuses = app.readCSV(qs, "status", nil)
+	filter.ApproverID = int32(app.readInt(qs, "approver_id", 0, v))
+
+	if from := qs.Get("submitted_from"); from != "" {
+		filter.SubmittedFrom = app.parseDate(v, "submitted_from", from)
+	}
+	if to := qs.Get("submitted_to"); to != "" {
+		filter.SubmittedTo = app.parseDate(v, "submitted_to", to)
+	}
+
+	filter.TeamID = int32(app.readInt(qs, "team_id", 0, v))
+
+	data.ValidateTimesheetFilter(v, filter)
+
+	return filter
+}
+
+func (app *application) listTimesheetHandler(w http.ResponseWriter, r *http.Request) {
+	app.listTimesheets(w, r, r.URL.Query(), 0)
+}
+
+// listMyTimesheetHandler lists the authenticated user's own entries. Any
+// user_id in the query string is discarded so the scope cannot be widened
+// from the client.
+func (app *application) listMyTimesheetHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	qs.Del("user_id")
+
+	app.listTimesheets(w, r, qs, app.contextGetUser(r).InternalID)
+}
+
+// listTimesheetSuggestionsHandler suggests entries for the caller to log on
+// date, today by default, from their recent entries and assignments.
+func (app *application) listTimesheetSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	v := validator.New()
+
+	day := time.Now()
+	if s := qs.Get("date"); s != "" {
+		if date := app.parseDate(v, "date", s); date != nil {
+			day = *date
+		}
+	}
+
+	limit := app.readInt(qs, "limit", 5, v)
+	v.Check(limit >= 1 && limit <= 20, "limit", "must be between 1 and 20")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	suggestions, err := app.models.Timesheet.GetSuggestions(app.contextGetUser(r).InternalID, day, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"suggestions": suggestions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listTimesheets writes the page of entries matching qs, restricted to
+// userID's entries when it is non-zero.
+func (app *application) listTimesheets(w http.ResponseWriter, r *http.Request, qs url.Values, userID int32) {
+	var input struct {
+		data.TimesheetFilter
+		data.Filters
+	}
+
+	v := validator.New()
+
+	input.TimesheetFilter = app.readTimesheetFilter(qs, v)
+	if userID != 0 {
+		input.TimesheetFilter.UserID = userID
+	}
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 0, v)
+
+	input.Filters.Sort = app.readString(qs, "sort", "-work_date")
+	input.Filters.SortSafelist = timesheetSortSafelist
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	entries, metadata, err := app.models.Timesheet.GetAll(actor, input.TimesheetFilter, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"metadata": metadata, "timesheet": entries}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showTimesheetFacetsHandler returns the users, projects, activities and date
+// range present in the entries matching the filter, for populating filter
+// dropdowns.
+func (app *application) showTimesheetFacetsHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	filter := app.readTimesheetFilter(r.URL.Query(), v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	facets, err := app.models.Timesheet.GetFacets(actor, filter)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"facets": facets}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const (
+	activationTokenTTL       = 3 * 24 * time.Hour
+	activationResendInterval = 5 * time.Minute
+)
+
+func (app *application) createActivationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateEmail(v, input.Email); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.User.GetByEmail(input.Email)
+	switch {
+	case err == nil && !user.Activated:
+		recent, err := app.models.Token.IssuedSince(data.ScopeActivation, user.InternalID, activationTokenTTL, time.Now().Add(-activationResendInterval))
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !recent {
+			err = app.models.Token.DeleteAllForUser(data.ScopeActivation, user.InternalID)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			token, err := app.models.Token.New(user.InternalID, activationTokenTTL, data.ScopeActivation)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			app.background(func() {
+				data := map[string]any{
+					"activationToken": token.Plaintext,
+				}
+
+				err := app.userMailer(user.InternalID).Send(user.Email, "token_activation.tmpl", data)
+				if err != nil {
+					app.requestLogger(r).Error(err.Error())
+				}
+			})
+		}
+	case err == nil, errors.Is(err, data.ErrRecordNotFound):
+		// Respond identically for unknown and already-activated addresses so
+		// the endpoint cannot be used to enumerate accounts.
+	default:
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"message": "if the address belongs to an account awaiting activation, an email will be sent to it containing activation instructions"}
+
+	err = app.writeJSON(w, http.StatusAccepted, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// typeaheadHandler returns the id and label of up to 20 users, projects,
+// clients or activities starting with q, for searchable selects that would
+// otherwise page through the list endpoints.
+func (app *application) typeaheadHandler(w http.ResponseWriter, r *http.Request) {
+	entity := chi.URLParam(r, "entity")
+	if !validator.PermittedValue(entity, data.TypeaheadEntities...) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	q := app.readString(r.URL.Query(), "q", "")
+
+	v := validator.New()
+	v.Check(len(q) <= 100, "q", "must not be more than 100 bytes long")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	options, err := app.models.Typeahead.Search(actor, entity, q)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"options": options}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
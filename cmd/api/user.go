@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/mailer"
+	"github.com/hwanbin/wanpm-api/internal/s3action"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// erasePersonalDataHandler anonymizes a user. As erasure cannot be undone,
+// the request must confirm it by repeating the user's current email address,
+// and only users holding user:erase may make it.
+func (app *application) erasePersonalDataHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "user:erase") {
+		return
+	}
+
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ConfirmEmail string `json:"confirm_email"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.ConfirmEmail != "", "confirm_email", "must be provided")
+	v.Check(strings.EqualFold(input.ConfirmEmail, user.Email), "confirm_email", "must match the user's email address")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	avatarKey := user.AvatarKey
+
+	err = app.models.User.Erase(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Cached responses may still carry the erased data, so none are kept.
+	app.cache.Clear()
+
+	// Remove every version of the avatar rather than trashing it, so it is
+	// not restorable.
+	if avatarKey != nil && app.storageEnabled() {
+		app.background(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			err := s3action.PermanentlyDeleteObjects(ctx, app.s3actor.client, app.config.s3.bucket, *avatarKey)
+			if err != nil {
+				app.requestLogger(r).Error("avatar deletion failed", "user_id", user.InternalID, "error", err.Error())
+			}
+		})
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateHourlyCostHandler sets what an hour of a user's time costs, which
+// timesheet reports multiply logged time by. A null cost clears it. Costs
+// are set with user:write-cost, on the users of the caller's organization.
+func (app *application) updateHourlyCostHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.requirePermission(w, r, "user:write-cost") {
+		return
+	}
+
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		HourlyCost *float64 `json:"hourly_cost"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if input.HourlyCost != nil {
+		v.Check(*input.HourlyCost >= 0, "hourly_cost", "must not be negative")
+		v.Check(*input.HourlyCost < 1e10, "hourly_cost", "must be less than 10 billion")
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	actor, err := app.actor(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.User.SetHourlyCost(actor, id, input.HourlyCost)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"hourly_cost": input.HourlyCost}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateMyLanguageHandler sets the language the caller's emails are sent in,
+// one the email templates are available in.
+func (app *application) updateMyLanguageHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Language string `json:"language"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	languages, err := mailer.Languages()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if v.Check(validator.PermittedValue(input.Language, languages...), "language", "must be one of "+strings.Join(languages, ", ")); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.User.SetLanguage(app.contextGetUser(r).InternalID, input.Language)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"language": input.Language}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+const emailChangeTokenTTL = 24 * time.Hour
+
+// requestEmailChangeHandler emails a confirmation code to the address the
+// user wants to change theirs to. Their email only changes once the code is
+// confirmed, so a typo cannot lock them out.
+func (app *application) requestEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email string `json:"email"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateEmail(v, input.Email); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.User.Get(app.contextGetUser(r).InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if strings.EqualFold(user.Email, input.Email) {
+		v.AddError("email", "must differ from the current address")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	_, err = app.models.User.GetByEmail(input.Email)
+	switch {
+	case err == nil:
+		v.AddError("email", "a user with this email address already exists")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	case !errors.Is(err, data.ErrRecordNotFound):
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.User.SetPendingEmail(user.InternalID, input.Email)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Token.DeleteAllForUser(data.ScopeEmailChange, user.InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	token, err := app.models.Token.New(user.InternalID, emailChangeTokenTTL, data.ScopeEmailChange)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, user.InternalID, data.SecurityEventEmailChangeRequested)
+
+	app.background(func() {
+		data := map[string]any{
+			"emailChangeToken": token.Plaintext,
+			"firstName":        user.FirstName,
+		}
+
+		err := app.userMailer(user.InternalID).Send(input.Email, "email_change.tmpl", data)
+		if err != nil {
+			app.requestLogger(r).Error(err.Error())
+		}
+	})
+
+	env := envelope{"message": "an email will be sent to the new address containing a code to confirm the change"}
+
+	err = app.writeJSON(w, http.StatusAccepted, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// confirmEmailChangeHandler applies a pending email change given the code
+// sent to the new address, and notifies the previous address.
+func (app *application) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token string `json:"token"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if data.ValidateTokenPlaintext(v, input.Token); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	userID, err := app.models.Token.GetUserID(data.ScopeEmailChange, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired email change token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user, err := app.models.User.Get(userID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	previous, err := app.models.User.ConfirmEmail(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired email change token")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateEmail):
+			v.AddError("email", "a user with this email address already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Token.DeleteAllForUser(data.ScopeEmailChange, user.InternalID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, user.InternalID, data.SecurityEventEmailChanged)
+
+	app.background(func() {
+		data := map[string]any{
+			"newEmail":  user.Email,
+			"firstName": user.FirstName,
+		}
+
+		err := app.userMailer(user.InternalID).Send(previous, "email_changed.tmpl", data)
+		if err != nil {
+			app.requestLogger(r).Error(err.Error())
+		}
+	})
+
+	app.setAvatarURL(r.Context(), user)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hwanbin/wanpm-api/internal/cache"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/datamock"
+)
+
+func TestErasePersonalDataClearsCache(t *testing.T) {
+	roleCalls := 0
+
+	app := &application{
+		cache: cache.New(time.Hour),
+		models: data.Models{
+			Role: &datamock.RoleStoreMock{
+				GetAllFunc: func() ([]*data.Role, error) {
+					roleCalls++
+					return []*data.Role{}, nil
+				},
+			},
+			User: &datamock.UserStoreMock{
+				GetFunc: func(id int32) (*data.User, error) {
+					return &data.User{InternalID: id, Email: "erased@example.com"}, nil
+				},
+			},
+		},
+	}
+
+	actor := data.Actor{UserID: 1, OrgID: 1, Permissions: data.Permissions{"user:erase"}}
+
+	request := func(method, target, body string) *http.Request {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r = app.contextSetUser(r, &data.User{InternalID: actor.UserID, Activated: true})
+		return r.WithContext(context.WithValue(r.Context(), actorContextKey, actor))
+	}
+
+	for range 2 {
+		app.listRoleHandler(httptest.NewRecorder(), request(http.MethodGet, "/v1/admin/role", ""))
+	}
+	if roleCalls != 1 {
+		t.Fatalf("roles read %d times before the erase, want 1 with the listing cached", roleCalls)
+	}
+
+	r := request(http.MethodDelete, "/v1/user/2/personal-data", `{"confirm_email": "erased@example.com"}`)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "2")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	w := httptest.NewRecorder()
+	app.erasePersonalDataHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("erase answered %d: %s", w.Code, w.Body)
+	}
+
+	app.listRoleHandler(httptest.NewRecorder(), request(http.MethodGet, "/v1/admin/role", ""))
+	if roleCalls != 2 {
+		t.Errorf("roles read %d times after the erase, want 2 with the cached listing gone", roleCalls)
+	}
+}
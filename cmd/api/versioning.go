@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type contextKey string
+
+const apiVersionContextKey = contextKey("apiVersion")
+
+// apiVersion records which API version a route group serves, so handlers
+// shared between versions can pick the matching response mapping.
+func (app *application) apiVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("API-Version", strconv.Itoa(version))
+
+			ctx := context.WithValue(r.Context(), apiVersionContextKey, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func (app *application) requestAPIVersion(r *http.Request) int {
+	version, ok := r.Context().Value(apiVersionContextKey).(int)
+	if !ok {
+		return 1
+	}
+
+	return version
+}
+
+// responseVersion is the response format a client asked for with a version
+// parameter in its Accept header, e.g. "application/json; version=2". It
+// lets clients opt into a newer shape on a route version that still serves
+// the old one, and falls back to the route's API version.
+func (app *application) responseVersion(r *http.Request) int {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accept)
+		if err != nil || (mediaType != "application/json" && mediaType != "*/*") {
+			continue
+		}
+
+		version, err := strconv.Atoi(params["version"])
+		if err == nil && version > 0 {
+			return version
+		}
+	}
+
+	return app.requestAPIVersion(r)
+}
+
+// deprecated marks a route slated for removal with the Deprecation header
+// (RFC 9745), a successor-version link and, when the removal date is
+// known, the Sunset header (RFC 8594).
+func (app *application) deprecated(since, sunset time.Time, successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", since.Unix()))
+
+			if successor != "" {
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+			}
+
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
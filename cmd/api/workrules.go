@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// dayLimit describes the most time a day may hold, such as "10 hours".
+func dayLimit(r *data.WorkRules) string {
+	return strconv.FormatFloat(float64(r.MaxDayMinutes)/60, 'f', -1, 64) + " hours"
+}
+
+func (app *application) showWorkRulesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	rules, err := app.models.WorkRules.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"work_rules": rules}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateWorkRulesHandler changes the rules given in the body. The daily
+// limit applies to entries saved from then on and rounding to entries
+// submitted from then on, while costs are recomputed for earlier entries
+// too, in the weekly summary once it is next refreshed.
+func (app *application) updateWorkRulesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readInt32IDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if !app.requireOrgAdmin(w, r, id) {
+		return
+	}
+
+	_, err = app.models.Organization.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	rules, err := app.models.WorkRules.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		DayMinutes         *int32   `json:"day_minutes"`
+		MaxDayMinutes      *int32   `json:"max_day_minutes"`
+		OvertimeMultiplier *float64 `json:"overtime_multiplier"`
+		WeekendMultiplier  *float64 `json:"weekend_multiplier"`
+		IncrementMinutes   *int32   `json:"increment_minutes"`
+		Rounding           *string  `json:"rounding"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.DayMinutes != nil {
+		rules.DayMinutes = *input.DayMinutes
+	}
+	if input.MaxDayMinutes != nil {
+		rules.MaxDayMinutes = *input.MaxDayMinutes
+	}
+	if input.OvertimeMultiplier != nil {
+		rules.OvertimeMultiplier = *input.OvertimeMultiplier
+	}
+	if input.WeekendMultiplier != nil {
+		rules.WeekendMultiplier = *input.WeekendMultiplier
+	}
+	if input.IncrementMinutes != nil {
+		rules.IncrementMinutes = *input.IncrementMinutes
+	}
+	if input.Rounding != nil {
+		rules.Rounding = *input.Rounding
+	}
+
+	v := validator.New()
+	if data.ValidateWorkRules(v, rules); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.WorkRules.Update(rules)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"work_rules": rules}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
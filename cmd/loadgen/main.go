@@ -0,0 +1,409 @@
+// Command loadgen fills a database with synthetic users, clients, projects
+// and timesheet entries so query and index changes can be benchmarked
+// against a reproducible data set. Everything it writes is tagged and can
+// be removed again with -clean.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	emailPattern    = "loadgen+%d@example.com"
+	emailLike       = "loadgen+%@example.com"
+	clientPrefix    = "Loadgen Client "
+	proposalPrefix  = "LG"
+	timesheetSource = "loadgen"
+)
+
+type config struct {
+	dsn        string
+	users      int
+	projects   int
+	timesheets int
+	seed       int64
+	clean      bool
+}
+
+var (
+	firstNames = []string{"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie", "Avery", "Quinn", "Drew", "Reese"}
+	lastNames  = []string{"Kim", "Lee", "Park", "Smith", "Chen", "Patel", "Nguyen", "Brown", "Garcia", "Singh", "Wilson", "Martin"}
+	towns      = []string{"Toronto", "Markham", "Richmond Hill", "Vaughan", "Mississauga", "Oakville", "Pickering", "Newmarket"}
+	// Most projects are active; the weights roughly follow production.
+	statuses = []struct {
+		name   string
+		weight float64
+	}{
+		{"In Progress", 0.5},
+		{"Completed", 0.25},
+		{"Pending", 0.2},
+		{"On Hold", 0.05},
+	}
+)
+
+func main() {
+	var cfg config
+
+	flag.StringVar(&cfg.dsn, "db-dsn", os.Getenv("WANTONI_DB_DSN"), "PostgreSQL DSN")
+	flag.IntVar(&cfg.users, "users", 100, "Number of users to generate")
+	flag.IntVar(&cfg.projects, "projects", 1000, "Number of projects to generate")
+	flag.IntVar(&cfg.timesheets, "timesheets", 1_000_000, "Number of timesheet entries to generate")
+	flag.Int64Var(&cfg.seed, "seed", 1, "Random seed, the same seed reproduces the same data set")
+	flag.BoolVar(&cfg.clean, "clean", false, "Remove previously generated data and exit")
+
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	db, err := sql.Open("postgres", cfg.dsn)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	g := &generator{
+		db:     db,
+		logger: logger,
+		rng:    rand.New(rand.NewSource(cfg.seed)),
+	}
+
+	if cfg.clean {
+		err = g.clean()
+	} else {
+		err = g.run(cfg)
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+type generator struct {
+	db     *sql.DB
+	logger *slog.Logger
+	rng    *rand.Rand
+}
+
+func (g *generator) run(cfg config) error {
+	if cfg.users < 1 || cfg.projects < 1 || cfg.timesheets < 0 {
+		return fmt.Errorf("users and projects must be positive and timesheets must not be negative")
+	}
+
+	start := time.Now()
+
+	userIDs, err := g.insertUsers(cfg.users)
+	if err != nil {
+		return fmt.Errorf("users: %w", err)
+	}
+	g.logger.Info("users generated", "count", len(userIDs))
+
+	projectIDs, err := g.insertProjects(cfg.projects)
+	if err != nil {
+		return fmt.Errorf("projects: %w", err)
+	}
+	g.logger.Info("projects generated", "count", len(projectIDs))
+
+	count, err := g.insertTimesheets(cfg.timesheets, userIDs, projectIDs)
+	if err != nil {
+		return fmt.Errorf("timesheets: %w", err)
+	}
+	g.logger.Info("timesheet entries generated", "count", count)
+
+	_, err = g.db.Exec(`ANALYZE appuser, client, project, project_client, timesheet_entry`)
+	if err != nil {
+		return err
+	}
+
+	g.logger.Info("done", "elapsed", time.Since(start).String())
+	return nil
+}
+
+func (g *generator) clean() error {
+	queries := []string{
+		`DELETE FROM timesheet_entry WHERE source = '` + timesheetSource + `'`,
+		`DELETE FROM project WHERE proposal_id LIKE '` + proposalPrefix + `%'`,
+		`DELETE FROM client WHERE name LIKE '` + clientPrefix + `%'`,
+		`DELETE FROM appuser WHERE email LIKE '` + emailLike + `'`,
+	}
+
+	for _, query := range queries {
+		result, err := g.db.Exec(query)
+		if err != nil {
+			return err
+		}
+
+		rows, _ := result.RowsAffected()
+		g.logger.Info("removed", "query", query, "rows", rows)
+	}
+
+	return nil
+}
+
+// copyRows streams rows into table with COPY inside a single transaction.
+func (g *generator) copyRows(table string, columns []string, rows func(emit func(values ...any) error) error) error {
+	ctx := context.Background()
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+
+	err = rows(func(values ...any) error {
+		_, err := stmt.ExecContext(ctx, values...)
+		return err
+	})
+	if err != nil {
+		stmt.Close()
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		stmt.Close()
+		return err
+	}
+
+	err = stmt.Close()
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (g *generator) ids(query string, args ...any) ([]int32, error) {
+	rows, err := g.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int32{}
+	for rows.Next() {
+		var id int32
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (g *generator) insertUsers(n int) ([]int32, error) {
+	var offset int
+	err := g.db.QueryRow(`SELECT count(*) FROM appuser WHERE email LIKE $1`, emailLike).Scan(&offset)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.copyRows("appuser", []string{"email", "first_name", "last_name", "activated"}, func(emit func(values ...any) error) error {
+		for i := offset; i < offset+n; i++ {
+			// Roughly one in ten accounts never gets activated.
+			err := emit(
+				fmt.Sprintf(emailPattern, i),
+				firstNames[g.rng.Intn(len(firstNames))],
+				lastNames[g.rng.Intn(len(lastNames))],
+				g.rng.Float64() >= 0.1,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g.ids(`SELECT internal_id FROM appuser WHERE email LIKE $1 ORDER BY internal_id`, emailLike)
+}
+
+func (g *generator) insertProjects(n int) ([]int32, error) {
+	var firstProjectID, firstProposal int
+	err := g.db.QueryRow(`SELECT COALESCE(MAX(project_id), 0) + 1 FROM project`).Scan(&firstProjectID)
+	if err != nil {
+		return nil, err
+	}
+	err = g.db.QueryRow(`SELECT count(*) FROM project WHERE proposal_id LIKE $1`, proposalPrefix+"%").Scan(&firstProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	// About one client per ten projects, each reused across several.
+	clients := n/10 + 1
+	err = g.copyRows("client", []string{"name", "address"}, func(emit func(values ...any) error) error {
+		for i := 0; i < clients; i++ {
+			err := emit(fmt.Sprintf("%s%d-%d", clientPrefix, firstProposal, i), towns[g.rng.Intn(len(towns))]+", ON, Canada")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientIDs, err := g.ids(`SELECT internal_id FROM client WHERE name LIKE $1 ORDER BY internal_id DESC LIMIT $2`, fmt.Sprintf("%s%d-%%", clientPrefix, firstProposal), clients)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.copyRows("project", []string{"project_id", "proposal_id", "name", "status", "feature"}, func(emit func(values ...any) error) error {
+		for i := 0; i < n; i++ {
+			town := towns[g.rng.Intn(len(towns))]
+			name := fmt.Sprintf("%s %d", town, firstProposal+i)
+			// Projects cluster around the Greater Toronto Area.
+			lng := -79.4 + g.rng.NormFloat64()*0.2
+			lat := 43.75 + g.rng.NormFloat64()*0.15
+			feature := fmt.Sprintf(`{"type":"Feature","geometry":{"type":"Point","coordinates":[%.6f,%.6f]},"properties":{"name":%q,"full_address":"%d Main Street, %s, ON, Canada"}}`,
+				lng, lat, name, g.rng.Intn(999)+1, town)
+
+			err := emit(firstProjectID+i, fmt.Sprintf("%s%07d", proposalPrefix, firstProposal+i), name, g.status(), feature)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs, err := g.ids(`SELECT internal_id FROM project WHERE project_id >= $1 ORDER BY project_id`, firstProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.copyRows("project_client", []string{"project_internal_id", "client_internal_id"}, func(emit func(values ...any) error) error {
+		for _, projectID := range projectIDs {
+			first := g.rng.Intn(len(clientIDs))
+			err := emit(projectID, clientIDs[first])
+			if err != nil {
+				return err
+			}
+
+			// A minority of projects are shared between two clients.
+			if second := g.rng.Intn(len(clientIDs)); g.rng.Float64() < 0.15 && second != first {
+				err = emit(projectID, clientIDs[second])
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return projectIDs, nil
+}
+
+func (g *generator) status() string {
+	r := g.rng.Float64()
+	for _, status := range statuses {
+		if r < status.weight {
+			return status.name
+		}
+		r -= status.weight
+	}
+	return statuses[0].name
+}
+
+// insertTimesheets spreads n entries over the users' working days, walking
+// back from today. Each user works on a handful of projects, drawn with a
+// Zipf distribution so a few projects collect most of the hours, and logs
+// one to three entries a weekday adding up to about a full day.
+func (g *generator) insertTimesheets(n int, userIDs, projectIDs []int32) (int, error) {
+	activityIDs, err := g.ids(`SELECT internal_id FROM activity`)
+	if err != nil {
+		return 0, err
+	}
+
+	zipf := rand.NewZipf(g.rng, 1.2, 1, uint64(len(projectIDs)-1))
+	assignments := make([][]int32, len(userIDs))
+	for i := range assignments {
+		for j := 0; j < 3+g.rng.Intn(6); j++ {
+			assignments[i] = append(assignments[i], projectIDs[zipf.Uint64()])
+		}
+	}
+
+	columns := []string{"user_internal_id", "project_internal_id", "activity_internal_id", "work_date", "minutes", "note", "source", "deleted_at"}
+
+	written := 0
+	err = g.copyRows("timesheet_entry", columns, func(emit func(values ...any) error) error {
+		day := time.Now().UTC().Truncate(24 * time.Hour)
+
+		for written < n {
+			day = day.AddDate(0, 0, -1)
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				continue
+			}
+
+			for i, userID := range userIDs {
+				if written >= n {
+					break
+				}
+
+				// Some days off, more or less one in twenty.
+				if g.rng.Float64() < 0.05 {
+					continue
+				}
+
+				entries := 1 + g.rng.Intn(3)
+				remaining := int(math.Round((7.5+g.rng.NormFloat64())*4)) * 15
+
+				for e := 0; e < entries && written < n && remaining >= 15; e++ {
+					minutes := remaining
+					if e < entries-1 {
+						minutes = (g.rng.Intn(remaining/15) + 1) * 15
+					}
+					remaining -= minutes
+
+					var activityID *int32
+					if len(activityIDs) > 0 {
+						activityID = &activityIDs[g.rng.Intn(len(activityIDs))]
+					}
+
+					// A small share of entries are soft deleted, which the
+					// retention job later purges.
+					var deletedAt *time.Time
+					if g.rng.Float64() < 0.02 {
+						t := day.Add(48 * time.Hour)
+						deletedAt = &t
+					}
+
+					projects := assignments[i]
+					err := emit(userID, projects[g.rng.Intn(len(projects))], activityID, day, min(minutes, 24*60), "", timesheetSource, deletedAt)
+					if err != nil {
+						return err
+					}
+					written++
+				}
+			}
+		}
+		return nil
+	})
+
+	return written, err
+}
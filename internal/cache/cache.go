@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type item struct {
+	value   []byte
+	expires time.Time
+}
+
+type Cache struct {
+	mu    sync.RWMutex
+	ttl   time.Duration
+	items map[string]item
+}
+
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:   ttl,
+		items: make(map[string]item),
+	}
+}
+
+func (c *Cache) Enabled() bool {
+	return c.ttl > 0
+}
+
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if !c.Enabled() {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	it, found := c.items[key]
+	if !found || time.Now().After(it.expires) {
+		return nil, false
+	}
+
+	return it.value, true
+}
+
+func (c *Cache) Set(key string, value []byte) {
+	if !c.Enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = item{
+		value:   value,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+// Invalidate drops every entry whose key starts with prefix, so a mutation
+// on one resource only evicts the lists derived from it.
+func (c *Cache) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+		}
+	}
+}
+
+// Clear drops every entry, expired or not.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.items)
+}
+
+// Purge drops the entries that have expired.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, it := range c.items {
+		if now.After(it.expires) {
+			delete(c.items, key)
+		}
+	}
+}
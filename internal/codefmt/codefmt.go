@@ -0,0 +1,185 @@
+// Package codefmt parses the formats organizations require their project
+// and proposal codes to follow. A format is literal text with placeholders:
+// YYYY for the four digit year, YY for its last two digits and a run of N
+// for a sequence number of that many digits. YYYY-NNN matches 2026-014 and
+// YYNNN matches 26014.
+package codefmt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrExhausted is returned by Next when every sequence number of the year
+// is taken.
+var ErrExhausted = errors.New("no sequence numbers left")
+
+type kind int
+
+const (
+	literal kind = iota
+	year4
+	year2
+	sequence
+)
+
+type part struct {
+	kind  kind
+	text  string
+	width int
+}
+
+// Format is a parsed code format.
+type Format struct {
+	text  string
+	parts []part
+	re    *regexp.Regexp
+}
+
+// Parse parses a code format, which must contain exactly one sequence.
+func Parse(format string) (*Format, error) {
+	f := &Format{text: format}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	sequences := 0
+	for rest := format; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "YYYY"):
+			f.parts = append(f.parts, part{kind: year4, width: 4})
+			pattern.WriteString(`(\d{4})`)
+			rest = rest[4:]
+		case strings.HasPrefix(rest, "YY"):
+			f.parts = append(f.parts, part{kind: year2, width: 2})
+			pattern.WriteString(`(\d{2})`)
+			rest = rest[2:]
+		case rest[0] == 'N':
+			width := len(rest) - len(strings.TrimLeft(rest, "N"))
+			f.parts = append(f.parts, part{kind: sequence, width: width})
+			fmt.Fprintf(&pattern, `(\d{%d})`, width)
+			rest = rest[width:]
+			sequences++
+		default:
+			text := rest[:1]
+			if n := len(f.parts); n > 0 && f.parts[n-1].kind == literal {
+				f.parts[n-1].text += text
+			} else {
+				f.parts = append(f.parts, part{kind: literal, text: text})
+			}
+			pattern.WriteString(regexp.QuoteMeta(text))
+			rest = rest[1:]
+		}
+	}
+
+	if sequences != 1 {
+		return nil, errors.New("must contain exactly one sequence of N")
+	}
+
+	pattern.WriteString("$")
+	f.re = regexp.MustCompile(pattern.String())
+
+	return f, nil
+}
+
+func (f *Format) String() string {
+	return f.text
+}
+
+// Numeric reports whether every code in the format is a number.
+func (f *Format) Numeric() bool {
+	for _, p := range f.parts {
+		if p.kind == literal && strings.Trim(p.text, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// LeadingZero reports whether codes may start with 0, which a code stored
+// as a number loses.
+func (f *Format) LeadingZero() bool {
+	first := f.parts[0]
+	return first.kind == sequence || first.kind == literal && first.text[0] == '0'
+}
+
+// Len is the length of every code in the format.
+func (f *Format) Len() int {
+	n := 0
+	for _, p := range f.parts {
+		n += p.width + len(p.text)
+	}
+	return n
+}
+
+// Match reports whether code follows the format, for any year.
+func (f *Format) Match(code string) bool {
+	return f.re.MatchString(code)
+}
+
+// Next returns the code following the highest of codes in the year of now,
+// or the first of that year when there is none.
+func (f *Format) Next(now time.Time, codes []string) (string, error) {
+	year := now.Year()
+	highest := 0
+
+	for _, code := range codes {
+		groups := f.re.FindStringSubmatch(code)
+		if groups == nil {
+			continue
+		}
+
+		seq, sameYear := 0, true
+		i := 1
+		for _, p := range f.parts {
+			if p.kind == literal {
+				continue
+			}
+
+			n, _ := strconv.Atoi(groups[i])
+			i++
+
+			switch p.kind {
+			case year4:
+				sameYear = sameYear && n == year
+			case year2:
+				sameYear = sameYear && n == year%100
+			case sequence:
+				seq = n
+			}
+		}
+
+		if sameYear && seq > highest {
+			highest = seq
+		}
+	}
+
+	return f.render(year, highest+1)
+}
+
+func (f *Format) render(year, seq int) (string, error) {
+	var b strings.Builder
+
+	for _, p := range f.parts {
+		switch p.kind {
+		case literal:
+			b.WriteString(p.text)
+		case year4:
+			fmt.Fprintf(&b, "%04d", year)
+		case year2:
+			fmt.Fprintf(&b, "%02d", year%100)
+		case sequence:
+			s := fmt.Sprintf("%0*d", p.width, seq)
+			if len(s) > p.width {
+				return "", ErrExhausted
+			}
+			b.WriteString(s)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,293 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// CustomerMapping names the accounting system customer a project is billed
+// to.
+type CustomerMapping struct {
+	ProjectID int32     `json:"project_id"`
+	Customer  string    `json:"customer"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ServiceItemMapping names the accounting system service item time on an
+// activity is billed as.
+type ServiceItemMapping struct {
+	ActivityID  int32     `json:"activity_id"`
+	ServiceItem string    `json:"service_item"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AccountingLine is approved time on a project mapped to a customer, as the
+// accounting export bills it. ServiceItem is empty when the entry's
+// activity has no service item.
+type AccountingLine struct {
+	WorkDate    time.Time
+	Customer    string
+	ServiceItem string
+	Employee    string
+	ProjectID   int32
+	ProjectName string
+	// Minutes are the billed minutes, rounded by the user's work rules.
+	Minutes int32
+	Note    string
+}
+
+func ValidateAccountingName(v *validator.Validator, key, name string) {
+	v.Check(name != "", key, "must be provided")
+	v.Check(len(name) <= 100, key, "must not be more than 100 bytes long")
+}
+
+type AccountingMappingModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// GetCustomers lists the customer mappings of the actor's organization's
+// projects.
+func (m AccountingMappingModel) GetCustomers(actor Actor) ([]*CustomerMapping, error) {
+	scope, scopeArgs := actor.orgScope("p.org_internal_id", 1)
+
+	query := `
+		SELECT p.project_id, acm.customer, acm.updated_at
+		FROM accounting_customer_map acm
+		INNER JOIN project p ON acm.project_internal_id = p.internal_id
+		WHERE true` + scope + `
+		ORDER BY p.project_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, scopeArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	mappings := []*CustomerMapping{}
+
+	for rows.Next() {
+		var mapping CustomerMapping
+		err := rows.Scan(&mapping.ProjectID, &mapping.Customer, &mapping.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		mappings = append(mappings, &mapping)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+// GetServiceItems lists the service item mappings of the actor's
+// organization's activities.
+func (m AccountingMappingModel) GetServiceItems(actor Actor) ([]*ServiceItemMapping, error) {
+	scope, scopeArgs := actor.orgScope("a.org_internal_id", 1)
+
+	query := `
+		SELECT aim.activity_internal_id, aim.service_item, aim.updated_at
+		FROM accounting_item_map aim
+		INNER JOIN activity a ON aim.activity_internal_id = a.internal_id
+		WHERE true` + scope + `
+		ORDER BY aim.activity_internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, scopeArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	mappings := []*ServiceItemMapping{}
+
+	for rows.Next() {
+		var mapping ServiceItemMapping
+		err := rows.Scan(&mapping.ActivityID, &mapping.ServiceItem, &mapping.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		mappings = append(mappings, &mapping)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+// SetCustomer maps a project of the actor's organization to a customer.
+func (m AccountingMappingModel) SetCustomer(actor Actor, mapping *CustomerMapping) error {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 3)
+
+	query := `
+		INSERT INTO accounting_customer_map (project_internal_id, customer)
+		SELECT internal_id, $2
+		FROM project
+		WHERE project_id = $1` + scope + `
+		ON CONFLICT (project_internal_id) DO UPDATE
+		SET customer = EXCLUDED.customer, updated_at = NOW()
+		RETURNING updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	args := append([]any{mapping.ProjectID, mapping.Customer}, scopeArgs...)
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&mapping.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetServiceItem maps an activity of the actor's organization to a service
+// item.
+func (m AccountingMappingModel) SetServiceItem(actor Actor, mapping *ServiceItemMapping) error {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 3)
+
+	query := `
+		INSERT INTO accounting_item_map (activity_internal_id, service_item)
+		SELECT internal_id, $2
+		FROM activity
+		WHERE internal_id = $1` + scope + `
+		ON CONFLICT (activity_internal_id) DO UPDATE
+		SET service_item = EXCLUDED.service_item, updated_at = NOW()
+		RETURNING updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	args := append([]any{mapping.ActivityID, mapping.ServiceItem}, scopeArgs...)
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&mapping.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m AccountingMappingModel) DeleteCustomer(actor Actor, externalID int32) error {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+
+	query := `
+		DELETE FROM accounting_customer_map
+		WHERE project_internal_id = (SELECT internal_id FROM project WHERE project_id = $1` + scope + `)`
+
+	return m.exec(query, append([]any{externalID}, scopeArgs...)...)
+}
+
+func (m AccountingMappingModel) DeleteServiceItem(actor Actor, activityID int32) error {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+
+	query := `
+		DELETE FROM accounting_item_map
+		WHERE activity_internal_id = (SELECT internal_id FROM activity WHERE internal_id = $1` + scope + `)`
+
+	return m.exec(query, append([]any{activityID}, scopeArgs...)...)
+}
+
+// GetLines returns the approved time worked between from and to, inclusive,
+// on the actor's organization's projects that are mapped to a customer,
+// ordered by customer and date. Time on unmapped projects is left out.
+func (m AccountingMappingModel) GetLines(actor Actor, from, to time.Time) ([]*AccountingLine, error) {
+	scope, scopeArgs := actor.orgScope("p.org_internal_id", 3)
+
+	query := `
+		SELECT t.work_date, acm.customer, COALESCE(aim.service_item, ''), trim(u.first_name || ' ' || u.last_name),
+			p.project_id, COALESCE(p.name, ''), COALESCE(t.billed_minutes, t.minutes), t.note
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		INNER JOIN accounting_customer_map acm ON acm.project_internal_id = p.internal_id
+		INNER JOIN appuser u ON u.internal_id = t.user_internal_id
+		LEFT JOIN accounting_item_map aim ON aim.activity_internal_id = t.activity_internal_id
+		WHERE t.status = 'approved' AND t.deleted_at IS NULL
+		AND t.work_date BETWEEN $1 AND $2` + scope + `
+		ORDER BY acm.customer, t.work_date, t.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{from, to}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	lines := []*AccountingLine{}
+
+	for rows.Next() {
+		var line AccountingLine
+		err := rows.Scan(
+			&line.WorkDate,
+			&line.Customer,
+			&line.ServiceItem,
+			&line.Employee,
+			&line.ProjectID,
+			&line.ProjectName,
+			&line.Minutes,
+			&line.Note,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, &line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func (m AccountingMappingModel) exec(query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrActivityHasChildren = errors.New("activity has children")
+	// ErrActivityNotEnabled is returned when time is recorded against an
+	// activity that is not enabled for the entry's project.
+	ErrActivityNotEnabled = errors.New("activity not enabled for project")
+)
+
+type Activity struct {
+	InternalID int32       `json:"id"`
+	Name       string      `json:"name"`
+	ParentID   *int32      `json:"parent_id"`
+	Children   []*Activity `json:"children,omitempty"`
+	OrgID      int32       `json:"-"`
+	Version    int32       `json:"version"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+func ValidateActivity(v *validator.Validator, activity *Activity) {
+	v.Check(activity.Name != "", "name", "must be provided")
+	v.Check(len(activity.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	if activity.ParentID != nil {
+		v.Check(*activity.ParentID != activity.InternalID, "parent_id", "must not reference the activity itself")
+	}
+}
+
+// BuildActivityTree nests a flat list of activities under their parents and
+// returns the roots.
+func BuildActivityTree(activities []*Activity) []*Activity {
+	byID := make(map[int32]*Activity, len(activities))
+	for _, activity := range activities {
+		byID[activity.InternalID] = activity
+	}
+
+	roots := []*Activity{}
+	for _, activity := range activities {
+		if activity.ParentID != nil {
+			if parent, ok := byID[*activity.ParentID]; ok {
+				parent.Children = append(parent.Children, activity)
+				continue
+			}
+		}
+		roots = append(roots, activity)
+	}
+
+	return roots
+}
+
+type ActivityModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m ActivityModel) Insert(activity *Activity) error {
+	query := `
+		INSERT INTO activity (name, parent_internal_id, org_internal_id)
+		VALUES ($1, $2, $3)
+		RETURNING internal_id, version, created_at, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, activity.Name, activity.ParentID, activity.OrgID).Scan(
+		&activity.InternalID,
+		&activity.Version,
+		&activity.CreatedAt,
+		&activity.UpdatedAt,
+	)
+}
+
+// Get returns the activity if it belongs to the actor's organization.
+func (m ActivityModel) Get(actor Actor, id int32) (*Activity, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT internal_id, name, parent_internal_id, org_internal_id, version, created_at, updated_at
+		FROM activity
+		WHERE internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	var activity Activity
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&activity.InternalID,
+		&activity.Name,
+		&activity.ParentID,
+		&activity.OrgID,
+		&activity.Version,
+		&activity.CreatedAt,
+		&activity.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &activity, nil
+}
+
+// GetAll returns the activities of the actor's organization.
+func (m ActivityModel) GetAll(actor Actor) ([]*Activity, error) {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 1)
+
+	query := `
+		SELECT internal_id, name, parent_internal_id, version, created_at, updated_at
+		FROM activity
+		WHERE true` + scope + `
+		ORDER BY name, internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, scopeArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	activities := []*Activity{}
+
+	for rows.Next() {
+		var activity Activity
+		err := rows.Scan(
+			&activity.InternalID,
+			&activity.Name,
+			&activity.ParentID,
+			&activity.Version,
+			&activity.CreatedAt,
+			&activity.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		activities = append(activities, &activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+// IsDescendant reports whether candidate is id itself or sits anywhere
+// below it, i.e. whether making candidate the parent of id would create a
+// cycle.
+func (m ActivityModel) IsDescendant(id, candidate int32) (bool, error) {
+	query := `
+		WITH RECURSIVE ancestor (internal_id, parent_internal_id) AS (
+			SELECT internal_id, parent_internal_id FROM activity WHERE internal_id = $2
+			UNION
+			SELECT a.internal_id, a.parent_internal_id
+			FROM activity a
+			INNER JOIN ancestor c ON a.internal_id = c.parent_internal_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestor WHERE internal_id = $1)`
+
+	var found bool
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id, candidate).Scan(&found)
+	return found, err
+}
+
+func (m ActivityModel) Update(activity *Activity) error {
+	query := `
+		UPDATE activity
+		SET name = $1, parent_internal_id = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $3 AND version = $4
+		RETURNING version, updated_at`
+
+	args := []any{activity.Name, activity.ParentID, activity.InternalID, activity.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&activity.Version, &activity.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the activity if it belongs to the actor's organization.
+func (m ActivityModel) Delete(actor Actor, id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM activity
+		WHERE internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, append([]any{id}, scopeArgs...)...)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: update or delete on table "activity" violates foreign key constraint "activity_parent_internal_id_fkey" on table "activity"`:
+			return ErrActivityHasChildren
+		default:
+			return err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m ActivityModel) GetAllForProject(externalID int32) ([]*Activity, error) {
+	query := `
+		SELECT a.internal_id, a.name, a.parent_internal_id, a.version, a.created_at, a.updated_at
+		FROM activity a
+		INNER JOIN project_activity pa ON pa.activity_internal_id = a.internal_id
+		INNER JOIN project p ON pa.project_internal_id = p.internal_id
+		WHERE p.project_id = $1
+		ORDER BY a.name, a.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	activities := []*Activity{}
+
+	for rows.Next() {
+		var activity Activity
+		err := rows.Scan(
+			&activity.InternalID,
+			&activity.Name,
+			&activity.ParentID,
+			&activity.Version,
+			&activity.CreatedAt,
+			&activity.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		activities = append(activities, &activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+// SetForProject replaces the activities that can be billed to a project.
+// Unknown activity ids, and those of other organizations, are reported as
+// ErrRecordNotFound and leave the previous set untouched.
+func (m ActivityModel) SetForProject(projectInternalID int32, activityIDs []int32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM project_activity WHERE project_internal_id = $1`, projectInternalID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO project_activity (project_internal_id, activity_internal_id)
+		SELECT $1, internal_id
+		FROM activity
+		WHERE internal_id = ANY($2::integer[])
+		AND org_internal_id = (SELECT org_internal_id FROM project WHERE internal_id = $1)`
+
+	result, err := tx.ExecContext(ctx, query, projectInternalID, pq.Array(activityIDs))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != int64(len(activityIDs)) {
+		return ErrRecordNotFound
+	}
+
+	return tx.Commit()
+}
+
+// IsEnabledForProject reports whether time can be recorded against an
+// activity on a project.
+func (m ActivityModel) IsEnabledForProject(projectInternalID, activityID int32) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return activityEnabled(ctx, m.DB, projectInternalID, activityID)
+}
+
+func activityEnabled(ctx context.Context, db DBTX, projectInternalID, activityID int32) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM project_activity
+			WHERE project_internal_id = $1 AND activity_internal_id = $2
+		)`
+
+	var enabled bool
+
+	err := db.QueryRowContext(ctx, query, projectInternalID, activityID).Scan(&enabled)
+	return enabled, err
+}
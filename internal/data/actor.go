@@ -0,0 +1,75 @@
+package data
+
+import "fmt"
+
+// Actor is the user a query runs on behalf of. Model methods that take an
+// Actor only return the rows it may see, so the scoping holds however the
+// method is reached.
+type Actor struct {
+	UserID      int32
+	OrgID       int32
+	Permissions Permissions
+	// Unrestricted lifts the scoping, for background jobs and other work the
+	// server does on its own behalf.
+	Unrestricted bool
+}
+
+// SystemActor sees every row in every organization.
+var SystemActor = Actor{Unrestricted: true}
+
+// projectScope restricts the project aliased p to those the actor may see:
+// every project in its organization with project:read-all, otherwise the
+// projects it is assigned to. The actor's arguments are bound from $n.
+func (a Actor) projectScope(n int) (string, []any) {
+	clause := fmt.Sprintf(`
+		AND (
+			$%[1]d::boolean
+			OR p.internal_id IN (
+				SELECT project_internal_id
+				FROM project_appuser
+				WHERE appuser_internal_id = $%[2]d
+			)
+		)
+		AND ($%[3]d::boolean OR p.org_internal_id = $%[4]d)`, n, n+1, n+2, n+3)
+
+	readAll := a.Unrestricted || a.Permissions.Include("project:read-all")
+
+	return clause, []any{readAll, a.UserID, a.Unrestricted, a.OrgID}
+}
+
+// orgScope restricts rows to those of the actor's organization, read from
+// column. The anonymous actor belongs to no organization and sees none. The
+// actor's arguments are bound from $n.
+func (a Actor) orgScope(column string, n int) (string, []any) {
+	clause := fmt.Sprintf(`
+		AND ($%[1]d::boolean OR %[2]s = $%[3]d)`, n, column, n+1)
+
+	return clause, []any{a.Unrestricted, a.OrgID}
+}
+
+// timesheetScope restricts the entry aliased t, on the project aliased p, to
+// those the actor may see: every entry in its organization with
+// timesheet:read-all, entries on its assigned projects with
+// timesheet:read-project, and its own entries otherwise. The actor's
+// arguments are bound from $n.
+func (a Actor) timesheetScope(n int) (string, []any) {
+	clause := fmt.Sprintf(`
+		AND (
+			$%[1]d::boolean
+			OR t.user_internal_id = $%[2]d
+			OR (
+				$%[3]d::boolean
+				AND t.project_internal_id IN (
+					SELECT project_internal_id
+					FROM project_appuser
+					WHERE appuser_internal_id = $%[2]d
+				)
+			)
+		)
+		AND ($%[4]d::boolean OR p.org_internal_id = $%[5]d)`, n, n+1, n+2, n+3, n+4)
+
+	readAll := a.Unrestricted || a.Permissions.Include("timesheet:read-all")
+	readProject := a.Permissions.Include("timesheet:read-project")
+
+	return clause, []any{readAll, a.UserID, readProject, a.Unrestricted, a.OrgID}
+}
@@ -0,0 +1,292 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+var (
+	ErrDuplicateAllocation = errors.New("duplicate allocation")
+	// ErrOverAllocated is returned when an allocation would plan more of a
+	// user's week than they have available.
+	ErrOverAllocated = errors.New("over-allocated")
+)
+
+// Allocation plans hours of a user's week on a project. Weeks start on
+// Monday.
+type Allocation struct {
+	InternalID int32     `json:"id"`
+	UserID     int32     `json:"user_id"`
+	ProjectID  int32     `json:"project_id"`
+	Week       time.Time `json:"week"`
+	Hours      float64   `json:"hours"`
+	Version    int32     `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type AllocationFilter struct {
+	UserID    int32
+	ProjectID int32
+	From      *time.Time
+	To        *time.Time
+}
+
+func ValidateAllocation(v *validator.Validator, a *Allocation) {
+	v.Check(a.UserID > 0, "user_id", "must be provided")
+	v.Check(a.ProjectID > 0, "project_id", "must be provided")
+	v.Check(!a.Week.IsZero(), "week", "must be provided")
+	v.Check(a.Week.IsZero() || a.Week.Weekday() == time.Monday, "week", "must be a Monday")
+	v.Check(a.Hours > 0, "hours", "must be greater than zero")
+	v.Check(a.Hours <= 168, "hours", "must not be more than 168")
+}
+
+type AllocationModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Insert adds an allocation unless it would take the user's planned hours
+// for the week past available, in which case it returns ErrOverAllocated.
+// The project must be one the actor sees and the user a member of its
+// organization, or it returns ErrRecordNotFound.
+func (m AllocationModel) Insert(actor Actor, a *Allocation, available float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = checkAllocation(ctx, tx, a, available)
+	if err != nil {
+		return err
+	}
+
+	scope, scopeArgs := actor.projectScope(5)
+
+	query := `
+		INSERT INTO allocation (user_internal_id, project_internal_id, week, hours)
+		SELECT $1, p.internal_id, $3, $4
+		FROM project p
+		WHERE p.project_id = $2 AND p.deleted_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM appuser u WHERE u.internal_id = $1 AND u.org_internal_id = p.org_internal_id
+		)` + scope + `
+		RETURNING internal_id, version, created_at, updated_at`
+
+	args := append([]any{a.UserID, a.ProjectID, a.Week, a.Hours}, scopeArgs...)
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&a.InternalID, &a.Version, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "allocation_user_internal_id_project_internal_id_week_key"`:
+			return ErrDuplicateAllocation
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get returns the allocation if its project is one the actor sees.
+func (m AllocationModel) Get(actor Actor, id int32) (*Allocation, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT a.internal_id, a.user_internal_id, p.project_id, a.week, a.hours::float8, a.version, a.created_at, a.updated_at
+		FROM allocation a
+		INNER JOIN project p ON p.internal_id = a.project_internal_id
+		WHERE a.internal_id = $1`
+
+	scope, scopeArgs := actor.projectScope(2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var a Allocation
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(&a.InternalID, &a.UserID, &a.ProjectID, &a.Week, &a.Hours, &a.Version, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &a, nil
+}
+
+// GetAll lists the allocations matching the filter on projects the actor
+// sees, the weeks starting between From and To when they are set.
+func (m AllocationModel) GetAll(actor Actor, filter AllocationFilter) ([]*Allocation, error) {
+	scope, scopeArgs := actor.projectScope(5)
+
+	query := `
+		SELECT a.internal_id, a.user_internal_id, p.project_id, a.week, a.hours::float8, a.version, a.created_at, a.updated_at
+		FROM allocation a
+		INNER JOIN project p ON p.internal_id = a.project_internal_id
+		WHERE ($1 = 0 OR a.user_internal_id = $1)
+		AND ($2 = 0 OR p.project_id = $2)
+		AND ($3::date IS NULL OR a.week >= $3)
+		AND ($4::date IS NULL OR a.week <= $4)
+		AND p.deleted_at IS NULL` + scope + `
+		ORDER BY a.week, a.user_internal_id, p.project_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	args := append([]any{filter.UserID, filter.ProjectID, filter.From, filter.To}, scopeArgs...)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	allocations := []*Allocation{}
+
+	for rows.Next() {
+		var a Allocation
+		err := rows.Scan(&a.InternalID, &a.UserID, &a.ProjectID, &a.Week, &a.Hours, &a.Version, &a.CreatedAt, &a.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		allocations = append(allocations, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// Update changes the week and hours of an allocation, with the same check
+// against available as Insert.
+func (m AllocationModel) Update(a *Allocation, available float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = checkAllocation(ctx, tx, a, available)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE allocation
+		SET week = $1, hours = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $3 AND version = $4
+		RETURNING version, updated_at`
+
+	err = tx.QueryRowContext(ctx, query, a.Week, a.Hours, a.InternalID, a.Version).Scan(&a.Version, &a.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "allocation_user_internal_id_project_internal_id_week_key"`:
+			return ErrDuplicateAllocation
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the allocation if its project is one the actor sees.
+func (m AllocationModel) Delete(actor Actor, id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	scope, scopeArgs := actor.projectScope(2)
+
+	query := `
+		DELETE FROM allocation a
+		USING project p
+		WHERE a.internal_id = $1 AND p.internal_id = a.project_internal_id` + scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, append([]any{id}, scopeArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// checkAllocation returns ErrOverAllocated when a, added to the user's other
+// allocations that week, comes to more than available. The user's row is
+// locked so concurrent allocations for them are checked one at a time.
+func checkAllocation(ctx context.Context, tx DBTX, a *Allocation, available float64) error {
+	query := `
+		SELECT 1
+		FROM appuser
+		WHERE internal_id = $1
+		FOR UPDATE`
+
+	var locked int
+
+	err := tx.QueryRowContext(ctx, query, a.UserID).Scan(&locked)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	query = `
+		SELECT COALESCE(sum(hours), 0)::float8
+		FROM allocation
+		WHERE user_internal_id = $1 AND week = $2 AND internal_id <> $3`
+
+	var planned float64
+
+	err = tx.QueryRowContext(ctx, query, a.UserID, a.Week, a.InternalID).Scan(&planned)
+	if err != nil {
+		return err
+	}
+
+	// Hours are stored to the hundredth, so allow for rounding.
+	if planned+a.Hours > available+0.005 {
+		return ErrOverAllocated
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// ApprovalStep is one level of an approval chain. Steps are worked through
+// in position order; a project without its own chain uses the default one.
+type ApprovalStep struct {
+	Position   int32  `json:"position"`
+	Name       string `json:"name"`
+	ApproverID int32  `json:"approver_id"`
+}
+
+func ValidateApprovalSteps(v *validator.Validator, steps []ApprovalStep) {
+	v.Check(len(steps) <= 10, "steps", "must not contain more than 10 steps")
+
+	for i, step := range steps {
+		key := fmt.Sprintf("steps[%d]", i)
+		v.Check(step.Name != "", key+".name", "must be provided")
+		v.Check(len(step.Name) <= 100, key+".name", "must not be more than 100 bytes long")
+		v.Check(step.ApproverID > 0, key+".approver_id", "must be provided")
+	}
+}
+
+// NextApprovalStep returns the step after the given position, or nil when
+// the chain is complete. Position 0 yields the first step.
+func NextApprovalStep(steps []ApprovalStep, position int32) *ApprovalStep {
+	for i := range steps {
+		if steps[i].Position > position {
+			return &steps[i]
+		}
+	}
+
+	return nil
+}
+
+type ApprovalStepModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// GetChain returns the approval chain for a project, falling back to the
+// default chain. A zero externalID returns the default chain.
+func (m ApprovalStepModel) GetChain(externalID int32) ([]ApprovalStep, error) {
+	query := `
+		SELECT s.position, s.name, s.approver_internal_id
+		FROM approval_step s
+		LEFT JOIN project p ON s.project_internal_id = p.internal_id
+		WHERE CASE
+			WHEN EXISTS (
+				SELECT 1 FROM approval_step ps
+				INNER JOIN project pp ON ps.project_internal_id = pp.internal_id
+				WHERE pp.project_id = $1
+			) THEN p.project_id = $1
+			ELSE s.project_internal_id IS NULL
+		END
+		ORDER BY s.position`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	steps := []ApprovalStep{}
+
+	for rows.Next() {
+		var step ApprovalStep
+		err := rows.Scan(&step.Position, &step.Name, &step.ApproverID)
+		if err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, step)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+// ReplaceChain stores steps as the chain of a project, or as the default
+// chain when projectInternalID is nil. Positions are assigned from the
+// order of steps. An empty chain removes a project override.
+func (m ApprovalStepModel) ReplaceChain(projectInternalID *int32, steps []ApprovalStep) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM approval_step WHERE project_internal_id IS NOT DISTINCT FROM $1`, projectInternalID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO approval_step (project_internal_id, position, name, approver_internal_id)
+		VALUES ($1, $2, $3, $4)`
+
+	for i := range steps {
+		steps[i].Position = int32(i + 1)
+
+		_, err = tx.ExecContext(ctx, query, projectInternalID, steps[i].Position, steps[i].Name, steps[i].ApproverID)
+		if err != nil {
+			switch {
+			case err.Error() == `pq: insert or update on table "approval_step" violates foreign key constraint "approval_step_approver_internal_id_fkey"`:
+				return ErrRecordNotFound
+			default:
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
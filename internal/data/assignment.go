@@ -0,0 +1,143 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Assignment puts a user on a project. Assigned users see the project, and
+// its entries when they may read project timesheets. Managers also receive
+// the project's health alerts. A user assigned with a role is granted its
+// permissions.
+type Assignment struct {
+	ProjectID int32   `json:"project_id"`
+	UserID    int32   `json:"user_id"`
+	Email     string  `json:"email"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Manager   bool    `json:"manager"`
+	Role      *string `json:"role"`
+}
+
+type AssignmentModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m AssignmentModel) GetAllForProject(externalID int32) ([]*Assignment, error) {
+	query := `
+		SELECT p.project_id, u.internal_id, u.email, u.first_name, u.last_name, pa.manager, pa.role
+		FROM project_appuser pa
+		INNER JOIN project p ON pa.project_internal_id = p.internal_id
+		INNER JOIN appuser u ON pa.appuser_internal_id = u.internal_id
+		WHERE p.project_id = $1
+		ORDER BY u.last_name, u.first_name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	assignments := []*Assignment{}
+
+	for rows.Next() {
+		var a Assignment
+		err := rows.Scan(&a.ProjectID, &a.UserID, &a.Email, &a.FirstName, &a.LastName, &a.Manager, &a.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// Insert assigns a user to a project, or updates whether they manage it when
+// they are assigned already, and reports whether the assignment is new. A
+// role, when given, replaces the one they hold on the project and grants
+// them its permissions; without one their role is kept.
+func (m AssignmentModel) Insert(externalID, userID int32, manager bool, role *string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO project_appuser (project_internal_id, appuser_internal_id, manager, role)
+		SELECT internal_id, $2, $3, $4
+		FROM project
+		WHERE project_id = $1 AND deleted_at IS NULL
+		ON CONFLICT (project_internal_id, appuser_internal_id)
+		DO UPDATE SET manager = EXCLUDED.manager, role = COALESCE(EXCLUDED.role, project_appuser.role)
+		RETURNING xmax = 0`
+
+	var created bool
+
+	err = tx.QueryRowContext(ctx, query, externalID, userID, manager, role).Scan(&created)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, ErrRecordNotFound
+		case err.Error() == `pq: insert or update on table "project_appuser" violates foreign key constraint "project_appuser_appuser_internal_id_fkey"`:
+			return false, ErrRecordNotFound
+		default:
+			return false, err
+		}
+	}
+
+	if role != nil {
+		err = grantRole(ctx, tx, userID, *role)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+func (m AssignmentModel) Delete(externalID, userID int32) error {
+	query := `
+		DELETE FROM project_appuser
+		WHERE project_internal_id = (SELECT internal_id FROM project WHERE project_id = $1)
+		AND appuser_internal_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, externalID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type AuditEntry struct {
+	InternalID int64           `json:"id"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	EntityID   string          `json:"entity_id"`
+	Detail     json.RawMessage `json:"detail"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m AuditModel) Insert(entry *AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return insertAudit(ctx, m.DB, entry)
+}
+
+// insertAudit records entry through db, which may be a transaction so the
+// entry commits together with the change it describes.
+func insertAudit(ctx context.Context, db DBTX, entry *AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (action, entity, entity_id, detail)
+		VALUES ($1, $2, $3, $4)
+		RETURNING internal_id, created_at`
+
+	if entry.Detail == nil {
+		entry.Detail = json.RawMessage(`{}`)
+	}
+
+	return db.QueryRowContext(ctx, query, entry.Action, entry.Entity, entry.EntityID, []byte(entry.Detail)).Scan(
+		&entry.InternalID,
+		&entry.CreatedAt,
+	)
+}
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// ProjectBudget is the time a project is expected to take and, optionally,
+// what it is expected to cost in the organization's base currency.
+type ProjectBudget struct {
+	ProjectID int32     `json:"project_id"`
+	Minutes   int32     `json:"minutes"`
+	Cost      *float64  `json:"cost"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ValidateProjectBudget(v *validator.Validator, budget *ProjectBudget) {
+	v.Check(budget.Minutes > 0, "minutes", "must be a positive integer")
+
+	if budget.Cost != nil {
+		v.Check(*budget.Cost >= 0, "cost", "must not be negative")
+		v.Check(*budget.Cost < 1e12, "cost", "must be less than 1 trillion")
+	}
+}
+
+type BudgetModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m BudgetModel) Get(externalID int32) (*ProjectBudget, error) {
+	query := `
+		SELECT p.project_id, b.minutes, b.cost::float8, b.updated_at
+		FROM project_budget b
+		INNER JOIN project p ON p.internal_id = b.project_internal_id
+		WHERE p.project_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var budget ProjectBudget
+
+	err := m.DB.QueryRowContext(ctx, query, externalID).Scan(&budget.ProjectID, &budget.Minutes, &budget.Cost, &budget.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &budget, nil
+}
+
+// Set creates or replaces the budget of the project budget.ProjectID.
+func (m BudgetModel) Set(budget *ProjectBudget) error {
+	query := `
+		INSERT INTO project_budget (project_internal_id, minutes, cost)
+		SELECT internal_id, $2, $3
+		FROM project
+		WHERE project_id = $1
+		ON CONFLICT (project_internal_id)
+		DO UPDATE SET minutes = EXCLUDED.minutes, cost = EXCLUDED.cost, updated_at = NOW()
+		RETURNING updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, budget.ProjectID, budget.Minutes, budget.Cost).Scan(&budget.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m BudgetModel) Delete(externalID int32) error {
+	query := `
+		DELETE FROM project_budget
+		WHERE project_internal_id = (SELECT internal_id FROM project WHERE project_id = $1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, externalID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -8,17 +8,53 @@ import (
 	"time"
 
 	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+// ErrClientCycle is returned when a client would become a division of
+// itself or of one of its own divisions.
+var ErrClientCycle = errors.New("client would be its own ancestor")
+
+// ErrClientInUse is returned when deleting a client that projects still
+// refer to in restrict mode.
+var ErrClientInUse = errors.New("client is linked to projects")
+
+// clientSubtree starts a query with the CTE subtree, holding the client $1
+// and, when $2 is true, every client below it.
+const clientSubtree = `
+	WITH RECURSIVE subtree AS (
+		SELECT internal_id
+		FROM client
+		WHERE internal_id = $1
+		UNION
+		SELECT c.internal_id
+		FROM client c
+		INNER JOIN subtree s ON c.parent_internal_id = s.internal_id
+		WHERE $2
+	)`
+
+// How Delete treats the projects a client is linked to: refuse to delete
+// it, unlink them, or link them to another client instead.
+const (
+	ClientDeleteRestrict = "restrict"
+	ClientDeleteDetach   = "detach"
+	ClientDeleteReassign = "reassign"
 )
 
 type Client struct {
-	InternalID int32     `json:"id"`
-	Name       *string   `json:"name"`
-	Address    *string   `json:"address"`
-	LogoURL    *string   `json:"logo_url"`
-	Note       *string   `json:"note"`
-	Version    int32     `json:"version"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	InternalID   int32        `json:"id"`
+	Name         *string      `json:"name"`
+	Address      *string      `json:"address"`
+	LogoURL      *string      `json:"logo_url"`
+	Note         *string      `json:"note"`
+	CustomFields CustomValues `json:"custom_fields"`
+	Longitude    *float64     `json:"longitude" doc:"Set from the address when client geocoding is enabled, or given explicitly."`
+	Latitude     *float64     `json:"latitude"`
+	ParentID     *int32       `json:"parent_id" doc:"The client this one is a division of."`
+	OrgID        int32        `json:"-"`
+	Version      int32        `json:"version"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
 func ValidateClient(v *validator.Validator, client *Client) {
@@ -34,21 +70,47 @@ func ValidateClient(v *validator.Validator, client *Client) {
 		v.Check(*client.Note != "", "contact_info", "must not be empty string")
 		v.Check(len(*client.Note) <= 500, "contact_info", "must not be more than 500 bytes long")
 	}
+
+	v.Check((client.Longitude == nil) == (client.Latitude == nil), "longitude", "must be given together with latitude")
+
+	if client.Longitude != nil {
+		v.Check(*client.Longitude >= -180 && *client.Longitude <= 180, "longitude", "must be between -180 and 180")
+	}
+
+	if client.Latitude != nil {
+		v.Check(*client.Latitude >= -90 && *client.Latitude <= 90, "latitude", "must be between -90 and 90")
+	}
+
+	if client.ParentID != nil {
+		v.Check(*client.ParentID != client.InternalID, "parent_id", "must not be the client itself")
+	}
+}
+
+// ClientFilter narrows client lists. Zero values match everything.
+type ClientFilter struct {
+	Name         string
+	CustomFields CustomValues
+	Bbox         BoundingBox
+	// Near and Radius keep clients within Radius metres of Near.
+	Near   *Point
+	Radius float64
 }
 
 type ClientModel struct {
-	DB *sql.DB
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
 }
 
 func (m ClientModel) Insert(client *Client) error {
 	query := `
-		INSERT INTO client (name, address, logo_url, note)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO client (name, address, logo_url, note, custom_fields, longitude, latitude, parent_internal_id, org_internal_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING internal_id, version, created_at, updated_at`
 
-	args := []any{client.Name, client.Address, client.LogoURL, client.Note}
+	args := []any{client.Name, client.Address, client.LogoURL, client.Note, client.CustomFields, client.Longitude, client.Latitude, client.ParentID, client.OrgID}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
 	return m.DB.QueryRowContext(ctx, query, args...).Scan(
@@ -59,26 +121,36 @@ func (m ClientModel) Insert(client *Client) error {
 	)
 }
 
-func (m ClientModel) Get(internal_id int32) (*Client, error) {
+// Get returns the client if it belongs to the actor's organization.
+func (m ClientModel) Get(actor Actor, internal_id int32) (*Client, error) {
 	if internal_id < 1 {
 		return nil, ErrRecordNotFound
 	}
 
 	query := `
-		SELECT internal_id, name, address, logo_url, note, version, created_at, updated_at
+		SELECT internal_id, name, address, logo_url, note, custom_fields, longitude, latitude, parent_internal_id, org_internal_id, version, created_at, updated_at
 		FROM client
 		WHERE internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
 	var client Client
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, internal_id).Scan(
+	err := m.DB.QueryRowContext(ctx, query, append([]any{internal_id}, scopeArgs...)...).Scan(
 		&client.InternalID,
 		&client.Name,
 		&client.Address,
 		&client.LogoURL,
 		&client.Note,
+		&client.CustomFields,
+		&client.Longitude,
+		&client.Latitude,
+		&client.ParentID,
+		&client.OrgID,
 		&client.Version,
 		&client.CreatedAt,
 		&client.UpdatedAt,
@@ -96,27 +168,56 @@ func (m ClientModel) Get(internal_id int32) (*Client, error) {
 	return &client, nil
 }
 
-func (m ClientModel) GetAll(name string, filters Filters) ([]*Client, Metadata, error) {
-	query := fmt.Sprintf(`
-		SELECT count(*) OVER(), internal_id, name, address, logo_url, note, version, created_at, updated_at
+// GetAll lists the clients of the actor's organization matching filter.
+func (m ClientModel) GetAll(actor Actor, filter ClientFilter, filters Filters) ([]*Client, Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), internal_id, name, address, logo_url, note, custom_fields, longitude, latitude, parent_internal_id, version, created_at, updated_at
 		FROM client
 		WHERE ( to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) OR $1 = '')
-		ORDER BY %s %s, internal_id ASC`, filters.sortColumn(), filters.sortDirection())
+		AND custom_fields @> $2::jsonb
+		AND ($3::boolean IS NOT TRUE OR (longitude BETWEEN $4 AND $6 AND latitude BETWEEN $5 AND $7))
+		AND (
+			$8::float8 IS NULL
+			OR ST_DWithin(
+				ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography,
+				ST_SetSRID(ST_MakePoint($8, $9), 4326)::geography,
+				$10
+			)
+		)`
+
+	var nearLongitude, nearLatitude *float64
+	if filter.Near != nil {
+		nearLongitude, nearLatitude = &filter.Near.Longitude, &filter.Near.Latitude
+	}
 
 	args := []any{
-		name,
+		filter.Name,
+		filter.CustomFields,
+		filter.Bbox.Valid,
+		filter.Bbox.BottomLeft[0],
+		filter.Bbox.BottomLeft[1],
+		filter.Bbox.TopRight[0],
+		filter.Bbox.TopRight[1],
+		nearLongitude,
+		nearLatitude,
+		filter.Radius,
 	}
 
+	scope, scopeArgs := actor.orgScope("org_internal_id", len(args)+1)
+	query += scope + fmt.Sprintf(`
+		ORDER BY %s, internal_id ASC`, filters.orderBy())
+	args = append(args, scopeArgs...)
+
 	if filters.limit() > 0 {
-		query += `
-		LIMIT $2 OFFSET $3`
+		query += fmt.Sprintf(`
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
 		args = append(args, filters.limit(), filters.offset())
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, args...)
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, Metadata{}, err
 	}
@@ -135,6 +236,10 @@ func (m ClientModel) GetAll(name string, filters Filters) ([]*Client, Metadata,
 			&client.Address,
 			&client.LogoURL,
 			&client.Note,
+			&client.CustomFields,
+			&client.Longitude,
+			&client.Latitude,
+			&client.ParentID,
 			&client.Version,
 			&client.CreatedAt,
 			&client.UpdatedAt,
@@ -155,27 +260,36 @@ func (m ClientModel) GetAll(name string, filters Filters) ([]*Client, Metadata,
 	return clients, metadata, nil
 }
 
-func (m ClientModel) GetClientByName(name string) (*Client, error) {
+// GetClientByName returns the client of the actor's organization with the
+// name.
+func (m ClientModel) GetClientByName(actor Actor, name string) (*Client, error) {
 	if name == "" {
 		return nil, ErrRecordNotFound
 	}
 
 	query := `
-		SELECT internal_id, name, address, logo_url, note, version, created_at, updated_at
+		SELECT internal_id, name, address, logo_url, note, custom_fields, longitude, latitude, parent_internal_id, version, created_at, updated_at
 		FROM client
 		WHERE name = $1`
 
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
 	var client Client
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, name).Scan(
+	err := m.DB.QueryRowContext(ctx, query, append([]any{name}, scopeArgs...)...).Scan(
 		&client.InternalID,
 		&client.Name,
 		&client.Address,
 		&client.LogoURL,
 		&client.Note,
+		&client.CustomFields,
+		&client.Longitude,
+		&client.Latitude,
+		&client.ParentID,
 		&client.Version,
 		&client.CreatedAt,
 		&client.UpdatedAt,
@@ -193,52 +307,372 @@ func (m ClientModel) GetClientByName(name string) (*Client, error) {
 	return &client, nil
 }
 
+// GetAllByNames returns the clients of the actor's organization matching
+// the given names, keyed by name.
+func (m ClientModel) GetAllByNames(actor Actor, names []string) (map[string]*Client, error) {
+	query := `
+		SELECT internal_id, name, address, logo_url, note, custom_fields, longitude, latitude, parent_internal_id, version, created_at, updated_at
+		FROM client
+		WHERE name = ANY($1::text[])`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{pq.Array(names)}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	clients := make(map[string]*Client)
+
+	for rows.Next() {
+		var client Client
+		err := rows.Scan(
+			&client.InternalID,
+			&client.Name,
+			&client.Address,
+			&client.LogoURL,
+			&client.Note,
+			&client.CustomFields,
+			&client.Longitude,
+			&client.Latitude,
+			&client.ParentID,
+			&client.Version,
+			&client.CreatedAt,
+			&client.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		clients[*client.Name] = &client
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// GetSubtree returns a client followed by its divisions, theirs and so on,
+// each level ordered by name. Update keeps the hierarchy free of cycles, so
+// the walk ends.
+func (m ClientModel) GetSubtree(internal_id int32) ([]*Client, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT internal_id, 0 AS depth
+			FROM client
+			WHERE internal_id = $1
+			UNION ALL
+			SELECT c.internal_id, s.depth + 1
+			FROM client c
+			INNER JOIN subtree s ON c.parent_internal_id = s.internal_id
+		)
+		SELECT c.internal_id, c.name, c.address, c.logo_url, c.note, c.custom_fields, c.longitude, c.latitude, c.parent_internal_id,
+			c.version, c.created_at, c.updated_at
+		FROM subtree s
+		INNER JOIN client c ON c.internal_id = s.internal_id
+		ORDER BY s.depth, c.name, c.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, internal_id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	clients := []*Client{}
+
+	for rows.Next() {
+		var client Client
+		err := rows.Scan(
+			&client.InternalID,
+			&client.Name,
+			&client.Address,
+			&client.LogoURL,
+			&client.Note,
+			&client.CustomFields,
+			&client.Longitude,
+			&client.Latitude,
+			&client.ParentID,
+			&client.Version,
+			&client.CreatedAt,
+			&client.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, &client)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(clients) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	return clients, nil
+}
+
+// ClientSummary totals the work done for a client across the projects
+// linked to it, and to its divisions when IncludesDivisions is set. Projects
+// linked to several of them are counted once. There are no bill rates, so time billed is valued at each
+// user's hourly cost; users without one add nothing to BilledAmount.
+type ClientSummary struct {
+	ClientID          int32          `json:"client_id"`
+	ProjectsByStatus  map[string]int `json:"projects_by_status" doc:"Projects that are not deleted, counted by status. Projects without a status are counted under an empty string." example:"{\"In Progress\": 3, \"Pending\": 1}"`
+	ApprovedHours     float64        `json:"approved_hours" doc:"Hours logged in approved timesheet entries."`
+	BilledAmount      float64        `json:"billed_amount" doc:"Billed minutes of approved entries at each user's hourly cost."`
+	IncludesDivisions bool           `json:"includes_divisions"`
+	LastActivityOn    *time.Time     `json:"last_activity_on" doc:"The later of the last day time was logged and the last day a project was updated, or null when neither happened."`
+}
+
+// GetSummary returns the summary of a client's projects and the time logged
+// on them, rolling up those of its divisions when divisions is true.
+func (m ClientModel) GetSummary(internal_id int32, divisions bool) (*ClientSummary, error) {
+	projects := clientSubtree + `, projects AS (
+		SELECT DISTINCT p.internal_id, p.status, p.updated_at
+		FROM project_client pc
+		INNER JOIN project p ON p.internal_id = pc.project_internal_id
+		WHERE pc.client_internal_id IN (SELECT internal_id FROM subtree) AND p.deleted_at IS NULL
+	)`
+
+	query := projects + `
+		SELECT COALESCE(status, ''), count(*)
+		FROM projects
+		GROUP BY 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	db := readDB(m.ReadDB, m.DB)
+
+	rows, err := db.QueryContext(ctx, query, internal_id, divisions)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	summary := ClientSummary{
+		ClientID:          internal_id,
+		ProjectsByStatus:  map[string]int{},
+		IncludesDivisions: divisions,
+	}
+
+	for rows.Next() {
+		var status string
+		var count int
+
+		err := rows.Scan(&status, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.ProjectsByStatus[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = projects + `
+		SELECT
+			COALESCE(sum(t.minutes) FILTER (WHERE t.status = 'approved'), 0)::float8 / 60,
+			COALESCE(sum(COALESCE(t.billed_minutes, t.minutes) * u.hourly_cost / 60) FILTER (WHERE t.status = 'approved'), 0)::float8,
+			GREATEST(max(t.work_date), (SELECT max(updated_at)::date FROM projects))
+		FROM projects p
+		LEFT JOIN timesheet_entry t ON t.project_internal_id = p.internal_id
+		LEFT JOIN appuser u ON u.internal_id = t.user_internal_id`
+
+	err = db.QueryRowContext(ctx, query, internal_id, divisions).Scan(
+		&summary.ApprovedHours,
+		&summary.BilledAmount,
+		&summary.LastActivityOn,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// Update saves c provided it is still at c.Version, returning
+// ErrEditConflict when another update got there first, and ErrClientCycle
+// when c.ParentID is c or one of its divisions.
 func (cm ClientModel) Update(c *Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(cm.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, cm.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if c.ParentID != nil {
+		// Parents are changed one at a time, so two changes cannot make a
+		// cycle that neither sees.
+		_, err = tx.ExecContext(ctx, `LOCK TABLE client IN SHARE ROW EXCLUSIVE MODE`)
+		if err != nil {
+			return err
+		}
+
+		query := clientSubtree + `
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE internal_id = $3)`
+
+		var cycle bool
+
+		err = tx.QueryRowContext(ctx, query, c.InternalID, true, *c.ParentID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+
+		if cycle {
+			return ErrClientCycle
+		}
+	}
+
 	query := `
 		UPDATE client
-		SET name = $1, address = $2, logo_url = $3, note = $4
-		WHERE internal_id = $5
-		RETURNING version`
+		SET name = $1, address = $2, logo_url = $3, note = $4, custom_fields = $5, longitude = $6, latitude = $7,
+			parent_internal_id = $8, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $9 AND version = $10
+		RETURNING version, updated_at`
 
 	args := []any{
 		c.Name,
 		c.Address,
 		c.LogoURL,
 		c.Note,
+		c.CustomFields,
+		c.Longitude,
+		c.Latitude,
+		c.ParentID,
 		c.InternalID,
+		c.Version,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&c.Version, &c.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
 
-	return cm.DB.QueryRowContext(ctx, query, args...).Scan(&c.Version)
+	return tx.Commit()
 }
 
-func (cm ClientModel) Delete(internal_id int32) error {
+// Delete deletes a client, treating the projects linked to it as mode
+// says, and returns the project_id of each. In reassign mode the projects
+// are linked to the client reassignTo instead. In restrict mode nothing is
+// deleted when there are any, and ErrClientInUse is returned with them.
+func (cm ClientModel) Delete(internal_id int32, mode string, reassignTo int32) ([]int32, error) {
 	if internal_id < 1 {
-		return ErrRecordNotFound
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(cm.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, cm.DB)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
+	// Locking the client keeps projects from being linked to it meanwhile.
 	query := `
-		DELETE FROM client
-		WHERE internal_id = $1`
+		SELECT 1
+		FROM client
+		WHERE internal_id = $1
+		FOR UPDATE`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	var locked int
 
-	result, err := cm.DB.ExecContext(ctx, query, internal_id)
+	err = tx.QueryRowContext(ctx, query, internal_id).Scan(&locked)
 	if err != nil {
-		return err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	query = `
+		SELECT p.project_id
+		FROM project_client pc
+		JOIN project p ON p.internal_id = pc.project_internal_id
+		WHERE pc.client_internal_id = $1
+		ORDER BY p.project_id`
+
+	rows, err := tx.QueryContext(ctx, query, internal_id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	projectIDs := []int32{}
+
+	for rows.Next() {
+		var projectID int32
+
+		err := rows.Scan(&projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
+	if mode == ClientDeleteRestrict && len(projectIDs) > 0 {
+		return projectIDs, ErrClientInUse
+	}
+
+	if mode == ClientDeleteReassign {
+		query = `
+			INSERT INTO project_client (project_internal_id, client_internal_id)
+			SELECT project_internal_id, $2
+			FROM project_client
+			WHERE client_internal_id = $1
+			ON CONFLICT DO NOTHING`
+
+		_, err = tx.ExecContext(ctx, query, internal_id, reassignTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The remaining links go with the client.
+	query = `
+		DELETE FROM client
+		WHERE internal_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, internal_id)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return projectIDs, tx.Commit()
 }
@@ -0,0 +1,159 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/codefmt"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// CodePolicy sets the formats an organization's new project and proposal
+// codes must follow, such as YYNNN or P-YYYY-NNN. An empty format allows any
+// code. A policy never saved has version 0 and no updated_at.
+type CodePolicy struct {
+	OrgID          int32      `json:"organization_id"`
+	ProjectFormat  string     `json:"project_format"`
+	ProposalFormat string     `json:"proposal_format"`
+	Version        int32      `json:"version"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+}
+
+func ValidateCodePolicy(v *validator.Validator, p *CodePolicy) {
+	if p.ProjectFormat != "" {
+		f, err := codefmt.Parse(p.ProjectFormat)
+		switch {
+		case err != nil:
+			v.AddError("project_format", err.Error())
+		case !f.Numeric():
+			v.AddError("project_format", "must only contain digits, YY, YYYY and N, as project codes are numbers")
+		case f.LeadingZero():
+			v.AddError("project_format", "must not start with N or 0, as project codes are numbers")
+		case f.Len() > 9:
+			v.AddError("project_format", "must not make codes longer than 9 digits")
+		}
+	}
+
+	if p.ProposalFormat != "" {
+		f, err := codefmt.Parse(p.ProposalFormat)
+		switch {
+		case err != nil:
+			v.AddError("proposal_format", err.Error())
+		case f.Len() > 10:
+			v.AddError("proposal_format", "must not make codes longer than 10 bytes")
+		}
+	}
+}
+
+type CodePolicyModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Get returns an organization's policy, empty when it has saved none.
+func (m CodePolicyModel) Get(orgID int32) (*CodePolicy, error) {
+	query := `
+		SELECT project_format, proposal_format, version, updated_at
+		FROM code_policy
+		WHERE org_internal_id = $1`
+
+	p := CodePolicy{OrgID: orgID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, orgID).Scan(
+		&p.ProjectFormat,
+		&p.ProposalFormat,
+		&p.Version,
+		&p.UpdatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Update saves an organization's policy, creating it when p.Version is 0,
+// provided it is still at p.Version.
+func (m CodePolicyModel) Update(p *CodePolicy) error {
+	query := `
+		INSERT INTO code_policy (org_internal_id, project_format, proposal_format)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (org_internal_id) DO UPDATE
+		SET project_format = EXCLUDED.project_format, proposal_format = EXCLUDED.proposal_format,
+			version = code_policy.version + 1, updated_at = NOW()
+		WHERE code_policy.version = $4
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, p.OrgID, p.ProjectFormat, p.ProposalFormat, p.Version).Scan(&p.Version, &p.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetProjectCodes returns every project code in use or reserved, deleted
+// projects included, as they keep theirs.
+func (m CodePolicyModel) GetProjectCodes() ([]string, error) {
+	return m.getCodes(`
+		SELECT project_id::text FROM project
+		UNION
+		SELECT project_id::text FROM project_id_reservation WHERE expires_at > NOW()`)
+}
+
+// GetProposalCodes returns every proposal code in use, by proposals or by
+// projects.
+func (m CodePolicyModel) GetProposalCodes() ([]string, error) {
+	return m.getCodes(`
+		SELECT project_id FROM proposal
+		UNION
+		SELECT proposal_id FROM project`)
+}
+
+func (m CodePolicyModel) getCodes(query string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return queryCodes(ctx, m.DB, query)
+}
+
+func queryCodes(ctx context.Context, db DBTX, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	codes := []string{}
+
+	for rows.Next() {
+		var code string
+
+		err := rows.Scan(&code)
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
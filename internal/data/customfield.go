@@ -0,0 +1,347 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+var ErrDuplicateCustomField = errors.New("duplicate custom field")
+
+var (
+	CustomFieldEntities = []string{"project", "client"}
+	CustomFieldTypes    = []string{"text", "number", "boolean", "date"}
+)
+
+var customFieldNameRX = regexp.MustCompile("^[a-z][a-z0-9_]*$")
+
+// CustomField defines an extra attribute that projects or clients can carry
+// in their custom_fields column.
+type CustomField struct {
+	InternalID int32     `json:"id"`
+	Entity     string    `json:"entity"`
+	Name       string    `json:"name"`
+	DataType   string    `json:"data_type"`
+	Required   bool      `json:"required"`
+	OrgID      int32     `json:"-"`
+	Version    int32     `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func ValidateCustomField(v *validator.Validator, field *CustomField) {
+	v.Check(validator.PermittedValue(field.Entity, CustomFieldEntities...), "entity", "must be one of project or client")
+	v.Check(field.Name != "", "name", "must be provided")
+	v.Check(len(field.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(validator.Matches(field.Name, customFieldNameRX), "name", "must be lowercase letters, digits and underscores, starting with a letter")
+	v.Check(validator.PermittedValue(field.DataType, CustomFieldTypes...), "data_type", "must be one of text, number, boolean or date")
+}
+
+// CustomValues holds a record's custom field values keyed by field name. It
+// is stored as a JSONB object.
+type CustomValues map[string]any
+
+func (c CustomValues) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(c)
+}
+
+func (c *CustomValues) Scan(src any) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into CustomValues", src)
+	}
+
+	return json.Unmarshal(b, c)
+}
+
+// Merge applies changes on top of c and returns the result. A null change
+// clears the field.
+func (c CustomValues) Merge(changes CustomValues) CustomValues {
+	merged := make(CustomValues, len(c)+len(changes))
+	for name, value := range c {
+		merged[name] = value
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+		merged[name] = value
+	}
+
+	return merged
+}
+
+// ValidateCustomValues checks values against the field definitions of an
+// entity: every value must belong to a defined field and match its data type,
+// and required fields must be present.
+func ValidateCustomValues(v *validator.Validator, fields []*CustomField, values CustomValues) {
+	defined := make(map[string]*CustomField, len(fields))
+	for _, field := range fields {
+		defined[field.Name] = field
+
+		if field.Required {
+			v.Check(values[field.Name] != nil, "custom_fields."+field.Name, "must be provided")
+		}
+	}
+
+	for name, value := range values {
+		key := "custom_fields." + name
+
+		field, ok := defined[name]
+		if !ok {
+			v.AddError(key, "is not a defined custom field")
+			continue
+		}
+
+		if value == nil {
+			continue
+		}
+
+		switch field.DataType {
+		case "text":
+			_, ok = value.(string)
+			v.Check(ok, key, "must be a string")
+		case "number":
+			_, ok = value.(float64)
+			v.Check(ok, key, "must be a number")
+		case "boolean":
+			_, ok = value.(bool)
+			v.Check(ok, key, "must be a boolean")
+		case "date":
+			s, _ := value.(string)
+			_, err := time.Parse(time.DateOnly, s)
+			v.Check(err == nil, key, "must be a date in YYYY-MM-DD format")
+		}
+	}
+}
+
+type CustomFieldModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m CustomFieldModel) Insert(field *CustomField) error {
+	query := `
+		INSERT INTO custom_field (entity, name, data_type, required, org_internal_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING internal_id, version, created_at, updated_at`
+
+	args := []any{field.Entity, field.Name, field.DataType, field.Required, field.OrgID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&field.InternalID,
+		&field.Version,
+		&field.CreatedAt,
+		&field.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "custom_field_org_internal_id_entity_name_key"`:
+			return ErrDuplicateCustomField
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the field definition if it belongs to the actor's
+// organization.
+func (m CustomFieldModel) Get(actor Actor, id int32) (*CustomField, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT internal_id, entity, name, data_type, required, org_internal_id, version, created_at, updated_at
+		FROM custom_field
+		WHERE internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	var field CustomField
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&field.InternalID,
+		&field.Entity,
+		&field.Name,
+		&field.DataType,
+		&field.Required,
+		&field.OrgID,
+		&field.Version,
+		&field.CreatedAt,
+		&field.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &field, nil
+}
+
+// GetAll returns the actor's organization's field definitions of an entity,
+// or of every entity when entity is empty.
+func (m CustomFieldModel) GetAll(actor Actor, entity string) ([]*CustomField, error) {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+
+	query := `
+		SELECT internal_id, entity, name, data_type, required, version, created_at, updated_at
+		FROM custom_field
+		WHERE (entity = $1 OR $1 = '')` + scope + `
+		ORDER BY entity, name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{entity}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	fields := []*CustomField{}
+
+	for rows.Next() {
+		var field CustomField
+		err := rows.Scan(
+			&field.InternalID,
+			&field.Entity,
+			&field.Name,
+			&field.DataType,
+			&field.Required,
+			&field.Version,
+			&field.CreatedAt,
+			&field.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, &field)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// Update saves a field definition. When the name changes, the values stored
+// by records of the field's organization are moved to the new name in the
+// same transaction.
+func (m CustomFieldModel) Update(field *CustomField, oldName string) error {
+	query := `
+		UPDATE custom_field
+		SET name = $1, required = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $3 AND version = $4
+		RETURNING version, updated_at`
+
+	args := []any{field.Name, field.Required, field.InternalID, field.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&field.Version, &field.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "custom_field_org_internal_id_entity_name_key"`:
+			return ErrDuplicateCustomField
+		default:
+			return err
+		}
+	}
+
+	if oldName != field.Name {
+		// The entity is one of CustomFieldEntities, so it is safe to use as
+		// a table name.
+		query = fmt.Sprintf(`
+			UPDATE %s
+			SET custom_fields = (custom_fields - $1::text) || jsonb_build_object($2::text, custom_fields->$1::text)
+			WHERE custom_fields ? $1::text AND org_internal_id = $3`, field.Entity)
+
+		_, err = tx.ExecContext(ctx, query, oldName, field.Name, field.OrgID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a field definition together with the values stored for it.
+func (m CustomFieldModel) Delete(field *CustomField) error {
+	query := `
+		DELETE FROM custom_field
+		WHERE internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, field.InternalID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	query = fmt.Sprintf(`
+		UPDATE %s
+		SET custom_fields = custom_fields - $1::text
+		WHERE custom_fields ? $1::text AND org_internal_id = $2`, field.Entity)
+
+	_, err = tx.ExecContext(ctx, query, field.Name, field.OrgID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
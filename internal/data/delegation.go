@@ -0,0 +1,245 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// Delegation lets a delegate act on a delegator's approvals between two
+// dates, inclusive.
+type Delegation struct {
+	InternalID  int32     `json:"id"`
+	DelegatorID int32     `json:"delegator_id"`
+	DelegateID  int32     `json:"delegate_id"`
+	StartsOn    time.Time `json:"starts_on"`
+	EndsOn      time.Time `json:"ends_on"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ValidateDelegation(v *validator.Validator, d *Delegation) {
+	v.Check(d.DelegatorID > 0, "delegator_id", "must be provided")
+	v.Check(d.DelegateID > 0, "delegate_id", "must be provided")
+	v.Check(d.DelegatorID != d.DelegateID, "delegate_id", "must be a different user than the delegator")
+	v.Check(!d.StartsOn.IsZero(), "starts_on", "must be provided")
+	v.Check(!d.EndsOn.IsZero(), "ends_on", "must be provided")
+	v.Check(!d.EndsOn.Before(d.StartsOn), "ends_on", "must not be before starts_on")
+}
+
+type DelegationModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Insert stores the delegation when both users belong to the actor's
+// organization, and returns ErrRecordNotFound otherwise.
+func (m DelegationModel) Insert(actor Actor, d *Delegation) error {
+	scope, scopeArgs := actor.orgScope("u.org_internal_id", 5)
+
+	query := `
+		INSERT INTO approval_delegation (delegator_internal_id, delegate_internal_id, starts_on, ends_on)
+		SELECT $1, $2, $3, $4
+		WHERE EXISTS (SELECT 1 FROM appuser u WHERE u.internal_id = $1` + scope + `)
+		AND EXISTS (SELECT 1 FROM appuser u WHERE u.internal_id = $2` + scope + `)
+		RETURNING internal_id, created_at`
+
+	args := append([]any{d.DelegatorID, d.DelegateID, d.StartsOn, d.EndsOn}, scopeArgs...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&d.InternalID, &d.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the delegation if its delegator belongs to the actor's
+// organization.
+func (m DelegationModel) Get(actor Actor, id int32) (*Delegation, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT d.internal_id, d.delegator_internal_id, d.delegate_internal_id, d.starts_on, d.ends_on, d.created_at
+		FROM approval_delegation d
+		INNER JOIN appuser u ON u.internal_id = d.delegator_internal_id
+		WHERE d.internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("u.org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var d Delegation
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&d.InternalID,
+		&d.DelegatorID,
+		&d.DelegateID,
+		&d.StartsOn,
+		&d.EndsOn,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &d, nil
+}
+
+// GetAll lists the delegations given or received by a user of the actor's
+// organization.
+func (m DelegationModel) GetAll(actor Actor, userID int32) ([]*Delegation, error) {
+	query := `
+		SELECT d.internal_id, d.delegator_internal_id, d.delegate_internal_id, d.starts_on, d.ends_on, d.created_at
+		FROM approval_delegation d
+		INNER JOIN appuser u ON u.internal_id = d.delegator_internal_id
+		WHERE (d.delegator_internal_id = $1 OR d.delegate_internal_id = $1)`
+
+	scope, scopeArgs := actor.orgScope("u.org_internal_id", 2)
+	query += scope + `
+		ORDER BY d.starts_on DESC, d.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{userID}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	delegations := []*Delegation{}
+
+	for rows.Next() {
+		var d Delegation
+		err := rows.Scan(
+			&d.InternalID,
+			&d.DelegatorID,
+			&d.DelegateID,
+			&d.StartsOn,
+			&d.EndsOn,
+			&d.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		delegations = append(delegations, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return delegations, nil
+}
+
+// GetActiveDelegates returns the users currently acting for an approver on
+// the given day. Permission checks and notification routing consult this.
+func (m DelegationModel) GetActiveDelegates(delegatorID int32, day time.Time) ([]int32, error) {
+	query := `
+		SELECT DISTINCT delegate_internal_id
+		FROM approval_delegation
+		WHERE delegator_internal_id = $1 AND $2::date BETWEEN starts_on AND ends_on`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, delegatorID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	delegates := []int32{}
+
+	for rows.Next() {
+		var id int32
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		delegates = append(delegates, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return delegates, nil
+}
+
+// CanActFor reports whether userID may approve on behalf of approverID on
+// the given day, either as the approver or through an active delegation.
+func (m DelegationModel) CanActFor(userID, approverID int32, day time.Time) (bool, error) {
+	if userID == approverID {
+		return true, nil
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM approval_delegation
+			WHERE delegator_internal_id = $1 AND delegate_internal_id = $2
+			AND $3::date BETWEEN starts_on AND ends_on
+		)`
+
+	var ok bool
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, approverID, userID, day).Scan(&ok)
+	return ok, err
+}
+
+func (m DelegationModel) Delete(id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM approval_delegation
+		WHERE internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
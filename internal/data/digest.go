@@ -0,0 +1,271 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+var DigestFrequencies = []string{DigestOff, DigestDaily, DigestWeekly}
+
+// DigestPreference is how often a user wants the summary email. Weekly
+// digests go out on Weekday, with Sunday as 0.
+type DigestPreference struct {
+	Frequency string    `json:"frequency"`
+	Weekday   int       `json:"weekday"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ValidateDigestPreference(v *validator.Validator, p *DigestPreference) {
+	v.Check(validator.PermittedValue(p.Frequency, DigestFrequencies...), "frequency", "must be one of off, daily or weekly")
+	v.Check(p.Weekday >= 0 && p.Weekday <= 6, "weekday", "must be between 0 (Sunday) and 6 (Saturday)")
+}
+
+// DigestRecipient is a user whose digest is due.
+type DigestRecipient struct {
+	UserID     int32
+	Email      string
+	FirstName  string
+	Frequency  string
+	LastSentAt *time.Time
+}
+
+// Digest summarizes what needs a manager's attention.
+type Digest struct {
+	// PendingApprovals counts the submitted entries waiting on the user.
+	PendingApprovals int
+	PendingMinutes   int64
+	// Projects lists the time logged on the user's projects since the
+	// previous digest.
+	Projects []DigestProject
+	// BudgetWarnings lists the user's projects that have used up most of
+	// their budget.
+	BudgetWarnings []DigestBudgetWarning
+}
+
+type DigestProject struct {
+	ProjectID int32
+	Name      string
+	Minutes   int64
+}
+
+type DigestBudgetWarning struct {
+	ProjectID     int32
+	Name          string
+	BudgetMinutes int64
+	LoggedMinutes int64
+}
+
+// Empty reports whether the digest has nothing worth sending.
+func (d *Digest) Empty() bool {
+	return d.PendingApprovals == 0 && len(d.Projects) == 0 && len(d.BudgetWarnings) == 0
+}
+
+type DigestModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// GetPreference returns the user's preference, reported as off when the user
+// has never set one.
+func (m DigestModel) GetPreference(userID int32) (*DigestPreference, error) {
+	query := `
+		SELECT COALESCE(d.frequency, 'off'), COALESCE(d.weekday, 1), COALESCE(d.updated_at, NOW())
+		FROM (SELECT $1::integer AS user_id) u
+		LEFT JOIN digest_preference d ON d.appuser_internal_id = u.user_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var p DigestPreference
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&p.Frequency, &p.Weekday, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (m DigestModel) SetPreference(userID int32, p *DigestPreference) error {
+	query := `
+		INSERT INTO digest_preference (appuser_internal_id, frequency, weekday)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (appuser_internal_id)
+		DO UPDATE SET frequency = EXCLUDED.frequency, weekday = EXCLUDED.weekday, updated_at = NOW()
+		RETURNING updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, userID, p.Frequency, p.Weekday).Scan(&p.UpdatedAt)
+}
+
+// GetDue returns the activated users whose digest should go out at now. A
+// daily digest is due once 23 hours have passed since the last one, which
+// leaves room for the job running a little early or late.
+func (m DigestModel) GetDue(now time.Time) ([]*DigestRecipient, error) {
+	query := `
+		SELECT u.internal_id, u.email, u.first_name, d.frequency, d.last_sent_at
+		FROM digest_preference d
+		INNER JOIN appuser u ON u.internal_id = d.appuser_internal_id
+		WHERE u.activated
+		AND (
+			(
+				d.frequency = 'daily'
+				AND (d.last_sent_at IS NULL OR d.last_sent_at <= $1::timestamptz - interval '23 hours')
+			)
+			OR (
+				d.frequency = 'weekly'
+				AND d.weekday = EXTRACT(DOW FROM $1::timestamptz)
+				AND (d.last_sent_at IS NULL OR d.last_sent_at <= $1::timestamptz - interval '6 days')
+			)
+		)
+		ORDER BY u.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	recipients := []*DigestRecipient{}
+
+	for rows.Next() {
+		var r DigestRecipient
+
+		err := rows.Scan(&r.UserID, &r.Email, &r.FirstName, &r.Frequency, &r.LastSentAt)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients = append(recipients, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+// Build gathers the digest for a user: entries awaiting their approval, time
+// logged on their projects since since, and their projects whose logged time
+// has reached warnRatio of the budget.
+func (m DigestModel) Build(userID int32, since time.Time, warnRatio float64) (*Digest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	db := readDB(m.ReadDB, m.DB)
+
+	digest := &Digest{
+		Projects:       []DigestProject{},
+		BudgetWarnings: []DigestBudgetWarning{},
+	}
+
+	query := `
+		SELECT count(*), COALESCE(sum(minutes), 0)
+		FROM timesheet_entry
+		WHERE approver_internal_id = $1 AND status = 'submitted' AND deleted_at IS NULL`
+
+	err := db.QueryRowContext(ctx, query, userID).Scan(&digest.PendingApprovals, &digest.PendingMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT p.project_id, COALESCE(p.name, ''), sum(t.minutes)
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		INNER JOIN project_appuser pa ON pa.project_internal_id = p.internal_id
+		WHERE pa.appuser_internal_id = $1
+		AND t.created_at >= $2
+		AND t.deleted_at IS NULL
+		AND p.deleted_at IS NULL
+		GROUP BY p.project_id, p.name
+		ORDER BY 3 DESC, p.project_id`
+
+	rows, err := db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var p DigestProject
+
+		err := rows.Scan(&p.ProjectID, &p.Name, &p.Minutes)
+		if err != nil {
+			return nil, err
+		}
+
+		digest.Projects = append(digest.Projects, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT p.project_id, COALESCE(p.name, ''), b.minutes, COALESCE(sum(t.minutes), 0)
+		FROM project_budget b
+		INNER JOIN project p ON p.internal_id = b.project_internal_id
+		INNER JOIN project_appuser pa ON pa.project_internal_id = p.internal_id
+		LEFT JOIN timesheet_entry t ON t.project_internal_id = p.internal_id AND t.deleted_at IS NULL
+		WHERE pa.appuser_internal_id = $1
+		AND p.deleted_at IS NULL
+		AND p.archived_at IS NULL
+		GROUP BY p.project_id, p.name, b.minutes
+		HAVING COALESCE(sum(t.minutes), 0) >= b.minutes * $2::float8
+		ORDER BY p.project_id`
+
+	rows, err = db.QueryContext(ctx, query, userID, warnRatio)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var w DigestBudgetWarning
+
+		err := rows.Scan(&w.ProjectID, &w.Name, &w.BudgetMinutes, &w.LoggedMinutes)
+		if err != nil {
+			return nil, err
+		}
+
+		digest.BudgetWarnings = append(digest.BudgetWarnings, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+func (m DigestModel) MarkSent(userID int32, at time.Time) error {
+	query := `
+		UPDATE digest_preference
+		SET last_sent_at = $2
+		WHERE appuser_internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, at)
+	return err
+}
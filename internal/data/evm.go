@@ -0,0 +1,194 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// DailyActual is the approved time logged on a project on one day, and what
+// it cost at the users' hourly cost under their work rules.
+type DailyActual struct {
+	Date    time.Time
+	Minutes int64
+	Cost    float64
+}
+
+// EVMPoint holds the earned value figures of a project as of Date. Planned
+// values run to the end of the schedule; earned and actual values, and the
+// indices derived from them, are only given up to today. The indices are
+// computed on hours, and the cost figures are present when the budget has a
+// cost.
+type EVMPoint struct {
+	Date           time.Time `json:"date"`
+	PlannedMinutes float64   `json:"pv_minutes"`
+	EarnedMinutes  *float64  `json:"ev_minutes"`
+	ActualMinutes  *int64    `json:"ac_minutes"`
+	PlannedCost    *float64  `json:"pv_cost,omitempty"`
+	EarnedCost     *float64  `json:"ev_cost,omitempty"`
+	ActualCost     *float64  `json:"ac_cost,omitempty"`
+	SPI            *float64  `json:"spi"`
+	CPI            *float64  `json:"cpi"`
+}
+
+// ProjectEVM is the earned value series of a project.
+type ProjectEVM struct {
+	ProjectID     int32      `json:"project_id"`
+	BudgetMinutes int32      `json:"budget_minutes"`
+	BudgetCost    *float64   `json:"budget_cost"`
+	Milestones    int        `json:"milestones"`
+	Completed     int        `json:"completed"`
+	Series        []EVMPoint `json:"series"`
+}
+
+// GetApprovedDaily returns the approved time logged on a project per work
+// date, oldest first.
+func (m TimesheetModel) GetApprovedDaily(externalID int32) ([]DailyActual, error) {
+	query := `
+		SELECT t.work_date, sum(t.minutes), COALESCE(sum(c.cost_minutes * u.hourly_cost / 60), 0)::float8
+		FROM timesheet_entry t
+		INNER JOIN timesheet_entry_cost c ON c.internal_id = t.internal_id
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		INNER JOIN appuser u ON u.internal_id = t.user_internal_id
+		WHERE p.project_id = $1 AND t.status = 'approved' AND t.deleted_at IS NULL
+		GROUP BY t.work_date
+		ORDER BY t.work_date`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	actuals := []DailyActual{}
+
+	for rows.Next() {
+		var actual DailyActual
+
+		err := rows.Scan(&actual.Date, &actual.Minutes, &actual.Cost)
+		if err != nil {
+			return nil, err
+		}
+
+		actuals = append(actuals, actual)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return actuals, nil
+}
+
+// ComputeEVM builds the earned value series of a project from its budget,
+// milestones and approved actuals, with a point every step from the first
+// milestone or actual to the last milestone or today, whichever is later,
+// plus a point for today.
+// Each milestone carries an equal share of the budget: it is planned on its
+// due date and earned on the day it was completed.
+func ComputeEVM(budget *ProjectBudget, milestones []*Milestone, actuals []DailyActual, today time.Time, step func(time.Time) time.Time) *ProjectEVM {
+	evm := &ProjectEVM{
+		ProjectID:     budget.ProjectID,
+		BudgetMinutes: budget.Minutes,
+		BudgetCost:    budget.Cost,
+		Milestones:    len(milestones),
+		Series:        []EVMPoint{},
+	}
+
+	if len(milestones) == 0 {
+		return evm
+	}
+
+	today = truncateDay(today)
+	start, end := truncateDay(milestones[0].DueOn), today
+	for _, milestone := range milestones {
+		due := truncateDay(milestone.DueOn)
+		if due.Before(start) {
+			start = due
+		}
+		if due.After(end) {
+			end = due
+		}
+		if milestone.CompletedOn != nil {
+			evm.Completed++
+		}
+	}
+	if len(actuals) > 0 && truncateDay(actuals[0].Date).Before(start) {
+		start = truncateDay(actuals[0].Date)
+	}
+
+	share := 1 / float64(len(milestones))
+
+	var dates []time.Time
+	for date := start; date.Before(end); date = step(date) {
+		if len(dates) > 0 && dates[len(dates)-1].Before(today) && date.After(today) {
+			dates = append(dates, today)
+		}
+		dates = append(dates, date)
+	}
+	if len(dates) > 0 && dates[len(dates)-1].Before(today) && end.After(today) {
+		dates = append(dates, today)
+	}
+	dates = append(dates, end)
+
+	next := 0
+	var actualMinutes int64
+	var actualCost float64
+
+	for _, date := range dates {
+		var planned, earned float64
+		for _, milestone := range milestones {
+			if !truncateDay(milestone.DueOn).After(date) {
+				planned += share
+			}
+			if milestone.CompletedOn != nil && !truncateDay(*milestone.CompletedOn).After(date) {
+				earned += share
+			}
+		}
+
+		point := EVMPoint{
+			Date:           date,
+			PlannedMinutes: planned * float64(budget.Minutes),
+		}
+		if budget.Cost != nil {
+			point.PlannedCost = ptr(planned * *budget.Cost)
+		}
+
+		if !date.After(today) {
+			for next < len(actuals) && !truncateDay(actuals[next].Date).After(date) {
+				actualMinutes += actuals[next].Minutes
+				actualCost += actuals[next].Cost
+				next++
+			}
+
+			point.EarnedMinutes = ptr(earned * float64(budget.Minutes))
+			point.ActualMinutes = ptr(actualMinutes)
+			if budget.Cost != nil {
+				point.EarnedCost = ptr(earned * *budget.Cost)
+				point.ActualCost = ptr(actualCost)
+			}
+
+			if point.PlannedMinutes > 0 {
+				point.SPI = ptr(*point.EarnedMinutes / point.PlannedMinutes)
+			}
+			if actualMinutes > 0 {
+				point.CPI = ptr(*point.EarnedMinutes / float64(actualMinutes))
+			}
+		}
+
+		evm.Series = append(evm.Series, point)
+	}
+
+	return evm
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
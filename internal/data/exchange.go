@@ -0,0 +1,102 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+type ExchangeRate struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Rate float64   `json:"rate"`
+	Date time.Time `json:"date"`
+}
+
+func ValidateCurrency(v *validator.Validator, key, currency string) {
+	v.Check(currency != "", key, "must be provided")
+	v.Check(validator.Matches(currency, validator.CurrencyRX), key, "must be a 3 letter ISO 4217 currency code")
+}
+
+type ExchangeRateModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Insert stores the rates for one unit of base on a date, replacing rates
+// already fetched for that day.
+func (m ExchangeRateModel) Insert(base string, date time.Time, rates map[string]float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO exchange_rate (base, quote, rate_date, rate)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (base, quote, rate_date) DO UPDATE
+		SET rate = EXCLUDED.rate, created_at = NOW()`
+
+	for quote, rate := range rates {
+		_, err = tx.ExecContext(ctx, query, base, quote, date, rate)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get returns the most recent rate to convert from into to on or before
+// date. Pairs that were not fetched directly are derived from the inverse
+// pair or crossed through a common base.
+func (m ExchangeRateModel) Get(from, to string, date time.Time) (*ExchangeRate, error) {
+	rate := ExchangeRate{From: from, To: to}
+
+	if from == to {
+		rate.Rate = 1
+		rate.Date = date
+		return &rate, nil
+	}
+
+	query := `
+		SELECT rate, rate_date
+		FROM (
+			SELECT rate, rate_date, 0 AS preference
+			FROM exchange_rate
+			WHERE base = $1 AND quote = $2 AND rate_date <= $3
+			UNION ALL
+			SELECT 1 / rate, rate_date, 1
+			FROM exchange_rate
+			WHERE base = $2 AND quote = $1 AND rate_date <= $3
+			UNION ALL
+			SELECT q.rate / b.rate, q.rate_date, 2
+			FROM exchange_rate b
+			INNER JOIN exchange_rate q ON q.base = b.base AND q.rate_date = b.rate_date
+			WHERE b.quote = $1 AND q.quote = $2 AND b.rate_date <= $3
+		) r
+		ORDER BY rate_date DESC, preference
+		LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, from, to, date).Scan(&rate.Rate, &rate.Date)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &rate, nil
+}
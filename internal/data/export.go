@@ -0,0 +1,159 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportEntities lists what an organization export contains, in the order it
+// is written. Each query takes the organization's internal ID as $1.
+var ExportEntities = []struct {
+	Name  string
+	Query string
+}{
+	{"organization", `
+		SELECT internal_id, name, base_currency, created_at, updated_at
+		FROM organization
+		WHERE internal_id = $1`},
+	{"users", `
+		SELECT internal_id, email, first_name, last_name, activated, avatar_key, created_at, updated_at
+		FROM appuser
+		WHERE org_internal_id = $1
+		ORDER BY internal_id`},
+	{"teams", `
+		SELECT internal_id, name, created_at, updated_at
+		FROM team
+		WHERE org_internal_id = $1
+		ORDER BY internal_id`},
+	{"team_members", `
+		SELECT tm.team_internal_id, tm.user_internal_id, tm.is_lead
+		FROM team_member tm
+		INNER JOIN team t ON t.internal_id = tm.team_internal_id
+		WHERE t.org_internal_id = $1
+		ORDER BY tm.team_internal_id, tm.user_internal_id`},
+	{"clients", `
+		SELECT internal_id, name, address, logo_url, note, custom_fields, parent_internal_id, created_at, updated_at
+		FROM client
+		WHERE org_internal_id = $1
+		ORDER BY internal_id`},
+	{"projects", `
+		SELECT internal_id, project_id, proposal_id, name, status, feature, images, custom_fields, storage_bytes, created_at, updated_at
+		FROM project
+		WHERE org_internal_id = $1
+		ORDER BY project_id`},
+	{"project_clients", `
+		SELECT p.project_id, pc.client_internal_id
+		FROM project_client pc
+		INNER JOIN project p ON p.internal_id = pc.project_internal_id
+		WHERE p.org_internal_id = $1
+		ORDER BY p.project_id, pc.client_internal_id`},
+	{"proposals", `
+		SELECT pr.project_id AS proposal_id, pr.due_on, pr.created_at, pr.updated_at
+		FROM proposal pr
+		INNER JOIN project p ON p.proposal_id = pr.project_id
+		WHERE p.org_internal_id = $1
+		ORDER BY pr.project_id`},
+	{"milestones", `
+		SELECT m.internal_id, p.project_id, m.name, m.due_on, m.created_at
+		FROM project_milestone m
+		INNER JOIN project p ON p.internal_id = m.project_internal_id
+		WHERE p.org_internal_id = $1
+		ORDER BY p.project_id, m.due_on`},
+	{"timesheet_entries", `
+		SELECT t.internal_id, t.user_internal_id, p.project_id, t.activity_internal_id, t.work_date, t.minutes, t.billed_minutes, t.note, t.source,
+			t.status, t.submitted_at, t.approver_internal_id, t.created_at
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE p.org_internal_id = $1
+		ORDER BY t.work_date, t.internal_id`},
+	{"files", `
+		SELECT f.internal_id, p.project_id, f.key, f.category, f.size_bytes, f.mime_type, f.checksum_sha256, f.supersedes_internal_id, f.created_at
+		FROM file f
+		INNER JOIN project p ON p.internal_id = f.project_internal_id
+		WHERE p.org_internal_id = $1
+		ORDER BY f.key`},
+}
+
+type ExportModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// WriteJSON writes the rows of an export query to w as a JSON array.
+func (m ExportModel) WriteJSON(w io.Writer, query string, orgID int32) error {
+	query = fmt.Sprintf(`SELECT COALESCE(json_agg(t), '[]') FROM (%s) t`, query)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var rows []byte
+
+	err := readDB(m.ReadDB, m.DB).QueryRowContext(ctx, query, orgID).Scan(&rows)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(rows)
+	return err
+}
+
+// WriteCSV writes the rows of an export query to w as CSV with a header row.
+// NULL values are written as empty cells.
+func (m ExportModel) WriteCSV(w io.Writer, query string, orgID int32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, orgID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+
+	err = cw.Write(columns)
+	if err != nil {
+		return err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	for rows.Next() {
+		err := rows.Scan(dest...)
+		if err != nil {
+			return err
+		}
+
+		for i, value := range values {
+			record[i] = value.String
+		}
+
+		err = cw.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,258 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+type File struct {
+	ID                int64      `json:"id"`
+	Key               string     `json:"key"`
+	ProjectID         int32      `json:"project_id"`
+	ProjectInternalID int32      `json:"-"`
+	UploaderID        *int32     `json:"uploader_id"`
+	SizeBytes         int64      `json:"size_bytes"`
+	MimeType          string     `json:"mime_type"`
+	ChecksumSHA256    *string    `json:"checksum_sha256"`
+	Category          *string    `json:"category"`
+	SupersedesID      *int64     `json:"supersedes_id"`
+	ScannedAt         *time.Time `json:"scanned_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+var DocumentCategories = []string{"drawings", "contracts", "photos", "reports"}
+
+func ValidateCategory(v *validator.Validator, category string) {
+	v.Check(validator.PermittedValue(category, DocumentCategories...), "category", "must be one of drawings, contracts, photos or reports")
+}
+
+func ValidateChecksumSHA256(v *validator.Validator, checksum string) {
+	v.Check(checksum != "", "sha256", "must be provided")
+	v.Check(validator.Matches(checksum, validator.SHA256HexRX), "sha256", "must be a hex encoded sha-256 digest")
+}
+
+type FileModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Upsert records a completed upload. Re-completing the same key replaces
+// the previous metadata, since S3 overwrote the object.
+func (m FileModel) Upsert(file *File) error {
+	query := `
+		INSERT INTO file (key, project_internal_id, uploader_internal_id, size_bytes, mime_type, checksum_sha256, category, supersedes_internal_id, scanned_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (key) DO UPDATE
+		SET project_internal_id = EXCLUDED.project_internal_id,
+			uploader_internal_id = EXCLUDED.uploader_internal_id,
+			size_bytes = EXCLUDED.size_bytes,
+			mime_type = EXCLUDED.mime_type,
+			checksum_sha256 = EXCLUDED.checksum_sha256,
+			category = EXCLUDED.category,
+			supersedes_internal_id = EXCLUDED.supersedes_internal_id,
+			scanned_at = EXCLUDED.scanned_at,
+			created_at = NOW()
+		RETURNING internal_id, created_at`
+
+	args := []any{
+		file.Key,
+		file.ProjectInternalID,
+		file.UploaderID,
+		file.SizeBytes,
+		file.MimeType,
+		file.ChecksumSHA256,
+		file.Category,
+		file.SupersedesID,
+		file.ScannedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&file.ID, &file.CreatedAt)
+}
+
+const fileColumns = `f.internal_id, f.key, p.project_id, f.project_internal_id, f.uploader_internal_id,
+	f.size_bytes, f.mime_type, f.checksum_sha256, f.category, f.supersedes_internal_id, f.scanned_at, f.created_at`
+
+func scanFile(row interface{ Scan(...any) error }, file *File) error {
+	return row.Scan(
+		&file.ID,
+		&file.Key,
+		&file.ProjectID,
+		&file.ProjectInternalID,
+		&file.UploaderID,
+		&file.SizeBytes,
+		&file.MimeType,
+		&file.ChecksumSHA256,
+		&file.Category,
+		&file.SupersedesID,
+		&file.ScannedAt,
+		&file.CreatedAt,
+	)
+}
+
+func (m FileModel) queryFiles(query string, args ...any) ([]*File, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	files := []*File{}
+
+	for rows.Next() {
+		var file File
+		err := scanFile(rows, &file)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, &file)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (m FileModel) Get(id int64) (*File, error) {
+	query := `
+		SELECT ` + fileColumns + `
+		FROM file f
+		INNER JOIN project p ON f.project_internal_id = p.internal_id
+		WHERE f.internal_id = $1`
+
+	var file File
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := scanFile(m.DB.QueryRowContext(ctx, query, id), &file)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &file, nil
+}
+
+func (m FileModel) GetAllForProject(externalID int32) ([]*File, error) {
+	query := `
+		SELECT ` + fileColumns + `
+		FROM file f
+		INNER JOIN project p ON f.project_internal_id = p.internal_id
+		WHERE p.project_id = $1
+		ORDER BY f.key`
+
+	return m.queryFiles(query, externalID)
+}
+
+// GetLatestDocuments returns the categorized files of a project that have
+// not been superseded by a newer version, optionally limited to a category.
+func (m FileModel) GetLatestDocuments(externalID int32, category string) ([]*File, error) {
+	query := `
+		SELECT ` + fileColumns + `
+		FROM file f
+		INNER JOIN project p ON f.project_internal_id = p.internal_id
+		WHERE p.project_id = $1
+		AND f.category IS NOT NULL
+		AND (f.category = $2 OR $2 = '')
+		AND NOT EXISTS (
+			SELECT 1 FROM file s WHERE s.supersedes_internal_id = f.internal_id
+		)
+		ORDER BY f.category, f.created_at DESC`
+
+	return m.queryFiles(query, externalID, category)
+}
+
+// GetVersions returns a file followed by every earlier version it
+// supersedes, newest first.
+func (m FileModel) GetVersions(id int64) ([]*File, error) {
+	query := `
+		WITH RECURSIVE chain (internal_id, depth) AS (
+			SELECT internal_id, 0 FROM file WHERE internal_id = $1
+			UNION ALL
+			SELECT f.supersedes_internal_id, c.depth + 1
+			FROM file f
+			INNER JOIN chain c ON f.internal_id = c.internal_id
+			WHERE f.supersedes_internal_id IS NOT NULL
+		)
+		SELECT ` + fileColumns + `
+		FROM chain c
+		INNER JOIN file f ON f.internal_id = c.internal_id
+		INNER JOIN project p ON f.project_internal_id = p.internal_id
+		ORDER BY c.depth`
+
+	return m.queryFiles(query, id)
+}
+
+// Reconcile makes the catalog for a project match the objects found in S3:
+// objects uploaded without completion are added unverified and rows whose
+// object is gone are removed.
+func (m FileModel) Reconcile(externalID int32, objects []*File) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+
+		query := `
+			INSERT INTO file (key, project_internal_id, size_bytes, mime_type)
+			SELECT $1, internal_id, $2, $3
+			FROM project
+			WHERE project_id = $4
+			ON CONFLICT (key) DO NOTHING`
+
+		_, err = tx.ExecContext(ctx, query, object.Key, object.SizeBytes, object.MimeType, externalID)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		DELETE FROM file
+		WHERE project_internal_id = (SELECT internal_id FROM project WHERE project_id = $1)
+		AND NOT key = ANY($2::text[])`
+
+	_, err = tx.ExecContext(ctx, query, externalID, pq.Array(keys))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m FileModel) DeleteByKeys(keys []string) error {
+	query := `
+		DELETE FROM file
+		WHERE key = ANY($1::text[])`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(keys))
+	return err
+}
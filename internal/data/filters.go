@@ -19,24 +19,35 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(f.PageSize >= 0, "page_size", "must be a minimum of 0")
 	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
 
-	v.Check(validator.PermittedValue(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+	// Sort is a comma-separated list of columns, each optionally prefixed
+	// with "-" for descending order.
+	seen := make(map[string]bool)
+	for _, sort := range strings.Split(f.Sort, ",") {
+		v.Check(validator.PermittedValue(sort, f.SortSafelist...), "sort", "invalid sort value")
+		column := strings.TrimPrefix(sort, "-")
+		v.Check(!seen[column], "sort", "must not sort by the same column twice")
+		seen[column] = true
+	}
 }
 
-func (f Filters) sortColumn() string {
-	for _, safeValue := range f.SortSafelist {
-		if f.Sort == safeValue {
-			return strings.TrimPrefix(f.Sort, "-")
+// orderBy returns the ORDER BY terms for the sort columns, e.g.
+// "work_date ASC, project_id DESC". It panics on a column outside the
+// safelist, which ValidateFilters rules out.
+func (f Filters) orderBy() string {
+	terms := []string{}
+	for _, sort := range strings.Split(f.Sort, ",") {
+		if !validator.PermittedValue(sort, f.SortSafelist...) {
+			panic("unsafe sort parameter: " + sort)
 		}
-	}
-	panic("unsafe sort parameter: " + f.Sort)
-}
 
-func (f Filters) sortDirection() string {
-	if strings.HasPrefix(f.Sort, "-") {
-		return "DESC"
+		direction := "ASC"
+		if strings.HasPrefix(sort, "-") {
+			direction = "DESC"
+		}
+		terms = append(terms, strings.TrimPrefix(sort, "-")+" "+direction)
 	}
 
-	return "ASC"
+	return strings.Join(terms, ", ")
 }
 
 func (f Filters) limit() int {
@@ -53,6 +64,9 @@ type Metadata struct {
 	FirstPage    int `json:"first_page,omitempty"`
 	LastPage     int `json:"last_page,omitempty"`
 	TotalRecords int `json:"total_records,omitempty"`
+	// TotalMinutes is the summed duration of every matching timesheet
+	// entry, not only those on the current page.
+	TotalMinutes int64 `json:"total_minutes,omitempty"`
 }
 
 func calculateMetadata(totalRecords, page, pageSize int) Metadata {
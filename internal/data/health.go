@@ -0,0 +1,251 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	HealthGreen = "green"
+	HealthAmber = "amber"
+	HealthRed   = "red"
+)
+
+// The thresholds a project's indicators turn amber and then red at.
+const (
+	healthBurnAmber    = 0.8
+	healthBurnRed      = 1.0
+	healthStaleAmber   = 14
+	healthStaleRed     = 30
+	healthOverdueAmber = 1
+	healthOverdueRed   = 2
+)
+
+// ProjectHealth rates an active project on its budget burn, the days since
+// time was last logged on it and its overdue milestones. Status is the
+// worst of the three, and Reasons explains every indicator that isn't
+// green.
+type ProjectHealth struct {
+	Status string `json:"status"`
+	// BudgetBurn is the share of the budgeted minutes logged so far, or nil
+	// without a budget.
+	BudgetBurn *float64 `json:"budget_burn"`
+	// DaysSinceTimesheet counts from the last day time was logged, or from
+	// the project's creation when none was.
+	DaysSinceTimesheet int      `json:"days_since_timesheet"`
+	OverdueMilestones  int      `json:"overdue_milestones"`
+	Reasons            []string `json:"reasons"`
+}
+
+// HealthAlert lists the projects a manager manages that are red.
+type HealthAlert struct {
+	UserID    int32
+	Email     string
+	FirstName string
+	Projects  []HealthAlertProject
+}
+
+type HealthAlertProject struct {
+	ProjectID int32
+	Name      string
+	Health    *ProjectHealth
+}
+
+type HealthModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// healthQuery gathers the indicators of the active projects, all of them
+// when $1 is NULL and those with these external IDs otherwise, with
+// milestones due before $2 counting as overdue.
+const healthQuery = `
+		SELECT p.project_id, COALESCE(p.name, ''), b.minutes,
+			(
+				SELECT COALESCE(sum(t.minutes), 0)
+				FROM timesheet_entry t
+				WHERE t.project_internal_id = p.internal_id AND t.deleted_at IS NULL
+			),
+			$2::date - COALESCE(
+				(
+					SELECT max(t.work_date)
+					FROM timesheet_entry t
+					WHERE t.project_internal_id = p.internal_id AND t.deleted_at IS NULL
+				),
+				p.created_at::date
+			),
+			(
+				SELECT count(*)
+				FROM project_milestone m
+				WHERE m.project_internal_id = p.internal_id AND m.completed_on IS NULL AND m.due_on < $2::date
+			)
+		FROM project p
+		LEFT JOIN project_budget b ON b.project_internal_id = p.internal_id
+		WHERE ($1::integer[] IS NULL OR p.project_id = ANY($1::integer[]))
+		AND p.archived_at IS NULL
+		AND p.deleted_at IS NULL
+		ORDER BY p.project_id`
+
+// GetForProjects rates the projects with the given external IDs as of today,
+// keyed by external ID. Archived projects are left out.
+func (m HealthModel) GetForProjects(externalIDs []int32, today time.Time) (map[int32]*ProjectHealth, error) {
+	projects, err := m.rate(pq.Array(externalIDs), today)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make(map[int32]*ProjectHealth, len(projects))
+	for _, p := range projects {
+		health[p.ProjectID] = p.Health
+	}
+
+	return health, nil
+}
+
+// GetAlerts rates every active project as of today and returns the red ones
+// grouped by the managers assigned to them. Red projects without a manager
+// are not reported.
+func (m HealthModel) GetAlerts(today time.Time) ([]*HealthAlert, error) {
+	projects, err := m.rate(nil, today)
+	if err != nil {
+		return nil, err
+	}
+
+	red := make(map[int32]HealthAlertProject)
+	ids := []int32{}
+	for _, p := range projects {
+		if p.Health.Status == HealthRed {
+			red[p.ProjectID] = p
+			ids = append(ids, p.ProjectID)
+		}
+	}
+
+	alerts := []*HealthAlert{}
+	if len(ids) == 0 {
+		return alerts, nil
+	}
+
+	query := `
+		SELECT u.internal_id, u.email, u.first_name, p.project_id
+		FROM project_appuser pa
+		INNER JOIN project p ON p.internal_id = pa.project_internal_id
+		INNER JOIN appuser u ON u.internal_id = pa.appuser_internal_id
+		WHERE pa.manager AND u.activated AND p.project_id = ANY($1)
+		ORDER BY u.internal_id, p.project_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var alert *HealthAlert
+
+	for rows.Next() {
+		var a HealthAlert
+		var projectID int32
+
+		err := rows.Scan(&a.UserID, &a.Email, &a.FirstName, &projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		if alert == nil || alert.UserID != a.UserID {
+			alert = &a
+			alerts = append(alerts, alert)
+		}
+		alert.Projects = append(alert.Projects, red[projectID])
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (m HealthModel) rate(externalIDs any, today time.Time) ([]HealthAlertProject, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, healthQuery, externalIDs, today)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	projects := []HealthAlertProject{}
+
+	for rows.Next() {
+		var p HealthAlertProject
+		var budget *int64
+		var logged int64
+		var stale, overdue int
+
+		err := rows.Scan(&p.ProjectID, &p.Name, &budget, &logged, &stale, &overdue)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Health = rateHealth(budget, logged, stale, overdue)
+		projects = append(projects, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func rateHealth(budget *int64, logged int64, stale, overdue int) *ProjectHealth {
+	h := &ProjectHealth{
+		Status:             HealthGreen,
+		DaysSinceTimesheet: stale,
+		OverdueMilestones:  overdue,
+		Reasons:            []string{},
+	}
+
+	flag := func(status, reason string) {
+		if status == HealthRed || h.Status == HealthGreen {
+			h.Status = status
+		}
+		h.Reasons = append(h.Reasons, reason)
+	}
+
+	if budget != nil && *budget > 0 {
+		h.BudgetBurn = ptr(float64(logged) / float64(*budget))
+
+		switch {
+		case *h.BudgetBurn >= healthBurnRed:
+			flag(HealthRed, fmt.Sprintf("%.0f%% of the budget is used", *h.BudgetBurn*100))
+		case *h.BudgetBurn >= healthBurnAmber:
+			flag(HealthAmber, fmt.Sprintf("%.0f%% of the budget is used", *h.BudgetBurn*100))
+		}
+	}
+
+	switch {
+	case stale >= healthStaleRed:
+		flag(HealthRed, fmt.Sprintf("no time logged in %d days", stale))
+	case stale >= healthStaleAmber:
+		flag(HealthAmber, fmt.Sprintf("no time logged in %d days", stale))
+	}
+
+	switch {
+	case overdue >= healthOverdueRed:
+		flag(HealthRed, fmt.Sprintf("%d milestones are overdue", overdue))
+	case overdue >= healthOverdueAmber:
+		flag(HealthAmber, fmt.Sprintf("%d milestone is overdue", overdue))
+	}
+
+	return h
+}
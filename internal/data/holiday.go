@@ -0,0 +1,118 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+var ErrDuplicateHoliday = errors.New("duplicate holiday")
+
+// Holiday is a day nobody in an organization is expected to work.
+type Holiday struct {
+	InternalID int32     `json:"id"`
+	Day        time.Time `json:"day"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func ValidateHoliday(v *validator.Validator, h *Holiday) {
+	v.Check(!h.Day.IsZero(), "day", "must be provided")
+	v.Check(h.Name != "", "name", "must be provided")
+	v.Check(len(h.Name) <= 200, "name", "must not be more than 200 bytes long")
+}
+
+type HolidayModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m HolidayModel) Insert(orgID int32, h *Holiday) error {
+	query := `
+		INSERT INTO holiday (org_internal_id, day, name)
+		VALUES ($1, $2, $3)
+		RETURNING internal_id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, orgID, h.Day, h.Name).Scan(&h.InternalID, &h.CreatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "holiday_org_internal_id_day_key"`:
+			return ErrDuplicateHoliday
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAll lists an organization's holidays between from and to, inclusive.
+func (m HolidayModel) GetAll(orgID int32, from, to time.Time) ([]*Holiday, error) {
+	query := `
+		SELECT internal_id, day, name, created_at
+		FROM holiday
+		WHERE org_internal_id = $1 AND day BETWEEN $2 AND $3
+		ORDER BY day`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	holidays := []*Holiday{}
+
+	for rows.Next() {
+		var h Holiday
+		err := rows.Scan(&h.InternalID, &h.Day, &h.Name, &h.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		holidays = append(holidays, &h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return holidays, nil
+}
+
+func (m HolidayModel) Delete(orgID, id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM holiday
+		WHERE internal_id = $1 AND org_internal_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
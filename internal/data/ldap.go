@@ -0,0 +1,381 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+
+	"github.com/lib/pq"
+)
+
+// ErrOtherOrganization is returned when a directory user's email belongs to
+// an account of another organization.
+var ErrOtherOrganization = errors.New("user belongs to another organization")
+
+// ErrNotLinked is returned when a directory user's email belongs to an
+// account that was not created from the directory nor linked to it.
+var ErrNotLinked = errors.New("account is not linked to the directory")
+
+var ldapAttributeRX = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// LDAPConfig lets an organization's users sign in with their directory
+// credentials. Users bind as BindTemplate with {username} replaced, such as
+// {username}@corp.example.com for Active Directory, and their entry is the
+// one under BaseDN whose LoginAttribute is their username.
+type LDAPConfig struct {
+	OrgID              int32           `json:"organization_id"`
+	URL                string          `json:"url"`
+	BindTemplate       string          `json:"bind_template"`
+	BaseDN             string          `json:"base_dn"`
+	LoginAttribute     string          `json:"login_attribute"`
+	EmailAttribute     string          `json:"email_attribute"`
+	FirstNameAttribute string          `json:"first_name_attribute"`
+	LastNameAttribute  string          `json:"last_name_attribute"`
+	GroupAttribute     string          `json:"group_attribute"`
+	GroupRoles         []LDAPGroupRole `json:"group_roles"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// LDAPGroupRole gives members of a directory group a role. Group DNs are
+// compared case-insensitively.
+type LDAPGroupRole struct {
+	Group string `json:"group"`
+	Role  string `json:"role"`
+}
+
+// Roles returns the roles members of groups hold.
+func (c *LDAPConfig) Roles(groups []string) []string {
+	member := make(map[string]bool)
+	for _, g := range groups {
+		member[strings.ToLower(g)] = true
+	}
+
+	roles := []string{}
+	for _, gr := range c.GroupRoles {
+		if member[gr.Group] && !validator.PermittedValue(gr.Role, roles...) {
+			roles = append(roles, gr.Role)
+		}
+	}
+
+	return roles
+}
+
+func ValidateLDAPConfig(v *validator.Validator, c *LDAPConfig) {
+	u, err := url.Parse(c.URL)
+	v.Check(c.URL != "", "url", "must be provided")
+	v.Check(err == nil && (u.Scheme == "ldaps" || u.Scheme == "ldap") && u.Host != "", "url", "must be an ldaps:// or ldap:// URL, the latter upgraded with StartTLS")
+
+	v.Check(strings.Contains(c.BindTemplate, "{username}"), "bind_template", "must contain {username}")
+	v.Check(c.BaseDN != "", "base_dn", "must be provided")
+
+	for key, attribute := range map[string]string{
+		"login_attribute":      c.LoginAttribute,
+		"email_attribute":      c.EmailAttribute,
+		"first_name_attribute": c.FirstNameAttribute,
+		"last_name_attribute":  c.LastNameAttribute,
+		"group_attribute":      c.GroupAttribute,
+	} {
+		v.Check(validator.Matches(attribute, ldapAttributeRX), key, "must be an attribute name")
+	}
+
+	v.Check(c.GroupRoles != nil, "group_roles", "must be provided")
+
+	lowered := make([]LDAPGroupRole, len(c.GroupRoles))
+	for i, gr := range c.GroupRoles {
+		v.Check(gr.Group != "" && gr.Role != "", "group_roles", "must each have a group and a role")
+		lowered[i] = LDAPGroupRole{Group: strings.ToLower(gr.Group), Role: gr.Role}
+	}
+	v.Check(validator.Unique(lowered), "group_roles", "must not contain duplicate values")
+}
+
+type LDAPModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m LDAPModel) Get(orgID int32) (*LDAPConfig, error) {
+	query := `
+		SELECT url, bind_template, base_dn, login_attribute, email_attribute, first_name_attribute,
+			last_name_attribute, group_attribute, created_at, updated_at
+		FROM ldap_config
+		WHERE org_internal_id = $1`
+
+	c := LDAPConfig{OrgID: orgID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, orgID).Scan(
+		&c.URL,
+		&c.BindTemplate,
+		&c.BaseDN,
+		&c.LoginAttribute,
+		&c.EmailAttribute,
+		&c.FirstNameAttribute,
+		&c.LastNameAttribute,
+		&c.GroupAttribute,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	query = `
+		SELECT group_dn, role
+		FROM ldap_group_role
+		WHERE org_internal_id = $1
+		ORDER BY group_dn, role`
+
+	rows, err := m.DB.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	c.GroupRoles = []LDAPGroupRole{}
+
+	for rows.Next() {
+		var gr LDAPGroupRole
+
+		err := rows.Scan(&gr.Group, &gr.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		c.GroupRoles = append(c.GroupRoles, gr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Put creates an organization's directory configuration or replaces it,
+// and reports whether it was created. Group DNs are stored lowercased.
+// Users keep the roles they were given until they next sign in.
+func (m LDAPModel) Put(c *LDAPConfig) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO ldap_config (org_internal_id, url, bind_template, base_dn, login_attribute, email_attribute,
+			first_name_attribute, last_name_attribute, group_attribute)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (org_internal_id) DO UPDATE
+		SET url = EXCLUDED.url, bind_template = EXCLUDED.bind_template, base_dn = EXCLUDED.base_dn,
+			login_attribute = EXCLUDED.login_attribute, email_attribute = EXCLUDED.email_attribute,
+			first_name_attribute = EXCLUDED.first_name_attribute, last_name_attribute = EXCLUDED.last_name_attribute,
+			group_attribute = EXCLUDED.group_attribute, updated_at = NOW()
+		RETURNING created_at, updated_at, xmax = 0`
+
+	args := []any{
+		c.OrgID,
+		c.URL,
+		c.BindTemplate,
+		c.BaseDN,
+		c.LoginAttribute,
+		c.EmailAttribute,
+		c.FirstNameAttribute,
+		c.LastNameAttribute,
+		c.GroupAttribute,
+	}
+
+	var created bool
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&c.CreatedAt, &c.UpdatedAt, &created)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM ldap_group_role WHERE org_internal_id = $1`, c.OrgID)
+	if err != nil {
+		return false, err
+	}
+
+	groups := make([]string, len(c.GroupRoles))
+	roles := make([]string, len(c.GroupRoles))
+	for i := range c.GroupRoles {
+		c.GroupRoles[i].Group = strings.ToLower(c.GroupRoles[i].Group)
+		groups[i], roles[i] = c.GroupRoles[i].Group, c.GroupRoles[i].Role
+	}
+
+	query = `
+		INSERT INTO ldap_group_role (org_internal_id, group_dn, role)
+		SELECT $1, g.group_dn, r.name
+		FROM unnest($2::text[], $3::text[]) AS g (group_dn, role)
+		INNER JOIN role r ON r.name = g.role
+		ON CONFLICT DO NOTHING`
+
+	result, err := tx.ExecContext(ctx, query, c.OrgID, pq.Array(groups), pq.Array(roles))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected != int64(len(c.GroupRoles)) {
+		return false, ErrUnknownRole
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// Delete stops an organization's users signing in with the directory and
+// takes away the roles they held through it. The permissions those roles
+// granted are revoked when Recompute is next run.
+func (m LDAPModel) Delete(orgID int32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM ldap_config WHERE org_internal_id = $1`, orgID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM appuser_role ur
+		USING appuser a
+		WHERE a.internal_id = ur.user_internal_id AND a.org_internal_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, orgID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Provision returns the account of a user who signed in with the directory
+// of organization orgID, setting user.InternalID. On their first sign-in an
+// activated account is created from user's email and names, linked to the
+// directory. An existing account is only used once linked with Link, as the
+// directory merely asserts the email; it is activated if it was awaiting
+// activation. Either way the user's directory roles become roles.
+func (m LDAPModel) Provision(orgID int32, user *User, roles []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO appuser (email, first_name, last_name, activated, org_internal_id, directory_linked)
+		VALUES ($1, $2, $3, true, $4, true)
+		ON CONFLICT (email) DO UPDATE SET email = appuser.email
+		RETURNING internal_id, org_internal_id, directory_linked, xmax = 0`
+
+	var accountOrgID int32
+	var linked, created bool
+
+	err = tx.QueryRowContext(ctx, query, user.Email, user.FirstName, user.LastName, orgID).Scan(&user.InternalID, &accountOrgID, &linked, &created)
+	if err != nil {
+		return false, err
+	}
+
+	if accountOrgID != orgID {
+		return false, ErrOtherOrganization
+	}
+
+	if !linked {
+		return false, ErrNotLinked
+	}
+
+	query = `
+		UPDATE appuser
+		SET activated = true, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $1 AND NOT activated`
+
+	_, err = tx.ExecContext(ctx, query, user.InternalID)
+	if err != nil {
+		return false, err
+	}
+
+	err = setDirectoryRoles(ctx, tx, user.InternalID, roles)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// Link lets the directory of organization orgID sign in to the user's
+// existing account, or with linked false stops it. It returns
+// ErrRecordNotFound when the user is not one of the organization's.
+func (m LDAPModel) Link(orgID, userID int32, linked bool) error {
+	query := `
+		UPDATE appuser
+		SET directory_linked = $3, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $1 AND org_internal_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, orgID, linked)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
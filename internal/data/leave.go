@@ -0,0 +1,171 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// Leave is time a user is away between two dates, inclusive. Capacity
+// planning takes it out of the user's available hours.
+type Leave struct {
+	InternalID int32     `json:"id"`
+	UserID     int32     `json:"user_id"`
+	StartsOn   time.Time `json:"starts_on"`
+	EndsOn     time.Time `json:"ends_on"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func ValidateLeave(v *validator.Validator, l *Leave) {
+	v.Check(l.UserID > 0, "user_id", "must be provided")
+	v.Check(!l.StartsOn.IsZero(), "starts_on", "must be provided")
+	v.Check(!l.EndsOn.IsZero(), "ends_on", "must be provided")
+	v.Check(!l.EndsOn.Before(l.StartsOn), "ends_on", "must not be before starts_on")
+	v.Check(len(l.Note) <= 500, "note", "must not be more than 500 bytes long")
+}
+
+type LeaveModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Insert stores the leave when its user belongs to the actor's
+// organization, and returns ErrRecordNotFound otherwise.
+func (m LeaveModel) Insert(actor Actor, l *Leave) error {
+	scope, scopeArgs := actor.orgScope("u.org_internal_id", 5)
+
+	query := `
+		INSERT INTO user_leave (user_internal_id, starts_on, ends_on, note)
+		SELECT $1, $2, $3, $4
+		WHERE EXISTS (SELECT 1 FROM appuser u WHERE u.internal_id = $1` + scope + `)
+		RETURNING internal_id, created_at`
+
+	args := append([]any{l.UserID, l.StartsOn, l.EndsOn, l.Note}, scopeArgs...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&l.InternalID, &l.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the leave if its user belongs to the actor's organization.
+func (m LeaveModel) Get(actor Actor, id int32) (*Leave, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT l.internal_id, l.user_internal_id, l.starts_on, l.ends_on, l.note, l.created_at
+		FROM user_leave l
+		INNER JOIN appuser u ON u.internal_id = l.user_internal_id
+		WHERE l.internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("u.org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var l Leave
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(&l.InternalID, &l.UserID, &l.StartsOn, &l.EndsOn, &l.Note, &l.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &l, nil
+}
+
+// GetAll lists the leave overlapping from and to, of one user or of every
+// user in the actor's organization when userID is zero. A nil bound leaves
+// that side open.
+func (m LeaveModel) GetAll(actor Actor, userID int32, from, to *time.Time) ([]*Leave, error) {
+	scope, scopeArgs := actor.orgScope("u.org_internal_id", 4)
+
+	query := `
+		SELECT l.internal_id, l.user_internal_id, l.starts_on, l.ends_on, l.note, l.created_at
+		FROM user_leave l
+		INNER JOIN appuser u ON u.internal_id = l.user_internal_id
+		WHERE ($1 = 0 OR l.user_internal_id = $1)
+		AND ($2::date IS NULL OR l.ends_on >= $2)
+		AND ($3::date IS NULL OR l.starts_on <= $3)` + scope + `
+		ORDER BY l.starts_on, l.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	args := append([]any{userID, from, to}, scopeArgs...)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	leave := []*Leave{}
+
+	for rows.Next() {
+		var l Leave
+		err := rows.Scan(&l.InternalID, &l.UserID, &l.StartsOn, &l.EndsOn, &l.Note, &l.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		leave = append(leave, &l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return leave, nil
+}
+
+func (m LeaveModel) Delete(id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM user_leave
+		WHERE internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+type Milestone struct {
+	InternalID  int32      `json:"id"`
+	ProjectID   int32      `json:"project_id"`
+	Name        string     `json:"name"`
+	DueOn       time.Time  `json:"due_on"`
+	CompletedOn *time.Time `json:"completed_on"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func ValidateMilestone(v *validator.Validator, milestone *Milestone) {
+	v.Check(milestone.Name != "", "name", "must be provided")
+	v.Check(len(milestone.Name) <= 500, "name", "must not be more than 500 bytes long")
+	v.Check(!milestone.DueOn.IsZero(), "due_on", "must be provided")
+}
+
+// CalendarEvent is an all-day entry in a user's calendar feed.
+type CalendarEvent struct {
+	UID       string
+	Summary   string
+	Date      time.Time
+	UpdatedAt time.Time
+}
+
+type MilestoneModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m MilestoneModel) Insert(milestone *Milestone) error {
+	query := `
+		INSERT INTO project_milestone (project_internal_id, name, due_on)
+		SELECT internal_id, $2, $3
+		FROM project
+		WHERE project_id = $1
+		RETURNING internal_id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, milestone.ProjectID, milestone.Name, milestone.DueOn).Scan(
+		&milestone.InternalID,
+		&milestone.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m MilestoneModel) GetAllForProject(externalID int32) ([]*Milestone, error) {
+	query := `
+		SELECT pm.internal_id, p.project_id, pm.name, pm.due_on, pm.completed_on, pm.created_at
+		FROM project_milestone pm
+		INNER JOIN project p ON pm.project_internal_id = p.internal_id
+		WHERE p.project_id = $1
+		ORDER BY pm.due_on, pm.internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	milestones := []*Milestone{}
+
+	for rows.Next() {
+		var milestone Milestone
+		err := rows.Scan(
+			&milestone.InternalID,
+			&milestone.ProjectID,
+			&milestone.Name,
+			&milestone.DueOn,
+			&milestone.CompletedOn,
+			&milestone.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		milestones = append(milestones, &milestone)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+// SetCompleted records the day a milestone of a project the actor may see
+// was reached, or clears it when completedOn is nil.
+func (m MilestoneModel) SetCompleted(actor Actor, id int32, completedOn *time.Time) (*Milestone, error) {
+	scope, scopeArgs := actor.projectScope(3)
+
+	query := `
+		UPDATE project_milestone pm
+		SET completed_on = $2
+		FROM project p
+		WHERE pm.internal_id = $1 AND p.internal_id = pm.project_internal_id` + scope + `
+		RETURNING pm.internal_id, p.project_id, pm.name, pm.due_on, pm.completed_on, pm.created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var milestone Milestone
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id, completedOn}, scopeArgs...)...).Scan(
+		&milestone.InternalID,
+		&milestone.ProjectID,
+		&milestone.Name,
+		&milestone.DueOn,
+		&milestone.CompletedOn,
+		&milestone.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &milestone, nil
+}
+
+func (m MilestoneModel) Delete(actor Actor, id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	scope, scopeArgs := actor.projectScope(2)
+
+	query := `
+		DELETE FROM project_milestone pm
+		USING project p
+		WHERE pm.internal_id = $1 AND p.internal_id = pm.project_internal_id` + scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, append([]any{id}, scopeArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetCalendarForUser returns milestones and proposal due dates of the
+// projects a user is assigned to.
+func (m MilestoneModel) GetCalendarForUser(userID int32) ([]*CalendarEvent, error) {
+	query := `
+		SELECT 'milestone-' || pm.internal_id, p.project_id || ' ' || COALESCE(p.name, '') || ': ' || pm.name, pm.due_on, pm.created_at
+		FROM project_milestone pm
+		INNER JOIN project p ON pm.project_internal_id = p.internal_id
+		INNER JOIN project_appuser pa ON pa.project_internal_id = p.internal_id
+		WHERE pa.appuser_internal_id = $1
+		UNION ALL
+		SELECT 'proposal-' || pr.internal_id, 'Proposal ' || pr.project_id || ' due', pr.due_on, pr.updated_at
+		FROM proposal pr
+		INNER JOIN project p ON p.proposal_id = pr.project_id
+		INNER JOIN project_appuser pa ON pa.project_internal_id = p.internal_id
+		WHERE pa.appuser_internal_id = $1 AND pr.due_on IS NOT NULL
+		ORDER BY 3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := []*CalendarEvent{}
+
+	for rows.Next() {
+		var event CalendarEvent
+		err := rows.Scan(&event.UID, &event.Summary, &event.Date, &event.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -11,16 +11,118 @@ var (
 	ErrZeroRowInserted = errors.New("no row inserted")
 )
 
+// Models holds the stores used by the handlers. NewModels backs them with
+// Postgres; tests and alternative backends can fill the fields directly, or
+// replace some of them with an Option.
 type Models struct {
-	Client   ClientModel
-	Proposal ProposalModel
-	Project  ProjectModel
+	Client       ClientStore
+	Proposal     ProposalStore
+	Project      ProjectStore
+	Notification NotificationStore
+	Preference   NotificationPreferenceStore
+	Token        TokenStore
+	User         UserStore
+	Permission   PermissionStore
+	File         FileStore
+	Activity     ActivityStore
+	Organization OrganizationStore
+	OrgSettings  OrgSettingsStore
+	WorkRules    WorkRulesStore
+	CodePolicy   CodePolicyStore
+	Team         TeamStore
+	Delegation   DelegationStore
+	ApprovalStep ApprovalStepStore
+	ExchangeRate ExchangeRateStore
+	Milestone    MilestoneStore
+	Accounting   AccountingMappingStore
+	Timesheet    TimesheetStore
+	Export       ExportStore
+	Audit        AuditStore
+	CustomField  CustomFieldStore
+	Tag          TagStore
+	Budget       BudgetStore
+	Digest       DigestStore
+	Assignment   AssignmentStore
+	Health       HealthStore
+	Leave        LeaveStore
+	Holiday      HolidayStore
+	Planning     PlanningStore
+	Allocation   AllocationStore
+	Sync         SyncStore
+	Schedule     ScheduleStore
+	Typeahead    TypeaheadStore
+	Role         RoleStore
+	Security     SecurityEventStore
+	LDAP         LDAPStore
+
+	db     *sql.DB
+	config QueryConfig
+	opts   []Option
+}
+
+// Option adjusts the models NewModels returns, such as to swap a store for
+// one of the fakes in package datamock. Options are applied again to the
+// copies WithTx binds to a transaction, so swapped stores stay swapped.
+type Option func(*Models)
+
+func NewModels(db *sql.DB, cfg QueryConfig, opts ...Option) Models {
+	m := newModels(db, cfg)
+	m.db = db
+	m.config = cfg
+	m.opts = opts
+	m.apply()
+	return m
 }
 
-func NewModels(db *sql.DB) Models {
+func (m *Models) apply() {
+	for _, opt := range m.opts {
+		opt(m)
+	}
+}
+
+func newModels(conn DBTX, cfg QueryConfig) Models {
+	db := cfg.wrap(conn)
+	read := cfg.reader(conn)
+
 	return Models{
-		Client:   ClientModel{DB: db},
-		Proposal: ProposalModel{DB: db},
-		Project:  ProjectModel{DB: db},
+		Client:       ClientModel{DB: db, ReadDB: read, Timeout: cfg.timeout("client")},
+		Proposal:     ProposalModel{DB: db, Timeout: cfg.timeout("proposal")},
+		Project:      ProjectModel{DB: db, ReadDB: read, Timeout: cfg.timeout("project")},
+		Notification: NotificationModel{DB: db, Timeout: cfg.timeout("notification")},
+		Preference:   NotificationPreferenceModel{DB: db, Timeout: cfg.timeout("preference")},
+		Token:        TokenModel{DB: db, Timeout: cfg.timeout("token")},
+		User:         UserModel{DB: db, ReadDB: read, Timeout: cfg.timeout("user")},
+		Permission:   PermissionModel{DB: db, Timeout: cfg.timeout("permission")},
+		File:         FileModel{DB: db, Timeout: cfg.timeout("file")},
+		Activity:     ActivityModel{DB: db, Timeout: cfg.timeout("activity")},
+		Organization: OrganizationModel{DB: db, Timeout: cfg.timeout("organization")},
+		OrgSettings:  OrgSettingsModel{DB: db, Timeout: cfg.timeout("org_settings")},
+		WorkRules:    WorkRulesModel{DB: db, Timeout: cfg.timeout("work_rules")},
+		CodePolicy:   CodePolicyModel{DB: db, Timeout: cfg.timeout("code_policy")},
+		Team:         TeamModel{DB: db, Timeout: cfg.timeout("team")},
+		Delegation:   DelegationModel{DB: db, Timeout: cfg.timeout("delegation")},
+		ApprovalStep: ApprovalStepModel{DB: db, Timeout: cfg.timeout("approval_step")},
+		ExchangeRate: ExchangeRateModel{DB: db, Timeout: cfg.timeout("exchange_rate")},
+		Milestone:    MilestoneModel{DB: db, Timeout: cfg.timeout("milestone")},
+		Accounting:   AccountingMappingModel{DB: db, Timeout: cfg.timeout("accounting")},
+		Timesheet:    TimesheetModel{DB: db, ReadDB: read, Timeout: cfg.timeout("timesheet")},
+		Export:       ExportModel{DB: db, ReadDB: read, Timeout: cfg.timeout("export")},
+		Audit:        AuditModel{DB: db, Timeout: cfg.timeout("audit")},
+		CustomField:  CustomFieldModel{DB: db, Timeout: cfg.timeout("custom_field")},
+		Tag:          TagModel{DB: db, Timeout: cfg.timeout("tag")},
+		Budget:       BudgetModel{DB: db, Timeout: cfg.timeout("budget")},
+		Digest:       DigestModel{DB: db, ReadDB: read, Timeout: cfg.timeout("digest")},
+		Assignment:   AssignmentModel{DB: db, Timeout: cfg.timeout("assignment")},
+		Health:       HealthModel{DB: db, ReadDB: read, Timeout: cfg.timeout("health")},
+		Leave:        LeaveModel{DB: db, Timeout: cfg.timeout("leave")},
+		Holiday:      HolidayModel{DB: db, Timeout: cfg.timeout("holiday")},
+		Planning:     PlanningModel{DB: db, ReadDB: read, Timeout: cfg.timeout("planning")},
+		Allocation:   AllocationModel{DB: db, Timeout: cfg.timeout("allocation")},
+		Sync:         SyncModel{DB: db, ReadDB: read, Timeout: cfg.timeout("sync")},
+		Schedule:     ScheduleModel{DB: db, Timeout: cfg.timeout("schedule")},
+		Typeahead:    TypeaheadModel{DB: db, ReadDB: read, Timeout: cfg.timeout("typeahead")},
+		Role:         RoleModel{DB: db, Timeout: cfg.timeout("role")},
+		Security:     SecurityEventModel{DB: db, Timeout: cfg.timeout("security_event")},
+		LDAP:         LDAPModel{DB: db, Timeout: cfg.timeout("ldap")},
 	}
 }
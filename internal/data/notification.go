@@ -0,0 +1,188 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+const (
+	NotificationApproval   = "approval"
+	NotificationAssignment = "assignment"
+	NotificationMention    = "mention"
+)
+
+var NotificationCategories = []string{NotificationApproval, NotificationAssignment, NotificationMention}
+
+// NotificationChannel is the PostgreSQL channel every stored notification is
+// announced on, so API instances can push it to the user's open connections.
+const NotificationChannel = "notification"
+
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int32      `json:"-"`
+	Category  string     `json:"category"`
+	Message   string     `json:"message"`
+	Link      *string    `json:"link"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ParseNotificationEvent decodes the payload of a NotificationChannel event.
+func ParseNotificationEvent(payload string) (*Notification, error) {
+	var event struct {
+		UserID int32 `json:"user_id"`
+		Notification
+	}
+
+	err := json.Unmarshal([]byte(payload), &event)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Notification.UserID = event.UserID
+
+	return &event.Notification, nil
+}
+
+func ValidateNotification(v *validator.Validator, n *Notification) {
+	v.Check(n.UserID > 0, "user_id", "must be provided")
+	v.Check(validator.PermittedValue(n.Category, NotificationCategories...), "category", "invalid category")
+	v.Check(n.Message != "", "message", "must be provided")
+	v.Check(len(n.Message) <= 1000, "message", "must not be more than 1000 bytes long")
+}
+
+type NotificationModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m NotificationModel) Insert(n *Notification) error {
+	query := `
+		INSERT INTO notification (appuser_internal_id, category, message, link)
+		VALUES ($1, $2, $3, $4)
+		RETURNING internal_id, created_at`
+
+	args := []any{n.UserID, n.Category, n.Message, n.Link}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&n.ID, &n.CreatedAt)
+}
+
+func (m NotificationModel) GetAllForUser(userID int32, unreadOnly bool, filters Filters) ([]*Notification, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), internal_id, appuser_internal_id, category, message, link, read_at, created_at
+		FROM notification
+		WHERE appuser_internal_id = $1 AND (read_at IS NULL OR NOT $2)
+		ORDER BY %s, internal_id DESC`, filters.orderBy())
+
+	args := []any{userID, unreadOnly}
+
+	if filters.limit() > 0 {
+		query += `
+		LIMIT $3 OFFSET $4`
+		args = append(args, filters.limit(), filters.offset())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	notifications := []*Notification{}
+
+	for rows.Next() {
+		var n Notification
+		err := rows.Scan(
+			&totalRecords,
+			&n.ID,
+			&n.UserID,
+			&n.Category,
+			&n.Message,
+			&n.Link,
+			&n.ReadAt,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		notifications = append(notifications, &n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return notifications, metadata, nil
+}
+
+func (m NotificationModel) CountUnread(userID int32) (int, error) {
+	query := `
+		SELECT count(*)
+		FROM notification
+		WHERE appuser_internal_id = $1 AND read_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (m NotificationModel) MarkRead(userID int32, id int64) error {
+	query := `
+		UPDATE notification
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE internal_id = $1 AND appuser_internal_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m NotificationModel) MarkAllRead(userID int32) (int64, error) {
+	query := `
+		UPDATE notification
+		SET read_at = NOW()
+		WHERE appuser_internal_id = $1 AND read_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
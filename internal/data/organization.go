@@ -0,0 +1,206 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// DefaultOrganizationID is the organization rows belong to when created
+// without one, as projects are.
+const DefaultOrganizationID int32 = 1
+
+var (
+	ErrDuplicateOrganizationName = errors.New("duplicate organization name")
+	ErrOrganizationInUse         = errors.New("organization in use")
+)
+
+type Organization struct {
+	InternalID   int32     `json:"id"`
+	Name         string    `json:"name"`
+	BaseCurrency string    `json:"base_currency"`
+	Version      int32     `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func ValidateOrganization(v *validator.Validator, org *Organization) {
+	v.Check(org.Name != "", "name", "must be provided")
+	v.Check(len(org.Name) <= 500, "name", "must not be more than 500 bytes long")
+	ValidateCurrency(v, "base_currency", org.BaseCurrency)
+}
+
+type OrganizationModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m OrganizationModel) Insert(org *Organization) error {
+	query := `
+		INSERT INTO organization (name, base_currency)
+		VALUES ($1, $2)
+		RETURNING internal_id, version, created_at, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, org.Name, org.BaseCurrency).Scan(
+		&org.InternalID,
+		&org.Version,
+		&org.CreatedAt,
+		&org.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "organization_name_key"`:
+			return ErrDuplicateOrganizationName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrganizationModel) Get(id int32) (*Organization, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT internal_id, name, base_currency, version, created_at, updated_at
+		FROM organization
+		WHERE internal_id = $1`
+
+	var org Organization
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&org.InternalID,
+		&org.Name,
+		&org.BaseCurrency,
+		&org.Version,
+		&org.CreatedAt,
+		&org.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &org, nil
+}
+
+func (m OrganizationModel) GetAll() ([]*Organization, error) {
+	query := `
+		SELECT internal_id, name, base_currency, version, created_at, updated_at
+		FROM organization
+		ORDER BY internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orgs := []*Organization{}
+
+	for rows.Next() {
+		var org Organization
+		err := rows.Scan(
+			&org.InternalID,
+			&org.Name,
+			&org.BaseCurrency,
+			&org.Version,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, &org)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (m OrganizationModel) Update(org *Organization) error {
+	query := `
+		UPDATE organization
+		SET name = $1, base_currency = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $3 AND version = $4
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, org.Name, org.BaseCurrency, org.InternalID, org.Version).Scan(&org.Version, &org.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "organization_name_key"`:
+			return ErrDuplicateOrganizationName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes an organization. Organizations that still own users,
+// clients or projects are kept and ErrOrganizationInUse is returned.
+func (m OrganizationModel) Delete(id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM organization
+		WHERE internal_id = $1
+		AND NOT EXISTS (SELECT 1 FROM appuser WHERE org_internal_id = $1)
+		AND NOT EXISTS (SELECT 1 FROM client WHERE org_internal_id = $1)
+		AND NOT EXISTS (SELECT 1 FROM project WHERE org_internal_id = $1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		_, err := m.Get(id)
+		if err != nil {
+			return err
+		}
+		return ErrOrganizationInUse
+	}
+
+	return nil
+}
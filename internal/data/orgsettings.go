@@ -0,0 +1,165 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// OrgSettings is how an organization's emails look and who they come from.
+// Empty fields keep the defaults. Settings never saved have version 0 and
+// no updated_at.
+type OrgSettings struct {
+	OrgID        int32      `json:"organization_id"`
+	SenderName   string     `json:"sender_name"`
+	SenderEmail  string     `json:"sender_email"`
+	ReplyTo      string     `json:"reply_to"`
+	LogoURL      string     `json:"logo_url"`
+	PrimaryColor string     `json:"primary_color"`
+	AccentColor  string     `json:"accent_color"`
+	Version      int32      `json:"version"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+}
+
+func ValidateOrgSettings(v *validator.Validator, s *OrgSettings) {
+	v.Check(len(s.SenderName) <= 100, "sender_name", "must not be more than 100 bytes long")
+
+	if s.SenderEmail != "" {
+		v.Check(validator.Matches(s.SenderEmail, validator.EmailRX), "sender_email", "must be a valid email address")
+	}
+
+	if s.ReplyTo != "" {
+		v.Check(validator.Matches(s.ReplyTo, validator.EmailRX), "reply_to", "must be a valid email address")
+	}
+
+	if s.LogoURL != "" {
+		u, err := url.Parse(s.LogoURL)
+		v.Check(err == nil && u.Scheme == "https" && u.Host != "", "logo_url", "must be an https URL")
+		v.Check(len(s.LogoURL) <= 2000, "logo_url", "must not be more than 2000 bytes long")
+	}
+
+	if s.PrimaryColor != "" {
+		v.Check(validator.Matches(s.PrimaryColor, validator.ColorRX), "primary_color", "must be a hex color such as #1a56db")
+	}
+
+	if s.AccentColor != "" {
+		v.Check(validator.Matches(s.AccentColor, validator.ColorRX), "accent_color", "must be a hex color such as #1a56db")
+	}
+}
+
+type OrgSettingsModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Get returns an organization's settings, empty when it has saved none.
+func (m OrgSettingsModel) Get(orgID int32) (*OrgSettings, error) {
+	query := `
+		SELECT sender_name, sender_email, reply_to, logo_url, primary_color, accent_color, version, updated_at
+		FROM org_settings
+		WHERE org_internal_id = $1`
+
+	s := OrgSettings{OrgID: orgID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, orgID).Scan(
+		&s.SenderName,
+		&s.SenderEmail,
+		&s.ReplyTo,
+		&s.LogoURL,
+		&s.PrimaryColor,
+		&s.AccentColor,
+		&s.Version,
+		&s.UpdatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// GetForUser returns the settings of the organization a user belongs to.
+func (m OrgSettingsModel) GetForUser(userID int32) (*OrgSettings, error) {
+	query := `
+		SELECT u.org_internal_id,
+			COALESCE(s.sender_name, ''), COALESCE(s.sender_email, ''), COALESCE(s.reply_to, ''),
+			COALESCE(s.logo_url, ''), COALESCE(s.primary_color, ''), COALESCE(s.accent_color, ''),
+			COALESCE(s.version, 0), s.updated_at
+		FROM appuser u
+		LEFT JOIN org_settings s ON s.org_internal_id = u.org_internal_id
+		WHERE u.internal_id = $1`
+
+	var s OrgSettings
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(
+		&s.OrgID,
+		&s.SenderName,
+		&s.SenderEmail,
+		&s.ReplyTo,
+		&s.LogoURL,
+		&s.PrimaryColor,
+		&s.AccentColor,
+		&s.Version,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &s, nil
+}
+
+// Update saves an organization's settings, creating them when s.Version is
+// 0, provided they are still at s.Version.
+func (m OrgSettingsModel) Update(s *OrgSettings) error {
+	query := `
+		INSERT INTO org_settings (org_internal_id, sender_name, sender_email, reply_to, logo_url, primary_color, accent_color)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (org_internal_id) DO UPDATE
+		SET sender_name = EXCLUDED.sender_name, sender_email = EXCLUDED.sender_email, reply_to = EXCLUDED.reply_to,
+			logo_url = EXCLUDED.logo_url, primary_color = EXCLUDED.primary_color, accent_color = EXCLUDED.accent_color,
+			version = org_settings.version + 1, updated_at = NOW()
+		WHERE org_settings.version = $8
+		RETURNING version, updated_at`
+
+	args := []any{
+		s.OrgID,
+		s.SenderName,
+		s.SenderEmail,
+		s.ReplyTo,
+		s.LogoURL,
+		s.PrimaryColor,
+		s.AccentColor,
+		s.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&s.Version, &s.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
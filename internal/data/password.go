@@ -0,0 +1,88 @@
+package data
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords are rejected whatever the policy.
+var commonPasswords = ParseDenylist(commonPasswordList)
+
+// PasswordClasses are the character classes a password policy can require,
+// with what a password lacking one is told.
+var PasswordClasses = map[string]string{
+	"lower":  "must contain a lowercase letter",
+	"upper":  "must contain an uppercase letter",
+	"digit":  "must contain a digit",
+	"symbol": "must contain a symbol",
+}
+
+// PasswordPolicy is what passwords must satisfy beyond the 72 byte limit of
+// bcrypt. Breached, when set, returns how often a password appears in known
+// data breaches.
+type PasswordPolicy struct {
+	MinLength int
+	Require   []string
+	Denylist  map[string]bool
+	Breached  func(password string) (int, error)
+}
+
+// ParseDenylist reads one password per line, ignoring blank lines and case.
+func ParseDenylist(list string) map[string]bool {
+	denylist := make(map[string]bool)
+
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			denylist[strings.ToLower(line)] = true
+		}
+	}
+
+	return denylist
+}
+
+// ValidatePasswordPlaintext checks password against policy, adding the first
+// rule it breaks to v. The breach check only runs once every other rule
+// passes; when it fails the password is not held against it and the error is
+// returned for the caller to log.
+func ValidatePasswordPlaintext(v *validator.Validator, password string, policy PasswordPolicy) error {
+	v.Check(password != "", "password", "must be provided")
+	v.Check(utf8.RuneCountInString(password) >= policy.MinLength, "password", fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	v.Check(len(password) <= 72, "password", "must not be more than 72 bytes long")
+
+	lower := strings.ToLower(password)
+	v.Check(!commonPasswords[lower] && !policy.Denylist[lower], "password", "is too common, choose a less predictable one")
+
+	for _, class := range policy.Require {
+		v.Check(strings.IndexFunc(password, passwordClassFuncs[class]) >= 0, "password", PasswordClasses[class])
+	}
+
+	if _, invalid := v.Errors["password"]; invalid || policy.Breached == nil {
+		return nil
+	}
+
+	count, err := policy.Breached(password)
+	if err != nil {
+		return err
+	}
+
+	v.Check(count == 0, "password", fmt.Sprintf("has appeared in %d known data breaches, choose another", count))
+
+	return nil
+}
+
+var passwordClassFuncs = map[string]func(rune) bool{
+	"lower": unicode.IsLower,
+	"upper": unicode.IsUpper,
+	"digit": unicode.IsDigit,
+	"symbol": func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r)
+	},
+}
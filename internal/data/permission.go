@@ -0,0 +1,169 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Permissions holds permission codes such as "project:read".
+type Permissions []string
+
+func (p Permissions) Include(code string) bool {
+	return slices.Contains(p, code)
+}
+
+// IncludeAll reports whether p holds every permission in codes.
+func (p Permissions) IncludeAll(codes Permissions) bool {
+	for _, code := range codes {
+		if !p.Include(code) {
+			return false
+		}
+	}
+	return true
+}
+
+type PermissionModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m PermissionModel) GetAllForUser(userID int32) (Permissions, error) {
+	query := `
+		SELECT p.code
+		FROM permission p
+		INNER JOIN appuser_permission ap ON ap.permission_internal_id = p.internal_id
+		WHERE ap.user_internal_id = $1
+		ORDER BY p.code`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var permissions Permissions
+
+	for rows.Next() {
+		var permission string
+
+		err := rows.Scan(&permission)
+		if err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// GetActor loads the organization and permissions of a user.
+func (m PermissionModel) GetActor(userID int32) (Actor, error) {
+	query := `
+		SELECT u.org_internal_id, ARRAY(
+			SELECT p.code
+			FROM permission p
+			INNER JOIN appuser_permission ap ON ap.permission_internal_id = p.internal_id
+			WHERE ap.user_internal_id = u.internal_id
+			ORDER BY p.code
+		)
+		FROM appuser u
+		WHERE u.internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	actor := Actor{UserID: userID}
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&actor.OrgID, pq.Array(&actor.Permissions))
+	if err != nil {
+		return Actor{}, err
+	}
+
+	return actor, nil
+}
+
+// GetActorClaims loads what GetActor does along with the user's claims
+// version, which is bumped whenever their permissions or organization
+// change, so access tokens embedding the actor can be invalidated.
+func (m PermissionModel) GetActorClaims(userID int32) (Actor, int32, error) {
+	query := `
+		SELECT u.org_internal_id, ARRAY(
+			SELECT p.code
+			FROM permission p
+			INNER JOIN appuser_permission ap ON ap.permission_internal_id = p.internal_id
+			WHERE ap.user_internal_id = u.internal_id
+			ORDER BY p.code
+		), u.claims_version
+		FROM appuser u
+		WHERE u.internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	actor := Actor{UserID: userID}
+	var version int32
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&actor.OrgID, pq.Array(&actor.Permissions), &version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return Actor{}, 0, ErrRecordNotFound
+		default:
+			return Actor{}, 0, err
+		}
+	}
+
+	return actor, version, nil
+}
+
+// GetClaimsVersions returns the claims version of each user whose claims
+// changed after since, keyed by user ID.
+func (m PermissionModel) GetClaimsVersions(since time.Time) (map[int32]int32, error) {
+	query := `
+		SELECT internal_id, claims_version
+		FROM appuser
+		WHERE claims_changed_at > $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	versions := make(map[int32]int32)
+
+	for rows.Next() {
+		var userID, version int32
+
+		err := rows.Scan(&userID, &version)
+		if err != nil {
+			return nil, err
+		}
+
+		versions[userID] = version
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,271 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UserCapacity is a user's available and committed hours week by week.
+type UserCapacity struct {
+	UserID    int32  `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	// AssignedProjects lists the active projects the user is assigned to,
+	// whether or not time is allocated on them.
+	AssignedProjects []int32        `json:"assigned_projects"`
+	Weeks            []CapacityWeek `json:"weeks"`
+}
+
+// CapacityWeek is one week of a user's capacity. Available hours are the
+// working days left after holidays and leave at the standard daily hours;
+// committed hours are the hours allocated to projects.
+type CapacityWeek struct {
+	Week           time.Time            `json:"week"`
+	HolidayDays    int                  `json:"holiday_days"`
+	LeaveDays      int                  `json:"leave_days"`
+	AvailableHours float64              `json:"available_hours"`
+	CommittedHours float64              `json:"committed_hours"`
+	RemainingHours float64              `json:"remaining_hours"`
+	Allocations    []CapacityAllocation `json:"allocations"`
+}
+
+type CapacityAllocation struct {
+	ProjectID int32   `json:"project_id"`
+	Name      string  `json:"name"`
+	Hours     float64 `json:"hours"`
+	// Percent is Hours as a share of the week's available hours, or nil when
+	// the user has none that week.
+	Percent *float64 `json:"percent"`
+}
+
+// workingDays is the number of days in a week capacity is counted on,
+// Monday to Friday.
+const workingDays = 5
+
+type PlanningModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// GetCapacity returns the capacity of the activated users in the actor's
+// organization, limited to a team when teamID is non-zero, for every week
+// from the one holding from to the one holding to. A full week is
+// weeklyHours over five working days.
+func (m PlanningModel) GetCapacity(actor Actor, teamID int32, from, to time.Time, weeklyHours float64) ([]*UserCapacity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	db := readDB(m.ReadDB, m.DB)
+
+	first, last := weekStart(from), weekStart(to)
+	end := last.AddDate(0, 0, 6)
+
+	query := `
+		SELECT internal_id, first_name, last_name
+		FROM appuser
+		WHERE activated
+		AND ($1 = 0 OR org_internal_id = $1)
+		AND ($2 = 0 OR internal_id IN (
+			SELECT user_internal_id FROM team_member WHERE team_internal_id = $2
+		))
+		ORDER BY last_name, first_name, internal_id`
+
+	rows, err := db.QueryContext(ctx, query, actor.OrgID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	capacities := []*UserCapacity{}
+	byUser := make(map[int32]*UserCapacity)
+	ids := []int32{}
+
+	for rows.Next() {
+		c := UserCapacity{AssignedProjects: []int32{}}
+
+		err := rows.Scan(&c.UserID, &c.FirstName, &c.LastName)
+		if err != nil {
+			return nil, err
+		}
+
+		for week := first; !week.After(last); week = week.AddDate(0, 0, 7) {
+			c.Weeks = append(c.Weeks, CapacityWeek{Week: week, Allocations: []CapacityAllocation{}})
+		}
+
+		capacities = append(capacities, &c)
+		byUser[c.UserID] = &c
+		ids = append(ids, c.UserID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return capacities, nil
+	}
+
+	// A day off counts once, as a holiday when it is one even if the user
+	// also took leave.
+	query = `
+		SELECT user_id, day, bool_or(holiday)
+		FROM (
+			SELECT u.internal_id AS user_id, h.day, true AS holiday
+			FROM holiday h
+			INNER JOIN appuser u ON u.org_internal_id = h.org_internal_id
+			WHERE u.internal_id = ANY($1) AND h.day BETWEEN $2 AND $3
+			UNION ALL
+			SELECT l.user_internal_id, d::date, false
+			FROM user_leave l
+			CROSS JOIN generate_series(GREATEST(l.starts_on, $2::date), LEAST(l.ends_on, $3::date), interval '1 day') d
+			WHERE l.user_internal_id = ANY($1) AND l.ends_on >= $2 AND l.starts_on <= $3
+		) off
+		WHERE EXTRACT(ISODOW FROM day) <= 5
+		GROUP BY user_id, day`
+
+	rows, err = db.QueryContext(ctx, query, pq.Array(ids), first, end)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int32
+		var day time.Time
+		var holiday bool
+
+		err := rows.Scan(&userID, &day, &holiday)
+		if err != nil {
+			return nil, err
+		}
+
+		week := &byUser[userID].Weeks[weekIndex(first, day)]
+		if holiday {
+			week.HolidayDays++
+		} else {
+			week.LeaveDays++
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, c := range capacities {
+		for i := range c.Weeks {
+			week := &c.Weeks[i]
+			week.AvailableHours = float64(workingDays-week.HolidayDays-week.LeaveDays) * weeklyHours / workingDays
+			week.RemainingHours = week.AvailableHours
+		}
+	}
+
+	query = `
+		SELECT a.user_internal_id, p.project_id, COALESCE(p.name, ''), a.week, a.hours::float8
+		FROM allocation a
+		INNER JOIN project p ON p.internal_id = a.project_internal_id
+		WHERE a.user_internal_id = ANY($1) AND a.week BETWEEN $2 AND $3 AND p.deleted_at IS NULL
+		ORDER BY a.week, p.project_id`
+
+	rows, err = db.QueryContext(ctx, query, pq.Array(ids), first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int32
+		var day time.Time
+		var a CapacityAllocation
+
+		err := rows.Scan(&userID, &a.ProjectID, &a.Name, &day, &a.Hours)
+		if err != nil {
+			return nil, err
+		}
+
+		week := &byUser[userID].Weeks[weekIndex(first, day)]
+		if week.AvailableHours > 0 {
+			a.Percent = ptr(a.Hours / week.AvailableHours * 100)
+		}
+		week.Allocations = append(week.Allocations, a)
+		week.CommittedHours += a.Hours
+		week.RemainingHours -= a.Hours
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT pa.appuser_internal_id, p.project_id
+		FROM project_appuser pa
+		INNER JOIN project p ON p.internal_id = pa.project_internal_id
+		WHERE pa.appuser_internal_id = ANY($1) AND p.archived_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY p.project_id`
+
+	rows, err = db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, projectID int32
+
+		err := rows.Scan(&userID, &projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		byUser[userID].AssignedProjects = append(byUser[userID].AssignedProjects, projectID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return capacities, nil
+}
+
+// AvailableHours returns the hours a user can be planned for in the week
+// starting on week: weeklyHours less their holidays and leave that week.
+func (m PlanningModel) AvailableHours(userID int32, week time.Time, weeklyHours float64) (float64, error) {
+	query := `
+		SELECT count(DISTINCT day)
+		FROM (
+			SELECT h.day
+			FROM holiday h
+			INNER JOIN appuser u ON u.org_internal_id = h.org_internal_id
+			WHERE u.internal_id = $1 AND h.day BETWEEN $2 AND $3
+			UNION ALL
+			SELECT d::date
+			FROM user_leave l
+			CROSS JOIN generate_series(GREATEST(l.starts_on, $2::date), LEAST(l.ends_on, $3::date), interval '1 day') d
+			WHERE l.user_internal_id = $1 AND l.ends_on >= $2 AND l.starts_on <= $3
+		) off
+		WHERE EXTRACT(ISODOW FROM day) <= 5`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var daysOff int
+
+	err := m.DB.QueryRowContext(ctx, query, userID, week, week.AddDate(0, 0, 6)).Scan(&daysOff)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(workingDays-daysOff) * weeklyHours / workingDays, nil
+}
+
+// weekIndex returns the week day falls in, counted from the one starting on
+// first.
+func weekIndex(first, day time.Time) int {
+	return int(truncateDay(day).Sub(first).Hours()/24) / 7
+}
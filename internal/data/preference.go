@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+type NotificationPreference struct {
+	Category     string    `json:"category"`
+	EmailEnabled bool      `json:"email_enabled"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func ValidateNotificationPreference(v *validator.Validator, p *NotificationPreference) {
+	v.Check(validator.PermittedValue(p.Category, NotificationCategories...), "category", "invalid category")
+}
+
+type NotificationPreferenceModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// GetAllForUser returns one preference per category. Categories the user has
+// never touched are reported as enabled.
+func (m NotificationPreferenceModel) GetAllForUser(userID int32) ([]*NotificationPreference, error) {
+	query := `
+		SELECT c.category, COALESCE(np.email_enabled, TRUE), COALESCE(np.updated_at, NOW())
+		FROM unnest($1::text[]) AS c(category)
+		LEFT JOIN notification_preference np
+			ON np.category = c.category AND np.appuser_internal_id = $2
+		ORDER BY c.category`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(NotificationCategories), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	preferences := []*NotificationPreference{}
+
+	for rows.Next() {
+		var p NotificationPreference
+		err := rows.Scan(&p.Category, &p.EmailEnabled, &p.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		preferences = append(preferences, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (m NotificationPreferenceModel) Upsert(userID int32, p *NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preference (appuser_internal_id, category, email_enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (appuser_internal_id, category)
+		DO UPDATE SET email_enabled = EXCLUDED.email_enabled, updated_at = NOW()
+		RETURNING updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, userID, p.Category, p.EmailEnabled).Scan(&p.UpdatedAt)
+}
+
+// EmailEnabled is consulted before dispatching a notification email.
+func (m NotificationPreferenceModel) EmailEnabled(userID int32, category string) (bool, error) {
+	query := `
+		SELECT email_enabled
+		FROM notification_preference
+		WHERE appuser_internal_id = $1 AND category = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var enabled bool
+	err := m.DB.QueryRowContext(ctx, query, userID, category).Scan(&enabled)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return true, nil
+		default:
+			return false, err
+		}
+	}
+
+	return enabled, nil
+}
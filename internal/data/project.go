@@ -3,7 +3,6 @@ package data
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hwanbin/wanpm-api/internal/codefmt"
 	"github.com/hwanbin/wanpm-api/internal/s3action"
 	"github.com/hwanbin/wanpm-api/internal/validator"
 	"github.com/lib/pq"
@@ -28,49 +28,67 @@ type Point struct {
 }
 
 type ProjectClient struct {
-	ClientID      *int32  `json:"id"`
-	ClientName    *string `json:"name"`
-	ClientLogo    *string `json:"logo_url"`
-	ClientAddress *string `json:"address"`
-	ClientNote    *string `json:"note"`
+	ClientID      *int32   `json:"id"`
+	ClientName    *string  `json:"name"`
+	ClientLogo    *string  `json:"logo_url"`
+	ClientAddress *string  `json:"address"`
+	ClientNote    *string  `json:"note"`
+	Longitude     *float64 `json:"longitude"`
+	Latitude      *float64 `json:"latitude"`
 }
 
 type ProjectInput struct {
-	ExternalID  *int32   `json:"project_id"`
-	ProposalID  *string  `json:"proposal_id"`
-	Name        *string  `json:"name"`
-	Status      *string  `json:"status"`
-	Feature     *Feature `json:"feature"`
-	Images      []string `json:"images"`
-	ClientNames []string `json:"client_names"`
+	ExternalID   *int32       `json:"project_id"`
+	ProposalID   *string      `json:"proposal_id"`
+	Name         *string      `json:"name"`
+	Status       *string      `json:"status"`
+	Feature      *Feature     `json:"feature"`
+	Images       []string     `json:"images"`
+	ClientNames  []string     `json:"client_names"`
+	CustomFields CustomValues `json:"custom_fields"`
 }
 
 type ProjectRequest struct {
-	InternalID int32           `json:"-"`
-	ExternalID *int32          `json:"project_id"`
-	ProposalID *string         `json:"proposal_id"`
-	Name       *string         `json:"name"`
-	Status     *string         `json:"status"`
-	Feature    *string         `json:"feature"`
-	Images     []string        `json:"images"`
-	Clients    []ProjectClient `json:"clients"`
-	Version    int32           `json:"version"`
-	CreatedAt  time.Time       `json:"created_at"`
-	UpdatedAt  time.Time       `json:"updated_at"`
+	InternalID   int32           `json:"-"`
+	ExternalID   *int32          `json:"project_id"`
+	ProposalID   *string         `json:"proposal_id"`
+	Name         *string         `json:"name"`
+	Status       *string         `json:"status"`
+	Feature      *string         `json:"feature"`
+	Images       []string        `json:"images"`
+	Clients      []ProjectClient `json:"clients"`
+	CustomFields CustomValues    `json:"custom_fields"`
+	OrgID        int32           `json:"-"`
+	Version      int32           `json:"version"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
 }
 
 type ProjectResponse struct {
-	InternalID int32           `json:"-"`
-	ExternalID *int32          `json:"project_id"`
-	ProposalID *string         `json:"proposal_id"`
-	Name       *string         `json:"name"`
-	Status     *string         `json:"status"`
-	Feature    *Feature        `json:"feature"`
-	Images     []string        `json:"images"`
-	Clients    []ProjectClient `json:"clients"`
-	Version    int32           `json:"version"`
-	CreatedAt  time.Time       `json:"created_at"`
-	UpdatedAt  time.Time       `json:"updated_at"`
+	InternalID   int32           `json:"-"`
+	ExternalID   *int32          `json:"project_id"`
+	ProposalID   *string         `json:"proposal_id"`
+	Name         *string         `json:"name"`
+	Status       *string         `json:"status"`
+	Feature      *Feature        `json:"feature"`
+	Images       []string        `json:"images"`
+	Clients      []ProjectClient `json:"clients"`
+	StorageBytes int64           `json:"storage_bytes" doc:"Bytes stored under the project's file prefix, as last measured."`
+	CustomFields CustomValues    `json:"custom_fields"`
+	Tags         []string        `json:"tags"`
+	ArchivedAt   *time.Time      `json:"archived_at"`
+	Health       *ProjectHealth  `json:"health,omitempty"`
+	Version      int32           `json:"version"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// ProjectSummary is the slim form of a project that pickers list.
+type ProjectSummary struct {
+	ExternalID int32   `json:"project_id"`
+	ProposalID string  `json:"proposal_id"`
+	Name       *string `json:"name"`
+	Status     *string `json:"status"`
 }
 
 type ProjectQsInput struct {
@@ -82,9 +100,39 @@ type ProjectQsInput struct {
 	ClientName  string
 	Clients     []string
 	Bbox        []string
+	// CustomFields keeps projects holding every one of these values.
+	CustomFields CustomValues
+	// Tags keeps projects carrying every one of these tags.
+	Tags []string
+	// IncludeArchived lists archived projects alongside active ones.
+	IncludeArchived bool
 	Filters
 }
 
+func ConvertToIDs(idStrings []string) ([]int32, error) {
+	ids := make([]int32, 0, len(idStrings))
+
+	for i, str := range idStrings {
+		id, err := strconv.ParseInt(str, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error near comma %d: %w", i, err)
+		}
+		ids = append(ids, int32(id))
+	}
+
+	return ids, nil
+}
+
+func ValidateProjectIDs(v *validator.Validator, ids []int32) {
+	v.Check(len(ids) >= 1, "ids", "must contain at least 1 id")
+	v.Check(len(ids) <= 100, "ids", "must not contain more than 100 ids")
+	v.Check(validator.Unique(ids), "ids", "must not contain duplicate values")
+
+	for _, id := range ids {
+		v.Check(id > 0, "ids", "must contain only positive integers")
+	}
+}
+
 func ValidateQueryString(v *validator.Validator, qs *ProjectQsInput) {
 	if qs.Bbox != nil {
 		v.Check(len(qs.Bbox) == 4, "bbox", "must have 4 coordinates")
@@ -222,14 +270,51 @@ func ValidateProject(v *validator.Validator, p *ProjectRequest) {
 }
 
 type ProjectModel struct {
-	DB *sql.DB
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
 }
 
 func (m ProjectModel) Insert(project *ProjectRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = insertProject(ctx, tx, project)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertProject inserts the project and its client links in one statement,
+// so a project is never left without the clients it was created with.
+func insertProject(ctx context.Context, tx DBTX, project *ProjectRequest) error {
 	query := `
-		INSERT INTO project (project_id, proposal_id, name, status, feature, images)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING internal_id, version, created_at, updated_at`
+		WITH p AS (
+			INSERT INTO project (project_id, proposal_id, name, status, feature, images, custom_fields, org_internal_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $9)
+			RETURNING internal_id, version, created_at, updated_at
+		), pc AS (
+			INSERT INTO project_client (project_internal_id, client_internal_id)
+			SELECT p.internal_id, c.id
+			FROM p, unnest($8::integer[]) AS c(id)
+			RETURNING client_internal_id
+		)
+		SELECT internal_id, version, created_at, updated_at, (SELECT count(*) FROM pc)
+		FROM p`
+
+	clientIDs := []int32{}
+	for _, client := range project.Clients {
+		clientIDs = append(clientIDs, *client.ClientID)
+	}
+
 	args := []any{
 		project.ExternalID,
 		project.ProposalID,
@@ -237,22 +322,19 @@ func (m ProjectModel) Insert(project *ProjectRequest) error {
 		project.Status,
 		project.Feature,
 		pq.Array(project.Images),
+		project.CustomFields,
+		pq.Array(clientIDs),
+		project.OrgID,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	tx, err := m.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+	var linked int
 
-	err = tx.QueryRowContext(ctx, query, args...).Scan(
+	err := tx.QueryRowContext(ctx, query, args...).Scan(
 		&project.InternalID,
 		&project.Version,
 		&project.CreatedAt,
 		&project.UpdatedAt,
+		&linked,
 	)
 	if err != nil {
 		switch {
@@ -265,91 +347,240 @@ func (m ProjectModel) Insert(project *ProjectRequest) error {
 		}
 	}
 
-	clientIDs := []int32{}
-	for _, client := range project.Clients {
-		clientIDs = append(clientIDs, *client.ClientID)
+	if linked == 0 {
+		return ErrZeroRowInserted
 	}
 
-	query = `
-		INSERT INTO project_client (project_internal_id, client_internal_id)
-		VALUES `
+	return nil
+}
 
-	args = []any{}
-	for i, clientID := range clientIDs {
-		if i > 0 {
-			query += ", "
-		}
-		query += fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2)
-		args = append(args, project.InternalID, clientID)
-	}
+// Import inserts projects into the organization in a single transaction,
+// first creating the named clients. Project clients without an ID are
+// resolved by name against the newly created ones.
+func (m ProjectModel) Import(orgID int32, projects []*ProjectRequest, newClients []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
 
-	result, err := tx.ExecContext(ctx, query, args...)
+	tx, err := beginTx(ctx, m.DB)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
+	created := make(map[string]int32, len(newClients))
+	for _, name := range newClients {
+		var id int32
+		err = tx.QueryRowContext(ctx, `INSERT INTO client (name, org_internal_id) VALUES ($1, $2) RETURNING internal_id`, name, orgID).Scan(&id)
+		if err != nil {
+			return err
+		}
+		created[name] = id
 	}
 
-	if rowsAffected == 0 {
-		return ErrZeroRowInserted
+	for _, project := range projects {
+		project.OrgID = orgID
+
+		for i, client := range project.Clients {
+			if client.ClientID == nil {
+				id, ok := created[*client.ClientName]
+				if !ok {
+					return fmt.Errorf("client %q was neither found nor created", *client.ClientName)
+				}
+				project.Clients[i].ClientID = &id
+			}
+		}
+
+		err = insertProject(ctx, tx, project)
+		if err != nil {
+			return fmt.Errorf("project %d: %w", *project.ExternalID, err)
+		}
 	}
 
-	err = tx.Commit()
+	return tx.Commit()
+}
+
+// GetExistingKeys returns which of the given project and proposal IDs are
+// already taken.
+func (m ProjectModel) GetExistingKeys(externalIDs []int32, proposalIDs []string) (map[int32]bool, map[string]bool, error) {
+	query := `
+		SELECT project_id, proposal_id
+		FROM project
+		WHERE project_id = ANY($1::integer[]) OR proposal_id = ANY($2::text[])`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(externalIDs), pq.Array(proposalIDs))
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return nil
+	defer rows.Close()
+
+	takenIDs := make(map[int32]bool)
+	takenProposals := make(map[string]bool)
+
+	for rows.Next() {
+		var externalID int32
+		var proposalID string
+
+		err := rows.Scan(&externalID, &proposalID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		takenIDs[externalID] = true
+		takenProposals[proposalID] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return takenIDs, takenProposals, nil
+}
+
+// projectFeatureClientsColumns selects a project's feature as plain columns
+// and its clients as one array per column, all in client order, so rows
+// scan into projectColumns without a JSON round trip. It expects the
+// project aliased p, its clients c joined through project_client, and a
+// GROUP BY on the project.
+const projectFeatureClientsColumns = `
+		p.feature->>'type', p.feature->'geometry'->>'type',
+		ARRAY(
+			SELECT jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(p.feature->'geometry'->'coordinates') = 'array' THEN p.feature->'geometry'->'coordinates' END
+			)::float8
+		),
+		p.feature->'properties'->>'name', p.feature->'properties'->>'full_address',
+		array_agg(c.internal_id ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL),
+		array_agg(c.name ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL),
+		array_agg(c.address ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL),
+		array_agg(c.logo_url ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL),
+		array_agg(c.note ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL),
+		array_agg(c.longitude ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL),
+		array_agg(c.latitude ORDER BY c.internal_id) FILTER (WHERE c.internal_id IS NOT NULL)`
+
+// projectColumns receives projectFeatureClientsColumns.
+type projectColumns struct {
+	featureType  sql.NullString
+	geometryType sql.NullString
+	coordinates  pq.Float64Array
+	featureName  sql.NullString
+	fullAddress  sql.NullString
+
+	clientIDs        pq.Int32Array
+	clientNames      []sql.NullString
+	clientAddresses  []sql.NullString
+	clientLogos      []sql.NullString
+	clientNotes      []sql.NullString
+	clientLongitudes []sql.NullFloat64
+	clientLatitudes  []sql.NullFloat64
+}
+
+func (c *projectColumns) dest() []any {
+	return []any{
+		&c.featureType,
+		&c.geometryType,
+		&c.coordinates,
+		&c.featureName,
+		&c.fullAddress,
+		&c.clientIDs,
+		pq.Array(&c.clientNames),
+		pq.Array(&c.clientAddresses),
+		pq.Array(&c.clientLogos),
+		pq.Array(&c.clientNotes),
+		pq.Array(&c.clientLongitudes),
+		pq.Array(&c.clientLatitudes),
+	}
 }
 
-func (m ProjectModel) Get(externalID int32) (*ProjectResponse, error) {
+// assemble sets the feature and clients of project from the scanned
+// columns. A project without a feature keeps a nil Feature, and one
+// without clients nil Clients.
+func (c *projectColumns) assemble(project *ProjectResponse) {
+	if c.featureType.Valid {
+		feature := &Feature{Type: c.featureType.String}
+		feature.Geometry.Type = c.geometryType.String
+		feature.Geometry.Coordinates = c.coordinates
+		feature.Properties.Name = c.featureName.String
+		feature.Properties.FullAddress = c.fullAddress.String
+		project.Feature = feature
+	}
+
+	for i := range c.clientIDs {
+		project.Clients = append(project.Clients, ProjectClient{
+			ClientID:      &c.clientIDs[i],
+			ClientName:    nullStringPtr(c.clientNames[i]),
+			ClientAddress: nullStringPtr(c.clientAddresses[i]),
+			ClientLogo:    nullStringPtr(c.clientLogos[i]),
+			ClientNote:    nullStringPtr(c.clientNotes[i]),
+			Longitude:     nullFloat64Ptr(c.clientLongitudes[i]),
+			Latitude:      nullFloat64Ptr(c.clientLatitudes[i]),
+		})
+	}
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+
+	return &s.String
+}
+
+func nullFloat64Ptr(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+
+	return &f.Float64
+}
+
+// Get returns the project if the actor may see it, as GetAll would list it.
+func (m ProjectModel) Get(actor Actor, externalID int32) (*ProjectResponse, error) {
 	if externalID < 1 {
 		return nil, ErrRecordNotFound
 	}
 
+	scope, scopeArgs := actor.projectScope(2)
+
 	query := `
-		SELECT p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.feature,
-		array_agg(
-			jsonb_build_object(
-				'id', c.internal_id, 
-				'name', c.name, 
-				'address', c.address,
-				'logo_url', c.logo_url,
-				'note', c.note,
-				'version', c.version,
-				'created_at', c.created_at,
-				'updated_at', c.updated_at
-			)
-		) as clients, p.images, p.version, p.created_at, p.updated_at 
+		SELECT p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.images, p.storage_bytes, p.custom_fields,
+		ARRAY(
+			SELECT t.name
+			FROM project_tag pt
+			INNER JOIN tag t ON t.internal_id = pt.tag_internal_id
+			WHERE pt.project_internal_id = p.internal_id
+			ORDER BY t.name
+		) AS tags, p.archived_at, p.version, p.created_at, p.updated_at,` + projectFeatureClientsColumns + `
 		FROM project p
 		LEFT JOIN project_client pc ON p.internal_id = pc.project_internal_id
 		LEFT JOIN client c ON pc.client_internal_id = c.internal_id
-		WHERE p.project_id = $1
-		GROUP BY p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.feature, p.images, p.version, p.created_at, p.updated_at`
+		WHERE p.project_id = $1 AND p.deleted_at IS NULL` + scope + `
+		GROUP BY p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.feature, p.images, p.storage_bytes, p.custom_fields, p.archived_at, p.version, p.created_at, p.updated_at`
 
 	var project ProjectResponse
-	var projectFeature string
-	var clients []string
+	var columns projectColumns
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, externalID).Scan(
+	err := m.DB.QueryRowContext(ctx, query, append([]any{externalID}, scopeArgs...)...).Scan(append([]any{
 		&project.InternalID,
 		&project.ExternalID,
 		&project.ProposalID,
 		&project.Name,
 		&project.Status,
-		&projectFeature,
-		pq.Array(&clients),
 		pq.Array(&project.Images),
+		&project.StorageBytes,
+		&project.CustomFields,
+		pq.Array(&project.Tags),
+		&project.ArchivedAt,
 		&project.Version,
 		&project.CreatedAt,
 		&project.UpdatedAt,
-	)
+	}, columns.dest()...)...)
 
 	if err != nil {
 		switch {
@@ -360,32 +591,42 @@ func (m ProjectModel) Get(externalID int32) (*ProjectResponse, error) {
 		}
 	}
 
-	err = json.Unmarshal([]byte(projectFeature), &project.Feature)
-	if err != nil {
-		return nil, errors.New("failed to unmarshal feature")
-	}
+	columns.assemble(&project)
 
-	for _, client := range clients {
-		var pc ProjectClient
-		err = json.Unmarshal([]byte(client), &pc)
-		if err != nil {
-			return nil, errors.New("failed to unmarshal clients associated with the project")
-		}
-		if pc.ClientID == nil {
-			project.Clients = nil
-			break
+	return &project, nil
+}
+
+// GetByProposalID returns the project created from a proposal, if the actor
+// may see it.
+func (m ProjectModel) GetByProposalID(actor Actor, proposalID string) (*ProjectResponse, error) {
+	query := `
+		SELECT project_id
+		FROM project
+		WHERE proposal_id = $1 AND deleted_at IS NULL`
+
+	var externalID int32
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, proposalID).Scan(&externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
 		}
-		project.Clients = append(project.Clients, pc)
 	}
 
-	return &project, nil
+	return m.Get(actor, externalID)
 }
 
 func (m ProjectModel) Update(project *ProjectRequest) error {
 	query := `
 		UPDATE project
-		SET project_id = $1, proposal_id = $2, name = $3, status = $4, feature = $5, images = $6, version = version + 1, updated_at = $7
-		WHERE internal_id = $8 AND version = $9
+		SET project_id = $1, proposal_id = $2, name = $3, status = $4, feature = $5, images = $6, custom_fields = $7, version = version + 1, updated_at = $8
+		WHERE internal_id = $9 AND version = $10
 		RETURNING version, created_at, updated_at`
 
 	args := []any{
@@ -395,15 +636,16 @@ func (m ProjectModel) Update(project *ProjectRequest) error {
 		project.Status,
 		project.Feature,
 		pq.Array(project.Images),
+		project.CustomFields,
 		time.Now(),
 		project.InternalID,
 		project.Version,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
-	tx, err := m.DB.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, m.DB)
 	if err != nil {
 		return err
 	}
@@ -423,39 +665,21 @@ func (m ProjectModel) Update(project *ProjectRequest) error {
 		}
 	}
 
-	query = `
-		DELETE FROM project_client
-		WHERE project_internal_id = $1 AND client_internal_id NOT IN (`
-	args = []any{project.InternalID}
-	for i, client := range project.Clients {
-		if i > 0 {
-			query += ", "
-		}
-		query += fmt.Sprintf("$%d", i+2)
-		args = append(args, *client.ClientID)
-	}
-	query += ")"
-
-	_, err = tx.ExecContext(ctx, query, args...)
-	if err != nil {
-		return err
+	clientIDs := []int32{}
+	for _, client := range project.Clients {
+		clientIDs = append(clientIDs, *client.ClientID)
 	}
 
 	query = `
+		WITH removed AS (
+			DELETE FROM project_client
+			WHERE project_internal_id = $1 AND NOT client_internal_id = ANY($2::integer[])
+		)
 		INSERT INTO project_client (project_internal_id, client_internal_id)
-		VALUES `
-	args = []any{}
-	for i, client := range project.Clients {
-		if i > 0 {
-			query += ", "
-		}
-		query += fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2)
-		args = append(args, project.InternalID, *client.ClientID)
-	}
-	query += `
+		SELECT $1, unnest($2::integer[])
 		ON CONFLICT (project_internal_id, client_internal_id) DO NOTHING`
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	_, err = tx.ExecContext(ctx, query, project.InternalID, pq.Array(clientIDs))
 	if err != nil {
 		return err
 	}
@@ -468,15 +692,44 @@ func (m ProjectModel) Update(project *ProjectRequest) error {
 	return nil
 }
 
+// CountDependents counts the live records that reference a project, keyed
+// timesheet_entries, milestones and files.
+func (m ProjectModel) CountDependents(internalID int32) (map[string]int, error) {
+	query := `
+		SELECT
+			(SELECT count(*) FROM timesheet_entry WHERE project_internal_id = $1 AND deleted_at IS NULL),
+			(SELECT count(*) FROM project_milestone WHERE project_internal_id = $1),
+			(SELECT count(*) FROM file WHERE project_internal_id = $1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var timesheetEntries, milestones, files int
+
+	err := m.DB.QueryRowContext(ctx, query, internalID).Scan(&timesheetEntries, &milestones, &files)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]int{
+		"timesheet_entries": timesheetEntries,
+		"milestones":        milestones,
+		"files":             files,
+	}, nil
+}
+
+// Delete trashes a project's files and hides the project. Both stay
+// recoverable with Undelete until Purge removes them for good.
 func (m ProjectModel) Delete(InternalID int32, bucket, prefix string, client *s3.Client, objects []types.ObjectIdentifier) error {
 	query := `
-		DELETE FROM project
-		WHERE internal_id = $1`
+		UPDATE project
+		SET deleted_at = NOW()
+		WHERE internal_id = $1 AND deleted_at IS NULL`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
-	tx, err := m.DB.BeginTx(ctx, nil)
+	tx, err := beginTx(ctx, m.DB)
 	if err != nil {
 		return err
 	}
@@ -514,8 +767,6 @@ func (m ProjectModel) Delete(InternalID int32, bucket, prefix string, client *s3
 		return ErrRecordNotFound
 	}
 
-	s3action.PermanentlyDeleteObjects(ctx, client, bucket, prefix)
-
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -524,6 +775,197 @@ func (m ProjectModel) Delete(InternalID int32, bucket, prefix string, client *s3
 	return err
 }
 
+// Archive marks a project read-only and hides it from default listings.
+func (m ProjectModel) Archive(project *ProjectResponse) error {
+	query := `
+		UPDATE project
+		SET archived_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE internal_id = $1 AND version = $2
+		RETURNING archived_at, version, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, project.InternalID, project.Version).Scan(
+		&project.ArchivedAt,
+		&project.Version,
+		&project.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Undelete restores a deleted project that has not been purged yet and
+// returns when it was deleted.
+func (m ProjectModel) Undelete(externalID int32) (time.Time, error) {
+	query := `
+		WITH deleted AS (
+			SELECT internal_id, deleted_at
+			FROM project
+			WHERE project_id = $1 AND deleted_at IS NOT NULL
+			FOR UPDATE
+		)
+		UPDATE project p
+		SET deleted_at = NULL
+		FROM deleted
+		WHERE p.internal_id = deleted.internal_id
+		RETURNING deleted.deleted_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var deletedAt time.Time
+
+	err := m.DB.QueryRowContext(ctx, query, externalID).Scan(&deletedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return time.Time{}, ErrRecordNotFound
+		default:
+			return time.Time{}, err
+		}
+	}
+
+	return deletedAt, nil
+}
+
+// GetAllDeletedBefore returns the external IDs of projects deleted before
+// cutoff.
+func (m ProjectModel) GetAllDeletedBefore(cutoff time.Time) ([]int32, error) {
+	query := `
+		SELECT project_id
+		FROM project
+		WHERE deleted_at < $1
+		ORDER BY project_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	externalIDs := []int32{}
+	for rows.Next() {
+		var externalID int32
+		err := rows.Scan(&externalID)
+		if err != nil {
+			return nil, err
+		}
+		externalIDs = append(externalIDs, externalID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return externalIDs, nil
+}
+
+// Purge permanently removes a deleted project.
+func (m ProjectModel) Purge(externalID int32) error {
+	query := `
+		DELETE FROM project
+		WHERE project_id = $1 AND deleted_at IS NOT NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, externalID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetByIDs returns the projects with the external IDs that the actor may
+// see, in the order the IDs were given.
+func (m ProjectModel) GetByIDs(actor Actor, externalIDs []int32) ([]*ProjectResponse, error) {
+	scope, scopeArgs := actor.projectScope(2)
+
+	query := `
+		SELECT p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.images, p.storage_bytes, p.custom_fields,
+		ARRAY(
+			SELECT t.name
+			FROM project_tag pt
+			INNER JOIN tag t ON t.internal_id = pt.tag_internal_id
+			WHERE pt.project_internal_id = p.internal_id
+			ORDER BY t.name
+		) AS tags, p.archived_at, p.version, p.created_at, p.updated_at,` + projectFeatureClientsColumns + `
+		FROM project p
+		LEFT JOIN project_client pc ON p.internal_id = pc.project_internal_id
+		LEFT JOIN client c ON pc.client_internal_id = c.internal_id
+		WHERE p.project_id = ANY($1::integer[]) AND p.deleted_at IS NULL` + scope + `
+		GROUP BY p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.feature, p.images, p.storage_bytes, p.custom_fields, p.archived_at, p.version, p.created_at, p.updated_at
+		ORDER BY array_position($1::integer[], p.project_id)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{pq.Array(externalIDs)}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	projects := []*ProjectResponse{}
+
+	for rows.Next() {
+		var project ProjectResponse
+		var columns projectColumns
+
+		err := rows.Scan(append([]any{
+			&project.InternalID,
+			&project.ExternalID,
+			&project.ProposalID,
+			&project.Name,
+			&project.Status,
+			pq.Array(&project.Images),
+			&project.StorageBytes,
+			&project.CustomFields,
+			pq.Array(&project.Tags),
+			&project.ArchivedAt,
+			&project.Version,
+			&project.CreatedAt,
+			&project.UpdatedAt,
+		}, columns.dest()...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		columns.assemble(&project)
+		projects = append(projects, &project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
 type Coordinates [2]float64
 
 type BoundingBox struct {
@@ -553,21 +995,41 @@ func ConvertToBbox(bboxStrings []string) (BoundingBox, error) {
 	return bbox, nil
 }
 
-func (m ProjectModel) GetAll(qs ProjectQsInput, bbox BoundingBox) ([]*ProjectResponse, Metadata, error) {
+// ConvertToPoint parses a longitude,latitude pair.
+func ConvertToPoint(pointStrings []string) (Point, error) {
+	if len(pointStrings) != 2 {
+		return Point{}, errors.New("point must have 2 coordinates")
+	}
+
+	longitude, err := strconv.ParseFloat(pointStrings[0], 64)
+	if err != nil {
+		return Point{}, err
+	}
+
+	latitude, err := strconv.ParseFloat(pointStrings[1], 64)
+	if err != nil {
+		return Point{}, err
+	}
+
+	if longitude < -180 || longitude > 180 || latitude < -90 || latitude > 90 {
+		return Point{}, errors.New("point is out of range")
+	}
+
+	return Point{Longitude: longitude, Latitude: latitude}, nil
+}
+
+func (m ProjectModel) GetAll(actor Actor, qs ProjectQsInput, bbox BoundingBox) ([]*ProjectResponse, Metadata, error) {
+	scope, scopeArgs := actor.projectScope(15)
+
 	query := fmt.Sprintf(`
-		SELECT count(*) OVER(), p.project_id, p.proposal_id, p.name, p.status, p.feature,
-		array_agg(
-			jsonb_build_object(
-				'id', c.internal_id,
-				'name', c.name,
-				'address', c.address,
-				'logo_url', c.logo_url,
-				'note', c.note,
-				'version', c.version,
-				'created_at', c.created_at,
-				'updated_at', c.updated_at
-			)
-		) as clients, p.images, p.version, p.created_at, p.updated_at
+		SELECT count(*) OVER(), p.project_id, p.proposal_id, p.name, p.status, p.images, p.storage_bytes, p.custom_fields,
+		ARRAY(
+			SELECT t.name
+			FROM project_tag pt
+			INNER JOIN tag t ON t.internal_id = pt.tag_internal_id
+			WHERE pt.project_internal_id = p.internal_id
+			ORDER BY t.name
+		) AS tags, p.archived_at, p.version, p.created_at, p.updated_at,`+projectFeatureClientsColumns+`
 		FROM project p
 		LEFT JOIN project_client pc ON p.internal_id = pc.project_internal_id
 		LEFT JOIN client c ON pc.client_internal_id = c.internal_id
@@ -611,11 +1073,25 @@ func (m ProjectModel) GetAll(qs ProjectQsInput, bbox BoundingBox) ([]*ProjectRes
 			OR
 			( $1 = '' and $2 = '' and $3 = FALSE and $8 = '' and $9 = '' and $10 = '' and $11 = '' )
 		)
-		GROUP BY p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.feature, p.images, p.version, p.created_at, p.updated_at
-		ORDER BY %s %s, p.project_id ASC`,
-		qs.Filters.sortColumn(), qs.Filters.sortDirection())
+		AND ($12::boolean OR p.archived_at IS NULL)
+		AND p.deleted_at IS NULL
+		AND p.custom_fields @> $13::jsonb
+		AND (
+			cardinality($14::text[]) = 0
+			OR p.internal_id IN (
+				SELECT pt.project_internal_id
+				FROM project_tag pt
+				INNER JOIN tag t ON t.internal_id = pt.tag_internal_id
+				WHERE t.name = ANY($14::text[])
+				GROUP BY pt.project_internal_id
+				HAVING count(*) = cardinality($14::text[])
+			)
+		)%s
+		GROUP BY p.internal_id, p.project_id, p.proposal_id, p.name, p.status, p.feature, p.images, p.storage_bytes, p.custom_fields, p.archived_at, p.version, p.created_at, p.updated_at
+		ORDER BY %s, p.project_id ASC`,
+		scope, qs.Filters.orderBy())
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
 	args := []any{
@@ -630,15 +1106,19 @@ func (m ProjectModel) GetAll(qs ProjectQsInput, bbox BoundingBox) ([]*ProjectRes
 		qs.ProposalId,
 		qs.FullAddress,
 		qs.ClientName,
+		qs.IncludeArchived,
+		qs.CustomFields,
+		pq.Array(qs.Tags),
 	}
+	args = append(args, scopeArgs...)
 
 	if qs.Filters.limit() > 0 {
-		query += `
-			LIMIT $12 OFFSET $13`
+		query += fmt.Sprintf(`
+			LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
 		args = append(args, qs.Filters.limit(), qs.Filters.offset())
 	}
 
-	rows, err := m.DB.QueryContext(ctx, query, args...)
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, Metadata{}, err
 	}
@@ -650,43 +1130,102 @@ func (m ProjectModel) GetAll(qs ProjectQsInput, bbox BoundingBox) ([]*ProjectRes
 
 	for rows.Next() {
 		var project ProjectResponse
-		var projectFeature string
-		var clients []string
+		var columns projectColumns
 
-		err := rows.Scan(
+		err := rows.Scan(append([]any{
 			&totalRecords,
 			&project.ExternalID,
 			&project.ProposalID,
 			&project.Name,
 			&project.Status,
-			&projectFeature,
-			pq.Array(&clients),
 			pq.Array(&project.Images),
+			&project.StorageBytes,
+			&project.CustomFields,
+			pq.Array(&project.Tags),
+			&project.ArchivedAt,
 			&project.Version,
 			&project.CreatedAt,
 			&project.UpdatedAt,
-		)
+		}, columns.dest()...)...)
 		if err != nil {
 			return nil, Metadata{}, err
 		}
 
-		err = json.Unmarshal([]byte(projectFeature), &project.Feature)
+		columns.assemble(&project)
+		projects = append(projects, &project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, qs.Filters.Page, qs.Filters.PageSize)
+
+	return projects, metadata, nil
+}
+
+// GetAllSummaries lists projects matching the name, status, project ID,
+// proposal ID and custom field filters of qs without joining clients or
+// tags. Filters that need those joins are ignored.
+func (m ProjectModel) GetAllSummaries(actor Actor, qs ProjectQsInput) ([]*ProjectSummary, Metadata, error) {
+	scope, scopeArgs := actor.projectScope(7)
+
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), project_id, proposal_id, name, status
+		FROM project p
+		WHERE (
+			( name ILIKE '%%' || $1 || '%%' and not $1 = '' )
+			OR
+			( status ILIKE '%%' || $2 || '%%' and not $2 = '' )
+			OR
+			( CAST(project_id AS TEXT) LIKE '%%' || $3 || '%%' and not $3 = '' )
+			OR
+			( proposal_id ILIKE '%%' || $4 || '%%' and not $4 = '' )
+			OR
+			( $1 = '' and $2 = '' and $3 = '' and $4 = '' )
+		)
+		AND ($5::boolean OR archived_at IS NULL)
+		AND deleted_at IS NULL
+		AND custom_fields @> $6::jsonb%s
+		ORDER BY %s, project_id ASC`,
+		scope, qs.Filters.orderBy())
+
+	args := []any{qs.Name, qs.Status, qs.ProjectId, qs.ProposalId, qs.IncludeArchived, qs.CustomFields}
+	args = append(args, scopeArgs...)
+
+	if qs.Filters.limit() > 0 {
+		query += fmt.Sprintf(`
+			LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+		args = append(args, qs.Filters.limit(), qs.Filters.offset())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	projects := []*ProjectSummary{}
+
+	for rows.Next() {
+		var project ProjectSummary
+
+		err := rows.Scan(
+			&totalRecords,
+			&project.ExternalID,
+			&project.ProposalID,
+			&project.Name,
+			&project.Status,
+		)
 		if err != nil {
-			return nil, Metadata{}, errors.New("failed to unmarshal feature")
+			return nil, Metadata{}, err
 		}
 
-		for _, client := range clients {
-			var pc ProjectClient
-			err = json.Unmarshal([]byte(client), &pc)
-			if err != nil {
-				return nil, Metadata{}, errors.New("failed to unmarshal clients")
-			}
-			if pc.ClientID == nil {
-				project.Clients = nil
-				break
-			}
-			project.Clients = append(project.Clients, pc)
-		}
 		projects = append(projects, &project)
 	}
 
@@ -698,3 +1237,192 @@ func (m ProjectModel) GetAll(qs ProjectQsInput, bbox BoundingBox) ([]*ProjectRes
 
 	return projects, metadata, nil
 }
+
+func (m ProjectModel) GetAllExternalIDs() ([]int32, error) {
+	query := `
+		SELECT project_id
+		FROM project
+		WHERE deleted_at IS NULL
+		ORDER BY project_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	externalIDs := []int32{}
+	for rows.Next() {
+		var externalID int32
+		err := rows.Scan(&externalID)
+		if err != nil {
+			return nil, err
+		}
+		externalIDs = append(externalIDs, externalID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return externalIDs, nil
+}
+
+// ReserveExternalID reserves the project_id after the highest in use or
+// reserved, or the next in format's sequence when format is not nil, until
+// expiresAt. Reservers take turns on a table lock, so no two are given the
+// same ID. The reservation only keeps the ID from being handed out again;
+// creating the project needs no further step.
+func (m ProjectModel) ReserveExternalID(format *codefmt.Format, userID *int32, expiresAt time.Time) (int32, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `LOCK TABLE project_id_reservation IN SHARE ROW EXCLUSIVE MODE`)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM project_id_reservation WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	var externalID int32
+
+	if format == nil {
+		query := `
+			SELECT COALESCE(max(project_id), 0) + 1
+			FROM (
+				SELECT project_id FROM project
+				UNION ALL
+				SELECT project_id FROM project_id_reservation
+			) ids`
+
+		err = tx.QueryRowContext(ctx, query).Scan(&externalID)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		codes, err := queryCodes(ctx, tx, `
+			SELECT project_id::text FROM project
+			UNION ALL
+			SELECT project_id::text FROM project_id_reservation`)
+		if err != nil {
+			return 0, err
+		}
+
+		code, err := format.Next(time.Now(), codes)
+		if err != nil {
+			return 0, err
+		}
+
+		id, err := strconv.ParseInt(code, 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		externalID = int32(id)
+	}
+
+	query := `
+		INSERT INTO project_id_reservation (project_id, user_internal_id, expires_at)
+		VALUES ($1, $2, $3)`
+
+	_, err = tx.ExecContext(ctx, query, externalID, userID, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return externalID, tx.Commit()
+}
+
+// ResolveRefs maps references to projects of the actor's organization, given
+// either as a project_id or a proposal_id, to their internal IDs.
+func (m ProjectModel) ResolveRefs(actor Actor, refs []string) (map[string]int32, error) {
+	query := `
+		SELECT internal_id, project_id::text, proposal_id
+		FROM project
+		WHERE (project_id::text = ANY($1::text[]) OR proposal_id = ANY($1::text[])) AND deleted_at IS NULL`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{pq.Array(refs)}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := make(map[string]int32)
+
+	for rows.Next() {
+		var internalID int32
+		var externalID, proposalID string
+
+		err := rows.Scan(&internalID, &externalID, &proposalID)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[externalID] = internalID
+		ids[proposalID] = internalID
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (m ProjectModel) GetStorageBytes(externalID int32) (int64, error) {
+	query := `
+		SELECT storage_bytes
+		FROM project
+		WHERE project_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var storageBytes int64
+	err := m.DB.QueryRowContext(ctx, query, externalID).Scan(&storageBytes)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return storageBytes, nil
+}
+
+// SetStorageBytes records the measured size of the project's files. It does
+// not bump version since it is not an edit of the project, but a changed size
+// moves updated_at so conditional GETs return the new figure.
+func (m ProjectModel) SetStorageBytes(externalID int32, storageBytes int64) error {
+	query := `
+		UPDATE project
+		SET storage_bytes = $1, updated_at = NOW()
+		WHERE project_id = $2 AND storage_bytes IS DISTINCT FROM $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, storageBytes, externalID)
+	return err
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rowsConnector opens connections that answer every query with the same
+// rows, recording the last query and its arguments.
+type rowsConnector struct {
+	columns []string
+	rows    [][]driver.Value
+	query   string
+	args    []driver.NamedValue
+}
+
+func (c *rowsConnector) Connect(context.Context) (driver.Conn, error) { return rowsConn{c}, nil }
+func (c *rowsConnector) Driver() driver.Driver                        { return nil }
+
+type rowsConn struct {
+	c *rowsConnector
+}
+
+func (rowsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (rowsConn) Close() error                        { return nil }
+func (rowsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (conn rowsConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	conn.c.query = query
+	conn.c.args = args
+	return &fakeRows{columns: conn.c.columns, rows: conn.c.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestProjectArchive(t *testing.T) {
+	archivedAt := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+
+	connector := &rowsConnector{
+		columns: []string{"archived_at", "version", "updated_at"},
+		rows:    [][]driver.Value{{archivedAt, int64(4), archivedAt}},
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	project := &ProjectResponse{InternalID: 7, Version: 3, Tags: []string{"bridge"}}
+
+	err := ProjectModel{DB: db}.Archive(project)
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+
+	if !strings.Contains(connector.query, "RETURNING archived_at, version, updated_at") {
+		t.Errorf("unexpected query %q", connector.query)
+	}
+	if len(connector.args) != 2 || connector.args[0].Value != int64(7) || connector.args[1].Value != int64(3) {
+		t.Errorf("got args %v, want internal_id 7 and version 3", connector.args)
+	}
+
+	if project.ArchivedAt == nil || !project.ArchivedAt.Equal(archivedAt) {
+		t.Errorf("got archived_at %v, want %v", project.ArchivedAt, archivedAt)
+	}
+	if project.Version != 4 {
+		t.Errorf("got version %d, want 4", project.Version)
+	}
+	if !project.UpdatedAt.Equal(archivedAt) {
+		t.Errorf("got updated_at %v, want %v", project.UpdatedAt, archivedAt)
+	}
+	if len(project.Tags) != 1 || project.Tags[0] != "bridge" {
+		t.Errorf("tags changed to %v", project.Tags)
+	}
+}
+
+func TestProjectArchiveConflict(t *testing.T) {
+	connector := &rowsConnector{columns: []string{"archived_at", "version", "updated_at"}}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	err := ProjectModel{DB: db}.Archive(&ProjectResponse{InternalID: 7, Version: 3})
+	if !errors.Is(err, ErrEditConflict) {
+		t.Fatalf("got %v, want ErrEditConflict", err)
+	}
+}
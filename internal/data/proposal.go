@@ -10,11 +10,13 @@ import (
 )
 
 type Proposal struct {
-	InternalID int32     `json:"-"`
-	ExternalID string    `json:"proposal_id"`
-	Version    int32     `json:"version"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	InternalID int32      `json:"-"`
+	ExternalID string     `json:"proposal_id"`
+	DueOn      *time.Time `json:"due_on"`
+	OrgID      int32      `json:"-"`
+	Version    int32      `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 func ValidateProposal(v *validator.Validator, proposal *Proposal) {
@@ -23,18 +25,19 @@ func ValidateProposal(v *validator.Validator, proposal *Proposal) {
 }
 
 type ProposalModel struct {
-	DB *sql.DB
+	DB      DBTX
+	Timeout time.Duration
 }
 
 func (ppm ProposalModel) Insert(proposal *Proposal) error {
 	query := `
-		INSERT INTO proposal (project_id)
-		VALUES ($1)
+		INSERT INTO proposal (project_id, due_on, org_internal_id)
+		VALUES ($1, $2, $3)
 		RETURNING internal_id, project_id, version, created_at, updated_at`
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(ppm.Timeout))
 	defer cancel()
 
-	return ppm.DB.QueryRowContext(ctx, query, proposal.ExternalID).Scan(
+	return ppm.DB.QueryRowContext(ctx, query, proposal.ExternalID, proposal.DueOn, proposal.OrgID).Scan(
 		&proposal.InternalID,
 		&proposal.ExternalID,
 		&proposal.Version,
@@ -43,23 +46,28 @@ func (ppm ProposalModel) Insert(proposal *Proposal) error {
 	)
 }
 
-func (ppm ProposalModel) Get(externalID string) (*Proposal, error) {
+// Get returns the proposal if it belongs to the actor's organization.
+func (ppm ProposalModel) Get(actor Actor, externalID string) (*Proposal, error) {
 	if externalID == "" {
 		return nil, ErrRecordNotFound
 	}
 
 	query := `
-		SELECT internal_id, project_id, version, created_at, updated_at
+		SELECT internal_id, project_id, due_on, org_internal_id, version, created_at, updated_at
 		FROM proposal
 		WHERE project_id = $1`
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
 	var proposal Proposal
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(ppm.Timeout))
 	defer cancel()
 
-	err := ppm.DB.QueryRowContext(ctx, query, externalID).Scan(
+	err := ppm.DB.QueryRowContext(ctx, query, append([]any{externalID}, scopeArgs...)...).Scan(
 		&proposal.InternalID,
 		&proposal.ExternalID,
+		&proposal.DueOn,
+		&proposal.OrgID,
 		&proposal.Version,
 		&proposal.CreatedAt,
 		&proposal.UpdatedAt,
@@ -79,20 +87,22 @@ func (ppm ProposalModel) Get(externalID string) (*Proposal, error) {
 func (ppm ProposalModel) Update(proposal *Proposal) error {
 	query := `
 		UPDATE proposal
-		SET project_id = $1, version = version + 1
-		WHERE internal_id = $2 AND version = $3
-		RETURNING version`
+		SET project_id = $1, due_on = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $3 AND version = $4
+		RETURNING version, updated_at`
 	args := []any{
 		proposal.ExternalID,
+		proposal.DueOn,
 		proposal.InternalID,
 		proposal.Version,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(ppm.Timeout))
 	defer cancel()
 
 	err := ppm.DB.QueryRowContext(ctx, query, args...).Scan(
 		&proposal.Version,
+		&proposal.UpdatedAt,
 	)
 	if err != nil {
 		switch {
@@ -105,7 +115,8 @@ func (ppm ProposalModel) Update(proposal *Proposal) error {
 	return nil
 }
 
-func (ppm ProposalModel) Delete(externalID string) error {
+// Delete removes the proposal if it belongs to the actor's organization.
+func (ppm ProposalModel) Delete(actor Actor, externalID string) error {
 	if externalID == "" {
 		return ErrRecordNotFound
 	}
@@ -113,11 +124,13 @@ func (ppm ProposalModel) Delete(externalID string) error {
 	query := `
 		DELETE FROM proposal
 		WHERE project_id = $1`
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(ppm.Timeout))
 	defer cancel()
 
-	result, err := ppm.DB.ExecContext(ctx, query, externalID)
+	result, err := ppm.DB.ExecContext(ctx, query, append([]any{externalID}, scopeArgs...)...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,168 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const defaultQueryTimeout = 3 * time.Second
+
+// queryTimeout is the deadline for a single model query. Models built
+// without a timeout use the default.
+func queryTimeout(d time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultQueryTimeout
+	}
+
+	return d
+}
+
+// QueryConfig tunes how the models talk to the database. Timeouts is keyed
+// by store name as in Models, lower-cased with underscores (for example
+// "timesheet" or "approval_step"); stores not listed use Timeout.
+// Statements running longer than SlowThreshold are logged to Logger.
+// When Replica is set, list and report queries outside transactions read
+// from it.
+type QueryConfig struct {
+	Timeout       time.Duration
+	Timeouts      map[string]time.Duration
+	SlowThreshold time.Duration
+	Logger        *slog.Logger
+	Replica       *sql.DB
+}
+
+func (c QueryConfig) timeout(store string) time.Duration {
+	if d, ok := c.Timeouts[store]; ok {
+		return d
+	}
+
+	return c.Timeout
+}
+
+// wrap returns db instrumented with slow query logging, or db itself when
+// logging is disabled.
+func (c QueryConfig) wrap(db DBTX) DBTX {
+	if c.SlowThreshold <= 0 || c.Logger == nil {
+		return db
+	}
+
+	return loggedDB{DBTX: db, threshold: c.SlowThreshold, logger: c.Logger}
+}
+
+// reader returns the connection read-heavy models query, or nil when reads
+// stay on conn. Reads inside a transaction must see its writes, so they
+// never go to the replica.
+func (c QueryConfig) reader(conn DBTX) DBTX {
+	if _, inTx := conn.(*sql.Tx); inTx || c.Replica == nil {
+		return nil
+	}
+
+	return c.wrap(newReplicaDB(conn, c.Replica, c.Logger))
+}
+
+// loggedDB logs statements that take longer than threshold, with their
+// arguments redacted and the model method and handler that issued them.
+type loggedDB struct {
+	DBTX
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+func (db loggedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer db.observe(time.Now(), query, args)
+	return db.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (db loggedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer db.observe(time.Now(), query, args)
+	return db.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (db loggedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer db.observe(time.Now(), query, args)
+	return db.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func (db loggedDB) observe(start time.Time, query string, args []any) {
+	elapsed := time.Since(start)
+	if elapsed < db.threshold {
+		return
+	}
+
+	model, handler := queryCallers()
+
+	db.logger.Warn("slow query",
+		"duration", elapsed,
+		"model", model,
+		"handler", handler,
+		"query", strings.Join(strings.Fields(query), " "),
+		"args", redactArgs(args),
+	)
+}
+
+// loggedTx is a transaction begun on a loggedDB. Its statements are logged
+// the same way.
+type loggedTx struct {
+	loggedDB
+	tx txHandle
+}
+
+func (t loggedTx) Commit() error   { return t.tx.Commit() }
+func (t loggedTx) Rollback() error { return t.tx.Rollback() }
+
+// queryCallers walks the stack for the data package method that ran the
+// statement and the first caller outside the package, normally a handler.
+func queryCallers() (model, handler string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(4, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+
+		switch {
+		case strings.HasPrefix(name, "data."):
+			if model == "" && !strings.HasPrefix(name, "data.loggedDB") {
+				model = strings.TrimPrefix(name, "data.")
+			}
+		case handler == "":
+			handler = name
+		}
+
+		if !more || handler != "" {
+			return model, handler
+		}
+	}
+}
+
+// redactArgs keeps the shape of query arguments without their content.
+// Identifiers, flags and times are kept since they are what makes a query
+// slow; text and binary values are replaced by their length.
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case nil:
+			redacted[i] = "NULL"
+		case int, int16, int32, int64, float64, bool:
+			redacted[i] = fmt.Sprint(arg)
+		case time.Time:
+			redacted[i] = arg.Format(time.RFC3339)
+		case string:
+			redacted[i] = fmt.Sprintf("<string len=%d>", len(arg))
+		case []byte:
+			redacted[i] = fmt.Sprintf("<bytes len=%d>", len(arg))
+		default:
+			redacted[i] = fmt.Sprintf("<%T>", arg)
+		}
+	}
+
+	return redacted
+}
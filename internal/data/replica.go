@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// replicaCooldown is how long reads stay on the primary after the replica
+// failed, before the replica is tried again.
+const replicaCooldown = 30 * time.Second
+
+// replicaDB sends reads to a read replica and falls back to the primary
+// when the replica cannot be reached. Errors reported by Postgres itself,
+// such as a bad query, are returned as they are since the primary would
+// fail the same way.
+type replicaDB struct {
+	DBTX
+	replica   DBTX
+	logger    *slog.Logger
+	downUntil *atomic.Int64
+}
+
+// readDB returns the connection heavy list and report queries run on: the
+// replica when the model has one, the primary otherwise.
+func readDB(read, db DBTX) DBTX {
+	if read == nil {
+		return db
+	}
+
+	return read
+}
+
+func newReplicaDB(primary, replica DBTX, logger *slog.Logger) replicaDB {
+	return replicaDB{DBTX: primary, replica: replica, logger: logger, downUntil: new(atomic.Int64)}
+}
+
+func (db replicaDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if db.available() {
+		rows, err := db.replica.QueryContext(ctx, query, args...)
+		if !db.failed(ctx, err) {
+			return rows, err
+		}
+	}
+
+	return db.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (db replicaDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if db.available() {
+		row := db.replica.QueryRowContext(ctx, query, args...)
+		if !db.failed(ctx, row.Err()) {
+			return row
+		}
+	}
+
+	return db.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func (db replicaDB) available() bool {
+	return time.Now().UnixNano() >= db.downUntil.Load()
+}
+
+// failed reports whether err means the replica is unavailable, and if so
+// keeps reads on the primary for replicaCooldown.
+func (db replicaDB) failed(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() != "57" && pqErr.Code.Class() != "08" {
+		return false
+	}
+
+	if db.downUntil.Swap(time.Now().Add(replicaCooldown).UnixNano()) < time.Now().UnixNano() && db.logger != nil {
+		db.logger.Warn("read replica unavailable, reading from primary", "error", err.Error(), "retry_in", replicaCooldown)
+	}
+
+	return true
+}
@@ -0,0 +1,173 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// reportDimension is a column a timesheet report can be grouped by. The
+// expressions refer to the entry t, its project p, its user u and its
+// activity a.
+type reportDimension struct {
+	id   string
+	name string
+}
+
+var reportDimensions = map[string]reportDimension{
+	"project":  {id: "p.project_id", name: "COALESCE(p.name, '')"},
+	"activity": {id: "COALESCE(a.internal_id, 0)", name: "COALESCE(a.name, '')"},
+	"user":     {id: "u.internal_id", name: "concat_ws(' ', u.first_name, u.last_name)"},
+	"month":    {id: "to_char(t.work_date, 'YYYY-MM')", name: "to_char(t.work_date, 'YYYY-MM')"},
+}
+
+// ReportDimensions and ReportMetrics list what a timesheet report can be
+// grouped by and what it can sum.
+var (
+	ReportDimensions = []string{"project", "activity", "user", "month"}
+	ReportMetrics    = []string{"minutes", "cost"}
+)
+
+// TimesheetReport is a flat table of metric values per combination of the
+// grouped dimensions, ready for a client to pivot.
+type TimesheetReport struct {
+	GroupBy []string           `json:"group_by"`
+	Metrics []string           `json:"metrics"`
+	Rows    []ReportRow        `json:"rows"`
+	Totals  map[string]float64 `json:"totals"`
+	// Snapshot is set when whole weeks were read from the weekly summary
+	// rather than the entries, and says how current those weeks are.
+	Snapshot *ReportSnapshot `json:"snapshot,omitempty"`
+}
+
+type ReportRow struct {
+	Keys   map[string]ReportKey `json:"keys"`
+	Values map[string]float64   `json:"values"`
+}
+
+// ReportKey identifies a dimension value. Entries without an activity are
+// grouped under ID 0.
+type ReportKey struct {
+	ID   any    `json:"id"`
+	Name string `json:"name"`
+}
+
+func ValidateReportParams(v *validator.Validator, groupBy, metrics []string) {
+	v.Check(len(groupBy) >= 1, "group_by", "must contain at least 1 dimension")
+	v.Check(validator.Unique(groupBy), "group_by", "must not contain duplicate values")
+	for _, dimension := range groupBy {
+		v.Check(validator.PermittedValue(dimension, ReportDimensions...), "group_by", "must only contain project, activity, user or month")
+	}
+
+	v.Check(len(metrics) >= 1, "metrics", "must contain at least 1 metric")
+	v.Check(validator.Unique(metrics), "metrics", "must not contain duplicate values")
+	for _, metric := range metrics {
+		v.Check(validator.PermittedValue(metric, ReportMetrics...), "metrics", "must only contain minutes or cost")
+	}
+}
+
+// Report sums the entries matching filter that actor may see, grouped by
+// the given dimensions in order. Cost is minutes at each user's hourly
+// cost, weighted by their organization's work rules; users without one add
+// nothing to it. Past weeks are read from the
+// weekly summary when it can answer the report.
+func (m TimesheetModel) Report(actor Actor, filter TimesheetFilter, groupBy, metrics []string) (*TimesheetReport, error) {
+	where, args := filter.where(actor)
+
+	source, sourceArgs, snapshot, err := m.summarySource(filter, groupBy, len(args)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := "c.cost_minutes * u.hourly_cost / 60"
+	costJoin := "INNER JOIN timesheet_entry_cost c ON c.internal_id = t.internal_id"
+	if source != "" {
+		cost, costJoin = "t.cost", ""
+		args = append(args, sourceArgs...)
+	} else {
+		source = "timesheet_entry"
+	}
+
+	var columns, groups, orders []string
+	for _, name := range groupBy {
+		dimension := reportDimensions[name]
+		columns = append(columns, dimension.id, dimension.name)
+		groups = append(groups, dimension.id, dimension.name)
+		orders = append(orders, dimension.id)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, sum(t.minutes)::bigint, COALESCE(sum(%s), 0)::float8
+		FROM %s t
+		%s
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		INNER JOIN appuser u ON u.internal_id = t.user_internal_id
+		LEFT JOIN activity a ON a.internal_id = t.activity_internal_id
+		WHERE %s
+		GROUP BY %s
+		ORDER BY %s`,
+		strings.Join(columns, ", "), cost, source, costJoin, where, strings.Join(groups, ", "), strings.Join(orders, ", "))
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	report := &TimesheetReport{
+		GroupBy:  groupBy,
+		Metrics:  metrics,
+		Rows:     []ReportRow{},
+		Totals:   make(map[string]float64, len(metrics)),
+		Snapshot: snapshot,
+	}
+	for _, metric := range metrics {
+		report.Totals[metric] = 0
+	}
+
+	for rows.Next() {
+		ids := make([]any, len(groupBy))
+		names := make([]string, len(groupBy))
+		dest := make([]any, 0, 2*len(groupBy)+2)
+		for i := range groupBy {
+			dest = append(dest, &ids[i], &names[i])
+		}
+
+		var minutes int64
+		var cost float64
+		dest = append(dest, &minutes, &cost)
+
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+
+		computed := map[string]float64{"minutes": float64(minutes), "cost": cost}
+
+		row := ReportRow{
+			Keys:   make(map[string]ReportKey, len(groupBy)),
+			Values: make(map[string]float64, len(metrics)),
+		}
+		for i, name := range groupBy {
+			row.Keys[name] = ReportKey{ID: ids[i], Name: names[i]}
+		}
+		for _, metric := range metrics {
+			row.Values[metric] = computed[metric]
+			report.Totals[metric] += computed[metric]
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,336 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrUnknownPermission is returned when a role is given a permission
+	// code that does not exist.
+	ErrUnknownPermission = errors.New("unknown permission")
+
+	// ErrUnknownRole is returned when a role that does not exist is mapped
+	// to.
+	ErrUnknownRole = errors.New("unknown role")
+)
+
+var roleNameRX = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Role is a bundle of permissions. Users assigned to a project with a role,
+// or holding it through a directory group, are granted its permissions,
+// which they keep as long as they hold the role on some project or through
+// the directory. Permissions granted otherwise are never revoked with roles.
+type Role struct {
+	Name        string      `json:"name"`
+	Permissions Permissions `json:"permissions"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func ValidateRole(v *validator.Validator, r *Role) {
+	v.Check(r.Name != "", "name", "must be provided")
+	v.Check(len(r.Name) <= 50, "name", "must not be more than 50 bytes long")
+	v.Check(validator.Matches(r.Name, roleNameRX), "name", "must be lowercase letters, digits and hyphens, starting with a letter")
+
+	v.Check(r.Permissions != nil, "permissions", "must be provided")
+	v.Check(validator.Unique(r.Permissions), "permissions", "must not contain duplicate values")
+}
+
+// RoleSync counts the permissions a recompute granted and revoked.
+type RoleSync struct {
+	Granted int64 `json:"granted"`
+	Revoked int64 `json:"revoked"`
+}
+
+type RoleModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+const roleColumns = `
+	r.name, ARRAY(
+		SELECT p.code
+		FROM permission p
+		INNER JOIN role_permission rp ON rp.permission_internal_id = p.internal_id
+		WHERE rp.role = r.name
+		ORDER BY p.code
+	), r.created_at, r.updated_at`
+
+func (m RoleModel) GetAll() ([]*Role, error) {
+	query := `
+		SELECT ` + roleColumns + `
+		FROM role r
+		ORDER BY r.name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	roles := []*Role{}
+
+	for rows.Next() {
+		var r Role
+
+		err := rows.Scan(&r.Name, pq.Array(&r.Permissions), &r.CreatedAt, &r.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (m RoleModel) Get(name string) (*Role, error) {
+	query := `
+		SELECT ` + roleColumns + `
+		FROM role r
+		WHERE r.name = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var r Role
+
+	err := m.DB.QueryRowContext(ctx, query, name).Scan(&r.Name, pq.Array(&r.Permissions), &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+// Put creates a role or replaces its permissions, and reports whether it
+// was created. Users holding the role keep the permissions they were
+// granted until Recompute is run.
+func (m RoleModel) Put(r *Role) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO role (name)
+		VALUES ($1)
+		ON CONFLICT (name) DO UPDATE SET updated_at = NOW()
+		RETURNING created_at, updated_at, xmax = 0`
+
+	var created bool
+
+	err = tx.QueryRowContext(ctx, query, r.Name).Scan(&r.CreatedAt, &r.UpdatedAt, &created)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM role_permission WHERE role = $1`, r.Name)
+	if err != nil {
+		return false, err
+	}
+
+	query = `
+		INSERT INTO role_permission (role, permission_internal_id)
+		SELECT $1, internal_id FROM permission WHERE code = ANY($2)`
+
+	result, err := tx.ExecContext(ctx, query, r.Name, pq.Array(r.Permissions))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected != int64(len(r.Permissions)) {
+		return false, ErrUnknownPermission
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	slices.Sort(r.Permissions)
+
+	return created, nil
+}
+
+// Delete deletes a role, taking it off the projects it was held on. Users
+// keep the permissions it granted until Recompute is run.
+func (m RoleModel) Delete(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `DELETE FROM role WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// roleBacked matches the appuser_permission rows ap that a role the user
+// still holds grants, on a project not deleted or through the directory.
+const roleBacked = `
+	EXISTS (
+		SELECT 1
+		FROM project_appuser pa
+		INNER JOIN project p ON p.internal_id = pa.project_internal_id
+		INNER JOIN role_permission rp ON rp.role = pa.role
+		WHERE pa.appuser_internal_id = ap.user_internal_id
+		AND rp.permission_internal_id = ap.permission_internal_id
+		AND p.deleted_at IS NULL
+	) OR EXISTS (
+		SELECT 1
+		FROM appuser_role ur
+		INNER JOIN role_permission rp ON rp.role = ur.role
+		WHERE ur.user_internal_id = ap.user_internal_id
+		AND rp.permission_internal_id = ap.permission_internal_id
+	)`
+
+// Recompute brings every user's role permissions in line with the roles
+// they hold on projects not deleted and through the directory: missing ones
+// are granted and those no role backs any more are revoked.
+func (m RoleModel) Recompute() (RoleSync, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return RoleSync{}, err
+	}
+	defer tx.Rollback()
+
+	var sync RoleSync
+
+	query := `
+		DELETE FROM appuser_permission ap
+		WHERE ap.granted_by_role
+		AND NOT (` + roleBacked + `)`
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return RoleSync{}, err
+	}
+
+	sync.Revoked, err = result.RowsAffected()
+	if err != nil {
+		return RoleSync{}, err
+	}
+
+	query = `
+		INSERT INTO appuser_permission (user_internal_id, permission_internal_id, granted_by_role)
+		SELECT pa.appuser_internal_id, rp.permission_internal_id, true
+		FROM project_appuser pa
+		INNER JOIN project p ON p.internal_id = pa.project_internal_id
+		INNER JOIN role_permission rp ON rp.role = pa.role
+		WHERE p.deleted_at IS NULL
+		UNION
+		SELECT ur.user_internal_id, rp.permission_internal_id, true
+		FROM appuser_role ur
+		INNER JOIN role_permission rp ON rp.role = ur.role
+		ON CONFLICT DO NOTHING`
+
+	result, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		return RoleSync{}, err
+	}
+
+	sync.Granted, err = result.RowsAffected()
+	if err != nil {
+		return RoleSync{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return RoleSync{}, err
+	}
+
+	return sync, nil
+}
+
+// grantRole grants a user the permissions of role they do not have yet.
+func grantRole(ctx context.Context, db DBTX, userID int32, role string) error {
+	query := `
+		INSERT INTO appuser_permission (user_internal_id, permission_internal_id, granted_by_role)
+		SELECT $1, permission_internal_id, true
+		FROM role_permission
+		WHERE role = $2
+		ON CONFLICT DO NOTHING`
+
+	_, err := db.ExecContext(ctx, query, userID, role)
+	return err
+}
+
+// setDirectoryRoles makes roles the ones a user holds through the directory,
+// granting the permissions of those gained and revoking those no role of
+// theirs backs any more.
+func setDirectoryRoles(ctx context.Context, db DBTX, userID int32, roles []string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM appuser_role WHERE user_internal_id = $1 AND NOT role = ANY($2)`, userID, pq.Array(roles))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO appuser_role (user_internal_id, role)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING`
+
+	_, err = db.ExecContext(ctx, query, userID, pq.Array(roles))
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		err = grantRole(ctx, db, userID, role)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `
+		DELETE FROM appuser_permission ap
+		WHERE ap.user_internal_id = $1 AND ap.granted_by_role
+		AND NOT (` + roleBacked + `)`
+
+	_, err = db.ExecContext(ctx, query, userID)
+	return err
+}
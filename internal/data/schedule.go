@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/cron"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+
+	"github.com/lib/pq"
+)
+
+// JobSchedule says when a background job runs. Expression is a cron
+// expression, evaluated in UTC, or @every followed by a duration.
+type JobSchedule struct {
+	Name       string     `json:"name"`
+	Expression string     `json:"expression"`
+	Enabled    bool       `json:"enabled"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	Version    int32      `json:"version"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func ValidateJobSchedule(v *validator.Validator, key string, s *JobSchedule) {
+	_, err := cron.Parse(s.Expression)
+	if err != nil {
+		v.AddError(key, "must be a valid schedule: "+err.Error())
+	}
+}
+
+type ScheduleModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Seed stores the given schedules for jobs that have none yet, leaving
+// those already stored, and possibly edited, alone.
+func (m ScheduleModel) Seed(schedules []*JobSchedule) error {
+	names := make([]string, len(schedules))
+	expressions := make([]string, len(schedules))
+	enabled := make([]bool, len(schedules))
+
+	for i, s := range schedules {
+		names[i] = s.Name
+		expressions[i] = s.Expression
+		enabled[i] = s.Enabled
+	}
+
+	query := `
+		INSERT INTO job_schedule (name, expression, enabled)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::boolean[])
+		ON CONFLICT (name) DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(names), pq.Array(expressions), pq.Array(enabled))
+	return err
+}
+
+func (m ScheduleModel) Get(name string) (*JobSchedule, error) {
+	query := `
+		SELECT name, expression, enabled, last_run_at, version, updated_at
+		FROM job_schedule
+		WHERE name = $1`
+
+	var s JobSchedule
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, name).Scan(
+		&s.Name,
+		&s.Expression,
+		&s.Enabled,
+		&s.LastRunAt,
+		&s.Version,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &s, nil
+}
+
+func (m ScheduleModel) GetAll() ([]*JobSchedule, error) {
+	query := `
+		SELECT name, expression, enabled, last_run_at, version, updated_at
+		FROM job_schedule
+		ORDER BY name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	schedules := []*JobSchedule{}
+
+	for rows.Next() {
+		var s JobSchedule
+		err := rows.Scan(
+			&s.Name,
+			&s.Expression,
+			&s.Enabled,
+			&s.LastRunAt,
+			&s.Version,
+			&s.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (m ScheduleModel) Update(s *JobSchedule) error {
+	query := `
+		UPDATE job_schedule
+		SET expression = $1, enabled = $2, version = version + 1, updated_at = NOW()
+		WHERE name = $3 AND version = $4
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, s.Expression, s.Enabled, s.Name, s.Version).Scan(&s.Version, &s.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Claim records that a job starts running at now, provided its last run is
+// still lastRunAt. Only one of several servers claiming the same run
+// succeeds, so each run happens once however many servers are up.
+func (m ScheduleModel) Claim(name string, lastRunAt *time.Time, now time.Time) (bool, error) {
+	query := `
+		UPDATE job_schedule
+		SET last_run_at = $3
+		WHERE name = $1 AND last_run_at IS NOT DISTINCT FROM $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, name, lastRunAt, now)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
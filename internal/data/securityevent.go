@@ -0,0 +1,114 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	SecurityEventLogin                = "login"
+	SecurityEventAccessTokenCreated   = "access_token_created"
+	SecurityEventAccessTokenRejected  = "access_token_rejected"
+	SecurityEventRefreshCookieSet     = "refresh_cookie_set"
+	SecurityEventLogoutAll            = "logout_all"
+	SecurityEventEmailChangeRequested = "email_change_requested"
+	SecurityEventEmailChanged         = "email_changed"
+)
+
+var SecurityEvents = []string{
+	SecurityEventLogin,
+	SecurityEventAccessTokenCreated,
+	SecurityEventAccessTokenRejected,
+	SecurityEventRefreshCookieSet,
+	SecurityEventLogoutAll,
+	SecurityEventEmailChangeRequested,
+	SecurityEventEmailChanged,
+}
+
+// SecurityEvent records a sign-in related action on a user's account, and
+// where it came from, for users and admins to audit access with.
+type SecurityEvent struct {
+	ID        int64     `json:"id"`
+	UserID    int32     `json:"-"`
+	Event     string    `json:"event"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SecurityEventModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m SecurityEventModel) Insert(e *SecurityEvent) error {
+	query := `
+		INSERT INTO security_event (user_internal_id, event, ip, user_agent)
+		VALUES ($1, $2, $3, $4)
+		RETURNING internal_id, created_at`
+
+	args := []any{e.UserID, e.Event, e.IP, e.UserAgent}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&e.ID, &e.CreatedAt)
+}
+
+// GetAllForUser lists a user's security events, optionally only those of
+// one kind when event is not empty.
+func (m SecurityEventModel) GetAllForUser(userID int32, event string, filters Filters) ([]*SecurityEvent, Metadata, error) {
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), internal_id, user_internal_id, event, ip, user_agent, created_at
+		FROM security_event
+		WHERE user_internal_id = $1 AND (event = $2 OR $2 = '')
+		ORDER BY %s, internal_id DESC`, filters.orderBy())
+
+	args := []any{userID, event}
+
+	if filters.limit() > 0 {
+		query += `
+		LIMIT $3 OFFSET $4`
+		args = append(args, filters.limit(), filters.offset())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	events := []*SecurityEvent{}
+
+	for rows.Next() {
+		var e SecurityEvent
+		err := rows.Scan(
+			&totalRecords,
+			&e.ID,
+			&e.UserID,
+			&e.Event,
+			&e.IP,
+			&e.UserAgent,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		events = append(events, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return events, metadata, nil
+}
@@ -0,0 +1,174 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ReportSnapshot describes the last materialization of the weekly timesheet
+// summary. Weeks starting before Through are read from the summary, with
+// the figures as they stood at RefreshedAt.
+type ReportSnapshot struct {
+	Through     time.Time `json:"through"`
+	Rows        int64     `json:"rows"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// weekSummarySource stands in for timesheet_entry in a report: weeks from
+// $n up to $n+1 come from the summary and every other day from the entries
+// themselves. It has the entry columns the filter and scope clauses refer
+// to, plus the cost of each row.
+const weekSummarySource = `(
+			SELECT NULL::bigint AS internal_id, s.user_internal_id, s.project_internal_id,
+				NULLIF(s.activity_internal_id, 0) AS activity_internal_id, s.week AS work_date,
+				s.minutes, s.cost, s.status, NULL::integer AS approver_internal_id,
+				NULL::timestamptz AS submitted_at, NULL::timestamptz AS deleted_at
+			FROM timesheet_week_summary s
+			WHERE ($%[1]d::date IS NULL OR s.week >= $%[1]d) AND s.week < $%[2]d
+			UNION ALL
+			SELECT e.internal_id, e.user_internal_id, e.project_internal_id,
+				e.activity_internal_id, e.work_date,
+				e.minutes, ec.cost_minutes * eu.hourly_cost / 60, e.status, e.approver_internal_id,
+				e.submitted_at, e.deleted_at
+			FROM timesheet_entry e
+			INNER JOIN timesheet_entry_cost ec ON ec.internal_id = e.internal_id
+			INNER JOIN appuser eu ON eu.internal_id = e.user_internal_id
+			WHERE ($%[1]d::date IS NOT NULL AND e.work_date < $%[1]d) OR e.work_date >= $%[2]d
+		)`
+
+// RefreshWeekSummary rebuilds the weekly summary from the live entries of
+// every week before the current one and returns the new snapshot.
+func (m TimesheetModel) RefreshWeekSummary() (*ReportSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	snapshot := &ReportSnapshot{Through: weekStart(time.Now())}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM timesheet_week_summary`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO timesheet_week_summary (project_internal_id, user_internal_id, activity_internal_id, status, week, minutes, cost)
+		SELECT t.project_internal_id, t.user_internal_id, COALESCE(t.activity_internal_id, 0), t.status,
+			date_trunc('week', t.work_date)::date, sum(t.minutes), COALESCE(sum(c.cost_minutes * u.hourly_cost / 60), 0)
+		FROM timesheet_entry t
+		INNER JOIN timesheet_entry_cost c ON c.internal_id = t.internal_id
+		INNER JOIN appuser u ON u.internal_id = t.user_internal_id
+		WHERE t.deleted_at IS NULL AND t.work_date < $1
+		GROUP BY 1, 2, 3, 4, 5`
+
+	result, err := tx.ExecContext(ctx, query, snapshot.Through)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Rows, err = result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO report_snapshot (name, through, row_count, refreshed_at)
+		VALUES ('timesheet_week', $1, $2, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET through = EXCLUDED.through, row_count = EXCLUDED.row_count, refreshed_at = EXCLUDED.refreshed_at
+		RETURNING refreshed_at`
+
+	err = tx.QueryRowContext(ctx, query, snapshot.Through, snapshot.Rows).Scan(&snapshot.RefreshedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// GetWeekSummarySnapshot returns the last refresh of the weekly summary, or
+// ErrRecordNotFound when it has never been built.
+func (m TimesheetModel) GetWeekSummarySnapshot() (*ReportSnapshot, error) {
+	query := `
+		SELECT through, row_count, refreshed_at
+		FROM report_snapshot
+		WHERE name = 'timesheet_week'`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var snapshot ReportSnapshot
+
+	err := readDB(m.ReadDB, m.DB).QueryRowContext(ctx, query).Scan(&snapshot.Through, &snapshot.Rows, &snapshot.RefreshedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &snapshot, nil
+}
+
+// summarySource returns the source a report with this filter and grouping
+// can read instead of timesheet_entry, binding its bounds from $n, or an
+// empty source when the summary can't answer it: it has no tags, approver
+// or submission dates, and weeks don't add up to months. Only whole weeks
+// inside the filter's date range are taken from the summary.
+func (m TimesheetModel) summarySource(filter TimesheetFilter, groupBy []string, n int) (string, []any, *ReportSnapshot, error) {
+	if len(filter.Tags) > 0 || filter.ApproverID != 0 || filter.SubmittedFrom != nil || filter.SubmittedTo != nil || slices.Contains(groupBy, "month") {
+		return "", nil, nil, nil
+	}
+
+	snapshot, err := m.GetWeekSummarySnapshot()
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return "", nil, nil, nil
+		}
+		return "", nil, nil, err
+	}
+
+	var from *time.Time
+	if filter.From != nil {
+		from = ptr(weekStart(*filter.From))
+		if from.Before(truncateDay(*filter.From)) {
+			*from = from.AddDate(0, 0, 7)
+		}
+	}
+
+	through := snapshot.Through
+	if filter.To != nil {
+		if end := weekStart(filter.To.AddDate(0, 0, 1)); end.Before(through) {
+			through = end
+		}
+	}
+
+	if from != nil && !from.Before(through) {
+		return "", nil, nil, nil
+	}
+
+	return fmt.Sprintf(weekSummarySource, n, n+1), []any{from, through}, snapshot, nil
+}
+
+// weekStart returns the Monday starting the week t falls in, matching
+// date_trunc('week', ...) in Postgres.
+func weekStart(t time.Time) time.Time {
+	day := truncateDay(t)
+
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
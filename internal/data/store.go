@@ -0,0 +1,377 @@
+package data
+
+import (
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hwanbin/wanpm-api/internal/codefmt"
+)
+
+// The stores below describe each model's behaviour so Models can hold an
+// alternative implementation. datamock has a generated mock of every store;
+// memstore has in-memory fakes of ProposalStore and OrganizationStore only.
+// The Postgres models satisfy them.
+
+//go:generate go run github.com/matryer/moq@v0.7.1 -out ../datamock/store.go -pkg datamock -stub . AccountingMappingStore ActivityStore AllocationStore ApprovalStepStore AssignmentStore AuditStore BudgetStore ClientStore CodePolicyStore CustomFieldStore DelegationStore DigestStore ExchangeRateStore ExportStore FileStore HealthStore HolidayStore LDAPStore LeaveStore MilestoneStore NotificationStore NotificationPreferenceStore OrganizationStore OrgSettingsStore PermissionStore PlanningStore ProjectStore ProposalStore RoleStore ScheduleStore SecurityEventStore SyncStore TagStore TeamStore TimesheetStore TokenStore TypeaheadStore UserStore WorkRulesStore
+
+type AccountingMappingStore interface {
+	GetCustomers(actor Actor) ([]*CustomerMapping, error)
+	GetServiceItems(actor Actor) ([]*ServiceItemMapping, error)
+	SetCustomer(actor Actor, mapping *CustomerMapping) error
+	SetServiceItem(actor Actor, mapping *ServiceItemMapping) error
+	DeleteCustomer(actor Actor, externalID int32) error
+	DeleteServiceItem(actor Actor, activityID int32) error
+	GetLines(actor Actor, from, to time.Time) ([]*AccountingLine, error)
+}
+
+type ActivityStore interface {
+	Insert(activity *Activity) error
+	Get(actor Actor, id int32) (*Activity, error)
+	GetAll(actor Actor) ([]*Activity, error)
+	IsDescendant(id, candidate int32) (bool, error)
+	Update(activity *Activity) error
+	Delete(actor Actor, id int32) error
+	GetAllForProject(externalID int32) ([]*Activity, error)
+	SetForProject(projectInternalID int32, activityIDs []int32) error
+	IsEnabledForProject(projectInternalID, activityID int32) (bool, error)
+}
+
+type AllocationStore interface {
+	Insert(actor Actor, a *Allocation, available float64) error
+	Get(actor Actor, id int32) (*Allocation, error)
+	GetAll(actor Actor, filter AllocationFilter) ([]*Allocation, error)
+	Update(a *Allocation, available float64) error
+	Delete(actor Actor, id int32) error
+}
+
+type ApprovalStepStore interface {
+	GetChain(externalID int32) ([]ApprovalStep, error)
+	ReplaceChain(projectInternalID *int32, steps []ApprovalStep) error
+}
+
+type AssignmentStore interface {
+	GetAllForProject(externalID int32) ([]*Assignment, error)
+	Insert(externalID, userID int32, manager bool, role *string) (bool, error)
+	Delete(externalID, userID int32) error
+}
+
+type AuditStore interface {
+	Insert(entry *AuditEntry) error
+}
+
+type BudgetStore interface {
+	Get(externalID int32) (*ProjectBudget, error)
+	Set(budget *ProjectBudget) error
+	Delete(externalID int32) error
+}
+
+type ClientStore interface {
+	Insert(client *Client) error
+	Get(actor Actor, internal_id int32) (*Client, error)
+	GetAll(actor Actor, filter ClientFilter, filters Filters) ([]*Client, Metadata, error)
+	GetClientByName(actor Actor, name string) (*Client, error)
+	GetAllByNames(actor Actor, names []string) (map[string]*Client, error)
+	Update(c *Client) error
+	Delete(internal_id int32, mode string, reassignTo int32) ([]int32, error)
+	GetSubtree(internal_id int32) ([]*Client, error)
+	GetSummary(internal_id int32, divisions bool) (*ClientSummary, error)
+}
+
+type CodePolicyStore interface {
+	Get(orgID int32) (*CodePolicy, error)
+	Update(p *CodePolicy) error
+	GetProjectCodes() ([]string, error)
+	GetProposalCodes() ([]string, error)
+}
+
+type CustomFieldStore interface {
+	Insert(field *CustomField) error
+	Get(actor Actor, id int32) (*CustomField, error)
+	GetAll(actor Actor, entity string) ([]*CustomField, error)
+	Update(field *CustomField, oldName string) error
+	Delete(field *CustomField) error
+}
+
+type DelegationStore interface {
+	Insert(actor Actor, d *Delegation) error
+	Get(actor Actor, id int32) (*Delegation, error)
+	GetAll(actor Actor, userID int32) ([]*Delegation, error)
+	GetActiveDelegates(delegatorID int32, day time.Time) ([]int32, error)
+	CanActFor(userID, approverID int32, day time.Time) (bool, error)
+	Delete(id int32) error
+}
+
+type DigestStore interface {
+	GetPreference(userID int32) (*DigestPreference, error)
+	SetPreference(userID int32, p *DigestPreference) error
+	GetDue(now time.Time) ([]*DigestRecipient, error)
+	Build(userID int32, since time.Time, warnRatio float64) (*Digest, error)
+	MarkSent(userID int32, at time.Time) error
+}
+
+type ExchangeRateStore interface {
+	Insert(base string, date time.Time, rates map[string]float64) error
+	Get(from, to string, date time.Time) (*ExchangeRate, error)
+}
+
+type ExportStore interface {
+	WriteJSON(w io.Writer, query string, orgID int32) error
+	WriteCSV(w io.Writer, query string, orgID int32) error
+}
+
+type FileStore interface {
+	Upsert(file *File) error
+	Get(id int64) (*File, error)
+	GetAllForProject(externalID int32) ([]*File, error)
+	GetLatestDocuments(externalID int32, category string) ([]*File, error)
+	GetVersions(id int64) ([]*File, error)
+	Reconcile(externalID int32, objects []*File) error
+	DeleteByKeys(keys []string) error
+}
+
+type HealthStore interface {
+	GetForProjects(externalIDs []int32, today time.Time) (map[int32]*ProjectHealth, error)
+	GetAlerts(today time.Time) ([]*HealthAlert, error)
+}
+
+type HolidayStore interface {
+	Insert(orgID int32, h *Holiday) error
+	GetAll(orgID int32, from, to time.Time) ([]*Holiday, error)
+	Delete(orgID, id int32) error
+}
+
+type LDAPStore interface {
+	Get(orgID int32) (*LDAPConfig, error)
+	Put(c *LDAPConfig) (bool, error)
+	Delete(orgID int32) error
+	Provision(orgID int32, user *User, roles []string) (bool, error)
+	Link(orgID, userID int32, linked bool) error
+}
+
+type LeaveStore interface {
+	Insert(actor Actor, l *Leave) error
+	Get(actor Actor, id int32) (*Leave, error)
+	GetAll(actor Actor, userID int32, from, to *time.Time) ([]*Leave, error)
+	Delete(id int32) error
+}
+
+type MilestoneStore interface {
+	Insert(milestone *Milestone) error
+	GetAllForProject(externalID int32) ([]*Milestone, error)
+	SetCompleted(actor Actor, id int32, completedOn *time.Time) (*Milestone, error)
+	Delete(actor Actor, id int32) error
+	GetCalendarForUser(userID int32) ([]*CalendarEvent, error)
+}
+
+type NotificationStore interface {
+	Insert(n *Notification) error
+	GetAllForUser(userID int32, unreadOnly bool, filters Filters) ([]*Notification, Metadata, error)
+	CountUnread(userID int32) (int, error)
+	MarkRead(userID int32, id int64) error
+	MarkAllRead(userID int32) (int64, error)
+}
+
+type NotificationPreferenceStore interface {
+	GetAllForUser(userID int32) ([]*NotificationPreference, error)
+	Upsert(userID int32, p *NotificationPreference) error
+	EmailEnabled(userID int32, category string) (bool, error)
+}
+
+type OrganizationStore interface {
+	Insert(org *Organization) error
+	Get(id int32) (*Organization, error)
+	GetAll() ([]*Organization, error)
+	Update(org *Organization) error
+	Delete(id int32) error
+}
+
+type OrgSettingsStore interface {
+	Get(orgID int32) (*OrgSettings, error)
+	GetForUser(userID int32) (*OrgSettings, error)
+	Update(s *OrgSettings) error
+}
+
+type PermissionStore interface {
+	GetAllForUser(userID int32) (Permissions, error)
+	GetActor(userID int32) (Actor, error)
+	GetActorClaims(userID int32) (Actor, int32, error)
+	GetClaimsVersions(since time.Time) (map[int32]int32, error)
+}
+
+type PlanningStore interface {
+	GetCapacity(actor Actor, teamID int32, from, to time.Time, weeklyHours float64) ([]*UserCapacity, error)
+	AvailableHours(userID int32, week time.Time, weeklyHours float64) (float64, error)
+}
+
+type ProjectStore interface {
+	Insert(project *ProjectRequest) error
+	Import(orgID int32, projects []*ProjectRequest, newClients []string) error
+	GetExistingKeys(externalIDs []int32, proposalIDs []string) (map[int32]bool, map[string]bool, error)
+	Get(actor Actor, externalID int32) (*ProjectResponse, error)
+	GetByProposalID(actor Actor, proposalID string) (*ProjectResponse, error)
+	Update(project *ProjectRequest) error
+	Delete(InternalID int32, bucket, prefix string, client *s3.Client, objects []types.ObjectIdentifier) error
+	Undelete(externalID int32) (time.Time, error)
+	GetAllDeletedBefore(cutoff time.Time) ([]int32, error)
+	Purge(externalID int32) error
+	Archive(project *ProjectResponse) error
+	GetByIDs(actor Actor, externalIDs []int32) ([]*ProjectResponse, error)
+	CountDependents(internalID int32) (map[string]int, error)
+	GetAll(actor Actor, qs ProjectQsInput, bbox BoundingBox) ([]*ProjectResponse, Metadata, error)
+	GetAllSummaries(actor Actor, qs ProjectQsInput) ([]*ProjectSummary, Metadata, error)
+	GetAllExternalIDs() ([]int32, error)
+	ReserveExternalID(format *codefmt.Format, userID *int32, expiresAt time.Time) (int32, error)
+	ResolveRefs(actor Actor, refs []string) (map[string]int32, error)
+	GetStorageBytes(externalID int32) (int64, error)
+	SetStorageBytes(externalID int32, storageBytes int64) error
+}
+
+type ProposalStore interface {
+	Insert(proposal *Proposal) error
+	Get(actor Actor, externalID string) (*Proposal, error)
+	Update(proposal *Proposal) error
+	Delete(actor Actor, externalID string) error
+}
+
+type RoleStore interface {
+	GetAll() ([]*Role, error)
+	Get(name string) (*Role, error)
+	Put(r *Role) (bool, error)
+	Delete(name string) error
+	Recompute() (RoleSync, error)
+}
+
+type ScheduleStore interface {
+	Seed(schedules []*JobSchedule) error
+	Get(name string) (*JobSchedule, error)
+	GetAll() ([]*JobSchedule, error)
+	Update(s *JobSchedule) error
+	Claim(name string, lastRunAt *time.Time, now time.Time) (bool, error)
+}
+
+type SecurityEventStore interface {
+	Insert(e *SecurityEvent) error
+	GetAllForUser(userID int32, event string, filters Filters) ([]*SecurityEvent, Metadata, error)
+}
+
+type SyncStore interface {
+	Changes(actor Actor, since *time.Time) (*SyncChanges, error)
+}
+
+type TagStore interface {
+	Insert(tag *Tag) error
+	GetAll(actor Actor) ([]*Tag, error)
+	Delete(actor Actor, id int32) error
+	SetProjectTags(projectInternalID int32, names []string) error
+	SetTimesheetTags(entryID int64, names []string) error
+}
+
+type TeamStore interface {
+	Insert(team *Team) error
+	Get(actor Actor, id int32) (*Team, error)
+	GetAll(actor Actor) ([]*Team, error)
+	Update(team *Team) error
+	Delete(actor Actor, id int32) error
+	GetMembers(teamID int32) ([]*TeamMember, error)
+	SetMember(teamID, userID int32, isLead bool) error
+	RemoveMember(teamID, userID int32) error
+	IsLead(leadID, userID int32) (bool, error)
+}
+
+type TimesheetStore interface {
+	GetAll(actor Actor, filter TimesheetFilter, filters Filters) ([]*TimesheetEntry, Metadata, error)
+	GetFacets(actor Actor, filter TimesheetFilter) (*TimesheetFacets, error)
+	Report(actor Actor, filter TimesheetFilter, groupBy, metrics []string) (*TimesheetReport, error)
+	RefreshWeekSummary() (*ReportSnapshot, error)
+	GetWeekSummarySnapshot() (*ReportSnapshot, error)
+	GetApprovedDaily(externalID int32) ([]DailyActual, error)
+	GetSuggestions(userID int32, day time.Time, limit int) ([]*TimesheetSuggestion, error)
+	Sync(userID int32, s *TimesheetSync, strategy string) (*TimesheetEntry, bool, error)
+	Get(id int64) (*TimesheetEntry, error)
+	Submit(entry *TimesheetEntry, step *ApprovalStep) error
+	Advance(entry *TimesheetEntry, next *ApprovalStep) error
+	Reject(entry *TimesheetEntry) error
+	GetDailyMinutes(userIDs []int32, from, to time.Time) (map[int32]map[string]int32, error)
+	CopyIn(entries []*TimesheetEntry, source string) error
+	PurgeDeleted(cutoff time.Time, dryRun bool) (int64, error)
+}
+
+type TokenStore interface {
+	New(userID int32, ttl time.Duration, scope string) (*Token, error)
+	Insert(token *Token) error
+	DeleteAllForUser(scope string, userID int32) error
+	IssuedSince(scope string, userID int32, ttl time.Duration, since time.Time) (bool, error)
+	GetUserID(scope, tokenPlaintext string) (int32, error)
+	RevokeAllForUser(userID int32) (int64, error)
+}
+
+type TypeaheadStore interface {
+	Search(actor Actor, entity, q string) ([]TypeaheadOption, error)
+}
+
+type UserStore interface {
+	GetByEmail(email string) (*User, error)
+	Get(id int32) (*User, error)
+	GetForActor(actor Actor, id int32) (*User, error)
+	UpdateAvatarKey(user *User) error
+	SetHourlyCost(actor Actor, id int32, cost *float64) error
+	GetLanguage(id int32) (string, error)
+	SetLanguage(id int32, language string) error
+	GetAll(actor Actor, teamID int32, filters Filters) ([]*User, Metadata, error)
+	GetAllByEmails(actor Actor, emails []string) (map[string]*User, error)
+	Import(orgID int32, users []*User, roles map[string]string) ([]*User, error)
+	Erase(user *User) error
+	SetPendingEmail(id int32, email string) error
+	ConfirmEmail(user *User) (string, error)
+	PurgeUnactivated(cutoff time.Time, dryRun bool) (int64, error)
+}
+
+type WorkRulesStore interface {
+	Get(orgID int32) (*WorkRules, error)
+	GetForUsers(userIDs []int32) (map[int32]*WorkRules, error)
+	Update(r *WorkRules) error
+}
+
+var (
+	_ AccountingMappingStore      = AccountingMappingModel{}
+	_ ActivityStore               = ActivityModel{}
+	_ AllocationStore             = AllocationModel{}
+	_ ApprovalStepStore           = ApprovalStepModel{}
+	_ AssignmentStore             = AssignmentModel{}
+	_ AuditStore                  = AuditModel{}
+	_ BudgetStore                 = BudgetModel{}
+	_ ClientStore                 = ClientModel{}
+	_ CodePolicyStore             = CodePolicyModel{}
+	_ CustomFieldStore            = CustomFieldModel{}
+	_ DelegationStore             = DelegationModel{}
+	_ DigestStore                 = DigestModel{}
+	_ ExchangeRateStore           = ExchangeRateModel{}
+	_ ExportStore                 = ExportModel{}
+	_ FileStore                   = FileModel{}
+	_ HealthStore                 = HealthModel{}
+	_ HolidayStore                = HolidayModel{}
+	_ LDAPStore                   = LDAPModel{}
+	_ LeaveStore                  = LeaveModel{}
+	_ MilestoneStore              = MilestoneModel{}
+	_ NotificationStore           = NotificationModel{}
+	_ NotificationPreferenceStore = NotificationPreferenceModel{}
+	_ OrganizationStore           = OrganizationModel{}
+	_ OrgSettingsStore            = OrgSettingsModel{}
+	_ PermissionStore             = PermissionModel{}
+	_ PlanningStore               = PlanningModel{}
+	_ ProjectStore                = ProjectModel{}
+	_ ProposalStore               = ProposalModel{}
+	_ RoleStore                   = RoleModel{}
+	_ ScheduleStore               = ScheduleModel{}
+	_ SecurityEventStore          = SecurityEventModel{}
+	_ SyncStore                   = SyncModel{}
+	_ TagStore                    = TagModel{}
+	_ TeamStore                   = TeamModel{}
+	_ TimesheetStore              = TimesheetModel{}
+	_ TokenStore                  = TokenModel{}
+	_ TypeaheadStore              = TypeaheadModel{}
+	_ UserStore                   = UserModel{}
+	_ WorkRulesStore              = WorkRulesModel{}
+)
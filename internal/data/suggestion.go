@@ -0,0 +1,92 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// TimesheetSuggestion is an entry a user is likely to log, taken from what
+// they logged recently on the projects they are assigned to. Minutes is the
+// median of those entries, and zero for assigned projects without any.
+type TimesheetSuggestion struct {
+	ProjectID    int32      `json:"project_id"`
+	ProjectName  string     `json:"project_name"`
+	ActivityID   *int32     `json:"activity_id"`
+	ActivityName *string    `json:"activity_name"`
+	Minutes      int32      `json:"minutes"`
+	Uses         int        `json:"uses"`
+	LastUsed     *time.Time `json:"last_used"`
+}
+
+// suggestionWindow is how far back entries count toward suggestions.
+const suggestionWindow = 8 * 7
+
+// GetSuggestions returns up to limit entries the user is likely to log on
+// day. Project and activity pairs are ranked by how often the user logged
+// them in the preceding weeks, counting entries on the same weekday double,
+// then by how recently. Pairs already logged on day are left out.
+func (m TimesheetModel) GetSuggestions(userID int32, day time.Time, limit int) ([]*TimesheetSuggestion, error) {
+	query := `
+		WITH recent AS (
+			SELECT t.project_internal_id, t.activity_internal_id,
+				count(*) AS uses,
+				count(*) FILTER (WHERE EXTRACT(DOW FROM t.work_date) = EXTRACT(DOW FROM $2::date)) AS same_weekday,
+				max(t.work_date) AS last_used,
+				percentile_disc(0.5) WITHIN GROUP (ORDER BY t.minutes) AS minutes
+			FROM timesheet_entry t
+			WHERE t.user_internal_id = $1
+			AND t.deleted_at IS NULL
+			AND t.work_date >= $2::date - $3::integer
+			AND t.work_date < $2::date
+			GROUP BY t.project_internal_id, t.activity_internal_id
+		)
+		SELECT p.project_id, COALESCE(p.name, ''), a.internal_id, a.name,
+			COALESCE(r.minutes, 0), COALESCE(r.uses, 0), r.last_used
+		FROM project_appuser pa
+		INNER JOIN project p ON p.internal_id = pa.project_internal_id
+		LEFT JOIN recent r ON r.project_internal_id = p.internal_id
+		LEFT JOIN activity a ON a.internal_id = r.activity_internal_id
+		WHERE pa.appuser_internal_id = $1
+		AND p.archived_at IS NULL
+		AND p.deleted_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1
+			FROM timesheet_entry t
+			WHERE t.user_internal_id = $1
+			AND t.project_internal_id = p.internal_id
+			AND t.activity_internal_id IS NOT DISTINCT FROM r.activity_internal_id
+			AND t.work_date = $2::date
+			AND t.deleted_at IS NULL
+		)
+		ORDER BY COALESCE(r.uses + r.same_weekday, 0) DESC, r.last_used DESC NULLS LAST, p.project_id
+		LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, userID, day, suggestionWindow, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	suggestions := []*TimesheetSuggestion{}
+
+	for rows.Next() {
+		var s TimesheetSuggestion
+
+		err := rows.Scan(&s.ProjectID, &s.ProjectName, &s.ActivityID, &s.ActivityName, &s.Minutes, &s.Uses, &s.LastUsed)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
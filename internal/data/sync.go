@@ -0,0 +1,271 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SyncChanges is everything an offline client needs to bring its copy up to
+// date since its last sync. Until is the cursor to pass as since next time;
+// it trails the server clock slightly, so consecutive syncs overlap rather
+// than miss writes committed late.
+type SyncChanges struct {
+	Since       *time.Time        `json:"since"`
+	Until       time.Time         `json:"until"`
+	Projects    []*SyncProject    `json:"projects"`
+	Assignments []*SyncAssignment `json:"assignments"`
+	Activities  []*Activity       `json:"activities"`
+	Timesheets  []*TimesheetEntry `json:"timesheets"`
+	Deleted     []*SyncTombstone  `json:"deleted"`
+}
+
+type SyncProject struct {
+	ExternalID int32      `json:"project_id"`
+	ProposalID string     `json:"proposal_id"`
+	Name       *string    `json:"name"`
+	Status     *string    `json:"status"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type SyncAssignment struct {
+	ProjectID int32     `json:"project_id"`
+	Manager   bool      `json:"manager"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SyncTombstone tells a client to drop a record. Entity is project,
+// assignment, activity or timesheet; assignments are identified by their
+// project ID.
+type SyncTombstone struct {
+	Entity    string    `json:"entity"`
+	ID        int64     `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type SyncModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// Changes returns what changed since since for the user behind actor: the
+// projects it may see, its own assignments and timesheet entries, and every
+// activity. A nil since returns everything current and no tombstones.
+func (m SyncModel) Changes(actor Actor, since *time.Time) (*SyncChanges, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	db := readDB(m.ReadDB, m.DB)
+
+	changes := &SyncChanges{
+		Since:       since,
+		Projects:    []*SyncProject{},
+		Assignments: []*SyncAssignment{},
+		Activities:  []*Activity{},
+		Timesheets:  []*TimesheetEntry{},
+		Deleted:     []*SyncTombstone{},
+	}
+
+	err := db.QueryRowContext(ctx, `SELECT date_trunc('second', NOW()) - interval '1 second'`).Scan(&changes.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, scopeArgs := actor.projectScope(2)
+
+	query := fmt.Sprintf(`
+		SELECT p.project_id, p.proposal_id, p.name, p.status, p.archived_at, p.updated_at
+		FROM project p
+		WHERE p.deleted_at IS NULL
+		AND ($1::timestamptz IS NULL OR p.updated_at >= $1)%s
+		ORDER BY p.project_id`, scope)
+
+	rows, err := db.QueryContext(ctx, query, append([]any{since}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var p SyncProject
+
+		err := rows.Scan(&p.ExternalID, &p.ProposalID, &p.Name, &p.Status, &p.ArchivedAt, &p.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.Projects = append(changes.Projects, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT p.project_id, pa.manager, pa.updated_at
+		FROM project_appuser pa
+		INNER JOIN project p ON p.internal_id = pa.project_internal_id
+		WHERE pa.appuser_internal_id = $1
+		AND p.deleted_at IS NULL
+		AND ($2::timestamptz IS NULL OR pa.updated_at >= $2)
+		ORDER BY p.project_id`
+
+	rows, err = db.QueryContext(ctx, query, actor.UserID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a SyncAssignment
+
+		err := rows.Scan(&a.ProjectID, &a.Manager, &a.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.Assignments = append(changes.Assignments, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	scope, scopeArgs = actor.orgScope("org_internal_id", 2)
+
+	query = fmt.Sprintf(`
+		SELECT internal_id, name, parent_internal_id, version, created_at, updated_at
+		FROM activity
+		WHERE ($1::timestamptz IS NULL OR updated_at >= $1)%s
+		ORDER BY internal_id`, scope)
+
+	rows, err = db.QueryContext(ctx, query, append([]any{since}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Activity
+
+		err := rows.Scan(&a.InternalID, &a.Name, &a.ParentID, &a.Version, &a.CreatedAt, &a.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.Activities = append(changes.Activities, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT t.internal_id, t.entry_uuid, t.user_internal_id, t.project_internal_id, p.project_id, t.activity_internal_id,
+			t.work_date, t.minutes, t.billed_minutes, t.note,
+			ARRAY(
+				SELECT tg.name
+				FROM timesheet_entry_tag et
+				INNER JOIN tag tg ON tg.internal_id = et.tag_internal_id
+				WHERE et.entry_internal_id = t.internal_id
+				ORDER BY tg.name
+			), t.status, t.submitted_at, t.approver_internal_id, t.version, t.created_at, t.updated_at
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE t.user_internal_id = $1
+		AND t.deleted_at IS NULL
+		AND p.deleted_at IS NULL
+		AND ($2::timestamptz IS NULL OR t.updated_at >= $2)
+		ORDER BY t.work_date, t.internal_id`
+
+	rows, err = db.QueryContext(ctx, query, actor.UserID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry TimesheetEntry
+
+		err := rows.Scan(
+			&entry.InternalID,
+			&entry.EntryUUID,
+			&entry.UserID,
+			&entry.ProjectID,
+			&entry.ExternalProjectID,
+			&entry.ActivityID,
+			&entry.WorkDate,
+			&entry.Minutes,
+			&entry.BilledMinutes,
+			&entry.Note,
+			pq.Array(&entry.Tags),
+			&entry.Status,
+			&entry.SubmittedAt,
+			&entry.ApproverID,
+			&entry.Version,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.Timesheets = append(changes.Timesheets, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if since == nil {
+		return changes, nil
+	}
+
+	// Hard deletes leave tombstones; soft deleted projects and entries are
+	// reported from their deleted_at.
+	query = `
+		SELECT entity, entity_id, deleted_at
+		FROM sync_tombstone
+		WHERE deleted_at >= $1 AND (user_internal_id IS NULL OR user_internal_id = $2)
+		UNION ALL
+		SELECT 'project', project_id, deleted_at
+		FROM project
+		WHERE deleted_at >= $1
+		UNION ALL
+		SELECT 'timesheet', internal_id, deleted_at
+		FROM timesheet_entry
+		WHERE deleted_at >= $1 AND user_internal_id = $2
+		ORDER BY 3, 1, 2`
+
+	rows, err = db.QueryContext(ctx, query, since, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var t SyncTombstone
+
+		err := rows.Scan(&t.Entity, &t.ID, &t.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		changes.Deleted = append(changes.Deleted, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,250 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+var ErrDuplicateTag = errors.New("duplicate tag")
+
+type Tag struct {
+	InternalID int32     `json:"id"`
+	Name       string    `json:"name"`
+	OrgID      int32     `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NormalizeTags trims and lowercases tag names and drops empty and repeated
+// ones, so "Roof" and "roof " name the same tag.
+func NormalizeTags(names []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+func ValidateTagName(v *validator.Validator, key, name string) {
+	v.Check(name != "", key, "must be provided")
+	v.Check(len(name) <= 50, key, "must not be more than 50 bytes long")
+	// Tags are filtered with a comma separated list.
+	v.Check(!strings.Contains(name, ","), key, "must not contain commas")
+}
+
+type TagModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m TagModel) Insert(tag *Tag) error {
+	query := `
+		INSERT INTO tag (name, org_internal_id)
+		VALUES ($1, $2)
+		RETURNING internal_id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, tag.Name, tag.OrgID).Scan(&tag.InternalID, &tag.CreatedAt)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "tag_org_internal_id_name_key"`:
+			return ErrDuplicateTag
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAll returns the tags of the actor's organization.
+func (m TagModel) GetAll(actor Actor) ([]*Tag, error) {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 1)
+
+	query := `
+		SELECT internal_id, name, created_at
+		FROM tag
+		WHERE true` + scope + `
+		ORDER BY name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, scopeArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := []*Tag{}
+
+	for rows.Next() {
+		var tag Tag
+		err := rows.Scan(&tag.InternalID, &tag.Name, &tag.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Delete removes a tag of the actor's organization from everything it is
+// attached to.
+func (m TagModel) Delete(actor Actor, id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM tag
+		WHERE internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, append([]any{id}, scopeArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetProjectTags replaces the tags of a project, creating tags that do not
+// exist yet in the project's organization. A trigger moves the project's
+// updated_at when its tags change.
+func (m TagModel) SetProjectTags(projectInternalID int32, names []string) error {
+	query := `
+		SELECT org_internal_id
+		FROM project
+		WHERE internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var orgID int32
+
+	err := m.DB.QueryRowContext(ctx, query, projectInternalID).Scan(&orgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return m.setTags("project_tag", "project_internal_id", projectInternalID, orgID, names)
+}
+
+// SetTimesheetTags replaces the tags of a timesheet entry, creating tags that
+// do not exist yet in the organization of the entry's project.
+func (m TagModel) SetTimesheetTags(entryID int64, names []string) error {
+	query := `
+		SELECT p.org_internal_id
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE t.internal_id = $1 AND t.deleted_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var orgID int32
+
+	err := m.DB.QueryRowContext(ctx, query, entryID).Scan(&orgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return m.setTags("timesheet_entry_tag", "entry_internal_id", entryID, orgID, names)
+}
+
+// setTags replaces the rows of a link table for one owner with tags of the
+// organization orgID. The table and column come from the callers above,
+// never from input.
+func (m TagModel) setTags(table, column string, ownerID any, orgID int32, names []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO tag (name, org_internal_id)
+		SELECT unnest($1::text[]), $2
+		ON CONFLICT (org_internal_id, name) DO NOTHING`
+
+	_, err = tx.ExecContext(ctx, query, pq.Array(names), orgID)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		DELETE FROM %[1]s
+		WHERE %[2]s = $1 AND tag_internal_id NOT IN (
+			SELECT internal_id FROM tag WHERE name = ANY($2::text[]) AND org_internal_id = $3
+		)`, table, column)
+
+	_, err = tx.ExecContext(ctx, query, ownerID, pq.Array(names), orgID)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, tag_internal_id)
+		SELECT $1, internal_id
+		FROM tag
+		WHERE name = ANY($2::text[]) AND org_internal_id = $3
+		ON CONFLICT DO NOTHING`, table, column)
+
+	_, err = tx.ExecContext(ctx, query, ownerID, pq.Array(names), orgID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,326 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+var ErrDuplicateTeamName = errors.New("duplicate team name")
+
+type Team struct {
+	InternalID int32     `json:"id"`
+	Name       string    `json:"name"`
+	OrgID      int32     `json:"-"`
+	Version    int32     `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type TeamMember struct {
+	UserID    int32  `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	IsLead    bool   `json:"is_lead"`
+}
+
+func ValidateTeam(v *validator.Validator, team *Team) {
+	v.Check(team.Name != "", "name", "must be provided")
+	v.Check(len(team.Name) <= 500, "name", "must not be more than 500 bytes long")
+}
+
+type TeamModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+func (m TeamModel) Insert(team *Team) error {
+	query := `
+		INSERT INTO team (name, org_internal_id)
+		VALUES ($1, $2)
+		RETURNING internal_id, version, created_at, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, team.Name, team.OrgID).Scan(
+		&team.InternalID,
+		&team.Version,
+		&team.CreatedAt,
+		&team.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "team_org_internal_id_name_key"`:
+			return ErrDuplicateTeamName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m TeamModel) Get(actor Actor, id int32) (*Team, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT internal_id, name, org_internal_id, version, created_at, updated_at
+		FROM team
+		WHERE internal_id = $1`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	var team Team
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&team.InternalID,
+		&team.Name,
+		&team.OrgID,
+		&team.Version,
+		&team.CreatedAt,
+		&team.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &team, nil
+}
+
+func (m TeamModel) GetAll(actor Actor) ([]*Team, error) {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 1)
+
+	query := `
+		SELECT internal_id, name, org_internal_id, version, created_at, updated_at
+		FROM team
+		WHERE true` + scope + `
+		ORDER BY name, internal_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, scopeArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teams := []*Team{}
+
+	for rows.Next() {
+		var team Team
+		err := rows.Scan(
+			&team.InternalID,
+			&team.Name,
+			&team.OrgID,
+			&team.Version,
+			&team.CreatedAt,
+			&team.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		teams = append(teams, &team)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (m TeamModel) Update(team *Team) error {
+	query := `
+		UPDATE team
+		SET name = $1, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $2 AND version = $3
+		RETURNING version, updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, team.Name, team.InternalID, team.Version).Scan(&team.Version, &team.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "team_org_internal_id_name_key"`:
+			return ErrDuplicateTeamName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m TeamModel) Delete(actor Actor, id int32) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+
+	query := `
+		DELETE FROM team
+		WHERE internal_id = $1` + scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, append([]any{id}, scopeArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m TeamModel) GetMembers(teamID int32) ([]*TeamMember, error) {
+	query := `
+		SELECT u.internal_id, u.email, u.first_name, u.last_name, tm.is_lead
+		FROM team_member tm
+		INNER JOIN appuser u ON tm.user_internal_id = u.internal_id
+		WHERE tm.team_internal_id = $1
+		ORDER BY tm.is_lead DESC, u.last_name, u.first_name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	members := []*TeamMember{}
+
+	for rows.Next() {
+		var member TeamMember
+		err := rows.Scan(
+			&member.UserID,
+			&member.Email,
+			&member.FirstName,
+			&member.LastName,
+			&member.IsLead,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, &member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// SetMember adds a user to a team, or updates their lead flag if they are
+// already a member. It returns ErrRecordNotFound unless the user belongs to
+// the team's organization.
+func (m TeamModel) SetMember(teamID, userID int32, isLead bool) error {
+	query := `
+		INSERT INTO team_member (team_internal_id, user_internal_id, is_lead)
+		SELECT t.internal_id, u.internal_id, $3
+		FROM team t
+		INNER JOIN appuser u ON u.org_internal_id = t.org_internal_id
+		WHERE t.internal_id = $1 AND u.internal_id = $2
+		ON CONFLICT (team_internal_id, user_internal_id) DO UPDATE
+		SET is_lead = EXCLUDED.is_lead`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, teamID, userID, isLead)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m TeamModel) RemoveMember(teamID, userID int32) error {
+	query := `
+		DELETE FROM team_member
+		WHERE team_internal_id = $1 AND user_internal_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, teamID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// IsLead reports whether a user leads a team that the other user belongs
+// to, which is what team-scoped approvals check.
+func (m TeamModel) IsLead(leadID, userID int32) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM team_member lead
+			INNER JOIN team_member member ON lead.team_internal_id = member.team_internal_id
+			WHERE lead.user_internal_id = $1 AND lead.is_lead AND member.user_internal_id = $2
+		)`
+
+	var isLead bool
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, leadID, userID).Scan(&isLead)
+	return isLead, err
+}
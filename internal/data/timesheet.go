@@ -0,0 +1,557 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+
+	"github.com/lib/pq"
+)
+
+// MaxDailyMinutes is the most time a user can record for a single day.
+const MaxDailyMinutes = 24 * 60
+
+// TimesheetStatuses lists the approval states an entry moves through.
+var TimesheetStatuses = []string{"draft", "submitted", "approved", "rejected"}
+
+type TimesheetEntry struct {
+	InternalID int64 `json:"id"`
+	// EntryUUID is set by offline clients when they create the entry, so
+	// that syncing it again updates it rather than adding a copy.
+	EntryUUID         *string    `json:"entry_uuid"`
+	UserID            int32      `json:"user_id"`
+	ProjectID         int32      `json:"-"`
+	ExternalProjectID int32      `json:"project_id"`
+	ActivityID        *int32     `json:"activity_id"`
+	WorkDate          time.Time  `json:"work_date"`
+	Minutes           int32      `json:"minutes"`
+	BilledMinutes     *int32     `json:"billed_minutes"`
+	Note              string     `json:"note"`
+	Tags              []string   `json:"tags,omitempty"`
+	Status            string     `json:"status"`
+	SubmittedAt       *time.Time `json:"submitted_at"`
+	ApproverID        *int32     `json:"approver_id"`
+	// ApprovalPosition is the approval step the entry is waiting on, or
+	// the last one it passed.
+	ApprovalPosition int32     `json:"-"`
+	Version          int32     `json:"version"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TimesheetFilter narrows timesheet queries. Zero values match everything.
+type TimesheetFilter struct {
+	UserID    int32
+	ProjectID int32
+	From      *time.Time
+	To        *time.Time
+	// Tags keeps entries carrying every one of these tags.
+	Tags []string
+	// Statuses keeps entries in any one of these states.
+	Statuses      []string
+	ApproverID    int32
+	SubmittedFrom *time.Time
+	SubmittedTo   *time.Time
+	// TeamID keeps entries of the team's members.
+	TeamID int32
+}
+
+// TimesheetFacets lists the distinct values present in a filtered set of
+// timesheet entries.
+type TimesheetFacets struct {
+	Users      []TimesheetFacet `json:"users"`
+	Projects   []TimesheetFacet `json:"projects"`
+	Activities []TimesheetFacet `json:"activities"`
+	Tags       []TimesheetFacet `json:"tags"`
+	From       *time.Time       `json:"from"`
+	To         *time.Time       `json:"to"`
+}
+
+type TimesheetFacet struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+func ValidateTimesheetFilter(v *validator.Validator, f TimesheetFilter) {
+	v.Check(f.UserID >= 0, "user_id", "must not be negative")
+	v.Check(f.ProjectID >= 0, "project_id", "must not be negative")
+
+	v.Check(f.ApproverID >= 0, "approver_id", "must not be negative")
+	v.Check(f.TeamID >= 0, "team_id", "must not be negative")
+
+	if f.From != nil && f.To != nil {
+		v.Check(!f.To.Before(*f.From), "to", "must not be before from")
+	}
+
+	if f.SubmittedFrom != nil && f.SubmittedTo != nil {
+		v.Check(!f.SubmittedTo.Before(*f.SubmittedFrom), "submitted_to", "must not be before submitted_from")
+	}
+
+	for _, status := range f.Statuses {
+		if !validator.PermittedValue(status, TimesheetStatuses...) {
+			v.AddError("status", "must be one of draft, submitted, approved or rejected")
+			break
+		}
+	}
+}
+
+type TimesheetModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// timesheetFilterClause matches live entries against a TimesheetFilter
+// passed as $1 to $10, with the entry aliased t and its project p. The
+// submitted range covers whole days, so submitted_to includes that day.
+const timesheetFilterClause = `
+		t.deleted_at IS NULL
+		AND p.deleted_at IS NULL
+		AND ($1 = 0 OR t.user_internal_id = $1)
+		AND ($2 = 0 OR p.project_id = $2)
+		AND ($3::date IS NULL OR t.work_date >= $3)
+		AND ($4::date IS NULL OR t.work_date <= $4)
+		AND (
+			cardinality($5::text[]) = 0
+			OR t.internal_id IN (
+				SELECT et.entry_internal_id
+				FROM timesheet_entry_tag et
+				INNER JOIN tag tg ON tg.internal_id = et.tag_internal_id
+				WHERE tg.name = ANY($5::text[])
+				GROUP BY et.entry_internal_id
+				HAVING count(*) = cardinality($5::text[])
+			)
+		)
+		AND (cardinality($6::text[]) = 0 OR t.status = ANY($6::text[]))
+		AND ($7 = 0 OR t.approver_internal_id = $7)
+		AND ($8::date IS NULL OR t.submitted_at >= $8::date)
+		AND ($9::date IS NULL OR t.submitted_at < $9::date + 1)
+		AND ($10 = 0 OR t.user_internal_id IN (
+			SELECT user_internal_id FROM team_member WHERE team_internal_id = $10
+		))`
+
+// where returns the WHERE conditions and arguments matching the filter
+// within what actor may see. The next free placeholder follows the
+// arguments.
+func (f TimesheetFilter) where(actor Actor) (string, []any) {
+	args := []any{
+		f.UserID, f.ProjectID, f.From, f.To, pq.Array(f.Tags),
+		pq.Array(f.Statuses), f.ApproverID, f.SubmittedFrom, f.SubmittedTo, f.TeamID,
+	}
+
+	scope, scopeArgs := actor.timesheetScope(len(args) + 1)
+
+	return timesheetFilterClause + scope, append(args, scopeArgs...)
+}
+
+func (m TimesheetModel) GetAll(actor Actor, filter TimesheetFilter, filters Filters) ([]*TimesheetEntry, Metadata, error) {
+	where, args := filter.where(actor)
+
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), sum(t.minutes) OVER(), t.internal_id, t.entry_uuid, t.user_internal_id AS user_id, t.project_internal_id, p.project_id,
+			t.activity_internal_id, t.work_date, t.minutes, t.billed_minutes, t.note,
+			ARRAY(
+				SELECT tg.name
+				FROM timesheet_entry_tag et
+				INNER JOIN tag tg ON tg.internal_id = et.tag_internal_id
+				WHERE et.entry_internal_id = t.internal_id
+				ORDER BY tg.name
+			) AS tags, t.status, t.submitted_at, t.approver_internal_id, t.version, t.created_at, t.updated_at
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE %s
+		ORDER BY %s, t.internal_id ASC`, where, filters.orderBy())
+
+	if filters.limit() > 0 {
+		query += fmt.Sprintf(`
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+		args = append(args, filters.limit(), filters.offset())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	var totalMinutes int64
+	entries := []*TimesheetEntry{}
+
+	for rows.Next() {
+		var entry TimesheetEntry
+		err := rows.Scan(
+			&totalRecords,
+			&totalMinutes,
+			&entry.InternalID,
+			&entry.EntryUUID,
+			&entry.UserID,
+			&entry.ProjectID,
+			&entry.ExternalProjectID,
+			&entry.ActivityID,
+			&entry.WorkDate,
+			&entry.Minutes,
+			&entry.BilledMinutes,
+			&entry.Note,
+			pq.Array(&entry.Tags),
+			&entry.Status,
+			&entry.SubmittedAt,
+			&entry.ApproverID,
+			&entry.Version,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	metadata.TotalMinutes = totalMinutes
+
+	return entries, metadata, nil
+}
+
+func (m TimesheetModel) GetFacets(actor Actor, filter TimesheetFilter) (*TimesheetFacets, error) {
+	where, args := filter.where(actor)
+
+	query := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT t.internal_id, t.user_internal_id, p.project_id, p.name AS project_name, t.activity_internal_id, t.work_date
+			FROM timesheet_entry t
+			INNER JOIN project p ON p.internal_id = t.project_internal_id
+			WHERE %s
+		)
+		SELECT 'user', u.internal_id, concat_ws(' ', u.first_name, u.last_name)
+		FROM appuser u
+		WHERE u.internal_id IN (SELECT user_internal_id FROM matched)
+		UNION ALL
+		SELECT DISTINCT 'project', project_id, project_name
+		FROM matched
+		UNION ALL
+		SELECT 'activity', a.internal_id, a.name
+		FROM activity a
+		WHERE a.internal_id IN (SELECT activity_internal_id FROM matched)
+		UNION ALL
+		SELECT 'tag', tg.internal_id, tg.name
+		FROM tag tg
+		WHERE tg.internal_id IN (
+			SELECT et.tag_internal_id
+			FROM timesheet_entry_tag et
+			WHERE et.entry_internal_id IN (SELECT internal_id FROM matched)
+		)
+		ORDER BY 1, 3, 2`, where)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	facets := &TimesheetFacets{
+		Users:      []TimesheetFacet{},
+		Projects:   []TimesheetFacet{},
+		Activities: []TimesheetFacet{},
+		Tags:       []TimesheetFacet{},
+	}
+
+	for rows.Next() {
+		var kind string
+		var facet TimesheetFacet
+
+		err := rows.Scan(&kind, &facet.ID, &facet.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "user":
+			facets.Users = append(facets.Users, facet)
+		case "project":
+			facets.Projects = append(facets.Projects, facet)
+		case "activity":
+			facets.Activities = append(facets.Activities, facet)
+		case "tag":
+			facets.Tags = append(facets.Tags, facet)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`
+		SELECT min(t.work_date), max(t.work_date)
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE %s`, where)
+
+	err = readDB(m.ReadDB, m.DB).QueryRowContext(ctx, query, args...).Scan(&facets.From, &facets.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func (m TimesheetModel) Get(id int64) (*TimesheetEntry, error) {
+	query := `
+		SELECT t.internal_id, t.entry_uuid, t.user_internal_id, t.project_internal_id, p.project_id, t.activity_internal_id,
+			t.work_date, t.minutes, t.billed_minutes, t.note, t.status, t.submitted_at, t.approver_internal_id, t.approval_position,
+			t.version, t.created_at, t.updated_at
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE t.internal_id = $1 AND t.deleted_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var entry TimesheetEntry
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&entry.InternalID,
+		&entry.EntryUUID,
+		&entry.UserID,
+		&entry.ProjectID,
+		&entry.ExternalProjectID,
+		&entry.ActivityID,
+		&entry.WorkDate,
+		&entry.Minutes,
+		&entry.BilledMinutes,
+		&entry.Note,
+		&entry.Status,
+		&entry.SubmittedAt,
+		&entry.ApproverID,
+		&entry.ApprovalPosition,
+		&entry.Version,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
+
+// Submit sends a draft or rejected entry for approval at step, billing
+// entry.BilledMinutes for it. A nil step, for a project without an approval
+// chain, approves the entry outright.
+func (m TimesheetModel) Submit(entry *TimesheetEntry, step *ApprovalStep) error {
+	query := `
+		UPDATE timesheet_entry
+		SET status = CASE WHEN $2::integer IS NULL THEN 'approved' ELSE 'submitted' END,
+			submitted_at = NOW(), approver_internal_id = $2, approval_position = $3, billed_minutes = $4
+		WHERE internal_id = $1 AND status IN ('draft', 'rejected') AND deleted_at IS NULL
+		RETURNING status, submitted_at, approver_internal_id, approval_position, version, updated_at`
+
+	var approverID *int32
+	var position int32
+	if step != nil {
+		approverID = &step.ApproverID
+		position = step.Position
+	}
+
+	return m.transition(entry, query, entry.InternalID, approverID, position, entry.BilledMinutes)
+}
+
+// Advance approves a submitted entry at its current step, handing it to
+// next, or marking it approved when next is nil.
+func (m TimesheetModel) Advance(entry *TimesheetEntry, next *ApprovalStep) error {
+	query := `
+		UPDATE timesheet_entry
+		SET status = CASE WHEN $3::integer IS NULL THEN 'approved' ELSE 'submitted' END,
+			approver_internal_id = COALESCE($3, approver_internal_id),
+			approval_position = CASE WHEN $3::integer IS NULL THEN approval_position ELSE $4 END
+		WHERE internal_id = $1 AND status = 'submitted' AND approval_position = $2 AND deleted_at IS NULL
+		RETURNING status, submitted_at, approver_internal_id, approval_position, version, updated_at`
+
+	var approverID *int32
+	var position int32
+	if next != nil {
+		approverID = &next.ApproverID
+		position = next.Position
+	}
+
+	return m.transition(entry, query, entry.InternalID, entry.ApprovalPosition, approverID, position)
+}
+
+// Reject returns a submitted entry to its owner at its current step.
+func (m TimesheetModel) Reject(entry *TimesheetEntry) error {
+	query := `
+		UPDATE timesheet_entry
+		SET status = 'rejected'
+		WHERE internal_id = $1 AND status = 'submitted' AND approval_position = $2 AND deleted_at IS NULL
+		RETURNING status, submitted_at, approver_internal_id, approval_position, version, updated_at`
+
+	return m.transition(entry, query, entry.InternalID, entry.ApprovalPosition)
+}
+
+// transition runs a status change and reads the new approval state into
+// entry. ErrEditConflict means the entry was not in the expected state.
+func (m TimesheetModel) transition(entry *TimesheetEntry, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&entry.Status,
+		&entry.SubmittedAt,
+		&entry.ApproverID,
+		&entry.ApprovalPosition,
+		&entry.Version,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetDailyMinutes returns the minutes already recorded by each of the given
+// users per day between from and to inclusive, keyed by user and then by
+// date formatted as YYYY-MM-DD.
+func (m TimesheetModel) GetDailyMinutes(userIDs []int32, from, to time.Time) (map[int32]map[string]int32, error) {
+	query := `
+		SELECT user_internal_id, work_date, SUM(minutes)
+		FROM timesheet_entry
+		WHERE user_internal_id = ANY($1::integer[]) AND work_date BETWEEN $2 AND $3
+		GROUP BY user_internal_id, work_date`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(userIDs), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	totals := make(map[int32]map[string]int32)
+
+	for rows.Next() {
+		var userID, minutes int32
+		var workDate time.Time
+
+		err := rows.Scan(&userID, &workDate, &minutes)
+		if err != nil {
+			return nil, err
+		}
+
+		if totals[userID] == nil {
+			totals[userID] = make(map[string]int32)
+		}
+		totals[userID][workDate.Format(time.DateOnly)] = minutes
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// CopyIn bulk loads entries with COPY in a single transaction, tagging each
+// with the system it was imported from.
+func (m TimesheetModel) CopyIn(entries []*TimesheetEntry, source string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("timesheet_entry",
+		"user_internal_id", "project_internal_id", "activity_internal_id", "work_date", "minutes", "note", "source"))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		_, err = stmt.ExecContext(ctx, entry.UserID, entry.ProjectID, entry.ActivityID, entry.WorkDate, entry.Minutes, entry.Note, source)
+		if err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		stmt.Close()
+		return err
+	}
+
+	err = stmt.Close()
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeleted permanently removes entries soft deleted before cutoff and
+// returns how many there were. With dryRun the entries are only counted.
+func (m TimesheetModel) PurgeDeleted(cutoff time.Time, dryRun bool) (int64, error) {
+	query := `
+		DELETE FROM timesheet_entry
+		WHERE deleted_at < $1`
+	if dryRun {
+		query = `
+			SELECT count(*)
+			FROM timesheet_entry
+			WHERE deleted_at < $1`
+	}
+
+	return countOrExec(m.DB, query, dryRun, cutoff)
+}
+
+// countOrExec runs a count query when dryRun is set and returns its result,
+// or executes the statement and returns the number of rows it affected.
+func countOrExec(db DBTX, query string, dryRun bool, args ...any) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if dryRun {
+		var count int64
+		err := db.QueryRowContext(ctx, query, args...).Scan(&count)
+		return count, err
+	}
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,246 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/id"
+	"github.com/hwanbin/wanpm-api/internal/validator"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrSyncConflict is returned when the server's copy of a synced entry
+	// changed after the client's.
+	ErrSyncConflict = errors.New("sync conflict")
+	// ErrTimesheetLocked is returned when a synced entry has been submitted,
+	// approved or deleted on the server and can no longer be edited.
+	ErrTimesheetLocked = errors.New("timesheet entry locked")
+	// ErrDuplicateEntryUUID is returned when a synced entry's UUID belongs to
+	// another user's entry.
+	ErrDuplicateEntryUUID = errors.New("duplicate entry uuid")
+	// ErrDailyMinutesExceeded is returned when a synced entry would bring the
+	// user's total for the day past the most their work rules allow.
+	ErrDailyMinutesExceeded = errors.New("daily minutes exceeded")
+)
+
+// Conflict strategies for TimesheetModel.Sync. With SyncLastWriteWins the
+// copy modified last is kept; with SyncServerVersion a client may only
+// update the version of the entry it last saw.
+const (
+	SyncLastWriteWins = "last_write_wins"
+	SyncServerVersion = "server_version"
+)
+
+// TimesheetSync is an entry as an offline client recorded it. BaseVersion is
+// the server version the client last saw, zero for an entry it created, and
+// ModifiedAt is when the client last changed it.
+type TimesheetSync struct {
+	Entry       *TimesheetEntry
+	BaseVersion int32
+	ModifiedAt  time.Time
+	// Rules are the work rules of the user's organization, the defaults
+	// when nil.
+	Rules *WorkRules
+}
+
+func ValidateTimesheetSync(v *validator.Validator, s *TimesheetSync, strategy string) {
+	v.Check(s.Entry.EntryUUID != nil, "entry_uuid", "must be provided")
+	if s.Entry.EntryUUID != nil {
+		id.UUID.Check(v, "entry_uuid", *s.Entry.EntryUUID)
+	}
+
+	v.Check(s.Entry.ExternalProjectID > 0, "project_id", "must be provided")
+	v.Check(!s.Entry.WorkDate.IsZero(), "work_date", "must be provided")
+	v.Check(s.Entry.Minutes > 0, "minutes", "must be greater than zero")
+	v.Check(s.Entry.Minutes <= MaxDailyMinutes, "minutes", "must not be more than 1440")
+	v.Check(s.BaseVersion >= 0, "base_version", "must not be negative")
+	v.Check(strategy != SyncLastWriteWins || !s.ModifiedAt.IsZero(), "modified_at", "must be provided")
+}
+
+// timesheetSyncColumns are the columns read back into a synced entry, with
+// the entry aliased t and its project p.
+const timesheetSyncColumns = `
+	t.internal_id, t.entry_uuid, t.user_internal_id, t.project_internal_id, p.project_id, t.activity_internal_id,
+	t.work_date, t.minutes, t.billed_minutes, t.note,
+	ARRAY(
+		SELECT tg.name
+		FROM timesheet_entry_tag et
+		INNER JOIN tag tg ON tg.internal_id = et.tag_internal_id
+		WHERE et.entry_internal_id = t.internal_id
+		ORDER BY tg.name
+	), t.status, t.submitted_at, t.approver_internal_id, t.version, t.created_at, t.updated_at, t.deleted_at`
+
+// Sync creates or updates the entry s.Entry.EntryUUID names for userID,
+// resolving a clash with the server's copy by strategy. The entry's project
+// must be one the user is assigned to, and its activity, if any, one enabled
+// for the project, or it returns ErrActivityNotEnabled.
+//
+// On success s.Entry holds the stored entry and created reports whether it
+// was new. On ErrSyncConflict and ErrTimesheetLocked the server's copy is
+// returned alongside, so the client can replace its own.
+func (m TimesheetModel) Sync(userID int32, s *TimesheetSync, strategy string) (server *TimesheetEntry, created bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	current, deletedAt, err := getTimesheetByUUID(ctx, tx, *s.Entry.EntryUUID, true)
+	switch {
+	case errors.Is(err, ErrRecordNotFound):
+		created = true
+	case err != nil:
+		return nil, false, err
+	case current.UserID != userID:
+		return nil, false, ErrDuplicateEntryUUID
+	case deletedAt != nil || (current.Status != "draft" && current.Status != "rejected"):
+		return current, false, ErrTimesheetLocked
+	case strategy == SyncLastWriteWins && s.ModifiedAt.Before(current.UpdatedAt):
+		return current, false, ErrSyncConflict
+	case strategy == SyncServerVersion && s.BaseVersion != current.Version:
+		return current, false, ErrSyncConflict
+	}
+
+	var internalID int64
+	if !created {
+		internalID = current.InternalID
+	}
+
+	query := `
+		SELECT COALESCE(sum(minutes), 0)
+		FROM timesheet_entry
+		WHERE user_internal_id = $1 AND work_date = $2 AND internal_id <> $3 AND deleted_at IS NULL`
+
+	var logged int32
+
+	err = tx.QueryRowContext(ctx, query, userID, s.Entry.WorkDate, internalID).Scan(&logged)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rules := s.Rules
+	if rules == nil {
+		rules = DefaultWorkRules(0)
+	}
+
+	if logged+s.Entry.Minutes > rules.MaxDayMinutes {
+		return nil, false, ErrDailyMinutesExceeded
+	}
+
+	args := []any{userID, s.Entry.ExternalProjectID, s.Entry.ActivityID, s.Entry.WorkDate, s.Entry.Minutes, s.Entry.Note}
+
+	if created {
+		query = `
+			INSERT INTO timesheet_entry (user_internal_id, project_internal_id, activity_internal_id, work_date, minutes, note, entry_uuid, source)
+			SELECT $1, p.internal_id, $3, $4, $5, $6, $7, 'sync'
+			FROM project p
+			INNER JOIN project_appuser pa ON pa.project_internal_id = p.internal_id AND pa.appuser_internal_id = $1
+			WHERE p.project_id = $2 AND p.deleted_at IS NULL AND p.archived_at IS NULL
+			RETURNING internal_id`
+		args = append(args, *s.Entry.EntryUUID)
+	} else {
+		query = `
+			UPDATE timesheet_entry t
+			SET project_internal_id = p.internal_id, activity_internal_id = $3, work_date = $4, minutes = $5, note = $6, billed_minutes = NULL
+			FROM project p
+			INNER JOIN project_appuser pa ON pa.project_internal_id = p.internal_id AND pa.appuser_internal_id = $1
+			WHERE t.internal_id = $7 AND p.project_id = $2 AND p.deleted_at IS NULL AND p.archived_at IS NULL
+			RETURNING t.internal_id`
+		args = append(args, internalID)
+	}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&internalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, false, ErrRecordNotFound
+		case err.Error() == `pq: insert or update on table "timesheet_entry" violates foreign key constraint "timesheet_entry_activity_internal_id_fkey"`:
+			return nil, false, ErrRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "idx_timesheet_entry_uuid"`:
+			// Another sync of the same entry got in first.
+			return nil, false, ErrSyncConflict
+		default:
+			return nil, false, err
+		}
+	}
+
+	stored, _, err := getTimesheetByUUID(ctx, tx, *s.Entry.EntryUUID, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if stored.ActivityID != nil {
+		enabled, err := activityEnabled(ctx, tx, stored.ProjectID, *stored.ActivityID)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if !enabled {
+			return nil, false, ErrActivityNotEnabled
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, false, err
+	}
+
+	*s.Entry = *stored
+
+	return nil, created, nil
+}
+
+// getTimesheetByUUID reads the entry with the given UUID, deleted or not,
+// locking it when lock is set.
+func getTimesheetByUUID(ctx context.Context, tx DBTX, entryUUID string, lock bool) (*TimesheetEntry, *time.Time, error) {
+	query := `
+		SELECT` + timesheetSyncColumns + `
+		FROM timesheet_entry t
+		INNER JOIN project p ON p.internal_id = t.project_internal_id
+		WHERE t.entry_uuid = $1`
+	if lock {
+		query += `
+		FOR UPDATE OF t`
+	}
+
+	var entry TimesheetEntry
+	var deletedAt *time.Time
+
+	err := tx.QueryRowContext(ctx, query, entryUUID).Scan(
+		&entry.InternalID,
+		&entry.EntryUUID,
+		&entry.UserID,
+		&entry.ProjectID,
+		&entry.ExternalProjectID,
+		&entry.ActivityID,
+		&entry.WorkDate,
+		&entry.Minutes,
+		&entry.BilledMinutes,
+		&entry.Note,
+		pq.Array(&entry.Tags),
+		&entry.Status,
+		&entry.SubmittedAt,
+		&entry.ApproverID,
+		&entry.Version,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	return &entry, deletedAt, nil
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/base32"
+	"errors"
 	"time"
 
 	"github.com/hwanbin/wanpm-api/internal/validator"
@@ -14,6 +15,8 @@ import (
 const (
 	ScopeActivation     = "activation"
 	ScopeAuthentication = "authentication"
+	ScopeCalendar       = "calendar"
+	ScopeEmailChange    = "email_change"
 )
 
 type Token struct {
@@ -51,7 +54,8 @@ func ValidateTokenPlaintext(v *validator.Validator, tokenPlaintext string) {
 }
 
 type TokenModel struct {
-	DB *sql.DB
+	DB      DBTX
+	Timeout time.Duration
 }
 
 func (m TokenModel) New(userID int32, ttl time.Duration, scope string) (*Token, error) {
@@ -71,7 +75,7 @@ func (m TokenModel) Insert(token *Token) error {
 
 	args := []any{token.Hash, token.UserID, token.Expiry, token.Scope}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
 	_, err := m.DB.ExecContext(ctx, query, args...)
@@ -83,9 +87,98 @@ func (m TokenModel) DeleteAllForUser(scope string, userID int32) error {
 		DELETE FROM token
 		WHERE scope = $1 AND appuser_internal_id = $2`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
 	defer cancel()
 
 	_, err := m.DB.ExecContext(ctx, query, scope, userID)
 	return err
 }
+
+// IssuedSince reports whether a token of the given scope was issued to the
+// user after the given time. Issue time is derived from the expiry and ttl.
+func (m TokenModel) IssuedSince(scope string, userID int32, ttl time.Duration, since time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM token
+			WHERE scope = $1 AND appuser_internal_id = $2 AND expiry > $3
+		)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, query, scope, userID, since.Add(ttl)).Scan(&exists)
+	return exists, err
+}
+
+func (m TokenModel) GetUserID(scope, tokenPlaintext string) (int32, error) {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := `
+		SELECT appuser_internal_id
+		FROM token
+		WHERE hash = $1 AND scope = $2 AND expiry > $3`
+
+	var userID int32
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, tokenHash[:], scope, time.Now()).Scan(&userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return userID, nil
+}
+
+// RevokeAllForUser signs a user out everywhere: their authentication tokens
+// are deleted and their claims version is bumped, so the access tokens
+// issued to them are rejected too.
+func (m TokenModel) RevokeAllForUser(userID int32) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM token
+		WHERE scope = $1 AND appuser_internal_id = $2`
+
+	result, err := tx.ExecContext(ctx, query, ScopeAuthentication, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	query = `
+		UPDATE appuser
+		SET claims_version = claims_version + 1, claims_changed_at = NOW()
+		WHERE internal_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
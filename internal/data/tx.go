@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DBTX is the subset of *sql.DB and *sql.Tx the models use, so a model can
+// run against either the pool or a request-level transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txHandle interface {
+	DBTX
+	Commit() error
+	Rollback() error
+}
+
+// joinedTx is a transaction a model method joined rather than started.
+// Committing and rolling back are left to whoever started it.
+type joinedTx struct {
+	*sql.Tx
+}
+
+func (joinedTx) Commit() error   { return nil }
+func (joinedTx) Rollback() error { return nil }
+
+// beginTx starts a transaction on db, or joins it when db is already one.
+func beginTx(ctx context.Context, db DBTX) (txHandle, error) {
+	switch db := db.(type) {
+	case *sql.Tx:
+		return joinedTx{db}, nil
+	case *sql.DB:
+		return db.BeginTx(ctx, nil)
+	case loggedDB:
+		tx, err := beginTx(ctx, db.DBTX)
+		if err != nil {
+			return nil, err
+		}
+
+		return loggedTx{loggedDB: loggedDB{DBTX: tx, threshold: db.threshold, logger: db.logger}, tx: tx}, nil
+	default:
+		panic("data: unsupported DBTX implementation")
+	}
+}
+
+// WithTx runs fn with a copy of the models bound to a single transaction,
+// committing when fn returns nil and rolling back otherwise. Model methods
+// that open their own transaction join this one, so a request made of
+// several steps is applied completely or not at all. Calling WithTx on
+// models that are already bound runs fn in the same transaction.
+func (m Models) WithTx(ctx context.Context, fn func(tx Models) error) error {
+	if m.db == nil {
+		return fn(m)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txm := newModels(tx, m.config)
+	txm.opts = m.opts
+	txm.apply()
+
+	err = fn(txm)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
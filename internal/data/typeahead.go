@@ -0,0 +1,120 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TypeaheadEntities are what typeahead can search.
+var TypeaheadEntities = []string{"users", "projects", "clients", "activities"}
+
+// TypeaheadLimit is the most options a typeahead search returns.
+const TypeaheadLimit = 20
+
+// TypeaheadOption is a row of a searchable select. The ID is what the
+// entity's other endpoints take: the project_id for projects, the internal
+// ID otherwise.
+type TypeaheadOption struct {
+	ID    int32  `json:"id"`
+	Label string `json:"label"`
+}
+
+// typeaheadQueries match the prefix $1 against columns with prefix indexes,
+// so a search reads no more rows than it returns. Each is followed by the
+// actor's scope and bound from $2.
+var typeaheadQueries = map[string]string{
+	"users": `
+		SELECT u.internal_id, concat_ws(' ', u.first_name, u.last_name)
+		FROM appuser u
+		WHERE u.erased_at IS NULL
+		AND (lower(u.first_name::text) LIKE $1 OR lower(u.last_name::text) LIKE $1 OR lower(u.email::text) LIKE $1)`,
+	"projects": `
+		SELECT p.project_id, concat_ws(' ', p.project_id, p.name)
+		FROM project p
+		WHERE p.deleted_at IS NULL AND p.archived_at IS NULL
+		AND (lower(p.name) LIKE $1 OR p.project_id::text LIKE $1 OR lower(p.proposal_id) LIKE $1)`,
+	"clients": `
+		SELECT c.internal_id, c.name
+		FROM client c
+		WHERE lower(c.name) LIKE $1`,
+	"activities": `
+		SELECT a.internal_id, a.name
+		FROM activity a
+		WHERE lower(a.name) LIKE $1`,
+}
+
+type TypeaheadModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+// Search returns up to TypeaheadLimit options of entity whose name, or for
+// users email and for projects project_id or proposal_id, starts with q,
+// ignoring case. Users, clients and activities are those of the actor's
+// organization and projects those it may see.
+func (m TypeaheadModel) Search(actor Actor, entity, q string) ([]TypeaheadOption, error) {
+	query, ok := typeaheadQueries[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown typeahead entity %q", entity)
+	}
+
+	args := []any{likePrefix(strings.ToLower(q))}
+
+	var scope string
+	var scopeArgs []any
+
+	switch entity {
+	case "projects":
+		scope, scopeArgs = actor.projectScope(2)
+	case "users":
+		scope, scopeArgs = actor.orgScope("u.org_internal_id", 2)
+	case "clients":
+		scope, scopeArgs = actor.orgScope("c.org_internal_id", 2)
+	case "activities":
+		scope, scopeArgs = actor.orgScope("a.org_internal_id", 2)
+	}
+
+	query += scope
+	args = append(args, scopeArgs...)
+
+	query += fmt.Sprintf(`
+		ORDER BY 2, 1
+		LIMIT %d`, TypeaheadLimit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	options := []TypeaheadOption{}
+
+	for rows.Next() {
+		var option TypeaheadOption
+
+		err := rows.Scan(&option.ID, &option.Label)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, option)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// likePrefix is a LIKE pattern matching values that start with s.
+func likePrefix(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s) + "%"
+}
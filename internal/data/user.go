@@ -0,0 +1,603 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+type User struct {
+	InternalID int32     `json:"id"`
+	Email      string    `json:"email"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	Activated  bool      `json:"activated"`
+	AvatarKey  *string   `json:"-"`
+	AvatarURL  *string   `json:"avatar_url" doc:"Signed download URL of the avatar. It expires, so fetch the user again for a fresh one."`
+	Language   string    `json:"language" doc:"The language emails are sent in, such as en or fr."`
+	Version    int32     `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ErrDuplicateEmail is returned when an email address belongs to another
+// account.
+var ErrDuplicateEmail = errors.New("duplicate email")
+
+// AnonymousUser stands for requests made without an authentication token.
+var AnonymousUser = &User{}
+
+func (u *User) IsAnonymous() bool {
+	return u == AnonymousUser
+}
+
+func ValidateEmail(v *validator.Validator, email string) {
+	v.Check(email != "", "email", "must be provided")
+	v.Check(validator.Matches(email, validator.EmailRX), "email", "must be a valid email address")
+}
+
+type UserModel struct {
+	DB      DBTX
+	ReadDB  DBTX
+	Timeout time.Duration
+}
+
+func (m UserModel) GetByEmail(email string) (*User, error) {
+	query := `
+		SELECT internal_id, email, first_name, last_name, activated, avatar_key, language, version, created_at, updated_at
+		FROM appuser
+		WHERE email = $1`
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, email).Scan(
+		&user.InternalID,
+		&user.Email,
+		&user.FirstName,
+		&user.LastName,
+		&user.Activated,
+		&user.AvatarKey,
+		&user.Language,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func (m UserModel) Get(id int32) (*User, error) {
+	query := `
+		SELECT internal_id, email, first_name, last_name, activated, avatar_key, language, version, created_at, updated_at
+		FROM appuser
+		WHERE internal_id = $1`
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&user.InternalID,
+		&user.Email,
+		&user.FirstName,
+		&user.LastName,
+		&user.Activated,
+		&user.AvatarKey,
+		&user.Language,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+// GetForActor is Get restricted to users in the actor's organization.
+func (m UserModel) GetForActor(actor Actor, id int32) (*User, error) {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+
+	query := `
+		SELECT internal_id, email, first_name, last_name, activated, avatar_key, language, version, created_at, updated_at
+		FROM appuser
+		WHERE internal_id = $1` + scope
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&user.InternalID,
+		&user.Email,
+		&user.FirstName,
+		&user.LastName,
+		&user.Activated,
+		&user.AvatarKey,
+		&user.Language,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func AvatarKey(userID int32) string {
+	return fmt.Sprintf("users/%d/avatar", userID)
+}
+
+func (m UserModel) UpdateAvatarKey(user *User) error {
+	query := `
+		UPDATE appuser
+		SET avatar_key = $1, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $2 AND version = $3
+		RETURNING version, updated_at`
+
+	args := []any{user.AvatarKey, user.InternalID, user.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.Version, &user.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetHourlyCost records what an hour of the user's time costs, in the
+// organization's base currency, when the user belongs to the actor's
+// organization. A nil cost clears it.
+func (m UserModel) SetHourlyCost(actor Actor, id int32, cost *float64) error {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 3)
+
+	query := `
+		UPDATE appuser
+		SET hourly_cost = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $1` + scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, append([]any{id, cost}, scopeArgs...)...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetLanguage returns the language the user's emails are sent in.
+func (m UserModel) GetLanguage(id int32) (string, error) {
+	query := `
+		SELECT language
+		FROM appuser
+		WHERE internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var language string
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&language)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return language, nil
+}
+
+// SetLanguage sets the language the user's emails are sent in.
+func (m UserModel) SetLanguage(id int32, language string) error {
+	query := `
+		UPDATE appuser
+		SET language = $2, version = version + 1, updated_at = NOW()
+		WHERE internal_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, language)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetAll lists the users of the actor's organization, limited to the
+// members of a team when teamID is non-zero.
+func (m UserModel) GetAll(actor Actor, teamID int32, filters Filters) ([]*User, Metadata, error) {
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), internal_id, email, first_name, last_name, activated, avatar_key, language, version, created_at, updated_at
+		FROM appuser
+		WHERE ($1 = 0 OR internal_id IN (
+			SELECT user_internal_id FROM team_member WHERE team_internal_id = $1
+		))%s
+		ORDER BY %s, internal_id ASC`, scope, filters.orderBy())
+
+	args := append([]any{teamID}, scopeArgs...)
+
+	if filters.limit() > 0 {
+		query += fmt.Sprintf(`
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+		args = append(args, filters.limit(), filters.offset())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := readDB(m.ReadDB, m.DB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	users := []*User{}
+
+	for rows.Next() {
+		var user User
+		err := rows.Scan(
+			&totalRecords,
+			&user.InternalID,
+			&user.Email,
+			&user.FirstName,
+			&user.LastName,
+			&user.Activated,
+			&user.AvatarKey,
+			&user.Language,
+			&user.Version,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return users, metadata, nil
+}
+
+// GetAllByEmails returns the users of the actor's organization registered
+// under the given addresses, keyed by lowercased email.
+func (m UserModel) GetAllByEmails(actor Actor, emails []string) (map[string]*User, error) {
+	query := `
+		SELECT internal_id, email, first_name, last_name, activated, avatar_key, language, version, created_at, updated_at
+		FROM appuser
+		WHERE email = ANY($1::citext[])`
+
+	scope, scopeArgs := actor.orgScope("org_internal_id", 2)
+	query += scope
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, append([]any{pq.Array(emails)}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := make(map[string]*User)
+
+	for rows.Next() {
+		var user User
+		err := rows.Scan(
+			&user.InternalID,
+			&user.Email,
+			&user.FirstName,
+			&user.LastName,
+			&user.Activated,
+			&user.AvatarKey,
+			&user.Language,
+			&user.Version,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		users[strings.ToLower(user.Email)] = &user
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Import creates invited, unactivated accounts in the organization orgID in a
+// single transaction and grants each the permissions of its role. Users whose email is already
+// registered are left untouched, so the returned slice holds only the
+// accounts that were created.
+func (m UserModel) Import(orgID int32, users []*User, roles map[string]string) ([]*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created := []*User{}
+
+	for _, user := range users {
+		query := `
+			INSERT INTO appuser (email, first_name, last_name, activated, org_internal_id)
+			VALUES ($1, $2, $3, false, $4)
+			ON CONFLICT (email) DO NOTHING
+			RETURNING internal_id, activated, version, created_at, updated_at`
+
+		err := tx.QueryRowContext(ctx, query, user.Email, user.FirstName, user.LastName, orgID).Scan(
+			&user.InternalID,
+			&user.Activated,
+			&user.Version,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				continue
+			default:
+				return nil, err
+			}
+		}
+
+		query = `
+			INSERT INTO appuser_permission (user_internal_id, permission_internal_id)
+			SELECT $1, permission_internal_id FROM role_permission WHERE role = $2`
+
+		_, err = tx.ExecContext(ctx, query, user.InternalID, roles[user.Email])
+		if err != nil {
+			return nil, err
+		}
+
+		created = append(created, user)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Erase replaces a user's personal data with a random pseudonym, revokes
+// their tokens and drops their permissions, notifications and team
+// memberships. Records such as timesheet entries keep pointing at the same
+// account, so history survives under the pseudonym. The erasure is audited
+// in the same transaction. Erasing an already erased user returns
+// ErrRecordNotFound.
+func (m UserModel) Erase(user *User) error {
+	pseudonym := make([]byte, 6)
+	_, err := rand.Read(pseudonym)
+	if err != nil {
+		return err
+	}
+	code := hex.EncodeToString(pseudonym)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := beginTx(ctx, m.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE appuser
+		SET email = $1, pending_email = NULL, first_name = 'Former user', last_name = $2, password_hash = NULL,
+			activated = false, avatar_key = NULL, erased_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE internal_id = $3 AND erased_at IS NULL
+		RETURNING email, first_name, last_name, activated, avatar_key, version, updated_at`
+
+	err = tx.QueryRowContext(ctx, query, code+"@erased.invalid", code, user.InternalID).Scan(
+		&user.Email,
+		&user.FirstName,
+		&user.LastName,
+		&user.Activated,
+		&user.AvatarKey,
+		&user.Version,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	for _, query := range []string{
+		`DELETE FROM token WHERE appuser_internal_id = $1`,
+		`DELETE FROM appuser_permission WHERE user_internal_id = $1`,
+		`DELETE FROM appuser_role WHERE user_internal_id = $1`,
+		`DELETE FROM notification WHERE appuser_internal_id = $1`,
+		`DELETE FROM notification_preference WHERE appuser_internal_id = $1`,
+		`DELETE FROM team_member WHERE user_internal_id = $1`,
+		`DELETE FROM approval_delegation WHERE delegator_internal_id = $1 OR delegate_internal_id = $1`,
+		`DELETE FROM security_event WHERE user_internal_id = $1`,
+	} {
+		_, err = tx.ExecContext(ctx, query, user.InternalID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertAudit(ctx, tx, &AuditEntry{
+		Action:   "user.personal_data_erased",
+		Entity:   "user",
+		EntityID: strconv.Itoa(int(user.InternalID)),
+		Detail:   json.RawMessage(fmt.Sprintf(`{"pseudonym": %q}`, code)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetPendingEmail records the address a user asked to change their email
+// to. It only replaces their email once ConfirmEmail is called.
+func (m UserModel) SetPendingEmail(id int32, email string) error {
+	query := `
+		UPDATE appuser
+		SET pending_email = $1
+		WHERE internal_id = $2 AND erased_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, email, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ConfirmEmail replaces a user's email with their pending one and returns
+// the previous address. ErrRecordNotFound is returned when no change is
+// pending, and ErrDuplicateEmail when another account took the address
+// meanwhile.
+func (m UserModel) ConfirmEmail(user *User) (string, error) {
+	query := `
+		UPDATE appuser u
+		SET email = u.pending_email, pending_email = NULL, version = u.version + 1, updated_at = NOW()
+		FROM appuser old
+		WHERE u.internal_id = $1 AND old.internal_id = u.internal_id AND u.pending_email IS NOT NULL
+		RETURNING old.email, u.email, u.version, u.updated_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	var previous string
+
+	err := m.DB.QueryRowContext(ctx, query, user.InternalID).Scan(&previous, &user.Email, &user.Version, &user.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "appuser_email_key"`:
+			return "", ErrDuplicateEmail
+		default:
+			return "", err
+		}
+	}
+
+	return previous, nil
+}
+
+// PurgeUnactivated deletes accounts created before cutoff that were never
+// activated and returns how many there were. Erased accounts and accounts
+// that own timesheet entries or approval steps are kept. With dryRun the
+// accounts are only counted.
+func (m UserModel) PurgeUnactivated(cutoff time.Time, dryRun bool) (int64, error) {
+	filter := `
+		WHERE activated = false AND erased_at IS NULL AND created_at < $1
+		AND NOT EXISTS (SELECT 1 FROM timesheet_entry WHERE user_internal_id = appuser.internal_id)
+		AND NOT EXISTS (SELECT 1 FROM approval_step WHERE approver_internal_id = appuser.internal_id)`
+
+	query := `DELETE FROM appuser` + filter
+	if dryRun {
+		query = `SELECT count(*) FROM appuser` + filter
+	}
+
+	return countOrExec(m.DB, query, dryRun, cutoff)
+}
@@ -0,0 +1,228 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+
+	"github.com/lib/pq"
+)
+
+// Rounding modes for billed minutes.
+const (
+	RoundUp      = "up"
+	RoundDown    = "down"
+	RoundNearest = "nearest"
+)
+
+// WorkRules are how an organization records and costs time. Entries are
+// billed rounded to IncrementMinutes as Rounding says when submitted, and a
+// user's day may hold no more than MaxDayMinutes. Time past DayMinutes on a
+// day is overtime; overtime and weekend time are costed at their
+// multipliers, the higher one when both apply. Rules never saved have
+// version 0 and no updated_at.
+type WorkRules struct {
+	OrgID              int32      `json:"organization_id"`
+	DayMinutes         int32      `json:"day_minutes"`
+	MaxDayMinutes      int32      `json:"max_day_minutes"`
+	OvertimeMultiplier float64    `json:"overtime_multiplier"`
+	WeekendMultiplier  float64    `json:"weekend_multiplier"`
+	IncrementMinutes   int32      `json:"increment_minutes"`
+	Rounding           string     `json:"rounding"`
+	Version            int32      `json:"version"`
+	UpdatedAt          *time.Time `json:"updated_at"`
+}
+
+// DefaultWorkRules are the rules of an organization that has saved none:
+// an eight hour day, up to 24 hours logged and every minute costed alike.
+func DefaultWorkRules(orgID int32) *WorkRules {
+	return &WorkRules{
+		OrgID:              orgID,
+		DayMinutes:         480,
+		MaxDayMinutes:      MaxDailyMinutes,
+		OvertimeMultiplier: 1,
+		WeekendMultiplier:  1,
+		IncrementMinutes:   1,
+		Rounding:           RoundUp,
+	}
+}
+
+func ValidateWorkRules(v *validator.Validator, r *WorkRules) {
+	v.Check(r.DayMinutes > 0, "day_minutes", "must be greater than zero")
+	v.Check(r.DayMinutes <= MaxDailyMinutes, "day_minutes", "must not be more than 1440")
+
+	v.Check(r.MaxDayMinutes >= r.DayMinutes, "max_day_minutes", "must not be less than day_minutes")
+	v.Check(r.MaxDayMinutes <= MaxDailyMinutes, "max_day_minutes", "must not be more than 1440")
+
+	v.Check(r.OvertimeMultiplier >= 1, "overtime_multiplier", "must be at least 1")
+	v.Check(r.OvertimeMultiplier <= 10, "overtime_multiplier", "must not be more than 10")
+
+	v.Check(r.WeekendMultiplier >= 1, "weekend_multiplier", "must be at least 1")
+	v.Check(r.WeekendMultiplier <= 10, "weekend_multiplier", "must not be more than 10")
+
+	v.Check(r.IncrementMinutes > 0, "increment_minutes", "must be greater than zero")
+	v.Check(r.IncrementMinutes <= 60, "increment_minutes", "must not be more than 60")
+
+	v.Check(validator.PermittedValue(r.Rounding, RoundUp, RoundDown, RoundNearest), "rounding", "must be up, down or nearest")
+}
+
+// Round returns the minutes billed for an entry of minutes. Rounding down
+// or to the nearest increment never bills less than one increment.
+func (r *WorkRules) Round(minutes int32) int32 {
+	step := r.IncrementMinutes
+	if step <= 1 || minutes <= 0 || minutes%step == 0 {
+		return minutes
+	}
+
+	down := minutes / step * step
+
+	switch r.Rounding {
+	case RoundDown:
+	case RoundNearest:
+		if minutes-down >= (step+1)/2 {
+			down += step
+		}
+	default:
+		down += step
+	}
+
+	return max(down, step)
+}
+
+type WorkRulesModel struct {
+	DB      DBTX
+	Timeout time.Duration
+}
+
+// Get returns an organization's rules, the defaults when it has saved none.
+func (m WorkRulesModel) Get(orgID int32) (*WorkRules, error) {
+	query := `
+		SELECT day_minutes, max_day_minutes, overtime_multiplier::float8, weekend_multiplier::float8,
+			increment_minutes, rounding, version, updated_at
+		FROM work_rules
+		WHERE org_internal_id = $1`
+
+	r := DefaultWorkRules(orgID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, orgID).Scan(
+		&r.DayMinutes,
+		&r.MaxDayMinutes,
+		&r.OvertimeMultiplier,
+		&r.WeekendMultiplier,
+		&r.IncrementMinutes,
+		&r.Rounding,
+		&r.Version,
+		&r.UpdatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetForUsers returns the rules of each user's organization, keyed by user
+// ID. Unknown users are left out.
+func (m WorkRulesModel) GetForUsers(userIDs []int32) (map[int32]*WorkRules, error) {
+	query := `
+		SELECT u.internal_id, u.org_internal_id,
+			COALESCE(r.day_minutes, 0), COALESCE(r.max_day_minutes, 0),
+			COALESCE(r.overtime_multiplier, 0)::float8, COALESCE(r.weekend_multiplier, 0)::float8,
+			COALESCE(r.increment_minutes, 0), COALESCE(r.rounding, ''), COALESCE(r.version, 0), r.updated_at
+		FROM appuser u
+		LEFT JOIN work_rules r ON r.org_internal_id = u.org_internal_id
+		WHERE u.internal_id = ANY($1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rules := make(map[int32]*WorkRules, len(userIDs))
+
+	for rows.Next() {
+		var userID int32
+		var r WorkRules
+
+		err := rows.Scan(
+			&userID,
+			&r.OrgID,
+			&r.DayMinutes,
+			&r.MaxDayMinutes,
+			&r.OvertimeMultiplier,
+			&r.WeekendMultiplier,
+			&r.IncrementMinutes,
+			&r.Rounding,
+			&r.Version,
+			&r.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Version == 0 {
+			rules[userID] = DefaultWorkRules(r.OrgID)
+			continue
+		}
+
+		rules[userID] = &r
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Update saves an organization's rules, creating them when r.Version is 0,
+// provided they are still at r.Version.
+func (m WorkRulesModel) Update(r *WorkRules) error {
+	query := `
+		INSERT INTO work_rules (org_internal_id, day_minutes, max_day_minutes, overtime_multiplier, weekend_multiplier, increment_minutes, rounding)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (org_internal_id) DO UPDATE
+		SET day_minutes = EXCLUDED.day_minutes, max_day_minutes = EXCLUDED.max_day_minutes,
+			overtime_multiplier = EXCLUDED.overtime_multiplier, weekend_multiplier = EXCLUDED.weekend_multiplier,
+			increment_minutes = EXCLUDED.increment_minutes, rounding = EXCLUDED.rounding,
+			version = work_rules.version + 1, updated_at = NOW()
+		WHERE work_rules.version = $8
+		RETURNING version, updated_at`
+
+	args := []any{
+		r.OrgID,
+		r.DayMinutes,
+		r.MaxDayMinutes,
+		r.OvertimeMultiplier,
+		r.WeekendMultiplier,
+		r.IncrementMinutes,
+		r.Rounding,
+		r.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout(m.Timeout))
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&r.Version, &r.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
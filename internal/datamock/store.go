@@ -0,0 +1,11285 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package datamock
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/hwanbin/wanpm-api/internal/codefmt"
+	"github.com/hwanbin/wanpm-api/internal/data"
+	"io"
+	"sync"
+	"time"
+)
+
+// Ensure, that AccountingMappingStoreMock does implement data.AccountingMappingStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.AccountingMappingStore = &AccountingMappingStoreMock{}
+
+// AccountingMappingStoreMock is a mock implementation of data.AccountingMappingStore.
+//
+//	func TestSomethingThatUsesAccountingMappingStore(t *testing.T) {
+//
+//		// make and configure a mocked data.AccountingMappingStore
+//		mockedAccountingMappingStore := &AccountingMappingStoreMock{
+//			DeleteCustomerFunc: func(actor data.Actor, externalID int32) error {
+//				panic("mock out the DeleteCustomer method")
+//			},
+//			DeleteServiceItemFunc: func(actor data.Actor, activityID int32) error {
+//				panic("mock out the DeleteServiceItem method")
+//			},
+//			GetCustomersFunc: func(actor data.Actor) ([]*data.CustomerMapping, error) {
+//				panic("mock out the GetCustomers method")
+//			},
+//			GetLinesFunc: func(actor data.Actor, from time.Time, to time.Time) ([]*data.AccountingLine, error) {
+//				panic("mock out the GetLines method")
+//			},
+//			GetServiceItemsFunc: func(actor data.Actor) ([]*data.ServiceItemMapping, error) {
+//				panic("mock out the GetServiceItems method")
+//			},
+//			SetCustomerFunc: func(actor data.Actor, mapping *data.CustomerMapping) error {
+//				panic("mock out the SetCustomer method")
+//			},
+//			SetServiceItemFunc: func(actor data.Actor, mapping *data.ServiceItemMapping) error {
+//				panic("mock out the SetServiceItem method")
+//			},
+//		}
+//
+//		// use mockedAccountingMappingStore in code that requires data.AccountingMappingStore
+//		// and then make assertions.
+//
+//	}
+type AccountingMappingStoreMock struct {
+	// DeleteCustomerFunc mocks the DeleteCustomer method.
+	DeleteCustomerFunc func(actor data.Actor, externalID int32) error
+
+	// DeleteServiceItemFunc mocks the DeleteServiceItem method.
+	DeleteServiceItemFunc func(actor data.Actor, activityID int32) error
+
+	// GetCustomersFunc mocks the GetCustomers method.
+	GetCustomersFunc func(actor data.Actor) ([]*data.CustomerMapping, error)
+
+	// GetLinesFunc mocks the GetLines method.
+	GetLinesFunc func(actor data.Actor, from time.Time, to time.Time) ([]*data.AccountingLine, error)
+
+	// GetServiceItemsFunc mocks the GetServiceItems method.
+	GetServiceItemsFunc func(actor data.Actor) ([]*data.ServiceItemMapping, error)
+
+	// SetCustomerFunc mocks the SetCustomer method.
+	SetCustomerFunc func(actor data.Actor, mapping *data.CustomerMapping) error
+
+	// SetServiceItemFunc mocks the SetServiceItem method.
+	SetServiceItemFunc func(actor data.Actor, mapping *data.ServiceItemMapping) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteCustomer holds details about calls to the DeleteCustomer method.
+		DeleteCustomer []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// DeleteServiceItem holds details about calls to the DeleteServiceItem method.
+		DeleteServiceItem []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ActivityID is the activityID argument value.
+			ActivityID int32
+		}
+		// GetCustomers holds details about calls to the GetCustomers method.
+		GetCustomers []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+		}
+		// GetLines holds details about calls to the GetLines method.
+		GetLines []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// From is the from argument value.
+			From time.Time
+			// To is the to argument value.
+			To time.Time
+		}
+		// GetServiceItems holds details about calls to the GetServiceItems method.
+		GetServiceItems []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+		}
+		// SetCustomer holds details about calls to the SetCustomer method.
+		SetCustomer []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Mapping is the mapping argument value.
+			Mapping *data.CustomerMapping
+		}
+		// SetServiceItem holds details about calls to the SetServiceItem method.
+		SetServiceItem []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Mapping is the mapping argument value.
+			Mapping *data.ServiceItemMapping
+		}
+	}
+	lockDeleteCustomer    sync.RWMutex
+	lockDeleteServiceItem sync.RWMutex
+	lockGetCustomers      sync.RWMutex
+	lockGetLines          sync.RWMutex
+	lockGetServiceItems   sync.RWMutex
+	lockSetCustomer       sync.RWMutex
+	lockSetServiceItem    sync.RWMutex
+}
+
+// DeleteCustomer calls DeleteCustomerFunc.
+func (mock *AccountingMappingStoreMock) DeleteCustomer(actor data.Actor, externalID int32) error {
+	callInfo := struct {
+		Actor      data.Actor
+		ExternalID int32
+	}{
+		Actor:      actor,
+		ExternalID: externalID,
+	}
+	mock.lockDeleteCustomer.Lock()
+	mock.calls.DeleteCustomer = append(mock.calls.DeleteCustomer, callInfo)
+	mock.lockDeleteCustomer.Unlock()
+	if mock.DeleteCustomerFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteCustomerFunc(actor, externalID)
+}
+
+// DeleteCustomerCalls gets all the calls that were made to DeleteCustomer.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.DeleteCustomerCalls())
+func (mock *AccountingMappingStoreMock) DeleteCustomerCalls() []struct {
+	Actor      data.Actor
+	ExternalID int32
+} {
+	var calls []struct {
+		Actor      data.Actor
+		ExternalID int32
+	}
+	mock.lockDeleteCustomer.RLock()
+	calls = mock.calls.DeleteCustomer
+	mock.lockDeleteCustomer.RUnlock()
+	return calls
+}
+
+// DeleteServiceItem calls DeleteServiceItemFunc.
+func (mock *AccountingMappingStoreMock) DeleteServiceItem(actor data.Actor, activityID int32) error {
+	callInfo := struct {
+		Actor      data.Actor
+		ActivityID int32
+	}{
+		Actor:      actor,
+		ActivityID: activityID,
+	}
+	mock.lockDeleteServiceItem.Lock()
+	mock.calls.DeleteServiceItem = append(mock.calls.DeleteServiceItem, callInfo)
+	mock.lockDeleteServiceItem.Unlock()
+	if mock.DeleteServiceItemFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteServiceItemFunc(actor, activityID)
+}
+
+// DeleteServiceItemCalls gets all the calls that were made to DeleteServiceItem.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.DeleteServiceItemCalls())
+func (mock *AccountingMappingStoreMock) DeleteServiceItemCalls() []struct {
+	Actor      data.Actor
+	ActivityID int32
+} {
+	var calls []struct {
+		Actor      data.Actor
+		ActivityID int32
+	}
+	mock.lockDeleteServiceItem.RLock()
+	calls = mock.calls.DeleteServiceItem
+	mock.lockDeleteServiceItem.RUnlock()
+	return calls
+}
+
+// GetCustomers calls GetCustomersFunc.
+func (mock *AccountingMappingStoreMock) GetCustomers(actor data.Actor) ([]*data.CustomerMapping, error) {
+	callInfo := struct {
+		Actor data.Actor
+	}{
+		Actor: actor,
+	}
+	mock.lockGetCustomers.Lock()
+	mock.calls.GetCustomers = append(mock.calls.GetCustomers, callInfo)
+	mock.lockGetCustomers.Unlock()
+	if mock.GetCustomersFunc == nil {
+		var (
+			customerMappingsOut []*data.CustomerMapping
+			errOut              error
+		)
+		return customerMappingsOut, errOut
+	}
+	return mock.GetCustomersFunc(actor)
+}
+
+// GetCustomersCalls gets all the calls that were made to GetCustomers.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.GetCustomersCalls())
+func (mock *AccountingMappingStoreMock) GetCustomersCalls() []struct {
+	Actor data.Actor
+} {
+	var calls []struct {
+		Actor data.Actor
+	}
+	mock.lockGetCustomers.RLock()
+	calls = mock.calls.GetCustomers
+	mock.lockGetCustomers.RUnlock()
+	return calls
+}
+
+// GetLines calls GetLinesFunc.
+func (mock *AccountingMappingStoreMock) GetLines(actor data.Actor, from time.Time, to time.Time) ([]*data.AccountingLine, error) {
+	callInfo := struct {
+		Actor data.Actor
+		From  time.Time
+		To    time.Time
+	}{
+		Actor: actor,
+		From:  from,
+		To:    to,
+	}
+	mock.lockGetLines.Lock()
+	mock.calls.GetLines = append(mock.calls.GetLines, callInfo)
+	mock.lockGetLines.Unlock()
+	if mock.GetLinesFunc == nil {
+		var (
+			accountingLinesOut []*data.AccountingLine
+			errOut             error
+		)
+		return accountingLinesOut, errOut
+	}
+	return mock.GetLinesFunc(actor, from, to)
+}
+
+// GetLinesCalls gets all the calls that were made to GetLines.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.GetLinesCalls())
+func (mock *AccountingMappingStoreMock) GetLinesCalls() []struct {
+	Actor data.Actor
+	From  time.Time
+	To    time.Time
+} {
+	var calls []struct {
+		Actor data.Actor
+		From  time.Time
+		To    time.Time
+	}
+	mock.lockGetLines.RLock()
+	calls = mock.calls.GetLines
+	mock.lockGetLines.RUnlock()
+	return calls
+}
+
+// GetServiceItems calls GetServiceItemsFunc.
+func (mock *AccountingMappingStoreMock) GetServiceItems(actor data.Actor) ([]*data.ServiceItemMapping, error) {
+	callInfo := struct {
+		Actor data.Actor
+	}{
+		Actor: actor,
+	}
+	mock.lockGetServiceItems.Lock()
+	mock.calls.GetServiceItems = append(mock.calls.GetServiceItems, callInfo)
+	mock.lockGetServiceItems.Unlock()
+	if mock.GetServiceItemsFunc == nil {
+		var (
+			serviceItemMappingsOut []*data.ServiceItemMapping
+			errOut                 error
+		)
+		return serviceItemMappingsOut, errOut
+	}
+	return mock.GetServiceItemsFunc(actor)
+}
+
+// GetServiceItemsCalls gets all the calls that were made to GetServiceItems.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.GetServiceItemsCalls())
+func (mock *AccountingMappingStoreMock) GetServiceItemsCalls() []struct {
+	Actor data.Actor
+} {
+	var calls []struct {
+		Actor data.Actor
+	}
+	mock.lockGetServiceItems.RLock()
+	calls = mock.calls.GetServiceItems
+	mock.lockGetServiceItems.RUnlock()
+	return calls
+}
+
+// SetCustomer calls SetCustomerFunc.
+func (mock *AccountingMappingStoreMock) SetCustomer(actor data.Actor, mapping *data.CustomerMapping) error {
+	callInfo := struct {
+		Actor   data.Actor
+		Mapping *data.CustomerMapping
+	}{
+		Actor:   actor,
+		Mapping: mapping,
+	}
+	mock.lockSetCustomer.Lock()
+	mock.calls.SetCustomer = append(mock.calls.SetCustomer, callInfo)
+	mock.lockSetCustomer.Unlock()
+	if mock.SetCustomerFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetCustomerFunc(actor, mapping)
+}
+
+// SetCustomerCalls gets all the calls that were made to SetCustomer.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.SetCustomerCalls())
+func (mock *AccountingMappingStoreMock) SetCustomerCalls() []struct {
+	Actor   data.Actor
+	Mapping *data.CustomerMapping
+} {
+	var calls []struct {
+		Actor   data.Actor
+		Mapping *data.CustomerMapping
+	}
+	mock.lockSetCustomer.RLock()
+	calls = mock.calls.SetCustomer
+	mock.lockSetCustomer.RUnlock()
+	return calls
+}
+
+// SetServiceItem calls SetServiceItemFunc.
+func (mock *AccountingMappingStoreMock) SetServiceItem(actor data.Actor, mapping *data.ServiceItemMapping) error {
+	callInfo := struct {
+		Actor   data.Actor
+		Mapping *data.ServiceItemMapping
+	}{
+		Actor:   actor,
+		Mapping: mapping,
+	}
+	mock.lockSetServiceItem.Lock()
+	mock.calls.SetServiceItem = append(mock.calls.SetServiceItem, callInfo)
+	mock.lockSetServiceItem.Unlock()
+	if mock.SetServiceItemFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetServiceItemFunc(actor, mapping)
+}
+
+// SetServiceItemCalls gets all the calls that were made to SetServiceItem.
+// Check the length with:
+//
+//	len(mockedAccountingMappingStore.SetServiceItemCalls())
+func (mock *AccountingMappingStoreMock) SetServiceItemCalls() []struct {
+	Actor   data.Actor
+	Mapping *data.ServiceItemMapping
+} {
+	var calls []struct {
+		Actor   data.Actor
+		Mapping *data.ServiceItemMapping
+	}
+	mock.lockSetServiceItem.RLock()
+	calls = mock.calls.SetServiceItem
+	mock.lockSetServiceItem.RUnlock()
+	return calls
+}
+
+// Ensure, that ActivityStoreMock does implement data.ActivityStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ActivityStore = &ActivityStoreMock{}
+
+// ActivityStoreMock is a mock implementation of data.ActivityStore.
+//
+//	func TestSomethingThatUsesActivityStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ActivityStore
+//		mockedActivityStore := &ActivityStoreMock{
+//			DeleteFunc: func(actor data.Actor, id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, id int32) (*data.Activity, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor) ([]*data.Activity, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			GetAllForProjectFunc: func(externalID int32) ([]*data.Activity, error) {
+//				panic("mock out the GetAllForProject method")
+//			},
+//			InsertFunc: func(activity *data.Activity) error {
+//				panic("mock out the Insert method")
+//			},
+//			IsDescendantFunc: func(id int32, candidate int32) (bool, error) {
+//				panic("mock out the IsDescendant method")
+//			},
+//			IsEnabledForProjectFunc: func(projectInternalID int32, activityID int32) (bool, error) {
+//				panic("mock out the IsEnabledForProject method")
+//			},
+//			SetForProjectFunc: func(projectInternalID int32, activityIDs []int32) error {
+//				panic("mock out the SetForProject method")
+//			},
+//			UpdateFunc: func(activity *data.Activity) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedActivityStore in code that requires data.ActivityStore
+//		// and then make assertions.
+//
+//	}
+type ActivityStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(actor data.Actor, id int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, id int32) (*data.Activity, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor) ([]*data.Activity, error)
+
+	// GetAllForProjectFunc mocks the GetAllForProject method.
+	GetAllForProjectFunc func(externalID int32) ([]*data.Activity, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(activity *data.Activity) error
+
+	// IsDescendantFunc mocks the IsDescendant method.
+	IsDescendantFunc func(id int32, candidate int32) (bool, error)
+
+	// IsEnabledForProjectFunc mocks the IsEnabledForProject method.
+	IsEnabledForProjectFunc func(projectInternalID int32, activityID int32) (bool, error)
+
+	// SetForProjectFunc mocks the SetForProject method.
+	SetForProjectFunc func(projectInternalID int32, activityIDs []int32) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(activity *data.Activity) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+		}
+		// GetAllForProject holds details about calls to the GetAllForProject method.
+		GetAllForProject []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Activity is the activity argument value.
+			Activity *data.Activity
+		}
+		// IsDescendant holds details about calls to the IsDescendant method.
+		IsDescendant []struct {
+			// ID is the id argument value.
+			ID int32
+			// Candidate is the candidate argument value.
+			Candidate int32
+		}
+		// IsEnabledForProject holds details about calls to the IsEnabledForProject method.
+		IsEnabledForProject []struct {
+			// ProjectInternalID is the projectInternalID argument value.
+			ProjectInternalID int32
+			// ActivityID is the activityID argument value.
+			ActivityID int32
+		}
+		// SetForProject holds details about calls to the SetForProject method.
+		SetForProject []struct {
+			// ProjectInternalID is the projectInternalID argument value.
+			ProjectInternalID int32
+			// ActivityIDs is the activityIDs argument value.
+			ActivityIDs []int32
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Activity is the activity argument value.
+			Activity *data.Activity
+		}
+	}
+	lockDelete              sync.RWMutex
+	lockGet                 sync.RWMutex
+	lockGetAll              sync.RWMutex
+	lockGetAllForProject    sync.RWMutex
+	lockInsert              sync.RWMutex
+	lockIsDescendant        sync.RWMutex
+	lockIsEnabledForProject sync.RWMutex
+	lockSetForProject       sync.RWMutex
+	lockUpdate              sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *ActivityStoreMock) Delete(actor data.Actor, id int32) error {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(actor, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedActivityStore.DeleteCalls())
+func (mock *ActivityStoreMock) DeleteCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *ActivityStoreMock) Get(actor data.Actor, id int32) (*data.Activity, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			activityOut *data.Activity
+			errOut      error
+		)
+		return activityOut, errOut
+	}
+	return mock.GetFunc(actor, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedActivityStore.GetCalls())
+func (mock *ActivityStoreMock) GetCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *ActivityStoreMock) GetAll(actor data.Actor) ([]*data.Activity, error) {
+	callInfo := struct {
+		Actor data.Actor
+	}{
+		Actor: actor,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			activitysOut []*data.Activity
+			errOut       error
+		)
+		return activitysOut, errOut
+	}
+	return mock.GetAllFunc(actor)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedActivityStore.GetAllCalls())
+func (mock *ActivityStoreMock) GetAllCalls() []struct {
+	Actor data.Actor
+} {
+	var calls []struct {
+		Actor data.Actor
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetAllForProject calls GetAllForProjectFunc.
+func (mock *ActivityStoreMock) GetAllForProject(externalID int32) ([]*data.Activity, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetAllForProject.Lock()
+	mock.calls.GetAllForProject = append(mock.calls.GetAllForProject, callInfo)
+	mock.lockGetAllForProject.Unlock()
+	if mock.GetAllForProjectFunc == nil {
+		var (
+			activitysOut []*data.Activity
+			errOut       error
+		)
+		return activitysOut, errOut
+	}
+	return mock.GetAllForProjectFunc(externalID)
+}
+
+// GetAllForProjectCalls gets all the calls that were made to GetAllForProject.
+// Check the length with:
+//
+//	len(mockedActivityStore.GetAllForProjectCalls())
+func (mock *ActivityStoreMock) GetAllForProjectCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetAllForProject.RLock()
+	calls = mock.calls.GetAllForProject
+	mock.lockGetAllForProject.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *ActivityStoreMock) Insert(activity *data.Activity) error {
+	callInfo := struct {
+		Activity *data.Activity
+	}{
+		Activity: activity,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(activity)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedActivityStore.InsertCalls())
+func (mock *ActivityStoreMock) InsertCalls() []struct {
+	Activity *data.Activity
+} {
+	var calls []struct {
+		Activity *data.Activity
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// IsDescendant calls IsDescendantFunc.
+func (mock *ActivityStoreMock) IsDescendant(id int32, candidate int32) (bool, error) {
+	callInfo := struct {
+		ID        int32
+		Candidate int32
+	}{
+		ID:        id,
+		Candidate: candidate,
+	}
+	mock.lockIsDescendant.Lock()
+	mock.calls.IsDescendant = append(mock.calls.IsDescendant, callInfo)
+	mock.lockIsDescendant.Unlock()
+	if mock.IsDescendantFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.IsDescendantFunc(id, candidate)
+}
+
+// IsDescendantCalls gets all the calls that were made to IsDescendant.
+// Check the length with:
+//
+//	len(mockedActivityStore.IsDescendantCalls())
+func (mock *ActivityStoreMock) IsDescendantCalls() []struct {
+	ID        int32
+	Candidate int32
+} {
+	var calls []struct {
+		ID        int32
+		Candidate int32
+	}
+	mock.lockIsDescendant.RLock()
+	calls = mock.calls.IsDescendant
+	mock.lockIsDescendant.RUnlock()
+	return calls
+}
+
+// IsEnabledForProject calls IsEnabledForProjectFunc.
+func (mock *ActivityStoreMock) IsEnabledForProject(projectInternalID int32, activityID int32) (bool, error) {
+	callInfo := struct {
+		ProjectInternalID int32
+		ActivityID        int32
+	}{
+		ProjectInternalID: projectInternalID,
+		ActivityID:        activityID,
+	}
+	mock.lockIsEnabledForProject.Lock()
+	mock.calls.IsEnabledForProject = append(mock.calls.IsEnabledForProject, callInfo)
+	mock.lockIsEnabledForProject.Unlock()
+	if mock.IsEnabledForProjectFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.IsEnabledForProjectFunc(projectInternalID, activityID)
+}
+
+// IsEnabledForProjectCalls gets all the calls that were made to IsEnabledForProject.
+// Check the length with:
+//
+//	len(mockedActivityStore.IsEnabledForProjectCalls())
+func (mock *ActivityStoreMock) IsEnabledForProjectCalls() []struct {
+	ProjectInternalID int32
+	ActivityID        int32
+} {
+	var calls []struct {
+		ProjectInternalID int32
+		ActivityID        int32
+	}
+	mock.lockIsEnabledForProject.RLock()
+	calls = mock.calls.IsEnabledForProject
+	mock.lockIsEnabledForProject.RUnlock()
+	return calls
+}
+
+// SetForProject calls SetForProjectFunc.
+func (mock *ActivityStoreMock) SetForProject(projectInternalID int32, activityIDs []int32) error {
+	callInfo := struct {
+		ProjectInternalID int32
+		ActivityIDs       []int32
+	}{
+		ProjectInternalID: projectInternalID,
+		ActivityIDs:       activityIDs,
+	}
+	mock.lockSetForProject.Lock()
+	mock.calls.SetForProject = append(mock.calls.SetForProject, callInfo)
+	mock.lockSetForProject.Unlock()
+	if mock.SetForProjectFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetForProjectFunc(projectInternalID, activityIDs)
+}
+
+// SetForProjectCalls gets all the calls that were made to SetForProject.
+// Check the length with:
+//
+//	len(mockedActivityStore.SetForProjectCalls())
+func (mock *ActivityStoreMock) SetForProjectCalls() []struct {
+	ProjectInternalID int32
+	ActivityIDs       []int32
+} {
+	var calls []struct {
+		ProjectInternalID int32
+		ActivityIDs       []int32
+	}
+	mock.lockSetForProject.RLock()
+	calls = mock.calls.SetForProject
+	mock.lockSetForProject.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *ActivityStoreMock) Update(activity *data.Activity) error {
+	callInfo := struct {
+		Activity *data.Activity
+	}{
+		Activity: activity,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(activity)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedActivityStore.UpdateCalls())
+func (mock *ActivityStoreMock) UpdateCalls() []struct {
+	Activity *data.Activity
+} {
+	var calls []struct {
+		Activity *data.Activity
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that AllocationStoreMock does implement data.AllocationStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.AllocationStore = &AllocationStoreMock{}
+
+// AllocationStoreMock is a mock implementation of data.AllocationStore.
+//
+//	func TestSomethingThatUsesAllocationStore(t *testing.T) {
+//
+//		// make and configure a mocked data.AllocationStore
+//		mockedAllocationStore := &AllocationStoreMock{
+//			DeleteFunc: func(actor data.Actor, id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, id int32) (*data.Allocation, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, filter data.AllocationFilter) ([]*data.Allocation, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(actor data.Actor, a *data.Allocation, available float64) error {
+//				panic("mock out the Insert method")
+//			},
+//			UpdateFunc: func(a *data.Allocation, available float64) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedAllocationStore in code that requires data.AllocationStore
+//		// and then make assertions.
+//
+//	}
+type AllocationStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(actor data.Actor, id int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, id int32) (*data.Allocation, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, filter data.AllocationFilter) ([]*data.Allocation, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(actor data.Actor, a *data.Allocation, available float64) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(a *data.Allocation, available float64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Filter is the filter argument value.
+			Filter data.AllocationFilter
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// A is the a argument value.
+			A *data.Allocation
+			// Available is the available argument value.
+			Available float64
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// A is the a argument value.
+			A *data.Allocation
+			// Available is the available argument value.
+			Available float64
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockGetAll sync.RWMutex
+	lockInsert sync.RWMutex
+	lockUpdate sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *AllocationStoreMock) Delete(actor data.Actor, id int32) error {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(actor, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedAllocationStore.DeleteCalls())
+func (mock *AllocationStoreMock) DeleteCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *AllocationStoreMock) Get(actor data.Actor, id int32) (*data.Allocation, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			allocationOut *data.Allocation
+			errOut        error
+		)
+		return allocationOut, errOut
+	}
+	return mock.GetFunc(actor, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedAllocationStore.GetCalls())
+func (mock *AllocationStoreMock) GetCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *AllocationStoreMock) GetAll(actor data.Actor, filter data.AllocationFilter) ([]*data.Allocation, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		Filter data.AllocationFilter
+	}{
+		Actor:  actor,
+		Filter: filter,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			allocationsOut []*data.Allocation
+			errOut         error
+		)
+		return allocationsOut, errOut
+	}
+	return mock.GetAllFunc(actor, filter)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedAllocationStore.GetAllCalls())
+func (mock *AllocationStoreMock) GetAllCalls() []struct {
+	Actor  data.Actor
+	Filter data.AllocationFilter
+} {
+	var calls []struct {
+		Actor  data.Actor
+		Filter data.AllocationFilter
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *AllocationStoreMock) Insert(actor data.Actor, a *data.Allocation, available float64) error {
+	callInfo := struct {
+		Actor     data.Actor
+		A         *data.Allocation
+		Available float64
+	}{
+		Actor:     actor,
+		A:         a,
+		Available: available,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(actor, a, available)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedAllocationStore.InsertCalls())
+func (mock *AllocationStoreMock) InsertCalls() []struct {
+	Actor     data.Actor
+	A         *data.Allocation
+	Available float64
+} {
+	var calls []struct {
+		Actor     data.Actor
+		A         *data.Allocation
+		Available float64
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *AllocationStoreMock) Update(a *data.Allocation, available float64) error {
+	callInfo := struct {
+		A         *data.Allocation
+		Available float64
+	}{
+		A:         a,
+		Available: available,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(a, available)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedAllocationStore.UpdateCalls())
+func (mock *AllocationStoreMock) UpdateCalls() []struct {
+	A         *data.Allocation
+	Available float64
+} {
+	var calls []struct {
+		A         *data.Allocation
+		Available float64
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that ApprovalStepStoreMock does implement data.ApprovalStepStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ApprovalStepStore = &ApprovalStepStoreMock{}
+
+// ApprovalStepStoreMock is a mock implementation of data.ApprovalStepStore.
+//
+//	func TestSomethingThatUsesApprovalStepStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ApprovalStepStore
+//		mockedApprovalStepStore := &ApprovalStepStoreMock{
+//			GetChainFunc: func(externalID int32) ([]data.ApprovalStep, error) {
+//				panic("mock out the GetChain method")
+//			},
+//			ReplaceChainFunc: func(projectInternalID *int32, steps []data.ApprovalStep) error {
+//				panic("mock out the ReplaceChain method")
+//			},
+//		}
+//
+//		// use mockedApprovalStepStore in code that requires data.ApprovalStepStore
+//		// and then make assertions.
+//
+//	}
+type ApprovalStepStoreMock struct {
+	// GetChainFunc mocks the GetChain method.
+	GetChainFunc func(externalID int32) ([]data.ApprovalStep, error)
+
+	// ReplaceChainFunc mocks the ReplaceChain method.
+	ReplaceChainFunc func(projectInternalID *int32, steps []data.ApprovalStep) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetChain holds details about calls to the GetChain method.
+		GetChain []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// ReplaceChain holds details about calls to the ReplaceChain method.
+		ReplaceChain []struct {
+			// ProjectInternalID is the projectInternalID argument value.
+			ProjectInternalID *int32
+			// Steps is the steps argument value.
+			Steps []data.ApprovalStep
+		}
+	}
+	lockGetChain     sync.RWMutex
+	lockReplaceChain sync.RWMutex
+}
+
+// GetChain calls GetChainFunc.
+func (mock *ApprovalStepStoreMock) GetChain(externalID int32) ([]data.ApprovalStep, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetChain.Lock()
+	mock.calls.GetChain = append(mock.calls.GetChain, callInfo)
+	mock.lockGetChain.Unlock()
+	if mock.GetChainFunc == nil {
+		var (
+			approvalStepsOut []data.ApprovalStep
+			errOut           error
+		)
+		return approvalStepsOut, errOut
+	}
+	return mock.GetChainFunc(externalID)
+}
+
+// GetChainCalls gets all the calls that were made to GetChain.
+// Check the length with:
+//
+//	len(mockedApprovalStepStore.GetChainCalls())
+func (mock *ApprovalStepStoreMock) GetChainCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetChain.RLock()
+	calls = mock.calls.GetChain
+	mock.lockGetChain.RUnlock()
+	return calls
+}
+
+// ReplaceChain calls ReplaceChainFunc.
+func (mock *ApprovalStepStoreMock) ReplaceChain(projectInternalID *int32, steps []data.ApprovalStep) error {
+	callInfo := struct {
+		ProjectInternalID *int32
+		Steps             []data.ApprovalStep
+	}{
+		ProjectInternalID: projectInternalID,
+		Steps:             steps,
+	}
+	mock.lockReplaceChain.Lock()
+	mock.calls.ReplaceChain = append(mock.calls.ReplaceChain, callInfo)
+	mock.lockReplaceChain.Unlock()
+	if mock.ReplaceChainFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.ReplaceChainFunc(projectInternalID, steps)
+}
+
+// ReplaceChainCalls gets all the calls that were made to ReplaceChain.
+// Check the length with:
+//
+//	len(mockedApprovalStepStore.ReplaceChainCalls())
+func (mock *ApprovalStepStoreMock) ReplaceChainCalls() []struct {
+	ProjectInternalID *int32
+	Steps             []data.ApprovalStep
+} {
+	var calls []struct {
+		ProjectInternalID *int32
+		Steps             []data.ApprovalStep
+	}
+	mock.lockReplaceChain.RLock()
+	calls = mock.calls.ReplaceChain
+	mock.lockReplaceChain.RUnlock()
+	return calls
+}
+
+// Ensure, that AssignmentStoreMock does implement data.AssignmentStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.AssignmentStore = &AssignmentStoreMock{}
+
+// AssignmentStoreMock is a mock implementation of data.AssignmentStore.
+//
+//	func TestSomethingThatUsesAssignmentStore(t *testing.T) {
+//
+//		// make and configure a mocked data.AssignmentStore
+//		mockedAssignmentStore := &AssignmentStoreMock{
+//			DeleteFunc: func(externalID int32, userID int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetAllForProjectFunc: func(externalID int32) ([]*data.Assignment, error) {
+//				panic("mock out the GetAllForProject method")
+//			},
+//			InsertFunc: func(externalID int32, userID int32, manager bool, role *string) (bool, error) {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedAssignmentStore in code that requires data.AssignmentStore
+//		// and then make assertions.
+//
+//	}
+type AssignmentStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(externalID int32, userID int32) error
+
+	// GetAllForProjectFunc mocks the GetAllForProject method.
+	GetAllForProjectFunc func(externalID int32) ([]*data.Assignment, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(externalID int32, userID int32, manager bool, role *string) (bool, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// GetAllForProject holds details about calls to the GetAllForProject method.
+		GetAllForProject []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+			// UserID is the userID argument value.
+			UserID int32
+			// Manager is the manager argument value.
+			Manager bool
+			// Role is the role argument value.
+			Role *string
+		}
+	}
+	lockDelete           sync.RWMutex
+	lockGetAllForProject sync.RWMutex
+	lockInsert           sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *AssignmentStoreMock) Delete(externalID int32, userID int32) error {
+	callInfo := struct {
+		ExternalID int32
+		UserID     int32
+	}{
+		ExternalID: externalID,
+		UserID:     userID,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(externalID, userID)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedAssignmentStore.DeleteCalls())
+func (mock *AssignmentStoreMock) DeleteCalls() []struct {
+	ExternalID int32
+	UserID     int32
+} {
+	var calls []struct {
+		ExternalID int32
+		UserID     int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetAllForProject calls GetAllForProjectFunc.
+func (mock *AssignmentStoreMock) GetAllForProject(externalID int32) ([]*data.Assignment, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetAllForProject.Lock()
+	mock.calls.GetAllForProject = append(mock.calls.GetAllForProject, callInfo)
+	mock.lockGetAllForProject.Unlock()
+	if mock.GetAllForProjectFunc == nil {
+		var (
+			assignmentsOut []*data.Assignment
+			errOut         error
+		)
+		return assignmentsOut, errOut
+	}
+	return mock.GetAllForProjectFunc(externalID)
+}
+
+// GetAllForProjectCalls gets all the calls that were made to GetAllForProject.
+// Check the length with:
+//
+//	len(mockedAssignmentStore.GetAllForProjectCalls())
+func (mock *AssignmentStoreMock) GetAllForProjectCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetAllForProject.RLock()
+	calls = mock.calls.GetAllForProject
+	mock.lockGetAllForProject.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *AssignmentStoreMock) Insert(externalID int32, userID int32, manager bool, role *string) (bool, error) {
+	callInfo := struct {
+		ExternalID int32
+		UserID     int32
+		Manager    bool
+		Role       *string
+	}{
+		ExternalID: externalID,
+		UserID:     userID,
+		Manager:    manager,
+		Role:       role,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.InsertFunc(externalID, userID, manager, role)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedAssignmentStore.InsertCalls())
+func (mock *AssignmentStoreMock) InsertCalls() []struct {
+	ExternalID int32
+	UserID     int32
+	Manager    bool
+	Role       *string
+} {
+	var calls []struct {
+		ExternalID int32
+		UserID     int32
+		Manager    bool
+		Role       *string
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that AuditStoreMock does implement data.AuditStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.AuditStore = &AuditStoreMock{}
+
+// AuditStoreMock is a mock implementation of data.AuditStore.
+//
+//	func TestSomethingThatUsesAuditStore(t *testing.T) {
+//
+//		// make and configure a mocked data.AuditStore
+//		mockedAuditStore := &AuditStoreMock{
+//			InsertFunc: func(entry *data.AuditEntry) error {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedAuditStore in code that requires data.AuditStore
+//		// and then make assertions.
+//
+//	}
+type AuditStoreMock struct {
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(entry *data.AuditEntry) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Entry is the entry argument value.
+			Entry *data.AuditEntry
+		}
+	}
+	lockInsert sync.RWMutex
+}
+
+// Insert calls InsertFunc.
+func (mock *AuditStoreMock) Insert(entry *data.AuditEntry) error {
+	callInfo := struct {
+		Entry *data.AuditEntry
+	}{
+		Entry: entry,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(entry)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedAuditStore.InsertCalls())
+func (mock *AuditStoreMock) InsertCalls() []struct {
+	Entry *data.AuditEntry
+} {
+	var calls []struct {
+		Entry *data.AuditEntry
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that BudgetStoreMock does implement data.BudgetStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.BudgetStore = &BudgetStoreMock{}
+
+// BudgetStoreMock is a mock implementation of data.BudgetStore.
+//
+//	func TestSomethingThatUsesBudgetStore(t *testing.T) {
+//
+//		// make and configure a mocked data.BudgetStore
+//		mockedBudgetStore := &BudgetStoreMock{
+//			DeleteFunc: func(externalID int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(externalID int32) (*data.ProjectBudget, error) {
+//				panic("mock out the Get method")
+//			},
+//			SetFunc: func(budget *data.ProjectBudget) error {
+//				panic("mock out the Set method")
+//			},
+//		}
+//
+//		// use mockedBudgetStore in code that requires data.BudgetStore
+//		// and then make assertions.
+//
+//	}
+type BudgetStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(externalID int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(externalID int32) (*data.ProjectBudget, error)
+
+	// SetFunc mocks the Set method.
+	SetFunc func(budget *data.ProjectBudget) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// Set holds details about calls to the Set method.
+		Set []struct {
+			// Budget is the budget argument value.
+			Budget *data.ProjectBudget
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockSet    sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *BudgetStoreMock) Delete(externalID int32) error {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(externalID)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedBudgetStore.DeleteCalls())
+func (mock *BudgetStoreMock) DeleteCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *BudgetStoreMock) Get(externalID int32) (*data.ProjectBudget, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			projectBudgetOut *data.ProjectBudget
+			errOut           error
+		)
+		return projectBudgetOut, errOut
+	}
+	return mock.GetFunc(externalID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedBudgetStore.GetCalls())
+func (mock *BudgetStoreMock) GetCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// Set calls SetFunc.
+func (mock *BudgetStoreMock) Set(budget *data.ProjectBudget) error {
+	callInfo := struct {
+		Budget *data.ProjectBudget
+	}{
+		Budget: budget,
+	}
+	mock.lockSet.Lock()
+	mock.calls.Set = append(mock.calls.Set, callInfo)
+	mock.lockSet.Unlock()
+	if mock.SetFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetFunc(budget)
+}
+
+// SetCalls gets all the calls that were made to Set.
+// Check the length with:
+//
+//	len(mockedBudgetStore.SetCalls())
+func (mock *BudgetStoreMock) SetCalls() []struct {
+	Budget *data.ProjectBudget
+} {
+	var calls []struct {
+		Budget *data.ProjectBudget
+	}
+	mock.lockSet.RLock()
+	calls = mock.calls.Set
+	mock.lockSet.RUnlock()
+	return calls
+}
+
+// Ensure, that ClientStoreMock does implement data.ClientStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ClientStore = &ClientStoreMock{}
+
+// ClientStoreMock is a mock implementation of data.ClientStore.
+//
+//	func TestSomethingThatUsesClientStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ClientStore
+//		mockedClientStore := &ClientStoreMock{
+//			DeleteFunc: func(internal_id int32, mode string, reassignTo int32) ([]int32, error) {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, internal_id int32) (*data.Client, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, filter data.ClientFilter, filters data.Filters) ([]*data.Client, data.Metadata, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			GetAllByNamesFunc: func(actor data.Actor, names []string) (map[string]*data.Client, error) {
+//				panic("mock out the GetAllByNames method")
+//			},
+//			GetClientByNameFunc: func(actor data.Actor, name string) (*data.Client, error) {
+//				panic("mock out the GetClientByName method")
+//			},
+//			GetSubtreeFunc: func(internal_id int32) ([]*data.Client, error) {
+//				panic("mock out the GetSubtree method")
+//			},
+//			GetSummaryFunc: func(internal_id int32, divisions bool) (*data.ClientSummary, error) {
+//				panic("mock out the GetSummary method")
+//			},
+//			InsertFunc: func(client *data.Client) error {
+//				panic("mock out the Insert method")
+//			},
+//			UpdateFunc: func(c *data.Client) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedClientStore in code that requires data.ClientStore
+//		// and then make assertions.
+//
+//	}
+type ClientStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(internal_id int32, mode string, reassignTo int32) ([]int32, error)
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, internal_id int32) (*data.Client, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, filter data.ClientFilter, filters data.Filters) ([]*data.Client, data.Metadata, error)
+
+	// GetAllByNamesFunc mocks the GetAllByNames method.
+	GetAllByNamesFunc func(actor data.Actor, names []string) (map[string]*data.Client, error)
+
+	// GetClientByNameFunc mocks the GetClientByName method.
+	GetClientByNameFunc func(actor data.Actor, name string) (*data.Client, error)
+
+	// GetSubtreeFunc mocks the GetSubtree method.
+	GetSubtreeFunc func(internal_id int32) ([]*data.Client, error)
+
+	// GetSummaryFunc mocks the GetSummary method.
+	GetSummaryFunc func(internal_id int32, divisions bool) (*data.ClientSummary, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(client *data.Client) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(c *data.Client) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Internal_id is the internal_id argument value.
+			Internal_id int32
+			// Mode is the mode argument value.
+			Mode string
+			// ReassignTo is the reassignTo argument value.
+			ReassignTo int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Internal_id is the internal_id argument value.
+			Internal_id int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Filter is the filter argument value.
+			Filter data.ClientFilter
+			// Filters is the filters argument value.
+			Filters data.Filters
+		}
+		// GetAllByNames holds details about calls to the GetAllByNames method.
+		GetAllByNames []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Names is the names argument value.
+			Names []string
+		}
+		// GetClientByName holds details about calls to the GetClientByName method.
+		GetClientByName []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Name is the name argument value.
+			Name string
+		}
+		// GetSubtree holds details about calls to the GetSubtree method.
+		GetSubtree []struct {
+			// Internal_id is the internal_id argument value.
+			Internal_id int32
+		}
+		// GetSummary holds details about calls to the GetSummary method.
+		GetSummary []struct {
+			// Internal_id is the internal_id argument value.
+			Internal_id int32
+			// Divisions is the divisions argument value.
+			Divisions bool
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Client is the client argument value.
+			Client *data.Client
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// C is the c argument value.
+			C *data.Client
+		}
+	}
+	lockDelete          sync.RWMutex
+	lockGet             sync.RWMutex
+	lockGetAll          sync.RWMutex
+	lockGetAllByNames   sync.RWMutex
+	lockGetClientByName sync.RWMutex
+	lockGetSubtree      sync.RWMutex
+	lockGetSummary      sync.RWMutex
+	lockInsert          sync.RWMutex
+	lockUpdate          sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *ClientStoreMock) Delete(internal_id int32, mode string, reassignTo int32) ([]int32, error) {
+	callInfo := struct {
+		Internal_id int32
+		Mode        string
+		ReassignTo  int32
+	}{
+		Internal_id: internal_id,
+		Mode:        mode,
+		ReassignTo:  reassignTo,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			int32sOut []int32
+			errOut    error
+		)
+		return int32sOut, errOut
+	}
+	return mock.DeleteFunc(internal_id, mode, reassignTo)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedClientStore.DeleteCalls())
+func (mock *ClientStoreMock) DeleteCalls() []struct {
+	Internal_id int32
+	Mode        string
+	ReassignTo  int32
+} {
+	var calls []struct {
+		Internal_id int32
+		Mode        string
+		ReassignTo  int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *ClientStoreMock) Get(actor data.Actor, internal_id int32) (*data.Client, error) {
+	callInfo := struct {
+		Actor       data.Actor
+		Internal_id int32
+	}{
+		Actor:       actor,
+		Internal_id: internal_id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			clientOut *data.Client
+			errOut    error
+		)
+		return clientOut, errOut
+	}
+	return mock.GetFunc(actor, internal_id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedClientStore.GetCalls())
+func (mock *ClientStoreMock) GetCalls() []struct {
+	Actor       data.Actor
+	Internal_id int32
+} {
+	var calls []struct {
+		Actor       data.Actor
+		Internal_id int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *ClientStoreMock) GetAll(actor data.Actor, filter data.ClientFilter, filters data.Filters) ([]*data.Client, data.Metadata, error) {
+	callInfo := struct {
+		Actor   data.Actor
+		Filter  data.ClientFilter
+		Filters data.Filters
+	}{
+		Actor:   actor,
+		Filter:  filter,
+		Filters: filters,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			clientsOut  []*data.Client
+			metadataOut data.Metadata
+			errOut      error
+		)
+		return clientsOut, metadataOut, errOut
+	}
+	return mock.GetAllFunc(actor, filter, filters)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedClientStore.GetAllCalls())
+func (mock *ClientStoreMock) GetAllCalls() []struct {
+	Actor   data.Actor
+	Filter  data.ClientFilter
+	Filters data.Filters
+} {
+	var calls []struct {
+		Actor   data.Actor
+		Filter  data.ClientFilter
+		Filters data.Filters
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetAllByNames calls GetAllByNamesFunc.
+func (mock *ClientStoreMock) GetAllByNames(actor data.Actor, names []string) (map[string]*data.Client, error) {
+	callInfo := struct {
+		Actor data.Actor
+		Names []string
+	}{
+		Actor: actor,
+		Names: names,
+	}
+	mock.lockGetAllByNames.Lock()
+	mock.calls.GetAllByNames = append(mock.calls.GetAllByNames, callInfo)
+	mock.lockGetAllByNames.Unlock()
+	if mock.GetAllByNamesFunc == nil {
+		var (
+			stringToClientOut map[string]*data.Client
+			errOut            error
+		)
+		return stringToClientOut, errOut
+	}
+	return mock.GetAllByNamesFunc(actor, names)
+}
+
+// GetAllByNamesCalls gets all the calls that were made to GetAllByNames.
+// Check the length with:
+//
+//	len(mockedClientStore.GetAllByNamesCalls())
+func (mock *ClientStoreMock) GetAllByNamesCalls() []struct {
+	Actor data.Actor
+	Names []string
+} {
+	var calls []struct {
+		Actor data.Actor
+		Names []string
+	}
+	mock.lockGetAllByNames.RLock()
+	calls = mock.calls.GetAllByNames
+	mock.lockGetAllByNames.RUnlock()
+	return calls
+}
+
+// GetClientByName calls GetClientByNameFunc.
+func (mock *ClientStoreMock) GetClientByName(actor data.Actor, name string) (*data.Client, error) {
+	callInfo := struct {
+		Actor data.Actor
+		Name  string
+	}{
+		Actor: actor,
+		Name:  name,
+	}
+	mock.lockGetClientByName.Lock()
+	mock.calls.GetClientByName = append(mock.calls.GetClientByName, callInfo)
+	mock.lockGetClientByName.Unlock()
+	if mock.GetClientByNameFunc == nil {
+		var (
+			clientOut *data.Client
+			errOut    error
+		)
+		return clientOut, errOut
+	}
+	return mock.GetClientByNameFunc(actor, name)
+}
+
+// GetClientByNameCalls gets all the calls that were made to GetClientByName.
+// Check the length with:
+//
+//	len(mockedClientStore.GetClientByNameCalls())
+func (mock *ClientStoreMock) GetClientByNameCalls() []struct {
+	Actor data.Actor
+	Name  string
+} {
+	var calls []struct {
+		Actor data.Actor
+		Name  string
+	}
+	mock.lockGetClientByName.RLock()
+	calls = mock.calls.GetClientByName
+	mock.lockGetClientByName.RUnlock()
+	return calls
+}
+
+// GetSubtree calls GetSubtreeFunc.
+func (mock *ClientStoreMock) GetSubtree(internal_id int32) ([]*data.Client, error) {
+	callInfo := struct {
+		Internal_id int32
+	}{
+		Internal_id: internal_id,
+	}
+	mock.lockGetSubtree.Lock()
+	mock.calls.GetSubtree = append(mock.calls.GetSubtree, callInfo)
+	mock.lockGetSubtree.Unlock()
+	if mock.GetSubtreeFunc == nil {
+		var (
+			clientsOut []*data.Client
+			errOut     error
+		)
+		return clientsOut, errOut
+	}
+	return mock.GetSubtreeFunc(internal_id)
+}
+
+// GetSubtreeCalls gets all the calls that were made to GetSubtree.
+// Check the length with:
+//
+//	len(mockedClientStore.GetSubtreeCalls())
+func (mock *ClientStoreMock) GetSubtreeCalls() []struct {
+	Internal_id int32
+} {
+	var calls []struct {
+		Internal_id int32
+	}
+	mock.lockGetSubtree.RLock()
+	calls = mock.calls.GetSubtree
+	mock.lockGetSubtree.RUnlock()
+	return calls
+}
+
+// GetSummary calls GetSummaryFunc.
+func (mock *ClientStoreMock) GetSummary(internal_id int32, divisions bool) (*data.ClientSummary, error) {
+	callInfo := struct {
+		Internal_id int32
+		Divisions   bool
+	}{
+		Internal_id: internal_id,
+		Divisions:   divisions,
+	}
+	mock.lockGetSummary.Lock()
+	mock.calls.GetSummary = append(mock.calls.GetSummary, callInfo)
+	mock.lockGetSummary.Unlock()
+	if mock.GetSummaryFunc == nil {
+		var (
+			clientSummaryOut *data.ClientSummary
+			errOut           error
+		)
+		return clientSummaryOut, errOut
+	}
+	return mock.GetSummaryFunc(internal_id, divisions)
+}
+
+// GetSummaryCalls gets all the calls that were made to GetSummary.
+// Check the length with:
+//
+//	len(mockedClientStore.GetSummaryCalls())
+func (mock *ClientStoreMock) GetSummaryCalls() []struct {
+	Internal_id int32
+	Divisions   bool
+} {
+	var calls []struct {
+		Internal_id int32
+		Divisions   bool
+	}
+	mock.lockGetSummary.RLock()
+	calls = mock.calls.GetSummary
+	mock.lockGetSummary.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *ClientStoreMock) Insert(client *data.Client) error {
+	callInfo := struct {
+		Client *data.Client
+	}{
+		Client: client,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(client)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedClientStore.InsertCalls())
+func (mock *ClientStoreMock) InsertCalls() []struct {
+	Client *data.Client
+} {
+	var calls []struct {
+		Client *data.Client
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *ClientStoreMock) Update(c *data.Client) error {
+	callInfo := struct {
+		C *data.Client
+	}{
+		C: c,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(c)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedClientStore.UpdateCalls())
+func (mock *ClientStoreMock) UpdateCalls() []struct {
+	C *data.Client
+} {
+	var calls []struct {
+		C *data.Client
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that CodePolicyStoreMock does implement data.CodePolicyStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.CodePolicyStore = &CodePolicyStoreMock{}
+
+// CodePolicyStoreMock is a mock implementation of data.CodePolicyStore.
+//
+//	func TestSomethingThatUsesCodePolicyStore(t *testing.T) {
+//
+//		// make and configure a mocked data.CodePolicyStore
+//		mockedCodePolicyStore := &CodePolicyStoreMock{
+//			GetFunc: func(orgID int32) (*data.CodePolicy, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetProjectCodesFunc: func() ([]string, error) {
+//				panic("mock out the GetProjectCodes method")
+//			},
+//			GetProposalCodesFunc: func() ([]string, error) {
+//				panic("mock out the GetProposalCodes method")
+//			},
+//			UpdateFunc: func(p *data.CodePolicy) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedCodePolicyStore in code that requires data.CodePolicyStore
+//		// and then make assertions.
+//
+//	}
+type CodePolicyStoreMock struct {
+	// GetFunc mocks the Get method.
+	GetFunc func(orgID int32) (*data.CodePolicy, error)
+
+	// GetProjectCodesFunc mocks the GetProjectCodes method.
+	GetProjectCodesFunc func() ([]string, error)
+
+	// GetProposalCodesFunc mocks the GetProposalCodes method.
+	GetProposalCodesFunc func() ([]string, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(p *data.CodePolicy) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+		// GetProjectCodes holds details about calls to the GetProjectCodes method.
+		GetProjectCodes []struct {
+		}
+		// GetProposalCodes holds details about calls to the GetProposalCodes method.
+		GetProposalCodes []struct {
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// P is the p argument value.
+			P *data.CodePolicy
+		}
+	}
+	lockGet              sync.RWMutex
+	lockGetProjectCodes  sync.RWMutex
+	lockGetProposalCodes sync.RWMutex
+	lockUpdate           sync.RWMutex
+}
+
+// Get calls GetFunc.
+func (mock *CodePolicyStoreMock) Get(orgID int32) (*data.CodePolicy, error) {
+	callInfo := struct {
+		OrgID int32
+	}{
+		OrgID: orgID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			codePolicyOut *data.CodePolicy
+			errOut        error
+		)
+		return codePolicyOut, errOut
+	}
+	return mock.GetFunc(orgID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedCodePolicyStore.GetCalls())
+func (mock *CodePolicyStoreMock) GetCalls() []struct {
+	OrgID int32
+} {
+	var calls []struct {
+		OrgID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetProjectCodes calls GetProjectCodesFunc.
+func (mock *CodePolicyStoreMock) GetProjectCodes() ([]string, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetProjectCodes.Lock()
+	mock.calls.GetProjectCodes = append(mock.calls.GetProjectCodes, callInfo)
+	mock.lockGetProjectCodes.Unlock()
+	if mock.GetProjectCodesFunc == nil {
+		var (
+			stringsOut []string
+			errOut     error
+		)
+		return stringsOut, errOut
+	}
+	return mock.GetProjectCodesFunc()
+}
+
+// GetProjectCodesCalls gets all the calls that were made to GetProjectCodes.
+// Check the length with:
+//
+//	len(mockedCodePolicyStore.GetProjectCodesCalls())
+func (mock *CodePolicyStoreMock) GetProjectCodesCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetProjectCodes.RLock()
+	calls = mock.calls.GetProjectCodes
+	mock.lockGetProjectCodes.RUnlock()
+	return calls
+}
+
+// GetProposalCodes calls GetProposalCodesFunc.
+func (mock *CodePolicyStoreMock) GetProposalCodes() ([]string, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetProposalCodes.Lock()
+	mock.calls.GetProposalCodes = append(mock.calls.GetProposalCodes, callInfo)
+	mock.lockGetProposalCodes.Unlock()
+	if mock.GetProposalCodesFunc == nil {
+		var (
+			stringsOut []string
+			errOut     error
+		)
+		return stringsOut, errOut
+	}
+	return mock.GetProposalCodesFunc()
+}
+
+// GetProposalCodesCalls gets all the calls that were made to GetProposalCodes.
+// Check the length with:
+//
+//	len(mockedCodePolicyStore.GetProposalCodesCalls())
+func (mock *CodePolicyStoreMock) GetProposalCodesCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetProposalCodes.RLock()
+	calls = mock.calls.GetProposalCodes
+	mock.lockGetProposalCodes.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *CodePolicyStoreMock) Update(p *data.CodePolicy) error {
+	callInfo := struct {
+		P *data.CodePolicy
+	}{
+		P: p,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(p)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedCodePolicyStore.UpdateCalls())
+func (mock *CodePolicyStoreMock) UpdateCalls() []struct {
+	P *data.CodePolicy
+} {
+	var calls []struct {
+		P *data.CodePolicy
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that CustomFieldStoreMock does implement data.CustomFieldStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.CustomFieldStore = &CustomFieldStoreMock{}
+
+// CustomFieldStoreMock is a mock implementation of data.CustomFieldStore.
+//
+//	func TestSomethingThatUsesCustomFieldStore(t *testing.T) {
+//
+//		// make and configure a mocked data.CustomFieldStore
+//		mockedCustomFieldStore := &CustomFieldStoreMock{
+//			DeleteFunc: func(field *data.CustomField) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, id int32) (*data.CustomField, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, entity string) ([]*data.CustomField, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(field *data.CustomField) error {
+//				panic("mock out the Insert method")
+//			},
+//			UpdateFunc: func(field *data.CustomField, oldName string) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedCustomFieldStore in code that requires data.CustomFieldStore
+//		// and then make assertions.
+//
+//	}
+type CustomFieldStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(field *data.CustomField) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, id int32) (*data.CustomField, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, entity string) ([]*data.CustomField, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(field *data.CustomField) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(field *data.CustomField, oldName string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Field is the field argument value.
+			Field *data.CustomField
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Entity is the entity argument value.
+			Entity string
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Field is the field argument value.
+			Field *data.CustomField
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Field is the field argument value.
+			Field *data.CustomField
+			// OldName is the oldName argument value.
+			OldName string
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockGetAll sync.RWMutex
+	lockInsert sync.RWMutex
+	lockUpdate sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *CustomFieldStoreMock) Delete(field *data.CustomField) error {
+	callInfo := struct {
+		Field *data.CustomField
+	}{
+		Field: field,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(field)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedCustomFieldStore.DeleteCalls())
+func (mock *CustomFieldStoreMock) DeleteCalls() []struct {
+	Field *data.CustomField
+} {
+	var calls []struct {
+		Field *data.CustomField
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *CustomFieldStoreMock) Get(actor data.Actor, id int32) (*data.CustomField, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			customFieldOut *data.CustomField
+			errOut         error
+		)
+		return customFieldOut, errOut
+	}
+	return mock.GetFunc(actor, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedCustomFieldStore.GetCalls())
+func (mock *CustomFieldStoreMock) GetCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *CustomFieldStoreMock) GetAll(actor data.Actor, entity string) ([]*data.CustomField, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		Entity string
+	}{
+		Actor:  actor,
+		Entity: entity,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			customFieldsOut []*data.CustomField
+			errOut          error
+		)
+		return customFieldsOut, errOut
+	}
+	return mock.GetAllFunc(actor, entity)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedCustomFieldStore.GetAllCalls())
+func (mock *CustomFieldStoreMock) GetAllCalls() []struct {
+	Actor  data.Actor
+	Entity string
+} {
+	var calls []struct {
+		Actor  data.Actor
+		Entity string
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *CustomFieldStoreMock) Insert(field *data.CustomField) error {
+	callInfo := struct {
+		Field *data.CustomField
+	}{
+		Field: field,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(field)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedCustomFieldStore.InsertCalls())
+func (mock *CustomFieldStoreMock) InsertCalls() []struct {
+	Field *data.CustomField
+} {
+	var calls []struct {
+		Field *data.CustomField
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *CustomFieldStoreMock) Update(field *data.CustomField, oldName string) error {
+	callInfo := struct {
+		Field   *data.CustomField
+		OldName string
+	}{
+		Field:   field,
+		OldName: oldName,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(field, oldName)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedCustomFieldStore.UpdateCalls())
+func (mock *CustomFieldStoreMock) UpdateCalls() []struct {
+	Field   *data.CustomField
+	OldName string
+} {
+	var calls []struct {
+		Field   *data.CustomField
+		OldName string
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that DelegationStoreMock does implement data.DelegationStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.DelegationStore = &DelegationStoreMock{}
+
+// DelegationStoreMock is a mock implementation of data.DelegationStore.
+//
+//	func TestSomethingThatUsesDelegationStore(t *testing.T) {
+//
+//		// make and configure a mocked data.DelegationStore
+//		mockedDelegationStore := &DelegationStoreMock{
+//			CanActForFunc: func(userID int32, approverID int32, day time.Time) (bool, error) {
+//				panic("mock out the CanActFor method")
+//			},
+//			DeleteFunc: func(id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, id int32) (*data.Delegation, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetActiveDelegatesFunc: func(delegatorID int32, day time.Time) ([]int32, error) {
+//				panic("mock out the GetActiveDelegates method")
+//			},
+//			GetAllFunc: func(actor data.Actor, userID int32) ([]*data.Delegation, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(actor data.Actor, d *data.Delegation) error {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedDelegationStore in code that requires data.DelegationStore
+//		// and then make assertions.
+//
+//	}
+type DelegationStoreMock struct {
+	// CanActForFunc mocks the CanActFor method.
+	CanActForFunc func(userID int32, approverID int32, day time.Time) (bool, error)
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(id int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, id int32) (*data.Delegation, error)
+
+	// GetActiveDelegatesFunc mocks the GetActiveDelegates method.
+	GetActiveDelegatesFunc func(delegatorID int32, day time.Time) ([]int32, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, userID int32) ([]*data.Delegation, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(actor data.Actor, d *data.Delegation) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CanActFor holds details about calls to the CanActFor method.
+		CanActFor []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// ApproverID is the approverID argument value.
+			ApproverID int32
+			// Day is the day argument value.
+			Day time.Time
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ID is the id argument value.
+			ID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetActiveDelegates holds details about calls to the GetActiveDelegates method.
+		GetActiveDelegates []struct {
+			// DelegatorID is the delegatorID argument value.
+			DelegatorID int32
+			// Day is the day argument value.
+			Day time.Time
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// D is the d argument value.
+			D *data.Delegation
+		}
+	}
+	lockCanActFor          sync.RWMutex
+	lockDelete             sync.RWMutex
+	lockGet                sync.RWMutex
+	lockGetActiveDelegates sync.RWMutex
+	lockGetAll             sync.RWMutex
+	lockInsert             sync.RWMutex
+}
+
+// CanActFor calls CanActForFunc.
+func (mock *DelegationStoreMock) CanActFor(userID int32, approverID int32, day time.Time) (bool, error) {
+	callInfo := struct {
+		UserID     int32
+		ApproverID int32
+		Day        time.Time
+	}{
+		UserID:     userID,
+		ApproverID: approverID,
+		Day:        day,
+	}
+	mock.lockCanActFor.Lock()
+	mock.calls.CanActFor = append(mock.calls.CanActFor, callInfo)
+	mock.lockCanActFor.Unlock()
+	if mock.CanActForFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.CanActForFunc(userID, approverID, day)
+}
+
+// CanActForCalls gets all the calls that were made to CanActFor.
+// Check the length with:
+//
+//	len(mockedDelegationStore.CanActForCalls())
+func (mock *DelegationStoreMock) CanActForCalls() []struct {
+	UserID     int32
+	ApproverID int32
+	Day        time.Time
+} {
+	var calls []struct {
+		UserID     int32
+		ApproverID int32
+		Day        time.Time
+	}
+	mock.lockCanActFor.RLock()
+	calls = mock.calls.CanActFor
+	mock.lockCanActFor.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *DelegationStoreMock) Delete(id int32) error {
+	callInfo := struct {
+		ID int32
+	}{
+		ID: id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedDelegationStore.DeleteCalls())
+func (mock *DelegationStoreMock) DeleteCalls() []struct {
+	ID int32
+} {
+	var calls []struct {
+		ID int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *DelegationStoreMock) Get(actor data.Actor, id int32) (*data.Delegation, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			delegationOut *data.Delegation
+			errOut        error
+		)
+		return delegationOut, errOut
+	}
+	return mock.GetFunc(actor, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedDelegationStore.GetCalls())
+func (mock *DelegationStoreMock) GetCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetActiveDelegates calls GetActiveDelegatesFunc.
+func (mock *DelegationStoreMock) GetActiveDelegates(delegatorID int32, day time.Time) ([]int32, error) {
+	callInfo := struct {
+		DelegatorID int32
+		Day         time.Time
+	}{
+		DelegatorID: delegatorID,
+		Day:         day,
+	}
+	mock.lockGetActiveDelegates.Lock()
+	mock.calls.GetActiveDelegates = append(mock.calls.GetActiveDelegates, callInfo)
+	mock.lockGetActiveDelegates.Unlock()
+	if mock.GetActiveDelegatesFunc == nil {
+		var (
+			int32sOut []int32
+			errOut    error
+		)
+		return int32sOut, errOut
+	}
+	return mock.GetActiveDelegatesFunc(delegatorID, day)
+}
+
+// GetActiveDelegatesCalls gets all the calls that were made to GetActiveDelegates.
+// Check the length with:
+//
+//	len(mockedDelegationStore.GetActiveDelegatesCalls())
+func (mock *DelegationStoreMock) GetActiveDelegatesCalls() []struct {
+	DelegatorID int32
+	Day         time.Time
+} {
+	var calls []struct {
+		DelegatorID int32
+		Day         time.Time
+	}
+	mock.lockGetActiveDelegates.RLock()
+	calls = mock.calls.GetActiveDelegates
+	mock.lockGetActiveDelegates.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *DelegationStoreMock) GetAll(actor data.Actor, userID int32) ([]*data.Delegation, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		UserID int32
+	}{
+		Actor:  actor,
+		UserID: userID,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			delegationsOut []*data.Delegation
+			errOut         error
+		)
+		return delegationsOut, errOut
+	}
+	return mock.GetAllFunc(actor, userID)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedDelegationStore.GetAllCalls())
+func (mock *DelegationStoreMock) GetAllCalls() []struct {
+	Actor  data.Actor
+	UserID int32
+} {
+	var calls []struct {
+		Actor  data.Actor
+		UserID int32
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *DelegationStoreMock) Insert(actor data.Actor, d *data.Delegation) error {
+	callInfo := struct {
+		Actor data.Actor
+		D     *data.Delegation
+	}{
+		Actor: actor,
+		D:     d,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(actor, d)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedDelegationStore.InsertCalls())
+func (mock *DelegationStoreMock) InsertCalls() []struct {
+	Actor data.Actor
+	D     *data.Delegation
+} {
+	var calls []struct {
+		Actor data.Actor
+		D     *data.Delegation
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that DigestStoreMock does implement data.DigestStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.DigestStore = &DigestStoreMock{}
+
+// DigestStoreMock is a mock implementation of data.DigestStore.
+//
+//	func TestSomethingThatUsesDigestStore(t *testing.T) {
+//
+//		// make and configure a mocked data.DigestStore
+//		mockedDigestStore := &DigestStoreMock{
+//			BuildFunc: func(userID int32, since time.Time, warnRatio float64) (*data.Digest, error) {
+//				panic("mock out the Build method")
+//			},
+//			GetDueFunc: func(now time.Time) ([]*data.DigestRecipient, error) {
+//				panic("mock out the GetDue method")
+//			},
+//			GetPreferenceFunc: func(userID int32) (*data.DigestPreference, error) {
+//				panic("mock out the GetPreference method")
+//			},
+//			MarkSentFunc: func(userID int32, at time.Time) error {
+//				panic("mock out the MarkSent method")
+//			},
+//			SetPreferenceFunc: func(userID int32, p *data.DigestPreference) error {
+//				panic("mock out the SetPreference method")
+//			},
+//		}
+//
+//		// use mockedDigestStore in code that requires data.DigestStore
+//		// and then make assertions.
+//
+//	}
+type DigestStoreMock struct {
+	// BuildFunc mocks the Build method.
+	BuildFunc func(userID int32, since time.Time, warnRatio float64) (*data.Digest, error)
+
+	// GetDueFunc mocks the GetDue method.
+	GetDueFunc func(now time.Time) ([]*data.DigestRecipient, error)
+
+	// GetPreferenceFunc mocks the GetPreference method.
+	GetPreferenceFunc func(userID int32) (*data.DigestPreference, error)
+
+	// MarkSentFunc mocks the MarkSent method.
+	MarkSentFunc func(userID int32, at time.Time) error
+
+	// SetPreferenceFunc mocks the SetPreference method.
+	SetPreferenceFunc func(userID int32, p *data.DigestPreference) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Build holds details about calls to the Build method.
+		Build []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// Since is the since argument value.
+			Since time.Time
+			// WarnRatio is the warnRatio argument value.
+			WarnRatio float64
+		}
+		// GetDue holds details about calls to the GetDue method.
+		GetDue []struct {
+			// Now is the now argument value.
+			Now time.Time
+		}
+		// GetPreference holds details about calls to the GetPreference method.
+		GetPreference []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// MarkSent holds details about calls to the MarkSent method.
+		MarkSent []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// At is the at argument value.
+			At time.Time
+		}
+		// SetPreference holds details about calls to the SetPreference method.
+		SetPreference []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// P is the p argument value.
+			P *data.DigestPreference
+		}
+	}
+	lockBuild         sync.RWMutex
+	lockGetDue        sync.RWMutex
+	lockGetPreference sync.RWMutex
+	lockMarkSent      sync.RWMutex
+	lockSetPreference sync.RWMutex
+}
+
+// Build calls BuildFunc.
+func (mock *DigestStoreMock) Build(userID int32, since time.Time, warnRatio float64) (*data.Digest, error) {
+	callInfo := struct {
+		UserID    int32
+		Since     time.Time
+		WarnRatio float64
+	}{
+		UserID:    userID,
+		Since:     since,
+		WarnRatio: warnRatio,
+	}
+	mock.lockBuild.Lock()
+	mock.calls.Build = append(mock.calls.Build, callInfo)
+	mock.lockBuild.Unlock()
+	if mock.BuildFunc == nil {
+		var (
+			digestOut *data.Digest
+			errOut    error
+		)
+		return digestOut, errOut
+	}
+	return mock.BuildFunc(userID, since, warnRatio)
+}
+
+// BuildCalls gets all the calls that were made to Build.
+// Check the length with:
+//
+//	len(mockedDigestStore.BuildCalls())
+func (mock *DigestStoreMock) BuildCalls() []struct {
+	UserID    int32
+	Since     time.Time
+	WarnRatio float64
+} {
+	var calls []struct {
+		UserID    int32
+		Since     time.Time
+		WarnRatio float64
+	}
+	mock.lockBuild.RLock()
+	calls = mock.calls.Build
+	mock.lockBuild.RUnlock()
+	return calls
+}
+
+// GetDue calls GetDueFunc.
+func (mock *DigestStoreMock) GetDue(now time.Time) ([]*data.DigestRecipient, error) {
+	callInfo := struct {
+		Now time.Time
+	}{
+		Now: now,
+	}
+	mock.lockGetDue.Lock()
+	mock.calls.GetDue = append(mock.calls.GetDue, callInfo)
+	mock.lockGetDue.Unlock()
+	if mock.GetDueFunc == nil {
+		var (
+			digestRecipientsOut []*data.DigestRecipient
+			errOut              error
+		)
+		return digestRecipientsOut, errOut
+	}
+	return mock.GetDueFunc(now)
+}
+
+// GetDueCalls gets all the calls that were made to GetDue.
+// Check the length with:
+//
+//	len(mockedDigestStore.GetDueCalls())
+func (mock *DigestStoreMock) GetDueCalls() []struct {
+	Now time.Time
+} {
+	var calls []struct {
+		Now time.Time
+	}
+	mock.lockGetDue.RLock()
+	calls = mock.calls.GetDue
+	mock.lockGetDue.RUnlock()
+	return calls
+}
+
+// GetPreference calls GetPreferenceFunc.
+func (mock *DigestStoreMock) GetPreference(userID int32) (*data.DigestPreference, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetPreference.Lock()
+	mock.calls.GetPreference = append(mock.calls.GetPreference, callInfo)
+	mock.lockGetPreference.Unlock()
+	if mock.GetPreferenceFunc == nil {
+		var (
+			digestPreferenceOut *data.DigestPreference
+			errOut              error
+		)
+		return digestPreferenceOut, errOut
+	}
+	return mock.GetPreferenceFunc(userID)
+}
+
+// GetPreferenceCalls gets all the calls that were made to GetPreference.
+// Check the length with:
+//
+//	len(mockedDigestStore.GetPreferenceCalls())
+func (mock *DigestStoreMock) GetPreferenceCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetPreference.RLock()
+	calls = mock.calls.GetPreference
+	mock.lockGetPreference.RUnlock()
+	return calls
+}
+
+// MarkSent calls MarkSentFunc.
+func (mock *DigestStoreMock) MarkSent(userID int32, at time.Time) error {
+	callInfo := struct {
+		UserID int32
+		At     time.Time
+	}{
+		UserID: userID,
+		At:     at,
+	}
+	mock.lockMarkSent.Lock()
+	mock.calls.MarkSent = append(mock.calls.MarkSent, callInfo)
+	mock.lockMarkSent.Unlock()
+	if mock.MarkSentFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.MarkSentFunc(userID, at)
+}
+
+// MarkSentCalls gets all the calls that were made to MarkSent.
+// Check the length with:
+//
+//	len(mockedDigestStore.MarkSentCalls())
+func (mock *DigestStoreMock) MarkSentCalls() []struct {
+	UserID int32
+	At     time.Time
+} {
+	var calls []struct {
+		UserID int32
+		At     time.Time
+	}
+	mock.lockMarkSent.RLock()
+	calls = mock.calls.MarkSent
+	mock.lockMarkSent.RUnlock()
+	return calls
+}
+
+// SetPreference calls SetPreferenceFunc.
+func (mock *DigestStoreMock) SetPreference(userID int32, p *data.DigestPreference) error {
+	callInfo := struct {
+		UserID int32
+		P      *data.DigestPreference
+	}{
+		UserID: userID,
+		P:      p,
+	}
+	mock.lockSetPreference.Lock()
+	mock.calls.SetPreference = append(mock.calls.SetPreference, callInfo)
+	mock.lockSetPreference.Unlock()
+	if mock.SetPreferenceFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetPreferenceFunc(userID, p)
+}
+
+// SetPreferenceCalls gets all the calls that were made to SetPreference.
+// Check the length with:
+//
+//	len(mockedDigestStore.SetPreferenceCalls())
+func (mock *DigestStoreMock) SetPreferenceCalls() []struct {
+	UserID int32
+	P      *data.DigestPreference
+} {
+	var calls []struct {
+		UserID int32
+		P      *data.DigestPreference
+	}
+	mock.lockSetPreference.RLock()
+	calls = mock.calls.SetPreference
+	mock.lockSetPreference.RUnlock()
+	return calls
+}
+
+// Ensure, that ExchangeRateStoreMock does implement data.ExchangeRateStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ExchangeRateStore = &ExchangeRateStoreMock{}
+
+// ExchangeRateStoreMock is a mock implementation of data.ExchangeRateStore.
+//
+//	func TestSomethingThatUsesExchangeRateStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ExchangeRateStore
+//		mockedExchangeRateStore := &ExchangeRateStoreMock{
+//			GetFunc: func(from string, to string, date time.Time) (*data.ExchangeRate, error) {
+//				panic("mock out the Get method")
+//			},
+//			InsertFunc: func(base string, date time.Time, rates map[string]float64) error {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedExchangeRateStore in code that requires data.ExchangeRateStore
+//		// and then make assertions.
+//
+//	}
+type ExchangeRateStoreMock struct {
+	// GetFunc mocks the Get method.
+	GetFunc func(from string, to string, date time.Time) (*data.ExchangeRate, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(base string, date time.Time, rates map[string]float64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// From is the from argument value.
+			From string
+			// To is the to argument value.
+			To string
+			// Date is the date argument value.
+			Date time.Time
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Base is the base argument value.
+			Base string
+			// Date is the date argument value.
+			Date time.Time
+			// Rates is the rates argument value.
+			Rates map[string]float64
+		}
+	}
+	lockGet    sync.RWMutex
+	lockInsert sync.RWMutex
+}
+
+// Get calls GetFunc.
+func (mock *ExchangeRateStoreMock) Get(from string, to string, date time.Time) (*data.ExchangeRate, error) {
+	callInfo := struct {
+		From string
+		To   string
+		Date time.Time
+	}{
+		From: from,
+		To:   to,
+		Date: date,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			exchangeRateOut *data.ExchangeRate
+			errOut          error
+		)
+		return exchangeRateOut, errOut
+	}
+	return mock.GetFunc(from, to, date)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedExchangeRateStore.GetCalls())
+func (mock *ExchangeRateStoreMock) GetCalls() []struct {
+	From string
+	To   string
+	Date time.Time
+} {
+	var calls []struct {
+		From string
+		To   string
+		Date time.Time
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *ExchangeRateStoreMock) Insert(base string, date time.Time, rates map[string]float64) error {
+	callInfo := struct {
+		Base  string
+		Date  time.Time
+		Rates map[string]float64
+	}{
+		Base:  base,
+		Date:  date,
+		Rates: rates,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(base, date, rates)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedExchangeRateStore.InsertCalls())
+func (mock *ExchangeRateStoreMock) InsertCalls() []struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]float64
+} {
+	var calls []struct {
+		Base  string
+		Date  time.Time
+		Rates map[string]float64
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that ExportStoreMock does implement data.ExportStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ExportStore = &ExportStoreMock{}
+
+// ExportStoreMock is a mock implementation of data.ExportStore.
+//
+//	func TestSomethingThatUsesExportStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ExportStore
+//		mockedExportStore := &ExportStoreMock{
+//			WriteCSVFunc: func(w io.Writer, query string, orgID int32) error {
+//				panic("mock out the WriteCSV method")
+//			},
+//			WriteJSONFunc: func(w io.Writer, query string, orgID int32) error {
+//				panic("mock out the WriteJSON method")
+//			},
+//		}
+//
+//		// use mockedExportStore in code that requires data.ExportStore
+//		// and then make assertions.
+//
+//	}
+type ExportStoreMock struct {
+	// WriteCSVFunc mocks the WriteCSV method.
+	WriteCSVFunc func(w io.Writer, query string, orgID int32) error
+
+	// WriteJSONFunc mocks the WriteJSON method.
+	WriteJSONFunc func(w io.Writer, query string, orgID int32) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// WriteCSV holds details about calls to the WriteCSV method.
+		WriteCSV []struct {
+			// W is the w argument value.
+			W io.Writer
+			// Query is the query argument value.
+			Query string
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+		// WriteJSON holds details about calls to the WriteJSON method.
+		WriteJSON []struct {
+			// W is the w argument value.
+			W io.Writer
+			// Query is the query argument value.
+			Query string
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+	}
+	lockWriteCSV  sync.RWMutex
+	lockWriteJSON sync.RWMutex
+}
+
+// WriteCSV calls WriteCSVFunc.
+func (mock *ExportStoreMock) WriteCSV(w io.Writer, query string, orgID int32) error {
+	callInfo := struct {
+		W     io.Writer
+		Query string
+		OrgID int32
+	}{
+		W:     w,
+		Query: query,
+		OrgID: orgID,
+	}
+	mock.lockWriteCSV.Lock()
+	mock.calls.WriteCSV = append(mock.calls.WriteCSV, callInfo)
+	mock.lockWriteCSV.Unlock()
+	if mock.WriteCSVFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.WriteCSVFunc(w, query, orgID)
+}
+
+// WriteCSVCalls gets all the calls that were made to WriteCSV.
+// Check the length with:
+//
+//	len(mockedExportStore.WriteCSVCalls())
+func (mock *ExportStoreMock) WriteCSVCalls() []struct {
+	W     io.Writer
+	Query string
+	OrgID int32
+} {
+	var calls []struct {
+		W     io.Writer
+		Query string
+		OrgID int32
+	}
+	mock.lockWriteCSV.RLock()
+	calls = mock.calls.WriteCSV
+	mock.lockWriteCSV.RUnlock()
+	return calls
+}
+
+// WriteJSON calls WriteJSONFunc.
+func (mock *ExportStoreMock) WriteJSON(w io.Writer, query string, orgID int32) error {
+	callInfo := struct {
+		W     io.Writer
+		Query string
+		OrgID int32
+	}{
+		W:     w,
+		Query: query,
+		OrgID: orgID,
+	}
+	mock.lockWriteJSON.Lock()
+	mock.calls.WriteJSON = append(mock.calls.WriteJSON, callInfo)
+	mock.lockWriteJSON.Unlock()
+	if mock.WriteJSONFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.WriteJSONFunc(w, query, orgID)
+}
+
+// WriteJSONCalls gets all the calls that were made to WriteJSON.
+// Check the length with:
+//
+//	len(mockedExportStore.WriteJSONCalls())
+func (mock *ExportStoreMock) WriteJSONCalls() []struct {
+	W     io.Writer
+	Query string
+	OrgID int32
+} {
+	var calls []struct {
+		W     io.Writer
+		Query string
+		OrgID int32
+	}
+	mock.lockWriteJSON.RLock()
+	calls = mock.calls.WriteJSON
+	mock.lockWriteJSON.RUnlock()
+	return calls
+}
+
+// Ensure, that FileStoreMock does implement data.FileStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.FileStore = &FileStoreMock{}
+
+// FileStoreMock is a mock implementation of data.FileStore.
+//
+//	func TestSomethingThatUsesFileStore(t *testing.T) {
+//
+//		// make and configure a mocked data.FileStore
+//		mockedFileStore := &FileStoreMock{
+//			DeleteByKeysFunc: func(keys []string) error {
+//				panic("mock out the DeleteByKeys method")
+//			},
+//			GetFunc: func(id int64) (*data.File, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllForProjectFunc: func(externalID int32) ([]*data.File, error) {
+//				panic("mock out the GetAllForProject method")
+//			},
+//			GetLatestDocumentsFunc: func(externalID int32, category string) ([]*data.File, error) {
+//				panic("mock out the GetLatestDocuments method")
+//			},
+//			GetVersionsFunc: func(id int64) ([]*data.File, error) {
+//				panic("mock out the GetVersions method")
+//			},
+//			ReconcileFunc: func(externalID int32, objects []*data.File) error {
+//				panic("mock out the Reconcile method")
+//			},
+//			UpsertFunc: func(file *data.File) error {
+//				panic("mock out the Upsert method")
+//			},
+//		}
+//
+//		// use mockedFileStore in code that requires data.FileStore
+//		// and then make assertions.
+//
+//	}
+type FileStoreMock struct {
+	// DeleteByKeysFunc mocks the DeleteByKeys method.
+	DeleteByKeysFunc func(keys []string) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(id int64) (*data.File, error)
+
+	// GetAllForProjectFunc mocks the GetAllForProject method.
+	GetAllForProjectFunc func(externalID int32) ([]*data.File, error)
+
+	// GetLatestDocumentsFunc mocks the GetLatestDocuments method.
+	GetLatestDocumentsFunc func(externalID int32, category string) ([]*data.File, error)
+
+	// GetVersionsFunc mocks the GetVersions method.
+	GetVersionsFunc func(id int64) ([]*data.File, error)
+
+	// ReconcileFunc mocks the Reconcile method.
+	ReconcileFunc func(externalID int32, objects []*data.File) error
+
+	// UpsertFunc mocks the Upsert method.
+	UpsertFunc func(file *data.File) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteByKeys holds details about calls to the DeleteByKeys method.
+		DeleteByKeys []struct {
+			// Keys is the keys argument value.
+			Keys []string
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetAllForProject holds details about calls to the GetAllForProject method.
+		GetAllForProject []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// GetLatestDocuments holds details about calls to the GetLatestDocuments method.
+		GetLatestDocuments []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+			// Category is the category argument value.
+			Category string
+		}
+		// GetVersions holds details about calls to the GetVersions method.
+		GetVersions []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// Reconcile holds details about calls to the Reconcile method.
+		Reconcile []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+			// Objects is the objects argument value.
+			Objects []*data.File
+		}
+		// Upsert holds details about calls to the Upsert method.
+		Upsert []struct {
+			// File is the file argument value.
+			File *data.File
+		}
+	}
+	lockDeleteByKeys       sync.RWMutex
+	lockGet                sync.RWMutex
+	lockGetAllForProject   sync.RWMutex
+	lockGetLatestDocuments sync.RWMutex
+	lockGetVersions        sync.RWMutex
+	lockReconcile          sync.RWMutex
+	lockUpsert             sync.RWMutex
+}
+
+// DeleteByKeys calls DeleteByKeysFunc.
+func (mock *FileStoreMock) DeleteByKeys(keys []string) error {
+	callInfo := struct {
+		Keys []string
+	}{
+		Keys: keys,
+	}
+	mock.lockDeleteByKeys.Lock()
+	mock.calls.DeleteByKeys = append(mock.calls.DeleteByKeys, callInfo)
+	mock.lockDeleteByKeys.Unlock()
+	if mock.DeleteByKeysFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteByKeysFunc(keys)
+}
+
+// DeleteByKeysCalls gets all the calls that were made to DeleteByKeys.
+// Check the length with:
+//
+//	len(mockedFileStore.DeleteByKeysCalls())
+func (mock *FileStoreMock) DeleteByKeysCalls() []struct {
+	Keys []string
+} {
+	var calls []struct {
+		Keys []string
+	}
+	mock.lockDeleteByKeys.RLock()
+	calls = mock.calls.DeleteByKeys
+	mock.lockDeleteByKeys.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *FileStoreMock) Get(id int64) (*data.File, error) {
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			fileOut *data.File
+			errOut  error
+		)
+		return fileOut, errOut
+	}
+	return mock.GetFunc(id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedFileStore.GetCalls())
+func (mock *FileStoreMock) GetCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAllForProject calls GetAllForProjectFunc.
+func (mock *FileStoreMock) GetAllForProject(externalID int32) ([]*data.File, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetAllForProject.Lock()
+	mock.calls.GetAllForProject = append(mock.calls.GetAllForProject, callInfo)
+	mock.lockGetAllForProject.Unlock()
+	if mock.GetAllForProjectFunc == nil {
+		var (
+			filesOut []*data.File
+			errOut   error
+		)
+		return filesOut, errOut
+	}
+	return mock.GetAllForProjectFunc(externalID)
+}
+
+// GetAllForProjectCalls gets all the calls that were made to GetAllForProject.
+// Check the length with:
+//
+//	len(mockedFileStore.GetAllForProjectCalls())
+func (mock *FileStoreMock) GetAllForProjectCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetAllForProject.RLock()
+	calls = mock.calls.GetAllForProject
+	mock.lockGetAllForProject.RUnlock()
+	return calls
+}
+
+// GetLatestDocuments calls GetLatestDocumentsFunc.
+func (mock *FileStoreMock) GetLatestDocuments(externalID int32, category string) ([]*data.File, error) {
+	callInfo := struct {
+		ExternalID int32
+		Category   string
+	}{
+		ExternalID: externalID,
+		Category:   category,
+	}
+	mock.lockGetLatestDocuments.Lock()
+	mock.calls.GetLatestDocuments = append(mock.calls.GetLatestDocuments, callInfo)
+	mock.lockGetLatestDocuments.Unlock()
+	if mock.GetLatestDocumentsFunc == nil {
+		var (
+			filesOut []*data.File
+			errOut   error
+		)
+		return filesOut, errOut
+	}
+	return mock.GetLatestDocumentsFunc(externalID, category)
+}
+
+// GetLatestDocumentsCalls gets all the calls that were made to GetLatestDocuments.
+// Check the length with:
+//
+//	len(mockedFileStore.GetLatestDocumentsCalls())
+func (mock *FileStoreMock) GetLatestDocumentsCalls() []struct {
+	ExternalID int32
+	Category   string
+} {
+	var calls []struct {
+		ExternalID int32
+		Category   string
+	}
+	mock.lockGetLatestDocuments.RLock()
+	calls = mock.calls.GetLatestDocuments
+	mock.lockGetLatestDocuments.RUnlock()
+	return calls
+}
+
+// GetVersions calls GetVersionsFunc.
+func (mock *FileStoreMock) GetVersions(id int64) ([]*data.File, error) {
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockGetVersions.Lock()
+	mock.calls.GetVersions = append(mock.calls.GetVersions, callInfo)
+	mock.lockGetVersions.Unlock()
+	if mock.GetVersionsFunc == nil {
+		var (
+			filesOut []*data.File
+			errOut   error
+		)
+		return filesOut, errOut
+	}
+	return mock.GetVersionsFunc(id)
+}
+
+// GetVersionsCalls gets all the calls that were made to GetVersions.
+// Check the length with:
+//
+//	len(mockedFileStore.GetVersionsCalls())
+func (mock *FileStoreMock) GetVersionsCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockGetVersions.RLock()
+	calls = mock.calls.GetVersions
+	mock.lockGetVersions.RUnlock()
+	return calls
+}
+
+// Reconcile calls ReconcileFunc.
+func (mock *FileStoreMock) Reconcile(externalID int32, objects []*data.File) error {
+	callInfo := struct {
+		ExternalID int32
+		Objects    []*data.File
+	}{
+		ExternalID: externalID,
+		Objects:    objects,
+	}
+	mock.lockReconcile.Lock()
+	mock.calls.Reconcile = append(mock.calls.Reconcile, callInfo)
+	mock.lockReconcile.Unlock()
+	if mock.ReconcileFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.ReconcileFunc(externalID, objects)
+}
+
+// ReconcileCalls gets all the calls that were made to Reconcile.
+// Check the length with:
+//
+//	len(mockedFileStore.ReconcileCalls())
+func (mock *FileStoreMock) ReconcileCalls() []struct {
+	ExternalID int32
+	Objects    []*data.File
+} {
+	var calls []struct {
+		ExternalID int32
+		Objects    []*data.File
+	}
+	mock.lockReconcile.RLock()
+	calls = mock.calls.Reconcile
+	mock.lockReconcile.RUnlock()
+	return calls
+}
+
+// Upsert calls UpsertFunc.
+func (mock *FileStoreMock) Upsert(file *data.File) error {
+	callInfo := struct {
+		File *data.File
+	}{
+		File: file,
+	}
+	mock.lockUpsert.Lock()
+	mock.calls.Upsert = append(mock.calls.Upsert, callInfo)
+	mock.lockUpsert.Unlock()
+	if mock.UpsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpsertFunc(file)
+}
+
+// UpsertCalls gets all the calls that were made to Upsert.
+// Check the length with:
+//
+//	len(mockedFileStore.UpsertCalls())
+func (mock *FileStoreMock) UpsertCalls() []struct {
+	File *data.File
+} {
+	var calls []struct {
+		File *data.File
+	}
+	mock.lockUpsert.RLock()
+	calls = mock.calls.Upsert
+	mock.lockUpsert.RUnlock()
+	return calls
+}
+
+// Ensure, that HealthStoreMock does implement data.HealthStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.HealthStore = &HealthStoreMock{}
+
+// HealthStoreMock is a mock implementation of data.HealthStore.
+//
+//	func TestSomethingThatUsesHealthStore(t *testing.T) {
+//
+//		// make and configure a mocked data.HealthStore
+//		mockedHealthStore := &HealthStoreMock{
+//			GetAlertsFunc: func(today time.Time) ([]*data.HealthAlert, error) {
+//				panic("mock out the GetAlerts method")
+//			},
+//			GetForProjectsFunc: func(externalIDs []int32, today time.Time) (map[int32]*data.ProjectHealth, error) {
+//				panic("mock out the GetForProjects method")
+//			},
+//		}
+//
+//		// use mockedHealthStore in code that requires data.HealthStore
+//		// and then make assertions.
+//
+//	}
+type HealthStoreMock struct {
+	// GetAlertsFunc mocks the GetAlerts method.
+	GetAlertsFunc func(today time.Time) ([]*data.HealthAlert, error)
+
+	// GetForProjectsFunc mocks the GetForProjects method.
+	GetForProjectsFunc func(externalIDs []int32, today time.Time) (map[int32]*data.ProjectHealth, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetAlerts holds details about calls to the GetAlerts method.
+		GetAlerts []struct {
+			// Today is the today argument value.
+			Today time.Time
+		}
+		// GetForProjects holds details about calls to the GetForProjects method.
+		GetForProjects []struct {
+			// ExternalIDs is the externalIDs argument value.
+			ExternalIDs []int32
+			// Today is the today argument value.
+			Today time.Time
+		}
+	}
+	lockGetAlerts      sync.RWMutex
+	lockGetForProjects sync.RWMutex
+}
+
+// GetAlerts calls GetAlertsFunc.
+func (mock *HealthStoreMock) GetAlerts(today time.Time) ([]*data.HealthAlert, error) {
+	callInfo := struct {
+		Today time.Time
+	}{
+		Today: today,
+	}
+	mock.lockGetAlerts.Lock()
+	mock.calls.GetAlerts = append(mock.calls.GetAlerts, callInfo)
+	mock.lockGetAlerts.Unlock()
+	if mock.GetAlertsFunc == nil {
+		var (
+			healthAlertsOut []*data.HealthAlert
+			errOut          error
+		)
+		return healthAlertsOut, errOut
+	}
+	return mock.GetAlertsFunc(today)
+}
+
+// GetAlertsCalls gets all the calls that were made to GetAlerts.
+// Check the length with:
+//
+//	len(mockedHealthStore.GetAlertsCalls())
+func (mock *HealthStoreMock) GetAlertsCalls() []struct {
+	Today time.Time
+} {
+	var calls []struct {
+		Today time.Time
+	}
+	mock.lockGetAlerts.RLock()
+	calls = mock.calls.GetAlerts
+	mock.lockGetAlerts.RUnlock()
+	return calls
+}
+
+// GetForProjects calls GetForProjectsFunc.
+func (mock *HealthStoreMock) GetForProjects(externalIDs []int32, today time.Time) (map[int32]*data.ProjectHealth, error) {
+	callInfo := struct {
+		ExternalIDs []int32
+		Today       time.Time
+	}{
+		ExternalIDs: externalIDs,
+		Today:       today,
+	}
+	mock.lockGetForProjects.Lock()
+	mock.calls.GetForProjects = append(mock.calls.GetForProjects, callInfo)
+	mock.lockGetForProjects.Unlock()
+	if mock.GetForProjectsFunc == nil {
+		var (
+			int32ToProjectHealthOut map[int32]*data.ProjectHealth
+			errOut                  error
+		)
+		return int32ToProjectHealthOut, errOut
+	}
+	return mock.GetForProjectsFunc(externalIDs, today)
+}
+
+// GetForProjectsCalls gets all the calls that were made to GetForProjects.
+// Check the length with:
+//
+//	len(mockedHealthStore.GetForProjectsCalls())
+func (mock *HealthStoreMock) GetForProjectsCalls() []struct {
+	ExternalIDs []int32
+	Today       time.Time
+} {
+	var calls []struct {
+		ExternalIDs []int32
+		Today       time.Time
+	}
+	mock.lockGetForProjects.RLock()
+	calls = mock.calls.GetForProjects
+	mock.lockGetForProjects.RUnlock()
+	return calls
+}
+
+// Ensure, that HolidayStoreMock does implement data.HolidayStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.HolidayStore = &HolidayStoreMock{}
+
+// HolidayStoreMock is a mock implementation of data.HolidayStore.
+//
+//	func TestSomethingThatUsesHolidayStore(t *testing.T) {
+//
+//		// make and configure a mocked data.HolidayStore
+//		mockedHolidayStore := &HolidayStoreMock{
+//			DeleteFunc: func(orgID int32, id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetAllFunc: func(orgID int32, from time.Time, to time.Time) ([]*data.Holiday, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(orgID int32, h *data.Holiday) error {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedHolidayStore in code that requires data.HolidayStore
+//		// and then make assertions.
+//
+//	}
+type HolidayStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(orgID int32, id int32) error
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(orgID int32, from time.Time, to time.Time) ([]*data.Holiday, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(orgID int32, h *data.Holiday) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// From is the from argument value.
+			From time.Time
+			// To is the to argument value.
+			To time.Time
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// H is the h argument value.
+			H *data.Holiday
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGetAll sync.RWMutex
+	lockInsert sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *HolidayStoreMock) Delete(orgID int32, id int32) error {
+	callInfo := struct {
+		OrgID int32
+		ID    int32
+	}{
+		OrgID: orgID,
+		ID:    id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(orgID, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedHolidayStore.DeleteCalls())
+func (mock *HolidayStoreMock) DeleteCalls() []struct {
+	OrgID int32
+	ID    int32
+} {
+	var calls []struct {
+		OrgID int32
+		ID    int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *HolidayStoreMock) GetAll(orgID int32, from time.Time, to time.Time) ([]*data.Holiday, error) {
+	callInfo := struct {
+		OrgID int32
+		From  time.Time
+		To    time.Time
+	}{
+		OrgID: orgID,
+		From:  from,
+		To:    to,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			holidaysOut []*data.Holiday
+			errOut      error
+		)
+		return holidaysOut, errOut
+	}
+	return mock.GetAllFunc(orgID, from, to)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedHolidayStore.GetAllCalls())
+func (mock *HolidayStoreMock) GetAllCalls() []struct {
+	OrgID int32
+	From  time.Time
+	To    time.Time
+} {
+	var calls []struct {
+		OrgID int32
+		From  time.Time
+		To    time.Time
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *HolidayStoreMock) Insert(orgID int32, h *data.Holiday) error {
+	callInfo := struct {
+		OrgID int32
+		H     *data.Holiday
+	}{
+		OrgID: orgID,
+		H:     h,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(orgID, h)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedHolidayStore.InsertCalls())
+func (mock *HolidayStoreMock) InsertCalls() []struct {
+	OrgID int32
+	H     *data.Holiday
+} {
+	var calls []struct {
+		OrgID int32
+		H     *data.Holiday
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that LDAPStoreMock does implement data.LDAPStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.LDAPStore = &LDAPStoreMock{}
+
+// LDAPStoreMock is a mock implementation of data.LDAPStore.
+//
+//	func TestSomethingThatUsesLDAPStore(t *testing.T) {
+//
+//		// make and configure a mocked data.LDAPStore
+//		mockedLDAPStore := &LDAPStoreMock{
+//			DeleteFunc: func(orgID int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(orgID int32) (*data.LDAPConfig, error) {
+//				panic("mock out the Get method")
+//			},
+//			LinkFunc: func(orgID int32, userID int32, linked bool) error {
+//				panic("mock out the Link method")
+//			},
+//			ProvisionFunc: func(orgID int32, user *data.User, roles []string) (bool, error) {
+//				panic("mock out the Provision method")
+//			},
+//			PutFunc: func(c *data.LDAPConfig) (bool, error) {
+//				panic("mock out the Put method")
+//			},
+//		}
+//
+//		// use mockedLDAPStore in code that requires data.LDAPStore
+//		// and then make assertions.
+//
+//	}
+type LDAPStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(orgID int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(orgID int32) (*data.LDAPConfig, error)
+
+	// LinkFunc mocks the Link method.
+	LinkFunc func(orgID int32, userID int32, linked bool) error
+
+	// ProvisionFunc mocks the Provision method.
+	ProvisionFunc func(orgID int32, user *data.User, roles []string) (bool, error)
+
+	// PutFunc mocks the Put method.
+	PutFunc func(c *data.LDAPConfig) (bool, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+		// Link holds details about calls to the Link method.
+		Link []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// UserID is the userID argument value.
+			UserID int32
+			// Linked is the linked argument value.
+			Linked bool
+		}
+		// Provision holds details about calls to the Provision method.
+		Provision []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// User is the user argument value.
+			User *data.User
+			// Roles is the roles argument value.
+			Roles []string
+		}
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// C is the c argument value.
+			C *data.LDAPConfig
+		}
+	}
+	lockDelete    sync.RWMutex
+	lockGet       sync.RWMutex
+	lockLink      sync.RWMutex
+	lockProvision sync.RWMutex
+	lockPut       sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *LDAPStoreMock) Delete(orgID int32) error {
+	callInfo := struct {
+		OrgID int32
+	}{
+		OrgID: orgID,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(orgID)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedLDAPStore.DeleteCalls())
+func (mock *LDAPStoreMock) DeleteCalls() []struct {
+	OrgID int32
+} {
+	var calls []struct {
+		OrgID int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *LDAPStoreMock) Get(orgID int32) (*data.LDAPConfig, error) {
+	callInfo := struct {
+		OrgID int32
+	}{
+		OrgID: orgID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			lDAPConfigOut *data.LDAPConfig
+			errOut        error
+		)
+		return lDAPConfigOut, errOut
+	}
+	return mock.GetFunc(orgID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedLDAPStore.GetCalls())
+func (mock *LDAPStoreMock) GetCalls() []struct {
+	OrgID int32
+} {
+	var calls []struct {
+		OrgID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// Link calls LinkFunc.
+func (mock *LDAPStoreMock) Link(orgID int32, userID int32, linked bool) error {
+	callInfo := struct {
+		OrgID  int32
+		UserID int32
+		Linked bool
+	}{
+		OrgID:  orgID,
+		UserID: userID,
+		Linked: linked,
+	}
+	mock.lockLink.Lock()
+	mock.calls.Link = append(mock.calls.Link, callInfo)
+	mock.lockLink.Unlock()
+	if mock.LinkFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.LinkFunc(orgID, userID, linked)
+}
+
+// LinkCalls gets all the calls that were made to Link.
+// Check the length with:
+//
+//	len(mockedLDAPStore.LinkCalls())
+func (mock *LDAPStoreMock) LinkCalls() []struct {
+	OrgID  int32
+	UserID int32
+	Linked bool
+} {
+	var calls []struct {
+		OrgID  int32
+		UserID int32
+		Linked bool
+	}
+	mock.lockLink.RLock()
+	calls = mock.calls.Link
+	mock.lockLink.RUnlock()
+	return calls
+}
+
+// Provision calls ProvisionFunc.
+func (mock *LDAPStoreMock) Provision(orgID int32, user *data.User, roles []string) (bool, error) {
+	callInfo := struct {
+		OrgID int32
+		User  *data.User
+		Roles []string
+	}{
+		OrgID: orgID,
+		User:  user,
+		Roles: roles,
+	}
+	mock.lockProvision.Lock()
+	mock.calls.Provision = append(mock.calls.Provision, callInfo)
+	mock.lockProvision.Unlock()
+	if mock.ProvisionFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.ProvisionFunc(orgID, user, roles)
+}
+
+// ProvisionCalls gets all the calls that were made to Provision.
+// Check the length with:
+//
+//	len(mockedLDAPStore.ProvisionCalls())
+func (mock *LDAPStoreMock) ProvisionCalls() []struct {
+	OrgID int32
+	User  *data.User
+	Roles []string
+} {
+	var calls []struct {
+		OrgID int32
+		User  *data.User
+		Roles []string
+	}
+	mock.lockProvision.RLock()
+	calls = mock.calls.Provision
+	mock.lockProvision.RUnlock()
+	return calls
+}
+
+// Put calls PutFunc.
+func (mock *LDAPStoreMock) Put(c *data.LDAPConfig) (bool, error) {
+	callInfo := struct {
+		C *data.LDAPConfig
+	}{
+		C: c,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	if mock.PutFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.PutFunc(c)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//
+//	len(mockedLDAPStore.PutCalls())
+func (mock *LDAPStoreMock) PutCalls() []struct {
+	C *data.LDAPConfig
+} {
+	var calls []struct {
+		C *data.LDAPConfig
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
+
+// Ensure, that LeaveStoreMock does implement data.LeaveStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.LeaveStore = &LeaveStoreMock{}
+
+// LeaveStoreMock is a mock implementation of data.LeaveStore.
+//
+//	func TestSomethingThatUsesLeaveStore(t *testing.T) {
+//
+//		// make and configure a mocked data.LeaveStore
+//		mockedLeaveStore := &LeaveStoreMock{
+//			DeleteFunc: func(id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, id int32) (*data.Leave, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, userID int32, from *time.Time, to *time.Time) ([]*data.Leave, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(actor data.Actor, l *data.Leave) error {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedLeaveStore in code that requires data.LeaveStore
+//		// and then make assertions.
+//
+//	}
+type LeaveStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(id int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, id int32) (*data.Leave, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, userID int32, from *time.Time, to *time.Time) ([]*data.Leave, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(actor data.Actor, l *data.Leave) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ID is the id argument value.
+			ID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// UserID is the userID argument value.
+			UserID int32
+			// From is the from argument value.
+			From *time.Time
+			// To is the to argument value.
+			To *time.Time
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// L is the l argument value.
+			L *data.Leave
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockGetAll sync.RWMutex
+	lockInsert sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *LeaveStoreMock) Delete(id int32) error {
+	callInfo := struct {
+		ID int32
+	}{
+		ID: id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedLeaveStore.DeleteCalls())
+func (mock *LeaveStoreMock) DeleteCalls() []struct {
+	ID int32
+} {
+	var calls []struct {
+		ID int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *LeaveStoreMock) Get(actor data.Actor, id int32) (*data.Leave, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			leaveOut *data.Leave
+			errOut   error
+		)
+		return leaveOut, errOut
+	}
+	return mock.GetFunc(actor, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedLeaveStore.GetCalls())
+func (mock *LeaveStoreMock) GetCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *LeaveStoreMock) GetAll(actor data.Actor, userID int32, from *time.Time, to *time.Time) ([]*data.Leave, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		UserID int32
+		From   *time.Time
+		To     *time.Time
+	}{
+		Actor:  actor,
+		UserID: userID,
+		From:   from,
+		To:     to,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			leavesOut []*data.Leave
+			errOut    error
+		)
+		return leavesOut, errOut
+	}
+	return mock.GetAllFunc(actor, userID, from, to)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedLeaveStore.GetAllCalls())
+func (mock *LeaveStoreMock) GetAllCalls() []struct {
+	Actor  data.Actor
+	UserID int32
+	From   *time.Time
+	To     *time.Time
+} {
+	var calls []struct {
+		Actor  data.Actor
+		UserID int32
+		From   *time.Time
+		To     *time.Time
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *LeaveStoreMock) Insert(actor data.Actor, l *data.Leave) error {
+	callInfo := struct {
+		Actor data.Actor
+		L     *data.Leave
+	}{
+		Actor: actor,
+		L:     l,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(actor, l)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedLeaveStore.InsertCalls())
+func (mock *LeaveStoreMock) InsertCalls() []struct {
+	Actor data.Actor
+	L     *data.Leave
+} {
+	var calls []struct {
+		Actor data.Actor
+		L     *data.Leave
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that MilestoneStoreMock does implement data.MilestoneStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.MilestoneStore = &MilestoneStoreMock{}
+
+// MilestoneStoreMock is a mock implementation of data.MilestoneStore.
+//
+//	func TestSomethingThatUsesMilestoneStore(t *testing.T) {
+//
+//		// make and configure a mocked data.MilestoneStore
+//		mockedMilestoneStore := &MilestoneStoreMock{
+//			DeleteFunc: func(actor data.Actor, id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetAllForProjectFunc: func(externalID int32) ([]*data.Milestone, error) {
+//				panic("mock out the GetAllForProject method")
+//			},
+//			GetCalendarForUserFunc: func(userID int32) ([]*data.CalendarEvent, error) {
+//				panic("mock out the GetCalendarForUser method")
+//			},
+//			InsertFunc: func(milestone *data.Milestone) error {
+//				panic("mock out the Insert method")
+//			},
+//			SetCompletedFunc: func(actor data.Actor, id int32, completedOn *time.Time) (*data.Milestone, error) {
+//				panic("mock out the SetCompleted method")
+//			},
+//		}
+//
+//		// use mockedMilestoneStore in code that requires data.MilestoneStore
+//		// and then make assertions.
+//
+//	}
+type MilestoneStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(actor data.Actor, id int32) error
+
+	// GetAllForProjectFunc mocks the GetAllForProject method.
+	GetAllForProjectFunc func(externalID int32) ([]*data.Milestone, error)
+
+	// GetCalendarForUserFunc mocks the GetCalendarForUser method.
+	GetCalendarForUserFunc func(userID int32) ([]*data.CalendarEvent, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(milestone *data.Milestone) error
+
+	// SetCompletedFunc mocks the SetCompleted method.
+	SetCompletedFunc func(actor data.Actor, id int32, completedOn *time.Time) (*data.Milestone, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAllForProject holds details about calls to the GetAllForProject method.
+		GetAllForProject []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// GetCalendarForUser holds details about calls to the GetCalendarForUser method.
+		GetCalendarForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Milestone is the milestone argument value.
+			Milestone *data.Milestone
+		}
+		// SetCompleted holds details about calls to the SetCompleted method.
+		SetCompleted []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+			// CompletedOn is the completedOn argument value.
+			CompletedOn *time.Time
+		}
+	}
+	lockDelete             sync.RWMutex
+	lockGetAllForProject   sync.RWMutex
+	lockGetCalendarForUser sync.RWMutex
+	lockInsert             sync.RWMutex
+	lockSetCompleted       sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *MilestoneStoreMock) Delete(actor data.Actor, id int32) error {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(actor, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedMilestoneStore.DeleteCalls())
+func (mock *MilestoneStoreMock) DeleteCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetAllForProject calls GetAllForProjectFunc.
+func (mock *MilestoneStoreMock) GetAllForProject(externalID int32) ([]*data.Milestone, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetAllForProject.Lock()
+	mock.calls.GetAllForProject = append(mock.calls.GetAllForProject, callInfo)
+	mock.lockGetAllForProject.Unlock()
+	if mock.GetAllForProjectFunc == nil {
+		var (
+			milestonesOut []*data.Milestone
+			errOut        error
+		)
+		return milestonesOut, errOut
+	}
+	return mock.GetAllForProjectFunc(externalID)
+}
+
+// GetAllForProjectCalls gets all the calls that were made to GetAllForProject.
+// Check the length with:
+//
+//	len(mockedMilestoneStore.GetAllForProjectCalls())
+func (mock *MilestoneStoreMock) GetAllForProjectCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetAllForProject.RLock()
+	calls = mock.calls.GetAllForProject
+	mock.lockGetAllForProject.RUnlock()
+	return calls
+}
+
+// GetCalendarForUser calls GetCalendarForUserFunc.
+func (mock *MilestoneStoreMock) GetCalendarForUser(userID int32) ([]*data.CalendarEvent, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetCalendarForUser.Lock()
+	mock.calls.GetCalendarForUser = append(mock.calls.GetCalendarForUser, callInfo)
+	mock.lockGetCalendarForUser.Unlock()
+	if mock.GetCalendarForUserFunc == nil {
+		var (
+			calendarEventsOut []*data.CalendarEvent
+			errOut            error
+		)
+		return calendarEventsOut, errOut
+	}
+	return mock.GetCalendarForUserFunc(userID)
+}
+
+// GetCalendarForUserCalls gets all the calls that were made to GetCalendarForUser.
+// Check the length with:
+//
+//	len(mockedMilestoneStore.GetCalendarForUserCalls())
+func (mock *MilestoneStoreMock) GetCalendarForUserCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetCalendarForUser.RLock()
+	calls = mock.calls.GetCalendarForUser
+	mock.lockGetCalendarForUser.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *MilestoneStoreMock) Insert(milestone *data.Milestone) error {
+	callInfo := struct {
+		Milestone *data.Milestone
+	}{
+		Milestone: milestone,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(milestone)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedMilestoneStore.InsertCalls())
+func (mock *MilestoneStoreMock) InsertCalls() []struct {
+	Milestone *data.Milestone
+} {
+	var calls []struct {
+		Milestone *data.Milestone
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// SetCompleted calls SetCompletedFunc.
+func (mock *MilestoneStoreMock) SetCompleted(actor data.Actor, id int32, completedOn *time.Time) (*data.Milestone, error) {
+	callInfo := struct {
+		Actor       data.Actor
+		ID          int32
+		CompletedOn *time.Time
+	}{
+		Actor:       actor,
+		ID:          id,
+		CompletedOn: completedOn,
+	}
+	mock.lockSetCompleted.Lock()
+	mock.calls.SetCompleted = append(mock.calls.SetCompleted, callInfo)
+	mock.lockSetCompleted.Unlock()
+	if mock.SetCompletedFunc == nil {
+		var (
+			milestoneOut *data.Milestone
+			errOut       error
+		)
+		return milestoneOut, errOut
+	}
+	return mock.SetCompletedFunc(actor, id, completedOn)
+}
+
+// SetCompletedCalls gets all the calls that were made to SetCompleted.
+// Check the length with:
+//
+//	len(mockedMilestoneStore.SetCompletedCalls())
+func (mock *MilestoneStoreMock) SetCompletedCalls() []struct {
+	Actor       data.Actor
+	ID          int32
+	CompletedOn *time.Time
+} {
+	var calls []struct {
+		Actor       data.Actor
+		ID          int32
+		CompletedOn *time.Time
+	}
+	mock.lockSetCompleted.RLock()
+	calls = mock.calls.SetCompleted
+	mock.lockSetCompleted.RUnlock()
+	return calls
+}
+
+// Ensure, that NotificationStoreMock does implement data.NotificationStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.NotificationStore = &NotificationStoreMock{}
+
+// NotificationStoreMock is a mock implementation of data.NotificationStore.
+//
+//	func TestSomethingThatUsesNotificationStore(t *testing.T) {
+//
+//		// make and configure a mocked data.NotificationStore
+//		mockedNotificationStore := &NotificationStoreMock{
+//			CountUnreadFunc: func(userID int32) (int, error) {
+//				panic("mock out the CountUnread method")
+//			},
+//			GetAllForUserFunc: func(userID int32, unreadOnly bool, filters data.Filters) ([]*data.Notification, data.Metadata, error) {
+//				panic("mock out the GetAllForUser method")
+//			},
+//			InsertFunc: func(n *data.Notification) error {
+//				panic("mock out the Insert method")
+//			},
+//			MarkAllReadFunc: func(userID int32) (int64, error) {
+//				panic("mock out the MarkAllRead method")
+//			},
+//			MarkReadFunc: func(userID int32, id int64) error {
+//				panic("mock out the MarkRead method")
+//			},
+//		}
+//
+//		// use mockedNotificationStore in code that requires data.NotificationStore
+//		// and then make assertions.
+//
+//	}
+type NotificationStoreMock struct {
+	// CountUnreadFunc mocks the CountUnread method.
+	CountUnreadFunc func(userID int32) (int, error)
+
+	// GetAllForUserFunc mocks the GetAllForUser method.
+	GetAllForUserFunc func(userID int32, unreadOnly bool, filters data.Filters) ([]*data.Notification, data.Metadata, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(n *data.Notification) error
+
+	// MarkAllReadFunc mocks the MarkAllRead method.
+	MarkAllReadFunc func(userID int32) (int64, error)
+
+	// MarkReadFunc mocks the MarkRead method.
+	MarkReadFunc func(userID int32, id int64) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CountUnread holds details about calls to the CountUnread method.
+		CountUnread []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// GetAllForUser holds details about calls to the GetAllForUser method.
+		GetAllForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// UnreadOnly is the unreadOnly argument value.
+			UnreadOnly bool
+			// Filters is the filters argument value.
+			Filters data.Filters
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// N is the n argument value.
+			N *data.Notification
+		}
+		// MarkAllRead holds details about calls to the MarkAllRead method.
+		MarkAllRead []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// MarkRead holds details about calls to the MarkRead method.
+		MarkRead []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// ID is the id argument value.
+			ID int64
+		}
+	}
+	lockCountUnread   sync.RWMutex
+	lockGetAllForUser sync.RWMutex
+	lockInsert        sync.RWMutex
+	lockMarkAllRead   sync.RWMutex
+	lockMarkRead      sync.RWMutex
+}
+
+// CountUnread calls CountUnreadFunc.
+func (mock *NotificationStoreMock) CountUnread(userID int32) (int, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockCountUnread.Lock()
+	mock.calls.CountUnread = append(mock.calls.CountUnread, callInfo)
+	mock.lockCountUnread.Unlock()
+	if mock.CountUnreadFunc == nil {
+		var (
+			nOut   int
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.CountUnreadFunc(userID)
+}
+
+// CountUnreadCalls gets all the calls that were made to CountUnread.
+// Check the length with:
+//
+//	len(mockedNotificationStore.CountUnreadCalls())
+func (mock *NotificationStoreMock) CountUnreadCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockCountUnread.RLock()
+	calls = mock.calls.CountUnread
+	mock.lockCountUnread.RUnlock()
+	return calls
+}
+
+// GetAllForUser calls GetAllForUserFunc.
+func (mock *NotificationStoreMock) GetAllForUser(userID int32, unreadOnly bool, filters data.Filters) ([]*data.Notification, data.Metadata, error) {
+	callInfo := struct {
+		UserID     int32
+		UnreadOnly bool
+		Filters    data.Filters
+	}{
+		UserID:     userID,
+		UnreadOnly: unreadOnly,
+		Filters:    filters,
+	}
+	mock.lockGetAllForUser.Lock()
+	mock.calls.GetAllForUser = append(mock.calls.GetAllForUser, callInfo)
+	mock.lockGetAllForUser.Unlock()
+	if mock.GetAllForUserFunc == nil {
+		var (
+			notificationsOut []*data.Notification
+			metadataOut      data.Metadata
+			errOut           error
+		)
+		return notificationsOut, metadataOut, errOut
+	}
+	return mock.GetAllForUserFunc(userID, unreadOnly, filters)
+}
+
+// GetAllForUserCalls gets all the calls that were made to GetAllForUser.
+// Check the length with:
+//
+//	len(mockedNotificationStore.GetAllForUserCalls())
+func (mock *NotificationStoreMock) GetAllForUserCalls() []struct {
+	UserID     int32
+	UnreadOnly bool
+	Filters    data.Filters
+} {
+	var calls []struct {
+		UserID     int32
+		UnreadOnly bool
+		Filters    data.Filters
+	}
+	mock.lockGetAllForUser.RLock()
+	calls = mock.calls.GetAllForUser
+	mock.lockGetAllForUser.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *NotificationStoreMock) Insert(n *data.Notification) error {
+	callInfo := struct {
+		N *data.Notification
+	}{
+		N: n,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(n)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedNotificationStore.InsertCalls())
+func (mock *NotificationStoreMock) InsertCalls() []struct {
+	N *data.Notification
+} {
+	var calls []struct {
+		N *data.Notification
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// MarkAllRead calls MarkAllReadFunc.
+func (mock *NotificationStoreMock) MarkAllRead(userID int32) (int64, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockMarkAllRead.Lock()
+	mock.calls.MarkAllRead = append(mock.calls.MarkAllRead, callInfo)
+	mock.lockMarkAllRead.Unlock()
+	if mock.MarkAllReadFunc == nil {
+		var (
+			nOut   int64
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.MarkAllReadFunc(userID)
+}
+
+// MarkAllReadCalls gets all the calls that were made to MarkAllRead.
+// Check the length with:
+//
+//	len(mockedNotificationStore.MarkAllReadCalls())
+func (mock *NotificationStoreMock) MarkAllReadCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockMarkAllRead.RLock()
+	calls = mock.calls.MarkAllRead
+	mock.lockMarkAllRead.RUnlock()
+	return calls
+}
+
+// MarkRead calls MarkReadFunc.
+func (mock *NotificationStoreMock) MarkRead(userID int32, id int64) error {
+	callInfo := struct {
+		UserID int32
+		ID     int64
+	}{
+		UserID: userID,
+		ID:     id,
+	}
+	mock.lockMarkRead.Lock()
+	mock.calls.MarkRead = append(mock.calls.MarkRead, callInfo)
+	mock.lockMarkRead.Unlock()
+	if mock.MarkReadFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.MarkReadFunc(userID, id)
+}
+
+// MarkReadCalls gets all the calls that were made to MarkRead.
+// Check the length with:
+//
+//	len(mockedNotificationStore.MarkReadCalls())
+func (mock *NotificationStoreMock) MarkReadCalls() []struct {
+	UserID int32
+	ID     int64
+} {
+	var calls []struct {
+		UserID int32
+		ID     int64
+	}
+	mock.lockMarkRead.RLock()
+	calls = mock.calls.MarkRead
+	mock.lockMarkRead.RUnlock()
+	return calls
+}
+
+// Ensure, that NotificationPreferenceStoreMock does implement data.NotificationPreferenceStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.NotificationPreferenceStore = &NotificationPreferenceStoreMock{}
+
+// NotificationPreferenceStoreMock is a mock implementation of data.NotificationPreferenceStore.
+//
+//	func TestSomethingThatUsesNotificationPreferenceStore(t *testing.T) {
+//
+//		// make and configure a mocked data.NotificationPreferenceStore
+//		mockedNotificationPreferenceStore := &NotificationPreferenceStoreMock{
+//			EmailEnabledFunc: func(userID int32, category string) (bool, error) {
+//				panic("mock out the EmailEnabled method")
+//			},
+//			GetAllForUserFunc: func(userID int32) ([]*data.NotificationPreference, error) {
+//				panic("mock out the GetAllForUser method")
+//			},
+//			UpsertFunc: func(userID int32, p *data.NotificationPreference) error {
+//				panic("mock out the Upsert method")
+//			},
+//		}
+//
+//		// use mockedNotificationPreferenceStore in code that requires data.NotificationPreferenceStore
+//		// and then make assertions.
+//
+//	}
+type NotificationPreferenceStoreMock struct {
+	// EmailEnabledFunc mocks the EmailEnabled method.
+	EmailEnabledFunc func(userID int32, category string) (bool, error)
+
+	// GetAllForUserFunc mocks the GetAllForUser method.
+	GetAllForUserFunc func(userID int32) ([]*data.NotificationPreference, error)
+
+	// UpsertFunc mocks the Upsert method.
+	UpsertFunc func(userID int32, p *data.NotificationPreference) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// EmailEnabled holds details about calls to the EmailEnabled method.
+		EmailEnabled []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// Category is the category argument value.
+			Category string
+		}
+		// GetAllForUser holds details about calls to the GetAllForUser method.
+		GetAllForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// Upsert holds details about calls to the Upsert method.
+		Upsert []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// P is the p argument value.
+			P *data.NotificationPreference
+		}
+	}
+	lockEmailEnabled  sync.RWMutex
+	lockGetAllForUser sync.RWMutex
+	lockUpsert        sync.RWMutex
+}
+
+// EmailEnabled calls EmailEnabledFunc.
+func (mock *NotificationPreferenceStoreMock) EmailEnabled(userID int32, category string) (bool, error) {
+	callInfo := struct {
+		UserID   int32
+		Category string
+	}{
+		UserID:   userID,
+		Category: category,
+	}
+	mock.lockEmailEnabled.Lock()
+	mock.calls.EmailEnabled = append(mock.calls.EmailEnabled, callInfo)
+	mock.lockEmailEnabled.Unlock()
+	if mock.EmailEnabledFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.EmailEnabledFunc(userID, category)
+}
+
+// EmailEnabledCalls gets all the calls that were made to EmailEnabled.
+// Check the length with:
+//
+//	len(mockedNotificationPreferenceStore.EmailEnabledCalls())
+func (mock *NotificationPreferenceStoreMock) EmailEnabledCalls() []struct {
+	UserID   int32
+	Category string
+} {
+	var calls []struct {
+		UserID   int32
+		Category string
+	}
+	mock.lockEmailEnabled.RLock()
+	calls = mock.calls.EmailEnabled
+	mock.lockEmailEnabled.RUnlock()
+	return calls
+}
+
+// GetAllForUser calls GetAllForUserFunc.
+func (mock *NotificationPreferenceStoreMock) GetAllForUser(userID int32) ([]*data.NotificationPreference, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetAllForUser.Lock()
+	mock.calls.GetAllForUser = append(mock.calls.GetAllForUser, callInfo)
+	mock.lockGetAllForUser.Unlock()
+	if mock.GetAllForUserFunc == nil {
+		var (
+			notificationPreferencesOut []*data.NotificationPreference
+			errOut                     error
+		)
+		return notificationPreferencesOut, errOut
+	}
+	return mock.GetAllForUserFunc(userID)
+}
+
+// GetAllForUserCalls gets all the calls that were made to GetAllForUser.
+// Check the length with:
+//
+//	len(mockedNotificationPreferenceStore.GetAllForUserCalls())
+func (mock *NotificationPreferenceStoreMock) GetAllForUserCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetAllForUser.RLock()
+	calls = mock.calls.GetAllForUser
+	mock.lockGetAllForUser.RUnlock()
+	return calls
+}
+
+// Upsert calls UpsertFunc.
+func (mock *NotificationPreferenceStoreMock) Upsert(userID int32, p *data.NotificationPreference) error {
+	callInfo := struct {
+		UserID int32
+		P      *data.NotificationPreference
+	}{
+		UserID: userID,
+		P:      p,
+	}
+	mock.lockUpsert.Lock()
+	mock.calls.Upsert = append(mock.calls.Upsert, callInfo)
+	mock.lockUpsert.Unlock()
+	if mock.UpsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpsertFunc(userID, p)
+}
+
+// UpsertCalls gets all the calls that were made to Upsert.
+// Check the length with:
+//
+//	len(mockedNotificationPreferenceStore.UpsertCalls())
+func (mock *NotificationPreferenceStoreMock) UpsertCalls() []struct {
+	UserID int32
+	P      *data.NotificationPreference
+} {
+	var calls []struct {
+		UserID int32
+		P      *data.NotificationPreference
+	}
+	mock.lockUpsert.RLock()
+	calls = mock.calls.Upsert
+	mock.lockUpsert.RUnlock()
+	return calls
+}
+
+// Ensure, that OrganizationStoreMock does implement data.OrganizationStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.OrganizationStore = &OrganizationStoreMock{}
+
+// OrganizationStoreMock is a mock implementation of data.OrganizationStore.
+//
+//	func TestSomethingThatUsesOrganizationStore(t *testing.T) {
+//
+//		// make and configure a mocked data.OrganizationStore
+//		mockedOrganizationStore := &OrganizationStoreMock{
+//			DeleteFunc: func(id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(id int32) (*data.Organization, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func() ([]*data.Organization, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(org *data.Organization) error {
+//				panic("mock out the Insert method")
+//			},
+//			UpdateFunc: func(org *data.Organization) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedOrganizationStore in code that requires data.OrganizationStore
+//		// and then make assertions.
+//
+//	}
+type OrganizationStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(id int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(id int32) (*data.Organization, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func() ([]*data.Organization, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(org *data.Organization) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(org *data.Organization) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// ID is the id argument value.
+			ID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Org is the org argument value.
+			Org *data.Organization
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Org is the org argument value.
+			Org *data.Organization
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockGetAll sync.RWMutex
+	lockInsert sync.RWMutex
+	lockUpdate sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *OrganizationStoreMock) Delete(id int32) error {
+	callInfo := struct {
+		ID int32
+	}{
+		ID: id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedOrganizationStore.DeleteCalls())
+func (mock *OrganizationStoreMock) DeleteCalls() []struct {
+	ID int32
+} {
+	var calls []struct {
+		ID int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *OrganizationStoreMock) Get(id int32) (*data.Organization, error) {
+	callInfo := struct {
+		ID int32
+	}{
+		ID: id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			organizationOut *data.Organization
+			errOut          error
+		)
+		return organizationOut, errOut
+	}
+	return mock.GetFunc(id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedOrganizationStore.GetCalls())
+func (mock *OrganizationStoreMock) GetCalls() []struct {
+	ID int32
+} {
+	var calls []struct {
+		ID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *OrganizationStoreMock) GetAll() ([]*data.Organization, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			organizationsOut []*data.Organization
+			errOut           error
+		)
+		return organizationsOut, errOut
+	}
+	return mock.GetAllFunc()
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedOrganizationStore.GetAllCalls())
+func (mock *OrganizationStoreMock) GetAllCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *OrganizationStoreMock) Insert(org *data.Organization) error {
+	callInfo := struct {
+		Org *data.Organization
+	}{
+		Org: org,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(org)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedOrganizationStore.InsertCalls())
+func (mock *OrganizationStoreMock) InsertCalls() []struct {
+	Org *data.Organization
+} {
+	var calls []struct {
+		Org *data.Organization
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *OrganizationStoreMock) Update(org *data.Organization) error {
+	callInfo := struct {
+		Org *data.Organization
+	}{
+		Org: org,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(org)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedOrganizationStore.UpdateCalls())
+func (mock *OrganizationStoreMock) UpdateCalls() []struct {
+	Org *data.Organization
+} {
+	var calls []struct {
+		Org *data.Organization
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that OrgSettingsStoreMock does implement data.OrgSettingsStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.OrgSettingsStore = &OrgSettingsStoreMock{}
+
+// OrgSettingsStoreMock is a mock implementation of data.OrgSettingsStore.
+//
+//	func TestSomethingThatUsesOrgSettingsStore(t *testing.T) {
+//
+//		// make and configure a mocked data.OrgSettingsStore
+//		mockedOrgSettingsStore := &OrgSettingsStoreMock{
+//			GetFunc: func(orgID int32) (*data.OrgSettings, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetForUserFunc: func(userID int32) (*data.OrgSettings, error) {
+//				panic("mock out the GetForUser method")
+//			},
+//			UpdateFunc: func(s *data.OrgSettings) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedOrgSettingsStore in code that requires data.OrgSettingsStore
+//		// and then make assertions.
+//
+//	}
+type OrgSettingsStoreMock struct {
+	// GetFunc mocks the Get method.
+	GetFunc func(orgID int32) (*data.OrgSettings, error)
+
+	// GetForUserFunc mocks the GetForUser method.
+	GetForUserFunc func(userID int32) (*data.OrgSettings, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(s *data.OrgSettings) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+		// GetForUser holds details about calls to the GetForUser method.
+		GetForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// S is the s argument value.
+			S *data.OrgSettings
+		}
+	}
+	lockGet        sync.RWMutex
+	lockGetForUser sync.RWMutex
+	lockUpdate     sync.RWMutex
+}
+
+// Get calls GetFunc.
+func (mock *OrgSettingsStoreMock) Get(orgID int32) (*data.OrgSettings, error) {
+	callInfo := struct {
+		OrgID int32
+	}{
+		OrgID: orgID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			orgSettingsOut *data.OrgSettings
+			errOut         error
+		)
+		return orgSettingsOut, errOut
+	}
+	return mock.GetFunc(orgID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedOrgSettingsStore.GetCalls())
+func (mock *OrgSettingsStoreMock) GetCalls() []struct {
+	OrgID int32
+} {
+	var calls []struct {
+		OrgID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetForUser calls GetForUserFunc.
+func (mock *OrgSettingsStoreMock) GetForUser(userID int32) (*data.OrgSettings, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetForUser.Lock()
+	mock.calls.GetForUser = append(mock.calls.GetForUser, callInfo)
+	mock.lockGetForUser.Unlock()
+	if mock.GetForUserFunc == nil {
+		var (
+			orgSettingsOut *data.OrgSettings
+			errOut         error
+		)
+		return orgSettingsOut, errOut
+	}
+	return mock.GetForUserFunc(userID)
+}
+
+// GetForUserCalls gets all the calls that were made to GetForUser.
+// Check the length with:
+//
+//	len(mockedOrgSettingsStore.GetForUserCalls())
+func (mock *OrgSettingsStoreMock) GetForUserCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetForUser.RLock()
+	calls = mock.calls.GetForUser
+	mock.lockGetForUser.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *OrgSettingsStoreMock) Update(s *data.OrgSettings) error {
+	callInfo := struct {
+		S *data.OrgSettings
+	}{
+		S: s,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(s)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedOrgSettingsStore.UpdateCalls())
+func (mock *OrgSettingsStoreMock) UpdateCalls() []struct {
+	S *data.OrgSettings
+} {
+	var calls []struct {
+		S *data.OrgSettings
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that PermissionStoreMock does implement data.PermissionStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.PermissionStore = &PermissionStoreMock{}
+
+// PermissionStoreMock is a mock implementation of data.PermissionStore.
+//
+//	func TestSomethingThatUsesPermissionStore(t *testing.T) {
+//
+//		// make and configure a mocked data.PermissionStore
+//		mockedPermissionStore := &PermissionStoreMock{
+//			GetActorFunc: func(userID int32) (data.Actor, error) {
+//				panic("mock out the GetActor method")
+//			},
+//			GetActorClaimsFunc: func(userID int32) (data.Actor, int32, error) {
+//				panic("mock out the GetActorClaims method")
+//			},
+//			GetAllForUserFunc: func(userID int32) (data.Permissions, error) {
+//				panic("mock out the GetAllForUser method")
+//			},
+//			GetClaimsVersionsFunc: func(since time.Time) (map[int32]int32, error) {
+//				panic("mock out the GetClaimsVersions method")
+//			},
+//		}
+//
+//		// use mockedPermissionStore in code that requires data.PermissionStore
+//		// and then make assertions.
+//
+//	}
+type PermissionStoreMock struct {
+	// GetActorFunc mocks the GetActor method.
+	GetActorFunc func(userID int32) (data.Actor, error)
+
+	// GetActorClaimsFunc mocks the GetActorClaims method.
+	GetActorClaimsFunc func(userID int32) (data.Actor, int32, error)
+
+	// GetAllForUserFunc mocks the GetAllForUser method.
+	GetAllForUserFunc func(userID int32) (data.Permissions, error)
+
+	// GetClaimsVersionsFunc mocks the GetClaimsVersions method.
+	GetClaimsVersionsFunc func(since time.Time) (map[int32]int32, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetActor holds details about calls to the GetActor method.
+		GetActor []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// GetActorClaims holds details about calls to the GetActorClaims method.
+		GetActorClaims []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// GetAllForUser holds details about calls to the GetAllForUser method.
+		GetAllForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// GetClaimsVersions holds details about calls to the GetClaimsVersions method.
+		GetClaimsVersions []struct {
+			// Since is the since argument value.
+			Since time.Time
+		}
+	}
+	lockGetActor          sync.RWMutex
+	lockGetActorClaims    sync.RWMutex
+	lockGetAllForUser     sync.RWMutex
+	lockGetClaimsVersions sync.RWMutex
+}
+
+// GetActor calls GetActorFunc.
+func (mock *PermissionStoreMock) GetActor(userID int32) (data.Actor, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetActor.Lock()
+	mock.calls.GetActor = append(mock.calls.GetActor, callInfo)
+	mock.lockGetActor.Unlock()
+	if mock.GetActorFunc == nil {
+		var (
+			actorOut data.Actor
+			errOut   error
+		)
+		return actorOut, errOut
+	}
+	return mock.GetActorFunc(userID)
+}
+
+// GetActorCalls gets all the calls that were made to GetActor.
+// Check the length with:
+//
+//	len(mockedPermissionStore.GetActorCalls())
+func (mock *PermissionStoreMock) GetActorCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetActor.RLock()
+	calls = mock.calls.GetActor
+	mock.lockGetActor.RUnlock()
+	return calls
+}
+
+// GetActorClaims calls GetActorClaimsFunc.
+func (mock *PermissionStoreMock) GetActorClaims(userID int32) (data.Actor, int32, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetActorClaims.Lock()
+	mock.calls.GetActorClaims = append(mock.calls.GetActorClaims, callInfo)
+	mock.lockGetActorClaims.Unlock()
+	if mock.GetActorClaimsFunc == nil {
+		var (
+			actorOut data.Actor
+			nOut     int32
+			errOut   error
+		)
+		return actorOut, nOut, errOut
+	}
+	return mock.GetActorClaimsFunc(userID)
+}
+
+// GetActorClaimsCalls gets all the calls that were made to GetActorClaims.
+// Check the length with:
+//
+//	len(mockedPermissionStore.GetActorClaimsCalls())
+func (mock *PermissionStoreMock) GetActorClaimsCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetActorClaims.RLock()
+	calls = mock.calls.GetActorClaims
+	mock.lockGetActorClaims.RUnlock()
+	return calls
+}
+
+// GetAllForUser calls GetAllForUserFunc.
+func (mock *PermissionStoreMock) GetAllForUser(userID int32) (data.Permissions, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockGetAllForUser.Lock()
+	mock.calls.GetAllForUser = append(mock.calls.GetAllForUser, callInfo)
+	mock.lockGetAllForUser.Unlock()
+	if mock.GetAllForUserFunc == nil {
+		var (
+			permissionsOut data.Permissions
+			errOut         error
+		)
+		return permissionsOut, errOut
+	}
+	return mock.GetAllForUserFunc(userID)
+}
+
+// GetAllForUserCalls gets all the calls that were made to GetAllForUser.
+// Check the length with:
+//
+//	len(mockedPermissionStore.GetAllForUserCalls())
+func (mock *PermissionStoreMock) GetAllForUserCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockGetAllForUser.RLock()
+	calls = mock.calls.GetAllForUser
+	mock.lockGetAllForUser.RUnlock()
+	return calls
+}
+
+// GetClaimsVersions calls GetClaimsVersionsFunc.
+func (mock *PermissionStoreMock) GetClaimsVersions(since time.Time) (map[int32]int32, error) {
+	callInfo := struct {
+		Since time.Time
+	}{
+		Since: since,
+	}
+	mock.lockGetClaimsVersions.Lock()
+	mock.calls.GetClaimsVersions = append(mock.calls.GetClaimsVersions, callInfo)
+	mock.lockGetClaimsVersions.Unlock()
+	if mock.GetClaimsVersionsFunc == nil {
+		var (
+			int32ToInt32Out map[int32]int32
+			errOut          error
+		)
+		return int32ToInt32Out, errOut
+	}
+	return mock.GetClaimsVersionsFunc(since)
+}
+
+// GetClaimsVersionsCalls gets all the calls that were made to GetClaimsVersions.
+// Check the length with:
+//
+//	len(mockedPermissionStore.GetClaimsVersionsCalls())
+func (mock *PermissionStoreMock) GetClaimsVersionsCalls() []struct {
+	Since time.Time
+} {
+	var calls []struct {
+		Since time.Time
+	}
+	mock.lockGetClaimsVersions.RLock()
+	calls = mock.calls.GetClaimsVersions
+	mock.lockGetClaimsVersions.RUnlock()
+	return calls
+}
+
+// Ensure, that PlanningStoreMock does implement data.PlanningStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.PlanningStore = &PlanningStoreMock{}
+
+// PlanningStoreMock is a mock implementation of data.PlanningStore.
+//
+//	func TestSomethingThatUsesPlanningStore(t *testing.T) {
+//
+//		// make and configure a mocked data.PlanningStore
+//		mockedPlanningStore := &PlanningStoreMock{
+//			AvailableHoursFunc: func(userID int32, week time.Time, weeklyHours float64) (float64, error) {
+//				panic("mock out the AvailableHours method")
+//			},
+//			GetCapacityFunc: func(actor data.Actor, teamID int32, from time.Time, to time.Time, weeklyHours float64) ([]*data.UserCapacity, error) {
+//				panic("mock out the GetCapacity method")
+//			},
+//		}
+//
+//		// use mockedPlanningStore in code that requires data.PlanningStore
+//		// and then make assertions.
+//
+//	}
+type PlanningStoreMock struct {
+	// AvailableHoursFunc mocks the AvailableHours method.
+	AvailableHoursFunc func(userID int32, week time.Time, weeklyHours float64) (float64, error)
+
+	// GetCapacityFunc mocks the GetCapacity method.
+	GetCapacityFunc func(actor data.Actor, teamID int32, from time.Time, to time.Time, weeklyHours float64) ([]*data.UserCapacity, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AvailableHours holds details about calls to the AvailableHours method.
+		AvailableHours []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// Week is the week argument value.
+			Week time.Time
+			// WeeklyHours is the weeklyHours argument value.
+			WeeklyHours float64
+		}
+		// GetCapacity holds details about calls to the GetCapacity method.
+		GetCapacity []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// TeamID is the teamID argument value.
+			TeamID int32
+			// From is the from argument value.
+			From time.Time
+			// To is the to argument value.
+			To time.Time
+			// WeeklyHours is the weeklyHours argument value.
+			WeeklyHours float64
+		}
+	}
+	lockAvailableHours sync.RWMutex
+	lockGetCapacity    sync.RWMutex
+}
+
+// AvailableHours calls AvailableHoursFunc.
+func (mock *PlanningStoreMock) AvailableHours(userID int32, week time.Time, weeklyHours float64) (float64, error) {
+	callInfo := struct {
+		UserID      int32
+		Week        time.Time
+		WeeklyHours float64
+	}{
+		UserID:      userID,
+		Week:        week,
+		WeeklyHours: weeklyHours,
+	}
+	mock.lockAvailableHours.Lock()
+	mock.calls.AvailableHours = append(mock.calls.AvailableHours, callInfo)
+	mock.lockAvailableHours.Unlock()
+	if mock.AvailableHoursFunc == nil {
+		var (
+			fOut   float64
+			errOut error
+		)
+		return fOut, errOut
+	}
+	return mock.AvailableHoursFunc(userID, week, weeklyHours)
+}
+
+// AvailableHoursCalls gets all the calls that were made to AvailableHours.
+// Check the length with:
+//
+//	len(mockedPlanningStore.AvailableHoursCalls())
+func (mock *PlanningStoreMock) AvailableHoursCalls() []struct {
+	UserID      int32
+	Week        time.Time
+	WeeklyHours float64
+} {
+	var calls []struct {
+		UserID      int32
+		Week        time.Time
+		WeeklyHours float64
+	}
+	mock.lockAvailableHours.RLock()
+	calls = mock.calls.AvailableHours
+	mock.lockAvailableHours.RUnlock()
+	return calls
+}
+
+// GetCapacity calls GetCapacityFunc.
+func (mock *PlanningStoreMock) GetCapacity(actor data.Actor, teamID int32, from time.Time, to time.Time, weeklyHours float64) ([]*data.UserCapacity, error) {
+	callInfo := struct {
+		Actor       data.Actor
+		TeamID      int32
+		From        time.Time
+		To          time.Time
+		WeeklyHours float64
+	}{
+		Actor:       actor,
+		TeamID:      teamID,
+		From:        from,
+		To:          to,
+		WeeklyHours: weeklyHours,
+	}
+	mock.lockGetCapacity.Lock()
+	mock.calls.GetCapacity = append(mock.calls.GetCapacity, callInfo)
+	mock.lockGetCapacity.Unlock()
+	if mock.GetCapacityFunc == nil {
+		var (
+			userCapacitysOut []*data.UserCapacity
+			errOut           error
+		)
+		return userCapacitysOut, errOut
+	}
+	return mock.GetCapacityFunc(actor, teamID, from, to, weeklyHours)
+}
+
+// GetCapacityCalls gets all the calls that were made to GetCapacity.
+// Check the length with:
+//
+//	len(mockedPlanningStore.GetCapacityCalls())
+func (mock *PlanningStoreMock) GetCapacityCalls() []struct {
+	Actor       data.Actor
+	TeamID      int32
+	From        time.Time
+	To          time.Time
+	WeeklyHours float64
+} {
+	var calls []struct {
+		Actor       data.Actor
+		TeamID      int32
+		From        time.Time
+		To          time.Time
+		WeeklyHours float64
+	}
+	mock.lockGetCapacity.RLock()
+	calls = mock.calls.GetCapacity
+	mock.lockGetCapacity.RUnlock()
+	return calls
+}
+
+// Ensure, that ProjectStoreMock does implement data.ProjectStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ProjectStore = &ProjectStoreMock{}
+
+// ProjectStoreMock is a mock implementation of data.ProjectStore.
+//
+//	func TestSomethingThatUsesProjectStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ProjectStore
+//		mockedProjectStore := &ProjectStoreMock{
+//			ArchiveFunc: func(project *data.ProjectResponse) error {
+//				panic("mock out the Archive method")
+//			},
+//			CountDependentsFunc: func(internalID int32) (map[string]int, error) {
+//				panic("mock out the CountDependents method")
+//			},
+//			DeleteFunc: func(InternalID int32, bucket string, prefix string, client *s3.Client, objects []types.ObjectIdentifier) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, externalID int32) (*data.ProjectResponse, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, qs data.ProjectQsInput, bbox data.BoundingBox) ([]*data.ProjectResponse, data.Metadata, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			GetAllDeletedBeforeFunc: func(cutoff time.Time) ([]int32, error) {
+//				panic("mock out the GetAllDeletedBefore method")
+//			},
+//			GetAllExternalIDsFunc: func() ([]int32, error) {
+//				panic("mock out the GetAllExternalIDs method")
+//			},
+//			GetAllSummariesFunc: func(actor data.Actor, qs data.ProjectQsInput) ([]*data.ProjectSummary, data.Metadata, error) {
+//				panic("mock out the GetAllSummaries method")
+//			},
+//			GetByIDsFunc: func(actor data.Actor, externalIDs []int32) ([]*data.ProjectResponse, error) {
+//				panic("mock out the GetByIDs method")
+//			},
+//			GetByProposalIDFunc: func(actor data.Actor, proposalID string) (*data.ProjectResponse, error) {
+//				panic("mock out the GetByProposalID method")
+//			},
+//			GetExistingKeysFunc: func(externalIDs []int32, proposalIDs []string) (map[int32]bool, map[string]bool, error) {
+//				panic("mock out the GetExistingKeys method")
+//			},
+//			GetStorageBytesFunc: func(externalID int32) (int64, error) {
+//				panic("mock out the GetStorageBytes method")
+//			},
+//			ImportFunc: func(orgID int32, projects []*data.ProjectRequest, newClients []string) error {
+//				panic("mock out the Import method")
+//			},
+//			InsertFunc: func(project *data.ProjectRequest) error {
+//				panic("mock out the Insert method")
+//			},
+//			PurgeFunc: func(externalID int32) error {
+//				panic("mock out the Purge method")
+//			},
+//			ReserveExternalIDFunc: func(format *codefmt.Format, userID *int32, expiresAt time.Time) (int32, error) {
+//				panic("mock out the ReserveExternalID method")
+//			},
+//			ResolveRefsFunc: func(actor data.Actor, refs []string) (map[string]int32, error) {
+//				panic("mock out the ResolveRefs method")
+//			},
+//			SetStorageBytesFunc: func(externalID int32, storageBytes int64) error {
+//				panic("mock out the SetStorageBytes method")
+//			},
+//			UndeleteFunc: func(externalID int32) (time.Time, error) {
+//				panic("mock out the Undelete method")
+//			},
+//			UpdateFunc: func(project *data.ProjectRequest) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedProjectStore in code that requires data.ProjectStore
+//		// and then make assertions.
+//
+//	}
+type ProjectStoreMock struct {
+	// ArchiveFunc mocks the Archive method.
+	ArchiveFunc func(project *data.ProjectResponse) error
+
+	// CountDependentsFunc mocks the CountDependents method.
+	CountDependentsFunc func(internalID int32) (map[string]int, error)
+
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(InternalID int32, bucket string, prefix string, client *s3.Client, objects []types.ObjectIdentifier) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, externalID int32) (*data.ProjectResponse, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, qs data.ProjectQsInput, bbox data.BoundingBox) ([]*data.ProjectResponse, data.Metadata, error)
+
+	// GetAllDeletedBeforeFunc mocks the GetAllDeletedBefore method.
+	GetAllDeletedBeforeFunc func(cutoff time.Time) ([]int32, error)
+
+	// GetAllExternalIDsFunc mocks the GetAllExternalIDs method.
+	GetAllExternalIDsFunc func() ([]int32, error)
+
+	// GetAllSummariesFunc mocks the GetAllSummaries method.
+	GetAllSummariesFunc func(actor data.Actor, qs data.ProjectQsInput) ([]*data.ProjectSummary, data.Metadata, error)
+
+	// GetByIDsFunc mocks the GetByIDs method.
+	GetByIDsFunc func(actor data.Actor, externalIDs []int32) ([]*data.ProjectResponse, error)
+
+	// GetByProposalIDFunc mocks the GetByProposalID method.
+	GetByProposalIDFunc func(actor data.Actor, proposalID string) (*data.ProjectResponse, error)
+
+	// GetExistingKeysFunc mocks the GetExistingKeys method.
+	GetExistingKeysFunc func(externalIDs []int32, proposalIDs []string) (map[int32]bool, map[string]bool, error)
+
+	// GetStorageBytesFunc mocks the GetStorageBytes method.
+	GetStorageBytesFunc func(externalID int32) (int64, error)
+
+	// ImportFunc mocks the Import method.
+	ImportFunc func(orgID int32, projects []*data.ProjectRequest, newClients []string) error
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(project *data.ProjectRequest) error
+
+	// PurgeFunc mocks the Purge method.
+	PurgeFunc func(externalID int32) error
+
+	// ReserveExternalIDFunc mocks the ReserveExternalID method.
+	ReserveExternalIDFunc func(format *codefmt.Format, userID *int32, expiresAt time.Time) (int32, error)
+
+	// ResolveRefsFunc mocks the ResolveRefs method.
+	ResolveRefsFunc func(actor data.Actor, refs []string) (map[string]int32, error)
+
+	// SetStorageBytesFunc mocks the SetStorageBytes method.
+	SetStorageBytesFunc func(externalID int32, storageBytes int64) error
+
+	// UndeleteFunc mocks the Undelete method.
+	UndeleteFunc func(externalID int32) (time.Time, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(project *data.ProjectRequest) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Archive holds details about calls to the Archive method.
+		Archive []struct {
+			// Project is the project argument value.
+			Project *data.ProjectResponse
+		}
+		// CountDependents holds details about calls to the CountDependents method.
+		CountDependents []struct {
+			// InternalID is the internalID argument value.
+			InternalID int32
+		}
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// InternalID is the InternalID argument value.
+			InternalID int32
+			// Bucket is the bucket argument value.
+			Bucket string
+			// Prefix is the prefix argument value.
+			Prefix string
+			// Client is the client argument value.
+			Client *s3.Client
+			// Objects is the objects argument value.
+			Objects []types.ObjectIdentifier
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Qs is the qs argument value.
+			Qs data.ProjectQsInput
+			// Bbox is the bbox argument value.
+			Bbox data.BoundingBox
+		}
+		// GetAllDeletedBefore holds details about calls to the GetAllDeletedBefore method.
+		GetAllDeletedBefore []struct {
+			// Cutoff is the cutoff argument value.
+			Cutoff time.Time
+		}
+		// GetAllExternalIDs holds details about calls to the GetAllExternalIDs method.
+		GetAllExternalIDs []struct {
+		}
+		// GetAllSummaries holds details about calls to the GetAllSummaries method.
+		GetAllSummaries []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Qs is the qs argument value.
+			Qs data.ProjectQsInput
+		}
+		// GetByIDs holds details about calls to the GetByIDs method.
+		GetByIDs []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ExternalIDs is the externalIDs argument value.
+			ExternalIDs []int32
+		}
+		// GetByProposalID holds details about calls to the GetByProposalID method.
+		GetByProposalID []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ProposalID is the proposalID argument value.
+			ProposalID string
+		}
+		// GetExistingKeys holds details about calls to the GetExistingKeys method.
+		GetExistingKeys []struct {
+			// ExternalIDs is the externalIDs argument value.
+			ExternalIDs []int32
+			// ProposalIDs is the proposalIDs argument value.
+			ProposalIDs []string
+		}
+		// GetStorageBytes holds details about calls to the GetStorageBytes method.
+		GetStorageBytes []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// Import holds details about calls to the Import method.
+		Import []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// Projects is the projects argument value.
+			Projects []*data.ProjectRequest
+			// NewClients is the newClients argument value.
+			NewClients []string
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Project is the project argument value.
+			Project *data.ProjectRequest
+		}
+		// Purge holds details about calls to the Purge method.
+		Purge []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// ReserveExternalID holds details about calls to the ReserveExternalID method.
+		ReserveExternalID []struct {
+			// Format is the format argument value.
+			Format *codefmt.Format
+			// UserID is the userID argument value.
+			UserID *int32
+			// ExpiresAt is the expiresAt argument value.
+			ExpiresAt time.Time
+		}
+		// ResolveRefs holds details about calls to the ResolveRefs method.
+		ResolveRefs []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Refs is the refs argument value.
+			Refs []string
+		}
+		// SetStorageBytes holds details about calls to the SetStorageBytes method.
+		SetStorageBytes []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+			// StorageBytes is the storageBytes argument value.
+			StorageBytes int64
+		}
+		// Undelete holds details about calls to the Undelete method.
+		Undelete []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Project is the project argument value.
+			Project *data.ProjectRequest
+		}
+	}
+	lockArchive             sync.RWMutex
+	lockCountDependents     sync.RWMutex
+	lockDelete              sync.RWMutex
+	lockGet                 sync.RWMutex
+	lockGetAll              sync.RWMutex
+	lockGetAllDeletedBefore sync.RWMutex
+	lockGetAllExternalIDs   sync.RWMutex
+	lockGetAllSummaries     sync.RWMutex
+	lockGetByIDs            sync.RWMutex
+	lockGetByProposalID     sync.RWMutex
+	lockGetExistingKeys     sync.RWMutex
+	lockGetStorageBytes     sync.RWMutex
+	lockImport              sync.RWMutex
+	lockInsert              sync.RWMutex
+	lockPurge               sync.RWMutex
+	lockReserveExternalID   sync.RWMutex
+	lockResolveRefs         sync.RWMutex
+	lockSetStorageBytes     sync.RWMutex
+	lockUndelete            sync.RWMutex
+	lockUpdate              sync.RWMutex
+}
+
+// Archive calls ArchiveFunc.
+func (mock *ProjectStoreMock) Archive(project *data.ProjectResponse) error {
+	callInfo := struct {
+		Project *data.ProjectResponse
+	}{
+		Project: project,
+	}
+	mock.lockArchive.Lock()
+	mock.calls.Archive = append(mock.calls.Archive, callInfo)
+	mock.lockArchive.Unlock()
+	if mock.ArchiveFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.ArchiveFunc(project)
+}
+
+// ArchiveCalls gets all the calls that were made to Archive.
+// Check the length with:
+//
+//	len(mockedProjectStore.ArchiveCalls())
+func (mock *ProjectStoreMock) ArchiveCalls() []struct {
+	Project *data.ProjectResponse
+} {
+	var calls []struct {
+		Project *data.ProjectResponse
+	}
+	mock.lockArchive.RLock()
+	calls = mock.calls.Archive
+	mock.lockArchive.RUnlock()
+	return calls
+}
+
+// CountDependents calls CountDependentsFunc.
+func (mock *ProjectStoreMock) CountDependents(internalID int32) (map[string]int, error) {
+	callInfo := struct {
+		InternalID int32
+	}{
+		InternalID: internalID,
+	}
+	mock.lockCountDependents.Lock()
+	mock.calls.CountDependents = append(mock.calls.CountDependents, callInfo)
+	mock.lockCountDependents.Unlock()
+	if mock.CountDependentsFunc == nil {
+		var (
+			stringToIntOut map[string]int
+			errOut         error
+		)
+		return stringToIntOut, errOut
+	}
+	return mock.CountDependentsFunc(internalID)
+}
+
+// CountDependentsCalls gets all the calls that were made to CountDependents.
+// Check the length with:
+//
+//	len(mockedProjectStore.CountDependentsCalls())
+func (mock *ProjectStoreMock) CountDependentsCalls() []struct {
+	InternalID int32
+} {
+	var calls []struct {
+		InternalID int32
+	}
+	mock.lockCountDependents.RLock()
+	calls = mock.calls.CountDependents
+	mock.lockCountDependents.RUnlock()
+	return calls
+}
+
+// Delete calls DeleteFunc.
+func (mock *ProjectStoreMock) Delete(InternalID int32, bucket string, prefix string, client *s3.Client, objects []types.ObjectIdentifier) error {
+	callInfo := struct {
+		InternalID int32
+		Bucket     string
+		Prefix     string
+		Client     *s3.Client
+		Objects    []types.ObjectIdentifier
+	}{
+		InternalID: InternalID,
+		Bucket:     bucket,
+		Prefix:     prefix,
+		Client:     client,
+		Objects:    objects,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(InternalID, bucket, prefix, client, objects)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedProjectStore.DeleteCalls())
+func (mock *ProjectStoreMock) DeleteCalls() []struct {
+	InternalID int32
+	Bucket     string
+	Prefix     string
+	Client     *s3.Client
+	Objects    []types.ObjectIdentifier
+} {
+	var calls []struct {
+		InternalID int32
+		Bucket     string
+		Prefix     string
+		Client     *s3.Client
+		Objects    []types.ObjectIdentifier
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *ProjectStoreMock) Get(actor data.Actor, externalID int32) (*data.ProjectResponse, error) {
+	callInfo := struct {
+		Actor      data.Actor
+		ExternalID int32
+	}{
+		Actor:      actor,
+		ExternalID: externalID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			projectResponseOut *data.ProjectResponse
+			errOut             error
+		)
+		return projectResponseOut, errOut
+	}
+	return mock.GetFunc(actor, externalID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetCalls())
+func (mock *ProjectStoreMock) GetCalls() []struct {
+	Actor      data.Actor
+	ExternalID int32
+} {
+	var calls []struct {
+		Actor      data.Actor
+		ExternalID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *ProjectStoreMock) GetAll(actor data.Actor, qs data.ProjectQsInput, bbox data.BoundingBox) ([]*data.ProjectResponse, data.Metadata, error) {
+	callInfo := struct {
+		Actor data.Actor
+		Qs    data.ProjectQsInput
+		Bbox  data.BoundingBox
+	}{
+		Actor: actor,
+		Qs:    qs,
+		Bbox:  bbox,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			projectResponsesOut []*data.ProjectResponse
+			metadataOut         data.Metadata
+			errOut              error
+		)
+		return projectResponsesOut, metadataOut, errOut
+	}
+	return mock.GetAllFunc(actor, qs, bbox)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetAllCalls())
+func (mock *ProjectStoreMock) GetAllCalls() []struct {
+	Actor data.Actor
+	Qs    data.ProjectQsInput
+	Bbox  data.BoundingBox
+} {
+	var calls []struct {
+		Actor data.Actor
+		Qs    data.ProjectQsInput
+		Bbox  data.BoundingBox
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetAllDeletedBefore calls GetAllDeletedBeforeFunc.
+func (mock *ProjectStoreMock) GetAllDeletedBefore(cutoff time.Time) ([]int32, error) {
+	callInfo := struct {
+		Cutoff time.Time
+	}{
+		Cutoff: cutoff,
+	}
+	mock.lockGetAllDeletedBefore.Lock()
+	mock.calls.GetAllDeletedBefore = append(mock.calls.GetAllDeletedBefore, callInfo)
+	mock.lockGetAllDeletedBefore.Unlock()
+	if mock.GetAllDeletedBeforeFunc == nil {
+		var (
+			int32sOut []int32
+			errOut    error
+		)
+		return int32sOut, errOut
+	}
+	return mock.GetAllDeletedBeforeFunc(cutoff)
+}
+
+// GetAllDeletedBeforeCalls gets all the calls that were made to GetAllDeletedBefore.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetAllDeletedBeforeCalls())
+func (mock *ProjectStoreMock) GetAllDeletedBeforeCalls() []struct {
+	Cutoff time.Time
+} {
+	var calls []struct {
+		Cutoff time.Time
+	}
+	mock.lockGetAllDeletedBefore.RLock()
+	calls = mock.calls.GetAllDeletedBefore
+	mock.lockGetAllDeletedBefore.RUnlock()
+	return calls
+}
+
+// GetAllExternalIDs calls GetAllExternalIDsFunc.
+func (mock *ProjectStoreMock) GetAllExternalIDs() ([]int32, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetAllExternalIDs.Lock()
+	mock.calls.GetAllExternalIDs = append(mock.calls.GetAllExternalIDs, callInfo)
+	mock.lockGetAllExternalIDs.Unlock()
+	if mock.GetAllExternalIDsFunc == nil {
+		var (
+			int32sOut []int32
+			errOut    error
+		)
+		return int32sOut, errOut
+	}
+	return mock.GetAllExternalIDsFunc()
+}
+
+// GetAllExternalIDsCalls gets all the calls that were made to GetAllExternalIDs.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetAllExternalIDsCalls())
+func (mock *ProjectStoreMock) GetAllExternalIDsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetAllExternalIDs.RLock()
+	calls = mock.calls.GetAllExternalIDs
+	mock.lockGetAllExternalIDs.RUnlock()
+	return calls
+}
+
+// GetAllSummaries calls GetAllSummariesFunc.
+func (mock *ProjectStoreMock) GetAllSummaries(actor data.Actor, qs data.ProjectQsInput) ([]*data.ProjectSummary, data.Metadata, error) {
+	callInfo := struct {
+		Actor data.Actor
+		Qs    data.ProjectQsInput
+	}{
+		Actor: actor,
+		Qs:    qs,
+	}
+	mock.lockGetAllSummaries.Lock()
+	mock.calls.GetAllSummaries = append(mock.calls.GetAllSummaries, callInfo)
+	mock.lockGetAllSummaries.Unlock()
+	if mock.GetAllSummariesFunc == nil {
+		var (
+			projectSummarysOut []*data.ProjectSummary
+			metadataOut        data.Metadata
+			errOut             error
+		)
+		return projectSummarysOut, metadataOut, errOut
+	}
+	return mock.GetAllSummariesFunc(actor, qs)
+}
+
+// GetAllSummariesCalls gets all the calls that were made to GetAllSummaries.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetAllSummariesCalls())
+func (mock *ProjectStoreMock) GetAllSummariesCalls() []struct {
+	Actor data.Actor
+	Qs    data.ProjectQsInput
+} {
+	var calls []struct {
+		Actor data.Actor
+		Qs    data.ProjectQsInput
+	}
+	mock.lockGetAllSummaries.RLock()
+	calls = mock.calls.GetAllSummaries
+	mock.lockGetAllSummaries.RUnlock()
+	return calls
+}
+
+// GetByIDs calls GetByIDsFunc.
+func (mock *ProjectStoreMock) GetByIDs(actor data.Actor, externalIDs []int32) ([]*data.ProjectResponse, error) {
+	callInfo := struct {
+		Actor       data.Actor
+		ExternalIDs []int32
+	}{
+		Actor:       actor,
+		ExternalIDs: externalIDs,
+	}
+	mock.lockGetByIDs.Lock()
+	mock.calls.GetByIDs = append(mock.calls.GetByIDs, callInfo)
+	mock.lockGetByIDs.Unlock()
+	if mock.GetByIDsFunc == nil {
+		var (
+			projectResponsesOut []*data.ProjectResponse
+			errOut              error
+		)
+		return projectResponsesOut, errOut
+	}
+	return mock.GetByIDsFunc(actor, externalIDs)
+}
+
+// GetByIDsCalls gets all the calls that were made to GetByIDs.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetByIDsCalls())
+func (mock *ProjectStoreMock) GetByIDsCalls() []struct {
+	Actor       data.Actor
+	ExternalIDs []int32
+} {
+	var calls []struct {
+		Actor       data.Actor
+		ExternalIDs []int32
+	}
+	mock.lockGetByIDs.RLock()
+	calls = mock.calls.GetByIDs
+	mock.lockGetByIDs.RUnlock()
+	return calls
+}
+
+// GetByProposalID calls GetByProposalIDFunc.
+func (mock *ProjectStoreMock) GetByProposalID(actor data.Actor, proposalID string) (*data.ProjectResponse, error) {
+	callInfo := struct {
+		Actor      data.Actor
+		ProposalID string
+	}{
+		Actor:      actor,
+		ProposalID: proposalID,
+	}
+	mock.lockGetByProposalID.Lock()
+	mock.calls.GetByProposalID = append(mock.calls.GetByProposalID, callInfo)
+	mock.lockGetByProposalID.Unlock()
+	if mock.GetByProposalIDFunc == nil {
+		var (
+			projectResponseOut *data.ProjectResponse
+			errOut             error
+		)
+		return projectResponseOut, errOut
+	}
+	return mock.GetByProposalIDFunc(actor, proposalID)
+}
+
+// GetByProposalIDCalls gets all the calls that were made to GetByProposalID.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetByProposalIDCalls())
+func (mock *ProjectStoreMock) GetByProposalIDCalls() []struct {
+	Actor      data.Actor
+	ProposalID string
+} {
+	var calls []struct {
+		Actor      data.Actor
+		ProposalID string
+	}
+	mock.lockGetByProposalID.RLock()
+	calls = mock.calls.GetByProposalID
+	mock.lockGetByProposalID.RUnlock()
+	return calls
+}
+
+// GetExistingKeys calls GetExistingKeysFunc.
+func (mock *ProjectStoreMock) GetExistingKeys(externalIDs []int32, proposalIDs []string) (map[int32]bool, map[string]bool, error) {
+	callInfo := struct {
+		ExternalIDs []int32
+		ProposalIDs []string
+	}{
+		ExternalIDs: externalIDs,
+		ProposalIDs: proposalIDs,
+	}
+	mock.lockGetExistingKeys.Lock()
+	mock.calls.GetExistingKeys = append(mock.calls.GetExistingKeys, callInfo)
+	mock.lockGetExistingKeys.Unlock()
+	if mock.GetExistingKeysFunc == nil {
+		var (
+			int32ToBoolOut  map[int32]bool
+			stringToBoolOut map[string]bool
+			errOut          error
+		)
+		return int32ToBoolOut, stringToBoolOut, errOut
+	}
+	return mock.GetExistingKeysFunc(externalIDs, proposalIDs)
+}
+
+// GetExistingKeysCalls gets all the calls that were made to GetExistingKeys.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetExistingKeysCalls())
+func (mock *ProjectStoreMock) GetExistingKeysCalls() []struct {
+	ExternalIDs []int32
+	ProposalIDs []string
+} {
+	var calls []struct {
+		ExternalIDs []int32
+		ProposalIDs []string
+	}
+	mock.lockGetExistingKeys.RLock()
+	calls = mock.calls.GetExistingKeys
+	mock.lockGetExistingKeys.RUnlock()
+	return calls
+}
+
+// GetStorageBytes calls GetStorageBytesFunc.
+func (mock *ProjectStoreMock) GetStorageBytes(externalID int32) (int64, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetStorageBytes.Lock()
+	mock.calls.GetStorageBytes = append(mock.calls.GetStorageBytes, callInfo)
+	mock.lockGetStorageBytes.Unlock()
+	if mock.GetStorageBytesFunc == nil {
+		var (
+			nOut   int64
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.GetStorageBytesFunc(externalID)
+}
+
+// GetStorageBytesCalls gets all the calls that were made to GetStorageBytes.
+// Check the length with:
+//
+//	len(mockedProjectStore.GetStorageBytesCalls())
+func (mock *ProjectStoreMock) GetStorageBytesCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetStorageBytes.RLock()
+	calls = mock.calls.GetStorageBytes
+	mock.lockGetStorageBytes.RUnlock()
+	return calls
+}
+
+// Import calls ImportFunc.
+func (mock *ProjectStoreMock) Import(orgID int32, projects []*data.ProjectRequest, newClients []string) error {
+	callInfo := struct {
+		OrgID      int32
+		Projects   []*data.ProjectRequest
+		NewClients []string
+	}{
+		OrgID:      orgID,
+		Projects:   projects,
+		NewClients: newClients,
+	}
+	mock.lockImport.Lock()
+	mock.calls.Import = append(mock.calls.Import, callInfo)
+	mock.lockImport.Unlock()
+	if mock.ImportFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.ImportFunc(orgID, projects, newClients)
+}
+
+// ImportCalls gets all the calls that were made to Import.
+// Check the length with:
+//
+//	len(mockedProjectStore.ImportCalls())
+func (mock *ProjectStoreMock) ImportCalls() []struct {
+	OrgID      int32
+	Projects   []*data.ProjectRequest
+	NewClients []string
+} {
+	var calls []struct {
+		OrgID      int32
+		Projects   []*data.ProjectRequest
+		NewClients []string
+	}
+	mock.lockImport.RLock()
+	calls = mock.calls.Import
+	mock.lockImport.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *ProjectStoreMock) Insert(project *data.ProjectRequest) error {
+	callInfo := struct {
+		Project *data.ProjectRequest
+	}{
+		Project: project,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(project)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedProjectStore.InsertCalls())
+func (mock *ProjectStoreMock) InsertCalls() []struct {
+	Project *data.ProjectRequest
+} {
+	var calls []struct {
+		Project *data.ProjectRequest
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Purge calls PurgeFunc.
+func (mock *ProjectStoreMock) Purge(externalID int32) error {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockPurge.Lock()
+	mock.calls.Purge = append(mock.calls.Purge, callInfo)
+	mock.lockPurge.Unlock()
+	if mock.PurgeFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.PurgeFunc(externalID)
+}
+
+// PurgeCalls gets all the calls that were made to Purge.
+// Check the length with:
+//
+//	len(mockedProjectStore.PurgeCalls())
+func (mock *ProjectStoreMock) PurgeCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockPurge.RLock()
+	calls = mock.calls.Purge
+	mock.lockPurge.RUnlock()
+	return calls
+}
+
+// ReserveExternalID calls ReserveExternalIDFunc.
+func (mock *ProjectStoreMock) ReserveExternalID(format *codefmt.Format, userID *int32, expiresAt time.Time) (int32, error) {
+	callInfo := struct {
+		Format    *codefmt.Format
+		UserID    *int32
+		ExpiresAt time.Time
+	}{
+		Format:    format,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+	mock.lockReserveExternalID.Lock()
+	mock.calls.ReserveExternalID = append(mock.calls.ReserveExternalID, callInfo)
+	mock.lockReserveExternalID.Unlock()
+	if mock.ReserveExternalIDFunc == nil {
+		var (
+			nOut   int32
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.ReserveExternalIDFunc(format, userID, expiresAt)
+}
+
+// ReserveExternalIDCalls gets all the calls that were made to ReserveExternalID.
+// Check the length with:
+//
+//	len(mockedProjectStore.ReserveExternalIDCalls())
+func (mock *ProjectStoreMock) ReserveExternalIDCalls() []struct {
+	Format    *codefmt.Format
+	UserID    *int32
+	ExpiresAt time.Time
+} {
+	var calls []struct {
+		Format    *codefmt.Format
+		UserID    *int32
+		ExpiresAt time.Time
+	}
+	mock.lockReserveExternalID.RLock()
+	calls = mock.calls.ReserveExternalID
+	mock.lockReserveExternalID.RUnlock()
+	return calls
+}
+
+// ResolveRefs calls ResolveRefsFunc.
+func (mock *ProjectStoreMock) ResolveRefs(actor data.Actor, refs []string) (map[string]int32, error) {
+	callInfo := struct {
+		Actor data.Actor
+		Refs  []string
+	}{
+		Actor: actor,
+		Refs:  refs,
+	}
+	mock.lockResolveRefs.Lock()
+	mock.calls.ResolveRefs = append(mock.calls.ResolveRefs, callInfo)
+	mock.lockResolveRefs.Unlock()
+	if mock.ResolveRefsFunc == nil {
+		var (
+			stringToInt32Out map[string]int32
+			errOut           error
+		)
+		return stringToInt32Out, errOut
+	}
+	return mock.ResolveRefsFunc(actor, refs)
+}
+
+// ResolveRefsCalls gets all the calls that were made to ResolveRefs.
+// Check the length with:
+//
+//	len(mockedProjectStore.ResolveRefsCalls())
+func (mock *ProjectStoreMock) ResolveRefsCalls() []struct {
+	Actor data.Actor
+	Refs  []string
+} {
+	var calls []struct {
+		Actor data.Actor
+		Refs  []string
+	}
+	mock.lockResolveRefs.RLock()
+	calls = mock.calls.ResolveRefs
+	mock.lockResolveRefs.RUnlock()
+	return calls
+}
+
+// SetStorageBytes calls SetStorageBytesFunc.
+func (mock *ProjectStoreMock) SetStorageBytes(externalID int32, storageBytes int64) error {
+	callInfo := struct {
+		ExternalID   int32
+		StorageBytes int64
+	}{
+		ExternalID:   externalID,
+		StorageBytes: storageBytes,
+	}
+	mock.lockSetStorageBytes.Lock()
+	mock.calls.SetStorageBytes = append(mock.calls.SetStorageBytes, callInfo)
+	mock.lockSetStorageBytes.Unlock()
+	if mock.SetStorageBytesFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetStorageBytesFunc(externalID, storageBytes)
+}
+
+// SetStorageBytesCalls gets all the calls that were made to SetStorageBytes.
+// Check the length with:
+//
+//	len(mockedProjectStore.SetStorageBytesCalls())
+func (mock *ProjectStoreMock) SetStorageBytesCalls() []struct {
+	ExternalID   int32
+	StorageBytes int64
+} {
+	var calls []struct {
+		ExternalID   int32
+		StorageBytes int64
+	}
+	mock.lockSetStorageBytes.RLock()
+	calls = mock.calls.SetStorageBytes
+	mock.lockSetStorageBytes.RUnlock()
+	return calls
+}
+
+// Undelete calls UndeleteFunc.
+func (mock *ProjectStoreMock) Undelete(externalID int32) (time.Time, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockUndelete.Lock()
+	mock.calls.Undelete = append(mock.calls.Undelete, callInfo)
+	mock.lockUndelete.Unlock()
+	if mock.UndeleteFunc == nil {
+		var (
+			timeOut time.Time
+			errOut  error
+		)
+		return timeOut, errOut
+	}
+	return mock.UndeleteFunc(externalID)
+}
+
+// UndeleteCalls gets all the calls that were made to Undelete.
+// Check the length with:
+//
+//	len(mockedProjectStore.UndeleteCalls())
+func (mock *ProjectStoreMock) UndeleteCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockUndelete.RLock()
+	calls = mock.calls.Undelete
+	mock.lockUndelete.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *ProjectStoreMock) Update(project *data.ProjectRequest) error {
+	callInfo := struct {
+		Project *data.ProjectRequest
+	}{
+		Project: project,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(project)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedProjectStore.UpdateCalls())
+func (mock *ProjectStoreMock) UpdateCalls() []struct {
+	Project *data.ProjectRequest
+} {
+	var calls []struct {
+		Project *data.ProjectRequest
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that ProposalStoreMock does implement data.ProposalStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ProposalStore = &ProposalStoreMock{}
+
+// ProposalStoreMock is a mock implementation of data.ProposalStore.
+//
+//	func TestSomethingThatUsesProposalStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ProposalStore
+//		mockedProposalStore := &ProposalStoreMock{
+//			DeleteFunc: func(actor data.Actor, externalID string) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, externalID string) (*data.Proposal, error) {
+//				panic("mock out the Get method")
+//			},
+//			InsertFunc: func(proposal *data.Proposal) error {
+//				panic("mock out the Insert method")
+//			},
+//			UpdateFunc: func(proposal *data.Proposal) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedProposalStore in code that requires data.ProposalStore
+//		// and then make assertions.
+//
+//	}
+type ProposalStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(actor data.Actor, externalID string) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, externalID string) (*data.Proposal, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(proposal *data.Proposal) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(proposal *data.Proposal) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ExternalID is the externalID argument value.
+			ExternalID string
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ExternalID is the externalID argument value.
+			ExternalID string
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Proposal is the proposal argument value.
+			Proposal *data.Proposal
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Proposal is the proposal argument value.
+			Proposal *data.Proposal
+		}
+	}
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockInsert sync.RWMutex
+	lockUpdate sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *ProposalStoreMock) Delete(actor data.Actor, externalID string) error {
+	callInfo := struct {
+		Actor      data.Actor
+		ExternalID string
+	}{
+		Actor:      actor,
+		ExternalID: externalID,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(actor, externalID)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedProposalStore.DeleteCalls())
+func (mock *ProposalStoreMock) DeleteCalls() []struct {
+	Actor      data.Actor
+	ExternalID string
+} {
+	var calls []struct {
+		Actor      data.Actor
+		ExternalID string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *ProposalStoreMock) Get(actor data.Actor, externalID string) (*data.Proposal, error) {
+	callInfo := struct {
+		Actor      data.Actor
+		ExternalID string
+	}{
+		Actor:      actor,
+		ExternalID: externalID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			proposalOut *data.Proposal
+			errOut      error
+		)
+		return proposalOut, errOut
+	}
+	return mock.GetFunc(actor, externalID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedProposalStore.GetCalls())
+func (mock *ProposalStoreMock) GetCalls() []struct {
+	Actor      data.Actor
+	ExternalID string
+} {
+	var calls []struct {
+		Actor      data.Actor
+		ExternalID string
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *ProposalStoreMock) Insert(proposal *data.Proposal) error {
+	callInfo := struct {
+		Proposal *data.Proposal
+	}{
+		Proposal: proposal,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(proposal)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedProposalStore.InsertCalls())
+func (mock *ProposalStoreMock) InsertCalls() []struct {
+	Proposal *data.Proposal
+} {
+	var calls []struct {
+		Proposal *data.Proposal
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *ProposalStoreMock) Update(proposal *data.Proposal) error {
+	callInfo := struct {
+		Proposal *data.Proposal
+	}{
+		Proposal: proposal,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(proposal)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedProposalStore.UpdateCalls())
+func (mock *ProposalStoreMock) UpdateCalls() []struct {
+	Proposal *data.Proposal
+} {
+	var calls []struct {
+		Proposal *data.Proposal
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that RoleStoreMock does implement data.RoleStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.RoleStore = &RoleStoreMock{}
+
+// RoleStoreMock is a mock implementation of data.RoleStore.
+//
+//	func TestSomethingThatUsesRoleStore(t *testing.T) {
+//
+//		// make and configure a mocked data.RoleStore
+//		mockedRoleStore := &RoleStoreMock{
+//			DeleteFunc: func(name string) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(name string) (*data.Role, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func() ([]*data.Role, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			PutFunc: func(r *data.Role) (bool, error) {
+//				panic("mock out the Put method")
+//			},
+//			RecomputeFunc: func() (data.RoleSync, error) {
+//				panic("mock out the Recompute method")
+//			},
+//		}
+//
+//		// use mockedRoleStore in code that requires data.RoleStore
+//		// and then make assertions.
+//
+//	}
+type RoleStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(name string) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(name string) (*data.Role, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func() ([]*data.Role, error)
+
+	// PutFunc mocks the Put method.
+	PutFunc func(r *data.Role) (bool, error)
+
+	// RecomputeFunc mocks the Recompute method.
+	RecomputeFunc func() (data.RoleSync, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+		}
+		// Put holds details about calls to the Put method.
+		Put []struct {
+			// R is the r argument value.
+			R *data.Role
+		}
+		// Recompute holds details about calls to the Recompute method.
+		Recompute []struct {
+		}
+	}
+	lockDelete    sync.RWMutex
+	lockGet       sync.RWMutex
+	lockGetAll    sync.RWMutex
+	lockPut       sync.RWMutex
+	lockRecompute sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *RoleStoreMock) Delete(name string) error {
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(name)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedRoleStore.DeleteCalls())
+func (mock *RoleStoreMock) DeleteCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *RoleStoreMock) Get(name string) (*data.Role, error) {
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			roleOut *data.Role
+			errOut  error
+		)
+		return roleOut, errOut
+	}
+	return mock.GetFunc(name)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedRoleStore.GetCalls())
+func (mock *RoleStoreMock) GetCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *RoleStoreMock) GetAll() ([]*data.Role, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			rolesOut []*data.Role
+			errOut   error
+		)
+		return rolesOut, errOut
+	}
+	return mock.GetAllFunc()
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedRoleStore.GetAllCalls())
+func (mock *RoleStoreMock) GetAllCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Put calls PutFunc.
+func (mock *RoleStoreMock) Put(r *data.Role) (bool, error) {
+	callInfo := struct {
+		R *data.Role
+	}{
+		R: r,
+	}
+	mock.lockPut.Lock()
+	mock.calls.Put = append(mock.calls.Put, callInfo)
+	mock.lockPut.Unlock()
+	if mock.PutFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.PutFunc(r)
+}
+
+// PutCalls gets all the calls that were made to Put.
+// Check the length with:
+//
+//	len(mockedRoleStore.PutCalls())
+func (mock *RoleStoreMock) PutCalls() []struct {
+	R *data.Role
+} {
+	var calls []struct {
+		R *data.Role
+	}
+	mock.lockPut.RLock()
+	calls = mock.calls.Put
+	mock.lockPut.RUnlock()
+	return calls
+}
+
+// Recompute calls RecomputeFunc.
+func (mock *RoleStoreMock) Recompute() (data.RoleSync, error) {
+	callInfo := struct {
+	}{}
+	mock.lockRecompute.Lock()
+	mock.calls.Recompute = append(mock.calls.Recompute, callInfo)
+	mock.lockRecompute.Unlock()
+	if mock.RecomputeFunc == nil {
+		var (
+			roleSyncOut data.RoleSync
+			errOut      error
+		)
+		return roleSyncOut, errOut
+	}
+	return mock.RecomputeFunc()
+}
+
+// RecomputeCalls gets all the calls that were made to Recompute.
+// Check the length with:
+//
+//	len(mockedRoleStore.RecomputeCalls())
+func (mock *RoleStoreMock) RecomputeCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockRecompute.RLock()
+	calls = mock.calls.Recompute
+	mock.lockRecompute.RUnlock()
+	return calls
+}
+
+// Ensure, that ScheduleStoreMock does implement data.ScheduleStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.ScheduleStore = &ScheduleStoreMock{}
+
+// ScheduleStoreMock is a mock implementation of data.ScheduleStore.
+//
+//	func TestSomethingThatUsesScheduleStore(t *testing.T) {
+//
+//		// make and configure a mocked data.ScheduleStore
+//		mockedScheduleStore := &ScheduleStoreMock{
+//			ClaimFunc: func(name string, lastRunAt *time.Time, now time.Time) (bool, error) {
+//				panic("mock out the Claim method")
+//			},
+//			GetFunc: func(name string) (*data.JobSchedule, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func() ([]*data.JobSchedule, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			SeedFunc: func(schedules []*data.JobSchedule) error {
+//				panic("mock out the Seed method")
+//			},
+//			UpdateFunc: func(s *data.JobSchedule) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedScheduleStore in code that requires data.ScheduleStore
+//		// and then make assertions.
+//
+//	}
+type ScheduleStoreMock struct {
+	// ClaimFunc mocks the Claim method.
+	ClaimFunc func(name string, lastRunAt *time.Time, now time.Time) (bool, error)
+
+	// GetFunc mocks the Get method.
+	GetFunc func(name string) (*data.JobSchedule, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func() ([]*data.JobSchedule, error)
+
+	// SeedFunc mocks the Seed method.
+	SeedFunc func(schedules []*data.JobSchedule) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(s *data.JobSchedule) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Claim holds details about calls to the Claim method.
+		Claim []struct {
+			// Name is the name argument value.
+			Name string
+			// LastRunAt is the lastRunAt argument value.
+			LastRunAt *time.Time
+			// Now is the now argument value.
+			Now time.Time
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Name is the name argument value.
+			Name string
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+		}
+		// Seed holds details about calls to the Seed method.
+		Seed []struct {
+			// Schedules is the schedules argument value.
+			Schedules []*data.JobSchedule
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// S is the s argument value.
+			S *data.JobSchedule
+		}
+	}
+	lockClaim  sync.RWMutex
+	lockGet    sync.RWMutex
+	lockGetAll sync.RWMutex
+	lockSeed   sync.RWMutex
+	lockUpdate sync.RWMutex
+}
+
+// Claim calls ClaimFunc.
+func (mock *ScheduleStoreMock) Claim(name string, lastRunAt *time.Time, now time.Time) (bool, error) {
+	callInfo := struct {
+		Name      string
+		LastRunAt *time.Time
+		Now       time.Time
+	}{
+		Name:      name,
+		LastRunAt: lastRunAt,
+		Now:       now,
+	}
+	mock.lockClaim.Lock()
+	mock.calls.Claim = append(mock.calls.Claim, callInfo)
+	mock.lockClaim.Unlock()
+	if mock.ClaimFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.ClaimFunc(name, lastRunAt, now)
+}
+
+// ClaimCalls gets all the calls that were made to Claim.
+// Check the length with:
+//
+//	len(mockedScheduleStore.ClaimCalls())
+func (mock *ScheduleStoreMock) ClaimCalls() []struct {
+	Name      string
+	LastRunAt *time.Time
+	Now       time.Time
+} {
+	var calls []struct {
+		Name      string
+		LastRunAt *time.Time
+		Now       time.Time
+	}
+	mock.lockClaim.RLock()
+	calls = mock.calls.Claim
+	mock.lockClaim.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *ScheduleStoreMock) Get(name string) (*data.JobSchedule, error) {
+	callInfo := struct {
+		Name string
+	}{
+		Name: name,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			jobScheduleOut *data.JobSchedule
+			errOut         error
+		)
+		return jobScheduleOut, errOut
+	}
+	return mock.GetFunc(name)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedScheduleStore.GetCalls())
+func (mock *ScheduleStoreMock) GetCalls() []struct {
+	Name string
+} {
+	var calls []struct {
+		Name string
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *ScheduleStoreMock) GetAll() ([]*data.JobSchedule, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			jobSchedulesOut []*data.JobSchedule
+			errOut          error
+		)
+		return jobSchedulesOut, errOut
+	}
+	return mock.GetAllFunc()
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedScheduleStore.GetAllCalls())
+func (mock *ScheduleStoreMock) GetAllCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Seed calls SeedFunc.
+func (mock *ScheduleStoreMock) Seed(schedules []*data.JobSchedule) error {
+	callInfo := struct {
+		Schedules []*data.JobSchedule
+	}{
+		Schedules: schedules,
+	}
+	mock.lockSeed.Lock()
+	mock.calls.Seed = append(mock.calls.Seed, callInfo)
+	mock.lockSeed.Unlock()
+	if mock.SeedFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SeedFunc(schedules)
+}
+
+// SeedCalls gets all the calls that were made to Seed.
+// Check the length with:
+//
+//	len(mockedScheduleStore.SeedCalls())
+func (mock *ScheduleStoreMock) SeedCalls() []struct {
+	Schedules []*data.JobSchedule
+} {
+	var calls []struct {
+		Schedules []*data.JobSchedule
+	}
+	mock.lockSeed.RLock()
+	calls = mock.calls.Seed
+	mock.lockSeed.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *ScheduleStoreMock) Update(s *data.JobSchedule) error {
+	callInfo := struct {
+		S *data.JobSchedule
+	}{
+		S: s,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(s)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedScheduleStore.UpdateCalls())
+func (mock *ScheduleStoreMock) UpdateCalls() []struct {
+	S *data.JobSchedule
+} {
+	var calls []struct {
+		S *data.JobSchedule
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that SecurityEventStoreMock does implement data.SecurityEventStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.SecurityEventStore = &SecurityEventStoreMock{}
+
+// SecurityEventStoreMock is a mock implementation of data.SecurityEventStore.
+//
+//	func TestSomethingThatUsesSecurityEventStore(t *testing.T) {
+//
+//		// make and configure a mocked data.SecurityEventStore
+//		mockedSecurityEventStore := &SecurityEventStoreMock{
+//			GetAllForUserFunc: func(userID int32, event string, filters data.Filters) ([]*data.SecurityEvent, data.Metadata, error) {
+//				panic("mock out the GetAllForUser method")
+//			},
+//			InsertFunc: func(e *data.SecurityEvent) error {
+//				panic("mock out the Insert method")
+//			},
+//		}
+//
+//		// use mockedSecurityEventStore in code that requires data.SecurityEventStore
+//		// and then make assertions.
+//
+//	}
+type SecurityEventStoreMock struct {
+	// GetAllForUserFunc mocks the GetAllForUser method.
+	GetAllForUserFunc func(userID int32, event string, filters data.Filters) ([]*data.SecurityEvent, data.Metadata, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(e *data.SecurityEvent) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetAllForUser holds details about calls to the GetAllForUser method.
+		GetAllForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// Event is the event argument value.
+			Event string
+			// Filters is the filters argument value.
+			Filters data.Filters
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// E is the e argument value.
+			E *data.SecurityEvent
+		}
+	}
+	lockGetAllForUser sync.RWMutex
+	lockInsert        sync.RWMutex
+}
+
+// GetAllForUser calls GetAllForUserFunc.
+func (mock *SecurityEventStoreMock) GetAllForUser(userID int32, event string, filters data.Filters) ([]*data.SecurityEvent, data.Metadata, error) {
+	callInfo := struct {
+		UserID  int32
+		Event   string
+		Filters data.Filters
+	}{
+		UserID:  userID,
+		Event:   event,
+		Filters: filters,
+	}
+	mock.lockGetAllForUser.Lock()
+	mock.calls.GetAllForUser = append(mock.calls.GetAllForUser, callInfo)
+	mock.lockGetAllForUser.Unlock()
+	if mock.GetAllForUserFunc == nil {
+		var (
+			securityEventsOut []*data.SecurityEvent
+			metadataOut       data.Metadata
+			errOut            error
+		)
+		return securityEventsOut, metadataOut, errOut
+	}
+	return mock.GetAllForUserFunc(userID, event, filters)
+}
+
+// GetAllForUserCalls gets all the calls that were made to GetAllForUser.
+// Check the length with:
+//
+//	len(mockedSecurityEventStore.GetAllForUserCalls())
+func (mock *SecurityEventStoreMock) GetAllForUserCalls() []struct {
+	UserID  int32
+	Event   string
+	Filters data.Filters
+} {
+	var calls []struct {
+		UserID  int32
+		Event   string
+		Filters data.Filters
+	}
+	mock.lockGetAllForUser.RLock()
+	calls = mock.calls.GetAllForUser
+	mock.lockGetAllForUser.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *SecurityEventStoreMock) Insert(e *data.SecurityEvent) error {
+	callInfo := struct {
+		E *data.SecurityEvent
+	}{
+		E: e,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(e)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedSecurityEventStore.InsertCalls())
+func (mock *SecurityEventStoreMock) InsertCalls() []struct {
+	E *data.SecurityEvent
+} {
+	var calls []struct {
+		E *data.SecurityEvent
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// Ensure, that SyncStoreMock does implement data.SyncStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.SyncStore = &SyncStoreMock{}
+
+// SyncStoreMock is a mock implementation of data.SyncStore.
+//
+//	func TestSomethingThatUsesSyncStore(t *testing.T) {
+//
+//		// make and configure a mocked data.SyncStore
+//		mockedSyncStore := &SyncStoreMock{
+//			ChangesFunc: func(actor data.Actor, since *time.Time) (*data.SyncChanges, error) {
+//				panic("mock out the Changes method")
+//			},
+//		}
+//
+//		// use mockedSyncStore in code that requires data.SyncStore
+//		// and then make assertions.
+//
+//	}
+type SyncStoreMock struct {
+	// ChangesFunc mocks the Changes method.
+	ChangesFunc func(actor data.Actor, since *time.Time) (*data.SyncChanges, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Changes holds details about calls to the Changes method.
+		Changes []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Since is the since argument value.
+			Since *time.Time
+		}
+	}
+	lockChanges sync.RWMutex
+}
+
+// Changes calls ChangesFunc.
+func (mock *SyncStoreMock) Changes(actor data.Actor, since *time.Time) (*data.SyncChanges, error) {
+	callInfo := struct {
+		Actor data.Actor
+		Since *time.Time
+	}{
+		Actor: actor,
+		Since: since,
+	}
+	mock.lockChanges.Lock()
+	mock.calls.Changes = append(mock.calls.Changes, callInfo)
+	mock.lockChanges.Unlock()
+	if mock.ChangesFunc == nil {
+		var (
+			syncChangesOut *data.SyncChanges
+			errOut         error
+		)
+		return syncChangesOut, errOut
+	}
+	return mock.ChangesFunc(actor, since)
+}
+
+// ChangesCalls gets all the calls that were made to Changes.
+// Check the length with:
+//
+//	len(mockedSyncStore.ChangesCalls())
+func (mock *SyncStoreMock) ChangesCalls() []struct {
+	Actor data.Actor
+	Since *time.Time
+} {
+	var calls []struct {
+		Actor data.Actor
+		Since *time.Time
+	}
+	mock.lockChanges.RLock()
+	calls = mock.calls.Changes
+	mock.lockChanges.RUnlock()
+	return calls
+}
+
+// Ensure, that TagStoreMock does implement data.TagStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.TagStore = &TagStoreMock{}
+
+// TagStoreMock is a mock implementation of data.TagStore.
+//
+//	func TestSomethingThatUsesTagStore(t *testing.T) {
+//
+//		// make and configure a mocked data.TagStore
+//		mockedTagStore := &TagStoreMock{
+//			DeleteFunc: func(actor data.Actor, id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetAllFunc: func(actor data.Actor) ([]*data.Tag, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			InsertFunc: func(tag *data.Tag) error {
+//				panic("mock out the Insert method")
+//			},
+//			SetProjectTagsFunc: func(projectInternalID int32, names []string) error {
+//				panic("mock out the SetProjectTags method")
+//			},
+//			SetTimesheetTagsFunc: func(entryID int64, names []string) error {
+//				panic("mock out the SetTimesheetTags method")
+//			},
+//		}
+//
+//		// use mockedTagStore in code that requires data.TagStore
+//		// and then make assertions.
+//
+//	}
+type TagStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(actor data.Actor, id int32) error
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor) ([]*data.Tag, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(tag *data.Tag) error
+
+	// SetProjectTagsFunc mocks the SetProjectTags method.
+	SetProjectTagsFunc func(projectInternalID int32, names []string) error
+
+	// SetTimesheetTagsFunc mocks the SetTimesheetTags method.
+	SetTimesheetTagsFunc func(entryID int64, names []string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Tag is the tag argument value.
+			Tag *data.Tag
+		}
+		// SetProjectTags holds details about calls to the SetProjectTags method.
+		SetProjectTags []struct {
+			// ProjectInternalID is the projectInternalID argument value.
+			ProjectInternalID int32
+			// Names is the names argument value.
+			Names []string
+		}
+		// SetTimesheetTags holds details about calls to the SetTimesheetTags method.
+		SetTimesheetTags []struct {
+			// EntryID is the entryID argument value.
+			EntryID int64
+			// Names is the names argument value.
+			Names []string
+		}
+	}
+	lockDelete           sync.RWMutex
+	lockGetAll           sync.RWMutex
+	lockInsert           sync.RWMutex
+	lockSetProjectTags   sync.RWMutex
+	lockSetTimesheetTags sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *TagStoreMock) Delete(actor data.Actor, id int32) error {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(actor, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedTagStore.DeleteCalls())
+func (mock *TagStoreMock) DeleteCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *TagStoreMock) GetAll(actor data.Actor) ([]*data.Tag, error) {
+	callInfo := struct {
+		Actor data.Actor
+	}{
+		Actor: actor,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			tagsOut []*data.Tag
+			errOut  error
+		)
+		return tagsOut, errOut
+	}
+	return mock.GetAllFunc(actor)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedTagStore.GetAllCalls())
+func (mock *TagStoreMock) GetAllCalls() []struct {
+	Actor data.Actor
+} {
+	var calls []struct {
+		Actor data.Actor
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *TagStoreMock) Insert(tag *data.Tag) error {
+	callInfo := struct {
+		Tag *data.Tag
+	}{
+		Tag: tag,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(tag)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedTagStore.InsertCalls())
+func (mock *TagStoreMock) InsertCalls() []struct {
+	Tag *data.Tag
+} {
+	var calls []struct {
+		Tag *data.Tag
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// SetProjectTags calls SetProjectTagsFunc.
+func (mock *TagStoreMock) SetProjectTags(projectInternalID int32, names []string) error {
+	callInfo := struct {
+		ProjectInternalID int32
+		Names             []string
+	}{
+		ProjectInternalID: projectInternalID,
+		Names:             names,
+	}
+	mock.lockSetProjectTags.Lock()
+	mock.calls.SetProjectTags = append(mock.calls.SetProjectTags, callInfo)
+	mock.lockSetProjectTags.Unlock()
+	if mock.SetProjectTagsFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetProjectTagsFunc(projectInternalID, names)
+}
+
+// SetProjectTagsCalls gets all the calls that were made to SetProjectTags.
+// Check the length with:
+//
+//	len(mockedTagStore.SetProjectTagsCalls())
+func (mock *TagStoreMock) SetProjectTagsCalls() []struct {
+	ProjectInternalID int32
+	Names             []string
+} {
+	var calls []struct {
+		ProjectInternalID int32
+		Names             []string
+	}
+	mock.lockSetProjectTags.RLock()
+	calls = mock.calls.SetProjectTags
+	mock.lockSetProjectTags.RUnlock()
+	return calls
+}
+
+// SetTimesheetTags calls SetTimesheetTagsFunc.
+func (mock *TagStoreMock) SetTimesheetTags(entryID int64, names []string) error {
+	callInfo := struct {
+		EntryID int64
+		Names   []string
+	}{
+		EntryID: entryID,
+		Names:   names,
+	}
+	mock.lockSetTimesheetTags.Lock()
+	mock.calls.SetTimesheetTags = append(mock.calls.SetTimesheetTags, callInfo)
+	mock.lockSetTimesheetTags.Unlock()
+	if mock.SetTimesheetTagsFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetTimesheetTagsFunc(entryID, names)
+}
+
+// SetTimesheetTagsCalls gets all the calls that were made to SetTimesheetTags.
+// Check the length with:
+//
+//	len(mockedTagStore.SetTimesheetTagsCalls())
+func (mock *TagStoreMock) SetTimesheetTagsCalls() []struct {
+	EntryID int64
+	Names   []string
+} {
+	var calls []struct {
+		EntryID int64
+		Names   []string
+	}
+	mock.lockSetTimesheetTags.RLock()
+	calls = mock.calls.SetTimesheetTags
+	mock.lockSetTimesheetTags.RUnlock()
+	return calls
+}
+
+// Ensure, that TeamStoreMock does implement data.TeamStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.TeamStore = &TeamStoreMock{}
+
+// TeamStoreMock is a mock implementation of data.TeamStore.
+//
+//	func TestSomethingThatUsesTeamStore(t *testing.T) {
+//
+//		// make and configure a mocked data.TeamStore
+//		mockedTeamStore := &TeamStoreMock{
+//			DeleteFunc: func(actor data.Actor, id int32) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(actor data.Actor, id int32) (*data.Team, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor) ([]*data.Team, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			GetMembersFunc: func(teamID int32) ([]*data.TeamMember, error) {
+//				panic("mock out the GetMembers method")
+//			},
+//			InsertFunc: func(team *data.Team) error {
+//				panic("mock out the Insert method")
+//			},
+//			IsLeadFunc: func(leadID int32, userID int32) (bool, error) {
+//				panic("mock out the IsLead method")
+//			},
+//			RemoveMemberFunc: func(teamID int32, userID int32) error {
+//				panic("mock out the RemoveMember method")
+//			},
+//			SetMemberFunc: func(teamID int32, userID int32, isLead bool) error {
+//				panic("mock out the SetMember method")
+//			},
+//			UpdateFunc: func(team *data.Team) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedTeamStore in code that requires data.TeamStore
+//		// and then make assertions.
+//
+//	}
+type TeamStoreMock struct {
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(actor data.Actor, id int32) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(actor data.Actor, id int32) (*data.Team, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor) ([]*data.Team, error)
+
+	// GetMembersFunc mocks the GetMembers method.
+	GetMembersFunc func(teamID int32) ([]*data.TeamMember, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(team *data.Team) error
+
+	// IsLeadFunc mocks the IsLead method.
+	IsLeadFunc func(leadID int32, userID int32) (bool, error)
+
+	// RemoveMemberFunc mocks the RemoveMember method.
+	RemoveMemberFunc func(teamID int32, userID int32) error
+
+	// SetMemberFunc mocks the SetMember method.
+	SetMemberFunc func(teamID int32, userID int32, isLead bool) error
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(team *data.Team) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+		}
+		// GetMembers holds details about calls to the GetMembers method.
+		GetMembers []struct {
+			// TeamID is the teamID argument value.
+			TeamID int32
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Team is the team argument value.
+			Team *data.Team
+		}
+		// IsLead holds details about calls to the IsLead method.
+		IsLead []struct {
+			// LeadID is the leadID argument value.
+			LeadID int32
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// RemoveMember holds details about calls to the RemoveMember method.
+		RemoveMember []struct {
+			// TeamID is the teamID argument value.
+			TeamID int32
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// SetMember holds details about calls to the SetMember method.
+		SetMember []struct {
+			// TeamID is the teamID argument value.
+			TeamID int32
+			// UserID is the userID argument value.
+			UserID int32
+			// IsLead is the isLead argument value.
+			IsLead bool
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// Team is the team argument value.
+			Team *data.Team
+		}
+	}
+	lockDelete       sync.RWMutex
+	lockGet          sync.RWMutex
+	lockGetAll       sync.RWMutex
+	lockGetMembers   sync.RWMutex
+	lockInsert       sync.RWMutex
+	lockIsLead       sync.RWMutex
+	lockRemoveMember sync.RWMutex
+	lockSetMember    sync.RWMutex
+	lockUpdate       sync.RWMutex
+}
+
+// Delete calls DeleteFunc.
+func (mock *TeamStoreMock) Delete(actor data.Actor, id int32) error {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	if mock.DeleteFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteFunc(actor, id)
+}
+
+// DeleteCalls gets all the calls that were made to Delete.
+// Check the length with:
+//
+//	len(mockedTeamStore.DeleteCalls())
+func (mock *TeamStoreMock) DeleteCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *TeamStoreMock) Get(actor data.Actor, id int32) (*data.Team, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			teamOut *data.Team
+			errOut  error
+		)
+		return teamOut, errOut
+	}
+	return mock.GetFunc(actor, id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedTeamStore.GetCalls())
+func (mock *TeamStoreMock) GetCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *TeamStoreMock) GetAll(actor data.Actor) ([]*data.Team, error) {
+	callInfo := struct {
+		Actor data.Actor
+	}{
+		Actor: actor,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			teamsOut []*data.Team
+			errOut   error
+		)
+		return teamsOut, errOut
+	}
+	return mock.GetAllFunc(actor)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedTeamStore.GetAllCalls())
+func (mock *TeamStoreMock) GetAllCalls() []struct {
+	Actor data.Actor
+} {
+	var calls []struct {
+		Actor data.Actor
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetMembers calls GetMembersFunc.
+func (mock *TeamStoreMock) GetMembers(teamID int32) ([]*data.TeamMember, error) {
+	callInfo := struct {
+		TeamID int32
+	}{
+		TeamID: teamID,
+	}
+	mock.lockGetMembers.Lock()
+	mock.calls.GetMembers = append(mock.calls.GetMembers, callInfo)
+	mock.lockGetMembers.Unlock()
+	if mock.GetMembersFunc == nil {
+		var (
+			teamMembersOut []*data.TeamMember
+			errOut         error
+		)
+		return teamMembersOut, errOut
+	}
+	return mock.GetMembersFunc(teamID)
+}
+
+// GetMembersCalls gets all the calls that were made to GetMembers.
+// Check the length with:
+//
+//	len(mockedTeamStore.GetMembersCalls())
+func (mock *TeamStoreMock) GetMembersCalls() []struct {
+	TeamID int32
+} {
+	var calls []struct {
+		TeamID int32
+	}
+	mock.lockGetMembers.RLock()
+	calls = mock.calls.GetMembers
+	mock.lockGetMembers.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *TeamStoreMock) Insert(team *data.Team) error {
+	callInfo := struct {
+		Team *data.Team
+	}{
+		Team: team,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(team)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedTeamStore.InsertCalls())
+func (mock *TeamStoreMock) InsertCalls() []struct {
+	Team *data.Team
+} {
+	var calls []struct {
+		Team *data.Team
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// IsLead calls IsLeadFunc.
+func (mock *TeamStoreMock) IsLead(leadID int32, userID int32) (bool, error) {
+	callInfo := struct {
+		LeadID int32
+		UserID int32
+	}{
+		LeadID: leadID,
+		UserID: userID,
+	}
+	mock.lockIsLead.Lock()
+	mock.calls.IsLead = append(mock.calls.IsLead, callInfo)
+	mock.lockIsLead.Unlock()
+	if mock.IsLeadFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.IsLeadFunc(leadID, userID)
+}
+
+// IsLeadCalls gets all the calls that were made to IsLead.
+// Check the length with:
+//
+//	len(mockedTeamStore.IsLeadCalls())
+func (mock *TeamStoreMock) IsLeadCalls() []struct {
+	LeadID int32
+	UserID int32
+} {
+	var calls []struct {
+		LeadID int32
+		UserID int32
+	}
+	mock.lockIsLead.RLock()
+	calls = mock.calls.IsLead
+	mock.lockIsLead.RUnlock()
+	return calls
+}
+
+// RemoveMember calls RemoveMemberFunc.
+func (mock *TeamStoreMock) RemoveMember(teamID int32, userID int32) error {
+	callInfo := struct {
+		TeamID int32
+		UserID int32
+	}{
+		TeamID: teamID,
+		UserID: userID,
+	}
+	mock.lockRemoveMember.Lock()
+	mock.calls.RemoveMember = append(mock.calls.RemoveMember, callInfo)
+	mock.lockRemoveMember.Unlock()
+	if mock.RemoveMemberFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.RemoveMemberFunc(teamID, userID)
+}
+
+// RemoveMemberCalls gets all the calls that were made to RemoveMember.
+// Check the length with:
+//
+//	len(mockedTeamStore.RemoveMemberCalls())
+func (mock *TeamStoreMock) RemoveMemberCalls() []struct {
+	TeamID int32
+	UserID int32
+} {
+	var calls []struct {
+		TeamID int32
+		UserID int32
+	}
+	mock.lockRemoveMember.RLock()
+	calls = mock.calls.RemoveMember
+	mock.lockRemoveMember.RUnlock()
+	return calls
+}
+
+// SetMember calls SetMemberFunc.
+func (mock *TeamStoreMock) SetMember(teamID int32, userID int32, isLead bool) error {
+	callInfo := struct {
+		TeamID int32
+		UserID int32
+		IsLead bool
+	}{
+		TeamID: teamID,
+		UserID: userID,
+		IsLead: isLead,
+	}
+	mock.lockSetMember.Lock()
+	mock.calls.SetMember = append(mock.calls.SetMember, callInfo)
+	mock.lockSetMember.Unlock()
+	if mock.SetMemberFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetMemberFunc(teamID, userID, isLead)
+}
+
+// SetMemberCalls gets all the calls that were made to SetMember.
+// Check the length with:
+//
+//	len(mockedTeamStore.SetMemberCalls())
+func (mock *TeamStoreMock) SetMemberCalls() []struct {
+	TeamID int32
+	UserID int32
+	IsLead bool
+} {
+	var calls []struct {
+		TeamID int32
+		UserID int32
+		IsLead bool
+	}
+	mock.lockSetMember.RLock()
+	calls = mock.calls.SetMember
+	mock.lockSetMember.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *TeamStoreMock) Update(team *data.Team) error {
+	callInfo := struct {
+		Team *data.Team
+	}{
+		Team: team,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(team)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedTeamStore.UpdateCalls())
+func (mock *TeamStoreMock) UpdateCalls() []struct {
+	Team *data.Team
+} {
+	var calls []struct {
+		Team *data.Team
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
+
+// Ensure, that TimesheetStoreMock does implement data.TimesheetStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.TimesheetStore = &TimesheetStoreMock{}
+
+// TimesheetStoreMock is a mock implementation of data.TimesheetStore.
+//
+//	func TestSomethingThatUsesTimesheetStore(t *testing.T) {
+//
+//		// make and configure a mocked data.TimesheetStore
+//		mockedTimesheetStore := &TimesheetStoreMock{
+//			AdvanceFunc: func(entry *data.TimesheetEntry, next *data.ApprovalStep) error {
+//				panic("mock out the Advance method")
+//			},
+//			CopyInFunc: func(entries []*data.TimesheetEntry, source string) error {
+//				panic("mock out the CopyIn method")
+//			},
+//			GetFunc: func(id int64) (*data.TimesheetEntry, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, filter data.TimesheetFilter, filters data.Filters) ([]*data.TimesheetEntry, data.Metadata, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			GetApprovedDailyFunc: func(externalID int32) ([]data.DailyActual, error) {
+//				panic("mock out the GetApprovedDaily method")
+//			},
+//			GetDailyMinutesFunc: func(userIDs []int32, from time.Time, to time.Time) (map[int32]map[string]int32, error) {
+//				panic("mock out the GetDailyMinutes method")
+//			},
+//			GetFacetsFunc: func(actor data.Actor, filter data.TimesheetFilter) (*data.TimesheetFacets, error) {
+//				panic("mock out the GetFacets method")
+//			},
+//			GetSuggestionsFunc: func(userID int32, day time.Time, limit int) ([]*data.TimesheetSuggestion, error) {
+//				panic("mock out the GetSuggestions method")
+//			},
+//			GetWeekSummarySnapshotFunc: func() (*data.ReportSnapshot, error) {
+//				panic("mock out the GetWeekSummarySnapshot method")
+//			},
+//			PurgeDeletedFunc: func(cutoff time.Time, dryRun bool) (int64, error) {
+//				panic("mock out the PurgeDeleted method")
+//			},
+//			RefreshWeekSummaryFunc: func() (*data.ReportSnapshot, error) {
+//				panic("mock out the RefreshWeekSummary method")
+//			},
+//			RejectFunc: func(entry *data.TimesheetEntry) error {
+//				panic("mock out the Reject method")
+//			},
+//			ReportFunc: func(actor data.Actor, filter data.TimesheetFilter, groupBy []string, metrics []string) (*data.TimesheetReport, error) {
+//				panic("mock out the Report method")
+//			},
+//			SubmitFunc: func(entry *data.TimesheetEntry, step *data.ApprovalStep) error {
+//				panic("mock out the Submit method")
+//			},
+//			SyncFunc: func(userID int32, s *data.TimesheetSync, strategy string) (*data.TimesheetEntry, bool, error) {
+//				panic("mock out the Sync method")
+//			},
+//		}
+//
+//		// use mockedTimesheetStore in code that requires data.TimesheetStore
+//		// and then make assertions.
+//
+//	}
+type TimesheetStoreMock struct {
+	// AdvanceFunc mocks the Advance method.
+	AdvanceFunc func(entry *data.TimesheetEntry, next *data.ApprovalStep) error
+
+	// CopyInFunc mocks the CopyIn method.
+	CopyInFunc func(entries []*data.TimesheetEntry, source string) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(id int64) (*data.TimesheetEntry, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, filter data.TimesheetFilter, filters data.Filters) ([]*data.TimesheetEntry, data.Metadata, error)
+
+	// GetApprovedDailyFunc mocks the GetApprovedDaily method.
+	GetApprovedDailyFunc func(externalID int32) ([]data.DailyActual, error)
+
+	// GetDailyMinutesFunc mocks the GetDailyMinutes method.
+	GetDailyMinutesFunc func(userIDs []int32, from time.Time, to time.Time) (map[int32]map[string]int32, error)
+
+	// GetFacetsFunc mocks the GetFacets method.
+	GetFacetsFunc func(actor data.Actor, filter data.TimesheetFilter) (*data.TimesheetFacets, error)
+
+	// GetSuggestionsFunc mocks the GetSuggestions method.
+	GetSuggestionsFunc func(userID int32, day time.Time, limit int) ([]*data.TimesheetSuggestion, error)
+
+	// GetWeekSummarySnapshotFunc mocks the GetWeekSummarySnapshot method.
+	GetWeekSummarySnapshotFunc func() (*data.ReportSnapshot, error)
+
+	// PurgeDeletedFunc mocks the PurgeDeleted method.
+	PurgeDeletedFunc func(cutoff time.Time, dryRun bool) (int64, error)
+
+	// RefreshWeekSummaryFunc mocks the RefreshWeekSummary method.
+	RefreshWeekSummaryFunc func() (*data.ReportSnapshot, error)
+
+	// RejectFunc mocks the Reject method.
+	RejectFunc func(entry *data.TimesheetEntry) error
+
+	// ReportFunc mocks the Report method.
+	ReportFunc func(actor data.Actor, filter data.TimesheetFilter, groupBy []string, metrics []string) (*data.TimesheetReport, error)
+
+	// SubmitFunc mocks the Submit method.
+	SubmitFunc func(entry *data.TimesheetEntry, step *data.ApprovalStep) error
+
+	// SyncFunc mocks the Sync method.
+	SyncFunc func(userID int32, s *data.TimesheetSync, strategy string) (*data.TimesheetEntry, bool, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Advance holds details about calls to the Advance method.
+		Advance []struct {
+			// Entry is the entry argument value.
+			Entry *data.TimesheetEntry
+			// Next is the next argument value.
+			Next *data.ApprovalStep
+		}
+		// CopyIn holds details about calls to the CopyIn method.
+		CopyIn []struct {
+			// Entries is the entries argument value.
+			Entries []*data.TimesheetEntry
+			// Source is the source argument value.
+			Source string
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// ID is the id argument value.
+			ID int64
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Filter is the filter argument value.
+			Filter data.TimesheetFilter
+			// Filters is the filters argument value.
+			Filters data.Filters
+		}
+		// GetApprovedDaily holds details about calls to the GetApprovedDaily method.
+		GetApprovedDaily []struct {
+			// ExternalID is the externalID argument value.
+			ExternalID int32
+		}
+		// GetDailyMinutes holds details about calls to the GetDailyMinutes method.
+		GetDailyMinutes []struct {
+			// UserIDs is the userIDs argument value.
+			UserIDs []int32
+			// From is the from argument value.
+			From time.Time
+			// To is the to argument value.
+			To time.Time
+		}
+		// GetFacets holds details about calls to the GetFacets method.
+		GetFacets []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Filter is the filter argument value.
+			Filter data.TimesheetFilter
+		}
+		// GetSuggestions holds details about calls to the GetSuggestions method.
+		GetSuggestions []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// Day is the day argument value.
+			Day time.Time
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetWeekSummarySnapshot holds details about calls to the GetWeekSummarySnapshot method.
+		GetWeekSummarySnapshot []struct {
+		}
+		// PurgeDeleted holds details about calls to the PurgeDeleted method.
+		PurgeDeleted []struct {
+			// Cutoff is the cutoff argument value.
+			Cutoff time.Time
+			// DryRun is the dryRun argument value.
+			DryRun bool
+		}
+		// RefreshWeekSummary holds details about calls to the RefreshWeekSummary method.
+		RefreshWeekSummary []struct {
+		}
+		// Reject holds details about calls to the Reject method.
+		Reject []struct {
+			// Entry is the entry argument value.
+			Entry *data.TimesheetEntry
+		}
+		// Report holds details about calls to the Report method.
+		Report []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Filter is the filter argument value.
+			Filter data.TimesheetFilter
+			// GroupBy is the groupBy argument value.
+			GroupBy []string
+			// Metrics is the metrics argument value.
+			Metrics []string
+		}
+		// Submit holds details about calls to the Submit method.
+		Submit []struct {
+			// Entry is the entry argument value.
+			Entry *data.TimesheetEntry
+			// Step is the step argument value.
+			Step *data.ApprovalStep
+		}
+		// Sync holds details about calls to the Sync method.
+		Sync []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// S is the s argument value.
+			S *data.TimesheetSync
+			// Strategy is the strategy argument value.
+			Strategy string
+		}
+	}
+	lockAdvance                sync.RWMutex
+	lockCopyIn                 sync.RWMutex
+	lockGet                    sync.RWMutex
+	lockGetAll                 sync.RWMutex
+	lockGetApprovedDaily       sync.RWMutex
+	lockGetDailyMinutes        sync.RWMutex
+	lockGetFacets              sync.RWMutex
+	lockGetSuggestions         sync.RWMutex
+	lockGetWeekSummarySnapshot sync.RWMutex
+	lockPurgeDeleted           sync.RWMutex
+	lockRefreshWeekSummary     sync.RWMutex
+	lockReject                 sync.RWMutex
+	lockReport                 sync.RWMutex
+	lockSubmit                 sync.RWMutex
+	lockSync                   sync.RWMutex
+}
+
+// Advance calls AdvanceFunc.
+func (mock *TimesheetStoreMock) Advance(entry *data.TimesheetEntry, next *data.ApprovalStep) error {
+	callInfo := struct {
+		Entry *data.TimesheetEntry
+		Next  *data.ApprovalStep
+	}{
+		Entry: entry,
+		Next:  next,
+	}
+	mock.lockAdvance.Lock()
+	mock.calls.Advance = append(mock.calls.Advance, callInfo)
+	mock.lockAdvance.Unlock()
+	if mock.AdvanceFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.AdvanceFunc(entry, next)
+}
+
+// AdvanceCalls gets all the calls that were made to Advance.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.AdvanceCalls())
+func (mock *TimesheetStoreMock) AdvanceCalls() []struct {
+	Entry *data.TimesheetEntry
+	Next  *data.ApprovalStep
+} {
+	var calls []struct {
+		Entry *data.TimesheetEntry
+		Next  *data.ApprovalStep
+	}
+	mock.lockAdvance.RLock()
+	calls = mock.calls.Advance
+	mock.lockAdvance.RUnlock()
+	return calls
+}
+
+// CopyIn calls CopyInFunc.
+func (mock *TimesheetStoreMock) CopyIn(entries []*data.TimesheetEntry, source string) error {
+	callInfo := struct {
+		Entries []*data.TimesheetEntry
+		Source  string
+	}{
+		Entries: entries,
+		Source:  source,
+	}
+	mock.lockCopyIn.Lock()
+	mock.calls.CopyIn = append(mock.calls.CopyIn, callInfo)
+	mock.lockCopyIn.Unlock()
+	if mock.CopyInFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.CopyInFunc(entries, source)
+}
+
+// CopyInCalls gets all the calls that were made to CopyIn.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.CopyInCalls())
+func (mock *TimesheetStoreMock) CopyInCalls() []struct {
+	Entries []*data.TimesheetEntry
+	Source  string
+} {
+	var calls []struct {
+		Entries []*data.TimesheetEntry
+		Source  string
+	}
+	mock.lockCopyIn.RLock()
+	calls = mock.calls.CopyIn
+	mock.lockCopyIn.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *TimesheetStoreMock) Get(id int64) (*data.TimesheetEntry, error) {
+	callInfo := struct {
+		ID int64
+	}{
+		ID: id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			timesheetEntryOut *data.TimesheetEntry
+			errOut            error
+		)
+		return timesheetEntryOut, errOut
+	}
+	return mock.GetFunc(id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetCalls())
+func (mock *TimesheetStoreMock) GetCalls() []struct {
+	ID int64
+} {
+	var calls []struct {
+		ID int64
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *TimesheetStoreMock) GetAll(actor data.Actor, filter data.TimesheetFilter, filters data.Filters) ([]*data.TimesheetEntry, data.Metadata, error) {
+	callInfo := struct {
+		Actor   data.Actor
+		Filter  data.TimesheetFilter
+		Filters data.Filters
+	}{
+		Actor:   actor,
+		Filter:  filter,
+		Filters: filters,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			timesheetEntrysOut []*data.TimesheetEntry
+			metadataOut        data.Metadata
+			errOut             error
+		)
+		return timesheetEntrysOut, metadataOut, errOut
+	}
+	return mock.GetAllFunc(actor, filter, filters)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetAllCalls())
+func (mock *TimesheetStoreMock) GetAllCalls() []struct {
+	Actor   data.Actor
+	Filter  data.TimesheetFilter
+	Filters data.Filters
+} {
+	var calls []struct {
+		Actor   data.Actor
+		Filter  data.TimesheetFilter
+		Filters data.Filters
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetApprovedDaily calls GetApprovedDailyFunc.
+func (mock *TimesheetStoreMock) GetApprovedDaily(externalID int32) ([]data.DailyActual, error) {
+	callInfo := struct {
+		ExternalID int32
+	}{
+		ExternalID: externalID,
+	}
+	mock.lockGetApprovedDaily.Lock()
+	mock.calls.GetApprovedDaily = append(mock.calls.GetApprovedDaily, callInfo)
+	mock.lockGetApprovedDaily.Unlock()
+	if mock.GetApprovedDailyFunc == nil {
+		var (
+			dailyActualsOut []data.DailyActual
+			errOut          error
+		)
+		return dailyActualsOut, errOut
+	}
+	return mock.GetApprovedDailyFunc(externalID)
+}
+
+// GetApprovedDailyCalls gets all the calls that were made to GetApprovedDaily.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetApprovedDailyCalls())
+func (mock *TimesheetStoreMock) GetApprovedDailyCalls() []struct {
+	ExternalID int32
+} {
+	var calls []struct {
+		ExternalID int32
+	}
+	mock.lockGetApprovedDaily.RLock()
+	calls = mock.calls.GetApprovedDaily
+	mock.lockGetApprovedDaily.RUnlock()
+	return calls
+}
+
+// GetDailyMinutes calls GetDailyMinutesFunc.
+func (mock *TimesheetStoreMock) GetDailyMinutes(userIDs []int32, from time.Time, to time.Time) (map[int32]map[string]int32, error) {
+	callInfo := struct {
+		UserIDs []int32
+		From    time.Time
+		To      time.Time
+	}{
+		UserIDs: userIDs,
+		From:    from,
+		To:      to,
+	}
+	mock.lockGetDailyMinutes.Lock()
+	mock.calls.GetDailyMinutes = append(mock.calls.GetDailyMinutes, callInfo)
+	mock.lockGetDailyMinutes.Unlock()
+	if mock.GetDailyMinutesFunc == nil {
+		var (
+			int32ToStringToInt32Out map[int32]map[string]int32
+			errOut                  error
+		)
+		return int32ToStringToInt32Out, errOut
+	}
+	return mock.GetDailyMinutesFunc(userIDs, from, to)
+}
+
+// GetDailyMinutesCalls gets all the calls that were made to GetDailyMinutes.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetDailyMinutesCalls())
+func (mock *TimesheetStoreMock) GetDailyMinutesCalls() []struct {
+	UserIDs []int32
+	From    time.Time
+	To      time.Time
+} {
+	var calls []struct {
+		UserIDs []int32
+		From    time.Time
+		To      time.Time
+	}
+	mock.lockGetDailyMinutes.RLock()
+	calls = mock.calls.GetDailyMinutes
+	mock.lockGetDailyMinutes.RUnlock()
+	return calls
+}
+
+// GetFacets calls GetFacetsFunc.
+func (mock *TimesheetStoreMock) GetFacets(actor data.Actor, filter data.TimesheetFilter) (*data.TimesheetFacets, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		Filter data.TimesheetFilter
+	}{
+		Actor:  actor,
+		Filter: filter,
+	}
+	mock.lockGetFacets.Lock()
+	mock.calls.GetFacets = append(mock.calls.GetFacets, callInfo)
+	mock.lockGetFacets.Unlock()
+	if mock.GetFacetsFunc == nil {
+		var (
+			timesheetFacetsOut *data.TimesheetFacets
+			errOut             error
+		)
+		return timesheetFacetsOut, errOut
+	}
+	return mock.GetFacetsFunc(actor, filter)
+}
+
+// GetFacetsCalls gets all the calls that were made to GetFacets.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetFacetsCalls())
+func (mock *TimesheetStoreMock) GetFacetsCalls() []struct {
+	Actor  data.Actor
+	Filter data.TimesheetFilter
+} {
+	var calls []struct {
+		Actor  data.Actor
+		Filter data.TimesheetFilter
+	}
+	mock.lockGetFacets.RLock()
+	calls = mock.calls.GetFacets
+	mock.lockGetFacets.RUnlock()
+	return calls
+}
+
+// GetSuggestions calls GetSuggestionsFunc.
+func (mock *TimesheetStoreMock) GetSuggestions(userID int32, day time.Time, limit int) ([]*data.TimesheetSuggestion, error) {
+	callInfo := struct {
+		UserID int32
+		Day    time.Time
+		Limit  int
+	}{
+		UserID: userID,
+		Day:    day,
+		Limit:  limit,
+	}
+	mock.lockGetSuggestions.Lock()
+	mock.calls.GetSuggestions = append(mock.calls.GetSuggestions, callInfo)
+	mock.lockGetSuggestions.Unlock()
+	if mock.GetSuggestionsFunc == nil {
+		var (
+			timesheetSuggestionsOut []*data.TimesheetSuggestion
+			errOut                  error
+		)
+		return timesheetSuggestionsOut, errOut
+	}
+	return mock.GetSuggestionsFunc(userID, day, limit)
+}
+
+// GetSuggestionsCalls gets all the calls that were made to GetSuggestions.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetSuggestionsCalls())
+func (mock *TimesheetStoreMock) GetSuggestionsCalls() []struct {
+	UserID int32
+	Day    time.Time
+	Limit  int
+} {
+	var calls []struct {
+		UserID int32
+		Day    time.Time
+		Limit  int
+	}
+	mock.lockGetSuggestions.RLock()
+	calls = mock.calls.GetSuggestions
+	mock.lockGetSuggestions.RUnlock()
+	return calls
+}
+
+// GetWeekSummarySnapshot calls GetWeekSummarySnapshotFunc.
+func (mock *TimesheetStoreMock) GetWeekSummarySnapshot() (*data.ReportSnapshot, error) {
+	callInfo := struct {
+	}{}
+	mock.lockGetWeekSummarySnapshot.Lock()
+	mock.calls.GetWeekSummarySnapshot = append(mock.calls.GetWeekSummarySnapshot, callInfo)
+	mock.lockGetWeekSummarySnapshot.Unlock()
+	if mock.GetWeekSummarySnapshotFunc == nil {
+		var (
+			reportSnapshotOut *data.ReportSnapshot
+			errOut            error
+		)
+		return reportSnapshotOut, errOut
+	}
+	return mock.GetWeekSummarySnapshotFunc()
+}
+
+// GetWeekSummarySnapshotCalls gets all the calls that were made to GetWeekSummarySnapshot.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.GetWeekSummarySnapshotCalls())
+func (mock *TimesheetStoreMock) GetWeekSummarySnapshotCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetWeekSummarySnapshot.RLock()
+	calls = mock.calls.GetWeekSummarySnapshot
+	mock.lockGetWeekSummarySnapshot.RUnlock()
+	return calls
+}
+
+// PurgeDeleted calls PurgeDeletedFunc.
+func (mock *TimesheetStoreMock) PurgeDeleted(cutoff time.Time, dryRun bool) (int64, error) {
+	callInfo := struct {
+		Cutoff time.Time
+		DryRun bool
+	}{
+		Cutoff: cutoff,
+		DryRun: dryRun,
+	}
+	mock.lockPurgeDeleted.Lock()
+	mock.calls.PurgeDeleted = append(mock.calls.PurgeDeleted, callInfo)
+	mock.lockPurgeDeleted.Unlock()
+	if mock.PurgeDeletedFunc == nil {
+		var (
+			nOut   int64
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.PurgeDeletedFunc(cutoff, dryRun)
+}
+
+// PurgeDeletedCalls gets all the calls that were made to PurgeDeleted.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.PurgeDeletedCalls())
+func (mock *TimesheetStoreMock) PurgeDeletedCalls() []struct {
+	Cutoff time.Time
+	DryRun bool
+} {
+	var calls []struct {
+		Cutoff time.Time
+		DryRun bool
+	}
+	mock.lockPurgeDeleted.RLock()
+	calls = mock.calls.PurgeDeleted
+	mock.lockPurgeDeleted.RUnlock()
+	return calls
+}
+
+// RefreshWeekSummary calls RefreshWeekSummaryFunc.
+func (mock *TimesheetStoreMock) RefreshWeekSummary() (*data.ReportSnapshot, error) {
+	callInfo := struct {
+	}{}
+	mock.lockRefreshWeekSummary.Lock()
+	mock.calls.RefreshWeekSummary = append(mock.calls.RefreshWeekSummary, callInfo)
+	mock.lockRefreshWeekSummary.Unlock()
+	if mock.RefreshWeekSummaryFunc == nil {
+		var (
+			reportSnapshotOut *data.ReportSnapshot
+			errOut            error
+		)
+		return reportSnapshotOut, errOut
+	}
+	return mock.RefreshWeekSummaryFunc()
+}
+
+// RefreshWeekSummaryCalls gets all the calls that were made to RefreshWeekSummary.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.RefreshWeekSummaryCalls())
+func (mock *TimesheetStoreMock) RefreshWeekSummaryCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockRefreshWeekSummary.RLock()
+	calls = mock.calls.RefreshWeekSummary
+	mock.lockRefreshWeekSummary.RUnlock()
+	return calls
+}
+
+// Reject calls RejectFunc.
+func (mock *TimesheetStoreMock) Reject(entry *data.TimesheetEntry) error {
+	callInfo := struct {
+		Entry *data.TimesheetEntry
+	}{
+		Entry: entry,
+	}
+	mock.lockReject.Lock()
+	mock.calls.Reject = append(mock.calls.Reject, callInfo)
+	mock.lockReject.Unlock()
+	if mock.RejectFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.RejectFunc(entry)
+}
+
+// RejectCalls gets all the calls that were made to Reject.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.RejectCalls())
+func (mock *TimesheetStoreMock) RejectCalls() []struct {
+	Entry *data.TimesheetEntry
+} {
+	var calls []struct {
+		Entry *data.TimesheetEntry
+	}
+	mock.lockReject.RLock()
+	calls = mock.calls.Reject
+	mock.lockReject.RUnlock()
+	return calls
+}
+
+// Report calls ReportFunc.
+func (mock *TimesheetStoreMock) Report(actor data.Actor, filter data.TimesheetFilter, groupBy []string, metrics []string) (*data.TimesheetReport, error) {
+	callInfo := struct {
+		Actor   data.Actor
+		Filter  data.TimesheetFilter
+		GroupBy []string
+		Metrics []string
+	}{
+		Actor:   actor,
+		Filter:  filter,
+		GroupBy: groupBy,
+		Metrics: metrics,
+	}
+	mock.lockReport.Lock()
+	mock.calls.Report = append(mock.calls.Report, callInfo)
+	mock.lockReport.Unlock()
+	if mock.ReportFunc == nil {
+		var (
+			timesheetReportOut *data.TimesheetReport
+			errOut             error
+		)
+		return timesheetReportOut, errOut
+	}
+	return mock.ReportFunc(actor, filter, groupBy, metrics)
+}
+
+// ReportCalls gets all the calls that were made to Report.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.ReportCalls())
+func (mock *TimesheetStoreMock) ReportCalls() []struct {
+	Actor   data.Actor
+	Filter  data.TimesheetFilter
+	GroupBy []string
+	Metrics []string
+} {
+	var calls []struct {
+		Actor   data.Actor
+		Filter  data.TimesheetFilter
+		GroupBy []string
+		Metrics []string
+	}
+	mock.lockReport.RLock()
+	calls = mock.calls.Report
+	mock.lockReport.RUnlock()
+	return calls
+}
+
+// Submit calls SubmitFunc.
+func (mock *TimesheetStoreMock) Submit(entry *data.TimesheetEntry, step *data.ApprovalStep) error {
+	callInfo := struct {
+		Entry *data.TimesheetEntry
+		Step  *data.ApprovalStep
+	}{
+		Entry: entry,
+		Step:  step,
+	}
+	mock.lockSubmit.Lock()
+	mock.calls.Submit = append(mock.calls.Submit, callInfo)
+	mock.lockSubmit.Unlock()
+	if mock.SubmitFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SubmitFunc(entry, step)
+}
+
+// SubmitCalls gets all the calls that were made to Submit.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.SubmitCalls())
+func (mock *TimesheetStoreMock) SubmitCalls() []struct {
+	Entry *data.TimesheetEntry
+	Step  *data.ApprovalStep
+} {
+	var calls []struct {
+		Entry *data.TimesheetEntry
+		Step  *data.ApprovalStep
+	}
+	mock.lockSubmit.RLock()
+	calls = mock.calls.Submit
+	mock.lockSubmit.RUnlock()
+	return calls
+}
+
+// Sync calls SyncFunc.
+func (mock *TimesheetStoreMock) Sync(userID int32, s *data.TimesheetSync, strategy string) (*data.TimesheetEntry, bool, error) {
+	callInfo := struct {
+		UserID   int32
+		S        *data.TimesheetSync
+		Strategy string
+	}{
+		UserID:   userID,
+		S:        s,
+		Strategy: strategy,
+	}
+	mock.lockSync.Lock()
+	mock.calls.Sync = append(mock.calls.Sync, callInfo)
+	mock.lockSync.Unlock()
+	if mock.SyncFunc == nil {
+		var (
+			timesheetEntryOut *data.TimesheetEntry
+			bOut              bool
+			errOut            error
+		)
+		return timesheetEntryOut, bOut, errOut
+	}
+	return mock.SyncFunc(userID, s, strategy)
+}
+
+// SyncCalls gets all the calls that were made to Sync.
+// Check the length with:
+//
+//	len(mockedTimesheetStore.SyncCalls())
+func (mock *TimesheetStoreMock) SyncCalls() []struct {
+	UserID   int32
+	S        *data.TimesheetSync
+	Strategy string
+} {
+	var calls []struct {
+		UserID   int32
+		S        *data.TimesheetSync
+		Strategy string
+	}
+	mock.lockSync.RLock()
+	calls = mock.calls.Sync
+	mock.lockSync.RUnlock()
+	return calls
+}
+
+// Ensure, that TokenStoreMock does implement data.TokenStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.TokenStore = &TokenStoreMock{}
+
+// TokenStoreMock is a mock implementation of data.TokenStore.
+//
+//	func TestSomethingThatUsesTokenStore(t *testing.T) {
+//
+//		// make and configure a mocked data.TokenStore
+//		mockedTokenStore := &TokenStoreMock{
+//			DeleteAllForUserFunc: func(scope string, userID int32) error {
+//				panic("mock out the DeleteAllForUser method")
+//			},
+//			GetUserIDFunc: func(scope string, tokenPlaintext string) (int32, error) {
+//				panic("mock out the GetUserID method")
+//			},
+//			InsertFunc: func(token *data.Token) error {
+//				panic("mock out the Insert method")
+//			},
+//			IssuedSinceFunc: func(scope string, userID int32, ttl time.Duration, since time.Time) (bool, error) {
+//				panic("mock out the IssuedSince method")
+//			},
+//			NewFunc: func(userID int32, ttl time.Duration, scope string) (*data.Token, error) {
+//				panic("mock out the New method")
+//			},
+//			RevokeAllForUserFunc: func(userID int32) (int64, error) {
+//				panic("mock out the RevokeAllForUser method")
+//			},
+//		}
+//
+//		// use mockedTokenStore in code that requires data.TokenStore
+//		// and then make assertions.
+//
+//	}
+type TokenStoreMock struct {
+	// DeleteAllForUserFunc mocks the DeleteAllForUser method.
+	DeleteAllForUserFunc func(scope string, userID int32) error
+
+	// GetUserIDFunc mocks the GetUserID method.
+	GetUserIDFunc func(scope string, tokenPlaintext string) (int32, error)
+
+	// InsertFunc mocks the Insert method.
+	InsertFunc func(token *data.Token) error
+
+	// IssuedSinceFunc mocks the IssuedSince method.
+	IssuedSinceFunc func(scope string, userID int32, ttl time.Duration, since time.Time) (bool, error)
+
+	// NewFunc mocks the New method.
+	NewFunc func(userID int32, ttl time.Duration, scope string) (*data.Token, error)
+
+	// RevokeAllForUserFunc mocks the RevokeAllForUser method.
+	RevokeAllForUserFunc func(userID int32) (int64, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DeleteAllForUser holds details about calls to the DeleteAllForUser method.
+		DeleteAllForUser []struct {
+			// Scope is the scope argument value.
+			Scope string
+			// UserID is the userID argument value.
+			UserID int32
+		}
+		// GetUserID holds details about calls to the GetUserID method.
+		GetUserID []struct {
+			// Scope is the scope argument value.
+			Scope string
+			// TokenPlaintext is the tokenPlaintext argument value.
+			TokenPlaintext string
+		}
+		// Insert holds details about calls to the Insert method.
+		Insert []struct {
+			// Token is the token argument value.
+			Token *data.Token
+		}
+		// IssuedSince holds details about calls to the IssuedSince method.
+		IssuedSince []struct {
+			// Scope is the scope argument value.
+			Scope string
+			// UserID is the userID argument value.
+			UserID int32
+			// TTL is the ttl argument value.
+			TTL time.Duration
+			// Since is the since argument value.
+			Since time.Time
+		}
+		// New holds details about calls to the New method.
+		New []struct {
+			// UserID is the userID argument value.
+			UserID int32
+			// TTL is the ttl argument value.
+			TTL time.Duration
+			// Scope is the scope argument value.
+			Scope string
+		}
+		// RevokeAllForUser holds details about calls to the RevokeAllForUser method.
+		RevokeAllForUser []struct {
+			// UserID is the userID argument value.
+			UserID int32
+		}
+	}
+	lockDeleteAllForUser sync.RWMutex
+	lockGetUserID        sync.RWMutex
+	lockInsert           sync.RWMutex
+	lockIssuedSince      sync.RWMutex
+	lockNew              sync.RWMutex
+	lockRevokeAllForUser sync.RWMutex
+}
+
+// DeleteAllForUser calls DeleteAllForUserFunc.
+func (mock *TokenStoreMock) DeleteAllForUser(scope string, userID int32) error {
+	callInfo := struct {
+		Scope  string
+		UserID int32
+	}{
+		Scope:  scope,
+		UserID: userID,
+	}
+	mock.lockDeleteAllForUser.Lock()
+	mock.calls.DeleteAllForUser = append(mock.calls.DeleteAllForUser, callInfo)
+	mock.lockDeleteAllForUser.Unlock()
+	if mock.DeleteAllForUserFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.DeleteAllForUserFunc(scope, userID)
+}
+
+// DeleteAllForUserCalls gets all the calls that were made to DeleteAllForUser.
+// Check the length with:
+//
+//	len(mockedTokenStore.DeleteAllForUserCalls())
+func (mock *TokenStoreMock) DeleteAllForUserCalls() []struct {
+	Scope  string
+	UserID int32
+} {
+	var calls []struct {
+		Scope  string
+		UserID int32
+	}
+	mock.lockDeleteAllForUser.RLock()
+	calls = mock.calls.DeleteAllForUser
+	mock.lockDeleteAllForUser.RUnlock()
+	return calls
+}
+
+// GetUserID calls GetUserIDFunc.
+func (mock *TokenStoreMock) GetUserID(scope string, tokenPlaintext string) (int32, error) {
+	callInfo := struct {
+		Scope          string
+		TokenPlaintext string
+	}{
+		Scope:          scope,
+		TokenPlaintext: tokenPlaintext,
+	}
+	mock.lockGetUserID.Lock()
+	mock.calls.GetUserID = append(mock.calls.GetUserID, callInfo)
+	mock.lockGetUserID.Unlock()
+	if mock.GetUserIDFunc == nil {
+		var (
+			nOut   int32
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.GetUserIDFunc(scope, tokenPlaintext)
+}
+
+// GetUserIDCalls gets all the calls that were made to GetUserID.
+// Check the length with:
+//
+//	len(mockedTokenStore.GetUserIDCalls())
+func (mock *TokenStoreMock) GetUserIDCalls() []struct {
+	Scope          string
+	TokenPlaintext string
+} {
+	var calls []struct {
+		Scope          string
+		TokenPlaintext string
+	}
+	mock.lockGetUserID.RLock()
+	calls = mock.calls.GetUserID
+	mock.lockGetUserID.RUnlock()
+	return calls
+}
+
+// Insert calls InsertFunc.
+func (mock *TokenStoreMock) Insert(token *data.Token) error {
+	callInfo := struct {
+		Token *data.Token
+	}{
+		Token: token,
+	}
+	mock.lockInsert.Lock()
+	mock.calls.Insert = append(mock.calls.Insert, callInfo)
+	mock.lockInsert.Unlock()
+	if mock.InsertFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.InsertFunc(token)
+}
+
+// InsertCalls gets all the calls that were made to Insert.
+// Check the length with:
+//
+//	len(mockedTokenStore.InsertCalls())
+func (mock *TokenStoreMock) InsertCalls() []struct {
+	Token *data.Token
+} {
+	var calls []struct {
+		Token *data.Token
+	}
+	mock.lockInsert.RLock()
+	calls = mock.calls.Insert
+	mock.lockInsert.RUnlock()
+	return calls
+}
+
+// IssuedSince calls IssuedSinceFunc.
+func (mock *TokenStoreMock) IssuedSince(scope string, userID int32, ttl time.Duration, since time.Time) (bool, error) {
+	callInfo := struct {
+		Scope  string
+		UserID int32
+		TTL    time.Duration
+		Since  time.Time
+	}{
+		Scope:  scope,
+		UserID: userID,
+		TTL:    ttl,
+		Since:  since,
+	}
+	mock.lockIssuedSince.Lock()
+	mock.calls.IssuedSince = append(mock.calls.IssuedSince, callInfo)
+	mock.lockIssuedSince.Unlock()
+	if mock.IssuedSinceFunc == nil {
+		var (
+			bOut   bool
+			errOut error
+		)
+		return bOut, errOut
+	}
+	return mock.IssuedSinceFunc(scope, userID, ttl, since)
+}
+
+// IssuedSinceCalls gets all the calls that were made to IssuedSince.
+// Check the length with:
+//
+//	len(mockedTokenStore.IssuedSinceCalls())
+func (mock *TokenStoreMock) IssuedSinceCalls() []struct {
+	Scope  string
+	UserID int32
+	TTL    time.Duration
+	Since  time.Time
+} {
+	var calls []struct {
+		Scope  string
+		UserID int32
+		TTL    time.Duration
+		Since  time.Time
+	}
+	mock.lockIssuedSince.RLock()
+	calls = mock.calls.IssuedSince
+	mock.lockIssuedSince.RUnlock()
+	return calls
+}
+
+// New calls NewFunc.
+func (mock *TokenStoreMock) New(userID int32, ttl time.Duration, scope string) (*data.Token, error) {
+	callInfo := struct {
+		UserID int32
+		TTL    time.Duration
+		Scope  string
+	}{
+		UserID: userID,
+		TTL:    ttl,
+		Scope:  scope,
+	}
+	mock.lockNew.Lock()
+	mock.calls.New = append(mock.calls.New, callInfo)
+	mock.lockNew.Unlock()
+	if mock.NewFunc == nil {
+		var (
+			tokenOut *data.Token
+			errOut   error
+		)
+		return tokenOut, errOut
+	}
+	return mock.NewFunc(userID, ttl, scope)
+}
+
+// NewCalls gets all the calls that were made to New.
+// Check the length with:
+//
+//	len(mockedTokenStore.NewCalls())
+func (mock *TokenStoreMock) NewCalls() []struct {
+	UserID int32
+	TTL    time.Duration
+	Scope  string
+} {
+	var calls []struct {
+		UserID int32
+		TTL    time.Duration
+		Scope  string
+	}
+	mock.lockNew.RLock()
+	calls = mock.calls.New
+	mock.lockNew.RUnlock()
+	return calls
+}
+
+// RevokeAllForUser calls RevokeAllForUserFunc.
+func (mock *TokenStoreMock) RevokeAllForUser(userID int32) (int64, error) {
+	callInfo := struct {
+		UserID int32
+	}{
+		UserID: userID,
+	}
+	mock.lockRevokeAllForUser.Lock()
+	mock.calls.RevokeAllForUser = append(mock.calls.RevokeAllForUser, callInfo)
+	mock.lockRevokeAllForUser.Unlock()
+	if mock.RevokeAllForUserFunc == nil {
+		var (
+			nOut   int64
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.RevokeAllForUserFunc(userID)
+}
+
+// RevokeAllForUserCalls gets all the calls that were made to RevokeAllForUser.
+// Check the length with:
+//
+//	len(mockedTokenStore.RevokeAllForUserCalls())
+func (mock *TokenStoreMock) RevokeAllForUserCalls() []struct {
+	UserID int32
+} {
+	var calls []struct {
+		UserID int32
+	}
+	mock.lockRevokeAllForUser.RLock()
+	calls = mock.calls.RevokeAllForUser
+	mock.lockRevokeAllForUser.RUnlock()
+	return calls
+}
+
+// Ensure, that TypeaheadStoreMock does implement data.TypeaheadStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.TypeaheadStore = &TypeaheadStoreMock{}
+
+// TypeaheadStoreMock is a mock implementation of data.TypeaheadStore.
+//
+//	func TestSomethingThatUsesTypeaheadStore(t *testing.T) {
+//
+//		// make and configure a mocked data.TypeaheadStore
+//		mockedTypeaheadStore := &TypeaheadStoreMock{
+//			SearchFunc: func(actor data.Actor, entity string, q string) ([]data.TypeaheadOption, error) {
+//				panic("mock out the Search method")
+//			},
+//		}
+//
+//		// use mockedTypeaheadStore in code that requires data.TypeaheadStore
+//		// and then make assertions.
+//
+//	}
+type TypeaheadStoreMock struct {
+	// SearchFunc mocks the Search method.
+	SearchFunc func(actor data.Actor, entity string, q string) ([]data.TypeaheadOption, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Search holds details about calls to the Search method.
+		Search []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Entity is the entity argument value.
+			Entity string
+			// Q is the q argument value.
+			Q string
+		}
+	}
+	lockSearch sync.RWMutex
+}
+
+// Search calls SearchFunc.
+func (mock *TypeaheadStoreMock) Search(actor data.Actor, entity string, q string) ([]data.TypeaheadOption, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		Entity string
+		Q      string
+	}{
+		Actor:  actor,
+		Entity: entity,
+		Q:      q,
+	}
+	mock.lockSearch.Lock()
+	mock.calls.Search = append(mock.calls.Search, callInfo)
+	mock.lockSearch.Unlock()
+	if mock.SearchFunc == nil {
+		var (
+			typeaheadOptionsOut []data.TypeaheadOption
+			errOut              error
+		)
+		return typeaheadOptionsOut, errOut
+	}
+	return mock.SearchFunc(actor, entity, q)
+}
+
+// SearchCalls gets all the calls that were made to Search.
+// Check the length with:
+//
+//	len(mockedTypeaheadStore.SearchCalls())
+func (mock *TypeaheadStoreMock) SearchCalls() []struct {
+	Actor  data.Actor
+	Entity string
+	Q      string
+} {
+	var calls []struct {
+		Actor  data.Actor
+		Entity string
+		Q      string
+	}
+	mock.lockSearch.RLock()
+	calls = mock.calls.Search
+	mock.lockSearch.RUnlock()
+	return calls
+}
+
+// Ensure, that UserStoreMock does implement data.UserStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.UserStore = &UserStoreMock{}
+
+// UserStoreMock is a mock implementation of data.UserStore.
+//
+//	func TestSomethingThatUsesUserStore(t *testing.T) {
+//
+//		// make and configure a mocked data.UserStore
+//		mockedUserStore := &UserStoreMock{
+//			ConfirmEmailFunc: func(user *data.User) (string, error) {
+//				panic("mock out the ConfirmEmail method")
+//			},
+//			EraseFunc: func(user *data.User) error {
+//				panic("mock out the Erase method")
+//			},
+//			GetFunc: func(id int32) (*data.User, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetAllFunc: func(actor data.Actor, teamID int32, filters data.Filters) ([]*data.User, data.Metadata, error) {
+//				panic("mock out the GetAll method")
+//			},
+//			GetAllByEmailsFunc: func(actor data.Actor, emails []string) (map[string]*data.User, error) {
+//				panic("mock out the GetAllByEmails method")
+//			},
+//			GetByEmailFunc: func(email string) (*data.User, error) {
+//				panic("mock out the GetByEmail method")
+//			},
+//			GetForActorFunc: func(actor data.Actor, id int32) (*data.User, error) {
+//				panic("mock out the GetForActor method")
+//			},
+//			GetLanguageFunc: func(id int32) (string, error) {
+//				panic("mock out the GetLanguage method")
+//			},
+//			ImportFunc: func(orgID int32, users []*data.User, roles map[string]string) ([]*data.User, error) {
+//				panic("mock out the Import method")
+//			},
+//			PurgeUnactivatedFunc: func(cutoff time.Time, dryRun bool) (int64, error) {
+//				panic("mock out the PurgeUnactivated method")
+//			},
+//			SetHourlyCostFunc: func(actor data.Actor, id int32, cost *float64) error {
+//				panic("mock out the SetHourlyCost method")
+//			},
+//			SetLanguageFunc: func(id int32, language string) error {
+//				panic("mock out the SetLanguage method")
+//			},
+//			SetPendingEmailFunc: func(id int32, email string) error {
+//				panic("mock out the SetPendingEmail method")
+//			},
+//			UpdateAvatarKeyFunc: func(user *data.User) error {
+//				panic("mock out the UpdateAvatarKey method")
+//			},
+//		}
+//
+//		// use mockedUserStore in code that requires data.UserStore
+//		// and then make assertions.
+//
+//	}
+type UserStoreMock struct {
+	// ConfirmEmailFunc mocks the ConfirmEmail method.
+	ConfirmEmailFunc func(user *data.User) (string, error)
+
+	// EraseFunc mocks the Erase method.
+	EraseFunc func(user *data.User) error
+
+	// GetFunc mocks the Get method.
+	GetFunc func(id int32) (*data.User, error)
+
+	// GetAllFunc mocks the GetAll method.
+	GetAllFunc func(actor data.Actor, teamID int32, filters data.Filters) ([]*data.User, data.Metadata, error)
+
+	// GetAllByEmailsFunc mocks the GetAllByEmails method.
+	GetAllByEmailsFunc func(actor data.Actor, emails []string) (map[string]*data.User, error)
+
+	// GetByEmailFunc mocks the GetByEmail method.
+	GetByEmailFunc func(email string) (*data.User, error)
+
+	// GetForActorFunc mocks the GetForActor method.
+	GetForActorFunc func(actor data.Actor, id int32) (*data.User, error)
+
+	// GetLanguageFunc mocks the GetLanguage method.
+	GetLanguageFunc func(id int32) (string, error)
+
+	// ImportFunc mocks the Import method.
+	ImportFunc func(orgID int32, users []*data.User, roles map[string]string) ([]*data.User, error)
+
+	// PurgeUnactivatedFunc mocks the PurgeUnactivated method.
+	PurgeUnactivatedFunc func(cutoff time.Time, dryRun bool) (int64, error)
+
+	// SetHourlyCostFunc mocks the SetHourlyCost method.
+	SetHourlyCostFunc func(actor data.Actor, id int32, cost *float64) error
+
+	// SetLanguageFunc mocks the SetLanguage method.
+	SetLanguageFunc func(id int32, language string) error
+
+	// SetPendingEmailFunc mocks the SetPendingEmail method.
+	SetPendingEmailFunc func(id int32, email string) error
+
+	// UpdateAvatarKeyFunc mocks the UpdateAvatarKey method.
+	UpdateAvatarKeyFunc func(user *data.User) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ConfirmEmail holds details about calls to the ConfirmEmail method.
+		ConfirmEmail []struct {
+			// User is the user argument value.
+			User *data.User
+		}
+		// Erase holds details about calls to the Erase method.
+		Erase []struct {
+			// User is the user argument value.
+			User *data.User
+		}
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetAll holds details about calls to the GetAll method.
+		GetAll []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// TeamID is the teamID argument value.
+			TeamID int32
+			// Filters is the filters argument value.
+			Filters data.Filters
+		}
+		// GetAllByEmails holds details about calls to the GetAllByEmails method.
+		GetAllByEmails []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// Emails is the emails argument value.
+			Emails []string
+		}
+		// GetByEmail holds details about calls to the GetByEmail method.
+		GetByEmail []struct {
+			// Email is the email argument value.
+			Email string
+		}
+		// GetForActor holds details about calls to the GetForActor method.
+		GetForActor []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+		}
+		// GetLanguage holds details about calls to the GetLanguage method.
+		GetLanguage []struct {
+			// ID is the id argument value.
+			ID int32
+		}
+		// Import holds details about calls to the Import method.
+		Import []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+			// Users is the users argument value.
+			Users []*data.User
+			// Roles is the roles argument value.
+			Roles map[string]string
+		}
+		// PurgeUnactivated holds details about calls to the PurgeUnactivated method.
+		PurgeUnactivated []struct {
+			// Cutoff is the cutoff argument value.
+			Cutoff time.Time
+			// DryRun is the dryRun argument value.
+			DryRun bool
+		}
+		// SetHourlyCost holds details about calls to the SetHourlyCost method.
+		SetHourlyCost []struct {
+			// Actor is the actor argument value.
+			Actor data.Actor
+			// ID is the id argument value.
+			ID int32
+			// Cost is the cost argument value.
+			Cost *float64
+		}
+		// SetLanguage holds details about calls to the SetLanguage method.
+		SetLanguage []struct {
+			// ID is the id argument value.
+			ID int32
+			// Language is the language argument value.
+			Language string
+		}
+		// SetPendingEmail holds details about calls to the SetPendingEmail method.
+		SetPendingEmail []struct {
+			// ID is the id argument value.
+			ID int32
+			// Email is the email argument value.
+			Email string
+		}
+		// UpdateAvatarKey holds details about calls to the UpdateAvatarKey method.
+		UpdateAvatarKey []struct {
+			// User is the user argument value.
+			User *data.User
+		}
+	}
+	lockConfirmEmail     sync.RWMutex
+	lockErase            sync.RWMutex
+	lockGet              sync.RWMutex
+	lockGetAll           sync.RWMutex
+	lockGetAllByEmails   sync.RWMutex
+	lockGetByEmail       sync.RWMutex
+	lockGetForActor      sync.RWMutex
+	lockGetLanguage      sync.RWMutex
+	lockImport           sync.RWMutex
+	lockPurgeUnactivated sync.RWMutex
+	lockSetHourlyCost    sync.RWMutex
+	lockSetLanguage      sync.RWMutex
+	lockSetPendingEmail  sync.RWMutex
+	lockUpdateAvatarKey  sync.RWMutex
+}
+
+// ConfirmEmail calls ConfirmEmailFunc.
+func (mock *UserStoreMock) ConfirmEmail(user *data.User) (string, error) {
+	callInfo := struct {
+		User *data.User
+	}{
+		User: user,
+	}
+	mock.lockConfirmEmail.Lock()
+	mock.calls.ConfirmEmail = append(mock.calls.ConfirmEmail, callInfo)
+	mock.lockConfirmEmail.Unlock()
+	if mock.ConfirmEmailFunc == nil {
+		var (
+			sOut   string
+			errOut error
+		)
+		return sOut, errOut
+	}
+	return mock.ConfirmEmailFunc(user)
+}
+
+// ConfirmEmailCalls gets all the calls that were made to ConfirmEmail.
+// Check the length with:
+//
+//	len(mockedUserStore.ConfirmEmailCalls())
+func (mock *UserStoreMock) ConfirmEmailCalls() []struct {
+	User *data.User
+} {
+	var calls []struct {
+		User *data.User
+	}
+	mock.lockConfirmEmail.RLock()
+	calls = mock.calls.ConfirmEmail
+	mock.lockConfirmEmail.RUnlock()
+	return calls
+}
+
+// Erase calls EraseFunc.
+func (mock *UserStoreMock) Erase(user *data.User) error {
+	callInfo := struct {
+		User *data.User
+	}{
+		User: user,
+	}
+	mock.lockErase.Lock()
+	mock.calls.Erase = append(mock.calls.Erase, callInfo)
+	mock.lockErase.Unlock()
+	if mock.EraseFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.EraseFunc(user)
+}
+
+// EraseCalls gets all the calls that were made to Erase.
+// Check the length with:
+//
+//	len(mockedUserStore.EraseCalls())
+func (mock *UserStoreMock) EraseCalls() []struct {
+	User *data.User
+} {
+	var calls []struct {
+		User *data.User
+	}
+	mock.lockErase.RLock()
+	calls = mock.calls.Erase
+	mock.lockErase.RUnlock()
+	return calls
+}
+
+// Get calls GetFunc.
+func (mock *UserStoreMock) Get(id int32) (*data.User, error) {
+	callInfo := struct {
+		ID int32
+	}{
+		ID: id,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			userOut *data.User
+			errOut  error
+		)
+		return userOut, errOut
+	}
+	return mock.GetFunc(id)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedUserStore.GetCalls())
+func (mock *UserStoreMock) GetCalls() []struct {
+	ID int32
+} {
+	var calls []struct {
+		ID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetAll calls GetAllFunc.
+func (mock *UserStoreMock) GetAll(actor data.Actor, teamID int32, filters data.Filters) ([]*data.User, data.Metadata, error) {
+	callInfo := struct {
+		Actor   data.Actor
+		TeamID  int32
+		Filters data.Filters
+	}{
+		Actor:   actor,
+		TeamID:  teamID,
+		Filters: filters,
+	}
+	mock.lockGetAll.Lock()
+	mock.calls.GetAll = append(mock.calls.GetAll, callInfo)
+	mock.lockGetAll.Unlock()
+	if mock.GetAllFunc == nil {
+		var (
+			usersOut    []*data.User
+			metadataOut data.Metadata
+			errOut      error
+		)
+		return usersOut, metadataOut, errOut
+	}
+	return mock.GetAllFunc(actor, teamID, filters)
+}
+
+// GetAllCalls gets all the calls that were made to GetAll.
+// Check the length with:
+//
+//	len(mockedUserStore.GetAllCalls())
+func (mock *UserStoreMock) GetAllCalls() []struct {
+	Actor   data.Actor
+	TeamID  int32
+	Filters data.Filters
+} {
+	var calls []struct {
+		Actor   data.Actor
+		TeamID  int32
+		Filters data.Filters
+	}
+	mock.lockGetAll.RLock()
+	calls = mock.calls.GetAll
+	mock.lockGetAll.RUnlock()
+	return calls
+}
+
+// GetAllByEmails calls GetAllByEmailsFunc.
+func (mock *UserStoreMock) GetAllByEmails(actor data.Actor, emails []string) (map[string]*data.User, error) {
+	callInfo := struct {
+		Actor  data.Actor
+		Emails []string
+	}{
+		Actor:  actor,
+		Emails: emails,
+	}
+	mock.lockGetAllByEmails.Lock()
+	mock.calls.GetAllByEmails = append(mock.calls.GetAllByEmails, callInfo)
+	mock.lockGetAllByEmails.Unlock()
+	if mock.GetAllByEmailsFunc == nil {
+		var (
+			stringToUserOut map[string]*data.User
+			errOut          error
+		)
+		return stringToUserOut, errOut
+	}
+	return mock.GetAllByEmailsFunc(actor, emails)
+}
+
+// GetAllByEmailsCalls gets all the calls that were made to GetAllByEmails.
+// Check the length with:
+//
+//	len(mockedUserStore.GetAllByEmailsCalls())
+func (mock *UserStoreMock) GetAllByEmailsCalls() []struct {
+	Actor  data.Actor
+	Emails []string
+} {
+	var calls []struct {
+		Actor  data.Actor
+		Emails []string
+	}
+	mock.lockGetAllByEmails.RLock()
+	calls = mock.calls.GetAllByEmails
+	mock.lockGetAllByEmails.RUnlock()
+	return calls
+}
+
+// GetByEmail calls GetByEmailFunc.
+func (mock *UserStoreMock) GetByEmail(email string) (*data.User, error) {
+	callInfo := struct {
+		Email string
+	}{
+		Email: email,
+	}
+	mock.lockGetByEmail.Lock()
+	mock.calls.GetByEmail = append(mock.calls.GetByEmail, callInfo)
+	mock.lockGetByEmail.Unlock()
+	if mock.GetByEmailFunc == nil {
+		var (
+			userOut *data.User
+			errOut  error
+		)
+		return userOut, errOut
+	}
+	return mock.GetByEmailFunc(email)
+}
+
+// GetByEmailCalls gets all the calls that were made to GetByEmail.
+// Check the length with:
+//
+//	len(mockedUserStore.GetByEmailCalls())
+func (mock *UserStoreMock) GetByEmailCalls() []struct {
+	Email string
+} {
+	var calls []struct {
+		Email string
+	}
+	mock.lockGetByEmail.RLock()
+	calls = mock.calls.GetByEmail
+	mock.lockGetByEmail.RUnlock()
+	return calls
+}
+
+// GetForActor calls GetForActorFunc.
+func (mock *UserStoreMock) GetForActor(actor data.Actor, id int32) (*data.User, error) {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+	}{
+		Actor: actor,
+		ID:    id,
+	}
+	mock.lockGetForActor.Lock()
+	mock.calls.GetForActor = append(mock.calls.GetForActor, callInfo)
+	mock.lockGetForActor.Unlock()
+	if mock.GetForActorFunc == nil {
+		var (
+			userOut *data.User
+			errOut  error
+		)
+		return userOut, errOut
+	}
+	return mock.GetForActorFunc(actor, id)
+}
+
+// GetForActorCalls gets all the calls that were made to GetForActor.
+// Check the length with:
+//
+//	len(mockedUserStore.GetForActorCalls())
+func (mock *UserStoreMock) GetForActorCalls() []struct {
+	Actor data.Actor
+	ID    int32
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+	}
+	mock.lockGetForActor.RLock()
+	calls = mock.calls.GetForActor
+	mock.lockGetForActor.RUnlock()
+	return calls
+}
+
+// GetLanguage calls GetLanguageFunc.
+func (mock *UserStoreMock) GetLanguage(id int32) (string, error) {
+	callInfo := struct {
+		ID int32
+	}{
+		ID: id,
+	}
+	mock.lockGetLanguage.Lock()
+	mock.calls.GetLanguage = append(mock.calls.GetLanguage, callInfo)
+	mock.lockGetLanguage.Unlock()
+	if mock.GetLanguageFunc == nil {
+		var (
+			sOut   string
+			errOut error
+		)
+		return sOut, errOut
+	}
+	return mock.GetLanguageFunc(id)
+}
+
+// GetLanguageCalls gets all the calls that were made to GetLanguage.
+// Check the length with:
+//
+//	len(mockedUserStore.GetLanguageCalls())
+func (mock *UserStoreMock) GetLanguageCalls() []struct {
+	ID int32
+} {
+	var calls []struct {
+		ID int32
+	}
+	mock.lockGetLanguage.RLock()
+	calls = mock.calls.GetLanguage
+	mock.lockGetLanguage.RUnlock()
+	return calls
+}
+
+// Import calls ImportFunc.
+func (mock *UserStoreMock) Import(orgID int32, users []*data.User, roles map[string]string) ([]*data.User, error) {
+	callInfo := struct {
+		OrgID int32
+		Users []*data.User
+		Roles map[string]string
+	}{
+		OrgID: orgID,
+		Users: users,
+		Roles: roles,
+	}
+	mock.lockImport.Lock()
+	mock.calls.Import = append(mock.calls.Import, callInfo)
+	mock.lockImport.Unlock()
+	if mock.ImportFunc == nil {
+		var (
+			usersOut []*data.User
+			errOut   error
+		)
+		return usersOut, errOut
+	}
+	return mock.ImportFunc(orgID, users, roles)
+}
+
+// ImportCalls gets all the calls that were made to Import.
+// Check the length with:
+//
+//	len(mockedUserStore.ImportCalls())
+func (mock *UserStoreMock) ImportCalls() []struct {
+	OrgID int32
+	Users []*data.User
+	Roles map[string]string
+} {
+	var calls []struct {
+		OrgID int32
+		Users []*data.User
+		Roles map[string]string
+	}
+	mock.lockImport.RLock()
+	calls = mock.calls.Import
+	mock.lockImport.RUnlock()
+	return calls
+}
+
+// PurgeUnactivated calls PurgeUnactivatedFunc.
+func (mock *UserStoreMock) PurgeUnactivated(cutoff time.Time, dryRun bool) (int64, error) {
+	callInfo := struct {
+		Cutoff time.Time
+		DryRun bool
+	}{
+		Cutoff: cutoff,
+		DryRun: dryRun,
+	}
+	mock.lockPurgeUnactivated.Lock()
+	mock.calls.PurgeUnactivated = append(mock.calls.PurgeUnactivated, callInfo)
+	mock.lockPurgeUnactivated.Unlock()
+	if mock.PurgeUnactivatedFunc == nil {
+		var (
+			nOut   int64
+			errOut error
+		)
+		return nOut, errOut
+	}
+	return mock.PurgeUnactivatedFunc(cutoff, dryRun)
+}
+
+// PurgeUnactivatedCalls gets all the calls that were made to PurgeUnactivated.
+// Check the length with:
+//
+//	len(mockedUserStore.PurgeUnactivatedCalls())
+func (mock *UserStoreMock) PurgeUnactivatedCalls() []struct {
+	Cutoff time.Time
+	DryRun bool
+} {
+	var calls []struct {
+		Cutoff time.Time
+		DryRun bool
+	}
+	mock.lockPurgeUnactivated.RLock()
+	calls = mock.calls.PurgeUnactivated
+	mock.lockPurgeUnactivated.RUnlock()
+	return calls
+}
+
+// SetHourlyCost calls SetHourlyCostFunc.
+func (mock *UserStoreMock) SetHourlyCost(actor data.Actor, id int32, cost *float64) error {
+	callInfo := struct {
+		Actor data.Actor
+		ID    int32
+		Cost  *float64
+	}{
+		Actor: actor,
+		ID:    id,
+		Cost:  cost,
+	}
+	mock.lockSetHourlyCost.Lock()
+	mock.calls.SetHourlyCost = append(mock.calls.SetHourlyCost, callInfo)
+	mock.lockSetHourlyCost.Unlock()
+	if mock.SetHourlyCostFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetHourlyCostFunc(actor, id, cost)
+}
+
+// SetHourlyCostCalls gets all the calls that were made to SetHourlyCost.
+// Check the length with:
+//
+//	len(mockedUserStore.SetHourlyCostCalls())
+func (mock *UserStoreMock) SetHourlyCostCalls() []struct {
+	Actor data.Actor
+	ID    int32
+	Cost  *float64
+} {
+	var calls []struct {
+		Actor data.Actor
+		ID    int32
+		Cost  *float64
+	}
+	mock.lockSetHourlyCost.RLock()
+	calls = mock.calls.SetHourlyCost
+	mock.lockSetHourlyCost.RUnlock()
+	return calls
+}
+
+// SetLanguage calls SetLanguageFunc.
+func (mock *UserStoreMock) SetLanguage(id int32, language string) error {
+	callInfo := struct {
+		ID       int32
+		Language string
+	}{
+		ID:       id,
+		Language: language,
+	}
+	mock.lockSetLanguage.Lock()
+	mock.calls.SetLanguage = append(mock.calls.SetLanguage, callInfo)
+	mock.lockSetLanguage.Unlock()
+	if mock.SetLanguageFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetLanguageFunc(id, language)
+}
+
+// SetLanguageCalls gets all the calls that were made to SetLanguage.
+// Check the length with:
+//
+//	len(mockedUserStore.SetLanguageCalls())
+func (mock *UserStoreMock) SetLanguageCalls() []struct {
+	ID       int32
+	Language string
+} {
+	var calls []struct {
+		ID       int32
+		Language string
+	}
+	mock.lockSetLanguage.RLock()
+	calls = mock.calls.SetLanguage
+	mock.lockSetLanguage.RUnlock()
+	return calls
+}
+
+// SetPendingEmail calls SetPendingEmailFunc.
+func (mock *UserStoreMock) SetPendingEmail(id int32, email string) error {
+	callInfo := struct {
+		ID    int32
+		Email string
+	}{
+		ID:    id,
+		Email: email,
+	}
+	mock.lockSetPendingEmail.Lock()
+	mock.calls.SetPendingEmail = append(mock.calls.SetPendingEmail, callInfo)
+	mock.lockSetPendingEmail.Unlock()
+	if mock.SetPendingEmailFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.SetPendingEmailFunc(id, email)
+}
+
+// SetPendingEmailCalls gets all the calls that were made to SetPendingEmail.
+// Check the length with:
+//
+//	len(mockedUserStore.SetPendingEmailCalls())
+func (mock *UserStoreMock) SetPendingEmailCalls() []struct {
+	ID    int32
+	Email string
+} {
+	var calls []struct {
+		ID    int32
+		Email string
+	}
+	mock.lockSetPendingEmail.RLock()
+	calls = mock.calls.SetPendingEmail
+	mock.lockSetPendingEmail.RUnlock()
+	return calls
+}
+
+// UpdateAvatarKey calls UpdateAvatarKeyFunc.
+func (mock *UserStoreMock) UpdateAvatarKey(user *data.User) error {
+	callInfo := struct {
+		User *data.User
+	}{
+		User: user,
+	}
+	mock.lockUpdateAvatarKey.Lock()
+	mock.calls.UpdateAvatarKey = append(mock.calls.UpdateAvatarKey, callInfo)
+	mock.lockUpdateAvatarKey.Unlock()
+	if mock.UpdateAvatarKeyFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateAvatarKeyFunc(user)
+}
+
+// UpdateAvatarKeyCalls gets all the calls that were made to UpdateAvatarKey.
+// Check the length with:
+//
+//	len(mockedUserStore.UpdateAvatarKeyCalls())
+func (mock *UserStoreMock) UpdateAvatarKeyCalls() []struct {
+	User *data.User
+} {
+	var calls []struct {
+		User *data.User
+	}
+	mock.lockUpdateAvatarKey.RLock()
+	calls = mock.calls.UpdateAvatarKey
+	mock.lockUpdateAvatarKey.RUnlock()
+	return calls
+}
+
+// Ensure, that WorkRulesStoreMock does implement data.WorkRulesStore.
+// If this is not the case, regenerate this file with moq.
+var _ data.WorkRulesStore = &WorkRulesStoreMock{}
+
+// WorkRulesStoreMock is a mock implementation of data.WorkRulesStore.
+//
+//	func TestSomethingThatUsesWorkRulesStore(t *testing.T) {
+//
+//		// make and configure a mocked data.WorkRulesStore
+//		mockedWorkRulesStore := &WorkRulesStoreMock{
+//			GetFunc: func(orgID int32) (*data.WorkRules, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetForUsersFunc: func(userIDs []int32) (map[int32]*data.WorkRules, error) {
+//				panic("mock out the GetForUsers method")
+//			},
+//			UpdateFunc: func(r *data.WorkRules) error {
+//				panic("mock out the Update method")
+//			},
+//		}
+//
+//		// use mockedWorkRulesStore in code that requires data.WorkRulesStore
+//		// and then make assertions.
+//
+//	}
+type WorkRulesStoreMock struct {
+	// GetFunc mocks the Get method.
+	GetFunc func(orgID int32) (*data.WorkRules, error)
+
+	// GetForUsersFunc mocks the GetForUsers method.
+	GetForUsersFunc func(userIDs []int32) (map[int32]*data.WorkRules, error)
+
+	// UpdateFunc mocks the Update method.
+	UpdateFunc func(r *data.WorkRules) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Get holds details about calls to the Get method.
+		Get []struct {
+			// OrgID is the orgID argument value.
+			OrgID int32
+		}
+		// GetForUsers holds details about calls to the GetForUsers method.
+		GetForUsers []struct {
+			// UserIDs is the userIDs argument value.
+			UserIDs []int32
+		}
+		// Update holds details about calls to the Update method.
+		Update []struct {
+			// R is the r argument value.
+			R *data.WorkRules
+		}
+	}
+	lockGet         sync.RWMutex
+	lockGetForUsers sync.RWMutex
+	lockUpdate      sync.RWMutex
+}
+
+// Get calls GetFunc.
+func (mock *WorkRulesStoreMock) Get(orgID int32) (*data.WorkRules, error) {
+	callInfo := struct {
+		OrgID int32
+	}{
+		OrgID: orgID,
+	}
+	mock.lockGet.Lock()
+	mock.calls.Get = append(mock.calls.Get, callInfo)
+	mock.lockGet.Unlock()
+	if mock.GetFunc == nil {
+		var (
+			workRulesOut *data.WorkRules
+			errOut       error
+		)
+		return workRulesOut, errOut
+	}
+	return mock.GetFunc(orgID)
+}
+
+// GetCalls gets all the calls that were made to Get.
+// Check the length with:
+//
+//	len(mockedWorkRulesStore.GetCalls())
+func (mock *WorkRulesStoreMock) GetCalls() []struct {
+	OrgID int32
+} {
+	var calls []struct {
+		OrgID int32
+	}
+	mock.lockGet.RLock()
+	calls = mock.calls.Get
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// GetForUsers calls GetForUsersFunc.
+func (mock *WorkRulesStoreMock) GetForUsers(userIDs []int32) (map[int32]*data.WorkRules, error) {
+	callInfo := struct {
+		UserIDs []int32
+	}{
+		UserIDs: userIDs,
+	}
+	mock.lockGetForUsers.Lock()
+	mock.calls.GetForUsers = append(mock.calls.GetForUsers, callInfo)
+	mock.lockGetForUsers.Unlock()
+	if mock.GetForUsersFunc == nil {
+		var (
+			int32ToWorkRulesOut map[int32]*data.WorkRules
+			errOut              error
+		)
+		return int32ToWorkRulesOut, errOut
+	}
+	return mock.GetForUsersFunc(userIDs)
+}
+
+// GetForUsersCalls gets all the calls that were made to GetForUsers.
+// Check the length with:
+//
+//	len(mockedWorkRulesStore.GetForUsersCalls())
+func (mock *WorkRulesStoreMock) GetForUsersCalls() []struct {
+	UserIDs []int32
+} {
+	var calls []struct {
+		UserIDs []int32
+	}
+	mock.lockGetForUsers.RLock()
+	calls = mock.calls.GetForUsers
+	mock.lockGetForUsers.RUnlock()
+	return calls
+}
+
+// Update calls UpdateFunc.
+func (mock *WorkRulesStoreMock) Update(r *data.WorkRules) error {
+	callInfo := struct {
+		R *data.WorkRules
+	}{
+		R: r,
+	}
+	mock.lockUpdate.Lock()
+	mock.calls.Update = append(mock.calls.Update, callInfo)
+	mock.lockUpdate.Unlock()
+	if mock.UpdateFunc == nil {
+		var (
+			errOut error
+		)
+		return errOut
+	}
+	return mock.UpdateFunc(r)
+}
+
+// UpdateCalls gets all the calls that were made to Update.
+// Check the length with:
+//
+//	len(mockedWorkRulesStore.UpdateCalls())
+func (mock *WorkRulesStoreMock) UpdateCalls() []struct {
+	R *data.WorkRules
+} {
+	var calls []struct {
+		R *data.WorkRules
+	}
+	mock.lockUpdate.RLock()
+	calls = mock.calls.Update
+	mock.lockUpdate.RUnlock()
+	return calls
+}
@@ -0,0 +1,463 @@
+package docs
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Object documents a JSON object inline. The Go type of each value gives
+// the schema of the property of the same name, so
+//
+//	Object{"project": data.ProjectResponse{}, "tags": []string{}}
+//
+// describes {"project": {...}, "tags": [...]}.
+type Object map[string]any
+
+// Schema is a raw OpenAPI schema, for values reflection cannot describe,
+// such as binary bodies or properties that need an example.
+type Schema map[string]any
+
+// Parameter documents a query, path or header parameter.
+type Parameter struct {
+	Name string
+	// In is query, path or header, query when empty.
+	In          string
+	Description string
+	// Type is the JSON schema type, string when empty.
+	Type     string
+	Format   string
+	Required bool
+	Default  any
+	Example  any
+}
+
+// Response documents one status an operation answers with. Body is a value
+// whose type gives the schema, as for Object values, or nil for no body.
+type Response struct {
+	Status      int
+	Description string
+	Body        any
+	// ContentType is application/json when empty.
+	ContentType string
+	// Headers maps response header names to their descriptions.
+	Headers map[string]string
+}
+
+// Operation annotates a route with what the router cannot tell.
+type Operation struct {
+	Summary            string
+	Description        string
+	Tags               []string
+	Parameters         []Parameter
+	Request            any
+	RequestDescription string
+	Responses          []Response
+}
+
+// Route is a registered route as the router reports it, with its
+// annotation when it has one.
+type Route struct {
+	Method         string
+	Path           string
+	Authentication bool
+	Deprecated     bool
+	Operation      *Operation
+}
+
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+	Server      string
+	// TypeDescriptions describes component schemas by Go type name.
+	TypeDescriptions map[string]string
+	// Schemas are component schemas added as they are, for formats no Go
+	// type describes.
+	Schemas map[string]Schema
+}
+
+var pathParamRX = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Generate builds an OpenAPI 3.1 document describing routes, as JSON. Every
+// route appears whether annotated or not: path parameters an operation does
+// not document are added as integers, and schemas are reflected from the Go
+// types of request and response bodies. Struct fields may carry doc and
+// example tags, the latter holding JSON.
+func Generate(info Info, routes []Route) ([]byte, error) {
+	g := &generator{
+		schemas:      map[string]any{},
+		names:        map[reflect.Type]string{},
+		descriptions: info.TypeDescriptions,
+	}
+
+	for name, schema := range info.Schemas {
+		g.schemas[name] = map[string]any(schema)
+	}
+
+	paths := map[string]map[string]any{}
+
+	for _, route := range routes {
+		path := pathParamRX.ReplaceAllString(route.Path, "{$1}")
+
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+
+		paths[path][strings.ToLower(route.Method)] = g.operation(route, path)
+	}
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"servers": []any{map[string]any{"url": info.Server}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+
+	return json.MarshalIndent(doc, "", "\t")
+}
+
+type generator struct {
+	schemas      map[string]any
+	names        map[reflect.Type]string
+	descriptions map[string]string
+}
+
+func (g *generator) operation(route Route, path string) map[string]any {
+	op := route.Operation
+	if op == nil {
+		op = &Operation{}
+	}
+
+	out := map[string]any{}
+
+	if op.Summary != "" {
+		out["summary"] = op.Summary
+	}
+	if op.Description != "" {
+		out["description"] = op.Description
+	}
+
+	tags := op.Tags
+	if len(tags) == 0 {
+		tags = []string{defaultTag(path)}
+	}
+	out["tags"] = tags
+
+	params := []any{}
+	documented := map[string]bool{}
+
+	for _, p := range op.Parameters {
+		in := p.In
+		if in == "" {
+			in = "query"
+		}
+		documented[in+" "+p.Name] = true
+
+		params = append(params, parameter(p, in))
+	}
+
+	for _, m := range pathParamRX.FindAllStringSubmatch(path, -1) {
+		if !documented["path "+m[1]] {
+			params = append(params, parameter(Parameter{Name: m[1], Type: "integer", Format: "int32"}, "path"))
+		}
+	}
+
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.Request != nil {
+		body := map[string]any{
+			"content": map[string]any{"application/json": map[string]any{"schema": g.value(op.Request)}},
+		}
+		if op.RequestDescription != "" {
+			body["description"] = op.RequestDescription
+		}
+		out["requestBody"] = body
+	}
+
+	responses := map[string]any{}
+	for _, resp := range op.Responses {
+		responses[strconv.Itoa(resp.Status)] = g.response(resp)
+	}
+	if len(responses) == 0 {
+		responses["200"] = map[string]any{"description": "Successful response"}
+	}
+	out["responses"] = responses
+
+	if route.Authentication {
+		out["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+	}
+	if route.Deprecated {
+		out["deprecated"] = true
+	}
+
+	return out
+}
+
+func parameter(p Parameter, in string) map[string]any {
+	typ := p.Type
+	if typ == "" {
+		typ = "string"
+	}
+
+	schema := map[string]any{"type": typ}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	if p.Default != nil {
+		schema["default"] = p.Default
+	}
+
+	out := map[string]any{
+		"name":     p.Name,
+		"in":       in,
+		"required": p.Required || in == "path",
+		"schema":   schema,
+	}
+	if p.Description != "" {
+		out["description"] = p.Description
+	}
+	if p.Example != nil {
+		out["example"] = p.Example
+	}
+
+	return out
+}
+
+func (g *generator) response(resp Response) map[string]any {
+	description := resp.Description
+	if description == "" {
+		description = "Successful response"
+	}
+
+	out := map[string]any{"description": description}
+
+	if resp.Body != nil {
+		contentType := resp.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		out["content"] = map[string]any{contentType: map[string]any{"schema": g.value(resp.Body)}}
+	}
+
+	if len(resp.Headers) > 0 {
+		headers := map[string]any{}
+		for name, description := range resp.Headers {
+			headers[name] = map[string]any{"description": description, "schema": map[string]any{"type": "string"}}
+		}
+		out["headers"] = headers
+	}
+
+	return out
+}
+
+// value returns the schema of a body or property value.
+func (g *generator) value(v any) map[string]any {
+	switch v := v.(type) {
+	case Schema:
+		out := map[string]any{}
+		for key, value := range v {
+			out[key] = g.nested(value)
+		}
+		return out
+	case Object:
+		properties := map[string]any{}
+		for name, value := range v {
+			properties[name] = g.value(value)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	case nil:
+		return map[string]any{}
+	default:
+		return g.schema(reflect.TypeOf(v))
+	}
+}
+
+// nested resolves the Objects, Schemas and Go values standing for schemas
+// within a raw Schema, e.g. the items of an array or the choices of oneOf.
+func (g *generator) nested(v any) any {
+	switch v := v.(type) {
+	case Schema, Object:
+		return g.value(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			switch t := reflect.TypeOf(item); {
+			case t != nil && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map || t.Kind() == reflect.Slice):
+				out[i] = g.value(item)
+			default:
+				out[i] = item
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns the schema of t, registering named structs as components
+// and referring to them.
+func (g *generator) schema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+		}
+		return s
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Uint:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + g.component(t)}
+	default:
+		return map[string]any{}
+	}
+}
+
+// component registers the named struct t and returns its schema name, the
+// type name qualified by its package when two packages share it.
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	for other := range g.names {
+		if other.Name() == t.Name() {
+			pkg := t.PkgPath()
+			name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+			break
+		}
+	}
+
+	g.names[t] = name
+	// Reserve the name first so recursive types refer to it.
+	g.schemas[name] = map[string]any{}
+
+	s := g.object(t)
+	if description := g.descriptions[t.Name()]; description != "" {
+		s["description"] = description
+	}
+	g.schemas[name] = s
+
+	return name
+}
+
+func (g *generator) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	g.fields(t, properties)
+
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+// fields adds the JSON properties of struct t, following encoding/json:
+// untagged embedded structs contribute their own fields.
+func (g *generator) fields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, properties)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s := g.schema(f.Type)
+
+		// A reference cannot carry siblings in every tool, so annotations
+		// wrap it.
+		if doc, example := f.Tag.Get("doc"), f.Tag.Get("example"); doc != "" || example != "" {
+			if _, ok := s["$ref"]; ok {
+				s = map[string]any{"allOf": []any{s}}
+			}
+			if doc != "" {
+				s["description"] = doc
+			}
+			if example != "" {
+				var v any
+				if err := json.Unmarshal([]byte(example), &v); err != nil {
+					v = example
+				}
+				s["examples"] = []any{v}
+			}
+		}
+
+		properties[name] = s
+	}
+}
+
+// defaultTag groups an unannotated route by the first segment of its path
+// after the version, e.g. Custom Field for /v1/custom-field/{id}.
+func defaultTag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 1 && strings.HasPrefix(segments[0], "v") {
+		segments = segments[1:]
+	}
+
+	words := strings.FieldsFunc(segments[0], func(r rune) bool { return r == '-' || r == '.' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+
+	return strings.Join(words, " ")
+}
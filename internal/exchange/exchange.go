@@ -0,0 +1,75 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var ErrUnknownProvider = errors.New("unknown exchange rate provider")
+
+// Provider fetches the latest rates for one unit of base, keyed by ISO 4217
+// currency code, along with the date the rates apply to.
+type Provider interface {
+	Latest(ctx context.Context, base string) (map[string]float64, time.Time, error)
+}
+
+// New returns the provider registered under name.
+func New(name string) (Provider, error) {
+	switch name {
+	case "frankfurter":
+		return Frankfurter{
+			BaseURL: "https://api.frankfurter.app",
+			Client:  &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, ErrUnknownProvider
+	}
+}
+
+// Frankfurter reads the European Central Bank reference rates published by
+// frankfurter.app. It needs no credentials.
+type Frankfurter struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func (f Frankfurter) Latest(ctx context.Context, base string) (map[string]float64, time.Time, error) {
+	requestURL := fmt.Sprintf("%s/latest?from=%s", f.BaseURL, url.QueryEscape(base))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	res, err := f.Client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("frankfurter: unexpected status %s", res.Status)
+	}
+
+	var body struct {
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	date, err := time.Parse(time.DateOnly, body.Date)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return body.Rates, date, nil
+}
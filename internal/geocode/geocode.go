@@ -0,0 +1,169 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown geocode provider")
+	ErrNoMatch         = errors.New("address could not be geocoded")
+	ErrNoRoute         = errors.New("no route between the points")
+)
+
+// Point is a longitude, latitude pair.
+type Point [2]float64
+
+// Route is a driving route between two points.
+type Route struct {
+	DistanceMeters  float64 `json:"distance_meters"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// Provider resolves free-form addresses to a longitude and latitude,
+// computes driving routes and renders static map images.
+type Provider interface {
+	Forward(ctx context.Context, address string) (longitude, latitude float64, err error)
+	Driving(ctx context.Context, from, to Point) (Route, error)
+	StaticMap(ctx context.Context, center Point) ([]byte, error)
+}
+
+// New returns the provider registered under name, authenticated with token.
+func New(name, token string) (Provider, error) {
+	switch name {
+	case "mapbox":
+		return Mapbox{
+			BaseURL: "https://api.mapbox.com",
+			Token:   token,
+			Client: &http.Client{
+				Timeout:   10 * time.Second,
+				Transport: otelhttp.NewTransport(http.DefaultTransport),
+			},
+		}, nil
+	default:
+		return nil, ErrUnknownProvider
+	}
+}
+
+// Mapbox uses the Mapbox Geocoding API v6.
+type Mapbox struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+func (m Mapbox) Forward(ctx context.Context, address string) (float64, float64, error) {
+	requestURL := fmt.Sprintf("%s/search/geocode/v6/forward?q=%s&limit=1&access_token=%s",
+		m.BaseURL, url.QueryEscape(address), url.QueryEscape(m.Token))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := m.Client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("mapbox: unexpected status %s", res.Status)
+	}
+
+	var body struct {
+		Features []struct {
+			Geometry struct {
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(body.Features) == 0 || len(body.Features[0].Geometry.Coordinates) != 2 {
+		return 0, 0, ErrNoMatch
+	}
+
+	coordinates := body.Features[0].Geometry.Coordinates
+	return coordinates[0], coordinates[1], nil
+}
+
+// Driving uses the Mapbox Directions API v5 driving profile.
+func (m Mapbox) Driving(ctx context.Context, from, to Point) (Route, error) {
+	requestURL := fmt.Sprintf("%s/directions/v5/mapbox/driving/%f,%f;%f,%f?overview=false&access_token=%s",
+		m.BaseURL, from[0], from[1], to[0], to[1], url.QueryEscape(m.Token))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return Route{}, err
+	}
+
+	res, err := m.Client.Do(req)
+	if err != nil {
+		return Route{}, err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		Code   string `json:"code"`
+		Routes []struct {
+			Distance float64 `json:"distance"`
+			Duration float64 `json:"duration"`
+		} `json:"routes"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return Route{}, err
+	}
+
+	switch {
+	case body.Code == "NoRoute" || body.Code == "NoSegment":
+		return Route{}, ErrNoRoute
+	case res.StatusCode != http.StatusOK || body.Code != "Ok":
+		return Route{}, fmt.Errorf("mapbox: unexpected response %s %s", res.Status, body.Code)
+	case len(body.Routes) == 0:
+		return Route{}, ErrNoRoute
+	}
+
+	return Route{
+		DistanceMeters:  body.Routes[0].Distance,
+		DurationSeconds: body.Routes[0].Duration,
+	}, nil
+}
+
+// StaticMap uses the Mapbox Static Images API to render a 600x400 PNG at
+// double resolution with a pin on center.
+func (m Mapbox) StaticMap(ctx context.Context, center Point) ([]byte, error) {
+	requestURL := fmt.Sprintf("%s/styles/v1/mapbox/streets-v12/static/pin-l+e74c3c(%f,%f)/%f,%f,14/600x400@2x?access_token=%s",
+		m.BaseURL, center[0], center[1], center[0], center[1], url.QueryEscape(m.Token))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("mapbox: unexpected status %s", res.Status)
+	}
+
+	return io.ReadAll(res.Body)
+}
@@ -0,0 +1,221 @@
+// Package id generates and validates the string identifiers the API assigns
+// itself, as opposed to the integer keys database sequences assign.
+//
+// A Scheme names a format along with its pattern, which is the same in Go,
+// in the PostgreSQL domain of that name and in the OpenAPI document, so the
+// three cannot disagree about what a valid ID is. The server generates IDs
+// with the scheme it is configured with, ULID by default.
+//
+// Migration guidance: a new table keys its rows on a column of the
+// configured scheme's Domain, which PostgreSQL checks on every write, and its
+// model fills it in with Scheme.New before inserting. Existing tables keep
+// their sequence IDs, which clients store. To give one of them a string ID,
+// add a nullable column of the domain, backfill it in batches, then make it
+// NOT NULL and UNIQUE in a later migration, keeping the integer key for
+// foreign keys.
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/validator"
+)
+
+// Scheme is a format of generated IDs.
+type Scheme struct {
+	Name string
+	// Domain is the PostgreSQL type columns of the scheme are declared with.
+	Domain string
+	// Pattern matches the IDs of the scheme, in Go and PostgreSQL regular
+	// expression syntax alike.
+	Pattern     string
+	Example     string
+	Description string
+
+	rx  *regexp.Regexp
+	new func() string
+}
+
+var (
+	// ULID is a 48-bit millisecond timestamp followed by 80 random bits, in
+	// 26 characters of Crockford's base32. IDs sort in creation order.
+	ULID = newScheme("ulid", "ulid", `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`, "01JA8Z7Q2M5X3K9RTV4BWNCE6H",
+		"A ULID: 26 characters of Crockford's base32, sorting in creation order.", newULID)
+
+	// UUIDv7 is a time-ordered RFC 9562 UUID.
+	UUIDv7 = newScheme("uuid7", "uuid7", `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, "01929b5e-3c2a-7d41-9f0e-5b7c2d8e4a16",
+		"A version 7 UUID in lowercase, sorting in creation order.", newUUIDv7)
+
+	// UUIDv4 is a random RFC 9562 UUID.
+	UUIDv4 = newScheme("uuid4", "uuid", `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, "3f0c9a62-8d14-4b7e-a5c1-6e2f9d0b7a43",
+		"A random version 4 UUID in lowercase.", newUUIDv4)
+
+	// UUID accepts the UUIDs clients generate, of any version and case, and
+	// generates version 4 ones.
+	UUID = newScheme("uuid", "uuid", `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`, "3f0c9a62-8d14-4b7e-a5c1-6e2f9d0b7a43",
+		"A UUID of any version.", newUUIDv4)
+)
+
+// Schemes are those the server may be configured to generate IDs with.
+var Schemes = []Scheme{ULID, UUIDv7, UUIDv4}
+
+func newScheme(name, domain, pattern, example, description string, generate func() string) Scheme {
+	return Scheme{
+		Name:        name,
+		Domain:      domain,
+		Pattern:     pattern,
+		Example:     example,
+		Description: description,
+		rx:          regexp.MustCompile(pattern),
+		new:         generate,
+	}
+}
+
+// Lookup returns the configurable scheme called name.
+func Lookup(name string) (Scheme, error) {
+	names := make([]string, len(Schemes))
+
+	for i, s := range Schemes {
+		if s.Name == name {
+			return s, nil
+		}
+		names[i] = s.Name
+	}
+
+	return Scheme{}, fmt.Errorf("unknown ID scheme %q, want one of %s", name, strings.Join(names, ", "))
+}
+
+// New generates an ID.
+func (s Scheme) New() string {
+	return s.new()
+}
+
+// Valid reports whether id is in the scheme's format.
+func (s Scheme) Valid(id string) bool {
+	return s.rx.MatchString(id)
+}
+
+// Check records an error for key on v unless id is in the scheme's format.
+func (s Scheme) Check(v *validator.Validator, key, id string) {
+	format := "UUID"
+	if s.Name == ULID.Name {
+		format = "ULID"
+	}
+
+	v.Check(s.Valid(id), key, "must be a "+format)
+}
+
+// SQLCheck returns a CHECK constraint holding column to the scheme's format,
+// for columns that cannot use its domain, such as text columns shared by
+// several schemes.
+func (s Scheme) SQLCheck(column string) string {
+	return fmt.Sprintf("CHECK (%s::text ~ '%s')", column, s.Pattern)
+}
+
+// OpenAPISchema describes the scheme's IDs as an OpenAPI string schema.
+func (s Scheme) OpenAPISchema() map[string]any {
+	return map[string]any{
+		"type":        "string",
+		"pattern":     s.Pattern,
+		"description": s.Description,
+		"examples":    []any{s.Example},
+	}
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidState keeps the last ULID generated, so IDs generated within the same
+// millisecond increment its random part and still sort in order.
+var ulidState struct {
+	mu     sync.Mutex
+	ms     uint64
+	random [10]byte
+}
+
+func newULID() string {
+	ms := uint64(time.Now().UnixMilli())
+
+	ulidState.mu.Lock()
+	switch {
+	case ms > ulidState.ms:
+		ulidState.ms = ms
+		rand.Read(ulidState.random[:])
+	case increment(ulidState.random[:]):
+		ms = ulidState.ms
+	default:
+		// The random part ran out within the millisecond, so borrow the
+		// next one.
+		ulidState.ms++
+		ms = ulidState.ms
+		rand.Read(ulidState.random[:])
+	}
+
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], ms<<16)
+	copy(b[6:], ulidState.random[:])
+	ulidState.mu.Unlock()
+
+	return encodeULID(b)
+}
+
+// increment adds one to the big-endian number b, reporting false when it
+// overflows.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeULID writes the 128 bits of b as 26 base32 digits, the first of
+// which holds only 3 bits.
+func encodeULID(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(out[:])
+}
+
+func newUUIDv7() string {
+	var b [16]byte
+	rand.Read(b[6:])
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16|uint64(b[6])<<8|uint64(b[7]))
+
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+
+	return formatUUID(b)
+}
+
+func newUUIDv4() string {
+	var b [16]byte
+	rand.Read(b[:])
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return formatUUID(b)
+}
+
+func formatUUID(b [16]byte) string {
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
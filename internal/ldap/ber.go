@@ -0,0 +1,182 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// BER tags of the ASN.1 types and LDAP protocol operations used.
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+
+	tagBindRequest       = 0x60
+	tagBindResponse      = 0x61
+	tagUnbindRequest     = 0x42
+	tagSearchRequest     = 0x63
+	tagSearchResultEntry = 0x64
+	tagSearchResultDone  = 0x65
+	tagSearchResultRef   = 0x73
+	tagExtendedRequest   = 0x77
+	tagExtendedResponse  = 0x78
+
+	tagSimpleAuth    = 0x80
+	tagExtendedName  = 0x80
+	tagEqualityMatch = 0xa3
+)
+
+// maxMessageSize bounds the responses read, which are a handful of
+// attributes of one entry.
+const maxMessageSize = 1 << 20
+
+var errMalformed = errors.New("ldap: malformed message")
+
+// element is a decoded BER element. Only single byte tags are supported,
+// which is all LDAP uses.
+type element struct {
+	tag     byte
+	content []byte
+}
+
+func encode(tag byte, children ...[]byte) []byte {
+	n := 0
+	for _, c := range children {
+		n += len(c)
+	}
+
+	b := append([]byte{tag}, encodeLength(n)...)
+	for _, c := range children {
+		b = append(b, c...)
+	}
+
+	return b
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+func encodeInt(tag byte, n int) []byte {
+	b := []byte{byte(n)}
+	for n > 0x7f || n < -0x80 {
+		n >>= 8
+		b = append([]byte{byte(n)}, b...)
+	}
+
+	return encode(tag, b)
+}
+
+func encodeString(tag byte, s string) []byte {
+	return encode(tag, []byte(s))
+}
+
+func encodeBool(b bool) []byte {
+	if b {
+		return encode(tagBoolean, []byte{0xff})
+	}
+	return encode(tagBoolean, []byte{0x00})
+}
+
+// readElement reads one whole element, such as an LDAP message, from r.
+func readElement(r *bufio.Reader) (element, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+
+	first, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+
+	length := int(first)
+	if first&0x80 != 0 {
+		octets := int(first & 0x7f)
+		if octets == 0 || octets > 4 {
+			return element{}, errMalformed
+		}
+
+		length = 0
+		for range octets {
+			b, err := r.ReadByte()
+			if err != nil {
+				return element{}, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+
+	if length > maxMessageSize {
+		return element{}, errMalformed
+	}
+
+	content := make([]byte, length)
+	_, err = io.ReadFull(r, content)
+	if err != nil {
+		return element{}, err
+	}
+
+	return element{tag: tag, content: content}, nil
+}
+
+// children decodes the elements a constructed element holds.
+func (e element) children() ([]element, error) {
+	var elements []element
+
+	b := e.content
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return nil, errMalformed
+		}
+
+		tag, length, header := b[0], int(b[1]), 2
+		if b[1]&0x80 != 0 {
+			octets := int(b[1] & 0x7f)
+			if octets == 0 || octets > 4 || len(b) < 2+octets {
+				return nil, errMalformed
+			}
+
+			length = 0
+			for _, o := range b[2 : 2+octets] {
+				length = length<<8 | int(o)
+			}
+			header += octets
+		}
+
+		if length < 0 || len(b) < header+length {
+			return nil, errMalformed
+		}
+
+		elements = append(elements, element{tag: tag, content: b[header : header+length]})
+		b = b[header+length:]
+	}
+
+	return elements, nil
+}
+
+func (e element) int() (int, error) {
+	if len(e.content) == 0 || len(e.content) > 4 {
+		return 0, errMalformed
+	}
+
+	n := int(int8(e.content[0]))
+	for _, b := range e.content[1:] {
+		n = n<<8 | int(b)
+	}
+
+	return n, nil
+}
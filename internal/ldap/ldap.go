@@ -0,0 +1,359 @@
+// Package ldap is a minimal LDAPv3 client for authenticating users against a
+// directory such as Active Directory: a simple bind with the user's own
+// credentials, then an equality search for their entry. Connections are
+// always encrypted, with TLS for ldaps:// URLs and StartTLS for ldap:// ones.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+	ErrUnsupportedURL     = errors.New("ldap: url must be ldaps://host[:port] or ldap://host[:port]")
+
+	errDisconnected = errors.New("ldap: server closed the connection")
+)
+
+// LDAP result codes told apart.
+const (
+	resultSizeLimitExceeded  = 4
+	resultInvalidCredentials = 49
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// ResultError is an LDAP operation that did not succeed.
+type ResultError struct {
+	Code    int
+	Message string
+}
+
+func (e *ResultError) Error() string {
+	return fmt.Sprintf("ldap: result code %d: %s", e.Code, e.Message)
+}
+
+// Entry is a directory entry found by a search.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Get returns the first value of attribute, matched case-insensitively as
+// attribute names are, or "" when the entry has none.
+func (e Entry) Get(attribute string) string {
+	values := e.Values(attribute)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Values returns every value of attribute.
+func (e Entry) Values(attribute string) []string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attribute) {
+			return values
+		}
+	}
+	return nil
+}
+
+// Conn is a connection to a directory server. It is not safe for concurrent
+// use.
+type Conn struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	messageID int
+}
+
+// Dial connects to the server at rawURL, which must be ldaps:// or ldap://,
+// the latter upgraded with StartTLS. Every operation on the connection must
+// complete before deadline.
+func Dial(ctx context.Context, rawURL string, deadline time.Time) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return nil, ErrUnsupportedURL
+	}
+
+	var port string
+	switch u.Scheme {
+	case "ldaps":
+		port = "636"
+	case "ldap":
+		port = "389"
+	default:
+		return nil, ErrUnsupportedURL
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+
+	d := net.Dialer{Deadline: deadline}
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(deadline)
+
+	c := &Conn{conn: conn, r: bufio.NewReader(conn)}
+
+	if u.Scheme == "ldap" {
+		err = c.startTLS()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+
+	err = tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c.conn = tlsConn
+	c.r = bufio.NewReader(tlsConn)
+
+	return c, nil
+}
+
+// Close sends an unbind request and closes the connection.
+func (c *Conn) Close() error {
+	c.send(encode(tagUnbindRequest))
+	return c.conn.Close()
+}
+
+// Bind authenticates as name with password. An empty password is refused
+// without contacting the server, which would otherwise treat the bind as
+// anonymous and let it succeed.
+func (c *Conn) Bind(name, password string) error {
+	if password == "" {
+		return ErrInvalidCredentials
+	}
+
+	err := c.send(encode(tagBindRequest,
+		encodeInt(tagInteger, 3),
+		encodeString(tagOctetString, name),
+		encodeString(tagSimpleAuth, password),
+	))
+	if err != nil {
+		return err
+	}
+
+	op, err := c.receive()
+	if err != nil {
+		return err
+	}
+
+	if op.tag != tagBindResponse {
+		return errMalformed
+	}
+
+	err = result(op)
+	var resultErr *ResultError
+	if errors.As(err, &resultErr) && resultErr.Code == resultInvalidCredentials {
+		return ErrInvalidCredentials
+	}
+
+	return err
+}
+
+// SearchEqual searches the subtree at baseDN for entries whose attribute
+// equals value, returning the attributes asked for.
+func (c *Conn) SearchEqual(baseDN, attribute, value string, attributes []string) ([]Entry, error) {
+	var requested [][]byte
+	for _, a := range attributes {
+		requested = append(requested, encodeString(tagOctetString, a))
+	}
+
+	err := c.send(encode(tagSearchRequest,
+		encodeString(tagOctetString, baseDN),
+		encodeInt(tagEnumerated, 2), // wholeSubtree
+		encodeInt(tagEnumerated, 0), // neverDerefAliases
+		encodeInt(tagInteger, 2),    // sizeLimit, enough to tell a match is ambiguous
+		encodeInt(tagInteger, 10),   // timeLimit in seconds
+		encodeBool(false),
+		encode(tagEqualityMatch,
+			encodeString(tagOctetString, attribute),
+			encodeString(tagOctetString, value),
+		),
+		encode(tagSequence, requested...),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+
+	for {
+		op, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.tag {
+		case tagSearchResultEntry:
+			entry, err := parseEntry(op)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		case tagSearchResultRef:
+			// Referrals to other servers are not followed.
+		case tagSearchResultDone:
+			err = result(op)
+			var resultErr *ResultError
+			if errors.As(err, &resultErr) && resultErr.Code == resultSizeLimitExceeded {
+				return entries, nil
+			}
+			return entries, err
+		default:
+			return nil, errMalformed
+		}
+	}
+}
+
+func (c *Conn) startTLS() error {
+	err := c.send(encode(tagExtendedRequest, encodeString(tagExtendedName, startTLSOID)))
+	if err != nil {
+		return err
+	}
+
+	op, err := c.receive()
+	if err != nil {
+		return err
+	}
+
+	if op.tag != tagExtendedResponse {
+		return errMalformed
+	}
+
+	return result(op)
+}
+
+func (c *Conn) send(op []byte) error {
+	c.messageID++
+
+	_, err := c.conn.Write(encode(tagSequence, encodeInt(tagInteger, c.messageID), op))
+	return err
+}
+
+// receive reads the protocol operation of the next message answering the
+// last request.
+func (c *Conn) receive() (element, error) {
+	message, err := readElement(c.r)
+	if err != nil {
+		return element{}, err
+	}
+
+	if message.tag != tagSequence {
+		return element{}, errMalformed
+	}
+
+	parts, err := message.children()
+	if err != nil {
+		return element{}, err
+	}
+
+	if len(parts) < 2 || parts[0].tag != tagInteger {
+		return element{}, errMalformed
+	}
+
+	id, err := parts[0].int()
+	if err != nil {
+		return element{}, err
+	}
+
+	// Message ID 0 is an unsolicited notification, which servers only send
+	// before disconnecting.
+	if id == 0 {
+		return element{}, errDisconnected
+	}
+
+	if id != c.messageID {
+		return element{}, errMalformed
+	}
+
+	return parts[1], nil
+}
+
+// result returns the error an LDAPResult reports, nil on success.
+func result(op element) error {
+	parts, err := op.children()
+	if err != nil {
+		return err
+	}
+
+	if len(parts) < 3 || parts[0].tag != tagEnumerated {
+		return errMalformed
+	}
+
+	code, err := parts[0].int()
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return &ResultError{Code: code, Message: string(parts[2].content)}
+	}
+
+	return nil
+}
+
+func parseEntry(op element) (Entry, error) {
+	parts, err := op.children()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if len(parts) < 2 || parts[0].tag != tagOctetString || parts[1].tag != tagSequence {
+		return Entry{}, errMalformed
+	}
+
+	entry := Entry{DN: string(parts[0].content), Attributes: make(map[string][]string)}
+
+	attributes, err := parts[1].children()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for _, a := range attributes {
+		pair, err := a.children()
+		if err != nil {
+			return Entry{}, err
+		}
+
+		if len(pair) != 2 || pair[0].tag != tagOctetString || pair[1].tag != tagSet {
+			return Entry{}, errMalformed
+		}
+
+		values, err := pair[1].children()
+		if err != nil {
+			return Entry{}, err
+		}
+
+		name := string(pair[0].content)
+		for _, v := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], string(v.content))
+		}
+	}
+
+	return entry, nil
+}
@@ -3,7 +3,13 @@ package mailer
 import (
 	"bytes"
 	"embed"
+	"errors"
+	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	netmail "net/mail"
 	"time"
 
 	"github.com/go-mail/mail/v2"
@@ -12,9 +18,50 @@ import (
 //go:embed "templates"
 var templateFS embed.FS
 
+const (
+	DefaultLanguage = "en"
+	logoFile        = "logo.png"
+)
+
+var ErrTemplateNotFound = errors.New("email template not found")
+
+var templateFuncs = template.FuncMap{
+	"minutes": formatMinutes,
+}
+
 type Mailer struct {
-	dialer *mail.Dialer
-	sender string
+	dialer   *mail.Dialer
+	logger   *slog.Logger
+	sender   string
+	branding *Branding
+	language string
+}
+
+// Branding is how an organization's emails look and who they come from.
+// Empty fields keep the defaults: the configured sender, no Reply-To, the
+// embedded logo and the templates' own colors.
+type Branding struct {
+	SenderName   string
+	SenderEmail  string
+	ReplyTo      string
+	LogoURL      string
+	PrimaryColor string
+	AccentColor  string
+}
+
+// Logo is the src of the logo image in HTML bodies. A custom logo URL is
+// trusted as validated when it was saved.
+func (b *Branding) Logo() template.URL {
+	if b.LogoURL != "" {
+		return template.URL(b.LogoURL)
+	}
+	return template.URL("cid:" + logoFile)
+}
+
+type Email struct {
+	Subject   string
+	PlainBody string
+	HTMLBody  string
 }
 
 func New(host string, port int, username, password, sender string) Mailer {
@@ -27,36 +74,166 @@ func New(host string, port int, username, password, sender string) Mailer {
 	}
 }
 
-func (m Mailer) Send(recipient, templateFile string, data any) error {
-	tmpl, err := template.New("email").ParseFS(templateFS, "templates/"+templateFile)
+// NewLog returns a mailer that logs the emails it is given instead of
+// sending them, for running without an SMTP server.
+func NewLog(logger *slog.Logger, sender string) Mailer {
+	return Mailer{
+		logger: logger,
+		sender: sender,
+	}
+}
+
+// Render executes the subject, plainBody and htmlBody blocks of a template.
+// Localized templates live under templates/{lang}/ and fall back to the
+// default templates when no translation exists. HTML bodies can reference the
+// embedded logo as cid:logo.png.
+func Render(templateFile, lang string, data any) (*Email, error) {
+	return RenderBranded(templateFile, lang, nil, data)
+}
+
+// RenderBranded is Render with templates given b, or the defaults when nil,
+// as the brand function.
+func RenderBranded(templateFile, lang string, b *Branding, data any) (*Email, error) {
+	path := "templates/" + templateFile
+	if lang != "" && lang != DefaultLanguage {
+		localized := "templates/" + lang + "/" + templateFile
+		if _, err := fs.Stat(templateFS, localized); err == nil {
+			path = localized
+		}
+	}
+
+	if _, err := fs.Stat(templateFS, path); err != nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	if b == nil {
+		b = &Branding{}
+	}
+
+	brand := template.FuncMap{"brand": func() *Branding { return b }}
+
+	tmpl, err := template.New("email").Funcs(templateFuncs).Funcs(brand).ParseFS(templateFS, path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := new(bytes.Buffer)
 	err = tmpl.ExecuteTemplate(subject, "subject", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	plainBody := new(bytes.Buffer)
 	err = tmpl.ExecuteTemplate(plainBody, "plainBody", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	htmlBody := new(bytes.Buffer)
 	err = tmpl.ExecuteTemplate(htmlBody, "htmlBody", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Email{
+		Subject:   subject.String(),
+		PlainBody: plainBody.String(),
+		HTMLBody:  htmlBody.String(),
+	}, nil
+}
+
+// formatMinutes writes a duration in minutes as hours and minutes, such as
+// "7h 30m".
+func formatMinutes(minutes int64) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+func Templates() ([]string, error) {
+	return fs.Glob(templateFS, "templates/*.tmpl")
+}
+
+// Languages lists the languages templates can be rendered in: the default
+// one and each with a directory of translations.
+func Languages() ([]string, error) {
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	languages := []string{DefaultLanguage}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			languages = append(languages, entry.Name())
+		}
+	}
+
+	return languages, nil
+}
+
+func Logo() ([]byte, error) {
+	return templateFS.ReadFile("templates/" + logoFile)
+}
+
+// WithBranding returns a mailer sending with b, or the defaults when nil.
+func (m Mailer) WithBranding(b *Branding) Mailer {
+	m.branding = b
+	return m
+}
+
+// WithLanguage returns a mailer rendering templates in lang, falling back to
+// the default language for templates not translated into it.
+func (m Mailer) WithLanguage(lang string) Mailer {
+	m.language = lang
+	return m
+}
+
+func (m Mailer) Send(recipient, templateFile string, data any) error {
+	lang := m.language
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+
+	return m.SendLocalized(recipient, lang, templateFile, data)
+}
+
+func (m Mailer) SendLocalized(recipient, lang, templateFile string, data any) error {
+	b := m.branding
+	if b == nil {
+		b = &Branding{}
+	}
+
+	email, err := RenderBranded(templateFile, lang, b, data)
 	if err != nil {
 		return err
 	}
 
+	if m.logger != nil {
+		m.logger.Info("email logged instead of sent", "to", recipient, "subject", email.Subject, "body", email.PlainBody)
+		return nil
+	}
+
 	msg := mail.NewMessage()
 	msg.SetHeader("To", recipient)
-	msg.SetHeader("From", m.sender)
-	msg.SetHeader("Subject", subject.String())
-	msg.SetBody("text/plain", plainBody.String())
-	msg.AddAlternative("text/html", htmlBody.String())
+	m.setSender(msg, b)
+	msg.SetHeader("Subject", email.Subject)
+	msg.SetBody("text/plain", email.PlainBody)
+	msg.AddAlternative("text/html", email.HTMLBody)
+
+	if b.LogoURL == "" {
+		logo, err := Logo()
+		if err != nil {
+			return err
+		}
+
+		msg.Embed(logoFile, mail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(logo)
+			return err
+		}))
+	}
 
 	for i := 1; i <= 3; i++ {
 		err = m.dialer.DialAndSend(msg)
@@ -70,6 +247,28 @@ func (m Mailer) Send(recipient, templateFile string, data any) error {
 	return err
 }
 
+// setSender sets the From and Reply-To headers. A sender name alone renames
+// the configured sender address.
+func (m Mailer) setSender(msg *mail.Message, b *Branding) {
+	switch {
+	case b.SenderEmail != "":
+		msg.SetAddressHeader("From", b.SenderEmail, b.SenderName)
+	case b.SenderName != "":
+		sender, err := netmail.ParseAddress(m.sender)
+		if err != nil {
+			msg.SetHeader("From", m.sender)
+			break
+		}
+		msg.SetAddressHeader("From", sender.Address, b.SenderName)
+	default:
+		msg.SetHeader("From", m.sender)
+	}
+
+	if b.ReplyTo != "" {
+		msg.SetHeader("Reply-To", b.ReplyTo)
+	}
+}
+
 func newFunction(err error) (bool, error) {
 	if err != nil {
 		return true, err
@@ -0,0 +1,194 @@
+// Package memstore provides in-memory implementations of the proposal and
+// organization stores, for running their handlers without Postgres. They
+// mirror the models' error behaviour (ErrRecordNotFound, ErrEditConflict and
+// duplicate errors) but not their SQL-specific features. Other stores have
+// no fake here; use the generated mocks in datamock for them.
+package memstore
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+)
+
+// visible mirrors the models' organization scoping: an actor sees the rows
+// of its own organization, or every row when unrestricted.
+func visible(actor data.Actor, orgID int32) bool {
+	return actor.Unrestricted || actor.OrgID == orgID
+}
+
+type ProposalStore struct {
+	mu        sync.Mutex
+	nextID    int32
+	proposals map[int32]data.Proposal
+}
+
+func NewProposalStore() *ProposalStore {
+	return &ProposalStore{proposals: make(map[int32]data.Proposal)}
+}
+
+func (s *ProposalStore) Insert(proposal *data.Proposal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	proposal.InternalID = s.nextID
+	proposal.Version = 1
+	proposal.CreatedAt = time.Now()
+	proposal.UpdatedAt = proposal.CreatedAt
+
+	s.proposals[proposal.InternalID] = *proposal
+	return nil
+}
+
+func (s *ProposalStore) Get(actor data.Actor, externalID string) (*data.Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, proposal := range s.proposals {
+		if proposal.ExternalID == externalID && visible(actor, proposal.OrgID) {
+			return &proposal, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (s *ProposalStore) Update(proposal *data.Proposal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.proposals[proposal.InternalID]
+	if !ok || stored.Version != proposal.Version {
+		return data.ErrEditConflict
+	}
+
+	proposal.Version++
+	proposal.UpdatedAt = time.Now()
+	s.proposals[proposal.InternalID] = *proposal
+	return nil
+}
+
+func (s *ProposalStore) Delete(actor data.Actor, externalID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, proposal := range s.proposals {
+		if proposal.ExternalID == externalID && visible(actor, proposal.OrgID) {
+			delete(s.proposals, id)
+			return nil
+		}
+	}
+
+	return data.ErrRecordNotFound
+}
+
+type OrganizationStore struct {
+	mu     sync.Mutex
+	nextID int32
+	orgs   map[int32]data.Organization
+	// InUse reports whether an organization still owns records, which the
+	// Postgres model checks against users, clients and projects.
+	InUse func(id int32) bool
+}
+
+func NewOrganizationStore() *OrganizationStore {
+	return &OrganizationStore{orgs: make(map[int32]data.Organization)}
+}
+
+func (s *OrganizationStore) nameTaken(name string, except int32) bool {
+	for id, org := range s.orgs {
+		if id != except && org.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OrganizationStore) Insert(org *data.Organization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nameTaken(org.Name, 0) {
+		return data.ErrDuplicateOrganizationName
+	}
+
+	s.nextID++
+	org.InternalID = s.nextID
+	org.Version = 1
+	org.CreatedAt = time.Now()
+	org.UpdatedAt = org.CreatedAt
+
+	s.orgs[org.InternalID] = *org
+	return nil
+}
+
+func (s *OrganizationStore) Get(id int32) (*data.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, data.ErrRecordNotFound
+	}
+
+	return &org, nil
+}
+
+func (s *OrganizationStore) GetAll() ([]*data.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orgs := make([]*data.Organization, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		orgs = append(orgs, &org)
+	}
+
+	sort.Slice(orgs, func(i, j int) bool {
+		return orgs[i].InternalID < orgs[j].InternalID
+	})
+
+	return orgs, nil
+}
+
+func (s *OrganizationStore) Update(org *data.Organization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.orgs[org.InternalID]
+	if !ok || stored.Version != org.Version {
+		return data.ErrEditConflict
+	}
+
+	if s.nameTaken(org.Name, org.InternalID) {
+		return data.ErrDuplicateOrganizationName
+	}
+
+	org.Version++
+	org.UpdatedAt = time.Now()
+	s.orgs[org.InternalID] = *org
+	return nil
+}
+
+func (s *OrganizationStore) Delete(id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orgs[id]; !ok {
+		return data.ErrRecordNotFound
+	}
+
+	if s.InUse != nil && s.InUse(id) {
+		return data.ErrOrganizationInUse
+	}
+
+	delete(s.orgs, id)
+	return nil
+}
+
+var (
+	_ data.ProposalStore     = (*ProposalStore)(nil)
+	_ data.OrganizationStore = (*OrganizationStore)(nil)
+)
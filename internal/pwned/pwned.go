@@ -0,0 +1,76 @@
+// Package pwned looks passwords up in the Have I Been Pwned Pwned Passwords
+// range API. Only the first five hex characters of a password's SHA-1 hash
+// are sent, and the matching suffixes are compared locally, so the password
+// itself never leaves the server.
+package pwned
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Checker queries the range API at BaseURL.
+type Checker struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// New returns a Checker for the public Pwned Passwords API.
+func New() Checker {
+	return Checker{
+		BaseURL: "https://api.pwnedpasswords.com",
+		Client: &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+	}
+}
+
+// Count returns how many times password appears in known data breaches,
+// zero when it does not.
+func (c Checker) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Padding makes every response about the same size, so the prefix
+	// cannot be told from the response length on the wire.
+	req.Header.Set("Add-Padding", "true")
+
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("pwned passwords: unexpected status %s", res.Status)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		candidate, count, found := strings.Cut(scanner.Text(), ":")
+		if !found || candidate != suffix {
+			continue
+		}
+
+		// Padding entries have a count of zero.
+		return strconv.Atoi(strings.TrimSpace(count))
+	}
+
+	return 0, scanner.Err()
+}
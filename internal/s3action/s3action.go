@@ -38,6 +38,61 @@ func ListObjects(client *s3.Client, bucket, prefix string) ([]string, error) {
 	return fileName, nil
 }
 
+func ListObjectSummaries(client *s3.Client, bucket, prefix string) ([]types.Object, error) {
+	var objects []types.Object
+
+	paginator := s3.NewListObjectsV2Paginator(
+		client,
+		&s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(prefix),
+		},
+	)
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, page.Contents...)
+	}
+
+	return objects, nil
+}
+
+type ObjectInfo struct {
+	Size           int64
+	ContentType    string
+	ChecksumSHA256 string
+}
+
+// GetObjectInfo returns the object's attributes including, when the upload
+// supplied one, the base64 encoded SHA-256 checksum that S3 verified on write.
+func GetObjectInfo(ctx context.Context, client *s3.Client, bucket, key string) (ObjectInfo, error) {
+	out, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{
+		Size:           aws.ToInt64(out.ContentLength),
+		ContentType:    aws.ToString(out.ContentType),
+		ChecksumSHA256: aws.ToString(out.ChecksumSHA256),
+	}, nil
+}
+
+func GetObject(ctx context.Context, client *s3.Client, bucket, key string) (*s3.GetObjectOutput, error) {
+	return client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+}
+
 func DeleteObjects(ctx context.Context, client *s3.Client, bucket string, objects []types.ObjectIdentifier) error {
 	if len(objects) == 0 {
 		return nil
@@ -189,3 +244,180 @@ func PermanentlyDeleteObjects(ctx context.Context, client *s3.Client, bucket, pr
 	}
 	return nil
 }
+
+// ListTrash returns the delete markers that currently hide an object, i.e.
+// objects that were soft deleted and can still be restored.
+func ListTrash(client *s3.Client, bucket, prefix string) ([]types.DeleteMarkerEntry, error) {
+	_, deleteMarkers, err := ListAllVersions(client, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var trash []types.DeleteMarkerEntry
+	for _, deleteMarker := range deleteMarkers {
+		if aws.ToBool(deleteMarker.IsLatest) {
+			trash = append(trash, deleteMarker)
+		}
+	}
+
+	return trash, nil
+}
+
+func RestoreObjects(ctx context.Context, client *s3.Client, bucket string, keys []string) ([]string, error) {
+	var restored []string
+
+	for _, key := range keys {
+		_, deleteMarkers, err := ListAllVersions(client, bucket, key)
+		if err != nil {
+			return restored, err
+		}
+
+		for _, deleteMarker := range deleteMarkers {
+			if *deleteMarker.Key != key || !aws.ToBool(deleteMarker.IsLatest) {
+				continue
+			}
+
+			_, err = DeleteObject(ctx, client, bucket, key, *deleteMarker.VersionId)
+			if err != nil {
+				return restored, err
+			}
+			restored = append(restored, key)
+		}
+	}
+
+	return restored, nil
+}
+
+// RestoreDeletedSince restores objects under prefix whose current delete
+// marker was placed at or after since, and returns their keys.
+func RestoreDeletedSince(ctx context.Context, client *s3.Client, bucket, prefix string, since time.Time) ([]string, error) {
+	deleteMarkers, err := ListTrash(client, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored []string
+	for _, deleteMarker := range deleteMarkers {
+		if deleteMarker.LastModified.Before(since) {
+			continue
+		}
+
+		_, err = DeleteObject(ctx, client, bucket, *deleteMarker.Key, *deleteMarker.VersionId)
+		if err != nil {
+			return restored, err
+		}
+		restored = append(restored, *deleteMarker.Key)
+	}
+
+	return restored, nil
+}
+
+// ListExpiredTrash returns the keys PurgeTrash would delete.
+func ListExpiredTrash(client *s3.Client, bucket, prefix string, olderThan time.Time) ([]string, error) {
+	deleteMarkers, err := ListTrash(client, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for _, deleteMarker := range deleteMarkers {
+		if deleteMarker.LastModified.Before(olderThan) {
+			expired = append(expired, *deleteMarker.Key)
+		}
+	}
+
+	return expired, nil
+}
+
+// PurgeTrash permanently deletes every version of objects whose delete
+// marker is older than the given time.
+func PurgeTrash(ctx context.Context, client *s3.Client, bucket, prefix string, olderThan time.Time) ([]string, error) {
+	versions, deleteMarkers, err := ListAllVersions(client, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := make(map[string]bool)
+	for _, deleteMarker := range deleteMarkers {
+		if aws.ToBool(deleteMarker.IsLatest) && deleteMarker.LastModified.Before(olderThan) {
+			expired[*deleteMarker.Key] = true
+		}
+	}
+
+	var purged []string
+	for _, version := range versions {
+		if expired[*version.Key] {
+			_, err = DeleteObject(ctx, client, bucket, *version.Key, *version.VersionId)
+			if err != nil {
+				return purged, err
+			}
+		}
+	}
+	for _, deleteMarker := range deleteMarkers {
+		if expired[*deleteMarker.Key] {
+			_, err = DeleteObject(ctx, client, bucket, *deleteMarker.Key, *deleteMarker.VersionId)
+			if err != nil {
+				return purged, err
+			}
+		}
+	}
+	for key := range expired {
+		purged = append(purged, key)
+	}
+
+	return purged, nil
+}
+
+// ArchiveTag marks objects for cold storage. The bucket lifecycle
+// configuration must have a rule transitioning objects carrying this tag to
+// a Glacier storage class.
+var ArchiveTag = types.Tag{Key: aws.String("storage-tier"), Value: aws.String("archive")}
+
+// TagForArchive tags every current object under prefix with ArchiveTag so the
+// bucket lifecycle rule moves it to cold storage, and returns the tagged keys.
+func TagForArchive(ctx context.Context, client *s3.Client, bucket, prefix string) ([]string, error) {
+	keys, err := ListObjects(client, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagged []string
+	for _, key := range keys {
+		_, err = client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+			Bucket:  aws.String(bucket),
+			Key:     aws.String(key),
+			Tagging: &types.Tagging{TagSet: []types.Tag{ArchiveTag}},
+		})
+		if err != nil {
+			return tagged, err
+		}
+		tagged = append(tagged, key)
+	}
+
+	return tagged, nil
+}
+
+// CheckBucket confirms bucket exists and the client's credentials may list
+// it, returning an error saying which is wrong when not.
+func CheckBucket(ctx context.Context, client *s3.Client, bucket string) error {
+	_, err := client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err == nil {
+		return nil
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("bucket %s unreachable: %w", bucket, err)
+	}
+
+	switch apiErr.ErrorCode() {
+	case "NotFound", "NoSuchBucket":
+		return fmt.Errorf("bucket %s does not exist", bucket)
+	case "Forbidden", "AccessDenied":
+		return fmt.Errorf("access to bucket %s denied, the credentials need s3:ListBucket on it", bucket)
+	case "MovedPermanently", "PermanentRedirect":
+		return fmt.Errorf("bucket %s is in another region than the client's", bucket)
+	default:
+		return fmt.Errorf("bucket %s unavailable: %w", bucket, err)
+	}
+}
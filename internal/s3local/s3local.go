@@ -0,0 +1,778 @@
+// Package s3local serves a single versioned S3 bucket from a directory, so
+// the API can store files in dev mode without AWS or MinIO. It speaks the
+// path-style S3 REST calls that s3action, the upload manager and presigned
+// URLs make. Requests are not authenticated, so it must only listen on a
+// loopback address.
+package s3local
+
+import (
+	"cmp"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+type version struct {
+	ID             string            `json:"id"`
+	DeleteMarker   bool              `json:"delete_marker,omitempty"`
+	Size           int64             `json:"size"`
+	ContentType    string            `json:"content_type,omitempty"`
+	ETag           string            `json:"etag,omitempty"`
+	ChecksumSHA256 string            `json:"checksum_sha256,omitempty"`
+	LastModified   time.Time         `json:"last_modified"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+type upload struct {
+	key         string
+	contentType string
+	parts       map[int]string
+}
+
+// Store keeps each object version in its own file under dir/data and the
+// versions of every key, oldest first, in dir/index.json.
+type Store struct {
+	dir    string
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string][]version
+	uploads map[string]*upload
+}
+
+// New opens the store in dir, creating it when missing.
+func New(dir, bucket string) (*Store, error) {
+	for _, sub := range []string{"data", "uploads"} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0o755)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Store{
+		dir:     dir,
+		bucket:  bucket,
+		objects: make(map[string][]version),
+		uploads: make(map[string]*upload),
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		err = json.Unmarshal(index, &s.objects)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, "index.json"), err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Presigned URLs are used from the browser.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag, x-amz-version-id")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	q := r.URL.Query()
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && q.Has("versions"):
+			s.listVersions(w, r)
+		case r.Method == http.MethodGet:
+			s.listObjects(w, r)
+		case r.Method == http.MethodPost && q.Has("delete"):
+			s.deleteObjects(w, r)
+		default:
+			writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource")
+		}
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPut && q.Has("tagging"):
+		s.putTagging(w, r, key)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		s.uploadPart(w, r, key)
+	case r.Method == http.MethodPut:
+		s.putObject(w, r, key)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.createUpload(w, r, key)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		s.completeUpload(w, r, key)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		s.getObject(w, r, key)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		s.abortUpload(w, r)
+	case r.Method == http.MethodDelete:
+		s.deleteObject(w, r, key)
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource")
+	}
+}
+
+func (s *Store) putObject(w http.ResponseWriter, r *http.Request, key string) {
+	// Presigned requests carry signed headers in the query string.
+	checksum := cmp.Or(r.Header.Get("x-amz-checksum-sha256"), r.URL.Query().Get("X-Amz-Checksum-Sha256"))
+
+	v, err := s.write(r.Body, checksum)
+	if err != nil {
+		s.writeFailed(w, r, err)
+		return
+	}
+	v.ContentType = r.Header.Get("Content-Type")
+
+	err = s.add(key, v)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.Header().Set("ETag", v.ETag)
+	w.Header().Set("x-amz-version-id", v.ID)
+	if v.ChecksumSHA256 != "" {
+		w.Header().Set("x-amz-checksum-sha256", v.ChecksumSHA256)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Store) getObject(w http.ResponseWriter, r *http.Request, key string) {
+	s.mu.Lock()
+	v, ok := s.find(key, r.URL.Query().Get("versionId"))
+	s.mu.Unlock()
+
+	if !ok || v.DeleteMarker {
+		if ok {
+			w.Header().Set("x-amz-delete-marker", "true")
+		}
+		writeError(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+		return
+	}
+
+	f, err := os.Open(s.dataPath(v.ID))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	defer f.Close()
+
+	q := r.URL.Query()
+	w.Header().Set("Content-Type", cmp.Or(q.Get("response-content-type"), v.ContentType, "binary/octet-stream"))
+	if disposition := q.Get("response-content-disposition"); disposition != "" {
+		w.Header().Set("Content-Disposition", disposition)
+	}
+	w.Header().Set("ETag", v.ETag)
+	w.Header().Set("x-amz-version-id", v.ID)
+	if v.ChecksumSHA256 != "" && r.Header.Get("x-amz-checksum-mode") == "ENABLED" {
+		w.Header().Set("x-amz-checksum-sha256", v.ChecksumSHA256)
+	}
+	if len(v.Tags) > 0 {
+		w.Header().Set("x-amz-tagging-count", strconv.Itoa(len(v.Tags)))
+	}
+
+	http.ServeContent(w, r, "", v.LastModified, f)
+}
+
+func (s *Store) deleteObject(w http.ResponseWriter, r *http.Request, key string) {
+	v, err := s.remove(key, r.URL.Query().Get("versionId"))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	if v.ID != "" {
+		w.Header().Set("x-amz-version-id", v.ID)
+	}
+	if v.DeleteMarker {
+		w.Header().Set("x-amz-delete-marker", "true")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Store) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Objects []struct {
+			Key       string `xml:"Key"`
+			VersionID string `xml:"VersionId"`
+		} `xml:"Object"`
+		Quiet bool `xml:"Quiet"`
+	}
+
+	err := xml.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	type deleted struct {
+		Key                   string `xml:"Key"`
+		VersionID             string `xml:"VersionId,omitempty"`
+		DeleteMarker          bool   `xml:"DeleteMarker,omitempty"`
+		DeleteMarkerVersionID string `xml:"DeleteMarkerVersionId,omitempty"`
+	}
+	type deleteError struct {
+		Key     string `xml:"Key"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	result := struct {
+		XMLName xml.Name      `xml:"DeleteResult"`
+		Xmlns   string        `xml:"xmlns,attr"`
+		Deleted []deleted     `xml:"Deleted"`
+		Errors  []deleteError `xml:"Error"`
+	}{Xmlns: xmlns}
+
+	for _, obj := range input.Objects {
+		v, err := s.remove(obj.Key, obj.VersionID)
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, deleteError{Key: obj.Key, Code: "InternalError", Message: err.Error()})
+		case input.Quiet:
+		case v.DeleteMarker && obj.VersionID == "":
+			result.Deleted = append(result.Deleted, deleted{Key: obj.Key, DeleteMarker: true, DeleteMarkerVersionID: v.ID})
+		default:
+			result.Deleted = append(result.Deleted, deleted{Key: obj.Key, VersionID: obj.VersionID, DeleteMarker: v.DeleteMarker})
+		}
+	}
+
+	writeXML(w, result)
+}
+
+func (s *Store) putTagging(w http.ResponseWriter, r *http.Request, key string) {
+	var input struct {
+		Tags []struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		} `xml:"TagSet>Tag"`
+	}
+
+	err := xml.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	tags := make(map[string]string, len(input.Tags))
+	for _, tag := range input.Tags {
+		tags[tag.Key] = tag.Value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.objects[key]
+	i := len(versions) - 1
+	if id := r.URL.Query().Get("versionId"); id != "" {
+		i = slices.IndexFunc(versions, func(v version) bool { return v.ID == id })
+	}
+	if i < 0 || versions[i].DeleteMarker {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+		return
+	}
+
+	versions[i].Tags = tags
+
+	err = s.save()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.Header().Set("x-amz-version-id", versions[i].ID)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Store) listObjects(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	type object struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int64  `xml:"Size"`
+		StorageClass string `xml:"StorageClass"`
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Xmlns       string   `xml:"xmlns,attr"`
+		Name        string   `xml:"Name"`
+		Prefix      string   `xml:"Prefix"`
+		KeyCount    int      `xml:"KeyCount"`
+		MaxKeys     int      `xml:"MaxKeys"`
+		IsTruncated bool     `xml:"IsTruncated"`
+		Contents    []object `xml:"Contents"`
+	}{Xmlns: xmlns, Name: s.bucket, Prefix: prefix}
+
+	s.mu.Lock()
+	for _, key := range s.keys(prefix) {
+		versions := s.objects[key]
+		v := versions[len(versions)-1]
+		if v.DeleteMarker {
+			continue
+		}
+
+		result.Contents = append(result.Contents, object{
+			Key:          key,
+			LastModified: timestamp(v.LastModified),
+			ETag:         v.ETag,
+			Size:         v.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	s.mu.Unlock()
+
+	result.KeyCount = len(result.Contents)
+	result.MaxKeys = max(1000, result.KeyCount)
+
+	writeXML(w, result)
+}
+
+func (s *Store) listVersions(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+
+	type objectVersion struct {
+		Key          string `xml:"Key"`
+		VersionID    string `xml:"VersionId"`
+		IsLatest     bool   `xml:"IsLatest"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag,omitempty"`
+		Size         int64  `xml:"Size"`
+		StorageClass string `xml:"StorageClass"`
+	}
+	type deleteMarker struct {
+		Key          string `xml:"Key"`
+		VersionID    string `xml:"VersionId"`
+		IsLatest     bool   `xml:"IsLatest"`
+		LastModified string `xml:"LastModified"`
+	}
+	result := struct {
+		XMLName       xml.Name        `xml:"ListVersionsResult"`
+		Xmlns         string          `xml:"xmlns,attr"`
+		Name          string          `xml:"Name"`
+		Prefix        string          `xml:"Prefix"`
+		MaxKeys       int             `xml:"MaxKeys"`
+		IsTruncated   bool            `xml:"IsTruncated"`
+		Versions      []objectVersion `xml:"Version"`
+		DeleteMarkers []deleteMarker  `xml:"DeleteMarker"`
+	}{Xmlns: xmlns, Name: s.bucket, Prefix: prefix}
+
+	s.mu.Lock()
+	for _, key := range s.keys(prefix) {
+		versions := s.objects[key]
+		for i, v := range slices.Backward(versions) {
+			latest := i == len(versions)-1
+			if v.DeleteMarker {
+				result.DeleteMarkers = append(result.DeleteMarkers, deleteMarker{
+					Key:          key,
+					VersionID:    v.ID,
+					IsLatest:     latest,
+					LastModified: timestamp(v.LastModified),
+				})
+				continue
+			}
+
+			result.Versions = append(result.Versions, objectVersion{
+				Key:          key,
+				VersionID:    v.ID,
+				IsLatest:     latest,
+				LastModified: timestamp(v.LastModified),
+				ETag:         v.ETag,
+				Size:         v.Size,
+				StorageClass: "STANDARD",
+			})
+		}
+	}
+	s.mu.Unlock()
+
+	result.MaxKeys = max(1000, len(result.Versions)+len(result.DeleteMarkers))
+
+	writeXML(w, result)
+}
+
+func (s *Store) createUpload(w http.ResponseWriter, r *http.Request, key string) {
+	id, err := newID()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	err = os.Mkdir(s.uploadPath(id), 0o755)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.uploads[id] = &upload{
+		key:         key,
+		contentType: r.Header.Get("Content-Type"),
+		parts:       make(map[int]string),
+	}
+	s.mu.Unlock()
+
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Bucket   string   `xml:"Bucket"`
+		Key      string   `xml:"Key"`
+		UploadID string   `xml:"UploadId"`
+	}{Xmlns: xmlns, Bucket: s.bucket, Key: key, UploadID: id})
+}
+
+func (s *Store) uploadPart(w http.ResponseWriter, r *http.Request, key string) {
+	q := r.URL.Query()
+	id := q.Get("uploadId")
+
+	number, err := strconv.Atoi(q.Get("partNumber"))
+	if err != nil || number < 1 || number > 10000 {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000")
+		return
+	}
+
+	s.mu.Lock()
+	u, ok := s.uploads[id]
+	s.mu.Unlock()
+	if !ok || u.key != key {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+
+	f, err := os.Create(filepath.Join(s.uploadPath(id), strconv.Itoa(number)))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+	defer f.Close()
+
+	sum := md5.New()
+	_, err = io.Copy(io.MultiWriter(f, sum), r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	etag := `"` + hex.EncodeToString(sum.Sum(nil)) + `"`
+
+	s.mu.Lock()
+	u.parts[number] = etag
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Store) completeUpload(w http.ResponseWriter, r *http.Request, key string) {
+	var input struct {
+		Parts []struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+		} `xml:"Part"`
+	}
+
+	err := xml.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	id := r.URL.Query().Get("uploadId")
+
+	s.mu.Lock()
+	u, ok := s.uploads[id]
+	if ok && u.key == key {
+		delete(s.uploads, id)
+	}
+	s.mu.Unlock()
+	if !ok || u.key != key {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+	defer os.RemoveAll(s.uploadPath(id))
+
+	var readers []io.Reader
+	for _, part := range input.Parts {
+		if u.parts[part.PartNumber] != part.ETag {
+			writeError(w, r, http.StatusBadRequest, "InvalidPart", fmt.Sprintf("Part %d was not uploaded or its ETag does not match", part.PartNumber))
+			return
+		}
+
+		f, err := os.Open(filepath.Join(s.uploadPath(id), strconv.Itoa(part.PartNumber)))
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+			return
+		}
+		defer f.Close()
+
+		readers = append(readers, f)
+	}
+
+	v, err := s.write(io.MultiReader(readers...), "")
+	if err != nil {
+		s.writeFailed(w, r, err)
+		return
+	}
+	v.ContentType = u.contentType
+	v.ETag = fmt.Sprintf(`%s-%d"`, strings.TrimSuffix(v.ETag, `"`), len(input.Parts))
+
+	err = s.add(key, v)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+		return
+	}
+
+	w.Header().Set("x-amz-version-id", v.ID)
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Bucket  string   `xml:"Bucket"`
+		Key     string   `xml:"Key"`
+		ETag    string   `xml:"ETag"`
+	}{Xmlns: xmlns, Bucket: s.bucket, Key: key, ETag: v.ETag})
+}
+
+func (s *Store) abortUpload(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("uploadId")
+
+	s.mu.Lock()
+	delete(s.uploads, id)
+	s.mu.Unlock()
+
+	os.RemoveAll(s.uploadPath(id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errBadDigest = errors.New("checksum does not match the content")
+
+// write stores body as a new version's data and returns the version, not yet
+// added to a key. When checksum is set it must be the base64 encoded SHA-256
+// of body, which S3 keeps as the object's checksum.
+func (s *Store) write(body io.Reader, checksum string) (version, error) {
+	id, err := newID()
+	if err != nil {
+		return version{}, err
+	}
+
+	f, err := os.Create(s.dataPath(id))
+	if err != nil {
+		return version{}, err
+	}
+	defer f.Close()
+
+	etag, digest := md5.New(), sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, etag, digest), body)
+	if err == nil && checksum != "" && checksum != encodeSum(digest) {
+		err = errBadDigest
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return version{}, err
+	}
+
+	return version{
+		ID:             id,
+		Size:           size,
+		ETag:           `"` + hex.EncodeToString(etag.Sum(nil)) + `"`,
+		ChecksumSHA256: checksum,
+		LastModified:   time.Now().UTC().Truncate(time.Millisecond),
+	}, nil
+}
+
+func (s *Store) writeFailed(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errBadDigest) {
+		writeError(w, r, http.StatusBadRequest, "BadDigest", "The SHA256 you specified did not match the calculated checksum")
+		return
+	}
+
+	writeError(w, r, http.StatusInternalServerError, "InternalError", err.Error())
+}
+
+// add makes v the latest version of key.
+func (s *Store) add(key string, v version) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = append(s.objects[key], v)
+
+	return s.save()
+}
+
+// remove deletes the given version of key, or places a delete marker on it
+// when versionID is empty, and returns the removed version or the marker.
+func (s *Store) remove(key, versionID string) (version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if versionID == "" {
+		id, err := newID()
+		if err != nil {
+			return version{}, err
+		}
+
+		marker := version{ID: id, DeleteMarker: true, LastModified: time.Now().UTC().Truncate(time.Millisecond)}
+		s.objects[key] = append(s.objects[key], marker)
+
+		return marker, s.save()
+	}
+
+	versions := s.objects[key]
+	i := slices.IndexFunc(versions, func(v version) bool { return v.ID == versionID })
+	if i < 0 {
+		return version{ID: versionID}, nil
+	}
+
+	v := versions[i]
+	versions = slices.Delete(versions, i, i+1)
+	if len(versions) == 0 {
+		delete(s.objects, key)
+	} else {
+		s.objects[key] = versions
+	}
+
+	err := s.save()
+	if err != nil {
+		return version{}, err
+	}
+
+	if !v.DeleteMarker {
+		os.Remove(s.dataPath(v.ID))
+	}
+
+	return v, nil
+}
+
+// find returns the given version of key, or its latest when versionID is
+// empty. The caller must hold s.mu.
+func (s *Store) find(key, versionID string) (version, bool) {
+	versions := s.objects[key]
+	if len(versions) == 0 {
+		return version{}, false
+	}
+
+	if versionID == "" {
+		return versions[len(versions)-1], true
+	}
+
+	i := slices.IndexFunc(versions, func(v version) bool { return v.ID == versionID })
+	if i < 0 {
+		return version{}, false
+	}
+
+	return versions[i], true
+}
+
+// keys returns the keys starting with prefix in sorted order. The caller must
+// hold s.mu.
+func (s *Store) keys(prefix string) []string {
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// save writes the index through a temporary file so a crash never leaves it
+// half written. The caller must hold s.mu.
+func (s *Store) save() error {
+	index, err := json.Marshal(s.objects)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, "index.json")
+
+	err = os.WriteFile(path+".tmp", index, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, "data", id)
+}
+
+func (s *Store) uploadPath(id string) string {
+	return filepath.Join(s.dir, "uploads", id)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func encodeSum(h hash.Hash) string {
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string   `xml:"Code"`
+		Message  string   `xml:"Message"`
+		Resource string   `xml:"Resource"`
+	}{Code: code, Message: message, Resource: r.URL.Path})
+}
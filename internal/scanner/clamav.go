@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const chunkSize = 64 * 1024
+
+// ClamAV streams content to a clamd daemon using the INSTREAM command.
+type ClamAV struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewClamAV(addr string) ClamAV {
+	return ClamAV{
+		addr:    addr,
+		timeout: 2 * time.Minute,
+	}
+}
+
+// Scan reports whether r is clean. When it is not, the detected signature
+// name is returned.
+func (c ClamAV) Scan(ctx context.Context, r io.Reader) (bool, string, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return false, "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	_, err = conn.Write([]byte("zINSTREAM\x00"))
+	if err != nil {
+		return false, "", err
+	}
+
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			_, err = conn.Write(size)
+			if err != nil {
+				return false, "", err
+			}
+			_, err = conn.Write(buf[:n])
+			if err != nil {
+				return false, "", err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return false, "", readErr
+		}
+	}
+
+	_, err = conn.Write([]byte{0, 0, 0, 0})
+	if err != nil {
+		return false, "", err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, "", err
+	}
+	reply = strings.TrimRight(reply, "\x00\n")
+
+	switch {
+	case strings.HasSuffix(reply, "OK"):
+		return true, "", nil
+	case strings.HasSuffix(reply, "FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return false, signature, nil
+	default:
+		return false, "", fmt.Errorf("clamd: %s", reply)
+	}
+}
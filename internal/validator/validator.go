@@ -6,8 +6,11 @@ import (
 )
 
 var (
-	EmailRX = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
-	UID     = regexp.MustCompile(`^E\d{4}$`)
+	EmailRX     = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+	UID         = regexp.MustCompile(`^E\d{4}$`)
+	SHA256HexRX = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+	CurrencyRX  = regexp.MustCompile(`^[A-Z]{3}$`)
+	ColorRX     = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
 )
 
 type Validator struct {
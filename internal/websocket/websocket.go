@@ -0,0 +1,273 @@
+// Package websocket implements the server side of the WebSocket protocol
+// (RFC 6455) for the API's push channel. Only what the API needs is
+// supported: unfragmented messages, the ping, pong and close control frames,
+// and no extensions or subprotocols.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	opText   = 0x1
+	opBinary = 0x2
+	opClose  = 0x8
+	opPing   = 0x9
+	opPong   = 0xa
+
+	CloseNormal      = 1000
+	CloseGoingAway   = 1001
+	CloseProtocol    = 1002
+	CloseUnsupported = 1003
+	CloseTooLarge    = 1009
+
+	// MaxMessageSize bounds the messages a client may send. The push
+	// channel expects nothing but control frames from clients.
+	MaxMessageSize = 4096
+
+	writeTimeout = 10 * time.Second
+
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+var (
+	ErrBadHandshake = errors.New("websocket: not a valid websocket handshake")
+	ErrClosed       = errors.New("websocket: connection closed")
+	ErrProtocol     = errors.New("websocket: protocol error")
+)
+
+// Conn is an upgraded WebSocket connection. Writes are safe for concurrent
+// use; ReadMessage must be called from a single goroutine.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	// ReadTimeout, when set, is how long ReadMessage waits for each frame,
+	// pongs included, before failing.
+	ReadTimeout time.Duration
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Upgrade completes the opening handshake of r and takes over its
+// connection. It returns ErrBadHandshake, having written nothing, when r is
+// not a version 13 WebSocket handshake.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		err != nil || len(decoded) != 16 {
+		return nil, ErrBadHandshake
+	}
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	// The server's read and write timeouts no longer apply once the
+	// connection is hijacked, but deadlines they set are still in place.
+	netConn.SetDeadline(time.Time{})
+
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+
+	err = brw.Flush()
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: netConn, br: brw.Reader}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContains reports whether a comma-separated header lists token,
+// ignoring case.
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// WriteText sends p as a single text message.
+func (c *Conn) WriteText(p []byte) error {
+	return c.writeFrame(opText, p)
+}
+
+// Ping sends a ping, which the client answers with a pong.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close sends a close frame with code and closes the connection without
+// waiting for the client to answer it. Closing twice is a no-op.
+func (c *Conn) Close(code int) error {
+	c.writeFrame(opClose, closePayload(code))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	return c.conn.Close()
+}
+
+func closePayload(code int) []byte {
+	return binary.BigEndian.AppendUint16(nil, uint16(code))
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	header := []byte{0x80 | opcode, 0}
+
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	_, err := c.conn.Write(append(header, payload...))
+	return err
+}
+
+// ReadMessage returns the next text or binary message from the client.
+// Pings are answered and pongs skipped along the way. A close frame is
+// answered and reported as ErrClosed, and a protocol violation closes the
+// connection and returns ErrProtocol.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			if errors.Is(err, ErrProtocol) {
+				c.Close(CloseProtocol)
+			}
+			return nil, err
+		}
+
+		switch opcode {
+		case opText, opBinary:
+			return payload, nil
+		case opPing:
+			err = c.writeFrame(opPong, payload)
+			if err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opClose:
+			c.Close(CloseNormal)
+			return nil, ErrClosed
+		default:
+			c.Close(CloseUnsupported)
+			return nil, ErrProtocol
+		}
+	}
+}
+
+func (c *Conn) readFrame() (byte, []byte, error) {
+	if c.ReadTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.ReadTimeout))
+	}
+
+	var head [2]byte
+	_, err := io.ReadFull(c.br, head[:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+
+	// Clients must mask every frame and the reserved bits must be clear;
+	// fragmented messages are not supported.
+	if !fin || head[0]&0x70 != 0 || !masked {
+		return 0, nil, ErrProtocol
+	}
+
+	length := uint64(head[1] & 0x7f)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(c.br, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, err = io.ReadFull(c.br, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if opcode >= opClose && length > 125 {
+		return 0, nil, ErrProtocol
+	}
+
+	if length > MaxMessageSize {
+		c.Close(CloseTooLarge)
+		return 0, nil, ErrClosed
+	}
+
+	var mask [4]byte
+	_, err = io.ReadFull(c.br, mask[:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.br, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return opcode, payload, nil
+}
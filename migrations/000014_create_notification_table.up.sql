@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS notification (
+    internal_id bigserial PRIMARY KEY,
+    appuser_internal_id integer NOT NULL,
+    category text NOT NULL,
+    message text NOT NULL,
+    link text,
+    read_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (appuser_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notification_unread ON notification (appuser_internal_id) WHERE read_at IS NULL;
\ No newline at end of file
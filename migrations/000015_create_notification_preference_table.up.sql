@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS notification_preference (
+    appuser_internal_id integer NOT NULL,
+    category text NOT NULL,
+    email_enabled bool NOT NULL DEFAULT TRUE,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (appuser_internal_id, category),
+    FOREIGN KEY (appuser_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
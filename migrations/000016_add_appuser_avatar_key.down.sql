@@ -0,0 +1 @@
+ALTER TABLE appuser DROP COLUMN IF EXISTS avatar_key;
\ No newline at end of file
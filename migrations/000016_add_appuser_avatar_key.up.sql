@@ -0,0 +1 @@
+ALTER TABLE appuser ADD COLUMN IF NOT EXISTS avatar_key text;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE project DROP COLUMN IF EXISTS storage_bytes;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE project ADD COLUMN IF NOT EXISTS storage_bytes bigint NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS file;
\ No newline at end of file
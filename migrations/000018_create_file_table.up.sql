@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS file (
+    internal_id bigserial PRIMARY KEY,
+    key text UNIQUE NOT NULL,
+    project_internal_id integer NOT NULL,
+    uploader_internal_id integer,
+    size_bytes bigint NOT NULL,
+    mime_type text NOT NULL,
+    checksum_sha256 text NOT NULL,
+    scanned_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (uploader_internal_id) REFERENCES appuser(internal_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_file_project ON file (project_internal_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+UPDATE file SET checksum_sha256 = '' WHERE checksum_sha256 IS NULL;
+ALTER TABLE file ALTER COLUMN checksum_sha256 SET NOT NULL;
\ No newline at end of file
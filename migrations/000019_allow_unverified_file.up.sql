@@ -0,0 +1 @@
+ALTER TABLE file ALTER COLUMN checksum_sha256 DROP NOT NULL;
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_file_supersedes;
+DROP INDEX IF EXISTS idx_file_project_category;
+ALTER TABLE file DROP CONSTRAINT IF EXISTS file_supersedes_fkey;
+ALTER TABLE file DROP CONSTRAINT IF EXISTS file_category_check;
+ALTER TABLE file DROP COLUMN IF EXISTS supersedes_internal_id;
+ALTER TABLE file DROP COLUMN IF EXISTS category;
\ No newline at end of file
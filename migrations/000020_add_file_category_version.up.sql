@@ -0,0 +1,7 @@
+ALTER TABLE file ADD COLUMN category text;
+ALTER TABLE file ADD COLUMN supersedes_internal_id bigint;
+ALTER TABLE file ADD CONSTRAINT file_category_check CHECK (category IN ('drawings', 'contracts', 'photos', 'reports'));
+ALTER TABLE file ADD CONSTRAINT file_supersedes_fkey FOREIGN KEY (supersedes_internal_id) REFERENCES file(internal_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_file_project_category ON file (project_internal_id, category);
+CREATE INDEX idx_file_supersedes ON file (supersedes_internal_id);
\ No newline at end of file
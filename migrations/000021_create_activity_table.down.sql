@@ -0,0 +1 @@
+DROP TABLE IF EXISTS activity;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS activity (
+    internal_id serial PRIMARY KEY,
+    name text NOT NULL,
+    parent_internal_id integer,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (parent_internal_id) REFERENCES activity(internal_id) ON DELETE RESTRICT,
+    CHECK (parent_internal_id <> internal_id)
+);
+
+CREATE INDEX idx_activity_parent ON activity (parent_internal_id);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS project_activity (
+    project_internal_id integer NOT NULL,
+    activity_internal_id integer NOT NULL,
+    PRIMARY KEY (project_internal_id, activity_internal_id),
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (activity_internal_id) REFERENCES activity(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE project DROP COLUMN IF EXISTS org_internal_id;
+ALTER TABLE client DROP COLUMN IF EXISTS org_internal_id;
+ALTER TABLE appuser DROP COLUMN IF EXISTS org_internal_id;
+DROP TABLE IF EXISTS organization;
\ No newline at end of file
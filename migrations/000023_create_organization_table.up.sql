@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS organization (
+    internal_id serial PRIMARY KEY,
+    name text UNIQUE NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO organization (internal_id, name) VALUES (1, 'Default');
+SELECT setval('organization_internal_id_seq', 1);
+
+ALTER TABLE appuser ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+ALTER TABLE client ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+ALTER TABLE project ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_appuser_org ON appuser (org_internal_id);
+CREATE INDEX idx_client_org ON client (org_internal_id);
+CREATE INDEX idx_project_org ON project (org_internal_id);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_member;
+DROP TABLE IF EXISTS team;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS team (
+    internal_id serial PRIMARY KEY,
+    org_internal_id integer NOT NULL DEFAULT 1,
+    name text NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (org_internal_id, name),
+    FOREIGN KEY (org_internal_id) REFERENCES organization(internal_id) ON DELETE RESTRICT
+);
+
+CREATE TABLE IF NOT EXISTS team_member (
+    team_internal_id integer NOT NULL,
+    user_internal_id integer NOT NULL,
+    is_lead bool NOT NULL DEFAULT false,
+    PRIMARY KEY (team_internal_id, user_internal_id),
+    FOREIGN KEY (team_internal_id) REFERENCES team(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_team_member_user ON team_member (user_internal_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS approval_delegation;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS approval_delegation (
+    internal_id serial PRIMARY KEY,
+    delegator_internal_id integer NOT NULL,
+    delegate_internal_id integer NOT NULL,
+    starts_on date NOT NULL,
+    ends_on date NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (delegator_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (delegate_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE,
+    CHECK (delegator_internal_id <> delegate_internal_id),
+    CHECK (starts_on <= ends_on)
+);
+
+CREATE INDEX idx_approval_delegation_delegator ON approval_delegation (delegator_internal_id, starts_on, ends_on);
+CREATE INDEX idx_approval_delegation_delegate ON approval_delegation (delegate_internal_id, starts_on, ends_on);
\ No newline at end of file
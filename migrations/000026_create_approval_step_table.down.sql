@@ -0,0 +1 @@
+DROP TABLE IF EXISTS approval_step;
\ No newline at end of file
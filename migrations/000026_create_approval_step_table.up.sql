@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS approval_step (
+    internal_id serial PRIMARY KEY,
+    project_internal_id integer,
+    position integer NOT NULL,
+    name text NOT NULL,
+    approver_internal_id integer NOT NULL,
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (approver_internal_id) REFERENCES appuser(internal_id) ON DELETE RESTRICT,
+    CHECK (position > 0)
+);
+
+CREATE UNIQUE INDEX idx_approval_step_project_position ON approval_step (project_internal_id, position) WHERE project_internal_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_approval_step_default_position ON approval_step (position) WHERE project_internal_id IS NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE organization DROP COLUMN IF EXISTS base_currency;
+DROP TABLE IF EXISTS exchange_rate;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS exchange_rate (
+    base char(3) NOT NULL,
+    quote char(3) NOT NULL,
+    rate_date date NOT NULL,
+    rate numeric(18, 8) NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base, quote, rate_date),
+    CHECK (rate > 0)
+);
+
+ALTER TABLE organization ADD COLUMN base_currency char(3) NOT NULL DEFAULT 'USD';
\ No newline at end of file
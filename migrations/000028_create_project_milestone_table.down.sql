@@ -0,0 +1,2 @@
+ALTER TABLE proposal DROP COLUMN IF EXISTS due_on;
+DROP TABLE IF EXISTS project_milestone;
\ No newline at end of file
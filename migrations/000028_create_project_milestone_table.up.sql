@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS project_milestone (
+    internal_id serial PRIMARY KEY,
+    project_internal_id integer NOT NULL,
+    name text NOT NULL,
+    due_on date NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_milestone_project ON project_milestone (project_internal_id, due_on);
+
+ALTER TABLE proposal ADD COLUMN due_on date;
\ No newline at end of file
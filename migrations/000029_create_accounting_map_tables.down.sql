@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS accounting_item_map;
+DROP TABLE IF EXISTS accounting_customer_map;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS accounting_customer_map (
+    project_internal_id integer PRIMARY KEY,
+    customer text NOT NULL,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS accounting_item_map (
+    activity_internal_id integer PRIMARY KEY,
+    service_item text NOT NULL,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (activity_internal_id) REFERENCES activity(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
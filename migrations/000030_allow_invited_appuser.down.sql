@@ -0,0 +1,2 @@
+UPDATE appuser SET password_hash = '' WHERE password_hash IS NULL;
+ALTER TABLE appuser ALTER COLUMN password_hash SET NOT NULL;
\ No newline at end of file
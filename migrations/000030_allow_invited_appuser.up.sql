@@ -0,0 +1 @@
+ALTER TABLE appuser ALTER COLUMN password_hash DROP NOT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS timesheet_entry;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS timesheet_entry (
+    internal_id bigserial PRIMARY KEY,
+    user_internal_id integer NOT NULL,
+    project_internal_id integer NOT NULL,
+    activity_internal_id integer,
+    work_date date NOT NULL,
+    minutes integer NOT NULL CHECK (minutes > 0 AND minutes <= 1440),
+    note text NOT NULL DEFAULT '',
+    source text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (activity_internal_id) REFERENCES activity(internal_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_timesheet_entry_user_date ON timesheet_entry (user_internal_id, work_date);
+CREATE INDEX idx_timesheet_entry_project ON timesheet_entry (project_internal_id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE appuser DROP COLUMN IF EXISTS erased_at;
+
+DROP TABLE IF EXISTS audit_log;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    internal_id bigserial PRIMARY KEY,
+    action text NOT NULL,
+    entity text NOT NULL,
+    entity_id text NOT NULL,
+    detail jsonb NOT NULL DEFAULT '{}',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log (entity, entity_id);
+
+ALTER TABLE appuser ADD COLUMN erased_at timestamp(0) with time zone;
\ No newline at end of file
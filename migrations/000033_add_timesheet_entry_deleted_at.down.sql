@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_timesheet_entry_deleted;
+
+ALTER TABLE timesheet_entry DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE timesheet_entry ADD COLUMN deleted_at timestamp(0) with time zone;
+
+CREATE INDEX idx_timesheet_entry_deleted ON timesheet_entry (deleted_at) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
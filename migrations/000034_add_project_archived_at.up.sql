@@ -0,0 +1 @@
+ALTER TABLE project ADD COLUMN IF NOT EXISTS archived_at timestamp(0) with time zone;
\ No newline at end of file
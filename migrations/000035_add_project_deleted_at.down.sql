@@ -0,0 +1 @@
+ALTER TABLE project DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
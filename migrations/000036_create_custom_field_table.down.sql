@@ -0,0 +1,3 @@
+ALTER TABLE client DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE project DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS custom_field;
\ No newline at end of file
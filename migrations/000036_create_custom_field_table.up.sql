@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS custom_field (
+    internal_id serial PRIMARY KEY,
+    entity text NOT NULL CHECK (entity IN ('project', 'client')),
+    name text NOT NULL,
+    data_type text NOT NULL CHECK (data_type IN ('text', 'number', 'boolean', 'date')),
+    required boolean NOT NULL DEFAULT false,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (entity, name)
+);
+
+ALTER TABLE project ADD COLUMN IF NOT EXISTS custom_fields jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE client ADD COLUMN IF NOT EXISTS custom_fields jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_project_custom_fields ON project USING gin (custom_fields);
+CREATE INDEX idx_client_custom_fields ON client USING gin (custom_fields);
\ No newline at end of file
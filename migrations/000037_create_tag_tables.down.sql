@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS timesheet_entry_tag;
+DROP TABLE IF EXISTS project_tag;
+DROP TABLE IF EXISTS tag;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS tag (
+    internal_id serial PRIMARY KEY,
+    name text UNIQUE NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS project_tag (
+    project_internal_id integer NOT NULL,
+    tag_internal_id integer NOT NULL,
+    PRIMARY KEY (project_internal_id, tag_internal_id),
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_internal_id) REFERENCES tag(internal_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS timesheet_entry_tag (
+    entry_internal_id bigint NOT NULL,
+    tag_internal_id integer NOT NULL,
+    PRIMARY KEY (entry_internal_id, tag_internal_id),
+    FOREIGN KEY (entry_internal_id) REFERENCES timesheet_entry(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_internal_id) REFERENCES tag(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_tag_tag ON project_tag (tag_internal_id);
+CREATE INDEX idx_timesheet_entry_tag_tag ON timesheet_entry_tag (tag_internal_id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_client_location;
+ALTER TABLE client DROP COLUMN IF EXISTS latitude;
+ALTER TABLE client DROP COLUMN IF EXISTS longitude;
\ No newline at end of file
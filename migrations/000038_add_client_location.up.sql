@@ -0,0 +1,4 @@
+ALTER TABLE client ADD COLUMN IF NOT EXISTS longitude double precision;
+ALTER TABLE client ADD COLUMN IF NOT EXISTS latitude double precision;
+
+CREATE INDEX idx_client_location ON client USING gist ((ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography));
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM permission WHERE code = 'project:force-delete';
\ No newline at end of file
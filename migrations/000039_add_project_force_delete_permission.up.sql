@@ -0,0 +1,2 @@
+INSERT INTO permission (code)
+VALUES ('project:force-delete');
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_timesheet_entry_approver_status;
+
+ALTER TABLE timesheet_entry
+    DROP COLUMN IF EXISTS approver_internal_id,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS status;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE timesheet_entry
+    ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'approved', 'rejected')),
+    ADD COLUMN IF NOT EXISTS submitted_at timestamp(0) with time zone,
+    ADD COLUMN IF NOT EXISTS approver_internal_id integer REFERENCES appuser(internal_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_timesheet_entry_approver_status ON timesheet_entry (approver_internal_id, status) WHERE deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM permission
+WHERE code IN ('project:read-all', 'timesheet:read-all', 'timesheet:read-project');
\ No newline at end of file
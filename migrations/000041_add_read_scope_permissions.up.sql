@@ -0,0 +1,2 @@
+INSERT INTO permission (code)
+VALUES ('project:read-all'), ('timesheet:read-all'), ('timesheet:read-project');
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS project_budget;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS project_budget (
+    project_internal_id integer PRIMARY KEY,
+    minutes integer NOT NULL CHECK (minutes > 0),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
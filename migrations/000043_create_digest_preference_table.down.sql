@@ -0,0 +1 @@
+DROP TABLE IF EXISTS digest_preference;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS digest_preference (
+    appuser_internal_id integer PRIMARY KEY,
+    frequency text NOT NULL DEFAULT 'off' CHECK (frequency IN ('off', 'daily', 'weekly')),
+    weekday smallint NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+    last_sent_at timestamp(0) with time zone,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (appuser_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
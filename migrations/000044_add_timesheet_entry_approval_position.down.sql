@@ -0,0 +1 @@
+ALTER TABLE timesheet_entry DROP COLUMN IF EXISTS approval_position;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE timesheet_entry ADD COLUMN IF NOT EXISTS approval_position integer NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE appuser DROP COLUMN IF EXISTS hourly_cost;
\ No newline at end of file
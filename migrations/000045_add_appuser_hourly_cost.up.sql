@@ -0,0 +1 @@
+ALTER TABLE appuser ADD COLUMN IF NOT EXISTS hourly_cost numeric(12, 2) CHECK (hourly_cost >= 0);
\ No newline at end of file
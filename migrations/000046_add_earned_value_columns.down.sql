@@ -0,0 +1,3 @@
+ALTER TABLE project_milestone DROP COLUMN IF EXISTS completed_on;
+
+ALTER TABLE project_budget DROP COLUMN IF EXISTS cost;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE project_budget ADD COLUMN IF NOT EXISTS cost numeric(14, 2) CHECK (cost >= 0);
+
+ALTER TABLE project_milestone ADD COLUMN IF NOT EXISTS completed_on date;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS report_snapshot;
+DROP TABLE IF EXISTS timesheet_week_summary;
\ No newline at end of file
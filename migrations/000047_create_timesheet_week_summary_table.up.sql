@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS timesheet_week_summary (
+    project_internal_id integer NOT NULL,
+    user_internal_id integer NOT NULL,
+    activity_internal_id integer NOT NULL DEFAULT 0,
+    status text NOT NULL,
+    week date NOT NULL,
+    minutes bigint NOT NULL,
+    cost numeric NOT NULL DEFAULT 0,
+    PRIMARY KEY (project_internal_id, week, user_internal_id, activity_internal_id, status)
+);
+
+CREATE INDEX idx_timesheet_week_summary_week ON timesheet_week_summary (week);
+
+CREATE TABLE IF NOT EXISTS report_snapshot (
+    name text PRIMARY KEY,
+    through date NOT NULL,
+    row_count bigint NOT NULL,
+    refreshed_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE project_appuser DROP COLUMN IF EXISTS manager;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE project_appuser ADD COLUMN IF NOT EXISTS manager boolean NOT NULL DEFAULT false;
\ No newline at end of file
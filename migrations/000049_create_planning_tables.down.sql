@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS holiday;
+DROP TABLE IF EXISTS user_leave;
+DROP TABLE IF EXISTS allocation;
\ No newline at end of file
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS allocation (
+    internal_id serial PRIMARY KEY,
+    user_internal_id integer NOT NULL,
+    project_internal_id integer NOT NULL,
+    week date NOT NULL CHECK (EXTRACT(ISODOW FROM week) = 1),
+    hours numeric(5, 2) NOT NULL CHECK (hours > 0 AND hours <= 168),
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (user_internal_id, project_internal_id, week),
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (project_internal_id) REFERENCES project(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_allocation_week ON allocation (week);
+
+CREATE TABLE IF NOT EXISTS user_leave (
+    internal_id serial PRIMARY KEY,
+    user_internal_id integer NOT NULL,
+    starts_on date NOT NULL,
+    ends_on date NOT NULL,
+    note text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CHECK (ends_on >= starts_on),
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_leave_user ON user_leave (user_internal_id, starts_on);
+
+CREATE TABLE IF NOT EXISTS holiday (
+    internal_id serial PRIMARY KEY,
+    org_internal_id integer NOT NULL DEFAULT 1,
+    day date NOT NULL,
+    name text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (org_internal_id, day),
+    FOREIGN KEY (org_internal_id) REFERENCES organization(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
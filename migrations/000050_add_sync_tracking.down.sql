@@ -0,0 +1,19 @@
+DROP TRIGGER IF EXISTS project_appuser_tombstone ON project_appuser;
+DROP TRIGGER IF EXISTS timesheet_entry_tombstone ON timesheet_entry;
+DROP TRIGGER IF EXISTS activity_tombstone ON activity;
+DROP TRIGGER IF EXISTS project_tombstone ON project;
+DROP FUNCTION IF EXISTS record_sync_tombstone();
+
+DROP TRIGGER IF EXISTS timesheet_entry_tag_touch ON timesheet_entry_tag;
+DROP FUNCTION IF EXISTS touch_timesheet_entry_tags();
+
+DROP TRIGGER IF EXISTS project_deleted_touch ON project;
+DROP TRIGGER IF EXISTS project_appuser_touch ON project_appuser;
+DROP TRIGGER IF EXISTS timesheet_entry_touch ON timesheet_entry;
+DROP FUNCTION IF EXISTS touch_updated_at();
+
+DROP TABLE IF EXISTS sync_tombstone;
+
+DROP INDEX IF EXISTS idx_timesheet_entry_user_updated;
+ALTER TABLE project_appuser DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE timesheet_entry DROP COLUMN IF EXISTS updated_at;
\ No newline at end of file
@@ -0,0 +1,75 @@
+ALTER TABLE timesheet_entry ADD COLUMN IF NOT EXISTS updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
+UPDATE timesheet_entry SET updated_at = COALESCE(deleted_at, submitted_at, created_at);
+
+ALTER TABLE project_appuser ADD COLUMN IF NOT EXISTS updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_timesheet_entry_user_updated ON timesheet_entry (user_internal_id, updated_at);
+
+CREATE TABLE IF NOT EXISTS sync_tombstone (
+    internal_id bigserial PRIMARY KEY,
+    entity text NOT NULL,
+    entity_id bigint NOT NULL,
+    user_internal_id integer,
+    deleted_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sync_tombstone_deleted ON sync_tombstone (deleted_at);
+
+CREATE OR REPLACE FUNCTION touch_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER timesheet_entry_touch BEFORE UPDATE ON timesheet_entry
+    FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+CREATE TRIGGER project_appuser_touch BEFORE UPDATE ON project_appuser
+    FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+CREATE TRIGGER project_deleted_touch BEFORE UPDATE OF deleted_at ON project
+    FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+CREATE OR REPLACE FUNCTION touch_timesheet_entry_tags() RETURNS trigger AS $$
+BEGIN
+    UPDATE timesheet_entry SET updated_at = NOW()
+    WHERE internal_id = COALESCE(NEW.entry_internal_id, OLD.entry_internal_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER timesheet_entry_tag_touch AFTER INSERT OR DELETE ON timesheet_entry_tag
+    FOR EACH ROW EXECUTE FUNCTION touch_timesheet_entry_tags();
+
+CREATE OR REPLACE FUNCTION record_sync_tombstone() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'project' THEN
+        INSERT INTO sync_tombstone (entity, entity_id) VALUES ('project', OLD.project_id);
+    ELSIF TG_TABLE_NAME = 'activity' THEN
+        INSERT INTO sync_tombstone (entity, entity_id) VALUES ('activity', OLD.internal_id);
+    ELSIF TG_TABLE_NAME = 'timesheet_entry' THEN
+        INSERT INTO sync_tombstone (entity, entity_id, user_internal_id) VALUES ('timesheet', OLD.internal_id, OLD.user_internal_id);
+    ELSIF TG_TABLE_NAME = 'project_appuser' THEN
+        -- When the project itself is being deleted its own tombstone
+        -- covers the assignment.
+        INSERT INTO sync_tombstone (entity, entity_id, user_internal_id)
+        SELECT 'assignment', project_id, OLD.appuser_internal_id
+        FROM project
+        WHERE internal_id = OLD.project_internal_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER project_tombstone AFTER DELETE ON project
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone();
+
+CREATE TRIGGER activity_tombstone AFTER DELETE ON activity
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone();
+
+CREATE TRIGGER timesheet_entry_tombstone AFTER DELETE ON timesheet_entry
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone();
+
+CREATE TRIGGER project_appuser_tombstone AFTER DELETE ON project_appuser
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone();
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS timesheet_entry_version ON timesheet_entry;
+DROP FUNCTION IF EXISTS bump_version();
+
+DROP INDEX IF EXISTS idx_timesheet_entry_uuid;
+
+ALTER TABLE timesheet_entry DROP COLUMN IF EXISTS version;
+ALTER TABLE timesheet_entry DROP COLUMN IF EXISTS entry_uuid;
\ No newline at end of file
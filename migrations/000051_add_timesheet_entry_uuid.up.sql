@@ -0,0 +1,14 @@
+ALTER TABLE timesheet_entry ADD COLUMN IF NOT EXISTS entry_uuid uuid;
+ALTER TABLE timesheet_entry ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_timesheet_entry_uuid ON timesheet_entry (entry_uuid);
+
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER timesheet_entry_version BEFORE UPDATE ON timesheet_entry
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS job_schedule;
\ No newline at end of file
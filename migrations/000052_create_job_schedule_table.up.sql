@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS job_schedule (
+    name text PRIMARY KEY,
+    expression text NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    last_run_at timestamp(0) with time zone,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS org_settings;
\ No newline at end of file
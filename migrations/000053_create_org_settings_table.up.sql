@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS org_settings (
+    org_internal_id integer PRIMARY KEY,
+    sender_name text NOT NULL DEFAULT '',
+    sender_email text NOT NULL DEFAULT '',
+    reply_to text NOT NULL DEFAULT '',
+    logo_url text NOT NULL DEFAULT '',
+    primary_color text NOT NULL DEFAULT '',
+    accent_color text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (org_internal_id) REFERENCES organization(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
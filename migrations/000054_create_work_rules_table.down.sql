@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS timesheet_entry_cost;
+DROP TABLE IF EXISTS work_rules;
\ No newline at end of file
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS work_rules (
+    org_internal_id integer PRIMARY KEY,
+    day_minutes integer NOT NULL DEFAULT 480,
+    max_day_minutes integer NOT NULL DEFAULT 1440,
+    overtime_multiplier numeric(4, 2) NOT NULL DEFAULT 1,
+    weekend_multiplier numeric(4, 2) NOT NULL DEFAULT 1,
+    increment_minutes integer NOT NULL DEFAULT 1,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (org_internal_id) REFERENCES organization(internal_id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE VIEW timesheet_entry_cost AS
+SELECT e.internal_id,
+    e.minutes * e.rate
+        + GREATEST(LEAST(e.minutes, e.day_through - e.day_minutes), 0) * GREATEST(e.overtime_multiplier - e.rate, 0) AS cost_minutes
+FROM (
+    SELECT t.internal_id, t.minutes,
+        (
+            SELECT COALESCE(sum(d.minutes), 0)
+            FROM timesheet_entry d
+            WHERE d.user_internal_id = t.user_internal_id AND d.work_date = t.work_date
+                AND d.internal_id <= t.internal_id AND d.deleted_at IS NULL
+        ) AS day_through,
+        COALESCE(r.day_minutes, 480) AS day_minutes,
+        COALESCE(r.overtime_multiplier, 1) AS overtime_multiplier,
+        CASE WHEN EXTRACT(ISODOW FROM t.work_date) >= 6 THEN COALESCE(r.weekend_multiplier, 1) ELSE 1 END AS rate
+    FROM timesheet_entry t
+    INNER JOIN appuser u ON u.internal_id = t.user_internal_id
+    LEFT JOIN work_rules r ON r.org_internal_id = u.org_internal_id
+) e;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE work_rules DROP COLUMN IF EXISTS rounding;
+ALTER TABLE timesheet_entry DROP COLUMN IF EXISTS billed_minutes;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE timesheet_entry ADD COLUMN IF NOT EXISTS billed_minutes integer;
+ALTER TABLE work_rules ADD COLUMN IF NOT EXISTS rounding text NOT NULL DEFAULT 'up';
+
+UPDATE timesheet_entry SET billed_minutes = minutes WHERE status IN ('submitted', 'approved');
\ No newline at end of file
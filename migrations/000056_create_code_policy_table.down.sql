@@ -0,0 +1 @@
+DROP TABLE IF EXISTS code_policy;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS code_policy (
+    org_internal_id integer PRIMARY KEY,
+    project_format text NOT NULL DEFAULT '',
+    proposal_format text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (org_internal_id) REFERENCES organization(internal_id) ON DELETE CASCADE
+);
\ No newline at end of file
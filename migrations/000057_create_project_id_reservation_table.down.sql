@@ -0,0 +1 @@
+DROP TABLE IF EXISTS project_id_reservation;
\ No newline at end of file
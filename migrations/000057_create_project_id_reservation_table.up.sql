@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS project_id_reservation (
+    project_id integer PRIMARY KEY,
+    user_internal_id integer,
+    expires_at timestamp(0) with time zone NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE SET NULL
+);
\ No newline at end of file
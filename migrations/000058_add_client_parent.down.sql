@@ -0,0 +1 @@
+ALTER TABLE client DROP COLUMN IF EXISTS parent_internal_id;
\ No newline at end of file
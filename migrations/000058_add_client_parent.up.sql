@@ -0,0 +1,3 @@
+ALTER TABLE client ADD COLUMN IF NOT EXISTS parent_internal_id integer REFERENCES client(internal_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_client_parent ON client (parent_internal_id);
\ No newline at end of file
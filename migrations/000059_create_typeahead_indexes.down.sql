@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_project_name_prefix;
+DROP INDEX IF EXISTS idx_project_id_prefix;
+DROP INDEX IF EXISTS idx_project_proposal_prefix;
+DROP INDEX IF EXISTS idx_client_name_prefix;
+DROP INDEX IF EXISTS idx_activity_name_prefix;
+DROP INDEX IF EXISTS idx_appuser_first_name_prefix;
+DROP INDEX IF EXISTS idx_appuser_last_name_prefix;
+DROP INDEX IF EXISTS idx_appuser_email_prefix;
\ No newline at end of file
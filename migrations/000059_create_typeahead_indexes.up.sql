@@ -0,0 +1,8 @@
+CREATE INDEX IF NOT EXISTS idx_project_name_prefix ON project (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_project_id_prefix ON project ((project_id::text) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_project_proposal_prefix ON project (lower(proposal_id) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_client_name_prefix ON client (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_activity_name_prefix ON activity (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_appuser_first_name_prefix ON appuser (lower(first_name::text) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_appuser_last_name_prefix ON appuser (lower(last_name::text) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_appuser_email_prefix ON appuser (lower(email::text) text_pattern_ops);
\ No newline at end of file
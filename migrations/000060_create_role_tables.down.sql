@@ -0,0 +1,7 @@
+ALTER TABLE appuser_permission DROP COLUMN IF EXISTS granted_by_role;
+
+ALTER TABLE project_appuser DROP COLUMN IF EXISTS role;
+
+DROP TABLE IF EXISTS role_permission;
+
+DROP TABLE IF EXISTS role;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS role (
+    name text PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permission (
+    role text NOT NULL,
+    permission_internal_id integer NOT NULL,
+    PRIMARY KEY (role, permission_internal_id),
+    FOREIGN KEY (role) REFERENCES role(name) ON DELETE CASCADE,
+    FOREIGN KEY (permission_internal_id) REFERENCES permission(internal_id) ON DELETE CASCADE
+);
+
+INSERT INTO role (name) VALUES ('viewer'), ('editor') ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permission (role, permission_internal_id)
+SELECT r.role, p.internal_id
+FROM (VALUES ('viewer', 'project:read'), ('editor', 'project:read'), ('editor', 'project:write')) AS r (role, code)
+INNER JOIN permission p ON p.code = r.code
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE project_appuser ADD COLUMN IF NOT EXISTS role text REFERENCES role(name) ON DELETE SET NULL;
+
+ALTER TABLE appuser_permission ADD COLUMN IF NOT EXISTS granted_by_role boolean NOT NULL DEFAULT false;
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS appuser_claims ON appuser;
+DROP FUNCTION IF EXISTS bump_appuser_claims_version();
+
+DROP TRIGGER IF EXISTS appuser_permission_claims ON appuser_permission;
+DROP FUNCTION IF EXISTS bump_claims_version();
+
+DROP INDEX IF EXISTS idx_appuser_claims_changed;
+ALTER TABLE appuser DROP COLUMN IF EXISTS claims_changed_at;
+ALTER TABLE appuser DROP COLUMN IF EXISTS claims_version;
\ No newline at end of file
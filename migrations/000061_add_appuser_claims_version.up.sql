@@ -0,0 +1,27 @@
+ALTER TABLE appuser ADD COLUMN IF NOT EXISTS claims_version integer NOT NULL DEFAULT 1;
+ALTER TABLE appuser ADD COLUMN IF NOT EXISTS claims_changed_at timestamp with time zone NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_appuser_claims_changed ON appuser (claims_changed_at);
+
+CREATE OR REPLACE FUNCTION bump_claims_version() RETURNS trigger AS $$
+BEGIN
+    UPDATE appuser SET claims_version = claims_version + 1, claims_changed_at = NOW()
+    WHERE internal_id = COALESCE(NEW.user_internal_id, OLD.user_internal_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER appuser_permission_claims AFTER INSERT OR DELETE ON appuser_permission
+    FOR EACH ROW EXECUTE FUNCTION bump_claims_version();
+
+CREATE OR REPLACE FUNCTION bump_appuser_claims_version() RETURNS trigger AS $$
+BEGIN
+    NEW.claims_version = OLD.claims_version + 1;
+    NEW.claims_changed_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER appuser_claims BEFORE UPDATE OF org_internal_id, erased_at ON appuser
+    FOR EACH ROW WHEN (OLD.org_internal_id IS DISTINCT FROM NEW.org_internal_id OR OLD.erased_at IS DISTINCT FROM NEW.erased_at)
+    EXECUTE FUNCTION bump_appuser_claims_version();
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE appuser DROP COLUMN IF EXISTS pending_email;
\ No newline at end of file
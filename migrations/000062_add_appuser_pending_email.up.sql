@@ -0,0 +1 @@
+ALTER TABLE appuser ADD COLUMN IF NOT EXISTS pending_email citext;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM permission WHERE code = 'security-event:read-all';
+
+DROP TABLE IF EXISTS security_event;
\ No newline at end of file
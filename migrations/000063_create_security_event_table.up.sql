@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS security_event (
+    internal_id bigserial PRIMARY KEY,
+    user_internal_id integer NOT NULL,
+    event text NOT NULL,
+    ip text NOT NULL DEFAULT '',
+    user_agent text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_security_event_user_created ON security_event (user_internal_id, created_at);
+
+INSERT INTO permission (code)
+VALUES ('security-event:read-all');
\ No newline at end of file
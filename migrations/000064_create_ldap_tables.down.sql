@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS appuser_role;
+
+DROP TABLE IF EXISTS ldap_group_role;
+
+DROP TABLE IF EXISTS ldap_config;
\ No newline at end of file
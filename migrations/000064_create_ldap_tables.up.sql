@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS ldap_config (
+    org_internal_id integer PRIMARY KEY,
+    url text NOT NULL,
+    bind_template text NOT NULL,
+    base_dn text NOT NULL,
+    login_attribute text NOT NULL,
+    email_attribute text NOT NULL,
+    first_name_attribute text NOT NULL,
+    last_name_attribute text NOT NULL,
+    group_attribute text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (org_internal_id) REFERENCES organization(internal_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS ldap_group_role (
+    org_internal_id integer NOT NULL,
+    group_dn text NOT NULL,
+    role text NOT NULL,
+    PRIMARY KEY (org_internal_id, group_dn, role),
+    FOREIGN KEY (org_internal_id) REFERENCES ldap_config(org_internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (role) REFERENCES role(name) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS appuser_role (
+    user_internal_id integer NOT NULL,
+    role text NOT NULL,
+    PRIMARY KEY (user_internal_id, role),
+    FOREIGN KEY (user_internal_id) REFERENCES appuser(internal_id) ON DELETE CASCADE,
+    FOREIGN KEY (role) REFERENCES role(name) ON DELETE CASCADE
+);
\ No newline at end of file
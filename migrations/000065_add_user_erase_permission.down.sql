@@ -0,0 +1 @@
+DELETE FROM permission WHERE code = 'user:erase';
\ No newline at end of file
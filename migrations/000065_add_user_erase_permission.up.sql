@@ -0,0 +1,2 @@
+INSERT INTO permission (code)
+VALUES ('user:erase');
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM permission WHERE code = 'role:write';
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO permission (code)
+VALUES ('role:write');
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM permission
+WHERE code IN ('organization:admin', 'organization:admin-all');
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO permission (code)
+VALUES ('organization:admin'), ('organization:admin-all');
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE appuser DROP COLUMN IF EXISTS directory_linked;
\ No newline at end of file
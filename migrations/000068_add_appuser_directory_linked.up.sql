@@ -0,0 +1 @@
+ALTER TABLE appuser ADD COLUMN IF NOT EXISTS directory_linked boolean NOT NULL DEFAULT false;
\ No newline at end of file
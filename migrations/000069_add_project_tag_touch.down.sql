@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS project_tag_touch ON project_tag;
+DROP FUNCTION IF EXISTS touch_project_tags();
\ No newline at end of file
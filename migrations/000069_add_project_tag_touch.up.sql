@@ -0,0 +1,10 @@
+CREATE OR REPLACE FUNCTION touch_project_tags() RETURNS trigger AS $$
+BEGIN
+    UPDATE project SET updated_at = NOW()
+    WHERE internal_id = COALESCE(NEW.project_internal_id, OLD.project_internal_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER project_tag_touch AFTER INSERT OR DELETE ON project_tag
+    FOR EACH ROW EXECUTE FUNCTION touch_project_tags();
\ No newline at end of file
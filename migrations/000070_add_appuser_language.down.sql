@@ -0,0 +1 @@
+ALTER TABLE appuser DROP COLUMN IF EXISTS language;
\ No newline at end of file
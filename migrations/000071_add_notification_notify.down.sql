@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS notification_notify ON notification;
+DROP FUNCTION IF EXISTS notify_notification();
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE OR REPLACE FUNCTION notify_notification() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('notification', json_build_object(
+        'id', NEW.internal_id,
+        'user_id', NEW.appuser_internal_id,
+        'category', NEW.category,
+        'message', NEW.message,
+        'link', NEW.link,
+        'read_at', NEW.read_at,
+        'created_at', NEW.created_at
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notification_notify AFTER INSERT ON notification
+    FOR EACH ROW EXECUTE FUNCTION notify_notification();
\ No newline at end of file
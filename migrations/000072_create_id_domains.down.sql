@@ -0,0 +1,2 @@
+DROP DOMAIN IF EXISTS uuid7;
+DROP DOMAIN IF EXISTS ulid;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM permission WHERE code = 'approval:write';
\ No newline at end of file
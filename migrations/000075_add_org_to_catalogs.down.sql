@@ -0,0 +1,9 @@
+ALTER TABLE custom_field DROP CONSTRAINT custom_field_org_internal_id_entity_name_key;
+ALTER TABLE custom_field ADD CONSTRAINT custom_field_entity_name_key UNIQUE (entity, name);
+ALTER TABLE tag DROP CONSTRAINT tag_org_internal_id_name_key;
+ALTER TABLE tag ADD CONSTRAINT tag_name_key UNIQUE (name);
+
+ALTER TABLE proposal DROP COLUMN IF EXISTS org_internal_id;
+ALTER TABLE custom_field DROP COLUMN IF EXISTS org_internal_id;
+ALTER TABLE tag DROP COLUMN IF EXISTS org_internal_id;
+ALTER TABLE activity DROP COLUMN IF EXISTS org_internal_id;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE activity ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+ALTER TABLE tag ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+ALTER TABLE custom_field ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+ALTER TABLE proposal ADD COLUMN org_internal_id integer NOT NULL DEFAULT 1 REFERENCES organization(internal_id) ON DELETE RESTRICT;
+
+ALTER TABLE tag DROP CONSTRAINT tag_name_key;
+ALTER TABLE tag ADD CONSTRAINT tag_org_internal_id_name_key UNIQUE (org_internal_id, name);
+ALTER TABLE custom_field DROP CONSTRAINT custom_field_entity_name_key;
+ALTER TABLE custom_field ADD CONSTRAINT custom_field_org_internal_id_entity_name_key UNIQUE (org_internal_id, entity, name);
+
+CREATE INDEX idx_activity_org ON activity (org_internal_id);
+CREATE INDEX idx_proposal_org ON proposal (org_internal_id);
\ No newline at end of file
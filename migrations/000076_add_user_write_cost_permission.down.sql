@@ -0,0 +1 @@
+DELETE FROM permission WHERE code = 'user:write-cost';
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO permission (code)
+VALUES ('user:write-cost');
\ No newline at end of file
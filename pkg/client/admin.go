@@ -0,0 +1,383 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Status describes the running server build and the optional features it
+// has enabled.
+type Status struct {
+	Status        string            `json:"status"`
+	Build         map[string]string `json:"build"`
+	Environment   string            `json:"environment"`
+	StartedAt     time.Time         `json:"started_at"`
+	Uptime        string            `json:"uptime"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Features      map[string]bool   `json:"features"`
+}
+
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	err := c.Do(ctx, http.MethodGet, "/v1/status", nil, nil, &status, "")
+	return &status, err
+}
+
+// AccessToken is a short-lived token authorizing requests without the
+// server reading the user's permissions, for use with WithToken.
+type AccessToken struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// CreateAccessToken exchanges the client's authentication token for an
+// access token. Create a new one once it expires or is rejected, as access
+// tokens stop working when the user's permissions change.
+func (c *Client) CreateAccessToken(ctx context.Context) (*AccessToken, error) {
+	var token AccessToken
+	err := c.Do(ctx, http.MethodPost, "/v1/token/access", nil, nil, &token, "access_token")
+	return &token, err
+}
+
+// LogoutAll revokes every authentication and access token of the client's
+// user, including the one the client uses, and returns how many
+// authentication tokens were revoked.
+func (c *Client) LogoutAll(ctx context.Context) (int64, error) {
+	var revoked int64
+	err := c.Do(ctx, http.MethodPost, "/v1/me/logout-all", nil, nil, &revoked, "revoked_tokens")
+	return revoked, err
+}
+
+// RequestEmailChange emails a confirmation code to the address the client's
+// user wants to change theirs to.
+func (c *Client) RequestEmailChange(ctx context.Context, email string) error {
+	return c.Do(ctx, http.MethodPut, "/v1/me/email", nil, map[string]any{"email": email}, nil, "")
+}
+
+// ConfirmEmailChange applies the email change a confirmation code was sent
+// for, returning the user with their new address.
+func (c *Client) ConfirmEmailChange(ctx context.Context, token string) (*User, error) {
+	var user User
+	err := c.Do(ctx, http.MethodPut, "/v1/me/email/confirm", nil, map[string]any{"token": token}, &user, "user")
+	return &user, err
+}
+
+// CheckPassword reports whether a password meets the server's password
+// policy, returning the validation error naming the rule it breaks if not.
+func (c *Client) CheckPassword(ctx context.Context, password string) error {
+	return c.Do(ctx, http.MethodPost, "/v1/password/check", nil, map[string]any{"password": password}, nil, "")
+}
+
+// ListSecurityEvents lists the security history of the client's user, only
+// events of one kind when event is set.
+func (c *Client) ListSecurityEvents(ctx context.Context, event string, opts ListOptions) ([]*SecurityEvent, Metadata, error) {
+	return c.listSecurityEvents(ctx, "/v1/me/security-events", event, opts)
+}
+
+// ListUserSecurityEvents lists another user's security history, which needs
+// the security-event:read-all permission.
+func (c *Client) ListUserSecurityEvents(ctx context.Context, userID int32, event string, opts ListOptions) ([]*SecurityEvent, Metadata, error) {
+	return c.listSecurityEvents(ctx, pathf("/v1/user/%s/security-events", userID), event, opts)
+}
+
+func (c *Client) listSecurityEvents(ctx context.Context, path, event string, opts ListOptions) ([]*SecurityEvent, Metadata, error) {
+	q := url.Values{}
+	setString(q, "event", event)
+	opts.encode(q)
+
+	var out struct {
+		Metadata       Metadata         `json:"metadata"`
+		SecurityEvents []*SecurityEvent `json:"security_events"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, path, q, nil, &out, "")
+	return out.SecurityEvents, out.Metadata, err
+}
+
+// RouteDeprecation says when a route was deprecated, when it is removed
+// and what replaces it.
+type RouteDeprecation struct {
+	Since     time.Time  `json:"since"`
+	Sunset    *time.Time `json:"sunset"`
+	Successor string     `json:"successor,omitempty"`
+}
+
+type Route struct {
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	Authentication bool              `json:"authentication"`
+	Permissions    []string          `json:"permissions"`
+	Deprecation    *RouteDeprecation `json:"deprecation"`
+}
+
+// ListRoutes lists every route the server has registered.
+func (c *Client) ListRoutes(ctx context.Context) ([]*Route, error) {
+	var routes []*Route
+	err := c.Do(ctx, http.MethodGet, "/v1/admin/routes", nil, nil, &routes, "routes")
+	return routes, err
+}
+
+func (c *Client) GetDigestPreference(ctx context.Context) (*DigestPreference, error) {
+	var preference DigestPreference
+	err := c.Do(ctx, http.MethodGet, "/v1/me/digest", nil, nil, &preference, "digest")
+	return &preference, err
+}
+
+// SetDigestPreference sets how often the caller is emailed a digest: off,
+// daily or weekly, on weekday (0 for Sunday) when weekly.
+func (c *Client) SetDigestPreference(ctx context.Context, frequency string, weekday *int) (*DigestPreference, error) {
+	var preference DigestPreference
+	err := c.Do(ctx, http.MethodPut, "/v1/me/digest", nil, map[string]any{"frequency": frequency, "weekday": weekday}, &preference, "digest")
+	return &preference, err
+}
+
+// SetLanguage sets the language the caller's emails are sent in.
+func (c *Client) SetLanguage(ctx context.Context, language string) error {
+	return c.Do(ctx, http.MethodPut, "/v1/me/language", nil, map[string]any{"language": language}, nil, "")
+}
+
+// GetNotificationPreferences reports, for every notification category,
+// whether the caller is emailed its notifications.
+func (c *Client) GetNotificationPreferences(ctx context.Context) ([]*NotificationPreference, error) {
+	var preferences []*NotificationPreference
+	err := c.Do(ctx, http.MethodGet, "/v1/me/preferences", nil, nil, &preferences, "preferences")
+	return preferences, err
+}
+
+// SetNotificationPreferences changes the given categories and returns the
+// caller's preferences for every category.
+func (c *Client) SetNotificationPreferences(ctx context.Context, preferences []*NotificationPreference) ([]*NotificationPreference, error) {
+	var stored []*NotificationPreference
+	err := c.Do(ctx, http.MethodPut, "/v1/me/preferences", nil, map[string]any{"preferences": preferences}, &stored, "preferences")
+	return stored, err
+}
+
+// GetApprovalSteps returns the organization's default approval chain.
+func (c *Client) GetApprovalSteps(ctx context.Context) ([]*ApprovalStep, error) {
+	var steps []*ApprovalStep
+	err := c.Do(ctx, http.MethodGet, "/v1/approval-steps", nil, nil, &steps, "steps")
+	return steps, err
+}
+
+func (c *Client) SetApprovalSteps(ctx context.Context, steps []*ApprovalStep) ([]*ApprovalStep, error) {
+	var stored []*ApprovalStep
+	err := c.Do(ctx, http.MethodPut, "/v1/approval-steps", nil, map[string]any{"steps": steps}, &stored, "steps")
+	return stored, err
+}
+
+func (c *Client) ListOrganizations(ctx context.Context) ([]*Organization, error) {
+	var orgs []*Organization
+	err := c.Do(ctx, http.MethodGet, "/v1/admin/organization", nil, nil, &orgs, "organizations")
+	return orgs, err
+}
+
+func (c *Client) GetOrganization(ctx context.Context, id int32) (*Organization, error) {
+	var org Organization
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/admin/organization/%s", id), nil, nil, &org, "organization")
+	return &org, err
+}
+
+func (c *Client) CreateOrganization(ctx context.Context, name, baseCurrency string) (*Organization, error) {
+	var org Organization
+	err := c.Do(ctx, http.MethodPost, "/v1/admin/organization", nil, map[string]any{"name": name, "base_currency": baseCurrency}, &org, "organization")
+	return &org, err
+}
+
+// UpdateOrganization changes an organization's name or base currency,
+// leaving whichever is nil as it is.
+func (c *Client) UpdateOrganization(ctx context.Context, id int32, name, baseCurrency *string) (*Organization, error) {
+	input := map[string]any{}
+	if name != nil {
+		input["name"] = *name
+	}
+	if baseCurrency != nil {
+		input["base_currency"] = *baseCurrency
+	}
+
+	var org Organization
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/admin/organization/%s", id), nil, input, &org, "organization")
+	return &org, err
+}
+
+func (c *Client) DeleteOrganization(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/admin/organization/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) GetOrgSettings(ctx context.Context, orgID int32) (*OrgSettings, error) {
+	var settings OrgSettings
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/admin/organization/%s/settings", orgID), nil, nil, &settings, "settings")
+	return &settings, err
+}
+
+// OrgSettingsChange changes an organization's email branding, leaving nil
+// fields as they are. An empty string puts a field back to the default.
+type OrgSettingsChange struct {
+	SenderName   *string `json:"sender_name,omitempty"`
+	SenderEmail  *string `json:"sender_email,omitempty"`
+	ReplyTo      *string `json:"reply_to,omitempty"`
+	LogoURL      *string `json:"logo_url,omitempty"`
+	PrimaryColor *string `json:"primary_color,omitempty"`
+	AccentColor  *string `json:"accent_color,omitempty"`
+}
+
+func (c *Client) UpdateOrgSettings(ctx context.Context, orgID int32, change OrgSettingsChange) (*OrgSettings, error) {
+	var settings OrgSettings
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/admin/organization/%s/settings", orgID), nil, change, &settings, "settings")
+	return &settings, err
+}
+
+func (c *Client) GetWorkRules(ctx context.Context, orgID int32) (*WorkRules, error) {
+	var rules WorkRules
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/admin/organization/%s/work-rules", orgID), nil, nil, &rules, "work_rules")
+	return &rules, err
+}
+
+// WorkRulesChange changes an organization's work rules, leaving nil fields
+// as they are.
+type WorkRulesChange struct {
+	DayMinutes         *int32   `json:"day_minutes,omitempty"`
+	MaxDayMinutes      *int32   `json:"max_day_minutes,omitempty"`
+	OvertimeMultiplier *float64 `json:"overtime_multiplier,omitempty"`
+	WeekendMultiplier  *float64 `json:"weekend_multiplier,omitempty"`
+	IncrementMinutes   *int32   `json:"increment_minutes,omitempty"`
+	// Rounding is up, down or nearest.
+	Rounding *string `json:"rounding,omitempty"`
+}
+
+func (c *Client) UpdateWorkRules(ctx context.Context, orgID int32, change WorkRulesChange) (*WorkRules, error) {
+	var rules WorkRules
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/admin/organization/%s/work-rules", orgID), nil, change, &rules, "work_rules")
+	return &rules, err
+}
+
+func (c *Client) GetCodePolicy(ctx context.Context, orgID int32) (*CodePolicy, error) {
+	var policy CodePolicy
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/admin/organization/%s/code-policy", orgID), nil, nil, &policy, "code_policy")
+	return &policy, err
+}
+
+// CodePolicyChange changes an organization's code policy, leaving nil
+// fields as they are. An empty format allows any code.
+type CodePolicyChange struct {
+	ProjectFormat  *string `json:"project_format,omitempty"`
+	ProposalFormat *string `json:"proposal_format,omitempty"`
+}
+
+func (c *Client) UpdateCodePolicy(ctx context.Context, orgID int32, change CodePolicyChange) (*CodePolicy, error) {
+	var policy CodePolicy
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/admin/organization/%s/code-policy", orgID), nil, change, &policy, "code_policy")
+	return &policy, err
+}
+
+func (c *Client) GetLDAPConfig(ctx context.Context, orgID int32) (*LDAPConfig, error) {
+	var config LDAPConfig
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/admin/organization/%s/ldap", orgID), nil, nil, &config, "ldap")
+	return &config, err
+}
+
+// LDAPSettings is how an organization's users sign in with their directory
+// credentials. Attribute names left empty default to those of Active
+// Directory.
+type LDAPSettings struct {
+	URL                string          `json:"url"`
+	BindTemplate       string          `json:"bind_template"`
+	BaseDN             string          `json:"base_dn"`
+	LoginAttribute     string          `json:"login_attribute,omitempty"`
+	EmailAttribute     string          `json:"email_attribute,omitempty"`
+	FirstNameAttribute string          `json:"first_name_attribute,omitempty"`
+	LastNameAttribute  string          `json:"last_name_attribute,omitempty"`
+	GroupAttribute     string          `json:"group_attribute,omitempty"`
+	GroupRoles         []LDAPGroupRole `json:"group_roles"`
+}
+
+// PutLDAPConfig lets an organization's users sign in with LoginLDAP, or
+// replaces how.
+func (c *Client) PutLDAPConfig(ctx context.Context, orgID int32, settings LDAPSettings) (*LDAPConfig, error) {
+	var config LDAPConfig
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/admin/organization/%s/ldap", orgID), nil, settings, &config, "ldap")
+	return &config, err
+}
+
+func (c *Client) DeleteLDAPConfig(ctx context.Context, orgID int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/admin/organization/%s/ldap", orgID), nil, nil, nil, "")
+}
+
+// LinkLDAPUser lets the organization's directory sign in to an existing
+// account, which LoginLDAP otherwise refuses.
+func (c *Client) LinkLDAPUser(ctx context.Context, orgID, userID int32) error {
+	return c.Do(ctx, http.MethodPut, pathf("/v1/admin/organization/%s/ldap/links/%s", orgID, userID), nil, nil, nil, "")
+}
+
+func (c *Client) UnlinkLDAPUser(ctx context.Context, orgID, userID int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/admin/organization/%s/ldap/links/%s", orgID, userID), nil, nil, nil, "")
+}
+
+// LoginLDAP signs a user in with their credentials in their organization's
+// directory, returning an authentication token for use with WithToken.
+func (c *Client) LoginLDAP(ctx context.Context, orgID int32, username, password string) (*Token, error) {
+	var token Token
+	body := map[string]any{"organization_id": orgID, "username": username, "password": password}
+	err := c.Do(ctx, http.MethodPost, "/v1/token/ldap", nil, body, &token, "authentication_token")
+	return &token, err
+}
+
+func (c *Client) ListRoles(ctx context.Context) ([]*Role, error) {
+	var roles []*Role
+	err := c.Do(ctx, http.MethodGet, "/v1/admin/role", nil, nil, &roles, "roles")
+	return roles, err
+}
+
+// PutRole creates a role or replaces the permissions it grants.
+func (c *Client) PutRole(ctx context.Context, name string, permissions []string) (*Role, error) {
+	var role Role
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/admin/role/%s", name), nil, map[string]any{"permissions": permissions}, &role, "role")
+	return &role, err
+}
+
+func (c *Client) DeleteRole(ctx context.Context, name string) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/admin/role/%s", name), nil, nil, nil, "")
+}
+
+// RecomputeRoles syncs every user's role permissions with the roles they
+// hold on projects and through the directory.
+func (c *Client) RecomputeRoles(ctx context.Context) (*RoleSync, error) {
+	var sync RoleSync
+	err := c.Do(ctx, http.MethodPost, "/v1/admin/role/recompute", nil, nil, &sync, "sync")
+	return &sync, err
+}
+
+// JobSchedule is when a background job runs. NextRunAt is null while the
+// job is disabled.
+type JobSchedule struct {
+	Name       string     `json:"name"`
+	Expression string     `json:"expression"`
+	Enabled    bool       `json:"enabled"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	NextRunAt  *time.Time `json:"next_run_at"`
+	Version    int32      `json:"version"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ScheduleChange changes a job's schedule, leaving nil fields as they are.
+// Expression is a cron expression evaluated in UTC, a shorthand such as
+// @daily, or @every followed by a duration.
+type ScheduleChange struct {
+	Expression *string `json:"expression,omitempty"`
+	Enabled    *bool   `json:"enabled,omitempty"`
+}
+
+func (c *Client) ListSchedules(ctx context.Context) ([]*JobSchedule, error) {
+	var schedules []*JobSchedule
+	err := c.Do(ctx, http.MethodGet, "/v1/admin/schedules", nil, nil, &schedules, "schedules")
+	return schedules, err
+}
+
+// UpdateSchedules applies changes keyed by job name, all or none of them,
+// and returns every schedule.
+func (c *Client) UpdateSchedules(ctx context.Context, changes map[string]ScheduleChange) ([]*JobSchedule, error) {
+	var schedules []*JobSchedule
+	err := c.Do(ctx, http.MethodPatch, "/v1/admin/schedules", nil, changes, &schedules, "schedules")
+	return schedules, err
+}
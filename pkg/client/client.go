@@ -0,0 +1,273 @@
+// Package client is a Go client for the Wanton API. It wraps each endpoint
+// in a method taking and returning typed values, unwrapping the JSON
+// envelope responses come in, attaching the authentication token and
+// retrying requests that failed for reasons worth trying again.
+//
+//	c := client.New("https://api.wanton.app", client.WithToken(token))
+//	project, err := c.GetProject(ctx, 24001)
+//
+// Endpoints without a typed method, such as file uploads and imports, can
+// be called with Do.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the API at one base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	userAgent  string
+	maxRetries int
+	backoff    time.Duration
+}
+
+type Option func(*Client)
+
+// WithToken authenticates requests with an authentication token.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient sends requests through hc instead of a client with a 30
+// second timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetries sets how many times a failed request is retried, 3 by
+// default, and the wait before the first retry, which doubles with each
+// one after. Zero retries disables retrying.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithUserAgent names the tool making requests in the User-Agent header.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New returns a client for the API at baseURL, e.g. https://api.wanton.app.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "wanpm-client",
+		maxRetries: 3,
+		backoff:    500 * time.Millisecond,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// APIError is returned for responses with an error status. Message holds
+// the error the API reported; for failed validation it is empty and Errors
+// maps each invalid field to what is wrong with it.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Errors     map[string]string
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) > 0 {
+		fields := make([]string, 0, len(e.Errors))
+		for field, problem := range e.Errors {
+			fields = append(fields, field+": "+problem)
+		}
+		return fmt.Sprintf("wanpm: %d: %s", e.StatusCode, strings.Join(fields, "; "))
+	}
+
+	return fmt.Sprintf("wanpm: %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Do sends a request to path, relative to the base URL, with query
+// parameters and body, which is encoded as JSON unless nil. When out is
+// non-nil the response is decoded into it: as a whole when key is empty,
+// otherwise only the envelope member named key.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any, key string) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	res, err := c.send(ctx, method, u, payload)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 400 {
+		return parseError(res.StatusCode, raw)
+	}
+
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+
+	if key == "" {
+		return json.Unmarshal(raw, out)
+	}
+
+	var env map[string]json.RawMessage
+	err = json.Unmarshal(raw, &env)
+	if err != nil {
+		return err
+	}
+
+	member, ok := env[key]
+	if !ok {
+		return fmt.Errorf("wanpm: response has no %q member", key)
+	}
+
+	return json.Unmarshal(member, out)
+}
+
+// send sends a request, retrying it after network errors and after
+// responses saying the server is busy or briefly unavailable. Requests that
+// are not idempotent are only retried when the server reports it did not
+// handle them, i.e. on 429 and 503.
+func (c *Client) send(ctx context.Context, method, u string, payload []byte) (*http.Response, error) {
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		res, err := c.httpClient.Do(req)
+
+		retry := false
+		var wait time.Duration
+
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			retry = idempotent
+		case res.StatusCode == http.StatusTooManyRequests, res.StatusCode == http.StatusServiceUnavailable:
+			retry = true
+			wait = retryAfter(res.Header.Get("Retry-After"))
+		case res.StatusCode == http.StatusBadGateway, res.StatusCode == http.StatusGatewayTimeout:
+			retry = idempotent
+		}
+
+		if !retry || attempt >= c.maxRetries {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if wait == 0 {
+			// Exponential backoff with jitter, so clients failing together
+			// do not retry together.
+			wait = c.backoff << attempt
+			wait += rand.N(wait/2 + 1)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds, returning zero
+// when it is absent or malformed.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// parseError reads the {"error": ...} body error responses carry, where
+// the error is either a message or, for failed validation, a map of fields
+// to problems.
+func parseError(status int, raw []byte) error {
+	apiErr := &APIError{StatusCode: status, Body: raw}
+
+	var env struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	if json.Unmarshal(raw, &env) != nil || len(env.Error) == 0 {
+		apiErr.Message = http.StatusText(status)
+		return apiErr
+	}
+
+	if json.Unmarshal(env.Error, &apiErr.Message) != nil {
+		json.Unmarshal(env.Error, &apiErr.Errors)
+	}
+
+	return apiErr
+}
+
+// pathf builds a path from a format, escaping each argument.
+func pathf(format string, args ...any) string {
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		escaped[i] = url.PathEscape(fmt.Sprint(arg))
+	}
+
+	return fmt.Sprintf(format, escaped...)
+}
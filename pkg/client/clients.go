@@ -0,0 +1,358 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ClientInput creates or, with only the fields to change set, updates a
+// client.
+type ClientInput struct {
+	Name         *string      `json:"name,omitempty"`
+	Address      *string      `json:"address,omitempty"`
+	LogoURL      *string      `json:"logo_url,omitempty"`
+	Note         *string      `json:"note,omitempty"`
+	CustomFields CustomValues `json:"custom_fields,omitempty"`
+	Longitude    *float64     `json:"longitude,omitempty"`
+	Latitude     *float64     `json:"latitude,omitempty"`
+	// ParentID makes the client a division of another; 0 makes it
+	// top-level again.
+	ParentID *int32 `json:"parent_id,omitempty"`
+}
+
+// ClientFilter narrows ListClients.
+type ClientFilter struct {
+	Name string
+	// Bbox is west,south,east,north.
+	Bbox []string
+	// Near is a longitude,latitude pair clients must be within Radius
+	// metres of, 10 km when Radius is zero.
+	Near         []string
+	Radius       int
+	CustomFields map[string]string
+	ListOptions
+}
+
+func (c *Client) ListClients(ctx context.Context, filter ClientFilter) ([]*ClientRecord, Metadata, error) {
+	q := url.Values{}
+
+	setString(q, "name", filter.Name)
+	setCSV(q, "bbox", filter.Bbox)
+	setCSV(q, "near", filter.Near)
+	setInt(q, "radius", filter.Radius)
+
+	for name, value := range filter.CustomFields {
+		q.Set("cf."+name, value)
+	}
+
+	filter.ListOptions.encode(q)
+
+	var out struct {
+		Metadata Metadata        `json:"metadata"`
+		Clients  []*ClientRecord `json:"clients"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, "/v1/client", q, nil, &out, "")
+	return out.Clients, out.Metadata, err
+}
+
+func (c *Client) GetClient(ctx context.Context, id int32) (*ClientRecord, error) {
+	var client ClientRecord
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/client/%s", id), nil, nil, &client, "client")
+	return &client, err
+}
+
+func (c *Client) CreateClient(ctx context.Context, input ClientInput) (*ClientRecord, error) {
+	var client ClientRecord
+	err := c.Do(ctx, http.MethodPost, "/v1/client", nil, input, &client, "client")
+	return &client, err
+}
+
+func (c *Client) UpdateClient(ctx context.Context, id int32, input ClientInput) (*ClientRecord, error) {
+	var client ClientRecord
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/client/%s", id), nil, input, &client, "client")
+	return &client, err
+}
+
+// GetClientSummary summarizes a client's projects, rolling up those of its
+// divisions when divisions is true.
+func (c *Client) GetClientSummary(ctx context.Context, id int32, divisions bool) (*ClientSummary, error) {
+	var summary ClientSummary
+	q := url.Values{"include_divisions": {strconv.FormatBool(divisions)}}
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/client/%s/summary", id), q, nil, &summary, "summary")
+	return &summary, err
+}
+
+// GetClientSubtree returns a client followed by its divisions, theirs and
+// so on.
+func (c *Client) GetClientSubtree(ctx context.Context, id int32) ([]*ClientRecord, error) {
+	var clients []*ClientRecord
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/client/%s/subtree", id), nil, nil, &clients, "clients")
+	return clients, err
+}
+
+// DeleteClient deletes a client no project is linked to.
+func (c *Client) DeleteClient(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/client/%s", id), nil, nil, nil, "")
+}
+
+// DetachClient deletes a client, unlinking the projects linked to it.
+func (c *Client) DetachClient(ctx context.Context, id int32) error {
+	q := url.Values{"mode": {"detach"}}
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/client/%s", id), q, nil, nil, "")
+}
+
+// ReassignClient deletes a client, linking the projects linked to it to
+// the client to instead.
+func (c *Client) ReassignClient(ctx context.Context, id, to int32) error {
+	q := url.Values{"mode": {"reassign"}, "reassign_to": {strconv.Itoa(int(to))}}
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/client/%s", id), q, nil, nil, "")
+}
+
+// ListCustomFields lists the custom fields of an entity, project or
+// client, or of both when entity is empty.
+func (c *Client) ListCustomFields(ctx context.Context, entity string) ([]*CustomField, error) {
+	q := url.Values{}
+	setString(q, "entity", entity)
+
+	var fields []*CustomField
+	err := c.Do(ctx, http.MethodGet, "/v1/custom-field", q, nil, &fields, "custom_fields")
+	return fields, err
+}
+
+func (c *Client) GetCustomField(ctx context.Context, id int32) (*CustomField, error) {
+	var field CustomField
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/custom-field/%s", id), nil, nil, &field, "custom_field")
+	return &field, err
+}
+
+// CreateCustomField adds a field to an entity, project or client. DataType
+// is one of the types the server lists in its validation error.
+func (c *Client) CreateCustomField(ctx context.Context, entity, name, dataType string, required bool) (*CustomField, error) {
+	input := map[string]any{"entity": entity, "name": name, "data_type": dataType, "required": required}
+
+	var field CustomField
+	err := c.Do(ctx, http.MethodPost, "/v1/custom-field", nil, input, &field, "custom_field")
+	return &field, err
+}
+
+// UpdateCustomField renames a field or changes whether it is required,
+// leaving whichever is nil as it is.
+func (c *Client) UpdateCustomField(ctx context.Context, id int32, name *string, required *bool) (*CustomField, error) {
+	input := map[string]any{}
+	if name != nil {
+		input["name"] = *name
+	}
+	if required != nil {
+		input["required"] = *required
+	}
+
+	var field CustomField
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/custom-field/%s", id), nil, input, &field, "custom_field")
+	return &field, err
+}
+
+func (c *Client) DeleteCustomField(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/custom-field/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) ListActivities(ctx context.Context) ([]*Activity, error) {
+	var activities []*Activity
+	err := c.Do(ctx, http.MethodGet, "/v1/activity", nil, nil, &activities, "activities")
+	return activities, err
+}
+
+// ActivityTree lists the top level activities with their children nested.
+func (c *Client) ActivityTree(ctx context.Context) ([]*Activity, error) {
+	var activities []*Activity
+	err := c.Do(ctx, http.MethodGet, "/v1/activity/tree", nil, nil, &activities, "activities")
+	return activities, err
+}
+
+func (c *Client) GetActivity(ctx context.Context, id int32) (*Activity, error) {
+	var activity Activity
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/activity/%s", id), nil, nil, &activity, "activity")
+	return &activity, err
+}
+
+func (c *Client) CreateActivity(ctx context.Context, name string, parentID *int32) (*Activity, error) {
+	var activity Activity
+	err := c.Do(ctx, http.MethodPost, "/v1/activity", nil, map[string]any{"name": name, "parent_id": parentID}, &activity, "activity")
+	return &activity, err
+}
+
+// ActivityUpdate changes an activity. Set MoveToRoot to make it top level,
+// otherwise a nil ParentID leaves its parent as it is.
+type ActivityUpdate struct {
+	Name       *string
+	ParentID   *int32
+	MoveToRoot bool
+}
+
+func (c *Client) UpdateActivity(ctx context.Context, id int32, update ActivityUpdate) (*Activity, error) {
+	input := map[string]any{}
+	if update.Name != nil {
+		input["name"] = *update.Name
+	}
+	switch {
+	case update.MoveToRoot:
+		input["parent_id"] = nil
+	case update.ParentID != nil:
+		input["parent_id"] = *update.ParentID
+	}
+
+	var activity Activity
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/activity/%s", id), nil, input, &activity, "activity")
+	return &activity, err
+}
+
+func (c *Client) DeleteActivity(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/activity/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) ListTags(ctx context.Context) ([]*Tag, error) {
+	var tags []*Tag
+	err := c.Do(ctx, http.MethodGet, "/v1/tag", nil, nil, &tags, "tags")
+	return tags, err
+}
+
+func (c *Client) CreateTag(ctx context.Context, name string) (*Tag, error) {
+	var tag Tag
+	err := c.Do(ctx, http.MethodPost, "/v1/tag", nil, map[string]any{"name": name}, &tag, "tag")
+	return &tag, err
+}
+
+func (c *Client) DeleteTag(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/tag/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) ListTeams(ctx context.Context) ([]*Team, error) {
+	var teams []*Team
+	err := c.Do(ctx, http.MethodGet, "/v1/team", nil, nil, &teams, "teams")
+	return teams, err
+}
+
+func (c *Client) GetTeam(ctx context.Context, id int32) (*Team, error) {
+	var team Team
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/team/%s", id), nil, nil, &team, "team")
+	return &team, err
+}
+
+func (c *Client) CreateTeam(ctx context.Context, name string) (*Team, error) {
+	var team Team
+	err := c.Do(ctx, http.MethodPost, "/v1/team", nil, map[string]any{"name": name}, &team, "team")
+	return &team, err
+}
+
+func (c *Client) RenameTeam(ctx context.Context, id int32, name string) (*Team, error) {
+	var team Team
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/team/%s", id), nil, map[string]any{"name": name}, &team, "team")
+	return &team, err
+}
+
+func (c *Client) DeleteTeam(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/team/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) ListTeamMembers(ctx context.Context, teamID int32) ([]*TeamMember, error) {
+	var members []*TeamMember
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/team/%s/members", teamID), nil, nil, &members, "members")
+	return members, err
+}
+
+// SetTeamMember adds a user to a team, or updates whether they lead it,
+// returning the team's members.
+func (c *Client) SetTeamMember(ctx context.Context, teamID, userID int32, isLead bool) ([]*TeamMember, error) {
+	var members []*TeamMember
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/team/%s/members/%s", teamID, userID), nil, map[string]any{"is_lead": isLead}, &members, "members")
+	return members, err
+}
+
+func (c *Client) RemoveTeamMember(ctx context.Context, teamID, userID int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/team/%s/members/%s", teamID, userID), nil, nil, nil, "")
+}
+
+// ListUsers lists users, only the members of a team when teamID is set.
+func (c *Client) ListUsers(ctx context.Context, teamID int32, opts ListOptions) ([]*User, Metadata, error) {
+	q := url.Values{}
+	setInt(q, "team_id", int(teamID))
+	opts.encode(q)
+
+	var out struct {
+		Metadata Metadata `json:"metadata"`
+		Users    []*User  `json:"users"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, "/v1/user", q, nil, &out, "")
+	return out.Users, out.Metadata, err
+}
+
+// SetHourlyCost sets what an hour of a user's time costs, or clears it
+// when cost is nil.
+func (c *Client) SetHourlyCost(ctx context.Context, userID int32, cost *float64) error {
+	return c.Do(ctx, http.MethodPut, pathf("/v1/user/%s/hourly-cost", userID), nil, map[string]any{"hourly_cost": cost}, nil, "")
+}
+
+// ErasePersonalData anonymizes a user. As erasure cannot be undone, the
+// user's current email address must be given to confirm it.
+func (c *Client) ErasePersonalData(ctx context.Context, userID int32, confirmEmail string) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/user/%s/personal-data", userID), nil, map[string]any{"confirm_email": confirmEmail}, nil, "")
+}
+
+// ListDelegations lists approval delegations, only those involving a user
+// when userID is set.
+func (c *Client) ListDelegations(ctx context.Context, userID int32) ([]*Delegation, error) {
+	q := url.Values{}
+	setInt(q, "user_id", int(userID))
+
+	var delegations []*Delegation
+	err := c.Do(ctx, http.MethodGet, "/v1/delegation", q, nil, &delegations, "delegations")
+	return delegations, err
+}
+
+// CreateDelegation lets delegateID approve on delegatorID's behalf between
+// two dates in YYYY-MM-DD form.
+func (c *Client) CreateDelegation(ctx context.Context, delegatorID, delegateID int32, startsOn, endsOn string) (*Delegation, error) {
+	input := map[string]any{"delegator_id": delegatorID, "delegate_id": delegateID, "starts_on": startsOn, "ends_on": endsOn}
+
+	var delegation Delegation
+	err := c.Do(ctx, http.MethodPost, "/v1/delegation", nil, input, &delegation, "delegation")
+	return &delegation, err
+}
+
+func (c *Client) DeleteDelegation(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/delegation/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) GetProposal(ctx context.Context, id int32) (*Proposal, error) {
+	var proposal Proposal
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/proposal/%s", id), nil, nil, &proposal, "proposal")
+	return &proposal, err
+}
+
+// CreateProposal adds a proposal, due on a date in YYYY-MM-DD form when
+// dueOn is set.
+func (c *Client) CreateProposal(ctx context.Context, proposalID string, dueOn *string) (*Proposal, error) {
+	var proposal Proposal
+	err := c.Do(ctx, http.MethodPost, "/v1/proposal", nil, map[string]any{"proposal_id": proposalID, "due_on": dueOn}, &proposal, "proposal")
+	return &proposal, err
+}
+
+func (c *Client) UpdateProposal(ctx context.Context, id int32, proposalID string, dueOn *string) (*Proposal, error) {
+	var proposal Proposal
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/proposal/%s", id), nil, map[string]any{"proposal_id": proposalID, "due_on": dueOn}, &proposal, "proposal")
+	return &proposal, err
+}
+
+// GetProposalProject returns the project created from a proposal.
+func (c *Client) GetProposalProject(ctx context.Context, proposalID string) (*Project, error) {
+	var project Project
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/proposal/%s/project", proposalID), nil, nil, &project, "project")
+	return &project, err
+}
+
+func (c *Client) DeleteProposal(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/proposal/%s", id), nil, nil, nil, "")
+}
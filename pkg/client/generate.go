@@ -0,0 +1,8 @@
+package client
+
+// TypeScript clients share the Go client's types through the OpenAPI
+// document, from which go generate writes ts/wanpm.d.ts. Regenerate it
+// after changing a route or a type the API encodes.
+//
+//go:generate sh -c "go run ../../cmd/api -openapi > ts/openapi.json"
+//go:generate npx --yes openapi-typescript@7 ts/openapi.json -o ts/wanpm.d.ts
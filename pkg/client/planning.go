@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// AllocationFilter narrows ListAllocations to a user, a project and a
+// range of weeks, each when set.
+type AllocationFilter struct {
+	UserID    int32
+	ProjectID int32
+	From      time.Time
+	To        time.Time
+}
+
+func (c *Client) ListAllocations(ctx context.Context, filter AllocationFilter) ([]*Allocation, error) {
+	q := url.Values{}
+	setInt(q, "user_id", int(filter.UserID))
+	setInt(q, "project_id", int(filter.ProjectID))
+	setDate(q, "from", filter.From)
+	setDate(q, "to", filter.To)
+
+	var allocations []*Allocation
+	err := c.Do(ctx, http.MethodGet, "/v1/allocation", q, nil, &allocations, "allocations")
+	return allocations, err
+}
+
+func (c *Client) GetAllocation(ctx context.Context, id int32) (*Allocation, error) {
+	var allocation Allocation
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/allocation/%s", id), nil, nil, &allocation, "allocation")
+	return &allocation, err
+}
+
+// CreateAllocation books hours of a user's time on a project in the week
+// starting on Monday week.
+func (c *Client) CreateAllocation(ctx context.Context, userID, projectID int32, week time.Time, hours float64) (*Allocation, error) {
+	input := map[string]any{"user_id": userID, "project_id": projectID, "week": week.Format(time.DateOnly), "hours": hours}
+
+	var allocation Allocation
+	err := c.Do(ctx, http.MethodPost, "/v1/allocation", nil, input, &allocation, "allocation")
+	return &allocation, err
+}
+
+// UpdateAllocation moves an allocation to another week or changes its
+// hours, leaving whichever is zero as it is.
+func (c *Client) UpdateAllocation(ctx context.Context, id int32, week time.Time, hours float64) (*Allocation, error) {
+	input := map[string]any{}
+	if !week.IsZero() {
+		input["week"] = week.Format(time.DateOnly)
+	}
+	if hours != 0 {
+		input["hours"] = hours
+	}
+
+	var allocation Allocation
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/allocation/%s", id), nil, input, &allocation, "allocation")
+	return &allocation, err
+}
+
+func (c *Client) DeleteAllocation(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/allocation/%s", id), nil, nil, nil, "")
+}
+
+// Capacity reports each user's available and committed hours per week
+// between two dates, widened to whole weeks, only for a team's members
+// when teamID is set.
+func (c *Client) Capacity(ctx context.Context, from, to time.Time, teamID int32) ([]*UserCapacity, error) {
+	q := url.Values{}
+	setDate(q, "from", from)
+	setDate(q, "to", to)
+	setInt(q, "team_id", int(teamID))
+
+	var capacity []*UserCapacity
+	err := c.Do(ctx, http.MethodGet, "/v1/planning/capacity", q, nil, &capacity, "capacity")
+	return capacity, err
+}
+
+// ListLeave lists leave overlapping the dates given, only a user's when
+// userID is set.
+func (c *Client) ListLeave(ctx context.Context, userID int32, from, to time.Time) ([]*Leave, error) {
+	q := url.Values{}
+	setInt(q, "user_id", int(userID))
+	setDate(q, "from", from)
+	setDate(q, "to", to)
+
+	var leave []*Leave
+	err := c.Do(ctx, http.MethodGet, "/v1/leave", q, nil, &leave, "leave")
+	return leave, err
+}
+
+func (c *Client) CreateLeave(ctx context.Context, userID int32, startsOn, endsOn time.Time, note string) (*Leave, error) {
+	input := map[string]any{"user_id": userID, "starts_on": startsOn.Format(time.DateOnly), "ends_on": endsOn.Format(time.DateOnly), "note": note}
+
+	var leave Leave
+	err := c.Do(ctx, http.MethodPost, "/v1/leave", nil, input, &leave, "leave")
+	return &leave, err
+}
+
+func (c *Client) DeleteLeave(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/leave/%s", id), nil, nil, nil, "")
+}
+
+// ListHolidays lists the caller's organization's holidays in a year, the
+// current one when year is zero.
+func (c *Client) ListHolidays(ctx context.Context, year int) ([]*Holiday, error) {
+	q := url.Values{}
+	setInt(q, "year", year)
+
+	var holidays []*Holiday
+	err := c.Do(ctx, http.MethodGet, "/v1/holiday", q, nil, &holidays, "holidays")
+	return holidays, err
+}
+
+func (c *Client) CreateHoliday(ctx context.Context, day time.Time, name string) (*Holiday, error) {
+	var holiday Holiday
+	err := c.Do(ctx, http.MethodPost, "/v1/holiday", nil, map[string]any{"day": day.Format(time.DateOnly), "name": name}, &holiday, "holiday")
+	return &holiday, err
+}
+
+func (c *Client) DeleteHoliday(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/holiday/%s", id), nil, nil, nil, "")
+}
@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ProjectInput creates or, with only the fields to change set, updates a
+// project.
+type ProjectInput struct {
+	ProjectID    *int32       `json:"project_id,omitempty"`
+	ProposalID   *string      `json:"proposal_id,omitempty"`
+	Name         *string      `json:"name,omitempty"`
+	Status       *string      `json:"status,omitempty"`
+	ClientNames  []string     `json:"client_names,omitempty"`
+	Feature      *Feature     `json:"feature,omitempty"`
+	Images       []string     `json:"images,omitempty"`
+	CustomFields CustomValues `json:"custom_fields,omitempty"`
+}
+
+// ProjectFilter narrows ListProjects. Text filters match values containing
+// them.
+type ProjectFilter struct {
+	Name        string
+	Status      string
+	ProjectID   string
+	ProposalID  string
+	FullAddress string
+	ClientName  string
+	Clients     []string
+	// Bbox is west,south,east,north.
+	Bbox []string
+	// Tags keeps projects carrying every one of these tags.
+	Tags            []string
+	IncludeArchived bool
+	// CustomFields filters on custom field values by field name.
+	CustomFields map[string]string
+	ListOptions
+}
+
+func (f ProjectFilter) query() url.Values {
+	q := url.Values{}
+
+	setString(q, "name", f.Name)
+	setString(q, "status", f.Status)
+	setString(q, "project_id", f.ProjectID)
+	setString(q, "proposal_id", f.ProposalID)
+	setString(q, "full_address", f.FullAddress)
+	setString(q, "client_name", f.ClientName)
+	setCSV(q, "clients", f.Clients)
+	setCSV(q, "bbox", f.Bbox)
+	setCSV(q, "tags", f.Tags)
+	setBool(q, "include_archived", f.IncludeArchived)
+
+	for name, value := range f.CustomFields {
+		q.Set("cf."+name, value)
+	}
+
+	f.ListOptions.encode(q)
+
+	return q
+}
+
+func (c *Client) ListProjects(ctx context.Context, filter ProjectFilter) ([]*Project, Metadata, error) {
+	var out struct {
+		Metadata Metadata   `json:"metadata"`
+		Projects []*Project `json:"projects"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, "/v1/project", filter.query(), nil, &out, "")
+	return out.Projects, out.Metadata, err
+}
+
+// ListProjectSummaries lists projects as the short summaries pickers use.
+func (c *Client) ListProjectSummaries(ctx context.Context, filter ProjectFilter) ([]*ProjectSummary, Metadata, error) {
+	q := filter.query()
+	q.Set("summary", "true")
+
+	var out struct {
+		Metadata Metadata          `json:"metadata"`
+		Projects []*ProjectSummary `json:"projects"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, "/v1/project", q, nil, &out, "")
+	return out.Projects, out.Metadata, err
+}
+
+// GetProjects fetches up to 100 projects by ID in the order given, leaving
+// out those that do not exist.
+func (c *Client) GetProjects(ctx context.Context, ids ...int32) ([]*Project, error) {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.Itoa(int(id))
+	}
+
+	var projects []*Project
+	err := c.Do(ctx, http.MethodGet, "/v1/project", url.Values{"ids": {strings.Join(strs, ",")}}, nil, &projects, "projects")
+	return projects, err
+}
+
+func (c *Client) GetProject(ctx context.Context, id int32) (*Project, error) {
+	var project Project
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s", id), nil, nil, &project, "project")
+	return &project, err
+}
+
+func (c *Client) CreateProject(ctx context.Context, input ProjectInput) (*Project, error) {
+	var project Project
+	err := c.Do(ctx, http.MethodPost, "/v1/project", nil, input, &project, "project")
+	return &project, err
+}
+
+func (c *Client) UpdateProject(ctx context.Context, id int32, input ProjectInput) (*Project, error) {
+	var project Project
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/project/%s", id), nil, input, &project, "project")
+	return &project, err
+}
+
+// DeleteProject deletes a project. Force deletes it even though timesheet
+// entries refer to it, which needs the project:force-delete permission.
+func (c *Client) DeleteProject(ctx context.Context, id int32, force bool) error {
+	q := url.Values{}
+	setBool(q, "force", force)
+
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/project/%s", id), q, nil, nil, "")
+}
+
+func (c *Client) ArchiveProject(ctx context.Context, id int32) (*Project, error) {
+	var project Project
+	err := c.Do(ctx, http.MethodPost, pathf("/v1/project/%s/archive", id), nil, nil, &project, "project")
+	return &project, err
+}
+
+func (c *Client) UndeleteProject(ctx context.Context, id int32) (*Project, error) {
+	var project Project
+	err := c.Do(ctx, http.MethodPost, pathf("/v1/project/%s/undelete", id), nil, nil, &project, "project")
+	return &project, err
+}
+
+// SetProjectTags replaces a project's tags, returning them as stored.
+func (c *Client) SetProjectTags(ctx context.Context, id int32, tags []string) ([]string, error) {
+	var stored []string
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/project/%s/tags", id), nil, map[string]any{"tags": tags}, &stored, "tags")
+	return stored, err
+}
+
+func (c *Client) GetProjectBudget(ctx context.Context, id int32) (*ProjectBudget, error) {
+	var budget ProjectBudget
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s/budget", id), nil, nil, &budget, "budget")
+	return &budget, err
+}
+
+// SetProjectBudget sets the minutes and, optionally, the cost budgeted for
+// a project.
+func (c *Client) SetProjectBudget(ctx context.Context, id int32, minutes int32, cost *float64) (*ProjectBudget, error) {
+	input := map[string]any{"minutes": minutes, "cost": cost}
+
+	var budget ProjectBudget
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/project/%s/budget", id), nil, input, &budget, "budget")
+	return &budget, err
+}
+
+func (c *Client) DeleteProjectBudget(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/project/%s/budget", id), nil, nil, nil, "")
+}
+
+func (c *Client) GetProjectEVM(ctx context.Context, id int32) (*ProjectEVM, error) {
+	var evm ProjectEVM
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s/evm", id), nil, nil, &evm, "evm")
+	return &evm, err
+}
+
+func (c *Client) ListProjectActivities(ctx context.Context, id int32) ([]*Activity, error) {
+	var activities []*Activity
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s/activities", id), nil, nil, &activities, "activities")
+	return activities, err
+}
+
+// SetProjectActivities replaces the activities time can be logged against
+// on a project.
+func (c *Client) SetProjectActivities(ctx context.Context, id int32, activityIDs []int32) ([]*Activity, error) {
+	var activities []*Activity
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/project/%s/activities", id), nil, map[string]any{"activity_ids": activityIDs}, &activities, "activities")
+	return activities, err
+}
+
+func (c *Client) ListMilestones(ctx context.Context, projectID int32) ([]*Milestone, error) {
+	var milestones []*Milestone
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s/milestones", projectID), nil, nil, &milestones, "milestones")
+	return milestones, err
+}
+
+// CreateMilestone adds a milestone due on a date in YYYY-MM-DD form.
+func (c *Client) CreateMilestone(ctx context.Context, projectID int32, name, dueOn string) (*Milestone, error) {
+	var milestone Milestone
+	err := c.Do(ctx, http.MethodPost, pathf("/v1/project/%s/milestones", projectID), nil, map[string]any{"name": name, "due_on": dueOn}, &milestone, "milestone")
+	return &milestone, err
+}
+
+// CompleteMilestone marks a milestone completed on a date in YYYY-MM-DD
+// form, or not completed when completedOn is nil.
+func (c *Client) CompleteMilestone(ctx context.Context, id int32, completedOn *string) (*Milestone, error) {
+	var milestone Milestone
+	err := c.Do(ctx, http.MethodPatch, pathf("/v1/milestone/%s", id), nil, map[string]any{"completed_on": completedOn}, &milestone, "milestone")
+	return &milestone, err
+}
+
+func (c *Client) DeleteMilestone(ctx context.Context, id int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/milestone/%s", id), nil, nil, nil, "")
+}
+
+func (c *Client) ListProjectMembers(ctx context.Context, projectID int32) ([]*Assignment, error) {
+	var members []*Assignment
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s/members", projectID), nil, nil, &members, "members")
+	return members, err
+}
+
+// AssignProjectMember assigns a user to a project, returning its members.
+func (c *Client) AssignProjectMember(ctx context.Context, projectID, userID int32) ([]*Assignment, error) {
+	var members []*Assignment
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/project/%s/members/%s", projectID, userID), nil, nil, &members, "members")
+	return members, err
+}
+
+// AssignProjectMemberRole assigns a user to a project with a role, granting
+// them its permissions, returning its members.
+func (c *Client) AssignProjectMemberRole(ctx context.Context, projectID, userID int32, role string) ([]*Assignment, error) {
+	q := url.Values{"role": {role}}
+
+	var members []*Assignment
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/project/%s/members/%s", projectID, userID), q, nil, &members, "members")
+	return members, err
+}
+
+func (c *Client) RemoveProjectMember(ctx context.Context, projectID, userID int32) error {
+	return c.Do(ctx, http.MethodDelete, pathf("/v1/project/%s/members/%s", projectID, userID), nil, nil, nil, "")
+}
+
+// GetProjectApprovalSteps returns the approval chain of a project, which
+// falls back to the organization's when the project has none.
+func (c *Client) GetProjectApprovalSteps(ctx context.Context, projectID int32) ([]*ApprovalStep, error) {
+	var steps []*ApprovalStep
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/project/%s/approval-steps", projectID), nil, nil, &steps, "steps")
+	return steps, err
+}
+
+func (c *Client) SetProjectApprovalSteps(ctx context.Context, projectID int32, steps []*ApprovalStep) ([]*ApprovalStep, error) {
+	var stored []*ApprovalStep
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/project/%s/approval-steps", projectID), nil, map[string]any{"steps": steps}, &stored, "steps")
+	return stored, err
+}
+
+// NextCodes are the next free project and proposal codes. Either is nil
+// when its format is empty or has no sequence numbers left this year.
+type NextCodes struct {
+	ProjectID  *int32  `json:"project_id"`
+	ProposalID *string `json:"proposal_id"`
+}
+
+// ReserveProjectID reserves the next project_id for a day, so that creating
+// a project with it cannot fail on a duplicate.
+func (c *Client) ReserveProjectID(ctx context.Context) (int32, error) {
+	var id int32
+	err := c.Do(ctx, http.MethodPost, "/v1/project/next-id", nil, nil, &id, "project_id")
+	return id, err
+}
+
+// GetNextCodes suggests the next free codes under the caller's code policy.
+func (c *Client) GetNextCodes(ctx context.Context) (*NextCodes, error) {
+	var next NextCodes
+	err := c.Do(ctx, http.MethodGet, "/v1/codes/next", nil, nil, &next, "next")
+	return &next, err
+}
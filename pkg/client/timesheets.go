@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TimesheetFilter narrows ListTimesheets and ListMyTimesheets. Dates are
+// inclusive and compared by day.
+type TimesheetFilter struct {
+	UserID    int32
+	ProjectID int32
+	From      time.Time
+	To        time.Time
+	// Tags keeps entries carrying every one of these tags.
+	Tags []string
+	// Statuses keeps entries in any one of these states.
+	Statuses      []string
+	ApproverID    int32
+	SubmittedFrom time.Time
+	SubmittedTo   time.Time
+	ListOptions
+}
+
+func (f TimesheetFilter) query() url.Values {
+	q := url.Values{}
+
+	setInt(q, "user_id", int(f.UserID))
+	setInt(q, "project_id", int(f.ProjectID))
+	setDate(q, "from", f.From)
+	setDate(q, "to", f.To)
+	setCSV(q, "tags", f.Tags)
+	setCSV(q, "status", f.Statuses)
+	setInt(q, "approver_id", int(f.ApproverID))
+	setDate(q, "submitted_from", f.SubmittedFrom)
+	setDate(q, "submitted_to", f.SubmittedTo)
+
+	f.ListOptions.encode(q)
+
+	return q
+}
+
+func (c *Client) ListTimesheets(ctx context.Context, filter TimesheetFilter) ([]*TimesheetEntry, Metadata, error) {
+	return c.listTimesheets(ctx, "/v1/timesheet", filter)
+}
+
+// ListMyTimesheets lists the caller's own entries. filter.UserID is
+// ignored.
+func (c *Client) ListMyTimesheets(ctx context.Context, filter TimesheetFilter) ([]*TimesheetEntry, Metadata, error) {
+	filter.UserID = 0
+	return c.listTimesheets(ctx, "/v1/me/timesheets", filter)
+}
+
+func (c *Client) listTimesheets(ctx context.Context, path string, filter TimesheetFilter) ([]*TimesheetEntry, Metadata, error) {
+	var out struct {
+		Metadata  Metadata          `json:"metadata"`
+		Timesheet []*TimesheetEntry `json:"timesheet"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, path, filter.query(), nil, &out, "")
+	return out.Timesheet, out.Metadata, err
+}
+
+// TimesheetFacets lists the distinct users, projects, activities and tags
+// of the entries matching filter, and the range of their dates.
+func (c *Client) TimesheetFacets(ctx context.Context, filter TimesheetFilter) (*TimesheetFacets, error) {
+	var facets TimesheetFacets
+	err := c.Do(ctx, http.MethodGet, "/v1/timesheet/facets", filter.query(), nil, &facets, "facets")
+	return &facets, err
+}
+
+// TimesheetSuggestions suggests up to limit entries for the caller to log
+// on day, from their recent entries and assignments. Zero values ask for
+// today and the server's default limit.
+func (c *Client) TimesheetSuggestions(ctx context.Context, day time.Time, limit int) ([]*TimesheetSuggestion, error) {
+	q := url.Values{}
+	setDate(q, "date", day)
+	setInt(q, "limit", limit)
+
+	var suggestions []*TimesheetSuggestion
+	err := c.Do(ctx, http.MethodGet, "/v1/me/timesheet/suggestions", q, nil, &suggestions, "suggestions")
+	return suggestions, err
+}
+
+// SetTimesheetTags replaces an entry's tags, returning them as stored.
+func (c *Client) SetTimesheetTags(ctx context.Context, id int64, tags []string) ([]string, error) {
+	var stored []string
+	err := c.Do(ctx, http.MethodPut, pathf("/v1/timesheet/%s/tags", id), nil, map[string]any{"tags": tags}, &stored, "tags")
+	return stored, err
+}
+
+// SubmitTimesheet submits a draft or rejected entry for approval.
+func (c *Client) SubmitTimesheet(ctx context.Context, id int64) (*TimesheetEntry, error) {
+	return c.decideTimesheet(ctx, id, "submit")
+}
+
+func (c *Client) ApproveTimesheet(ctx context.Context, id int64) (*TimesheetEntry, error) {
+	return c.decideTimesheet(ctx, id, "approve")
+}
+
+func (c *Client) RejectTimesheet(ctx context.Context, id int64) (*TimesheetEntry, error) {
+	return c.decideTimesheet(ctx, id, "reject")
+}
+
+func (c *Client) decideTimesheet(ctx context.Context, id int64, action string) (*TimesheetEntry, error) {
+	var entry TimesheetEntry
+	err := c.Do(ctx, http.MethodPost, pathf("/v1/timesheet/%s/"+action, id), nil, nil, &entry, "timesheet_entry")
+	return &entry, err
+}
+
+// Sync returns what changed for the caller since a time, or everything
+// current when since is zero. Pass the Until of one result as the since of
+// the next.
+func (c *Client) Sync(ctx context.Context, since time.Time) (*SyncChanges, error) {
+	q := url.Values{}
+	if !since.IsZero() {
+		q.Set("since", since.Format(time.RFC3339))
+	}
+
+	var changes SyncChanges
+	err := c.Do(ctx, http.MethodGet, "/v1/sync", q, nil, &changes, "sync")
+	return &changes, err
+}
+
+// TimesheetSyncEntry is an entry recorded offline. BaseVersion is the
+// server version last seen, zero for a new entry, and ModifiedAt when the
+// entry was last changed offline.
+type TimesheetSyncEntry struct {
+	EntryUUID   string    `json:"entry_uuid"`
+	ProjectID   int32     `json:"project_id"`
+	ActivityID  *int32    `json:"activity_id,omitempty"`
+	WorkDate    string    `json:"work_date"`
+	Minutes     int32     `json:"minutes"`
+	Note        string    `json:"note"`
+	BaseVersion int32     `json:"base_version"`
+	ModifiedAt  time.Time `json:"modified_at"`
+}
+
+// TimesheetSyncResult is what became of one synced entry. Status is
+// created, updated, conflict or rejected; conflicts carry the server's
+// copy of the entry.
+type TimesheetSyncResult struct {
+	EntryUUID string            `json:"entry_uuid"`
+	Status    string            `json:"status"`
+	Reason    string            `json:"reason,omitempty"`
+	Entry     *TimesheetEntry   `json:"entry,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+type TimesheetSyncReport struct {
+	Strategy  string                 `json:"strategy"`
+	Created   int                    `json:"created"`
+	Updated   int                    `json:"updated"`
+	Conflicts int                    `json:"conflicts"`
+	Rejected  int                    `json:"rejected"`
+	Results   []*TimesheetSyncResult `json:"results"`
+}
+
+// SyncTimesheets upserts up to 500 of the caller's entries recorded
+// offline. Strategy is last_write_wins or server_version, the server's
+// default when empty. Entries are matched by UUID, so a batch may safely be
+// sent again.
+func (c *Client) SyncTimesheets(ctx context.Context, strategy string, entries []TimesheetSyncEntry) (*TimesheetSyncReport, error) {
+	input := map[string]any{"strategy": strategy, "entries": entries}
+
+	var report TimesheetSyncReport
+	err := c.Do(ctx, http.MethodPost, "/v1/sync/timesheets", nil, input, &report, "sync")
+	return &report, err
+}
+
+// ListNotifications lists the caller's notifications, newest first by
+// default, with how many are unread.
+func (c *Client) ListNotifications(ctx context.Context, unreadOnly bool, opts ListOptions) ([]*Notification, int, Metadata, error) {
+	q := url.Values{}
+	setBool(q, "unread", unreadOnly)
+	opts.encode(q)
+
+	var out struct {
+		Metadata      Metadata        `json:"metadata"`
+		UnreadCount   int             `json:"unread_count"`
+		Notifications []*Notification `json:"notifications"`
+	}
+
+	err := c.Do(ctx, http.MethodGet, "/v1/notifications", q, nil, &out, "")
+	return out.Notifications, out.UnreadCount, out.Metadata, err
+}
+
+func (c *Client) MarkNotificationRead(ctx context.Context, id int64) error {
+	return c.Do(ctx, http.MethodPatch, pathf("/v1/notifications/%s", id), nil, nil, nil, "")
+}
+
+// MarkAllNotificationsRead returns how many notifications it marked.
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) (int, error) {
+	var updated int
+	err := c.Do(ctx, http.MethodPatch, "/v1/notifications", nil, nil, &updated, "updated")
+	return updated, err
+}
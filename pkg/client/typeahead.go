@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Typeahead returns up to 20 users, projects, clients or activities, as
+// entity says, starting with q, for searchable selects.
+func (c *Client) Typeahead(ctx context.Context, entity, q string) ([]TypeaheadOption, error) {
+	var options []TypeaheadOption
+	err := c.Do(ctx, http.MethodGet, pathf("/v1/typeahead/%s", entity), url.Values{"q": {q}}, nil, &options, "options")
+	return options, err
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwanbin/wanpm-api/internal/data"
+)
+
+// Resources are the types the API encodes, so the client decodes exactly
+// what the server sends.
+type (
+	Project                = data.ProjectResponse
+	ProjectSummary         = data.ProjectSummary
+	ProjectBudget          = data.ProjectBudget
+	ProjectEVM             = data.ProjectEVM
+	Feature                = data.Feature
+	CustomValues           = data.CustomValues
+	ClientRecord           = data.Client
+	ClientSummary          = data.ClientSummary
+	CustomField            = data.CustomField
+	Activity               = data.Activity
+	Milestone              = data.Milestone
+	Assignment             = data.Assignment
+	Team                   = data.Team
+	TeamMember             = data.TeamMember
+	User                   = data.User
+	Delegation             = data.Delegation
+	Allocation             = data.Allocation
+	Leave                  = data.Leave
+	Holiday                = data.Holiday
+	UserCapacity           = data.UserCapacity
+	TimesheetEntry         = data.TimesheetEntry
+	TimesheetFacets        = data.TimesheetFacets
+	TimesheetSuggestion    = data.TimesheetSuggestion
+	Tag                    = data.Tag
+	ApprovalStep           = data.ApprovalStep
+	Proposal               = data.Proposal
+	Organization           = data.Organization
+	OrgSettings            = data.OrgSettings
+	WorkRules              = data.WorkRules
+	CodePolicy             = data.CodePolicy
+	Role                   = data.Role
+	RoleSync               = data.RoleSync
+	LDAPConfig             = data.LDAPConfig
+	LDAPGroupRole          = data.LDAPGroupRole
+	Token                  = data.Token
+	SecurityEvent          = data.SecurityEvent
+	Notification           = data.Notification
+	DigestPreference       = data.DigestPreference
+	NotificationPreference = data.NotificationPreference
+	SyncChanges            = data.SyncChanges
+	Metadata               = data.Metadata
+	TypeaheadOption        = data.TypeaheadOption
+)
+
+// ListOptions pages and sorts list endpoints. Zero values leave the
+// server's defaults in place.
+type ListOptions struct {
+	Page     int
+	PageSize int
+	// Sort is a comma separated list of columns, each prefixed with - for
+	// descending order.
+	Sort string
+}
+
+func (o ListOptions) encode(q url.Values) {
+	setInt(q, "page", o.Page)
+	setInt(q, "page_size", o.PageSize)
+	setString(q, "sort", o.Sort)
+}
+
+func setString(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
+
+func setInt(q url.Values, key string, value int) {
+	if value != 0 {
+		q.Set(key, strconv.Itoa(value))
+	}
+}
+
+func setBool(q url.Values, key string, value bool) {
+	if value {
+		q.Set(key, "true")
+	}
+}
+
+func setCSV(q url.Values, key string, values []string) {
+	if len(values) > 0 {
+		q.Set(key, strings.Join(values, ","))
+	}
+}
+
+func setDate(q url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		q.Set(key, value.Format(time.DateOnly))
+	}
+}